@@ -0,0 +1,131 @@
+/*
+Package apidocs serves the OpenAPI document generated for APIs that use the Docs DSL together with
+optional Swagger UI and ReDoc pages that render it.
+
+The Swagger UI and ReDoc pages are small HTML documents that load the corresponding scripts and
+stylesheets from SwaggerUIAssets and ReDocAssets, by default the unpkg and redoc.ly CDNs. Set these
+variables before calling Mount to serve the assets from another location, for example a file
+server of the API.
+*/
+package apidocs
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// SwaggerUIAssets is the base URL of the Swagger UI distribution, the page loads
+	// "swagger-ui.css" and "swagger-ui-bundle.js" from this location.
+	SwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@5"
+
+	// ReDocAssets is the URL of the ReDoc standalone script.
+	ReDocAssets = "https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"
+)
+
+// UI is a page rendering the OpenAPI document.
+type UI struct {
+	// Path is the request path of the page.
+	Path string
+	// tmpl renders the page, the template data is a pageData.
+	tmpl *template.Template
+	// assets returns the URL of the page assets.
+	assets func() string
+}
+
+// pageData is the data used to render the UI pages.
+type pageData struct {
+	// Spec is the request path of the OpenAPI document.
+	Spec string
+	// Assets is the URL of the page assets.
+	Assets string
+}
+
+// SwaggerUI returns the Swagger UI page served under path.
+func SwaggerUI(path string) *UI {
+	return &UI{Path: path, tmpl: swaggerUIT, assets: func() string { return SwaggerUIAssets }}
+}
+
+// ReDoc returns the ReDoc page served under path.
+func ReDoc(path string) *UI {
+	return &UI{Path: path, tmpl: redocT, assets: func() string { return ReDocAssets }}
+}
+
+// Mount mounts the handler serving the OpenAPI document spec under specPath and the handlers
+// serving the given UI pages on the service mux. The handlers are not controller actions so that
+// the service and controller middlewares (security, logging, tracing etc.) do not apply to them.
+// Mount panics if a page cannot be rendered.
+func Mount(service *goa.Service, specPath string, spec []byte, uis ...*UI) {
+	mount(service, specPath, SpecHandler(spec))
+	for _, ui := range uis {
+		mount(service, ui.Path, ui.Handler(specPath))
+	}
+}
+
+// SpecHandler returns the handler that serves the OpenAPI document spec.
+func SpecHandler(spec []byte) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Access-Control-Allow-Origin", "*")
+		rw.Write(spec)
+	})
+}
+
+// Handler returns the handler that serves the page rendering the OpenAPI document served under
+// specPath. Handler panics if the page cannot be rendered.
+func (ui *UI) Handler(specPath string) http.Handler {
+	var buf bytes.Buffer
+	if err := ui.tmpl.Execute(&buf, &pageData{Spec: specPath, Assets: ui.assets()}); err != nil {
+		panic(err) // bug
+	}
+	page := buf.Bytes()
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.Write(page)
+	})
+}
+
+// mount mounts h on the service mux under path.
+func mount(service *goa.Service, path string, h http.Handler) {
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		h.ServeHTTP(rw, req)
+	})
+}
+
+var (
+	swaggerUIT = template.Must(template.New("swaggerui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Swagger UI</title>
+<link rel="stylesheet" href="{{ .Assets }}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{ .Assets }}/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function() {
+	window.ui = SwaggerUIBundle({url: {{ .Spec }}, dom_id: "#swagger-ui"});
+};
+</script>
+</body>
+</html>
+`))
+
+	redocT = template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>ReDoc</title>
+</head>
+<body>
+<redoc spec-url="{{ .Spec }}"></redoc>
+<script src="{{ .Assets }}"></script>
+</body>
+</html>
+`))
+)
@@ -0,0 +1,47 @@
+package apidocs_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/apidocs"
+)
+
+func TestMount(t *testing.T) {
+	spec := []byte(`{"openapi":"3.0.3"}`)
+	cases := map[string]struct {
+		Path        string
+		ContentType string
+		Body        string
+	}{
+		"spec":    {"/openapi.json", "application/json", string(spec)},
+		"swagger": {"/docs", "text/html; charset=utf-8", `SwaggerUIBundle({url: "/openapi.json"`},
+		"redoc":   {"/redoc", "text/html; charset=utf-8", `<redoc spec-url="/openapi.json">`},
+	}
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			service := goa.New("test")
+			service.Use(func(goa.Handler) goa.Handler {
+				t.Error("service middleware applied to documentation handler")
+				return nil
+			})
+			apidocs.Mount(service, "/openapi.json", spec, apidocs.SwaggerUI("/docs"), apidocs.ReDoc("/redoc"))
+
+			rw := httptest.NewRecorder()
+			service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", c.Path, nil))
+
+			if rw.Code != http.StatusOK {
+				t.Errorf("got status %d, expected %d", rw.Code, http.StatusOK)
+			}
+			if ct := rw.Header().Get("Content-Type"); ct != c.ContentType {
+				t.Errorf("got content type %q, expected %q", ct, c.ContentType)
+			}
+			if body := rw.Body.String(); !strings.Contains(body, c.Body) {
+				t.Errorf("got body %q, expected it to contain %q", body, c.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	// BreakerClosed is the state of circuit breakers that let requests through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen is the state of circuit breakers that fail requests immediately.
+	BreakerOpen
+	// BreakerHalfOpen is the state of circuit breakers that let a limited number of probe
+	// requests through to test whether the service has recovered.
+	BreakerHalfOpen
+)
+
+// ErrCircuitOpen is the error returned by the Doer created with BreakerDoer when the circuit
+// breaker of the endpoint is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type (
+	// BreakerState is the state of a circuit breaker.
+	BreakerState int
+
+	// BreakerOption configures the circuit breakers created by BreakerDoer.
+	BreakerOption func(*breakerOptions)
+
+	// breakerOptions contains the circuit breaker settings.
+	breakerOptions struct {
+		threshold int
+		timeout   time.Duration
+		probes    int
+		isFailure func(*http.Response, error) bool
+		onChange  func(endpoint string, from, to BreakerState)
+	}
+
+	// breakerDoer is the Doer returned by BreakerDoer.
+	breakerDoer struct {
+		Doer
+		opts     *breakerOptions
+		mu       sync.Mutex
+		breakers map[string]*breaker
+	}
+
+	// breaker is the circuit breaker of an endpoint.
+	breaker struct {
+		state    BreakerState
+		failures int
+		openedAt time.Time
+		inflight int
+		probed   int
+	}
+)
+
+// FailureThreshold sets the number of consecutive failures that opens the circuit breaker, 5 by
+// default.
+func FailureThreshold(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		o.threshold = n
+	}
+}
+
+// OpenTimeout sets the duration the circuit breaker stays open before letting probe requests
+// through, 30 seconds by default.
+func OpenTimeout(d time.Duration) BreakerOption {
+	return func(o *breakerOptions) {
+		o.timeout = d
+	}
+}
+
+// HalfOpenProbes sets the number of probe requests let through by a half-open circuit breaker,
+// 1 by default. The circuit breaker closes once all the probes succeed and opens again as soon
+// as one fails.
+func HalfOpenProbes(n int) BreakerOption {
+	return func(o *breakerOptions) {
+		o.probes = n
+	}
+}
+
+// FailureFunc sets the function used to decide whether a request failed. By default requests
+// fail if they return an error or a response with a 5xx status code.
+func FailureFunc(f func(*http.Response, error) bool) BreakerOption {
+	return func(o *breakerOptions) {
+		o.isFailure = f
+	}
+}
+
+// OnStateChange registers a function called each time the circuit breaker of an endpoint
+// changes state, for example to record metrics. The function must not block.
+func OnStateChange(f func(endpoint string, from, to BreakerState)) BreakerOption {
+	return func(o *breakerOptions) {
+		o.onChange = f
+	}
+}
+
+// BreakerDoer wraps d with circuit breakers so that requests sent to failing endpoints fail fast
+// with ErrCircuitOpen instead of waiting on an unavailable service. Each endpoint gets its own
+// circuit breaker, endpoints are identified with ContextEndpoint.
+func BreakerDoer(d Doer, opts ...BreakerOption) Doer {
+	o := &breakerOptions{
+		threshold: 5,
+		timeout:   30 * time.Second,
+		probes:    1,
+		isFailure: func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &breakerDoer{Doer: d, opts: o, breakers: make(map[string]*breaker)}
+}
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Do sends the request if the circuit breaker of the endpoint allows it and records the outcome.
+func (d *breakerDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	endpoint := ContextEndpoint(ctx)
+	allowed, probe := d.allow(endpoint, time.Now())
+	if !allowed {
+		return nil, ErrCircuitOpen
+	}
+	resp, err := d.Doer.Do(ctx, req)
+	d.record(endpoint, probe, d.opts.isFailure(resp, err))
+	return resp, err
+}
+
+// allow returns true if the circuit breaker of the endpoint lets the request through and whether
+// the request is a half-open probe.
+func (d *breakerDoer) allow(endpoint string, now time.Time) (allowed, probe bool) {
+	d.mu.Lock()
+	b, ok := d.breakers[endpoint]
+	if !ok {
+		b = &breaker{}
+		d.breakers[endpoint] = b
+	}
+	from := b.state
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= d.opts.timeout {
+		b.state = BreakerHalfOpen
+		b.inflight, b.probed = 0, 0
+	}
+	switch b.state {
+	case BreakerClosed:
+		allowed = true
+	case BreakerHalfOpen:
+		if b.inflight+b.probed < d.opts.probes {
+			b.inflight++
+			allowed, probe = true, true
+		}
+	}
+	to := b.state
+	d.mu.Unlock()
+	d.notify(endpoint, from, to)
+	return
+}
+
+// record updates the circuit breaker of the endpoint with the outcome of a request. The outcome
+// of requests sent before the circuit breaker changed state is ignored.
+func (d *breakerDoer) record(endpoint string, probe, failed bool) {
+	d.mu.Lock()
+	b := d.breakers[endpoint]
+	from := b.state
+	switch {
+	case b.state == BreakerClosed && !probe:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= d.opts.threshold {
+			b.state, b.openedAt = BreakerOpen, time.Now()
+		}
+	case b.state == BreakerHalfOpen && probe:
+		b.inflight--
+		if failed {
+			b.state, b.openedAt = BreakerOpen, time.Now()
+		} else if b.probed++; b.probed >= d.opts.probes {
+			b.state, b.failures = BreakerClosed, 0
+		}
+	}
+	to := b.state
+	d.mu.Unlock()
+	d.notify(endpoint, from, to)
+}
+
+// notify calls the state change callback if the state changed.
+func (d *breakerDoer) notify(endpoint string, from, to BreakerState) {
+	if from != to && d.opts.onChange != nil {
+		d.opts.onChange(endpoint, from, to)
+	}
+}
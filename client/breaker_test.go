@@ -0,0 +1,83 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type doerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return f(ctx, req)
+}
+
+var _ = Describe("BreakerDoer", func() {
+	var (
+		fail    bool
+		calls   int
+		changes []string
+		doer    client.Doer
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		fail, calls, changes = true, 0, nil
+		ctx = client.SetContextEndpoint(context.Background(), "bottle.show")
+		d := doerFunc(func(context.Context, *http.Request) (*http.Response, error) {
+			calls++
+			if fail {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+		doer = client.BreakerDoer(d,
+			client.FailureThreshold(2),
+			client.OpenTimeout(10*time.Millisecond),
+			client.OnStateChange(func(endpoint string, from, to client.BreakerState) {
+				changes = append(changes, endpoint+": "+from.String()+" -> "+to.String())
+			}))
+	})
+
+	do := func(ctx context.Context) error {
+		req, _ := http.NewRequest("GET", "http://example.com", nil)
+		_, err := doer.Do(ctx, req)
+		return err
+	}
+
+	It("opens after consecutive failures and closes after a successful probe", func() {
+		Ω(do(ctx)).ShouldNot(MatchError(client.ErrCircuitOpen))
+		Ω(do(ctx)).ShouldNot(MatchError(client.ErrCircuitOpen))
+		Ω(do(ctx)).Should(MatchError(client.ErrCircuitOpen))
+		Ω(calls).Should(Equal(2))
+
+		By("keeping the circuit breakers of other endpoints closed")
+		Ω(do(context.Background())).ShouldNot(MatchError(client.ErrCircuitOpen))
+
+		time.Sleep(20 * time.Millisecond)
+		fail = false
+		Ω(do(ctx)).ShouldNot(HaveOccurred())
+		Ω(do(ctx)).ShouldNot(HaveOccurred())
+		Ω(changes).Should(Equal([]string{
+			"bottle.show: closed -> open",
+			"bottle.show: open -> half-open",
+			"bottle.show: half-open -> closed",
+		}))
+	})
+
+	It("opens again when the probe fails", func() {
+		do(ctx)
+		do(ctx)
+		time.Sleep(20 * time.Millisecond)
+		Ω(do(ctx)).Should(HaveOccurred())
+		Ω(do(ctx)).Should(MatchError(client.ErrCircuitOpen))
+		Ω(changes).Should(HaveLen(3))
+		Ω(changes[2]).Should(Equal("bottle.show: half-open -> open"))
+	})
+})
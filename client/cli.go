@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"golang.org/x/net/websocket"
 )
@@ -73,6 +74,35 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 	os.Exit(exitStatus)
 }
 
+// SetPayloadField sets the attribute name of the JSON object encoded in payload to value and
+// returns the resulting JSON. value is used as is if isString is true, it must be a JSON literal
+// otherwise. This makes it possible for generated command line tools to expose payload attributes
+// as individual flags and to combine them with a JSON payload.
+func SetPayloadField(payload, name, value string, isString bool) (string, error) {
+	obj := make(map[string]interface{})
+	if payload != "" {
+		dec := json.NewDecoder(strings.NewReader(payload))
+		dec.UseNumber()
+		if err := dec.Decode(&obj); err != nil {
+			return "", fmt.Errorf("failed to deserialize payload: %s", err)
+		}
+	}
+	var val interface{} = value
+	if !isString {
+		dec := json.NewDecoder(strings.NewReader(value))
+		dec.UseNumber()
+		if err := dec.Decode(&val); err != nil {
+			return "", fmt.Errorf("invalid value for %s: %s", name, err)
+		}
+	}
+	obj[name] = val
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 // WSWrite sends STDIN lines to a websocket server.
 func WSWrite(ws *websocket.Conn) {
 	scanner := bufio.NewScanner(os.Stdin)
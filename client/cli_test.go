@@ -0,0 +1,33 @@
+package client_test
+
+import (
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetPayloadField", func() {
+	It("sets string fields", func() {
+		p, err := client.SetPayloadField("", "name", "goa", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(MatchJSON(`{"name":"goa"}`))
+	})
+
+	It("merges JSON literals with the payload", func() {
+		p, err := client.SetPayloadField(`{"name":"goa","count":12345678901234567}`, "enabled", "true", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(MatchJSON(`{"name":"goa","count":12345678901234567,"enabled":true}`))
+	})
+
+	It("overrides existing fields", func() {
+		p, err := client.SetPayloadField(`{"count":1}`, "count", "2", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p).To(MatchJSON(`{"count":2}`))
+	})
+
+	It("rejects invalid values", func() {
+		_, err := client.SetPayloadField("", "count", "two", false)
+		Expect(err).To(HaveOccurred())
+	})
+})
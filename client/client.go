@@ -1,13 +1,10 @@
 package client
 
 import (
-	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"io"
-	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"time"
 
 	"context"
@@ -31,8 +28,13 @@ type (
 		Host string
 		// UserAgent is the user agent set in requests made by the client.
 		UserAgent string
-		// Dump indicates whether to dump request response.
+		// Dump indicates whether to dump the requests and responses at the debug level, see
+		// DebugDoer.
 		Dump bool
+		// ValidateResponses indicates whether the decoded responses are validated against
+		// the design validations. This makes it possible to detect differences between the
+		// service implementation and its design, for example during integration tests.
+		ValidateResponses bool
 	}
 )
 
@@ -62,7 +64,35 @@ func (f doFunc) Do(ctx context.Context, req *http.Request) (*http.Response, erro
 
 // Do wraps the underlying http client Do method and adds logging.
 // The logger should be in the context.
+//
+// If the context contains a timeout, see SetContextTimeout, Do cancels the request once the
+// timeout expires, including the reading of the response body. The request is given the context
+// if it has a deadline so that the deadline of the incoming request handled by a service
+// propagates to the requests the service makes.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if d := ContextTimeout(ctx); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		resp, err := c.do(ctx, req)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if resp.Body == nil {
+			cancel()
+			return resp, nil
+		}
+		resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+	return c.do(ctx, req)
+}
+
+// do sends the request and logs the response.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if _, ok := ctx.Deadline(); ok {
+		req = req.WithContext(ctx)
+	}
 	// TODO: setting the request ID should be done via client middleware. For now only set it if the
 	// caller provided one in the ctx.
 	if ctxreqid := ContextRequestID(ctx); ctxreqid != "" {
@@ -74,139 +104,30 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	startedAt := time.Now()
 	ctx, id := ContextWithRequestID(ctx)
 	goa.LogInfo(ctx, "started", "id", id, req.Method, req.URL.String())
+	doer := c.Doer
 	if c.Dump {
-		c.dumpRequest(ctx, req)
+		doer = DebugDoer(doer)
 	}
-	resp, err := c.Doer.Do(ctx, req)
+	resp, err := doer.Do(ctx, req)
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return nil, err
 	}
 	goa.LogInfo(ctx, "completed", "id", id, "status", resp.StatusCode, "time", time.Since(startedAt).String())
-	if c.Dump {
-		c.dumpResponse(ctx, resp)
-	}
 	return resp, err
 }
 
-// Dump request if needed.
-func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
-	reqBody, err := dumpReqBody(req)
-	if err != nil {
-		goa.LogError(ctx, "Failed to load request body for dump", "err", err.Error())
-	}
-	goa.LogInfo(ctx, "request headers", headersToSlice(req.Header)...)
-	if reqBody != nil {
-		goa.LogInfo(ctx, "request", "body", string(reqBody))
-	}
-}
-
-// dumpResponse dumps the response and the request.
-func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
-	respBody, _ := dumpRespBody(resp)
-	goa.LogInfo(ctx, "response headers", headersToSlice(resp.Header)...)
-	if respBody != nil {
-		goa.LogInfo(ctx, "response", "body", string(respBody))
-	}
-}
-
-// headersToSlice produces a loggable slice from a HTTP header.
-func headersToSlice(header http.Header) []interface{} {
-	res := make([]interface{}, 2*len(header))
-	i := 0
-	for k, v := range header {
-		res[i] = k
-		if len(v) == 1 {
-			res[i+1] = v[0]
-		} else {
-			res[i+1] = v
-		}
-		i += 2
-	}
-	return res
+// cancelBody cancels the request context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
 }
 
-// Dump request body, strongly inspired from httputil.DumpRequest
-func dumpReqBody(req *http.Request) ([]byte, error) {
-	if req.Body == nil {
-		return nil, nil
-	}
-	var save io.ReadCloser
-	var err error
-	save, req.Body, err = drainBody(req.Body)
-	if err != nil {
-		return nil, err
-	}
-	var b bytes.Buffer
-	var dest io.Writer = &b
-	chunked := len(req.TransferEncoding) > 0 && req.TransferEncoding[0] == "chunked"
-	if chunked {
-		dest = httputil.NewChunkedWriter(dest)
-	}
-	_, err = io.Copy(dest, req.Body)
-	if chunked {
-		dest.(io.Closer).Close()
-		io.WriteString(&b, "\r\n")
-	}
-	req.Body = save
-	return b.Bytes(), err
-}
-
-// Dump response body, strongly inspired from httputil.DumpResponse
-func dumpRespBody(resp *http.Response) ([]byte, error) {
-	if resp.Body == nil {
-		return nil, nil
-	}
-	var b bytes.Buffer
-	savecl := resp.ContentLength
-	var save io.ReadCloser
-	var err error
-	save, resp.Body, err = drainBody(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	_, err = io.Copy(&b, resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	resp.Body = save
-	resp.ContentLength = savecl
-	if err != nil {
-		return nil, err
-	}
-	return b.Bytes(), nil
-}
-
-// One of the copies, say from b to r2, could be avoided by using a more
-// elaborate trick where the other copy is made during Request/Response.Write.
-// This would complicate things too much, given that these functions are for
-// debugging only.
-func drainBody(b io.ReadCloser) (r1, r2 io.ReadCloser, err error) {
-	var buf bytes.Buffer
-	if _, err = buf.ReadFrom(b); err != nil {
-		return nil, nil, err
-	}
-	if err = b.Close(); err != nil {
-		return nil, nil, err
-	}
-	return ioutil.NopCloser(&buf), ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
-}
-
-// headerIterator is a HTTP header iterator.
-type headerIterator func(name string, value []string)
-
-// filterHeaders iterates through the headers skipping hidden headers.
-// It calls the given iterator for each header name/value pair. The values are serialized as
-// strings.
-func filterHeaders(headers http.Header, iterator headerIterator) {
-	for k, v := range headers {
-		// Skip sensitive headers
-		if k == "Authorization" || k == "Cookie" {
-			iterator(k, []string{"*****"})
-			continue
-		}
-		iterator(k, v)
-	}
+// Close closes the body and cancels the request context.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
 }
 
 // shortID produces a "unique" 6 bytes long string.
@@ -221,8 +142,14 @@ func shortID() string {
 // It is private to avoid possible collisions with keys used by other packages.
 type clientKey int
 
-// ReqIDKey is the context key used to store the request ID value.
-const reqIDKey clientKey = 1
+const (
+	// ReqIDKey is the context key used to store the request ID value.
+	reqIDKey clientKey = iota + 1
+	// endpointKey is the context key used to store the endpoint name.
+	endpointKey
+	// timeoutKey is the context key used to store the endpoint timeout.
+	timeoutKey
+)
 
 // ContextRequestID extracts the Request ID from the context.
 func ContextRequestID(ctx context.Context) string {
@@ -249,3 +176,35 @@ func ContextWithRequestID(ctx context.Context) (context.Context, string) {
 func SetContextRequestID(ctx context.Context, reqID string) context.Context {
 	return context.WithValue(ctx, reqIDKey, reqID)
 }
+
+// ContextEndpoint extracts the name of the endpoint called by the request from the context. The
+// generated clients set the endpoint name to "resource.action" using the resource and action
+// names defined in the design.
+func ContextEndpoint(ctx context.Context) string {
+	if e, ok := ctx.Value(endpointKey).(string); ok {
+		return e
+	}
+	return ""
+}
+
+// SetContextEndpoint sets the name of the endpoint called by the request in the given context and
+// returns a new context.
+func SetContextEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey, endpoint)
+}
+
+// ContextTimeout extracts the timeout of the endpoint called by the request from the context, 0
+// if there is none.
+func ContextTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(timeoutKey).(time.Duration); ok {
+		return d
+	}
+	return 0
+}
+
+// SetContextTimeout sets the timeout of the endpoint called by the request in the given context
+// and returns a new context. The generated clients set the timeout of the endpoints whose design
+// defines one, see Client.Do.
+func SetContextTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey, d)
+}
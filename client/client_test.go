@@ -2,6 +2,10 @@ package client_test
 
 import (
 	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
 	"github.com/goadesign/goa/client"
 
@@ -45,4 +49,37 @@ var _ = Describe("client", func() {
 			})
 		})
 	})
+
+	Context("with a timeout", func() {
+		It("cancels the request once the timeout expires", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				select {
+				case <-req.Context().Done():
+				case <-time.After(time.Second):
+				}
+			}))
+			defer server.Close()
+			c := client.New(nil)
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			ctx := client.SetContextTimeout(context.Background(), 10*time.Millisecond)
+			_, err := c.Do(ctx, req)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("lets the response body be read", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Write([]byte("ok"))
+			}))
+			defer server.Close()
+			c := client.New(nil)
+			req, _ := http.NewRequest("GET", server.URL, nil)
+			ctx := client.SetContextTimeout(context.Background(), time.Second)
+			resp, err := c.Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("ok"))
+		})
+	})
 })
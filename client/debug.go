@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/goadesign/goa"
+)
+
+// redacted is the value logged in place of the values of redacted headers.
+const redacted = "[REDACTED]"
+
+type (
+	// DebugOption configures the round tripper created by DebugTransport and the Doer created
+	// by DebugDoer.
+	DebugOption func(*debugOptions)
+
+	// debugOptions contains the debug settings.
+	debugOptions struct {
+		maxBodySize int
+		redacted    map[string]bool
+		logger      goa.LogAdapter
+	}
+
+	// debugTransport is the round tripper returned by DebugTransport.
+	debugTransport struct {
+		rt   http.RoundTripper
+		opts *debugOptions
+	}
+
+	// debugDoer is the Doer returned by DebugDoer.
+	debugDoer struct {
+		Doer
+		opts *debugOptions
+	}
+
+	// peekedBody replays the bytes read from a body before reading the rest of it.
+	peekedBody struct {
+		io.Reader
+		io.Closer
+	}
+)
+
+// DebugMaxBodySize sets the maximum number of bytes of the request and response bodies that get
+// logged, 4KB by default. Larger bodies are truncated, bodies are not logged if n is 0 or less.
+func DebugMaxBodySize(n int) DebugOption {
+	return func(o *debugOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// DebugRedactHeaders causes the values of the request and response headers with the given names
+// to be logged as "[REDACTED]". The Authorization, Proxy-Authorization, Cookie and Set-Cookie
+// headers are always redacted.
+func DebugRedactHeaders(names ...string) DebugOption {
+	return func(o *debugOptions) {
+		for _, n := range names {
+			o.redacted[http.CanonicalHeaderKey(n)] = true
+		}
+	}
+}
+
+// DebugLogger sets the logger used to dump the requests and responses. The logger stored in the
+// request context is used by default, see goa.WithLogger.
+func DebugLogger(logger goa.LogAdapter) DebugOption {
+	return func(o *debugOptions) {
+		o.logger = logger
+	}
+}
+
+// DebugTransport wraps rt so that the requests and their responses are dumped at the debug level,
+// see goa.LogDebug. If rt is nil DebugTransport wraps http.DefaultTransport. Use DebugTransport
+// with http.Client, see DebugDoer for clients that use a Doer.
+func DebugTransport(rt http.RoundTripper, opts ...DebugOption) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &debugTransport{rt: rt, opts: newDebugOptions(opts)}
+}
+
+// DebugDoer wraps d so that the requests and their responses are dumped at the debug level, see
+// DebugTransport.
+func DebugDoer(d Doer, opts ...DebugOption) Doer {
+	return &debugDoer{Doer: d, opts: newDebugOptions(opts)}
+}
+
+// RoundTrip dumps the request, sends it using the underlying round tripper and dumps the
+// response.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.opts.dump(req.Context(), req, t.rt.RoundTrip)
+}
+
+// Do dumps the request, sends it using the underlying Doer and dumps the response.
+func (d *debugDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return d.opts.dump(ctx, req, func(req *http.Request) (*http.Response, error) {
+		return d.Doer.Do(ctx, req)
+	})
+}
+
+// newDebugOptions returns the debug settings initialized with the defaults and the given options.
+func newDebugOptions(opts []DebugOption) *debugOptions {
+	o := &debugOptions{
+		maxBodySize: 4096,
+		redacted: map[string]bool{
+			"Authorization":       true,
+			"Proxy-Authorization": true,
+			"Cookie":              true,
+			"Set-Cookie":          true,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// dump logs req, sends it with send and logs the response. The request is not modified, the
+// request sent and the response returned read their body from the bytes consumed by the dump
+// before reading the rest of the original body.
+func (o *debugOptions) dump(ctx context.Context, req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	if o.logger != nil {
+		ctx = goa.WithLogger(ctx, o.logger)
+	}
+	id := ContextRequestID(ctx)
+	goa.LogDebug(ctx, "request", "id", id, "method", req.Method, "url", req.URL.String())
+	goa.LogDebug(ctx, "request headers", o.headers(req.Header)...)
+	if req.Body != nil && o.maxBodySize > 0 {
+		r := *req
+		body, err := o.peek(&r.Body)
+		if err != nil {
+			return nil, err
+		}
+		goa.LogDebug(ctx, "request body", "body", body)
+		req = &r
+	}
+
+	resp, err := send(req)
+	if err != nil {
+		goa.LogDebug(ctx, "response", "id", id, "err", err.Error())
+		return nil, err
+	}
+	goa.LogDebug(ctx, "response", "id", id, "status", resp.StatusCode)
+	goa.LogDebug(ctx, "response headers", o.headers(resp.Header)...)
+	if resp.Body != nil && o.maxBodySize > 0 {
+		body, err := o.peek(&resp.Body)
+		if err != nil {
+			goa.LogError(ctx, "failed to read response body for dump", "err", err.Error())
+		} else {
+			goa.LogDebug(ctx, "response body", "body", body)
+		}
+	}
+	return resp, nil
+}
+
+// peek reads up to the maximum body size from *body and replaces *body with a reader that
+// replays the bytes read. peek returns the bytes read formatted for logging.
+func (o *debugOptions) peek(body *io.ReadCloser) (string, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(*body, int64(o.maxBodySize)+1))
+	*body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(b), *body), Closer: *body}
+	if err != nil {
+		return "", err
+	}
+	if len(b) > o.maxBodySize {
+		return fmt.Sprintf("%s... (truncated)", b[:o.maxBodySize]), nil
+	}
+	return string(b), nil
+}
+
+// headers returns the given headers sorted by name as a list of key/value pairs with the values
+// of the sensitive headers redacted.
+func (o *debugOptions) headers(h http.Header) []interface{} {
+	names := make([]string, 0, len(h))
+	for n := range h {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	keyvals := make([]interface{}, 0, 2*len(names))
+	for _, n := range names {
+		var v interface{} = h[n]
+		if o.redacted[http.CanonicalHeaderKey(n)] {
+			v = redacted
+		} else if len(h[n]) == 1 {
+			v = h[n][0]
+		}
+		keyvals = append(keyvals, n, v)
+	}
+	return keyvals
+}
@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DebugTransport", func() {
+	var (
+		server *httptest.Server
+		logs   *bytes.Buffer
+		opts   []client.DebugOption
+		body   string
+		resp   *http.Response
+		err    error
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			b, _ := ioutil.ReadAll(req.Body)
+			rw.Header().Set("Set-Cookie", "session=secret")
+			rw.Write(b)
+		}))
+		logs = &bytes.Buffer{}
+		opts = []client.DebugOption{client.DebugLogger(goa.NewLogger(log.New(logs, "", 0)))}
+		body = "hello"
+	})
+
+	JustBeforeEach(func() {
+		c := &http.Client{Transport: client.DebugTransport(nil, opts...)}
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Api-Key", "secret")
+		resp, err = c.Do(req)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("dumps the request and response and redacts the sensitive headers", func() {
+		Ω(err).ShouldNot(HaveOccurred())
+		b, _ := ioutil.ReadAll(resp.Body)
+		Ω(string(b)).Should(Equal("hello"))
+		Ω(logs.String()).Should(ContainSubstring("POST"))
+		Ω(logs.String()).Should(ContainSubstring("status=200"))
+		Ω(logs.String()).Should(ContainSubstring("Authorization=[REDACTED]"))
+		Ω(logs.String()).Should(ContainSubstring("Set-Cookie=[REDACTED]"))
+		Ω(logs.String()).Should(ContainSubstring("X-Api-Key=secret"))
+		Ω(strings.Count(logs.String(), "body=hello")).Should(Equal(2))
+	})
+
+	Context("with redacted headers and a body size limit", func() {
+		BeforeEach(func() {
+			opts = append(opts, client.DebugRedactHeaders("x-api-key"), client.DebugMaxBodySize(3))
+			body = "hello world"
+		})
+
+		It("redacts the headers and truncates the bodies", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			b, _ := ioutil.ReadAll(resp.Body)
+			Ω(string(b)).Should(Equal("hello world"))
+			Ω(logs.String()).Should(ContainSubstring("X-Api-Key=[REDACTED]"))
+			Ω(logs.String()).Should(ContainSubstring("body=hel... (truncated)"))
+			Ω(logs.String()).ShouldNot(ContainSubstring("hello world"))
+		})
+	})
+})
+
+var _ = Describe("DebugDoer", func() {
+	It("dumps the requests using the logger stored in the context", func() {
+		logs := &bytes.Buffer{}
+		ctx := goa.WithLogger(context.Background(), goa.NewLogger(log.New(logs, "", 0)))
+		d := client.DebugDoer(doerFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}}, nil
+		}))
+		req, _ := http.NewRequest("GET", "http://example.com/widgets", nil)
+		resp, err := d.Do(ctx, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(http.StatusNoContent))
+		Ω(logs.String()).Should(ContainSubstring("http://example.com/widgets"))
+		Ω(logs.String()).Should(ContainSubstring("status=204"))
+	})
+})
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryOption configures the Doer created by RetryDoer.
+	RetryOption func(*retryOptions)
+
+	// RetryPolicy overrides the retry settings of an endpoint, see EndpointRetry.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts including the first request,
+		// the RetryDoer setting is used if 0.
+		MaxAttempts int
+		// Idempotent indicates that the endpoint is idempotent and may be retried even
+		// though its HTTP method is not, for example a POST request that uses an
+		// idempotency key.
+		Idempotent bool
+		// Disabled prevents the requests sent to the endpoint from being retried.
+		Disabled bool
+	}
+
+	// retryOptions contains the retry settings.
+	retryOptions struct {
+		maxAttempts int
+		base        time.Duration
+		max         time.Duration
+		endpoints   map[string]RetryPolicy
+	}
+
+	// retryDoer is the Doer returned by RetryDoer.
+	retryDoer struct {
+		Doer
+		opts *retryOptions
+	}
+)
+
+// RetryMaxAttempts sets the maximum number of attempts including the first request, 3 by
+// default.
+func RetryMaxAttempts(n int) RetryOption {
+	return func(o *retryOptions) {
+		o.maxAttempts = n
+	}
+}
+
+// RetryBackoff sets the delay before the first retry and the maximum delay between two retries,
+// 100 milliseconds and 5 seconds by default. The delay doubles after each attempt.
+func RetryBackoff(base, max time.Duration) RetryOption {
+	return func(o *retryOptions) {
+		o.base = base
+		o.max = max
+	}
+}
+
+// EndpointRetry overrides the retry settings of the given endpoint, see ContextEndpoint. The
+// generated clients use EndpointRetry to apply the retry policies defined in the design.
+func EndpointRetry(endpoint string, p RetryPolicy) RetryOption {
+	return func(o *retryOptions) {
+		o.endpoints[endpoint] = p
+	}
+}
+
+// RetryDoer wraps d so that requests to idempotent endpoints are retried when they fail with an
+// error or a response with status code 429 or 5xx. The delay between attempts grows exponentially
+// with random jitter unless the response specifies a Retry-After header. Requests are idempotent
+// if their method is GET, HEAD, OPTIONS, TRACE, PUT or DELETE, if they have an Idempotency-Key
+// header or if their endpoint policy says so.
+// Requests whose body cannot be replayed, see http.Request GetBody, are never retried.
+func RetryDoer(d Doer, opts ...RetryOption) Doer {
+	o := &retryOptions{
+		maxAttempts: 3,
+		base:        100 * time.Millisecond,
+		max:         5 * time.Second,
+		endpoints:   make(map[string]RetryPolicy),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &retryDoer{Doer: d, opts: o}
+}
+
+// Do sends the request and retries it if it fails and may be retried.
+func (d *retryDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	p := d.opts.endpoints[ContextEndpoint(ctx)]
+	attempts := p.MaxAttempts
+	if attempts == 0 {
+		attempts = d.opts.maxAttempts
+	}
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	idempotent := p.Idempotent || isIdempotent(req.Method) || req.Header.Get("Idempotency-Key") != ""
+	if p.Disabled || !idempotent || !replayable {
+		return d.Doer.Do(ctx, req)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := d.Doer.Do(ctx, req)
+		if attempt >= attempts || ctx.Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		wait := d.backoff(attempt)
+		if resp != nil {
+			if ra, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = ra
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt: the exponential delay
+// capped to the maximum delay with jitter between half the delay and the delay.
+func (d *retryDoer) backoff(attempt int) time.Duration {
+	delay := d.opts.max
+	if attempt < 32 {
+		if exp := d.opts.base << uint(attempt-1); exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// isIdempotent returns true if requests using the given method are idempotent.
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// shouldRetry returns true if the request failed with a transient error.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter parses the value of a Retry-After header given either as a number of seconds
+// or as a HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
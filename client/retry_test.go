@@ -0,0 +1,100 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryDoer", func() {
+	var (
+		statuses []int
+		bodies   []string
+		doer     client.Doer
+		opts     []client.RetryOption
+	)
+
+	BeforeEach(func() {
+		statuses, bodies = nil, nil
+		opts = []client.RetryOption{client.RetryBackoff(time.Millisecond, 2*time.Millisecond)}
+	})
+
+	JustBeforeEach(func() {
+		d := doerFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
+			if req.Body != nil {
+				b, _ := ioutil.ReadAll(req.Body)
+				bodies = append(bodies, string(b))
+			}
+			if len(statuses) == 0 {
+				return nil, errors.New("connection refused")
+			}
+			status := statuses[0]
+			statuses = statuses[1:]
+			resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: ioutil.NopCloser(&bytes.Buffer{})}
+			if status == http.StatusTooManyRequests {
+				resp.Header.Set("Retry-After", "0")
+			}
+			return resp, nil
+		})
+		doer = client.RetryDoer(d, opts...)
+	})
+
+	do := func(ctx context.Context, method string) (*http.Response, error) {
+		req, _ := http.NewRequest(method, "http://example.com", bytes.NewBufferString("body"))
+		return doer.Do(ctx, req)
+	}
+
+	It("retries idempotent requests and replays the body", func() {
+		statuses = []int{503, 429, 200}
+		resp, err := do(context.Background(), "PUT")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(200))
+		Ω(bodies).Should(Equal([]string{"body", "body", "body"}))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		statuses = []int{500, 500, 500, 200}
+		resp, err := do(context.Background(), "GET")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(500))
+		Ω(statuses).Should(Equal([]int{200}))
+	})
+
+	It("does not retry non idempotent requests", func() {
+		_, err := do(context.Background(), "POST")
+		Ω(err).Should(HaveOccurred())
+		Ω(bodies).Should(HaveLen(1))
+	})
+
+	It("retries requests with an idempotency key", func() {
+		statuses = []int{503, 201}
+		req, _ := http.NewRequest("POST", "http://example.com", bytes.NewBufferString("body"))
+		req.Header.Set("Idempotency-Key", "abc")
+		resp, err := doer.Do(context.Background(), req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(resp.StatusCode).Should(Equal(201))
+		Ω(bodies).Should(HaveLen(2))
+	})
+
+	Context("with an endpoint policy", func() {
+		BeforeEach(func() {
+			opts = append(opts, client.EndpointRetry("bottle.create", client.RetryPolicy{MaxAttempts: 2, Idempotent: true}))
+		})
+
+		It("retries the endpoint requests", func() {
+			statuses = []int{502, 502, 200}
+			resp, err := do(client.SetContextEndpoint(context.Background(), "bottle.create"), "POST")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(resp.StatusCode).Should(Equal(502))
+			Ω(bodies).Should(HaveLen(2))
+		})
+	})
+})
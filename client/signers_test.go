@@ -0,0 +1,50 @@
+package client_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIKeySigner", func() {
+	var signer *client.APIKeySigner
+	var req *http.Request
+
+	BeforeEach(func() {
+		signer = &client.APIKeySigner{KeyName: "key", KeyValue: "secret", Format: "%s"}
+		req, _ = http.NewRequest("GET", "http://example.com", nil)
+	})
+
+	JustBeforeEach(func() {
+		Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+	})
+
+	It("sets the key in a header", func() {
+		Ω(req.Header.Get("key")).Should(Equal("secret"))
+	})
+
+	Context("signing query strings", func() {
+		BeforeEach(func() {
+			signer.SignQuery = true
+		})
+
+		It("sets the key in the query string", func() {
+			Ω(req.URL.Query().Get("key")).Should(Equal("secret"))
+		})
+	})
+
+	Context("signing cookies", func() {
+		BeforeEach(func() {
+			signer.SignCookie = true
+		})
+
+		It("sets the key in a cookie", func() {
+			c, err := req.Cookie("key")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(c.Value).Should(Equal("secret"))
+		})
+	})
+})
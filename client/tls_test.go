@@ -0,0 +1,49 @@
+package client_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/goadesign/goa/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TLSConfig", func() {
+	var (
+		server *httptest.Server
+		caFile string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+		f, err := ioutil.TempFile("", "ca")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		Expect(pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})).To(Succeed())
+		caFile = f.Name()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Remove(caFile)
+	})
+
+	It("verifies the server certificate with the CA file", func() {
+		cfg, err := client.TLSConfig("", "", caFile)
+		Expect(err).NotTo(HaveOccurred())
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+		resp, err := c.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+	})
+
+	It("returns an error if the client certificate cannot be loaded", func() {
+		_, err := client.TLSConfig("missing.pem", "missing-key.pem", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
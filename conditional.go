@@ -0,0 +1,86 @@
+package goa
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ComputeETag returns a weak entity tag computed from the JSON representation of v.
+func ComputeETag(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(b)
+	return `W/"` + hex.EncodeToString(sum[:]) + `"`, nil
+}
+
+// QuoteETag returns the entity tag for the given value, it adds the surrounding double quotes
+// if missing.
+func QuoteETag(v string) string {
+	if strings.HasSuffix(v, `"`) && (strings.HasPrefix(v, `"`) || strings.HasPrefix(v, `W/"`)) {
+		return v
+	}
+	return `"` + v + `"`
+}
+
+// NotModified returns true if the response to the given GET or HEAD request can be replaced
+// with a 304 Not Modified response given the response ETag and Last-Modified headers. The
+// If-None-Match request header is compared with the ETag header using the weak comparison
+// function and takes precedence over the If-Modified-Since header as described in RFC 7232.
+func NotModified(req *http.Request, h http.Header) bool {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		return false
+	}
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || weakETag(t) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lm.Truncate(time.Second).After(ims)
+}
+
+// CheckIfMatch returns an error of class ErrPreconditionFailed if the request has an If-Match
+// header that does not match the current entity tag of the resource using the strong comparison
+// function as described in RFC 7232. etag is the empty string if the resource does not exist.
+func CheckIfMatch(req *http.Request, etag string) error {
+	im := req.Header.Get("If-Match")
+	if im == "" {
+		return nil
+	}
+	for _, t := range strings.Split(im, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" && etag != "" {
+			return nil
+		}
+		if etag != "" && !strings.HasPrefix(t, "W/") && !strings.HasPrefix(etag, "W/") && t == QuoteETag(etag) {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed("resource has changed", "etag", etag)
+}
+
+// weakETag returns the opaque tag of the given entity tag stripped of the weakness indicator.
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
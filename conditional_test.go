@@ -0,0 +1,91 @@
+package goa_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ComputeETag", func() {
+	It("computes stable weak entity tags", func() {
+		etag, err := goa.ComputeETag(map[string]int{"a": 1})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(etag).Should(HavePrefix(`W/"`))
+		etag2, _ := goa.ComputeETag(map[string]int{"a": 1})
+		Ω(etag2).Should(Equal(etag))
+		etag3, _ := goa.ComputeETag(map[string]int{"a": 2})
+		Ω(etag3).ShouldNot(Equal(etag))
+	})
+})
+
+var _ = Describe("QuoteETag", func() {
+	It("quotes entity tags", func() {
+		Ω(goa.QuoteETag("v1")).Should(Equal(`"v1"`))
+		Ω(goa.QuoteETag(`"v1"`)).Should(Equal(`"v1"`))
+		Ω(goa.QuoteETag(`W/"v1"`)).Should(Equal(`W/"v1"`))
+	})
+})
+
+var _ = Describe("NotModified", func() {
+	var req *http.Request
+	var h http.Header
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("GET", "/bottles/1", nil)
+		h = make(http.Header)
+		h.Set("ETag", `"v1"`)
+		h.Set("Last-Modified", time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+	})
+
+	It("compares If-None-Match with the ETag", func() {
+		req.Header.Set("If-None-Match", `"v0", W/"v1"`)
+		Ω(goa.NotModified(req, h)).Should(BeTrue())
+		req.Header.Set("If-None-Match", `"v2"`)
+		Ω(goa.NotModified(req, h)).Should(BeFalse())
+	})
+
+	It("compares If-Modified-Since with Last-Modified", func() {
+		req.Header.Set("If-Modified-Since", time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		Ω(goa.NotModified(req, h)).Should(BeTrue())
+		req.Header.Set("If-Modified-Since", time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		Ω(goa.NotModified(req, h)).Should(BeFalse())
+	})
+
+	It("ignores requests that are not GET or HEAD", func() {
+		req.Method = "PUT"
+		req.Header.Set("If-None-Match", `"v1"`)
+		Ω(goa.NotModified(req, h)).Should(BeFalse())
+	})
+})
+
+var _ = Describe("CheckIfMatch", func() {
+	var req *http.Request
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("PUT", "/bottles/1", nil)
+	})
+
+	It("accepts requests without If-Match header", func() {
+		Ω(goa.CheckIfMatch(req, "v1")).ShouldNot(HaveOccurred())
+	})
+
+	It("accepts matching entity tags", func() {
+		req.Header.Set("If-Match", `"v0", "v1"`)
+		Ω(goa.CheckIfMatch(req, "v1")).ShouldNot(HaveOccurred())
+	})
+
+	It("rejects entity tags that do not match", func() {
+		req.Header.Set("If-Match", `"v0"`)
+		err := goa.CheckIfMatch(req, "v1")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(412))
+	})
+
+	It("rejects * when the resource does not exist", func() {
+		req.Header.Set("If-Match", "*")
+		Ω(goa.CheckIfMatch(req, "")).Should(HaveOccurred())
+	})
+})
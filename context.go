@@ -19,6 +19,9 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	errIDKey
+	routeKey
+	endpointMiddlewareKey
 )
 
 type (
@@ -71,6 +74,13 @@ func WithAction(ctx context.Context, action string) context.Context {
 	return context.WithValue(ctx, actionKey, action)
 }
 
+// WithRoute creates a context with the given route path pattern. ServeMux implementations call
+// WithRoute on the request context so that middlewares can retrieve the route matched by the
+// request with ContextRoute.
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
 // WithLogger sets the request context logger and returns the resulting new context.
 func WithLogger(ctx context.Context, logger LogAdapter) context.Context {
 	return context.WithValue(ctx, logKey, logger)
@@ -92,6 +102,19 @@ func WithError(ctx context.Context, err error) context.Context {
 	return context.WithValue(ctx, errKey, err)
 }
 
+// ContextWithErrorID allocates a new error ID and stores it in the context. Middlewares that need
+// to correlate their output with the error responses (e.g. logging or tracing middlewares) call
+// ContextWithErrorID prior to calling the next handler, the ErrorHandler middleware then uses the
+// same ID in the error response. If the context already holds an error ID it is returned
+// unchanged together with the existing ID.
+func ContextWithErrorID(ctx context.Context) (context.Context, string) {
+	if id := ContextErrorID(ctx); id != "" {
+		return ctx, id
+	}
+	id := newErrorID()
+	return context.WithValue(ctx, errIDKey, id), id
+}
+
 // ContextController extracts the controller name from the given context.
 func ContextController(ctx context.Context) string {
 	if c := ctx.Value(ctrlKey); c != nil {
@@ -108,6 +131,15 @@ func ContextAction(ctx context.Context) string {
 	return "<unknown>"
 }
 
+// ContextRoute extracts the path pattern of the route that matched the request, e.g.
+// "/bottles/:id", from the given context. It returns an empty string if the route is unknown.
+func ContextRoute(ctx context.Context) string {
+	if r := ctx.Value(routeKey); r != nil {
+		return r.(string)
+	}
+	return ""
+}
+
 // ContextRequest extracts the request data from the given context.
 func ContextRequest(ctx context.Context) *RequestData {
 	if r := ctx.Value(reqKey); r != nil {
@@ -140,6 +172,15 @@ func ContextError(ctx context.Context) error {
 	return nil
 }
 
+// ContextErrorID extracts the error ID allocated with ContextWithErrorID from the given context.
+// It returns an empty string if no ID was allocated.
+func ContextErrorID(ctx context.Context) string {
+	if id := ctx.Value(errIDKey); id != nil {
+		return id.(string)
+	}
+	return ""
+}
+
 // SwitchWriter overrides the underlying response writer. It returns the response
 // writer that was previously set.
 func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter {
@@ -148,6 +189,13 @@ func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter
 	return rwo
 }
 
+// Flush sends any buffered data to the client if the underlying response writer supports it.
+func (r *ResponseData) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Written returns true if the response was written, false otherwise.
 func (r *ResponseData) Written() bool {
 	return r.Status != 0
@@ -56,3 +56,15 @@ var _ = Describe("ResponseData", func() {
 		})
 	})
 })
+
+var _ = Describe("ContextWithErrorID", func() {
+	It("allocates the error ID once", func() {
+		ctx := context.Background()
+		Ω(goa.ContextErrorID(ctx)).Should(BeEmpty())
+		ctx, id := goa.ContextWithErrorID(ctx)
+		Ω(id).ShouldNot(BeEmpty())
+		Ω(goa.ContextErrorID(ctx)).Should(Equal(id))
+		_, id2 := goa.ContextWithErrorID(ctx)
+		Ω(id2).Should(Equal(id))
+	})
+})
@@ -88,31 +88,35 @@ var (
 	// KnownEncoders contains the list of encoding packages and factories known by goa indexed
 	// by MIME type.
 	KnownEncoders = map[string]string{
-		"application/json":      "github.com/goadesign/goa",
-		"application/xml":       "github.com/goadesign/goa",
-		"application/gob":       "github.com/goadesign/goa",
-		"application/x-gob":     "github.com/goadesign/goa",
-		"application/binc":      "github.com/goadesign/goa/encoding/binc",
-		"application/x-binc":    "github.com/goadesign/goa/encoding/binc",
-		"application/cbor":      "github.com/goadesign/goa/encoding/cbor",
-		"application/x-cbor":    "github.com/goadesign/goa/encoding/cbor",
-		"application/msgpack":   "github.com/goadesign/goa/encoding/msgpack",
-		"application/x-msgpack": "github.com/goadesign/goa/encoding/msgpack",
+		"application/json":                  "github.com/goadesign/goa",
+		"application/xml":                   "github.com/goadesign/goa",
+		"application/gob":                   "github.com/goadesign/goa",
+		"application/x-gob":                 "github.com/goadesign/goa",
+		"application/binc":                  "github.com/goadesign/goa/encoding/binc",
+		"application/x-binc":                "github.com/goadesign/goa/encoding/binc",
+		"application/msgpack":               "github.com/goadesign/goa/encoding/msgpack",
+		"application/x-msgpack":             "github.com/goadesign/goa/encoding/msgpack",
+		"application/protobuf":              "github.com/goadesign/goa/encoding/protobuf",
+		"application/x-protobuf":            "github.com/goadesign/goa/encoding/protobuf",
+		"application/x-ndjson":              "github.com/goadesign/goa",
+		"application/x-www-form-urlencoded": "github.com/goadesign/goa",
 	}
 
 	// KnownEncoderFunctions contains the list of encoding encoder and decoder functions known
 	// by goa indexed by MIME type.
 	KnownEncoderFunctions = map[string][2]string{
-		"application/json":      {"NewJSONEncoder", "NewJSONDecoder"},
-		"application/xml":       {"NewXMLEncoder", "NewXMLDecoder"},
-		"application/gob":       {"NewGobEncoder", "NewGobDecoder"},
-		"application/x-gob":     {"NewGobEncoder", "NewGobDecoder"},
-		"application/binc":      {"NewEncoder", "NewDecoder"},
-		"application/x-binc":    {"NewEncoder", "NewDecoder"},
-		"application/cbor":      {"NewEncoder", "NewDecoder"},
-		"application/x-cbor":    {"NewEncoder", "NewDecoder"},
-		"application/msgpack":   {"NewEncoder", "NewDecoder"},
-		"application/x-msgpack": {"NewEncoder", "NewDecoder"},
+		"application/json":                  {"NewJSONEncoder", "NewJSONDecoder"},
+		"application/xml":                   {"NewXMLEncoder", "NewXMLDecoder"},
+		"application/gob":                   {"NewGobEncoder", "NewGobDecoder"},
+		"application/x-gob":                 {"NewGobEncoder", "NewGobDecoder"},
+		"application/binc":                  {"NewEncoder", "NewDecoder"},
+		"application/x-binc":                {"NewEncoder", "NewDecoder"},
+		"application/msgpack":               {"NewEncoder", "NewDecoder"},
+		"application/x-msgpack":             {"NewEncoder", "NewDecoder"},
+		"application/protobuf":              {"NewEncoder", "NewDecoder"},
+		"application/x-protobuf":            {"NewEncoder", "NewDecoder"},
+		"application/x-ndjson":              {"NewNDJSONEncoder", "NewNDJSONDecoder"},
+		"application/x-www-form-urlencoded": {"NewFormEncoder", "NewFormDecoder"},
 	}
 
 	// JSONContentTypes list the Content-Type header values that cause goa to encode or decode
@@ -127,6 +131,30 @@ var (
 	// Gob by default.
 	GobContentTypes = []string{"application/gob", "application/x-gob"}
 
+	// MsgpackContentTypes list the Content-Type header values that cause goa to encode or
+	// decode MessagePack when listed in the Consumes or Produces DSL.
+	MsgpackContentTypes = []string{"application/msgpack", "application/x-msgpack"}
+
+	// CBORContentTypes list the Content-Type header values that cause goa to encode or decode
+	// CBOR when listed in the Consumes or Produces DSL.
+	CBORContentTypes = []string{"application/cbor", "application/x-cbor"}
+
+	// ProtobufContentTypes list the Content-Type header values that cause goa to encode or
+	// decode Protocol Buffers when listed in the Consumes or Produces DSL.
+	ProtobufContentTypes = []string{"application/x-protobuf", "application/protobuf"}
+
+	// YAMLContentTypes list the Content-Type header values that cause goa to encode or decode
+	// YAML when listed in the Consumes or Produces DSL.
+	YAMLContentTypes = []string{"application/yaml", "application/x-yaml", "text/yaml"}
+
+	// NDJSONContentTypes list the Content-Type header values that cause goa to encode or
+	// decode newline delimited JSON when listed in the Consumes or Produces DSL.
+	NDJSONContentTypes = []string{"application/x-ndjson"}
+
+	// FormContentTypes list the Content-Type header values that cause goa to decode HTML form
+	// posts by default.
+	FormContentTypes = []string{"application/x-www-form-urlencoded"}
+
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
@@ -185,6 +213,67 @@ var (
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
 	}
+
+	// JobMediaIdentifier is the media type identifier used for the job status responses of
+	// asynchronous actions.
+	JobMediaIdentifier = "application/vnd.goa.job"
+
+	// JobMedia is the built-in media type describing the jobs started by asynchronous
+	// actions, see the Async DSL.
+	JobMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        jobMediaType,
+				Description: "Status of a long running job",
+				Validation:  &dslengine.ValidationDefinition{Required: []string{"id", "status"}},
+				Example: map[string]interface{}{
+					"id":       "0b9b3a7e-9d6a-4a8e-8d3c-2f7e5a6b1c4d",
+					"status":   "running",
+					"progress": 40,
+					"message":  "Exporting bottles",
+					"href":     "/bottles/export/jobs/0b9b3a7e-9d6a-4a8e-8d3c-2f7e5a6b1c4d",
+				},
+			},
+			TypeName: "GoaJob",
+		},
+		Identifier:  JobMediaIdentifier,
+		ContentType: JobMediaIdentifier,
+		Views:       map[string]*ViewDefinition{"default": jobMediaView},
+	}
+
+	jobMediaType = Object{
+		"id": &AttributeDefinition{
+			Type:        String,
+			Description: "Unique identifier of the job",
+		},
+		"status": &AttributeDefinition{
+			Type:        String,
+			Description: "Status of the job",
+			Validation: &dslengine.ValidationDefinition{
+				Values: []interface{}{"pending", "running", "succeeded", "failed"},
+			},
+		},
+		"progress": &AttributeDefinition{
+			Type:        Integer,
+			Description: "Completion percentage of the job",
+			Validation:  &dslengine.ValidationDefinition{Minimum: &jobProgressMin, Maximum: &jobProgressMax},
+		},
+		"message": &AttributeDefinition{
+			Type:        String,
+			Description: "Current step of the job or reason of its failure",
+		},
+		"href": &AttributeDefinition{
+			Type:        String,
+			Description: "Path to the job status",
+		},
+	}
+
+	jobMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: jobMediaType},
+		Name:                "default",
+	}
+
+	jobProgressMin, jobProgressMax = 0.0, 100.0
 )
 
 func init() {
@@ -198,8 +287,18 @@ func init() {
 		{MIMETypes: JSONContentTypes, PackagePath: goa, Function: "NewJSONDecoder"},
 		{MIMETypes: XMLContentTypes, PackagePath: goa, Function: "NewXMLDecoder"},
 		{MIMETypes: GobContentTypes, PackagePath: goa, Function: "NewGobDecoder"},
+		{MIMETypes: FormContentTypes, PackagePath: goa, Function: "NewFormDecoder"},
+	}
+	for _, ct := range CBORContentTypes {
+		KnownEncoders[ct] = "github.com/goadesign/goa/encoding/cbor"
+		KnownEncoderFunctions[ct] = [2]string{"NewEncoder", "NewDecoder"}
+	}
+	for _, ct := range YAMLContentTypes {
+		KnownEncoders[ct] = "github.com/goadesign/goa/encoding/yaml"
+		KnownEncoderFunctions[ct] = [2]string{"NewEncoder", "NewDecoder"}
 	}
 	errorMediaView.Parent = ErrorMedia
+	jobMediaView.Parent = JobMedia
 }
 
 // CanonicalIdentifier returns the media type identifier sans suffix
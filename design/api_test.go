@@ -36,6 +36,22 @@ var _ = Describe("CanonicalIdentifier", func() {
 	})
 })
 
+var _ = Describe("HasKnownEncoder", func() {
+	It("knows the CBOR content types", func() {
+		for _, ct := range design.CBORContentTypes {
+			Ω(design.HasKnownEncoder(ct)).Should(BeTrue())
+			Ω(design.KnownEncoders[ct]).Should(Equal("github.com/goadesign/goa/encoding/cbor"))
+		}
+	})
+
+	It("knows the YAML content types", func() {
+		for _, ct := range design.YAMLContentTypes {
+			Ω(design.HasKnownEncoder(ct)).Should(BeTrue())
+			Ω(design.KnownEncoders[ct]).Should(Equal("github.com/goadesign/goa/encoding/yaml"))
+		}
+	})
+})
+
 var _ = Describe("ExtractWildcards", func() {
 	var path string
 	var wcs []string
@@ -2,12 +2,23 @@ package apidsl
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 	"unicode"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
 
+// bracedWildcardRegex matches the wildcards written "{*name}" in file server request paths.
+var bracedWildcardRegex = regexp.MustCompile(`\{\*([a-zA-Z0-9_]+)\}`)
+
+// inResponseHeaders is true while the DSL given to Headers in a Response is being executed, Header
+// only maps response headers to media type attributes.
+var inResponseHeaders bool
+
 // Files used in: Resource
 //
 // Files defines an API endpoint that serves static assets. The logic for what to do when the
@@ -23,9 +34,13 @@ import (
 //	Files("/assets/*filepath", "/www/data/assets")
 //
 // returns the content of the file "/www/data/assets/x/y/z" when requests are sent to
-// "/assets/x/y/z".
+// "/assets/x/y/z". The wildcard may also be written "{*filepath}".
 // The file path may be specified as a relative path to the current path of the process.
-// Files support setting a description, security scheme and doc links via additional DSL:
+// The Content-Type header of the response is computed from the file extension or from the file
+// content, range and conditional requests are supported. Requests for a directory return the
+// content of the index.html file it contains if any.
+// Files support setting a description, security scheme, caching rules and doc links via
+// additional DSL:
 //
 //    Files("/index.html", "/www/data/index.html", func() {
 //        Description("Serve home page")
@@ -36,12 +51,13 @@ import (
 //        Security("oauth2", func() {
 //            Scope("api:read")
 //        })
+//        CacheControl(3600, "public")
 //    })
 func Files(path, filename string, dsls ...func()) {
 	if r, ok := resourceDefinition(); ok {
 		server := &design.FileServerDefinition{
 			Parent:      r,
-			RequestPath: path,
+			RequestPath: bracedWildcardRegex.ReplaceAllString(path, "*$1"),
 			FilePath:    filename,
 			Metadata:    make(dslengine.MetadataDefinition),
 		}
@@ -113,25 +129,149 @@ func Action(name string, dsl func()) {
 			return
 		}
 		r.Actions[name] = action
+		if action.Async && action.JobStatus == nil {
+			jobStatusAction(r, action)
+		}
 	}
 }
 
+// jobStatusAction defines the action that reports the status of the jobs started by the given
+// asynchronous action. The action is named after the asynchronous action with the "_status"
+// suffix and has one route per asynchronous action route made of the route path followed by
+// "/jobs/:jobID".
+func jobStatusAction(r *design.ResourceDefinition, a *design.ActionDefinition) {
+	name := a.Name + "_status"
+	if _, ok := r.Actions[name]; ok {
+		dslengine.ReportError("action %#v conflicts with the job status action of asynchronous action %#v", name, a.Name)
+		return
+	}
+	status := &design.ActionDefinition{
+		Parent:      r,
+		Name:        name,
+		Description: fmt.Sprintf("Retrieve the status of a job started by %s.", a.Name),
+		Security:    a.Security,
+		Metadata:    make(dslengine.MetadataDefinition),
+	}
+	dsl := func() {
+		for _, route := range a.Routes {
+			Routing(GET(strings.TrimSuffix(route.Path, "/") + "/jobs/:jobID"))
+		}
+		Params(func() {
+			Param("jobID", design.String, "Job ID")
+		})
+		Response(design.OK, design.JobMedia)
+		Response(design.NotFound)
+	}
+	if !dslengine.Execute(dsl, status) {
+		return
+	}
+	if a.Params != nil {
+		// Use the same definitions for the path parameters of the asynchronous action.
+		for _, route := range a.Routes {
+			for _, p := range route.Params() {
+				if att, ok := a.Params.Type.ToObject()[p]; ok {
+					status.Params.Type.ToObject()[p] = design.DupAtt(att)
+				}
+			}
+		}
+	}
+	r.Actions[name] = status
+	a.JobStatus = status
+}
+
 // Routing used in: Action
 //
 // Routing lists the action route. Each route is defined with a function named after the HTTP method.
 // The route function takes the path as argument. Route paths may use wildcards as described in the
 // [httptreemux](https://godoc.org/github.com/dimfeld/httptreemux) package documentation. These
 // wildcards define parameters using the `:name` or `*name` syntax where `:name` matches a path
-// segment and `*name` is a catch-all that matches the path until the end.
+// segment and `*name` is a catch-all that matches the path until the end. The parameters may also
+// be written `{name}` and `{*name}`. The `{name:pattern}` syntax defines a parameter whose values
+// must match the given regular expression, requests with values that do not match are rejected
+// with a validation error:
+//
+//	Routing(
+//		GET("/items/{id:[0-9]+}"),
+//		GET("/files/{*filepath}"),
+//	)
+//
+// The pattern is anchored so that it must match the entire parameter value. Patterns only apply to
+// string parameters. A parameter that appears in several routes must use the same pattern in all
+// of them. The pattern is added to the parameter validations and may not conflict with a pattern
+// set with Pattern in the parameter definition. The API and resource base paths may also define
+// patterns, see BasePath.
 func Routing(routes ...*design.RouteDefinition) {
 	if a, ok := actionDefinition(); ok {
 		for _, r := range routes {
+			path, patterns, err := parseRoutePath(r.Path)
+			if err != nil {
+				dslengine.ReportError("invalid route path %#v, %s", r.Path, err)
+				continue
+			}
+			r.Path = path
+			r.ParamPatterns = patterns
 			r.Parent = a
 			a.Routes = append(a.Routes, r)
 		}
 	}
 }
 
+// parseRoutePath converts the braced parameters of the given route path into the httptreemux
+// syntax, e.g. "/items/{id:[0-9]+}" into "/items/:id". It returns the anchored patterns of the
+// parameters that define one indexed by parameter name.
+func parseRoutePath(path string) (string, map[string]string, error) {
+	var (
+		res      strings.Builder
+		patterns map[string]string
+	)
+	for i := 0; i < len(path); i++ {
+		if path[i] != '{' {
+			res.WriteByte(path[i])
+			continue
+		}
+		// Look for the closing brace, patterns may contain braces e.g. "{code:[a-z]{3}}".
+		depth, end := 0, -1
+		for j := i; j < len(path) && end < 0; j++ {
+			switch path[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return "", nil, fmt.Errorf("missing closing brace")
+		}
+		name, pattern := path[i+1:end], ""
+		if idx := strings.Index(name, ":"); idx > -1 {
+			name, pattern = name[:idx], name[idx+1:]
+		}
+		wildcard := ":"
+		if strings.HasPrefix(name, "*") {
+			name, wildcard = name[1:], "*"
+		}
+		if name == "" {
+			return "", nil, fmt.Errorf("missing parameter name")
+		}
+		if pattern != "" {
+			pattern = "^(?:" + pattern + ")$"
+			if _, err := regexp.Compile(pattern); err != nil {
+				return "", nil, fmt.Errorf("invalid pattern of parameter %#v, %s", name, err)
+			}
+			if patterns == nil {
+				patterns = make(map[string]string)
+			}
+			patterns[name] = pattern
+		}
+		res.WriteString(wildcard + name)
+		i = end
+	}
+	return res.String(), patterns, nil
+}
+
 // GET is used as an argument to Routing
 //
 // GET creates a route using the GET HTTP method.
@@ -293,7 +433,10 @@ func Headers(params ...interface{}) {
 			default:
 				dslengine.ReportError("invalid use of Response or ResponseTemplate")
 			}
-			if dslengine.Execute(dsl, h) {
+			inResponseHeaders = true
+			executed := dslengine.Execute(dsl, h)
+			inResponseHeaders = false
+			if executed {
 				def.Headers = def.Headers.Merge(h)
 			}
 
@@ -316,6 +459,97 @@ func Headers(params ...interface{}) {
 	}
 }
 
+// Cookies can be used in: Action, Response
+//
+// Cookies implements the DSL for describing HTTP cookies. The DSL syntax is identical to the one
+// of Attribute, cookies must be primitives. Cookies can be used inside Action to define the
+// cookies read from the request or inside Response to define the cookies set by the response.
+// The CookieMaxAge, CookieSecure, CookieHTTPOnly and CookieSameSite functions define the
+// attributes of response cookies. Example:
+//
+//	Action("login", func() {
+//		Routing(POST("/login"))
+//		Cookies(func() {
+//			Cookie("remember_me", Boolean)
+//		})
+//		Response(NoContent, func() {
+//			Cookies(func() {
+//				Cookie("session_id", String, func() {
+//					CookieMaxAge(3600)
+//					CookieSecure()
+//					CookieHTTPOnly()
+//					CookieSameSite("strict")
+//				})
+//			})
+//		})
+//	})
+//
+// The generated action context exposes the request cookies as fields and a Set<Name>Cookie
+// method for each response cookie.
+func Cookies(dsl func()) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		cookies := newAttribute(def.Parent.MediaType)
+		if dslengine.Execute(dsl, cookies) {
+			def.Cookies = def.Cookies.Merge(cookies)
+		}
+
+	case *design.ResponseDefinition:
+		cookies := &design.AttributeDefinition{Type: design.Object{}}
+		if dslengine.Execute(dsl, cookies) {
+			def.Cookies = def.Cookies.Merge(cookies)
+		}
+
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// CookieMaxAge can be used in: Cookie
+//
+// CookieMaxAge sets the Max-Age attribute of a response cookie in seconds.
+func CookieMaxAge(seconds int) {
+	cookieAttribute("max-age", strconv.Itoa(seconds))
+}
+
+// CookieSecure can be used in: Cookie
+//
+// CookieSecure sets the Secure attribute of a response cookie.
+func CookieSecure() {
+	cookieAttribute("secure", "true")
+}
+
+// CookieHTTPOnly can be used in: Cookie
+//
+// CookieHTTPOnly sets the HttpOnly attribute of a response cookie.
+func CookieHTTPOnly() {
+	cookieAttribute("http-only", "true")
+}
+
+// CookieSameSite can be used in: Cookie
+//
+// CookieSameSite sets the SameSite attribute of a response cookie, mode must be one of "strict",
+// "lax" or "none".
+func CookieSameSite(mode string) {
+	switch mode {
+	case "strict", "lax", "none":
+		cookieAttribute("same-site", mode)
+	default:
+		dslengine.ReportError("invalid SameSite mode %#v, must be one of \"strict\", \"lax\" or \"none\"", mode)
+	}
+}
+
+// cookieAttribute records the response cookie attribute with the given name and value in the
+// cookie attribute definition metadata.
+func cookieAttribute(name, value string) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Metadata == nil {
+			a.Metadata = make(dslengine.MetadataDefinition)
+		}
+		a.Metadata["cookie:"+name] = []string{value}
+	}
+}
+
 // Params can be used in: Action, Resource, API
 //
 // Params describe the action parameters, either path parameters identified via wildcards or query
@@ -418,6 +652,538 @@ func OptionalPayload(p interface{}, dsls ...func()) {
 	payload(true, p, dsls...)
 }
 
+// StreamingPayload can be used in: Action
+//
+// StreamingPayload defines the type of the messages sent by the client once the action websocket
+// connection is established. The function accepts a type, a media type or the name of a type.
+// Actions that define a streaming payload or a streaming result are implemented using websockets,
+// the generated context exposes a Stream method that returns a stream with typed Recv and Send
+// methods and the generated client exposes a similar stream with typed Send and Recv methods.
+// Example:
+//
+//	Action("chat", func() {
+//		Routing(GET("/chat"))
+//		StreamingPayload(Message)	// Clients send Message instances
+//		StreamingResult(Message)	// Service sends Message instances
+//	})
+//
+func StreamingPayload(p interface{}) {
+	if a, ok := actionDefinition(); ok {
+		if ut := streamingType("StreamingPayload", p); ut != nil {
+			a.StreamingPayload = ut
+		}
+	}
+}
+
+// StreamingResult can be used in: Action
+//
+// StreamingResult defines the type of the messages sent by the service once the action websocket
+// connection is established. The function accepts a type, a media type, the name of a type or the
+// identifier of a media type. See StreamingPayload for an example.
+func StreamingResult(r interface{}) {
+	if a, ok := actionDefinition(); ok {
+		if ut := streamingType("StreamingResult", r); ut != nil {
+			a.StreamingResult = ut
+		}
+	}
+}
+
+// MultipartForm can be used in: Action
+//
+// MultipartForm specifies that the action request body is encoded using multipart/form-data.
+// The payload must be an object, its attributes are decoded from the form parts of the same
+// name. Attributes of type File are decoded from file parts into multipart.FileHeader values.
+// The generated code reads the whole request body prior to invoking the action and keeps at most
+// the number of bytes given by the goa.Service MaxMultipartMemory field (32MB by default) in
+// memory, the content of the file parts beyond that limit is stored in temporary files. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/upload"))
+//		MultipartForm()
+//		Payload(func() {
+//			Member("avatar", File, "Profile picture")
+//			Member("name", String)
+//			Required("avatar")
+//		})
+//	})
+//
+func MultipartForm() {
+	if a, ok := actionDefinition(); ok {
+		a.PayloadMultipart = true
+	}
+}
+
+// SkipRequestBodyEncodeDecode can be used in: Action
+//
+// SkipRequestBodyEncodeDecode specifies that the request body is not decoded by the generated
+// code. The generated context Body field gives the action implementation access to the request
+// body as it is read from the connection so that it can be proxied or stored without being
+// buffered in memory, the implementation is responsible for reading it. The action cannot
+// define a payload. The generated client method accepts an io.Reader and the content type of the
+// request body. Example:
+//
+//	Action("upload", func() {
+//		Routing(PUT("/files/:name"))
+//		Params(func() {
+//			Param("name", String)
+//		})
+//		SkipRequestBodyEncodeDecode()
+//		Response(NoContent)
+//	})
+//
+func SkipRequestBodyEncodeDecode() {
+	if a, ok := actionDefinition(); ok {
+		a.SkipRequestBodyEncodeDecode = true
+	}
+}
+
+// SkipResponseBodyEncodeDecode can be used in: Action
+//
+// SkipResponseBodyEncodeDecode specifies that the action implementation may write the response
+// body from an io.Reader rather than having the generated code encode a result. The generated
+// context WriteBody method copies the content of the reader to the response without buffering
+// it. The responses defined by the action are still generated and may be used to send errors.
+// Example:
+//
+//	Action("download", func() {
+//		Routing(GET("/files/:name"))
+//		Params(func() {
+//			Param("name", String)
+//		})
+//		SkipResponseBodyEncodeDecode()
+//		Response(OK, "application/octet-stream")
+//		Response(NotFound)
+//	})
+//
+func SkipResponseBodyEncodeDecode() {
+	if a, ok := actionDefinition(); ok {
+		a.SkipResponseBodyEncodeDecode = true
+	}
+}
+
+// MaxBodyLength can be used in: Action, Resource
+//
+// MaxBodyLength sets the maximum length in bytes of the request body. The generated code
+// returns a response with status code 413 (Request Entity Too Large) when the body is longer.
+// When used in a resource definition the limit applies to all the resource actions that don't
+// define one themselves. Example:
+//
+//	Action("upload", func() {
+//		Routing(POST("/upload"))
+//		Payload(UploadPayload)
+//		MaxBodyLength(1024 * 1024)	// 1MB
+//	})
+//
+func MaxBodyLength(n int64) {
+	if n <= 0 {
+		dslengine.ReportError("invalid maximum body length %d, must be greater than 0", n)
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.MaxBodyLength = n
+	case *design.ResourceDefinition:
+		def.MaxBodyLength = n
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// RateLimit can be used in: API, Resource, Action
+//
+// RateLimit limits the rate of requests to rps requests per second with bursts of up to burst
+// requests. The generated code rejects the requests that exceed the limit with a response with
+// status code 429 (Too Many Requests) and a Retry-After header. When used in an API or resource
+// definition the limit applies to each action that doesn't define one itself, each action gets
+// its own token bucket.
+//
+// By default all requests sent to an action share the same bucket. The optional DSL may use
+// ByIP or ByHeader to give each client its own bucket. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		RateLimit(10, 20, func() {
+//			ByHeader("X-API-Key")
+//		})
+//	})
+//
+func RateLimit(rps float64, burst int, dsl ...func()) {
+	if rps <= 0 || burst <= 0 {
+		dslengine.ReportError("invalid rate limit %v requests per second with burst %d, both must be greater than 0", rps, burst)
+		return
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to RateLimit")
+		return
+	}
+	rl := &design.RateLimitDefinition{RPS: rps, Burst: burst}
+	if len(dsl) == 1 {
+		if !dslengine.Execute(dsl[0], rl) {
+			return
+		}
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.RateLimit = rl
+	case *design.ResourceDefinition:
+		def.RateLimit = rl
+	case *design.ActionDefinition:
+		def.RateLimit = rl
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Timeout can be used in: Action
+//
+// Timeout sets the maximum duration of the requests handled by the action. The value is a
+// duration string as accepted by time.ParseDuration, e.g. "2s" or "500ms". The generated code
+// gives the action a context with the corresponding deadline and responds with status code 504
+// (Gateway Timeout) if the deadline expires before the action writes its response. The generated
+// clients cancel the requests to the action that take longer. Example:
+//
+//	Action("export", func() {
+//		Routing(POST("/export"))
+//		Timeout("30s")
+//	})
+//
+func Timeout(d string) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	timeout, err := time.ParseDuration(d)
+	if err != nil {
+		dslengine.ReportError("invalid timeout %q: %s", d, err)
+		return
+	}
+	if timeout <= 0 {
+		dslengine.ReportError("invalid timeout %q, must be greater than 0", d)
+		return
+	}
+	a.Timeout = timeout
+}
+
+// Interceptor can be used in: Resource, Action
+//
+// Interceptor runs the interceptor with the given name around the action or around all the
+// resource actions. Interceptors implement logic such as caching or auditing that needs typed
+// access to the action payload, parameters and result and that would otherwise end up in
+// transport middleware.
+//
+// The generated app package defines a <Name>Interceptor interface with one method per intercepted
+// action. The method receives the action context and a function that runs the action, it may
+// respond in place of the action by calling the context response methods without running it.
+// The context Result field holds the value given to the response method used by the action once
+// it ran. The interceptor is registered with Use<Name>Interceptor.
+//
+// The generated client package defines the client side counterpart of the interface, its methods
+// receive the request and the payload and a function that sends the request. The client runs the
+// interceptor set in its <Name>Interceptor field. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Interceptor("cache")
+//		Response(OK, BottleMedia)
+//	})
+//
+func Interceptor(name string) {
+	if name == "" {
+		dslengine.ReportError("interceptor name cannot be empty")
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Interceptors = append(def.Interceptors, name)
+	case *design.ActionDefinition:
+		def.Interceptors = append(def.Interceptors, name)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// KeepAlive can be used in: Action
+//
+// KeepAlive sets the interval between the ping frames sent over the websocket connections of an
+// action that defines a streaming payload or a streaming result. The value is a duration string
+// as accepted by time.ParseDuration. The peers answer the pings with pong frames which keeps the
+// connections open through proxies and detects the connections that were lost. Both the
+// generated service and client streams send pings. Example:
+//
+//	Action("chat", func() {
+//		Routing(GET("/chat"))
+//		StreamingPayload(Message)
+//		StreamingResult(Message)
+//		KeepAlive("30s")
+//	})
+//
+func KeepAlive(interval string) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		dslengine.ReportError("invalid keep alive interval %q: %s", interval, err)
+		return
+	}
+	if d <= 0 {
+		dslengine.ReportError("invalid keep alive interval %q, must be greater than 0", interval)
+		return
+	}
+	a.KeepAlive = d
+}
+
+// IdempotencyKey can be used in: Action
+//
+// IdempotencyKey specifies that the action accepts an Idempotency-Key request header that lets
+// clients retry unsafe requests without repeating their side effects. IdempotencyKey adds the
+// header to the action headers so that the generated context exposes its value in the
+// IdempotencyKey field and the generated client accepts it. Use the Idempotency middleware to
+// replay the stored responses to the requests that reuse a key. Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		IdempotencyKey()
+//		Payload(PaymentPayload)
+//	})
+//
+func IdempotencyKey() {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	a.IdempotencyKey = true
+	a.Headers = a.Headers.Merge(&design.AttributeDefinition{
+		Type: design.Object{
+			"Idempotency-Key": &design.AttributeDefinition{
+				Type:        design.String,
+				Description: "Unique key used to retry the request without repeating its side effects",
+			},
+		},
+	})
+}
+
+// Async can be used in: Action
+//
+// Async declares that the action starts a long running job rather than completing the request
+// synchronously. The action responds with status code 202 (Accepted) and a body using the
+// built-in JobMedia media type describing the job. Async also defines a job status action named
+// after the action with the "_status" suffix whose routes consist of the action route paths
+// followed by "/jobs/:jobID". The generated context of the action exposes an AcceptedJob method
+// that sets the Location response header to the path of the job status. The service implements
+// the job status action, typically using a goa.JobStore to track the progress of the jobs.
+// Example:
+//
+//	Action("export", func() {
+//		Routing(POST("/export"))
+//		Async()
+//	})
+//
+// defines the "export_status" action with route "GET /export/jobs/:jobID".
+func Async() {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	a.Async = true
+	if design.Design.MediaTypes == nil {
+		design.Design.MediaTypes = make(map[string]*design.MediaTypeDefinition)
+	}
+	design.Design.MediaTypes[design.CanonicalIdentifier(design.JobMediaIdentifier)] = design.JobMedia
+	Response(design.Accepted, design.JobMedia, func() {
+		Headers(func() {
+			Header("Location", design.String, "Path to the job status")
+		})
+	})
+}
+
+// ByIP can be used in: RateLimit
+//
+// ByIP gives each client IP address its own token bucket.
+func ByIP() {
+	if rl, ok := rateLimitDefinition(); ok {
+		rl.ByIP = true
+	}
+}
+
+// ByHeader can be used in: RateLimit
+//
+// ByHeader gives each value of the given request header its own token bucket, typically the
+// header containing the API key of the client.
+func ByHeader(name string) {
+	if rl, ok := rateLimitDefinition(); ok {
+		rl.Header = name
+	}
+}
+
+// SSE can be used in: Action
+//
+// SSE specifies that the action streams its results using Server-Sent Events rather than a
+// websocket connection. The action must define a streaming result and cannot define a streaming
+// payload. The generated context Stream method writes the event stream response headers and
+// returns a stream whose Send method writes and flushes one event per result. The stream
+// LastEventID field contains the value of the Last-Event-ID request header so that the action
+// implementation may resume the stream when clients reconnect. Example:
+//
+//	Action("listen", func() {
+//		Routing(GET("/events"))
+//		StreamingResult(Event)
+//		SSE()
+//	})
+//
+func SSE() {
+	if a, ok := actionDefinition(); ok {
+		a.ServerSentEvents = true
+	}
+}
+
+// NDJSON can be used in: Action
+//
+// NDJSON specifies that the action streams its results as newline delimited JSON in the response
+// body rather than over a websocket connection. The action must define a streaming result and
+// cannot define a streaming payload. The generated context Stream method writes the
+// application/x-ndjson response headers and returns a stream whose Send method writes and
+// flushes one line per result. The generated client includes an iterator that decodes the
+// results as they are received. Example:
+//
+//	Action("export", func() {
+//		Routing(GET("/export"))
+//		StreamingResult(Bottle)
+//		NDJSON()
+//	})
+//
+// Collection results may also be encoded as newline delimited JSON without defining a streaming
+// result by adding "application/x-ndjson" to the Produces DSL.
+func NDJSON() {
+	if a, ok := actionDefinition(); ok {
+		a.NDJSON = true
+	}
+}
+
+// IfMatch can be used in: Action
+//
+// IfMatch specifies that the action supports conditional requests using the If-Match header,
+// typically to prevent lost updates. IfMatch adds the If-Match header to the action headers and
+// the PreconditionFailed response to the action responses. The generated context CheckETag
+// method returns an error that results in a 412 Precondition Failed response if the header does
+// not match the current entity tag of the resource. Example:
+//
+//	Action("update", func() {
+//		Routing(PUT("/:id"))
+//		Payload(BottlePayload)
+//		IfMatch()
+//		Response(NoContent)
+//	})
+//
+func IfMatch() {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	Headers(func() {
+		Header("If-Match", design.String, "Entity tags the resource must match")
+	})
+	if _, ok := a.Responses[design.PreconditionFailed]; !ok {
+		Response(design.PreconditionFailed, design.ErrorMedia)
+	}
+	a.IfMatch = true
+}
+
+// Paginate can be used in: Action
+//
+// Paginate specifies that the action returns a paginated collection. style is one of:
+//
+//	"page":   the action accepts the "page" (starting at 1) and "page_size" query parameters
+//	"offset": the action accepts the "offset" (starting at 0) and "limit" query parameters
+//	"cursor": the action accepts the "cursor" and "limit" query parameters
+//
+// The optional argument sets the maximum page size, 100 by default. The page size defaults to
+// 20 or the maximum page size if lower. The generated context Paginate method sets the "Link"
+// response header (and the "X-Total-Count" header for the "page" and "offset" styles) and returns
+// the goa.Page describing the links to the other pages so that it can also be rendered in the
+// response body. Example:
+//
+//	Action("list", func() {
+//		Routing(GET(""))
+//		Paginate("page", 50)
+//		Response(OK, CollectionOf(BottleMedia))
+//	})
+//
+func Paginate(style string, maxPageSize ...int) {
+	a, ok := actionDefinition()
+	if !ok {
+		return
+	}
+	max := 100
+	if len(maxPageSize) > 0 {
+		max = maxPageSize[0]
+	}
+	if max < 1 {
+		dslengine.ReportError("invalid maximum page size %d, must be greater than 0", max)
+		return
+	}
+	size := 20
+	if max < size {
+		size = max
+	}
+	sizeName := "limit"
+	switch style {
+	case "page":
+		sizeName = "page_size"
+		Params(func() {
+			Param("page", design.Integer, "Page number", func() {
+				Minimum(1)
+				Default(1)
+			})
+		})
+	case "offset":
+		Params(func() {
+			Param("offset", design.Integer, "Index of the first item of the page", func() {
+				Minimum(0)
+				Default(0)
+			})
+		})
+	case "cursor":
+		Params(func() {
+			Param("cursor", design.String, "Opaque cursor returned by the previous page")
+		})
+	default:
+		dslengine.ReportError(`invalid pagination style %#v, must be one of "page", "offset" or "cursor"`, style)
+		return
+	}
+	Params(func() {
+		Param(sizeName, design.Integer, "Maximum number of items in the page", func() {
+			Minimum(1)
+			Maximum(max)
+			Default(size)
+		})
+	})
+	a.Pagination = style
+}
+
+// streamingType returns the user type described by the argument of StreamingPayload or
+// StreamingResult.
+func streamingType(dsl string, t interface{}) *design.UserTypeDefinition {
+	switch actual := t.(type) {
+	case *design.UserTypeDefinition:
+		return actual
+	case *design.MediaTypeDefinition:
+		return actual.UserTypeDefinition
+	case string:
+		if ut, ok := design.Design.Types[actual]; ok {
+			return ut
+		}
+		if mt := design.Design.MediaTypeWithIdentifier(actual); mt != nil {
+			return mt.UserTypeDefinition
+		}
+		dslengine.ReportError("unknown %s type %s", dsl, actual)
+	default:
+		dslengine.ReportError("invalid %s argument, must be a type, a media type or the name of a type", dsl)
+	}
+	return nil
+}
+
 func payload(isOptional bool, p interface{}, dsls ...func()) {
 	if len(dsls) > 1 {
 		dslengine.ReportError("too many arguments given to Payload")
@@ -2,6 +2,7 @@ package apidsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -112,6 +113,103 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with braced route parameters", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/items/{id:[0-9]+}/files/{*filepath}"))
+			}
+		})
+
+		It("converts the parameters and constrains the values with the pattern", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Routes[0].Path).Should(Equal("/items/:id/files/*filepath"))
+			Ω(action.Routes[0].ParamPatterns).Should(Equal(map[string]string{"id": "^(?:[0-9]+)$"}))
+			params := action.Params.Type.ToObject()
+			Ω(params).Should(HaveKey("id"))
+			Ω(params).Should(HaveKey("filepath"))
+			Ω(params["id"].Validation).ShouldNot(BeNil())
+			Ω(params["id"].Validation.Pattern).Should(Equal("^(?:[0-9]+)$"))
+			Ω(params["filepath"].Validation).Should(BeNil())
+		})
+
+		Context("with an invalid pattern", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/items/{id:[0-9}"))
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a pattern on a non string parameter", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/items/{id:[0-9]+}"))
+					Params(func() {
+						Param("id", Integer)
+					})
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with routes defining different patterns", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(
+						GET("/items/{id:[0-9]+}"),
+						GET("/things/{id:[a-z]+}"),
+					)
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with routes defining the same pattern", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(
+						GET("/items/{id:[0-9]+}"),
+						GET("/things/{id:[0-9]+}"),
+					)
+				}
+			})
+
+			It("constrains the values with the pattern", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(action.Params.Type.ToObject()["id"].Validation.Pattern).Should(Equal("^(?:[0-9]+)$"))
+			})
+		})
+
+		Context("with a pattern conflicting with the parameter pattern", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/items/{id:[0-9]+}"))
+					Params(func() {
+						Param("id", String, func() {
+							Pattern("^[a-z]+$")
+						})
+					})
+				}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a name and DSL defining a description, route, headers, payload and responses", func() {
 		const typeName = "typeName"
 		const description = "description"
@@ -187,6 +285,26 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with a request header using the response header mapping syntax", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"))
+				Headers(func() {
+					Header("X-Foo:foo")
+				})
+			}
+		})
+
+		It("does not map the header to an attribute", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			o := action.Headers.Type.ToObject()
+			Ω(o).Should(HaveKey("X-Foo:foo"))
+			Ω(o["X-Foo:foo"].Metadata).ShouldNot(HaveKey("header:attribute"))
+		})
+	})
+
 	Context("using a response with a media type modifier", func() {
 		const mtID = "application/vnd.app.foo+json"
 
@@ -364,3 +482,869 @@ var _ = Describe("Payload", func() {
 	})
 
 })
+
+var _ = Describe("StreamingPayload and StreamingResult", func() {
+	var dsl func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", dsl)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["bar"]
+		}
+	})
+
+	Context("with a type", func() {
+		BeforeEach(func() {
+			msg := Type("Message", func() {
+				Attribute("body", String)
+			})
+			dsl = func() {
+				Routing(GET("/chat"))
+				StreamingPayload(msg)
+				StreamingResult("Message")
+			}
+		})
+
+		It("sets the streaming types and makes the action a websocket action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.StreamingPayload).ShouldNot(BeNil())
+			Ω(action.StreamingPayload.TypeName).Should(Equal("Message"))
+			Ω(action.StreamingResult).ShouldNot(BeNil())
+			Ω(action.StreamingResult.TypeName).Should(Equal("Message"))
+			Ω(action.Streaming()).Should(BeTrue())
+			Ω(action.WebSocket()).Should(BeTrue())
+			Ω(action.CanonicalScheme()).Should(Equal("ws"))
+		})
+	})
+
+	Context("with an unknown type name", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET("/chat"))
+				StreamingResult("Unknown")
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with both a payload and a streaming payload", func() {
+		BeforeEach(func() {
+			msg := Type("Message", func() {
+				Attribute("body", String)
+			})
+			dsl = func() {
+				Routing(GET("/chat"))
+				Payload(msg)
+				StreamingPayload(msg)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("SSE", func() {
+	var dsl func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", dsl)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["bar"]
+		}
+	})
+
+	Context("with a streaming result", func() {
+		BeforeEach(func() {
+			event := Type("Event", func() {
+				Attribute("body", String)
+			})
+			dsl = func() {
+				Routing(GET("/events"))
+				StreamingResult(event)
+				SSE()
+			}
+		})
+
+		It("uses Server-Sent Events", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.ServerSentEvents).Should(BeTrue())
+			Ω(action.Streaming()).Should(BeTrue())
+			Ω(action.WebSocket()).Should(BeFalse())
+		})
+	})
+
+	Context("without a streaming result", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET("/events"))
+				SSE()
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("NDJSON", func() {
+	var dsl func()
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", dsl)
+		})
+		dslengine.Run()
+		if r, ok := Design.Resources["foo"]; ok {
+			action = r.Actions["bar"]
+		}
+	})
+
+	Context("with a streaming result", func() {
+		BeforeEach(func() {
+			item := Type("Item", func() {
+				Attribute("body", String)
+			})
+			dsl = func() {
+				Routing(GET("/items"))
+				StreamingResult(item)
+				NDJSON()
+			}
+		})
+
+		It("streams newline delimited JSON", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.NDJSON).Should(BeTrue())
+			Ω(action.Streaming()).Should(BeTrue())
+			Ω(action.WebSocket()).Should(BeFalse())
+		})
+	})
+
+	Context("with a streaming payload", func() {
+		BeforeEach(func() {
+			item := Type("Item", func() {
+				Attribute("body", String)
+			})
+			dsl = func() {
+				Routing(GET("/items"))
+				StreamingPayload(item)
+				StreamingResult(item)
+				NDJSON()
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("without a streaming result", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Routing(GET("/items"))
+				NDJSON()
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("MaxBodyLength", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("set on the resource and on an action", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				MaxBodyLength(1024)
+				Action("bar", func() {
+					Routing(POST("/bar"))
+					MaxBodyLength(10)
+				})
+				Action("baz", func() {
+					Routing(POST("/baz"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the effective maximum body length", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["foo"].Actions["bar"].EffectiveMaxBodyLength()).Should(Equal(int64(10)))
+			Ω(Design.Resources["foo"].Actions["baz"].EffectiveMaxBodyLength()).Should(Equal(int64(1024)))
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(POST("/bar"))
+					MaxBodyLength(0)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("RateLimit", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("set on the API and on an action", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				RateLimit(100, 200)
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/bar"))
+					RateLimit(1.5, 3, func() {
+						ByHeader("X-API-Key")
+					})
+				})
+				Action("baz", func() {
+					Routing(GET("/baz"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the effective rate limit", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			bar := Design.Resources["foo"].Actions["bar"].EffectiveRateLimit()
+			Ω(*bar).Should(Equal(RateLimitDefinition{RPS: 1.5, Burst: 3, Header: "X-API-Key"}))
+			baz := Design.Resources["foo"].Actions["baz"].EffectiveRateLimit()
+			Ω(*baz).Should(Equal(RateLimitDefinition{RPS: 100, Burst: 200}))
+		})
+	})
+
+	Context("with an invalid burst", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				RateLimit(10, 0)
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Interceptor", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("set on a resource and on an action", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Interceptor("audit")
+				Action("bar", func() {
+					Routing(GET("/bar"))
+					Interceptor("cache")
+					Interceptor("audit")
+				})
+				Action("baz", func() {
+					Routing(GET("/baz"))
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("lists the interceptors of each action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["foo"].Actions["bar"].AllInterceptors()).Should(Equal([]string{"audit", "cache"}))
+			Ω(Design.Resources["foo"].Actions["baz"].AllInterceptors()).Should(Equal([]string{"audit"}))
+			Ω(Design.Interceptors()).Should(Equal([]string{"audit", "cache"}))
+		})
+	})
+
+	Context("with an empty name", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Interceptor("")
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Timeout", func() {
+	var timeout string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("bar", func() {
+				Routing(GET("/bar"))
+				Timeout(timeout)
+			})
+		})
+		dslengine.Run()
+	})
+
+	Context("with a valid duration", func() {
+		BeforeEach(func() {
+			timeout = "1m30s"
+		})
+
+		It("sets the action timeout", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["foo"].Actions["bar"].Timeout).Should(Equal(90 * time.Second))
+		})
+	})
+
+	Context("with an invalid duration", func() {
+		BeforeEach(func() {
+			timeout = "soon"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("KeepAlive", func() {
+	var streaming bool
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		streaming = true
+	})
+
+	JustBeforeEach(func() {
+		msg := Type("Message", func() {
+			Attribute("body", String)
+		})
+		Resource("foo", func() {
+			Action("bar", func() {
+				Routing(GET("/bar"))
+				if streaming {
+					StreamingPayload(msg)
+					StreamingResult(msg)
+				}
+				KeepAlive("30s")
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the keep alive interval", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["foo"].Actions["bar"].KeepAlive).Should(Equal(30 * time.Second))
+	})
+
+	Context("with an action that does not stream", func() {
+		BeforeEach(func() {
+			streaming = false
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("IdempotencyKey", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("foo", func() {
+			Action("bar", func() {
+				Routing(POST("/bar"))
+				IdempotencyKey()
+				Headers(func() {
+					Header("X-Request-Id")
+				})
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("adds the Idempotency-Key header to the action headers", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["foo"].Actions["bar"]
+		Ω(a.IdempotencyKey).Should(BeTrue())
+		Ω(a.Headers.Type.ToObject()).Should(HaveKey("Idempotency-Key"))
+		Ω(a.Headers.Type.ToObject()).Should(HaveKey("X-Request-Id"))
+	})
+})
+
+var _ = Describe("Async", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("foo", func() {
+			BasePath("/foos")
+			Action("export", func() {
+				Routing(POST("/:id/export"))
+				Params(func() {
+					Param("id", Integer)
+				})
+				Async()
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("defines the job status action", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		a := Design.Resources["foo"].Actions["export"]
+		Ω(a.Async).Should(BeTrue())
+		Ω(a.Responses).Should(HaveKey("Accepted"))
+		Ω(a.Responses["Accepted"].MediaType).Should(Equal(JobMediaIdentifier))
+		status := Design.Resources["foo"].Actions["export_status"]
+		Ω(status).ShouldNot(BeNil())
+		Ω(a.JobStatus).Should(Equal(status))
+		Ω(status.Routes).Should(HaveLen(1))
+		Ω(status.Routes[0].Verb).Should(Equal("GET"))
+		Ω(status.Routes[0].FullPath()).Should(Equal("/foos/:id/export/jobs/:jobID"))
+		Ω(status.Params.Type.ToObject()["id"].Type).Should(Equal(Integer))
+		Ω(status.Responses["OK"].MediaType).Should(Equal(JobMediaIdentifier))
+		Ω(Design.MediaTypes).Should(HaveKey(JobMediaIdentifier))
+	})
+})
+
+var _ = Describe("SkipRequestBodyEncodeDecode", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("used in an action without payload", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(PUT("/files/:name"))
+					SkipRequestBodyEncodeDecode()
+					SkipResponseBodyEncodeDecode()
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the skip flags", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			a := Design.Resources["foo"].Actions["upload"]
+			Ω(a.SkipRequestBodyEncodeDecode).Should(BeTrue())
+			Ω(a.SkipResponseBodyEncodeDecode).Should(BeTrue())
+		})
+	})
+
+	Context("used in an action with a payload", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(PUT("/files/:name"))
+					SkipRequestBodyEncodeDecode()
+					Payload(String)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("MultipartForm", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("with an object payload containing files", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(POST("/upload"))
+					MultipartForm()
+					Payload(func() {
+						Member("avatar", File)
+						Member("attachments", ArrayOf(File))
+						Member("name", String)
+						Required("avatar")
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the multipart flag", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["foo"].Actions["upload"].PayloadMultipart).Should(BeTrue())
+		})
+	})
+
+	Context("with a non object payload", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(POST("/upload"))
+					MultipartForm()
+					Payload(ArrayOf(String))
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a file in a payload that is not multipart", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(POST("/upload"))
+					Payload(func() {
+						Member("avatar", File)
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a file with a default value", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(POST("/upload"))
+					MultipartForm()
+					Payload(func() {
+						Member("avatar", File, func() {
+							Default("avatar.png")
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a nested file", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("upload", func() {
+					Routing(POST("/upload"))
+					MultipartForm()
+					Payload(func() {
+						Member("meta", func() {
+							Attribute("avatar", File)
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Origin", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("test", func() {
+			Origin("*", func() {
+				Methods("GET")
+			})
+		})
+		Resource("foo", func() {
+			Origin("here.example.com", func() {
+				Methods("GET")
+			})
+			Action("list", func() {
+				Routing(GET("/"))
+				Origin("here.example.com", func() {
+					Methods("GET", "POST")
+					Credentials()
+				})
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the action CORS policies", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		origins := Design.Resources["foo"].Actions["list"].AllOrigins()
+		Ω(origins).Should(HaveLen(2))
+		Ω(origins[0].Origin).Should(Equal("*"))
+		Ω(origins[1].Origin).Should(Equal("here.example.com"))
+		Ω(origins[1].Methods).Should(Equal([]string{"GET", "POST"}))
+		Ω(origins[1].Credentials).Should(BeTrue())
+	})
+})
+
+var _ = Describe("Cookies", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	Context("defined on an action and a response", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("login", func() {
+					Routing(POST("/login"))
+					Cookies(func() {
+						Cookie("remember_me", Boolean)
+					})
+					Response(NoContent, func() {
+						Cookies(func() {
+							Cookie("session_id", String, func() {
+								CookieMaxAge(3600)
+								CookieSecure()
+								CookieHTTPOnly()
+								CookieSameSite("strict")
+							})
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("sets the cookies", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			action := Design.Resources["foo"].Actions["login"]
+			Ω(action.Cookies.Type.ToObject()).Should(HaveKey("remember_me"))
+			cookies := action.Responses["NoContent"].Cookies.Type.ToObject()
+			Ω(cookies).Should(HaveKey("session_id"))
+			Ω(cookies["session_id"].Metadata).Should(Equal(dslengine.MetadataDefinition{
+				"cookie:max-age":   {"3600"},
+				"cookie:secure":    {"true"},
+				"cookie:http-only": {"true"},
+				"cookie:same-site": {"strict"},
+			}))
+		})
+	})
+
+	Context("with a cookie that is not a primitive", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("login", func() {
+					Routing(POST("/login"))
+					Cookies(func() {
+						Cookie("ids", ArrayOf(Integer))
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an invalid SameSite mode", func() {
+		BeforeEach(func() {
+			Resource("foo", func() {
+				Action("login", func() {
+					Routing(POST("/login"))
+					Response(NoContent, func() {
+						Cookies(func() {
+							Cookie("session_id", func() {
+								CookieSameSite("always")
+							})
+						})
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Tagged responses", func() {
+	var tagValue, view string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		tagValue = "accepted"
+		view = "default"
+	})
+
+	JustBeforeEach(func() {
+		mt := MediaType("application/vnd.result", func() {
+			Attributes(func() {
+				Attribute("outcome")
+				Attribute("id")
+			})
+			View("default", func() {
+				Attribute("outcome")
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		Resource("foo", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Response(Created, mt, func() {
+					Tag("outcome", "created")
+				})
+				Response(Accepted, func() {
+					Media(mt, view)
+					Tag("outcome", tagValue)
+				})
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the tags", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		resp := Design.Resources["foo"].Actions["create"].Responses["Accepted"]
+		Ω(resp.TagAttribute).Should(Equal("outcome"))
+		Ω(resp.TagValue).Should(Equal("accepted"))
+	})
+
+	Context("with duplicate tag values", func() {
+		BeforeEach(func() {
+			tagValue = "created"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`tag value "created"`))
+		})
+	})
+
+	Context("with a view that does not render the tag attribute", func() {
+		BeforeEach(func() {
+			view = "tiny"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("tag attribute outcome is not rendered by view tiny"))
+		})
+	})
+})
+
+var _ = Describe("Paginate", func() {
+	var style string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		style = "page"
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				Paginate(style, 10)
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("defines the pagination parameters", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		action := Design.Resources["foo"].Actions["list"]
+		Ω(action.Pagination).Should(Equal("page"))
+		params := action.Params.Type.ToObject()
+		Ω(params).Should(HaveKey("page"))
+		Ω(params).Should(HaveKey("page_size"))
+		Ω(params["page_size"].DefaultValue).Should(Equal(10))
+		Ω(*params["page_size"].Validation.Maximum).Should(Equal(10.0))
+	})
+
+	Context("with an invalid style", func() {
+		BeforeEach(func() {
+			style = "token"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("IfMatch", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("foo", func() {
+			Action("update", func() {
+				Routing(PUT("/:id"))
+				IfMatch()
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("defines the If-Match header and the Precondition Failed response", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		action := Design.Resources["foo"].Actions["update"]
+		Ω(action.IfMatch).Should(BeTrue())
+		Ω(action.Headers.Type.ToObject()).Should(HaveKey("If-Match"))
+		Ω(action.Responses).Should(HaveKey(PreconditionFailed))
+		Ω(action.Responses[PreconditionFailed].Status).Should(Equal(412))
+	})
+})
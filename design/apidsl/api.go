@@ -40,6 +40,11 @@ import (
 //			Param("param")
 //		})
 //		Security("JWT")
+//		HealthCheck()				// Expose the /healthz and /readyz probes
+//		TLS(func() {				// Serve the API over HTTPS
+//			Certificate("cert.pem", "key.pem")	// One or more server certificates selected with SNI
+//			ClientCA("ca.pem")			// Require client certificates (mutual TLS)
+//		})
 //		Origin("http://swagger.goa.design", func() { // Define CORS policy, may be prefixed with "*" wildcard
 //			Headers("X-Shared-Secret")           // One or more authorized headers, use "*" to authorize all
 //			Methods("GET", "POST")               // One or more authorized HTTP methods
@@ -99,7 +104,90 @@ func Version(ver string) {
 	}
 }
 
-// Description can be used in: API, Resource, Action, or MediaType
+// Versions can be used in: API, Resource, Action
+//
+// Versions lists the versions of the API described by the design when used in the API
+// definition. The generated code serves each version with its own router and the controller
+// interfaces include the actions of all the versions so that actions shared by several versions
+// are implemented once. By default requests select the version with a path prefix inserted after
+// the API base path, e.g. "/v1/bottles", use VersionHeader or VersionMediaType to select the
+// version with a request header or with a media type parameter instead.
+//
+// When used in a resource or action definition Versions lists the API versions that serve the
+// resource actions or the action, all the API versions serve them by default. Example:
+//
+//	var _ = API("cellar", func() {
+//		Versions("v1", "v2")
+//	})
+//
+//	var _ = Resource("bottle", func() {
+//		Action("show", func() {      // Served by v1 and v2
+//			Routing(GET("/:id"))
+//		})
+//		Action("rate", func() {      // Served by v2 only
+//			Versions("v2")
+//			Routing(PUT("/:id/rating"))
+//		})
+//	})
+//
+func Versions(versions ...string) {
+	if len(versions) == 0 {
+		dslengine.ReportError("Versions requires at least one version")
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		v := versioning(def)
+		v.Versions = append(v.Versions, versions...)
+	case *design.ResourceDefinition:
+		def.Versions = append(def.Versions, versions...)
+	case *design.ActionDefinition:
+		def.Versions = append(def.Versions, versions...)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// VersionHeader can be used in: API
+//
+// VersionHeader makes requests select the API version listed with Versions using the value of
+// the header with the given name, e.g. "X-API-Version: v2".
+func VersionHeader(name string) {
+	if api, ok := apiDefinition(); ok {
+		if name == "" {
+			dslengine.ReportError("version header name cannot be empty")
+			return
+		}
+		v := versioning(api)
+		v.Scheme, v.Name = design.VersionSchemeHeader, name
+	}
+}
+
+// VersionMediaType can be used in: API
+//
+// VersionMediaType makes requests select the API version listed with Versions using the
+// parameter with the given name of the media type listed in the Accept header or else in the
+// Content-Type header, e.g. "Accept: application/json; version=v2".
+func VersionMediaType(param string) {
+	if api, ok := apiDefinition(); ok {
+		if param == "" {
+			dslengine.ReportError("version media type parameter name cannot be empty")
+			return
+		}
+		v := versioning(api)
+		v.Scheme, v.Name = design.VersionSchemeMediaType, param
+	}
+}
+
+// versioning returns the versioning definition of the API, creating it if needed.
+func versioning(api *design.APIDefinition) *design.VersioningDefinition {
+	if api.Versioning == nil {
+		api.Versioning = &design.VersioningDefinition{Scheme: design.VersionSchemePath}
+	}
+	return api.Versioning
+}
+
+// Description can be used in: API, Resource, Action, MediaType or Error
 //
 // Description sets the definition description.
 func Description(d string) {
@@ -118,6 +206,8 @@ func Description(d string) {
 		def.Description = d
 	case *design.ResponseDefinition:
 		def.Description = d
+	case *design.ErrorDefinition:
+		def.Description = d
 	case *design.DocsDefinition:
 		def.Description = d
 	case *design.SecuritySchemeDefinition:
@@ -130,17 +220,25 @@ func Description(d string) {
 // BasePath can used in: API, Resource
 //
 // BasePath defines the API base path, i.e. the common path prefix to all the API actions.
-// The path may define wildcards (see Routing for a description of the wildcard syntax).
-// The corresponding parameters must be described using Params.
+// The path may define wildcards including braced parameters constrained with a pattern (see Routing
+// for a description of the wildcard syntax). The corresponding parameters must be described using
+// Params.
 func BasePath(val string) {
+	p, patterns, err := parseRoutePath(val)
+	if err != nil {
+		dslengine.ReportError("invalid base path %#v, %s", val, err)
+		return
+	}
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
-		def.BasePath = val
+		def.BasePath = p
+		def.BasePathPatterns = patterns
 	case *design.ResourceDefinition:
-		def.BasePath = val
-		if !strings.HasPrefix(val, "//") {
+		def.BasePath = p
+		def.BasePathPatterns = patterns
+		if !strings.HasPrefix(p, "//") {
 			awcs := design.ExtractWildcards(design.Design.BasePath)
-			wcs := design.ExtractWildcards(val)
+			wcs := design.ExtractWildcards(p)
 			for _, awc := range awcs {
 				for _, wc := range wcs {
 					if awc == wc {
@@ -154,12 +252,15 @@ func BasePath(val string) {
 	}
 }
 
-// Origin can be used in: Resource, API
+// Origin can be used in: Resource, API, Action
 //
 // Origin defines the CORS policy for a given origin. The origin can use a wildcard prefix
 // such as "https://*.mydomain.com". The special value "*" defines the policy for all origins
 // (in which case there should be only one Origin DSL in the parent resource).
 // The origin can also be a regular expression wrapped into "/".
+// Policies defined in a resource override the API policies for the same origin and policies
+// defined in an action override the resource policies. goagen generates the handlers for the
+// preflight OPTIONS requests and the middleware that sets the CORS response headers.
 // Example:
 //
 //        Origin("http://swagger.goa.design", func() { // Define CORS policy, may be prefixed with "*" wildcard
@@ -196,6 +297,12 @@ func Origin(origin string, dsl func()) {
 			def.Origins = make(map[string]*design.CORSDefinition)
 		}
 		def.Origins[origin] = cors
+	case *design.ActionDefinition:
+		parent = def
+		if def.Origins == nil {
+			def.Origins = make(map[string]*design.CORSDefinition)
+		}
+		def.Origins[origin] = cors
 	default:
 		dslengine.IncompatibleDSL()
 		return
@@ -319,7 +426,9 @@ func License(dsl func()) {
 
 // Docs can be used in: API, Action, Files
 //
-// Docs provides external documentation pointers.
+// Docs provides external documentation pointers. When used in the API, ServeOpenAPI, SwaggerUI
+// and ReDoc may also be used to serve the OpenAPI document describing the API and pages rendering
+// it.
 func Docs(dsl func()) {
 	docs := new(design.DocsDefinition)
 	if !dslengine.Execute(dsl, docs) {
@@ -328,16 +437,179 @@ func Docs(dsl func()) {
 
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
+		if docs.OpenAPIPath == "" && (docs.SwaggerUIPath != "" || docs.ReDocPath != "") {
+			docs.OpenAPIPath = "/openapi.json"
+		}
 		def.Docs = docs
 	case *design.ActionDefinition:
+		checkServedDocs(docs)
 		def.Docs = docs
 	case *design.FileServerDefinition:
+		checkServedDocs(docs)
 		def.Docs = docs
 	default:
 		dslengine.IncompatibleDSL()
 	}
 }
 
+// checkServedDocs reports an error if the documentation served by the API is defined outside of
+// the API Docs.
+func checkServedDocs(docs *design.DocsDefinition) {
+	if docs.OpenAPIPath != "" || docs.SwaggerUIPath != "" || docs.ReDocPath != "" {
+		dslengine.ReportError("ServeOpenAPI, SwaggerUI and ReDoc can only be used in the API Docs")
+	}
+}
+
+// ServeOpenAPI can be used in: Docs
+//
+// ServeOpenAPI makes the service serve the OpenAPI 3.0 document describing the API in JSON. The
+// optional argument overrides the default request path "/openapi.json". The path is not prefixed
+// with the API base path. ServeOpenAPI may only be used in the API Docs.
+//
+// goagen generates a MountDocs function in the app package that mounts the handler serving the
+// document - and the Swagger UI and ReDoc pages if any - on the service mux, see package
+// github.com/goadesign/goa/apidocs. The document is computed at generation time and embedded in
+// the generated code.
+//
+// Example:
+//
+//	API("cellar", func() {
+//		Docs(func() {
+//			Description("Cellar guide")
+//			URL("https://cellar.goa.design/guide")
+//			ServeOpenAPI("/openapi.json")
+//			SwaggerUI("/docs")
+//			ReDoc("/redoc")
+//		})
+//	})
+//
+func ServeOpenAPI(path ...string) {
+	if d, ok := docsDefinition(); ok {
+		d.OpenAPIPath = docsPath("ServeOpenAPI", "/openapi.json", path)
+	}
+}
+
+// SwaggerUI can be used in: Docs
+//
+// SwaggerUI makes the service serve a Swagger UI page rendering the OpenAPI document served by
+// the API. The optional argument overrides the default request path "/docs". Using SwaggerUI
+// implies ServeOpenAPI with the default path if ServeOpenAPI is not used. See ServeOpenAPI for
+// an example.
+func SwaggerUI(path ...string) {
+	if d, ok := docsDefinition(); ok {
+		d.SwaggerUIPath = docsPath("SwaggerUI", "/docs", path)
+	}
+}
+
+// ReDoc can be used in: Docs
+//
+// ReDoc makes the service serve a ReDoc page rendering the OpenAPI document served by the API.
+// The optional argument overrides the default request path "/redoc". Using ReDoc implies
+// ServeOpenAPI with the default path if ServeOpenAPI is not used. See ServeOpenAPI for an
+// example.
+func ReDoc(path ...string) {
+	if d, ok := docsDefinition(); ok {
+		d.ReDocPath = docsPath("ReDoc", "/redoc", path)
+	}
+}
+
+// docsPath returns the request path given to the DSL function with the given name or def if
+// none.
+func docsPath(name, def string, path []string) string {
+	if len(path) > 1 {
+		dslengine.ReportError("too many arguments given to %s", name)
+	}
+	if len(path) > 0 {
+		return path[0]
+	}
+	return def
+}
+
+// HealthCheck can be used in: API
+//
+// HealthCheck exposes the liveness and readiness probes of the service. The optional arguments
+// override the default request paths of the probes, "/healthz" for the liveness probe and
+// "/readyz" for the readiness probe. The paths are not prefixed with the API base path.
+//
+// goagen generates a MountHealthCheck function in the app package that mounts the probes on the
+// service mux. The readiness probe runs the checkers given to MountHealthCheck, see package
+// github.com/goadesign/goa/health. The probes are not controller actions so that the service and
+// controller middlewares - for example security, logging or tracing - do not apply to them.
+//
+// Example:
+//
+//	API("cellar", func() {
+//		HealthCheck("/health/live", "/health/ready")
+//	})
+//
+func HealthCheck(paths ...string) {
+	if len(paths) > 2 {
+		dslengine.ReportError("too many arguments given to HealthCheck")
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		hc := &design.HealthCheckDefinition{LivePath: "/healthz", ReadyPath: "/readyz"}
+		if len(paths) > 0 {
+			hc.LivePath = paths[0]
+		}
+		if len(paths) > 1 {
+			hc.ReadyPath = paths[1]
+		}
+		a.HealthCheck = hc
+	}
+}
+
+// TLS can be used in: API
+//
+// TLS defines the certificates used by the generated main to serve the API over HTTPS and
+// optionally the CA used to authenticate clients (mutual TLS). The API must use the "https"
+// scheme. Certificate may be used multiple times to serve multiple hosts, the server picks the
+// certificate matching the host requested by the client (SNI) and defaults to the first one.
+//
+// goagen generates the calls to goa.TLSFiles and goa.ClientCAFile in main and --tls-cert,
+// --tls-key and --tls-ca flags in the client tool.
+//
+// Example:
+//
+//	API("cellar", func() {
+//		Scheme("https")
+//		TLS(func() {
+//			Certificate("certs/cellar.pem", "certs/cellar-key.pem")
+//			Certificate("certs/admin.pem", "certs/admin-key.pem")
+//			ClientCA("certs/ca.pem")
+//		})
+//	})
+//
+func TLS(dsl func()) {
+	t := new(design.TLSDefinition)
+	if !dslengine.Execute(dsl, t) {
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.TLS = t
+	}
+}
+
+// Certificate can be used in: TLS
+//
+// Certificate adds a server certificate given the paths to the PEM encoded certificate and
+// private key files.
+func Certificate(certFile, keyFile string) {
+	if t, ok := tlsDefinition(); ok {
+		t.Certificates = append(t.Certificates, &design.CertificateDefinition{CertFile: certFile, KeyFile: keyFile})
+	}
+}
+
+// ClientCA can be used in: TLS
+//
+// ClientCA sets the path to the PEM encoded CA certificates used to verify the client
+// certificates. Servers require clients to present a valid certificate when ClientCA is set.
+func ClientCA(caFile string) {
+	if t, ok := tlsDefinition(); ok {
+		t.ClientCA = caFile
+	}
+}
+
 // Name can be used in: Contact, License.
 //
 // Name sets the contact or license name.
@@ -405,6 +677,75 @@ func Produces(args ...interface{}) {
 	}
 }
 
+// DefaultResponseContentType can be used in: API
+//
+// DefaultResponseContentType sets the content type used to encode responses when requests do not
+// specify an Accept header and to decode request bodies that do not specify a Content-Type
+// header. The content type must be listed in Produces, it defaults to "application/json".
+// Example:
+//
+//	API("cellar", func() {
+//		Produces("application/json", "application/gob")
+//		Consumes("application/json", "application/gob")
+//		DefaultResponseContentType("application/gob")
+//	})
+//
+func DefaultResponseContentType(contentType string) {
+	if a, ok := apiDefinition(); ok {
+		a.DefaultResponseContentType = contentType
+	}
+}
+
+// TrailingSlash can be used in: API
+//
+// TrailingSlash sets how the generated service handles requests whose path only differs from the
+// path of an action by a trailing slash. The behavior is one of:
+//
+//	"redirect" (default): respond with a redirect to the action path.
+//	"absorb": serve the request with the action handler directly.
+//	"strict": respond with 404 Not Found.
+//
+// Example:
+//
+//	API("cellar", func() {
+//		TrailingSlash("absorb")
+//	})
+//
+func TrailingSlash(behavior string) {
+	if a, ok := apiDefinition(); ok {
+		switch behavior {
+		case "redirect", "absorb", "strict":
+			a.TrailingSlash = behavior
+		default:
+			dslengine.ReportError(`invalid trailing slash behavior %#v, must be one of "redirect", "absorb" or "strict"`, behavior)
+		}
+	}
+}
+
+// CaseInsensitiveRouting can be used in: API
+//
+// CaseInsensitiveRouting makes the generated service match the static segments of the request
+// paths against the action paths in a case insensitive way, so that for example requests sent to
+// "/Bottles/1" are handled by the action with path "/bottles/:id". The values of the path
+// parameters are not modified.
+func CaseInsensitiveRouting() {
+	if a, ok := apiDefinition(); ok {
+		a.CaseInsensitiveRouting = true
+	}
+}
+
+// MethodOverride can be used in: API
+//
+// MethodOverride makes the generated service route POST requests that set the
+// X-HTTP-Method-Override header using the method given in the header. This makes it possible for
+// clients behind proxies that only allow GET and POST requests to send PUT, PATCH and DELETE
+// requests. Other methods given in the header are ignored.
+func MethodOverride() {
+	if a, ok := apiDefinition(); ok {
+		a.MethodOverride = true
+	}
+}
+
 // buildEncodingDefinition builds up an encoding definition.
 func buildEncodingDefinition(encoding bool, args ...interface{}) *design.EncodingDefinition {
 	var dsl func()
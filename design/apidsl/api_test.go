@@ -56,6 +56,96 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with a default response content type not listed in Produces", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				DefaultResponseContentType("application/msgpack")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an invalid health check path", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				HealthCheck("healthz")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with versioning and no version", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				VersionHeader("X-API-Version")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("at least one version"))
+		})
+	})
+
+	Context("with a resource served by an unknown version", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Versions("v1", "v2")
+			}
+			Resource("bar", func() {
+				Versions("v3")
+				Action("show", func() {
+					Routing(GET(""))
+				})
+			})
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`unknown version "v3"`))
+		})
+	})
+
+	Context("with TLS and no https scheme", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				TLS(func() {
+					Certificate("cert.pem", "key.pem")
+				})
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("https scheme"))
+		})
+	})
+
+	Context("with an invalid trailing slash behavior", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				TrailingSlash("ignore")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid trailing slash behavior"))
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -104,6 +194,22 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with versions", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Versions("v1", "v2")
+					VersionMediaType("version")
+				}
+			})
+
+			It("sets the API versioning", func() {
+				Ω(Design.Versioning).ShouldNot(BeNil())
+				Ω(Design.Versioning.Versions).Should(Equal([]string{"v1", "v2"}))
+				Ω(Design.Versioning.Scheme).Should(Equal(VersionSchemeMediaType))
+				Ω(Design.Versioning.Name).Should(Equal("version"))
+			})
+		})
+
 		Context("with a terms of service", func() {
 			const terms = "terms"
 
@@ -200,6 +306,46 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with a default response content type", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Produces("application/json", "application/gob")
+					DefaultResponseContentType("application/gob")
+				}
+			})
+
+			It("sets the API default response content type", func() {
+				Ω(Design.DefaultResponseContentType).Should(Equal("application/gob"))
+			})
+		})
+
+		Context("with routing options", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					TrailingSlash("absorb")
+					CaseInsensitiveRouting()
+				}
+			})
+
+			It("sets the API routing options", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.TrailingSlash).Should(Equal("absorb"))
+				Ω(Design.CaseInsensitiveRouting).Should(BeTrue())
+			})
+		})
+
+		Context("with method override", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					MethodOverride()
+				}
+			})
+
+			It("enables method override", func() {
+				Ω(Design.MethodOverride).Should(BeTrue())
+			})
+		})
+
 		Context("with a BasePath", func() {
 			const basePath = "basePath"
 
@@ -214,6 +360,125 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with a health check", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					HealthCheck()
+				}
+			})
+
+			It("uses the default probe paths", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.HealthCheck).ShouldNot(BeNil())
+				Ω(Design.HealthCheck.LivePath).Should(Equal("/healthz"))
+				Ω(Design.HealthCheck.ReadyPath).Should(Equal("/readyz"))
+			})
+
+			Context("with custom paths", func() {
+				BeforeEach(func() {
+					dsl = func() {
+						HealthCheck("/health/live", "/health/ready")
+					}
+				})
+
+				It("sets the probe paths", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					Ω(Design.HealthCheck.LivePath).Should(Equal("/health/live"))
+					Ω(Design.HealthCheck.ReadyPath).Should(Equal("/health/ready"))
+				})
+			})
+
+			Context("with a conflicting action route", func() {
+				JustBeforeEach(func() {
+					Resource("foo", func() {
+						Action("ready", func() {
+							Routing(GET("/readyz"))
+						})
+					})
+					dslengine.Run()
+				})
+
+				It("returns an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("conflicts with the health check probe"))
+				})
+			})
+		})
+
+		Context("with served documentation", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Docs(func() {
+						SwaggerUI()
+						ReDoc("/guide")
+					})
+				}
+			})
+
+			It("sets the documentation paths", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Docs).ShouldNot(BeNil())
+				Ω(Design.Docs.OpenAPIPath).Should(Equal("/openapi.json"))
+				Ω(Design.Docs.SwaggerUIPath).Should(Equal("/docs"))
+				Ω(Design.Docs.ReDocPath).Should(Equal("/guide"))
+			})
+
+			Context("with a conflicting action route", func() {
+				JustBeforeEach(func() {
+					Resource("foo", func() {
+						Action("docs", func() {
+							Routing(GET("/docs"))
+						})
+					})
+					dslengine.Run()
+				})
+
+				It("returns an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("conflicts with the documentation"))
+				})
+			})
+
+			Context("in an action", func() {
+				JustBeforeEach(func() {
+					Resource("foo", func() {
+						Action("show", func() {
+							Routing(GET("/"))
+							Docs(func() {
+								ServeOpenAPI()
+							})
+						})
+					})
+					dslengine.Run()
+				})
+
+				It("returns an error", func() {
+					Ω(dslengine.Errors).Should(HaveOccurred())
+					Ω(dslengine.Errors.Error()).Should(ContainSubstring("can only be used in the API Docs"))
+				})
+			})
+		})
+
+		Context("with TLS", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Scheme("https")
+					TLS(func() {
+						Certificate("a.pem", "a-key.pem")
+						Certificate("b.pem", "b-key.pem")
+						ClientCA("ca.pem")
+					})
+				}
+			})
+
+			It("sets the certificates and client CA", func() {
+				Ω(Design.TLS).ShouldNot(BeNil())
+				Ω(Design.TLS.Certificates).Should(HaveLen(2))
+				Ω(*Design.TLS.Certificates[1]).Should(Equal(CertificateDefinition{CertFile: "b.pem", KeyFile: "b-key.pem"}))
+				Ω(Design.TLS.ClientCA).Should(Equal("ca.pem"))
+			})
+		})
+
 		Context("with Params", func() {
 			const param1Name = "accountID"
 			const param1Type = Integer
@@ -296,6 +561,59 @@ var _ = Describe("API", func() {
 					})
 				})
 			})
+
+			Context("and a BasePath with a braced parameter", func() {
+				var basePath string
+
+				BeforeEach(func() {
+					basePath = "/:accountID/{id:[a-z]+}"
+					prevDSL := dsl
+					dsl = func() {
+						BasePath(basePath)
+						prevDSL()
+					}
+				})
+
+				It("converts the parameter and records its pattern", func() {
+					Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+					Ω(Design.BasePath).Should(Equal("/:accountID/:id"))
+					Ω(Design.BasePathPatterns).Should(Equal(map[string]string{"id": "^(?:[a-z]+)$"}))
+				})
+
+				Context("with a resource action", func() {
+					var action *ActionDefinition
+
+					JustBeforeEach(func() {
+						Resource("foo", func() {
+							Action("show", func() {
+								Routing(GET("/{name:[a-z]+}"))
+							})
+						})
+						dslengine.Run()
+						action = Design.Resources["foo"].Actions["show"]
+					})
+
+					It("constrains the action parameters with the base path and route patterns", func() {
+						Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+						params := action.Params.Type.ToObject()
+						Ω(params["id"].Validation).ShouldNot(BeNil())
+						Ω(params["id"].Validation.Pattern).Should(Equal("^(?:[a-z]+)$"))
+						Ω(params["name"].Validation).ShouldNot(BeNil())
+						Ω(params["name"].Validation.Pattern).Should(Equal("^(?:[a-z]+)$"))
+						Ω(Design.Params.Type.ToObject()["id"].Validation).Should(BeNil())
+					})
+
+					Context("with a pattern on a non string parameter", func() {
+						BeforeEach(func() {
+							basePath = "/{accountID:[0-9]+}/:id"
+						})
+
+						It("returns an error", func() {
+							Ω(dslengine.Errors).Should(HaveOccurred())
+						})
+					})
+				})
+			})
 		})
 
 		Context("with ResponseTemplates", func() {
@@ -128,6 +128,7 @@ func Attribute(name string, args ...interface{}) {
 			}
 		}
 		baseAttr.Reference = parent.Reference
+		inlineScalar(baseAttr)
 		if dsl != nil {
 			dslengine.Execute(dsl, baseAttr)
 		}
@@ -248,6 +249,19 @@ func parseAttributeArgs(baseAttr *design.AttributeDefinition, args ...interface{
 // Within an APIKeySecurity or JWTSecurity definition, Header
 // defines that an implementation must check the given header to get
 // the API Key.  In this case, no `args` parameter is necessary.
+//
+// Within the Headers of a Response, the name may use the form "header:attribute" to map the
+// attribute of the response media type to the header. The generated response helpers set the
+// header from the value of the attribute before writing the body. Array attributes produce one
+// header value per element. Example:
+//
+//	Response(OK, func() {
+//		Media(BottleMedia)
+//		Headers(func() {
+//			Header("ETag:etag")
+//			Header("Last-Modified:updated_at")
+//		})
+//	})
 func Header(name string, args ...interface{}) {
 	if _, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if len(args) != 0 {
@@ -258,7 +272,22 @@ func Header(name string, args ...interface{}) {
 		return
 	}
 
+	var attName string
+	if i := strings.Index(name, ":"); i > 0 && inResponseHeaders {
+		name, attName = name[:i], name[i+1:]
+	}
 	Attribute(name, args...)
+	if attName == "" {
+		return
+	}
+	if parent, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition); ok {
+		if att := parent.Type.ToObject()[name]; att != nil {
+			if att.Metadata == nil {
+				att.Metadata = make(dslengine.MetadataDefinition)
+			}
+			att.Metadata["header:attribute"] = []string{attName}
+		}
+	}
 }
 
 // Member can be used in: Payload
@@ -296,7 +325,7 @@ func Default(def interface{}) {
 	}
 }
 
-// Example can be used in: Attribute, Header, Param, HashOf, ArrayOf
+// Example can be used in: Attribute, Header, Param, HashOf, ArrayOf, Type, MediaType
 //
 // Example sets the example of an attribute to be used for the documentation:
 //
@@ -310,13 +339,32 @@ func Default(def interface{}) {
 //		Attribute("price", String) //If no Example() is provided, goa generates one that fits your specification
 //	})
 //
-// If you do not want an auto-generated example for an attribute, add NoExample() to it.
+// Example may also set the example of a whole type or media type, the value of object types is
+// a map indexed by attribute names:
+//
+//	var Period = Type("Period", func() {
+//		Attribute("start", DateTime)
+//		Attribute("end", DateTime)
+//		Example(map[string]interface{}{"start": "2017-01-01T00:00:00Z"})
+//	})
+//
+// If you do not want an auto-generated example for an attribute, add NoExample() to it. The
+// examples that are not provided are generated deterministically from the API name so that the
+// documentation and the generated test helpers do not change from one run to the next.
 func Example(exp interface{}) {
-	if a, ok := attributeDefinition(); ok {
-		if pass := a.SetExample(exp); !pass {
-			dslengine.ReportError("example value %#v is incompatible with attribute of type %s",
-				exp, a.Type.Name())
-		}
+	var a *design.AttributeDefinition
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		a = def
+	case *design.MediaTypeDefinition:
+		a = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if pass := a.SetExample(exp); !pass {
+		dslengine.ReportError("example value %#v is incompatible with attribute of type %s",
+			exp, a.Type.Name())
 	}
 }
 
@@ -373,20 +421,64 @@ func Enum(val ...interface{}) {
 	}
 }
 
+// Formats supported by the Format DSL.
+const (
+	// FormatDateTime defines RFC3339 date time values decoded into time.Time.
+	FormatDateTime = "date-time"
+
+	// FormatUUID defines RFC4122 uuid values decoded into uuid.UUID.
+	FormatUUID = "uuid"
+
+	// FormatDuration defines duration values using the syntax accepted by time.ParseDuration.
+	FormatDuration = "duration"
+
+	// FormatEmail defines RFC5322 email addresses.
+	FormatEmail = "email"
+
+	// FormatHostname defines RFC1035 Internet host names.
+	FormatHostname = "hostname"
+
+	// FormatIPv4 defines RFC2373 IPv4 address values.
+	FormatIPv4 = "ipv4"
+
+	// FormatIPv6 defines RFC2373 IPv6 address values.
+	FormatIPv6 = "ipv6"
+
+	// FormatIP defines RFC2373 IPv4 or IPv6 address values decoded into net.IP.
+	FormatIP = "ip"
+
+	// FormatURI defines RFC3986 URI values.
+	FormatURI = "uri"
+
+	// FormatMAC defines IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address values.
+	FormatMAC = "mac"
+
+	// FormatCIDR defines RFC4632 and RFC4291 CIDR notation IP address values.
+	FormatCIDR = "cidr"
+
+	// FormatRegexp defines regular expression syntax accepted by RE2.
+	FormatRegexp = "regexp"
+
+	// FormatRFC1123 defines RFC1123 date time values.
+	FormatRFC1123 = "rfc1123"
+)
+
 // SupportedValidationFormats lists the supported formats for use with the
 // Format DSL.
 var SupportedValidationFormats = []string{
-	"cidr",
-	"date-time",
-	"email",
-	"hostname",
-	"ipv4",
-	"ipv6",
-	"ip",
-	"mac",
-	"regexp",
-	"rfc1123",
-	"uri",
+	FormatCIDR,
+	FormatDateTime,
+	FormatDuration,
+	FormatEmail,
+	FormatHostname,
+	FormatIPv4,
+	FormatIPv6,
+	FormatIP,
+	FormatMAC,
+	FormatRegexp,
+	FormatRFC1123,
+	FormatURI,
+	FormatUUID,
 }
 
 // Format can be used in: Attribute, Header, Param, HashOf, ArrayOf
@@ -395,26 +487,39 @@ var SupportedValidationFormats = []string{
 // See http://json-schema.org/latest/json-schema-validation.html#anchor104.
 // The formats supported by goa are:
 //
-// "date-time": RFC3339 date time
+// FormatDateTime ("date-time"): RFC3339 date time, changes the attribute type to DateTime
+//
+// FormatUUID ("uuid"): RFC4122 uuid, changes the attribute type to UUID
+//
+// FormatDuration ("duration"): duration such as "1h30m", values are decoded into goa.Duration
+//
+// FormatEmail ("email"): RFC5322 email address
 //
-// "email": RFC5322 email address
+// FormatHostname ("hostname"): RFC1035 internet host name
 //
-// "hostname": RFC1035 internet host name
+// FormatIPv4, FormatIPv6, FormatIP ("ipv4", "ipv6", "ip"): RFC2373 IPv4, IPv6 address or either,
+// FormatIP values are decoded into net.IP
 //
-// "ipv4", "ipv6", "ip": RFC2373 IPv4, IPv6 address or either
+// FormatURI ("uri"): RFC3986 URI
 //
-// "uri": RFC3986 URI
+// FormatMAC ("mac"): IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address
 //
-// "mac": IEEE 802 MAC-48, EUI-48 or EUI-64 MAC address
+// FormatCIDR ("cidr"): RFC4632 or RFC4291 CIDR notation IP address
 //
-// "cidr": RFC4632 or RFC4291 CIDR notation IP address
+// FormatRegexp ("regexp"): RE2 regular expression
 //
-// "regexp": RE2 regular expression
+// FormatRFC1123 ("rfc1123"): RFC1123 date time
 //
-// "rfc1123": RFC1123 date time
+// Example:
+//
+//	Param("since", String, func() {
+//		Format(FormatDateTime)
+//	})
 func Format(f string) {
 	if a, ok := attributeDefinition(); ok {
-		if a.Type != nil && a.Type.Kind() != design.StringKind {
+		if a.Type != nil && a.Type.Kind() != design.StringKind &&
+			!(f == FormatDateTime && a.Type.Kind() == design.DateTimeKind) &&
+			!(f == FormatUUID && a.Type.Kind() == design.UUIDKind) {
 			incompatibleAttributeType("format", a.Type.Name(), "a string")
 		} else {
 			supported := false
@@ -427,12 +532,32 @@ func Format(f string) {
 			if !supported {
 				dslengine.ReportError("unsupported format %#v, supported formats are: %s",
 					f, strings.Join(SupportedValidationFormats, ", "))
-			} else {
-				if a.Validation == nil {
-					a.Validation = &dslengine.ValidationDefinition{}
+				return
+			}
+			switch f {
+			case FormatDateTime:
+				a.Type = design.DateTime
+				return
+			case FormatUUID:
+				a.Type = design.UUID
+				return
+			case FormatIP:
+				if a.Metadata == nil {
+					a.Metadata = make(dslengine.MetadataDefinition)
 				}
-				a.Validation.Format = f
+				a.Metadata["struct:field:type"] = []string{"net.IP", "net"}
+				a.Metadata["struct:field:scalar"] = []string{FormatIP}
+			case FormatDuration:
+				if a.Metadata == nil {
+					a.Metadata = make(dslengine.MetadataDefinition)
+				}
+				a.Metadata["struct:field:type"] = []string{"goa.Duration", "github.com/goadesign/goa"}
+				a.Metadata["struct:field:scalar"] = []string{FormatDuration}
 			}
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.Format = f
 		}
 	}
 }
@@ -584,6 +709,54 @@ func Required(names ...string) {
 	}
 }
 
+// CustomValidation can be used in: Payload, Type
+//
+// CustomValidation makes the generated request decoding code call a user provided function once
+// the validation rules defined in the design succeed. This makes it possible to implement
+// domain specific validations such as checks that involve multiple attributes. goagen generates
+// a variable named after the type with the "Validator" suffix in the app package, the function
+// it holds receives the request context and the decoded payload, a non nil error is returned as
+// is to the client if it is a goa error or as a bad request otherwise. Example:
+//
+//	Payload(func() {
+//		Member("start", DateTime)
+//		Member("end", DateTime)
+//		Required("start", "end")
+//		CustomValidation()
+//	})
+//
+// The validator is then set in the service code:
+//
+//	app.CreateReservationPayloadValidator = func(ctx context.Context, p *app.CreateReservationPayload) error {
+//		if p.End.Before(p.Start) {
+//			return goa.ErrInvalidRequest("end must be after start")
+//		}
+//		return nil
+//	}
+//
+func CustomValidation() {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+
+	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType("custom", at.Type.Name(), "an object")
+		return
+	}
+	if at.Metadata == nil {
+		at.Metadata = make(dslengine.MetadataDefinition)
+	}
+	at.Metadata["validate:custom"] = []string{"true"}
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
@@ -213,6 +213,65 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL defining a date-time format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Format(FormatDateTime) }
+		})
+
+		It("produces an attribute of type date time", func() {
+			o := parent.Type.(Object)
+			Ω(o[name].Type).Should(Equal(DateTime))
+			Ω(o[name].Validation).Should(BeNil())
+		})
+	})
+
+	Context("with a name and a DSL defining a uuid format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() { Format(FormatUUID) }
+		})
+
+		It("produces an attribute of type uuid", func() {
+			o := parent.Type.(Object)
+			Ω(o[name].Type).Should(Equal(UUID))
+			Ω(o[name].Validation).Should(BeNil())
+		})
+	})
+
+	Context("with a name and a DSL defining an ip format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Format(FormatIP) }
+		})
+
+		It("produces a string attribute decoded into net.IP", func() {
+			o := parent.Type.(Object)
+			Ω(o[name].Type).Should(Equal(String))
+			Ω(o[name].Validation.Format).Should(Equal(FormatIP))
+			Ω(o[name].Metadata["struct:field:type"]).Should(Equal([]string{"net.IP", "net"}))
+			Ω(o[name].Scalar()).Should(Equal(FormatIP))
+		})
+	})
+
+	Context("with a name and a DSL defining a duration format", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Format(FormatDuration) }
+		})
+
+		It("produces a string attribute decoded into goa.Duration", func() {
+			o := parent.Type.(Object)
+			Ω(o[name].Type).Should(Equal(String))
+			Ω(o[name].Validation.Format).Should(Equal(FormatDuration))
+			Ω(o[name].Metadata["struct:field:type"]).Should(Equal([]string{"goa.Duration", "github.com/goadesign/goa"}))
+			Ω(o[name].Scalar()).Should(Equal(FormatDuration))
+		})
+	})
+
 	Context("with a name, type datetime and a DSL defining a default value", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -381,3 +440,49 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 })
+
+var _ = Describe("CustomValidation", func() {
+	var dsl func()
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+		ut = nil
+	})
+
+	JustBeforeEach(func() {
+		Type("type", dsl)
+		dslengine.Run()
+		ut = Design.Types["type"]
+	})
+
+	Context("on an object", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("start", DateTime)
+				Attribute("end", DateTime)
+				CustomValidation()
+			}
+		})
+
+		It("enables the custom validation", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut.Metadata).Should(HaveKeyWithValue("validate:custom", []string{"true"}))
+		})
+	})
+
+	Context("on an attribute that is not an object", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Attribute("name", String, func() {
+					CustomValidation()
+				})
+			}
+		})
+
+		It("fails", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package apidsl
+
+import (
+	"reflect"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// ConvertTo can be used in: Type
+//
+// ConvertTo makes goagen generate a method on the user type that creates an instance of the given
+// external Go struct initialized from the user type fields. The argument is a value of the
+// external struct type or a pointer to it. CreateFrom generates the reverse conversion. This makes
+// it possible to reuse existing Go structs such as ORM models without writing the mapping code by
+// hand. Example:
+//
+//	var User = Type("User", func() {
+//		Attribute("name", String)
+//		Attribute("email", String, func() {
+//			Metadata("convert:field", "EmailAddress")
+//		})
+//		ConvertTo(models.User{})
+//		CreateFrom(models.User{})
+//	})
+//
+// generates the methods:
+//
+//	func (ut *User) ConvertToModelsUser() *models.User
+//	func (ut *User) CreateFromModelsUser(v *models.User)
+//
+// Each attribute is mapped to the exported field of the external struct with the same name as the
+// generated field, the comparison is case insensitive and ignores underscores. The
+// "convert:field" metadata sets the name of the external field explicitly. Attributes that do not
+// match any field are not converted. The types of the matching fields must be identical, numeric
+// or string types that can be converted into each other or user types that define a conversion to
+// the external field type, goagen reports an error otherwise. Since the generated code refers to
+// the external types directly, any change that makes these types incompatible with the design
+// causes the generated code to fail to compile.
+func ConvertTo(obj interface{}) {
+	conversion(obj, false)
+}
+
+// CreateFrom can be used in: Type
+//
+// CreateFrom makes goagen generate a method on the user type that initializes the user type
+// fields from an instance of the given external Go struct. See ConvertTo.
+func CreateFrom(obj interface{}) {
+	conversion(obj, true)
+}
+
+// conversion records a conversion to or from the type of obj on the user type being defined.
+func conversion(obj interface{}, create bool) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	var ut *design.UserTypeDefinition
+	for _, t := range design.Design.Types {
+		if t.AttributeDefinition == a {
+			ut = t
+			break
+		}
+	}
+	if ut == nil {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	t := reflect.TypeOf(obj)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Name() == "" || t.PkgPath() == "" {
+		dslengine.ReportError("conversion type must be a named struct defined in a package, got %T", obj)
+		return
+	}
+	for _, c := range a.Conversions {
+		if c.External == t && c.Create == create {
+			dslengine.ReportError("conversion to or from %s defined twice", t)
+			return
+		}
+	}
+	a.Conversions = append(a.Conversions, &design.ConversionDefinition{External: t, Create: create})
+}
@@ -42,6 +42,26 @@ func contactDefinition() (*design.ContactDefinition, bool) {
 	return a, ok
 }
 
+// rateLimitDefinition returns true and current context if it is a RateLimitDefinition,
+// nil and false otherwise.
+func rateLimitDefinition() (*design.RateLimitDefinition, bool) {
+	rl, ok := dslengine.CurrentDefinition().(*design.RateLimitDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return rl, ok
+}
+
+// tlsDefinition returns true and current context if it is a TLSDefinition,
+// nil and false otherwise.
+func tlsDefinition() (*design.TLSDefinition, bool) {
+	t, ok := dslengine.CurrentDefinition().(*design.TLSDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return t, ok
+}
+
 // licenseDefinition returns true and current context if it is an APIDefinition,
 // nil and false otherwise.
 func licenseDefinition() (*design.LicenseDefinition, bool) {
@@ -0,0 +1,85 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Error can be used in: Action, Resource
+//
+// Error defines an error that the action may return. Errors defined on a resource apply to all
+// the resource actions. Error takes the name of the error as first argument and optionally the
+// type of the error response body as second argument: a type, a media type or the name of a
+// type or identifier of a media type. The type defaults to ErrorMedia. A last optional
+// anonymous function may set the error description and the status of the HTTP responses that
+// carry the error, the status defaults to 400.
+//
+// goagen generates an error class for errors that use ErrorMedia and a constructor function for
+// errors that use other types. The generated errors implement goa.ServiceError so that the error
+// handler middleware maps them to the HTTP responses defined in the design. Example:
+//
+//	Action("show", func() {
+//		Routing(GET("/:id"))
+//		Error("not_found", func() { // Generates ErrShowBottleNotFound
+//			Description("Bottle not found")
+//			Status(404)
+//		})
+//		Error("conflict", ConflictMedia, func() { // Generates NewShowBottleConflictError
+//			Status(409)
+//		})
+//	})
+//
+func Error(name string, args ...interface{}) {
+	var parent dslengine.Definition
+	var errors *[]*design.ErrorDefinition
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		parent, errors = def, &def.Errors
+	case *design.ResourceDefinition:
+		parent, errors = def, &def.Errors
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if len(args) > 2 {
+		dslengine.ReportError("too many arguments given to Error")
+		return
+	}
+	e := &design.ErrorDefinition{
+		Name:   name,
+		Type:   design.ErrorMedia,
+		Status: 400,
+		Parent: parent,
+	}
+	var dsl func()
+	if len(args) > 0 {
+		if d, ok := args[len(args)-1].(func()); ok {
+			dsl = d
+			args = args[:len(args)-1]
+		}
+	}
+	if len(args) > 0 {
+		switch actual := args[0].(type) {
+		case *design.MediaTypeDefinition:
+			e.Type = actual
+		case *design.UserTypeDefinition:
+			e.Type = actual
+		case string:
+			if mt := design.Design.MediaTypeWithIdentifier(actual); mt != nil {
+				e.Type = mt
+			} else if ut, ok := design.Design.Types[actual]; ok {
+				e.Type = ut
+			} else {
+				dslengine.ReportError("unknown error type %s", actual)
+				return
+			}
+		default:
+			dslengine.ReportError("invalid Error argument, must be a type, a media type or the name of a type")
+			return
+		}
+	}
+	if dsl != nil && !dslengine.Execute(dsl, e) {
+		return
+	}
+	*errors = append(*errors, e)
+}
@@ -0,0 +1,114 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error", func() {
+	var conflict *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		conflict = Type("Conflict", func() {
+			Attribute("reason", String)
+		})
+	})
+
+	Context("with errors defined on the action and the resource", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Error("unavailable", func() {
+					Status(503)
+				})
+				Error("not_found", func() {
+					Status(410)
+				})
+				Action("action", func() {
+					Routing(GET("/"))
+					Error("not_found", func() {
+						Description("not found")
+						Status(404)
+					})
+					Error("conflict", conflict, func() {
+						Status(409)
+					})
+					Error("bad")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("defines the errors", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			errors := Design.Resources["res"].Actions["action"].AllErrors()
+			Ω(errors).Should(HaveLen(4))
+			Ω(errors[0].Name).Should(Equal("not_found"))
+			Ω(errors[0].Description).Should(Equal("not found"))
+			Ω(errors[0].Status).Should(Equal(404))
+			Ω(errors[0].Type).Should(Equal(ErrorMedia))
+			Ω(errors[1].Name).Should(Equal("conflict"))
+			Ω(errors[1].Type).Should(Equal(conflict))
+			Ω(errors[1].Status).Should(Equal(409))
+			Ω(errors[2].Name).Should(Equal("bad"))
+			Ω(errors[2].Status).Should(Equal(400))
+			Ω(errors[3].Name).Should(Equal("unavailable"))
+			Ω(errors[3].Status).Should(Equal(503))
+		})
+	})
+
+	Context("with a type name", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Action("action", func() {
+					Routing(GET("/"))
+					Error("conflict", "Conflict")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("uses the type", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.Resources["res"].Actions["action"].Errors[0].Type).Should(Equal(conflict))
+		})
+	})
+
+	Context("with an invalid status", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Action("action", func() {
+					Routing(GET("/"))
+					Error("not_found", func() {
+						Status(200)
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with duplicate names", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Action("action", func() {
+					Routing(GET("/"))
+					Error("not_found")
+					Error("not_found")
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
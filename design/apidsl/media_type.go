@@ -219,6 +219,107 @@ func ContentType(typ string) {
 	}
 }
 
+// Hypermedia can be used in: MediaType
+//
+// Hypermedia renders the media type using a hypermedia format. The supported formats are "hal"
+// (application/hal+json) and "jsonapi" (application/vnd.api+json). The "href" attribute of the
+// media type provides the self link and the media type links defined with Links provide the other
+// links (HAL) or the relationships (JSON:API). JSON:API resource objects use the "id" attribute as
+// identifier and the optional second argument as type, the snake case media type name by default.
+// Hypermedia also sets the Content-Type response header unless ContentType was used previously.
+// Example:
+//
+//	var BottleMedia = MediaType("application/vnd.bottle", func() {
+//		Hypermedia("jsonapi", "bottles")
+//		Attributes(func() {
+//			Attribute("id", Integer)
+//			Attribute("href", String)
+//			Attribute("account", AccountMedia)
+//		})
+//		Links(func() {
+//			Link("account")
+//		})
+//		View("default", func() {
+//			Attribute("id")
+//			Attribute("href")
+//			Attribute("links")
+//		})
+//	})
+func Hypermedia(format string, resourceType ...string) {
+	mt, ok := mediaTypeDefinition()
+	if !ok {
+		return
+	}
+	var contentType string
+	switch format {
+	case "hal":
+		contentType = "application/hal+json"
+	case "jsonapi":
+		contentType = "application/vnd.api+json"
+	default:
+		dslengine.ReportError(`invalid hypermedia format %#v, must be "hal" or "jsonapi"`, format)
+		return
+	}
+	if mt.Metadata == nil {
+		mt.Metadata = make(dslengine.MetadataDefinition)
+	}
+	mt.Metadata["hypermedia"] = []string{format}
+	if len(resourceType) > 0 {
+		mt.Metadata["hypermedia:type"] = []string{resourceType[0]}
+	}
+	if mt.ContentType == "" {
+		mt.ContentType = contentType
+	}
+}
+
+// ETag can be used in: MediaType
+//
+// ETag makes responses rendering the media type cacheable using entity tags. The optional
+// argument is the name of the media type attribute whose value is the entity tag, the tag is
+// computed from a hash of the rendered media type when omitted. The generated response helpers
+// set the ETag response header and send a 304 Not Modified response instead of the body when the
+// If-None-Match header of GET and HEAD requests matches. Example:
+//
+//	var BottleMedia = MediaType("application/vnd.bottle", func() {
+//		ETag("version")
+//		LastModified("updated_at")
+//		Attributes(func() {
+//			Attribute("version", String)
+//			Attribute("updated_at", DateTime)
+//		})
+//		View("default", func() {
+//			Attribute("version")
+//			Attribute("updated_at")
+//		})
+//	})
+func ETag(attName ...string) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		name := ""
+		if len(attName) > 0 {
+			name = attName[0]
+		}
+		if mt.Metadata == nil {
+			mt.Metadata = make(dslengine.MetadataDefinition)
+		}
+		mt.Metadata["cache:etag"] = []string{name}
+	}
+}
+
+// LastModified can be used in: MediaType
+//
+// LastModified sets the name of the DateTime media type attribute whose value is the date the
+// resource was last modified. The generated response helpers set the Last-Modified response
+// header and send a 304 Not Modified response instead of the body when the If-Modified-Since
+// header of GET and HEAD requests is not older. See ETag for an example.
+func LastModified(attName string) {
+	if mt, ok := mediaTypeDefinition(); ok {
+		if mt.Metadata == nil {
+			mt.Metadata = make(dslengine.MetadataDefinition)
+		}
+		mt.Metadata["cache:last-modified"] = []string{attName}
+	}
+}
+
 // View can be used in: MediaType, Response
 //
 // View adds a new view to a media type. A view has a name and lists attributes that are
@@ -239,6 +340,10 @@ func ContentType(typ string) {
 //			View("extended")	// Use view "extended" to render attribute "origin"
 //		})
 //	})
+//
+// Responses that do not set a view get a response helper for each view of the media type as well
+// as a helper suffixed with "View" (e.g. OKView) which renders the view requested by the client via
+// the "view" query string parameter or the "view" parameter of the Accept header.
 func View(name string, apidsl ...func()) {
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.MediaTypeDefinition:
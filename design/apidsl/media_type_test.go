@@ -89,6 +89,79 @@ var _ = Describe("MediaType", func() {
 		})
 	})
 
+	Context("with a hypermedia format", func() {
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				Hypermedia("jsonapi", "foos")
+				Attributes(func() {
+					Attribute("id")
+				})
+				View("default", func() { Attribute("id") })
+			}
+		})
+
+		It("sets the format and the content type", func() {
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.Validate()).ShouldNot(HaveOccurred())
+			Ω(mt.Metadata).Should(HaveKeyWithValue("hypermedia", []string{"jsonapi"}))
+			Ω(mt.Metadata).Should(HaveKeyWithValue("hypermedia:type", []string{"foos"}))
+			Ω(mt.ContentType).Should(Equal("application/vnd.api+json"))
+		})
+	})
+
+	Context("with an invalid hypermedia format", func() {
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				Hypermedia("siren")
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with cache validators", func() {
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				ETag("version")
+				LastModified("updated_at")
+				Attributes(func() {
+					Attribute("version", Integer)
+					Attribute("updated_at", DateTime)
+				})
+				View("default", func() { Attribute("version") })
+			}
+		})
+
+		It("records the validator attributes", func() {
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.Validate()).ShouldNot(HaveOccurred())
+			Ω(mt.Metadata).Should(HaveKeyWithValue("cache:etag", []string{"version"}))
+			Ω(mt.Metadata).Should(HaveKeyWithValue("cache:last-modified", []string{"updated_at"}))
+		})
+	})
+
+	Context("with a last modified attribute that is not a date time", func() {
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				LastModified("updated_at")
+				Attributes(func() {
+					Attribute("updated_at", String)
+				})
+				View("default", func() { Attribute("updated_at") })
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(mt.Validate()).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a description", func() {
 		const description = "desc"
 
@@ -822,4 +895,44 @@ var _ = Describe("Example", func() {
 			Ω(attr.Example).Should(BeNil())
 		})
 	})
+
+	Context("defined examples in types and media types", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			ProjectedMediaTypes = make(MediaTypeRoot)
+		})
+
+		It("sets the type and media type examples", func() {
+			ut := Type("period", func() {
+				Attribute("start", String)
+				Attribute("end", String)
+				Example(map[string]interface{}{"start": "today"})
+			})
+			mt := MediaType("application/vnd.example+json", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+				})
+				Example(map[string]interface{}{"id": 1})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+
+			Ω(ut.Example).Should(Equal(map[string]interface{}{"start": "today"}))
+			Ω(mt.Example).Should(Equal(map[string]interface{}{"id": 1}))
+		})
+
+		It("reports incompatible examples", func() {
+			Type("period", func() {
+				Attribute("start", String)
+				Example("today")
+			})
+
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
 })
@@ -27,13 +27,36 @@ import (
 //        Metadata("struct:field:type", "json.RawMessage", "encoding/json")
 //        Metadata("struct:field:type", "mypackage.MyType", "github.com/me/mypackage")
 //
+// `struct:field:pointer`: controls whether the Go struct field generated for an optional primitive
+// attribute is a pointer. Setting the value to "false" makes goagen generate a field whose zero
+// value denotes the absence of the attribute, the attribute validations do not apply to the zero
+// value. Applicable to attributes and to the API where it sets the default for all attributes.
+//
+//        Metadata("struct:field:pointer", "false")
+//
 // `struct:tag:xxx`: sets the struct field tag xxx on generated Go structs.  Overrides tags that
 // goagen would otherwise set.  If the metadata value is a slice then the strings are joined with
-// the space character as separator.
+// the comma character as separator.
 // Applicable to attributes only.
 //
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
+//        Metadata("struct:tag:validate", "required,email")
+//
+// `struct:tags`: lists additional tags set on all the fields of the generated Go struct. The value
+// of each tag is the attribute name followed by ",omitempty" if the attribute is not required
+// which makes it possible to use the generated types with libraries such as ORMs directly. The
+// `struct:tag:xxx` metadata of an attribute overrides the tag xxx of the corresponding field.
+// Applicable to types, media types and payloads.
+//
+//        Metadata("struct:tags", "bson", "db")
+//
+// `log:redact`: flags the attribute as sensitive. The generated payload and user types define a
+// RedactedFields method listing the names of the flagged attributes, the LogRequest middleware
+// does not log the values of these fields.
+// Applicable to attributes only.
+//
+//        Metadata("log:redact")
 //
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
@@ -54,18 +77,44 @@ import (
 //        Metadata("swagger:tag:Backend:url", "http://example.com")
 //        Metadata("swagger:tag:Backend:url:desc", "See more docs here")
 //
-// `swagger:extension:xxx`: sets the Swagger extensions xxx. It can have any valid JSON format value.
+// `swagger:extension:xxx` or `openapi:extension:xxx`: sets the Swagger and OpenAPI extensions xxx.
+// The extension name must start with "x-". It can have any valid JSON format value, other values
+// are used as strings.
 // Applicable to
 // api as within the info and tag object,
 // resource as within the paths object,
 // action as within the path-item object,
 // route as within the operation object,
 // param as within the parameter object,
-// response as within the response object
+// response as within the response object,
+// type, media type and attribute as within the schema object
 // and security as within the security-scheme object.
 // See https://github.com/OAI/OpenAPI-Specification/blob/master/guidelines/EXTENSIONS.md.
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
+//        Metadata("openapi:extension:x-amazon-apigateway-integration", `{"type":"http_proxy"}`)
+//
+// `openapi:link:xxx`: defines the OpenAPI 3.0 link xxx. The first value is the target operation
+// ID, the following values map the target parameters to runtime expressions.
+// Applicable to responses.
+//
+//        Metadata("openapi:link:ShowBottle", "bottle#show", "bottleID=$response.body#/id")
+//
+// `openapi:callback:xxx`: defines the OpenAPI 3.0 callback xxx. The first value is the runtime
+// expression that computes the callback URL, the second value identifies the action describing
+// the callback request with the form "resource#action".
+// Applicable to actions.
+//
+//        Metadata("openapi:callback:onRated", "{$request.body#/callback}", "hooks#rated")
+//
+// `retry:attempts`, `retry:idempotent` and `retry:disable`: override the retry policy applied by
+// the generated clients created with the WithRetry option. `retry:attempts` sets the maximum
+// number of attempts, `retry:idempotent` allows retrying actions whose HTTP method is not
+// idempotent and `retry:disable` disables retries.
+// Applicable to resources and actions.
+//
+//        Metadata("retry:attempts", "5")
+//        Metadata("retry:idempotent")
 //
 // The special key names listed above may be used as follows:
 //
@@ -133,6 +133,28 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("with files using a braced wildcard and caching rules", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Files("/public/{*path}", "./static", func() {
+					CacheControl(3600, "public")
+				})
+			}
+		})
+
+		It("sets the request path and caching rules", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.FileServers).Should(HaveLen(1))
+			fs := res.FileServers[0]
+			Ω(fs.RequestPath).Should(Equal("/public/*path"))
+			Ω(fs.IsDir()).Should(BeTrue())
+			Ω(fs.CacheControl).Should(Equal([]string{"max-age=3600", "public"}))
+			Ω(fs.MaxAge).Should(Equal(3600))
+		})
+	})
+
 	Context("with a canonical action that does not exist", func() {
 		const can = "can"
 
@@ -208,6 +230,37 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("with a braced base path parameter", func() {
+		var basePath string
+
+		BeforeEach(func() {
+			name = "foo"
+			basePath = "basePath/{paramID:[0-9]+}"
+			dsl = func() {
+				BasePath(basePath)
+				Params(func() {
+					Param("paramID")
+				})
+			}
+		})
+
+		It("converts the parameter and records its pattern", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.BasePath).Should(Equal("basePath/:paramID"))
+			Ω(res.BasePathPatterns).Should(Equal(map[string]string{"paramID": "^(?:[0-9]+)$"}))
+		})
+
+		Context("with an invalid pattern", func() {
+			BeforeEach(func() {
+				basePath = "basePath/{paramID:[0-9}"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a media type name", func() {
 		const mediaType = "application/mt"
 
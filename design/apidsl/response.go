@@ -1,6 +1,10 @@
 package apidsl
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -113,13 +117,184 @@ func Response(name string, paramsAndDSL ...interface{}) {
 	}
 }
 
-// Status can be used in: Response, ResponseTemplate
+// Status can be used in: Response, ResponseTemplate, Error
 //
-// Status sets the Response status.
+// Status sets the Response status or the status of the responses that carry the error.
 func Status(status int) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResponseDefinition:
+		def.Status = status
+	case *design.ErrorDefinition:
+		def.Status = status
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Tag can be used in: Response
+//
+// Tag associates the response with a value of an attribute of the response media type. Actions
+// that define multiple tagged responses get a Respond helper method on their context that selects
+// the response to send - and thus its status, headers and view - by comparing the value of the
+// tag attribute with the tag values. The tag attribute must be a string rendered by the view of
+// each tagged response, Respond projects its argument onto the view of the selected response. An
+// untagged response of the action that uses the same media type is sent when no tag value
+// matches. Example:
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Response(Created, BottleMedia, func() {
+//			Tag("outcome", "created")
+//		})
+//		Response(Accepted, BottleMedia, func() {
+//			Tag("outcome", "accepted")
+//		})
+//		Response(OK, BottleMedia)     // Sent if outcome is neither "created" nor "accepted"
+//	})
+func Tag(attName, value string) {
+	if r, ok := responseDefinition(); ok {
+		r.TagAttribute = attName
+		r.TagValue = value
+	}
+}
+
+// Redirect can be used in: Action, Resource, Response
+//
+// Redirect defines a redirect response: it sets the response status to code and the value of the
+// Location header to url. The URL may contain wildcards using the same syntax as action routes,
+// each wildcard refers to a required primitive attribute of the response media type rendered by
+// the response view. The generated response helper then takes the media type as argument, builds
+// the Location header from its attributes and writes no body. Used in an action or resource
+// Redirect defines the goa default response for the status code. Examples:
+//
+//	Action("legacy", func() {
+//		Routing(GET("/old"))
+//		Redirect("/new", 301)                   // Defines the MovedPermanently response
+//	})
+//
+//	Action("create", func() {
+//		Routing(POST(""))
+//		Response(Found, BottleMedia, func() {
+//			Redirect("/bottles/:id", 302)   // Location built from the "id" attribute
+//		})
+//	})
+func Redirect(url string, code int) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResponseDefinition:
+		def.Status = code
+		def.RedirectURL = url
+		location := &design.AttributeDefinition{Type: design.Object{"Location": {Type: design.String}}}
+		def.Headers = def.Headers.Merge(location)
+	case *design.ActionDefinition, *design.ResourceDefinition:
+		for name, resp := range design.Design.DefaultResponses {
+			if resp.Status == code {
+				Response(name, func() { Redirect(url, code) })
+				return
+			}
+		}
+		dslengine.ReportError("invalid redirect status %d", code)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// CacheControl can be used in: Response, Files
+//
+// CacheControl defines the caching rules of the response: the generated response helper sets the
+// Cache-Control header to the max-age directive computed from maxAge - the number of seconds the
+// response may be cached for - followed by the given directives. It also sets the Expires header
+// for HTTP/1.0 caches. The directives must be standard response directives: "public", "private",
+// "no-cache", "no-store", "no-transform", "must-revalidate", "proxy-revalidate", "immutable" or
+// one of "s-maxage", "stale-while-revalidate" and "stale-if-error" followed by "=" and a number
+// of seconds. The max-age directive and the Expires header are omitted if the directives include
+// "no-store". Use Vary to list the request headers the response depends on. Example:
+//
+//	Response(OK, BottleMedia, func() {
+//		CacheControl(300, "public", "stale-while-revalidate=60")
+//		Vary("Accept", "Accept-Encoding")
+//	})
+//
+// When used in a Files definition the headers are sent with the files served successfully.
+func CacheControl(maxAge int, directives ...string) {
+	var (
+		r  *design.ResponseDefinition
+		fs *design.FileServerDefinition
+	)
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResponseDefinition:
+		r = def
+	case *design.FileServerDefinition:
+		fs = def
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if maxAge < 0 {
+		dslengine.ReportError("invalid max age %d, must be positive", maxAge)
+		return
+	}
+	noStore := false
+	for _, d := range directives {
+		if err := validateCacheDirective(d); err != nil {
+			dslengine.ReportError(err.Error())
+			return
+		}
+		if d == "no-store" {
+			noStore = true
+		}
+	}
+	var cc []string
+	if noStore {
+		maxAge = 0
+	} else {
+		cc = []string{fmt.Sprintf("max-age=%d", maxAge)}
+	}
+	cc = append(cc, directives...)
+	if fs != nil {
+		fs.CacheControl = cc
+		fs.MaxAge = maxAge
+		return
+	}
+	r.CacheControl = cc
+	r.MaxAge = maxAge
+	headers := design.Object{"Cache-Control": {Type: design.String}}
+	if !noStore {
+		headers["Expires"] = &design.AttributeDefinition{Type: design.String}
+	}
+	r.Headers = r.Headers.Merge(&design.AttributeDefinition{Type: headers})
+}
+
+// Vary can be used in: Response
+//
+// Vary lists the names of the request headers that select the representation sent in the
+// response, the generated response helper sets the Vary header accordingly so that caches store
+// one response per value of these headers. See CacheControl.
+func Vary(headers ...string) {
 	if r, ok := responseDefinition(); ok {
-		r.Status = status
+		r.Vary = append(r.Vary, headers...)
+		vary := design.Object{"Vary": {Type: design.String}}
+		r.Headers = r.Headers.Merge(&design.AttributeDefinition{Type: vary})
+	}
+}
+
+// validateCacheDirective returns an error if d is not a valid Cache-Control response directive.
+func validateCacheDirective(d string) error {
+	switch d {
+	case "public", "private", "no-cache", "no-store", "no-transform", "must-revalidate",
+		"proxy-revalidate", "immutable":
+		return nil
+	}
+	elems := strings.SplitN(d, "=", 2)
+	switch elems[0] {
+	case "s-maxage", "stale-while-revalidate", "stale-if-error":
+		if len(elems) == 2 {
+			if n, err := strconv.Atoi(elems[1]); err == nil && n >= 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid cache directive %#v, value must be a number of seconds", d)
 	}
+	return fmt.Errorf("invalid cache directive %#v", d)
 }
 
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
@@ -177,6 +177,42 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with headers mapped to the media type attributes", func() {
+		BeforeEach(func() {
+			name = "foo"
+			mt := MediaType("application/vnd.etag", func() {
+				Attributes(func() {
+					Attribute("etag")
+				})
+				View("default", func() {
+					Attribute("etag")
+				})
+			})
+			dsl = func() {
+				Status(200)
+				Media(mt)
+				Headers(func() {
+					Header("ETag:etag")
+					Header("X-Missing:missing")
+				})
+			}
+		})
+
+		It("records the mapping", func() {
+			Ω(res).ShouldNot(BeNil())
+			o := res.Headers.Type.ToObject()
+			Ω(o).Should(HaveKey("ETag"))
+			Ω(o["ETag"].Metadata).Should(HaveKeyWithValue("header:attribute", []string{"etag"}))
+		})
+
+		It("reports mappings to unknown attributes", func() {
+			err := res.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("X-Missing"))
+			Ω(err.Error()).ShouldNot(ContainSubstring("ETag"))
+		})
+	})
+
 	Context("not from the goa default definitions", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -198,3 +234,128 @@ var _ = Describe("Response", func() {
 	})
 
 })
+
+var _ = Describe("Redirect", func() {
+	var url string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		url = "/bottles/:id"
+	})
+
+	JustBeforeEach(func() {
+		mt := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer)
+				Attribute("name")
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+			})
+		})
+		Resource("foo", func() {
+			Action("legacy", func() {
+				Routing(GET("/legacy"))
+				Redirect("/new", 301)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Response(Found, mt, func() {
+					Redirect(url, 302)
+				})
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("defines the redirect responses", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		legacy := Design.Resources["foo"].Actions["legacy"].Responses[MovedPermanently]
+		Ω(legacy).ShouldNot(BeNil())
+		Ω(legacy.Status).Should(Equal(301))
+		Ω(legacy.RedirectURL).Should(Equal("/new"))
+		Ω(legacy.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		found := Design.Resources["foo"].Actions["create"].Responses[Found]
+		Ω(found.RedirectURL).Should(Equal("/bottles/:id"))
+	})
+
+	Context("with a wildcard that is not a media type attribute", func() {
+		BeforeEach(func() {
+			url = "/bottles/:origin"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("wildcard origin is not an attribute"))
+		})
+	})
+
+	Context("with a wildcard that is not rendered by the response view", func() {
+		BeforeEach(func() {
+			url = "/bottles/:name"
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("wildcard name is not rendered by view default"))
+		})
+	})
+})
+
+var _ = Describe("CacheControl", func() {
+	var directives []string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		directives = []string{"public", "stale-while-revalidate=60"}
+	})
+
+	JustBeforeEach(func() {
+		Resource("foo", func() {
+			Action("show", func() {
+				Routing(GET(""))
+				Response(OK, func() {
+					CacheControl(300, directives...)
+					Vary("Accept")
+				})
+			})
+		})
+		dslengine.Run()
+	})
+
+	It("sets the caching rules and documents the headers", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		resp := Design.Resources["foo"].Actions["show"].Responses[OK]
+		Ω(resp.CacheControl).Should(Equal([]string{"max-age=300", "public", "stale-while-revalidate=60"}))
+		Ω(resp.MaxAge).Should(Equal(300))
+		Ω(resp.Vary).Should(Equal([]string{"Accept"}))
+		headers := resp.Headers.Type.ToObject()
+		Ω(headers).Should(HaveKey("Cache-Control"))
+		Ω(headers).Should(HaveKey("Expires"))
+		Ω(headers).Should(HaveKey("Vary"))
+	})
+
+	Context("with the no-store directive", func() {
+		BeforeEach(func() {
+			directives = []string{"no-store"}
+		})
+
+		It("omits the max age", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			resp := Design.Resources["foo"].Actions["show"].Responses[OK]
+			Ω(resp.CacheControl).Should(Equal([]string{"no-store"}))
+			Ω(resp.Headers.Type.ToObject()).ShouldNot(HaveKey("Expires"))
+		})
+	})
+
+	Context("with an invalid directive", func() {
+		BeforeEach(func() {
+			directives = []string{"s-maxage=forever"}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,80 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Scalar defines a custom primitive type backed by a user Go type. The result can be used anywhere
+// a primitive type can, for example to define attributes, parameters, headers or the elements of
+// arrays and hashes. Scalar takes the name of the type, the Go type given as a qualified type
+// name and the import path of the package that defines it.
+//
+// The Go type must implement encoding.TextMarshaler and encoding.TextUnmarshaler. The generated
+// structs and contexts use the Go type in place of string, the generated code parses the
+// parameters, headers and cookies with UnmarshalText and the JSON encoding relies on the text
+// marshaling methods. The values are described as strings in the OpenAPI and JSON schema
+// documents with the name of the scalar as format.
+//
+// The optional DSL may set the description, example and validations of the scalar. The
+// validations are documented in the OpenAPI and JSON schema documents, the generated code
+// delegates them to UnmarshalText. Example:
+//
+//	var Money = Scalar("Money", "money.Amount", "github.com/acme/money", func() {
+//		Description("Amount followed by the ISO 4217 currency code")
+//		Pattern(`^\d+(\.\d+)? [A-Z]{3}$`)
+//		Example("12.50 USD")
+//	})
+//
+//	var Bottle = Type("bottle", func() {
+//		Attribute("price", Money)
+//		Attribute("prices", HashOf(String, Money))
+//	})
+func Scalar(name, goType, pkgPath string, dsl ...func()) *design.UserTypeDefinition {
+	att := &design.AttributeDefinition{
+		Type: design.String,
+		Metadata: dslengine.MetadataDefinition{
+			"struct:field:type":   {goType, pkgPath},
+			"struct:field:scalar": {name},
+		},
+	}
+	if len(dsl) > 1 {
+		dslengine.ReportError("Scalar: too many arguments")
+	} else if len(dsl) == 1 {
+		dslengine.Execute(dsl[0], att)
+	}
+	return &design.UserTypeDefinition{TypeName: name, AttributeDefinition: att}
+}
+
+// inlineScalar replaces the custom scalar type of the given attribute with the primitive type
+// of the scalar. It copies the scalar description, validations, metadata and example that are not
+// already defined by the attribute.
+func inlineScalar(att *design.AttributeDefinition) {
+	ut, ok := att.Type.(*design.UserTypeDefinition)
+	if !ok || ut.Scalar() == "" {
+		return
+	}
+	scalar := design.DupAtt(ut.AttributeDefinition)
+	att.Type = scalar.Type
+	if att.Description == "" {
+		att.Description = scalar.Description
+	}
+	if scalar.Validation != nil {
+		if att.Validation == nil {
+			att.Validation = scalar.Validation
+		} else {
+			att.Validation.Merge(scalar.Validation)
+		}
+	}
+	md := make(dslengine.MetadataDefinition)
+	for k, v := range scalar.Metadata {
+		md[k] = v
+	}
+	for k, v := range att.Metadata {
+		md[k] = v
+	}
+	att.Metadata = md
+	if att.Example == nil {
+		att.Example = scalar.Example
+	}
+}
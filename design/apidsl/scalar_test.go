@@ -0,0 +1,71 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scalar", func() {
+	var scalar *UserTypeDefinition
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		scalar = Scalar("Money", "money.Amount", "github.com/acme/money", func() {
+			Description("An amount")
+			Pattern(`^\d+ [A-Z]{3}$`)
+			Example("12 USD")
+		})
+	})
+
+	JustBeforeEach(func() {
+		Type("bottle", func() {
+			Attribute("price", scalar)
+			Attribute("min", scalar, "Minimum price", func() {
+				MinLength(5)
+			})
+			Attribute("prices", ArrayOf(scalar))
+			Attribute("rates", HashOf(String, scalar))
+		})
+		dslengine.Run()
+		ut = Design.Types["bottle"]
+	})
+
+	It("defines the scalar", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(scalar.Scalar()).Should(Equal("Money"))
+		Ω(scalar.Type).Should(Equal(String))
+		Ω(scalar.Metadata["struct:field:type"]).Should(Equal([]string{"money.Amount", "github.com/acme/money"}))
+	})
+
+	It("inlines the scalar in attributes", func() {
+		Ω(ut).ShouldNot(BeNil())
+		price := ut.Type.ToObject()["price"]
+		Ω(price.Type).Should(Equal(String))
+		Ω(price.Scalar()).Should(Equal("Money"))
+		Ω(price.Description).Should(Equal("An amount"))
+		Ω(price.Validation).ShouldNot(BeNil())
+		Ω(price.Validation.Pattern).Should(Equal(`^\d+ [A-Z]{3}$`))
+		Ω(price.Example).Should(Equal("12 USD"))
+	})
+
+	It("keeps the attribute description and merges the validations", func() {
+		min := ut.Type.ToObject()["min"]
+		Ω(min.Description).Should(Equal("Minimum price"))
+		Ω(min.Validation).ShouldNot(BeNil())
+		Ω(*min.Validation.MinLength).Should(Equal(5))
+		Ω(min.Validation.Pattern).Should(Equal(`^\d+ [A-Z]{3}$`))
+	})
+
+	It("inlines the scalar in arrays and hashes", func() {
+		elem := ut.Type.ToObject()["prices"].Type.ToArray().ElemType
+		Ω(elem.Type).Should(Equal(String))
+		Ω(elem.Scalar()).Should(Equal("Money"))
+		val := ut.Type.ToObject()["rates"].Type.ToHash().ElemType
+		Ω(val.Type).Should(Equal(String))
+		Ω(val.Scalar()).Should(Equal("Money"))
+	})
+})
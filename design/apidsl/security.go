@@ -231,7 +231,7 @@ func OAuth2Security(name string, dsl ...func()) *design.SecuritySchemeDefinition
 // Example:
 //
 //    JWTSecurity("jwt", func() {
-//        Header("Authorization")
+//        Header("Authorization")     // or Query("token") or Cookie("token")
 //        TokenURL("https://example.com/token")
 //        Scope("my_system:write", "Write to the system")
 //        Scope("my_system:read", "Read anything in there")
@@ -299,7 +299,7 @@ func inHeader(headerName string) {
 	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if current.Kind == design.APIKeySecurityKind || current.Kind == design.JWTSecurityKind {
 			if current.In != "" {
-				dslengine.ReportError("'In' previously defined through Header or Query")
+				dslengine.ReportError("'In' previously defined through Header, Query or Cookie")
 				return
 			}
 			current.In = "header"
@@ -318,7 +318,7 @@ func Query(parameterName string) {
 	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if current.Kind == design.APIKeySecurityKind || current.Kind == design.JWTSecurityKind {
 			if current.In != "" {
-				dslengine.ReportError("'In' previously defined through Header or Query")
+				dslengine.ReportError("'In' previously defined through Header, Query or Cookie")
 				return
 			}
 			current.In = "query"
@@ -333,57 +333,55 @@ func Query(parameterName string) {
 //
 // AccessCodeFlow defines an "access code" OAuth2 flow.  Use within an OAuth2Security definition.
 func AccessCodeFlow(authorizationURL, tokenURL string) {
-	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
-		if current.Kind == design.OAuth2SecurityKind {
-			current.Flow = "accessCode"
-			current.AuthorizationURL = authorizationURL
-			current.TokenURL = tokenURL
-			return
-		}
+	if current, ok := oauth2Flow("accessCode"); ok {
+		current.AuthorizationURL = authorizationURL
+		current.TokenURL = tokenURL
 	}
-	dslengine.IncompatibleDSL()
 }
 
 // ApplicationFlow can be used in: OAuth2Security
 //
 // ApplicationFlow defines an "application" OAuth2 flow.  Use within an OAuth2Security definition.
 func ApplicationFlow(tokenURL string) {
-	if parent, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
-		if parent.Kind == design.OAuth2SecurityKind {
-			parent.Flow = "application"
-			parent.TokenURL = tokenURL
-			return
-		}
+	if parent, ok := oauth2Flow("application"); ok {
+		parent.TokenURL = tokenURL
 	}
-	dslengine.IncompatibleDSL()
 }
 
 // PasswordFlow can be used in: OAuth2Security
 //
 // PasswordFlow defines a "password" OAuth2 flow.  Use within an OAuth2Security definition.
 func PasswordFlow(tokenURL string) {
-	if parent, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
-		if parent.Kind == design.OAuth2SecurityKind {
-			parent.Flow = "password"
-			parent.TokenURL = tokenURL
-			return
-		}
+	if parent, ok := oauth2Flow("password"); ok {
+		parent.TokenURL = tokenURL
 	}
-	dslengine.IncompatibleDSL()
 }
 
 // ImplicitFlow can be used in: OAuth2Security
 //
 // ImplicitFlow defines an "implicit" OAuth2 flow.  Use within an OAuth2Security definition.
 func ImplicitFlow(authorizationURL string) {
+	if parent, ok := oauth2Flow("implicit"); ok {
+		parent.AuthorizationURL = authorizationURL
+	}
+}
+
+// oauth2Flow sets the flow of the current OAuth2 security scheme definition and returns it.
+// It reports an error and returns false if the current definition is not an OAuth2 security
+// scheme or if it already defines a flow.
+func oauth2Flow(flow string) (*design.SecuritySchemeDefinition, bool) {
 	if parent, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
 		if parent.Kind == design.OAuth2SecurityKind {
-			parent.Flow = "implicit"
-			parent.AuthorizationURL = authorizationURL
-			return
+			if parent.Flow != "" {
+				dslengine.ReportError("OAuth2 flow previously defined as %q", parent.Flow)
+				return nil, false
+			}
+			parent.Flow = flow
+			return parent, true
 		}
 	}
 	dslengine.IncompatibleDSL()
+	return nil, false
 }
 
 // TokenURL can be used in: JWTSecurity
@@ -401,3 +399,78 @@ func TokenURL(tokenURL string) {
 	}
 	dslengine.IncompatibleDSL()
 }
+
+// Cookie can be used in: APIKeySecurity, JWTSecurity, Cookies
+//
+// Cookie defines that an APIKeySecurity or JWTSecurity implementation must check in the cookie
+// named "cookieName" to get the key or token.
+//
+// Within Cookies, Cookie defines a request or response cookie. It is an alias of Attribute, see
+// Cookies for an example.
+func Cookie(cookieName string, args ...interface{}) {
+	if _, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition); ok {
+		Attribute(cookieName, args...)
+		return
+	}
+	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
+		if len(args) != 0 {
+			dslengine.ReportError("do not specify args")
+			return
+		}
+		if current.Kind == design.APIKeySecurityKind || current.Kind == design.JWTSecurityKind {
+			if current.In != "" {
+				dslengine.ReportError("'In' previously defined through Header, Query or Cookie")
+				return
+			}
+			current.In = "cookie"
+			current.Name = cookieName
+			return
+		}
+	}
+	dslengine.IncompatibleDSL()
+}
+
+// Username can be used in: Payload
+//
+// Username defines the payload attribute that holds the username of the basic auth credentials.
+// The generated code reads the credentials from the request Authorization header and sets the
+// attribute before validating the payload. Requests that do not carry basic auth credentials are
+// rejected with a 401 response that includes a WWW-Authenticate header. The attribute is always
+// of type String, Username accepts an optional description and DSL like Attribute. Example:
+//
+//	Action("login", func() {
+//		Security(BasicAuth)
+//		Payload(func() {
+//			Username("user", "Login name")
+//			Password("pass")
+//			Required("user", "pass")
+//		})
+//	})
+func Username(name string, args ...interface{}) {
+	credentialAttribute("security:username", name, args...)
+}
+
+// Password can be used in: Payload
+//
+// Password defines the payload attribute that holds the password of the basic auth credentials,
+// see Username.
+func Password(name string, args ...interface{}) {
+	credentialAttribute("security:password", name, args...)
+}
+
+// credentialAttribute defines a string attribute of the current payload and flags it with the
+// given metadata key.
+func credentialAttribute(key, name string, args ...interface{}) {
+	parent, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	Attribute(name, append([]interface{}{design.String}, args...)...)
+	if att := parent.Type.ToObject()[name]; att != nil {
+		if att.Metadata == nil {
+			att.Metadata = make(dslengine.MetadataDefinition)
+		}
+		att.Metadata[key] = []string{"true"}
+	}
+}
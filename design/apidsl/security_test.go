@@ -127,6 +127,70 @@ var _ = Describe("Security", func() {
 			dslengine.Run()
 			Ω(dslengine.Errors).Should(HaveOccurred())
 		})
+		It("should flag the payload credentials attributes", func() {
+			var basic *SecuritySchemeDefinition
+			API("secure", func() {
+				basic = BasicAuthSecurity("basic_authz")
+			})
+			Resource("session", func() {
+				Action("login", func() {
+					Security(basic)
+					Routing(POST("/login"))
+					Payload(func() {
+						Username("user", "Login name")
+						Password("pass")
+					})
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			action := Design.Resources["session"].Actions["login"]
+			user, pass := action.BasicAuthCredentials()
+			Ω(user).Should(Equal("user"))
+			Ω(pass).Should(Equal("pass"))
+			Ω(action.Payload.ToObject()["user"].Type).Should(Equal(String))
+			Ω(action.Payload.ToObject()["user"].Description).Should(Equal("Login name"))
+		})
+
+		It("should fail when the action does not use basic auth", func() {
+			API("secure", nil)
+			Resource("session", func() {
+				Action("login", func() {
+					Routing(POST("/login"))
+					Payload(func() {
+						Username("user")
+						Password("pass")
+					})
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with jwt security", func() {
+		It("should read the token from a cookie", func() {
+			API("", func() {
+				JWTSecurity("jwt", func() {
+					Cookie("token")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes[0].In).Should(Equal("cookie"))
+			Ω(Design.SecuritySchemes[0].Name).Should(Equal("token"))
+		})
+
+		It("should fail because of duplicate In declaration", func() {
+			API("", func() {
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+					Cookie("token")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
 	})
 
 	Context("with oauth2 security", func() {
@@ -163,6 +227,27 @@ var _ = Describe("Security", func() {
 			Ω(scheme.Scopes["scope:2"]).Should(Equal("Desc 2"))
 		})
 
+		It("should fail because of a missing flow", func() {
+			API("", func() {
+				OAuth2Security("googAuthz", func() {
+					Scope("scope:1", "Desc 1")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
+		It("should fail because of multiple flows", func() {
+			API("", func() {
+				OAuth2Security("googAuthz", func() {
+					ImplicitFlow("/auth")
+					PasswordFlow("/token")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
 		It("should fail because of invalid declaration of Header", func() {
 			API("", func() {
 				OAuth2Security("googAuthz", func() {
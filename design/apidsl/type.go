@@ -34,6 +34,14 @@ import (
 //		Attribute("Country")
 //	})
 //
+// Types may reference themselves directly or indirectly, use the type name to refer to a type
+// whose definition is not complete yet:
+//
+//	var Comment = Type("Comment", func() {
+//		Attribute("body", String)
+//		Attribute("replies", ArrayOf("Comment"))
+//	})
+//
 // This function returns the newly defined type so the value can be used throughout the dsl.
 func Type(name string, dsl func()) *design.UserTypeDefinition {
 	if design.Design.Types == nil {
@@ -61,6 +69,89 @@ func Type(name string, dsl func()) *design.UserTypeDefinition {
 	return t
 }
 
+// Extend can be used in: Type, MediaType, Attributes, Payload, Attribute
+//
+// Extend makes the type being defined inherit all the attributes of the given base type together
+// with their validations and the list of required attributes. Attributes defined explicitly
+// override the inherited attributes with the same name, regardless of whether they are defined
+// before or after the call to Extend. Contrary to Reference which only provides defaults for the
+// attributes listed explicitly, Extend adds all the base type attributes:
+//
+//	var Entity = Type("Entity", func() {
+//		Attribute("id", UUID)
+//		Attribute("created_at", DateTime)
+//		Required("id")
+//	})
+//
+//	var Bottle = Type("Bottle", func() {
+//		Extend(Entity)
+//		Attribute("name", String, func() {
+//			MinLength(3)
+//		})
+//		Attribute("created_at", String) // Overrides the Entity attribute
+//	})
+//
+// The generated Go struct of a type that extends a base type embeds the base type struct and only
+// declares the fields that differ from the base type, the generated JSON schemas and OpenAPI
+// specifications list the base type definition under "allOf". Extend may be called multiple
+// times to extend multiple base types.
+func Extend(t design.DataType) {
+	var parent *design.AttributeDefinition
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		parent = def
+	case *design.MediaTypeDefinition:
+		parent = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	var base *design.UserTypeDefinition
+	switch actual := t.(type) {
+	case *design.MediaTypeDefinition:
+		if actual.DSLFunc != nil {
+			dsl := actual.DSLFunc
+			actual.DSLFunc = nil
+			dslengine.Execute(dsl, actual)
+		}
+		base = actual.UserTypeDefinition
+	case *design.UserTypeDefinition:
+		if actual.DSLFunc != nil {
+			dsl := actual.DSLFunc
+			actual.DSLFunc = nil
+			dslengine.Execute(dsl, actual.AttributeDefinition)
+		}
+		base = actual
+	default:
+		dslengine.ReportError("Extend: base type must be a type or a media type")
+		return
+	}
+	if base.IsUnion() || !base.Type.IsObject() {
+		dslengine.ReportError("Extend: base type %#v must be an object", base.TypeName)
+		return
+	}
+	if parent.Type == nil {
+		parent.Type = make(design.Object)
+	}
+	obj, ok := parent.Type.(design.Object)
+	if !ok {
+		dslengine.ReportError("can't extend attribute of type %s", parent.Type.Name())
+		return
+	}
+	for n, att := range base.ToObject() {
+		if _, ok := obj[n]; !ok {
+			obj[n] = design.DupAtt(att)
+		}
+	}
+	if base.Validation != nil && len(base.Validation.Required) > 0 {
+		if parent.Validation == nil {
+			parent.Validation = &dslengine.ValidationDefinition{}
+		}
+		parent.Validation.AddRequired(base.Validation.Required)
+	}
+	parent.Bases = append(parent.Bases, t)
+}
+
 // ArrayOf creates an array type from its element type. The result can be used
 // anywhere a type can. Examples:
 //
@@ -103,6 +194,7 @@ func ArrayOf(v interface{}, dsl ...func()) *design.Array {
 		return res
 	}
 	at := design.AttributeDefinition{Type: t}
+	inlineScalar(&at)
 	if len(dsl) == 1 {
 		dslengine.Execute(dsl[0], &at)
 	}
@@ -155,6 +247,8 @@ func HashOf(k, v interface{}, dsls ...func()) *design.Hash {
 	}
 	kat := design.AttributeDefinition{Type: tk}
 	vat := design.AttributeDefinition{Type: tv}
+	inlineScalar(&kat)
+	inlineScalar(&vat)
 	if len(dsls) > 2 {
 		// never return nil to avoid panics, errors are reported after DSL execution
 		dslengine.ReportError("HashOf: too many arguments")
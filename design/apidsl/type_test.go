@@ -65,6 +65,40 @@ var _ = Describe("Type", func() {
 		})
 	})
 
+	Context("extending a base type", func() {
+		var base *UserTypeDefinition
+
+		BeforeEach(func() {
+			name = "foo"
+			base = Type("base", func() {
+				Attribute("id", UUID)
+				Attribute("created_at", DateTime)
+				Required("id")
+			})
+			dsl = func() {
+				Extend(base)
+				Attribute("name")
+				Attribute("created_at", String)
+			}
+		})
+
+		It("inherits the base type attributes", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(ut).ShouldNot(BeNil())
+			Ω(ut.Bases).Should(Equal([]DataType{base}))
+			o := ut.Type.ToObject()
+			Ω(o).Should(HaveLen(3))
+			Ω(o["id"].Type).Should(Equal(UUID))
+			Ω(o["name"].Type).Should(Equal(String))
+			Ω(ut.IsRequired("id")).Should(BeTrue())
+		})
+
+		It("overrides the base type attributes", func() {
+			Ω(ut.Type.ToObject()["created_at"].Type).Should(Equal(String))
+			Ω(base.Type.ToObject()["created_at"].Type).Should(Equal(DateTime))
+		})
+	})
+
 	Context("with a name and uuid datatype", func() {
 		const attName = "att"
 		BeforeEach(func() {
@@ -0,0 +1,97 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// OneOf is a top level DSL.
+//
+// OneOf defines a union type whose values are one of the given variant types. The value of the
+// discriminator attribute identifies the variant of a given value, it defaults to "type". The
+// variants must be object types that do not define the discriminator attribute themselves: the
+// generated code adds it when encoding the values and uses it to select the variant when decoding
+// them. The generated Go type wraps the variant value in a field whose type is an interface
+// implemented by the variant types.
+//
+// The OneOf DSL may use Description, Discriminator and Variant. Example:
+//
+//	var Pet = OneOf("Pet", func() {
+//		Description("A pet is either a dog or a cat")
+//		Discriminator("kind")
+//		Variant("dog", Dog)
+//		Variant("cat", Cat)
+//	})
+//
+//	var Owner = Type("Owner", func() {
+//		Attribute("name", String)
+//		Attribute("pets", ArrayOf(Pet))
+//	})
+//
+// This function returns the newly defined type so the value can be used throughout the dsl.
+func OneOf(name string, dsl func()) *design.UserTypeDefinition {
+	return Type(name, func() {
+		a, ok := attributeDefinition()
+		if !ok {
+			return
+		}
+		a.Union = &design.UnionDefinition{Discriminator: "type"}
+		if dsl != nil {
+			dsl()
+		}
+		values := make([]interface{}, len(a.Union.Variants))
+		for i, v := range a.Union.Variants {
+			values[i] = v.Value
+		}
+		a.Type = design.Object{
+			a.Union.Discriminator: &design.AttributeDefinition{
+				Type:        design.String,
+				Description: "Identifies the variant",
+				Validation:  &dslengine.ValidationDefinition{Values: values},
+			},
+		}
+		if a.Validation == nil {
+			a.Validation = &dslengine.ValidationDefinition{}
+		}
+		a.Validation.AddRequired([]string{a.Union.Discriminator})
+	})
+}
+
+// Discriminator can be used in: OneOf
+//
+// Discriminator sets the name of the attribute whose value identifies the variant of the union
+// values, the default is "type".
+func Discriminator(name string) {
+	if a, ok := unionDefinition(); ok {
+		a.Union.Discriminator = name
+	}
+}
+
+// Variant can be used in: OneOf
+//
+// Variant defines a variant of the union. The first argument is the value of the discriminator
+// attribute that identifies the variant, the second argument is the variant type. The variant
+// type must be an object type defined with Type.
+func Variant(value string, t design.DataType) {
+	a, ok := unionDefinition()
+	if !ok {
+		return
+	}
+	ut, ok := t.(*design.UserTypeDefinition)
+	if !ok {
+		dslengine.ReportError("invalid type for variant %#v, must be a type defined with Type", value)
+		return
+	}
+	a.Union.Variants = append(a.Union.Variants, &design.VariantDefinition{Value: value, Type: ut})
+}
+
+// unionDefinition returns true and the current attribute if it is the attribute of a type defined
+// with OneOf, nil and false otherwise.
+func unionDefinition() (*design.AttributeDefinition, bool) {
+	a, ok := dslengine.CurrentDefinition().(*design.AttributeDefinition)
+	if !ok || a.Union == nil {
+		dslengine.IncompatibleDSL()
+		return nil, false
+	}
+	return a, true
+}
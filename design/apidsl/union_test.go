@@ -0,0 +1,133 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OneOf", func() {
+	var dsl func()
+	var union *UserTypeDefinition
+	var dog, cat *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+		dog = Type("Dog", func() {
+			Attribute("name", String)
+			Required("name")
+		})
+		cat = Type("Cat", func() {
+			Attribute("lives", Integer)
+		})
+	})
+
+	JustBeforeEach(func() {
+		union = OneOf("Pet", dsl)
+		dslengine.Run()
+	})
+
+	Context("with variants", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Description("A pet")
+				Variant("dog", dog)
+				Variant("cat", cat)
+			}
+		})
+
+		It("defines the union", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(union.IsUnion()).Should(BeTrue())
+			Ω(union.Description).Should(Equal("A pet"))
+			Ω(union.Union.Discriminator).Should(Equal("type"))
+			Ω(union.Union.Variants).Should(HaveLen(2))
+			Ω(union.Union.Variants[0].Value).Should(Equal("dog"))
+			Ω(union.Union.Variants[0].Type).Should(Equal(dog))
+			Ω(union.Union.Variants[1].Value).Should(Equal("cat"))
+			Ω(union.Union.Variants[1].Type).Should(Equal(cat))
+		})
+
+		It("defines the required discriminator attribute", func() {
+			o := union.Type.ToObject()
+			Ω(o).Should(HaveKey("type"))
+			Ω(o["type"].Type).Should(Equal(String))
+			Ω(o["type"].Validation.Values).Should(Equal([]interface{}{"dog", "cat"}))
+			Ω(union.Validation.Required).Should(Equal([]string{"type"}))
+		})
+
+		It("generates examples of the first variant", func() {
+			ex := union.GenerateExample(NewRandomGenerator("Pet"), nil)
+			Ω(ex).Should(BeAssignableToTypeOf(map[string]interface{}{}))
+			Ω(ex.(map[string]interface{})["type"]).Should(Equal("dog"))
+			Ω(ex.(map[string]interface{})).Should(HaveKey("name"))
+		})
+	})
+
+	Context("with a discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Discriminator("kind")
+				Variant("dog", dog)
+			}
+		})
+
+		It("uses the discriminator attribute", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(union.Union.Discriminator).Should(Equal("kind"))
+			Ω(union.Type.ToObject()).Should(HaveKey("kind"))
+			Ω(union.Validation.Required).Should(Equal([]string{"kind"}))
+		})
+	})
+
+	Context("with a variant that is not a user type", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Variant("name", String)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with duplicate variant values", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Variant("dog", dog)
+				Variant("dog", cat)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a variant defining the discriminator", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Discriminator("name")
+				Variant("dog", dog)
+			}
+		})
+
+		It("produces an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("used outside of OneOf", func() {
+		It("produces an error", func() {
+			Type("Bird", func() {
+				Discriminator("kind")
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+})
@@ -3,10 +3,13 @@ package design
 import (
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"path"
+	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -29,12 +32,29 @@ type (
 		Schemes []string
 		// BasePath is the common base path to all API endpoints
 		BasePath string
+		// BasePathPatterns lists the regular expressions that the values of the base path
+		// parameters must match indexed by parameter name, see RouteDefinition.ParamPatterns.
+		BasePathPatterns map[string]string
 		// Params define the common path parameters to all API endpoints
 		Params *AttributeDefinition
 		// Consumes lists the mime types supported by the API controllers
 		Consumes []*EncodingDefinition
 		// Produces lists the mime types generated by the API controllers
 		Produces []*EncodingDefinition
+		// DefaultResponseContentType is the content type used to encode responses when
+		// requests do not specify an Accept header and to decode requests that do not
+		// specify a Content-Type header.
+		DefaultResponseContentType string
+		// TrailingSlash is the handling of requests whose path only differs from the path of
+		// an action by a trailing slash: "redirect", "absorb" or "strict". The empty string
+		// selects the router default which is to redirect.
+		TrailingSlash string
+		// CaseInsensitiveRouting is true if the static segments of the request paths are
+		// matched against the action paths in a case insensitive way.
+		CaseInsensitiveRouting bool
+		// MethodOverride is true if POST requests may select the method used to route the
+		// request with the X-HTTP-Method-Override header.
+		MethodOverride bool
 		// Origins defines the CORS policies that apply to this API.
 		Origins map[string]*CORSDefinition
 		// TermsOfService describes or links to the API terms of service
@@ -74,6 +94,16 @@ type (
 		Security *SecurityDefinition
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// HealthCheck defines the liveness and readiness probes exposed by the API if any.
+		HealthCheck *HealthCheckDefinition
+		// TLS defines the certificates used to serve the API over HTTPS if any.
+		TLS *TLSDefinition
+		// RateLimit defines the rate limit applied to each action of the API unless
+		// overridden by Resource or Action-level RateLimit() calls.
+		RateLimit *RateLimitDefinition
+		// Versioning lists the versions of the API and defines how requests select them if
+		// the design describes more than one version.
+		Versioning *VersioningDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -103,8 +133,70 @@ type (
 		Description string `json:"description,omitempty"`
 		// URL to documentation.
 		URL string `json:"url,omitempty"`
+		// OpenAPIPath is the request path of the OpenAPI document served by the API if any.
+		OpenAPIPath string `json:"-"`
+		// SwaggerUIPath is the request path of the Swagger UI page served by the API if any.
+		SwaggerUIPath string `json:"-"`
+		// ReDocPath is the request path of the ReDoc page served by the API if any.
+		ReDocPath string `json:"-"`
+	}
+
+	// HealthCheckDefinition defines the paths of the liveness and readiness probes.
+	HealthCheckDefinition struct {
+		// LivePath is the request path of the liveness probe.
+		LivePath string
+		// ReadyPath is the request path of the readiness probe.
+		ReadyPath string
+	}
+
+	// TLSDefinition defines the TLS configuration of the API servers.
+	TLSDefinition struct {
+		// Certificates lists the server certificates, the server picks the certificate
+		// matching the host requested by the client (SNI).
+		Certificates []*CertificateDefinition
+		// ClientCA is the path to the PEM encoded CA certificates used to verify client
+		// certificates, the server requires clients to authenticate with a certificate
+		// (mutual TLS) if not empty.
+		ClientCA string
+	}
+
+	// CertificateDefinition defines the files containing a server certificate and its key.
+	CertificateDefinition struct {
+		// CertFile is the path to the PEM encoded certificate.
+		CertFile string
+		// KeyFile is the path to the PEM encoded private key.
+		KeyFile string
+	}
+
+	// RateLimitDefinition defines the token bucket used to limit the rate of requests to an
+	// action.
+	RateLimitDefinition struct {
+		// RPS is the number of requests allowed per second.
+		RPS float64
+		// Burst is the maximum number of requests allowed in a burst.
+		Burst int
+		// ByIP indicates whether each client IP address gets its own bucket.
+		ByIP bool
+		// Header is the name of the request header whose value identifies the client, for
+		// example the header containing the API key, if any.
+		Header string
+	}
+
+	// VersioningDefinition lists the versions of an API and defines how requests select the
+	// version they target.
+	VersioningDefinition struct {
+		// Versions lists the API versions.
+		Versions []string
+		// Scheme defines how requests select the version.
+		Scheme VersionScheme
+		// Name is the name of the request header or of the media type parameter holding the
+		// version when Scheme is VersionSchemeHeader or VersionSchemeMediaType.
+		Name string
 	}
 
+	// VersionScheme is the mechanism used by requests to select the API version.
+	VersionScheme string
+
 	// ResourceDefinition describes a REST resource.
 	// It defines both a media type and a set of actions that can be executed through HTTP
 	// requests.
@@ -115,6 +207,8 @@ type (
 		Schemes []string
 		// Common URL prefix to all resource action HTTP requests
 		BasePath string
+		// Patterns of the base path parameters indexed by parameter name
+		BasePathPatterns map[string]string
 		// Path and query string parameters that apply to all actions.
 		Params *AttributeDefinition
 		// Name of parent resource if any
@@ -144,6 +238,20 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// MaxBodyLength is the maximum length of request bodies of the resource actions
+		// that don't define one themselves, 0 means no limit.
+		MaxBodyLength int64
+		// RateLimit defines the rate limit of the resource actions that don't define one
+		// themselves.
+		RateLimit *RateLimitDefinition
+		// Versions lists the API versions that serve the resource actions that don't list
+		// their own, all the API versions if empty.
+		Versions []string
+		// Interceptors lists the names of the interceptors that run around all the resource
+		// actions.
+		Interceptors []string
+		// Errors lists the errors that may be returned by all the resource actions.
+		Errors []*ErrorDefinition
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -198,6 +306,23 @@ type (
 		ViewName string
 		// Response header definitions
 		Headers *AttributeDefinition
+		// Response cookie definitions
+		Cookies *AttributeDefinition
+		// TagAttribute is the name of the media type attribute whose value selects the
+		// response when the action defines multiple tagged responses.
+		TagAttribute string
+		// TagValue is the value of TagAttribute that selects the response.
+		TagValue string
+		// RedirectURL is the value of the Location header of redirect responses. It may
+		// contain wildcards that refer to attributes of the response media type.
+		RedirectURL string
+		// CacheControl lists the directives of the response Cache-Control header.
+		CacheControl []string
+		// MaxAge is the number of seconds the response may be cached for, used to compute
+		// the Expires header if CacheControl includes a max-age directive.
+		MaxAge int
+		// Vary lists the request headers that select the response representation.
+		Vary []string
 		// Parent action or resource
 		Parent dslengine.Definition
 		// Metadata is a list of key/value pairs
@@ -206,6 +331,21 @@ type (
 		Standard bool
 	}
 
+	// ErrorDefinition defines an error that an action may return. Errors are mapped to HTTP
+	// responses using their status and the error type to describe the response body.
+	ErrorDefinition struct {
+		// Error name, e.g. "not_found"
+		Name string
+		// Error description
+		Description string
+		// Type of the error response body, ErrorMedia by default
+		Type DataType
+		// HTTP status of responses that carry the error
+		Status int
+		// Parent action or resource
+		Parent dslengine.Definition
+	}
+
 	// ResponseTemplateDefinition defines a response template.
 	// A response template is a function that takes an arbitrary number
 	// of strings and returns a response definition.
@@ -245,12 +385,67 @@ type (
 		Payload *UserTypeDefinition
 		// PayloadOptional is true if the request payload is optional, false otherwise.
 		PayloadOptional bool
+		// StreamingPayload is the type of the messages streamed by the client if any.
+		StreamingPayload *UserTypeDefinition
+		// StreamingResult is the type of the messages streamed by the service if any.
+		StreamingResult *UserTypeDefinition
+		// ServerSentEvents is true if the streaming results are sent using Server-Sent Events
+		// rather than websocket messages.
+		ServerSentEvents bool
+		// NDJSON is true if the streaming results are sent as newline delimited JSON in the
+		// response body rather than websocket messages.
+		NDJSON bool
+		// KeepAlive is the interval between the ping frames sent over the websocket
+		// connections of streaming actions, 0 if the connections are not kept alive.
+		KeepAlive time.Duration
+		// MaxBodyLength is the maximum length of the request body, 0 means no limit.
+		MaxBodyLength int64
+		// IfMatch is true if the action supports conditional requests using the If-Match
+		// header.
+		IfMatch bool
+		// Pagination is the pagination style of the action results, one of "page",
+		// "offset" or "cursor", the empty string if the action results are not paginated.
+		Pagination string
+		// PayloadMultipart is true if the request payload is encoded using
+		// multipart/form-data, false otherwise.
+		PayloadMultipart bool
+		// SkipRequestBodyEncodeDecode is true if the request body is given as is to the
+		// action implementation rather than decoded into a payload.
+		SkipRequestBodyEncodeDecode bool
+		// SkipResponseBodyEncodeDecode is true if the action implementation may write the
+		// response body from an io.Reader rather than encoding a result.
+		SkipResponseBodyEncodeDecode bool
+		// Errors lists the errors that may be returned by the action.
+		Errors []*ErrorDefinition
+		// Origins defines the CORS policies that apply to this action.
+		Origins map[string]*CORSDefinition
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
+		// Request cookies that need to be made available to action
+		Cookies *AttributeDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// RateLimit defines the rate limit of the action if any.
+		RateLimit *RateLimitDefinition
+		// Versions lists the API versions that serve the action, the resource versions if
+		// empty.
+		Versions []string
+		// Timeout is the maximum duration of the requests handled by the action, 0 if
+		// unlimited.
+		Timeout time.Duration
+		// IdempotencyKey is true if the action accepts an Idempotency-Key request header.
+		IdempotencyKey bool
+		// Async is true if the action starts a long running job and responds with 202
+		// Accepted, see JobStatus.
+		Async bool
+		// JobStatus is the action that reports the status of the jobs started by the
+		// action if Async is true.
+		JobStatus *ActionDefinition
+		// Interceptors lists the names of the interceptors that run around the action after
+		// the interceptors of its resource.
+		Interceptors []string
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -269,6 +464,11 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the file server.
 		Security *SecurityDefinition
+		// CacheControl lists the directives of the Cache-Control header sent with the files.
+		CacheControl []string
+		// MaxAge is the number of seconds the files may be cached for, used to compute the
+		// Expires header if CacheControl includes a max-age directive.
+		MaxAge int
 	}
 
 	// LinkDefinition defines a media type link, it specifies a URL to a related resource.
@@ -303,6 +503,10 @@ type (
 		Verb string
 		// Path is the URL path e.g. "/tasks/:id"
 		Path string
+		// ParamPatterns lists the regular expressions that the values of the path parameters
+		// must match indexed by parameter name. The patterns are defined in the route path
+		// using the "{name:pattern}" syntax.
+		ParamPatterns map[string]string
 		// Parent is the action this route applies to.
 		Parent *ActionDefinition
 		// Metadata is a list of key/value pairs
@@ -316,6 +520,8 @@ type (
 		Type DataType
 		// Attribute reference type if any
 		Reference DataType
+		// Bases lists the types extended with Extend if any.
+		Bases []DataType
 		// Optional description
 		Description string
 		// Optional validations
@@ -331,10 +537,43 @@ type (
 		// NonZeroAttributes lists the names of the child attributes that cannot have a
 		// zero value (and thus whose presence does not need to be validated).
 		NonZeroAttributes map[string]bool
+		// Union describes the variants of the types defined with OneOf, nil otherwise.
+		Union *UnionDefinition
+		// Conversions lists the conversions to and from external Go types defined with
+		// ConvertTo and CreateFrom.
+		Conversions []*ConversionDefinition
 		// DSLFunc contains the initialization DSL. This is used for user types.
 		DSLFunc func()
 	}
 
+	// UnionDefinition describes the variants of a type defined with OneOf. The value of the
+	// discriminator attribute identifies the variant of a given value.
+	UnionDefinition struct {
+		// Discriminator is the name of the attribute that identifies the variant.
+		Discriminator string
+		// Variants lists the variants in the order they are defined.
+		Variants []*VariantDefinition
+	}
+
+	// VariantDefinition describes a variant of a type defined with OneOf.
+	VariantDefinition struct {
+		// Value is the value of the discriminator attribute that identifies the variant.
+		Value string
+		// Type is the variant type.
+		Type *UserTypeDefinition
+	}
+
+	// ConversionDefinition describes a conversion between a user type and an external Go
+	// struct defined with ConvertTo or CreateFrom.
+	ConversionDefinition struct {
+		// External is the external Go struct type.
+		External reflect.Type
+		// Create is true if the conversion initializes the user type from the external type
+		// (CreateFrom) and false if it creates the external type from the user type
+		// (ConvertTo).
+		Create bool
+	}
+
 	// ContainerDefinition defines a generic container definition that contains attributes.
 	// This makes it possible for plugins to use attributes in their own data structures.
 	ContainerDefinition interface {
@@ -364,6 +603,16 @@ type (
 	ResponseIterator func(r *ResponseDefinition) error
 )
 
+const (
+	// VersionSchemePath means that the version prefixes the request paths, e.g. "/v1/bottles".
+	VersionSchemePath VersionScheme = "path"
+	// VersionSchemeHeader means that the version is the value of a request header.
+	VersionSchemeHeader VersionScheme = "header"
+	// VersionSchemeMediaType means that the version is a parameter of the media type listed
+	// in the Accept or Content-Type request header, e.g. "application/json; version=v1".
+	VersionSchemeMediaType VersionScheme = "mediatype"
+)
+
 // NewAPIDefinition returns a new design with built-in response templates.
 func NewAPIDefinition() *APIDefinition {
 	api := &APIDefinition{
@@ -531,6 +780,42 @@ func (a *APIDefinition) PathParams() *AttributeDefinition {
 	return &AttributeDefinition{Type: obj}
 }
 
+// UsesProtobuf returns true if the API consumes or produces Protocol Buffers, see
+// ProtobufContentTypes.
+func (a *APIDefinition) UsesProtobuf() bool {
+	for _, encs := range [][]*EncodingDefinition{a.Consumes, a.Produces} {
+		for _, enc := range encs {
+			for _, m := range enc.MIMETypes {
+				for _, ct := range ProtobufContentTypes {
+					if m == ct {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Interceptors returns the sorted names of the interceptors used by the API actions.
+func (a *APIDefinition) Interceptors() []string {
+	seen := make(map[string]bool)
+	var names []string
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, n := range ac.AllInterceptors() {
+				if !seen[n] {
+					seen[n] = true
+					names = append(names, n)
+				}
+			}
+			return nil
+		})
+	})
+	sort.Strings(names)
+	return names
+}
+
 // IterateMediaTypes calls the given iterator passing in each media type sorted in alphabetical order.
 // Iteration stops if an iterator returns an error and in this case IterateMediaTypes returns that
 // error.
@@ -790,6 +1075,27 @@ func (r *ResourceDefinition) FullPath() string {
 	return httppath.Clean(path.Join(basePath, r.BasePath))
 }
 
+// FullPathPatterns returns the patterns of the parameters of the resource full path indexed by
+// parameter name, see FullPath.
+func (r *ResourceDefinition) FullPathPatterns() map[string]string {
+	res := make(map[string]string)
+	if !strings.HasPrefix(r.BasePath, "//") {
+		if p := r.Parent(); p != nil {
+			if ca := p.CanonicalAction(); ca != nil && len(ca.Routes) > 0 {
+				res = ca.Routes[0].FullPathPatterns()
+			}
+		} else {
+			for n, p := range Design.BasePathPatterns {
+				res[n] = p
+			}
+		}
+	}
+	for n, p := range r.BasePathPatterns {
+		res[n] = p
+	}
+	return res
+}
+
 // Parent returns the parent resource if any, nil otherwise.
 func (r *ResourceDefinition) Parent() *ResourceDefinition {
 	if r.ParentName != "" {
@@ -803,12 +1109,18 @@ func (r *ResourceDefinition) Parent() *ResourceDefinition {
 // AllOrigins compute all CORS policies for the resource taking into account any API policy.
 // The result is sorted alphabetically by policy origin.
 func (r *ResourceDefinition) AllOrigins() []*CORSDefinition {
+	return mergeOrigins(Design.Origins, r.Origins)
+}
+
+// mergeOrigins merges the given CORS policies, policies defined in later maps override policies
+// for the same origin defined in earlier maps. The result is sorted alphabetically by policy
+// origin.
+func mergeOrigins(origins ...map[string]*CORSDefinition) []*CORSDefinition {
 	all := make(map[string]*CORSDefinition)
-	for n, o := range Design.Origins {
-		all[n] = o
-	}
-	for n, o := range r.Origins {
-		all[n] = o
+	for _, os := range origins {
+		for n, o := range os {
+			all[n] = o
+		}
 	}
 	names := make([]string, len(all))
 	i := 0
@@ -832,16 +1144,17 @@ func (r *ResourceDefinition) PreflightPaths() []string {
 			if r.Verb == "OPTIONS" {
 				continue
 			}
-			found := false
-			fp := r.FullPath()
-			for _, p := range paths {
-				if fp == p {
-					found = true
-					break
+			for _, fp := range r.VersionedPaths() {
+				found := false
+				for _, p := range paths {
+					if fp == p {
+						found = true
+						break
+					}
+				}
+				if !found {
+					paths = append(paths, fp)
 				}
-			}
-			if !found {
-				paths = append(paths, fp)
 			}
 		}
 		return nil
@@ -1022,6 +1335,25 @@ func (a *AttributeDefinition) IsNonZero(attName string) bool {
 	return a.NonZeroAttributes[attName]
 }
 
+// IsNonPointer returns true if the "struct:field:pointer" metadata of the given primitive
+// attribute is "false", false otherwise. The API metadata sets the default for all attributes.
+// The fields generated for such attributes are not pointers even if the attributes are optional,
+// their zero value denotes the absence of value.
+func (a *AttributeDefinition) IsNonPointer(attName string) bool {
+	if a.Type == nil || !a.Type.IsObject() {
+		return false
+	}
+	att := a.Type.ToObject()[attName]
+	if att == nil || att.Type == nil || !att.Type.IsPrimitive() {
+		return false
+	}
+	ptr, ok := att.Metadata["struct:field:pointer"]
+	if !ok && Design != nil {
+		ptr = Design.Metadata["struct:field:pointer"]
+	}
+	return len(ptr) > 0 && ptr[0] == "false"
+}
+
 // IsPrimitivePointer returns true if the field generated for the given attribute should be a
 // pointer to a primitive type. The target attribute must be an object.
 func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
@@ -1033,11 +1365,22 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 		return false
 	}
 	if att.Type.IsPrimitive() {
-		return !a.IsRequired(attName) && !a.HasDefaultValue(attName) && !a.IsNonZero(attName)
+		return !a.IsRequired(attName) && !a.HasDefaultValue(attName) && !a.IsNonZero(attName) &&
+			!a.IsNonPointer(attName)
 	}
 	return false
 }
 
+// Scalar returns the name of the custom scalar type used by the attribute, the empty string if
+// the attribute does not use a custom scalar type. Custom scalar types are defined with the Scalar
+// DSL or implied by formats such as "ip" whose values are decoded into net.IP.
+func (a *AttributeDefinition) Scalar() string {
+	if s := a.Metadata["struct:field:scalar"]; len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
 // SetExample sets the custom example. SetExample also handles the case when the user doesn't
 // want any example or any auto-generated example.
 func (a *AttributeDefinition) SetExample(example interface{}) bool {
@@ -1100,6 +1443,21 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 	return a.Example
 }
 
+// unionExample returns an example of the first variant of the union including the discriminator.
+func unionExample(u *UnionDefinition, rand *RandomGenerator, seen []string) interface{} {
+	if len(u.Variants) == 0 {
+		return nil
+	}
+	v := u.Variants[0]
+	res := map[string]interface{}{u.Discriminator: v.Value}
+	if ex, ok := v.Type.GenerateExample(rand, seen).(map[string]interface{}); ok {
+		for k, val := range ex {
+			res[k] = val
+		}
+	}
+	return res
+}
+
 func (a *AttributeDefinition) arrayExample(rand *RandomGenerator, seen []string) interface{} {
 	ary := a.Type.ToArray()
 	ln := newExampleGenerator(a, rand).ExampleLength()
@@ -1134,6 +1492,15 @@ func (a *AttributeDefinition) hashExample(rand *RandomGenerator, seen []string)
 }
 
 func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string) interface{} {
+	union := a.Union
+	if ut, ok := a.Type.(*UserTypeDefinition); ok {
+		union = ut.Union
+	}
+	if union != nil {
+		a.Example = unionExample(union, rand, seen)
+		return a.Example
+	}
+
 	// project media types
 	actual := a
 	if mt, ok := a.Type.(*MediaTypeDefinition); ok {
@@ -1297,6 +1664,38 @@ func (d *DocsDefinition) Context() string {
 	return fmt.Sprintf("documentation for %s", Design.Name)
 }
 
+// Context returns the generic definition name used in error messages.
+func (t *TLSDefinition) Context() string {
+	return fmt.Sprintf("TLS configuration of %s", Design.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (r *RateLimitDefinition) Context() string {
+	return "rate limit"
+}
+
+// Context returns the generic definition name used in error messages.
+func (v *VersioningDefinition) Context() string {
+	return "versioning"
+}
+
+// RequestHeader returns the name and value of the request header that selects the given version,
+// empty strings if v is nil, if the version is empty or if the API uses path based versioning.
+// The media type scheme sets the version parameter on an Accept header that accepts any media
+// type.
+func (v *VersioningDefinition) RequestHeader(version string) (name, value string) {
+	if v == nil || version == "" {
+		return "", ""
+	}
+	switch v.Scheme {
+	case VersionSchemeHeader:
+		return v.Name, version
+	case VersionSchemeMediaType:
+		return "Accept", mime.FormatMediaType("*/*", map[string]string{v.Name: version})
+	}
+	return "", ""
+}
+
 // Context returns the generic definition name used in error messages.
 func (t *UserTypeDefinition) Context() string {
 	if t.TypeName != "" {
@@ -1324,6 +1723,29 @@ func (r *ResponseDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (e *ErrorDefinition) Context() string {
+	var prefix, suffix string
+	if e.Name != "" {
+		prefix = fmt.Sprintf("error %#v", e.Name)
+	} else {
+		prefix = "unnamed error"
+	}
+	if e.Parent != nil {
+		suffix = fmt.Sprintf(" of %s", e.Parent.Context())
+	}
+	return prefix + suffix
+}
+
+// MediaType returns the media type identifier of the error response body, the empty string
+// if the error type is not a media type.
+func (e *ErrorDefinition) MediaType() string {
+	if mt, ok := e.Type.(*MediaTypeDefinition); ok {
+		return mt.Identifier
+	}
+	return ""
+}
+
 // Finalize sets the response media type from its type if the type is a media type and no media
 // type is already specified.
 func (r *ResponseDefinition) Finalize() {
@@ -1340,18 +1762,36 @@ func (r *ResponseDefinition) Finalize() {
 	r.MediaType = mt.Identifier
 }
 
+// TagView returns the name of the view used to render the response body when the response is
+// selected by its tag value.
+func (r *ResponseDefinition) TagView() string {
+	if r.ViewName == "" {
+		return "default"
+	}
+	return r.ViewName
+}
+
 // Dup returns a copy of the response definition.
 func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	res := ResponseDefinition{
-		Name:        r.Name,
-		Status:      r.Status,
-		Description: r.Description,
-		MediaType:   r.MediaType,
-		ViewName:    r.ViewName,
+		Name:         r.Name,
+		Status:       r.Status,
+		Description:  r.Description,
+		MediaType:    r.MediaType,
+		ViewName:     r.ViewName,
+		TagAttribute: r.TagAttribute,
+		TagValue:     r.TagValue,
+		RedirectURL:  r.RedirectURL,
+		CacheControl: r.CacheControl,
+		MaxAge:       r.MaxAge,
+		Vary:         r.Vary,
 	}
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	if r.Cookies != nil {
+		res.Cookies = DupAtt(r.Cookies)
+	}
 	return &res
 }
 
@@ -1373,6 +1813,20 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if r.TagAttribute == "" {
+		r.TagAttribute = other.TagAttribute
+		r.TagValue = other.TagValue
+	}
+	if r.RedirectURL == "" {
+		r.RedirectURL = other.RedirectURL
+	}
+	if r.CacheControl == nil {
+		r.CacheControl = other.CacheControl
+		r.MaxAge = other.MaxAge
+	}
+	if r.Vary == nil {
+		r.Vary = other.Vary
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1387,6 +1841,20 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 			}
 		}
 	}
+	if other.Cookies != nil {
+		otherCookies := other.Cookies.Type.ToObject()
+		if len(otherCookies) > 0 {
+			if r.Cookies == nil {
+				r.Cookies = &AttributeDefinition{Type: Object{}}
+			}
+			cookies := r.Cookies.Type.ToObject()
+			for n, c := range otherCookies {
+				if _, ok := cookies[n]; !ok {
+					cookies[n] = c
+				}
+			}
+		}
+	}
 }
 
 // Context returns the generic definition name used in error messages.
@@ -1426,22 +1894,25 @@ func (a *ActionDefinition) PathParams() *AttributeDefinition {
 }
 
 // AllParams returns the path and query string parameters of the action across all its routes.
+// The action parameters take precedence over the resource and API parameters with the same name,
+// e.g. the path parameters constrained by the route patterns.
 func (a *ActionDefinition) AllParams() *AttributeDefinition {
-	var res *AttributeDefinition
+	res := &AttributeDefinition{Type: Object{}}
 	if a.Params != nil {
 		res = DupAtt(a.Params)
-	} else {
-		res = &AttributeDefinition{Type: Object{}}
-	}
-	if a.HasAbsoluteRoutes() {
-		return res
+		res.Type = Object{}
+	}
+	if !a.HasAbsoluteRoutes() {
+		res = res.Merge(a.Parent.Params)
+		if p := a.Parent.Parent(); p != nil {
+			res = res.Merge(p.CanonicalAction().PathParams())
+		} else {
+			res = res.Merge(a.Parent.PathParams())
+			res = res.Merge(Design.PathParams())
+		}
 	}
-	res = res.Merge(a.Parent.Params)
-	if p := a.Parent.Parent(); p != nil {
-		res = res.Merge(p.CanonicalAction().PathParams())
-	} else {
-		res = res.Merge(a.Parent.PathParams())
-		res = res.Merge(Design.PathParams())
+	if a.Params != nil {
+		res = res.Merge(&AttributeDefinition{Type: a.Params.Type})
 	}
 	return res
 }
@@ -1474,6 +1945,128 @@ func (a *ActionDefinition) CanonicalScheme() string {
 	return "http"
 }
 
+// EffectiveMaxBodyLength returns the maximum length of the action request body defined either
+// on the action or on its resource, 0 if there is no limit.
+func (a *ActionDefinition) EffectiveMaxBodyLength() int64 {
+	if a.MaxBodyLength > 0 {
+		return a.MaxBodyLength
+	}
+	if a.Parent != nil {
+		return a.Parent.MaxBodyLength
+	}
+	return 0
+}
+
+// EffectiveRateLimit returns the rate limit of the action defined either on the action, on its
+// resource or on the API, nil if there is no limit.
+func (a *ActionDefinition) EffectiveRateLimit() *RateLimitDefinition {
+	if a.RateLimit != nil {
+		return a.RateLimit
+	}
+	if a.Parent != nil && a.Parent.RateLimit != nil {
+		return a.Parent.RateLimit
+	}
+	return Design.RateLimit
+}
+
+// AllInterceptors returns the names of the interceptors that run around the action: the resource
+// interceptors followed by the action interceptors. The first interceptor runs first.
+func (a *ActionDefinition) AllInterceptors() []string {
+	var names []string
+	if a.Parent != nil {
+		names = append(names, a.Parent.Interceptors...)
+	}
+	for _, n := range a.Interceptors {
+		found := false
+		for _, e := range names {
+			if e == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// EffectiveVersions returns the API versions that serve the action: the versions listed by the
+// action, by its resource or else all the API versions. EffectiveVersions returns nil if the API
+// is not versioned.
+func (a *ActionDefinition) EffectiveVersions() []string {
+	if Design.Versioning == nil {
+		return nil
+	}
+	if len(a.Versions) > 0 {
+		return a.Versions
+	}
+	if a.Parent != nil && len(a.Parent.Versions) > 0 {
+		return a.Parent.Versions
+	}
+	return Design.Versioning.Versions
+}
+
+// LatestVersion returns the last API version that serves the action, the empty string if the API
+// is not versioned. Generated clients and tests send their requests to that version.
+func (a *ActionDefinition) LatestVersion() string {
+	versions := a.EffectiveVersions()
+	if len(versions) == 0 {
+		return ""
+	}
+	return versions[len(versions)-1]
+}
+
+// BasicAuthCredentials returns the names of the payload attributes defined with the Username and
+// Password DSLs, empty strings if there are none.
+func (a *ActionDefinition) BasicAuthCredentials() (username, password string) {
+	if a.Payload == nil {
+		return
+	}
+	for n, att := range a.Payload.ToObject() {
+		if _, ok := att.Metadata["security:username"]; ok {
+			username = n
+		}
+		if _, ok := att.Metadata["security:password"]; ok {
+			password = n
+		}
+	}
+	return
+}
+
+// AllOrigins compute all CORS policies for the action taking into account any API and resource
+// policy. The result is sorted alphabetically by policy origin.
+func (a *ActionDefinition) AllOrigins() []*CORSDefinition {
+	return mergeOrigins(Design.Origins, a.Parent.Origins, a.Origins)
+}
+
+// AllErrors returns the errors defined on the action followed by the errors defined on its
+// resource that the action does not override.
+func (a *ActionDefinition) AllErrors() []*ErrorDefinition {
+	all := append([]*ErrorDefinition{}, a.Errors...)
+	if a.Parent == nil {
+		return all
+	}
+	for _, re := range a.Parent.Errors {
+		found := false
+		for _, e := range a.Errors {
+			if e.Name == re.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			all = append(all, re)
+		}
+	}
+	return all
+}
+
+// Streaming returns true if the action defines a streaming payload or a streaming result.
+func (a *ActionDefinition) Streaming() bool {
+	return a.StreamingPayload != nil || a.StreamingResult != nil
+}
+
 // EffectiveSchemes return the URL schemes that apply to the action. Looks recursively into action
 // resource, parent resources and API.
 func (a *ActionDefinition) EffectiveSchemes() []string {
@@ -1495,8 +2088,15 @@ func (a *ActionDefinition) EffectiveSchemes() []string {
 }
 
 // WebSocket returns true if the action scheme is "ws" or "wss" or both (directly or inherited
-// from the resource or API)
+// from the resource or API) or if the action streams payloads or results without using
+// Server-Sent Events or newline delimited JSON.
 func (a *ActionDefinition) WebSocket() bool {
+	if a.ServerSentEvents || a.NDJSON {
+		return false
+	}
+	if a.Streaming() {
+		return true
+	}
 	schemes := a.EffectiveSchemes()
 	if len(schemes) == 0 {
 		return false
@@ -1652,6 +2252,27 @@ func (a *ActionDefinition) initImplicitParams() {
 			a.Params.Type.ToObject()[wc] = &AttributeDefinition{Type: String}
 		}
 	}
+	// Constrain the string path parameters whose values must match a pattern, the attribute
+	// is copied first as it may be shared with the resource or API parameters. Validation
+	// makes sure that the routes agree on the patterns and that the patterns do not conflict
+	// with the parameter definitions.
+	for _, ro := range a.Routes {
+		for wc, pattern := range ro.FullPathPatterns() {
+			att, ok := a.Params.Type.ToObject()[wc]
+			if !ok || att.Type.Kind() != StringKind {
+				continue
+			}
+			if att.Validation != nil && att.Validation.Pattern != "" {
+				continue
+			}
+			att = DupAtt(att)
+			if att.Validation == nil {
+				att.Validation = &dslengine.ValidationDefinition{}
+			}
+			att.Validation.Pattern = pattern
+			a.Params.Type.ToObject()[wc] = att
+		}
+	}
 }
 
 // initQueryParams extract the query parameters from the action params.
@@ -1801,12 +2422,62 @@ func (r *RouteDefinition) FullPath() string {
 	return httppath.Clean(joinedPath)
 }
 
+// FullPathPatterns returns the patterns of the parameters of the route full path indexed by
+// parameter name, these include the patterns defined in the API and resource base paths.
+func (r *RouteDefinition) FullPathPatterns() map[string]string {
+	res := make(map[string]string)
+	if !r.IsAbsolute() && r.Parent != nil && r.Parent.Parent != nil {
+		res = r.Parent.Parent.FullPathPatterns()
+	}
+	for n, p := range r.ParamPatterns {
+		res[n] = p
+	}
+	return res
+}
+
 // IsAbsolute returns true if the action path should not be concatenated to the resource and API
 // base paths.
 func (r *RouteDefinition) IsAbsolute() bool {
 	return strings.HasPrefix(r.Path, "//")
 }
 
+// VersionedPaths returns the full paths of the route for each API version that serves its
+// action. VersionedPaths returns the full path alone unless the API uses path based versioning.
+func (r *RouteDefinition) VersionedPaths() []string {
+	v := Design.Versioning
+	if v == nil || v.Scheme != VersionSchemePath || r.Parent == nil {
+		return []string{r.FullPath()}
+	}
+	versions := r.Parent.EffectiveVersions()
+	paths := make([]string, len(versions))
+	for i, ver := range versions {
+		paths[i] = r.VersionedPath(ver)
+	}
+	return paths
+}
+
+// VersionedPath returns the full path of the route served by the given API version. The version
+// is inserted after the API base path when the API uses path based versioning, the full path is
+// returned unchanged otherwise.
+func (r *RouteDefinition) VersionedPath(version string) string {
+	full := r.FullPath()
+	if version == "" || Design.Versioning == nil || Design.Versioning.Scheme != VersionSchemePath {
+		return full
+	}
+	var base string
+	if !r.IsAbsolute() && Design.BasePath != "" {
+		base = strings.TrimSuffix(httppath.Clean(Design.BasePath), "/")
+		if full != base && !strings.HasPrefix(full, base+"/") {
+			base = ""
+		}
+	}
+	rest := full[len(base):]
+	if rest == "/" {
+		rest = ""
+	}
+	return base + "/" + version + rest
+}
+
 func iterateHeaders(headers *AttributeDefinition, isRequired func(name string) bool, it HeaderIterator) error {
 	if headers == nil || !headers.Type.IsObject() {
 		return nil
@@ -124,6 +124,73 @@ var _ = Describe("IsRequired", func() {
 	})
 })
 
+var _ = Describe("IsPrimitivePointer", func() {
+	var metadata dslengine.MetadataDefinition
+	var apiMetadata dslengine.MetadataDefinition
+
+	var api *design.APIDefinition
+	var attribute *design.AttributeDefinition
+	var res bool
+
+	BeforeEach(func() {
+		metadata = nil
+		apiMetadata = nil
+		api = design.Design
+	})
+
+	AfterEach(func() {
+		design.Design = api
+	})
+
+	JustBeforeEach(func() {
+		design.Design = &design.APIDefinition{Metadata: apiMetadata}
+		optional := &design.AttributeDefinition{Type: design.Integer, Metadata: metadata}
+		attribute = &design.AttributeDefinition{Type: design.Object{"optional": optional}}
+		res = attribute.IsPrimitivePointer("optional")
+	})
+
+	Context("called on an optional field", func() {
+		It("returns true", func() {
+			Ω(res).Should(BeTrue())
+		})
+	})
+
+	Context("called on a field with the struct:field:pointer metadata set to false", func() {
+		BeforeEach(func() {
+			metadata = dslengine.MetadataDefinition{"struct:field:pointer": {"false"}}
+		})
+
+		It("returns false", func() {
+			Ω(res).Should(BeFalse())
+		})
+
+		It("does not make the field non-zero", func() {
+			Ω(attribute.IsNonPointer("optional")).Should(BeTrue())
+			Ω(attribute.IsNonZero("optional")).Should(BeFalse())
+		})
+	})
+
+	Context("with the struct:field:pointer API metadata set to false", func() {
+		BeforeEach(func() {
+			apiMetadata = dslengine.MetadataDefinition{"struct:field:pointer": {"false"}}
+		})
+
+		It("returns false", func() {
+			Ω(res).Should(BeFalse())
+		})
+
+		Context("and the field metadata set to true", func() {
+			BeforeEach(func() {
+				metadata = dslengine.MetadataDefinition{"struct:field:pointer": {"true"}}
+			})
+
+			It("returns true", func() {
+				Ω(res).Should(BeTrue())
+			})
+		})
+	})
+})
+
 var _ = Describe("IterateHeaders", func() {
 	It("works when Parent.Headers is nil", func() {
 		// create a Resource with no headers, Action with one header
@@ -255,6 +322,75 @@ var _ = Describe("FullPath", func() {
 	})
 })
 
+var _ = Describe("VersionedPath", func() {
+	var route *design.RouteDefinition
+	var scheme design.VersionScheme
+
+	BeforeEach(func() {
+		scheme = design.VersionSchemePath
+	})
+
+	JustBeforeEach(func() {
+		design.Design.BasePath = "/api"
+		design.Design.Versioning = &design.VersioningDefinition{Versions: []string{"v1", "v2"}, Scheme: scheme}
+		action := &design.ActionDefinition{Versions: []string{"v2"}}
+		action.Parent = &design.ResourceDefinition{BasePath: "/bottles"}
+		route = &design.RouteDefinition{Path: "/:id", Parent: action}
+		action.Routes = []*design.RouteDefinition{route}
+	})
+
+	AfterEach(func() {
+		design.Design.BasePath = ""
+		design.Design.Versioning = nil
+	})
+
+	It("inserts the version after the API base path", func() {
+		Ω(route.VersionedPath("v1")).Should(Equal("/api/v1/bottles/:id"))
+		Ω(route.VersionedPaths()).Should(Equal([]string{"/api/v2/bottles/:id"}))
+	})
+
+	Context("with header versioning", func() {
+		BeforeEach(func() {
+			scheme = design.VersionSchemeHeader
+		})
+
+		It("returns the full path", func() {
+			Ω(route.VersionedPath("v1")).Should(Equal("/api/bottles/:id"))
+			Ω(route.VersionedPaths()).Should(Equal([]string{"/api/bottles/:id"}))
+		})
+	})
+})
+
+var _ = Describe("RequestHeader", func() {
+	var versioning *design.VersioningDefinition
+
+	BeforeEach(func() {
+		versioning = &design.VersioningDefinition{Versions: []string{"v1", "v2"}, Name: "version"}
+	})
+
+	It("does not use a header with path versioning", func() {
+		versioning.Scheme = design.VersionSchemePath
+		name, value := versioning.RequestHeader("v2")
+		Ω(name).Should(BeEmpty())
+		Ω(value).Should(BeEmpty())
+	})
+
+	It("sets the version header with header versioning", func() {
+		versioning.Scheme = design.VersionSchemeHeader
+		versioning.Name = "X-Api-Version"
+		name, value := versioning.RequestHeader("v2")
+		Ω(name).Should(Equal("X-Api-Version"))
+		Ω(value).Should(Equal("v2"))
+	})
+
+	It("sets the Accept header with media type versioning", func() {
+		versioning.Scheme = design.VersionSchemeMediaType
+		name, value := versioning.RequestHeader("v2")
+		Ω(name).Should(Equal("Accept"))
+		Ω(value).Should(Equal("*/*; version=v2"))
+	})
+})
+
 var _ = Describe("AllParams", func() {
 	Context("Given a resource with a parent and an action with a route", func() {
 		var (
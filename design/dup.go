@@ -48,6 +48,8 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		DefaultValue:      att.DefaultValue,
 		NonZeroAttributes: att.NonZeroAttributes,
 		View:              att.View,
+		Union:             att.Union,
+		Bases:             att.Bases,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
 	}
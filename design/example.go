@@ -164,9 +164,10 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 			}
 			return res
 		}(),
-		"cidr":    "192.168.100.14/24",
-		"regexp":  eg.r.faker.Characters(3) + ".*",
-		"rfc1123": time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC1123), // to obtain a "fixed" rand
+		"cidr":     "192.168.100.14/24",
+		"regexp":   eg.r.faker.Characters(3) + ".*",
+		"rfc1123":  time.Unix(int64(eg.r.Int())%1454957045, 0).Format(time.RFC1123), // to obtain a "fixed" rand
+		"duration": (time.Duration(eg.r.Int()%86400) * time.Second).String(),
 	}[format]; ok {
 		return res
 	}
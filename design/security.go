@@ -55,8 +55,8 @@ type SecuritySchemeDefinition struct {
 	Type string `json:"type"`
 	// Description describes the security scheme. Ex: "Google OAuth2"
 	Description string `json:"description"`
-	// In determines whether it is in the "header" or in the "query"
-	// string that we will find an `apiKey`.
+	// In determines whether it is in the "header", in the "query"
+	// string or in a "cookie" that we will find an `apiKey`.
 	In string `json:"in,omitempty"`
 	// Name refers to a header or parameter name, based on In's value.
 	Name string `json:"name,omitempty"`
@@ -94,8 +94,12 @@ func (s *SecuritySchemeDefinition) Context() string {
 	return dslFunc
 }
 
-// Validate ensures that TokenURL and AuthorizationURL are valid URLs.
+// Validate ensures that OAuth2 schemes define a flow and that TokenURL and AuthorizationURL are
+// valid URLs.
 func (s *SecuritySchemeDefinition) Validate() error {
+	if s.Kind == OAuth2SecurityKind && s.Flow == "" {
+		return fmt.Errorf("OAuth2 security scheme %#v must define a flow with AccessCodeFlow, ImplicitFlow, PasswordFlow or ApplicationFlow", s.SchemeName)
+	}
 	_, err := url.Parse(s.TokenURL)
 	if err != nil {
 		return fmt.Errorf("invalid token URL %#v: %s", s.TokenURL, err)
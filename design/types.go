@@ -163,6 +163,8 @@ const (
 	UserTypeKind
 	// MediaTypeKind represents a media type.
 	MediaTypeKind
+	// FileKind represents a file part of a multipart request.
+	FileKind
 )
 
 const (
@@ -188,6 +190,11 @@ const (
 
 	// Any is the type for an arbitrary JSON value (interface{} in Go).
 	Any = Primitive(AnyKind)
+
+	// File is the type for a file part of a multipart request (multipart.FileHeader in Go).
+	// File may only be used to define attributes of payloads of actions that use the
+	// MultipartForm DSL.
+	File = Primitive(FileKind)
 )
 
 // DataType implementation
@@ -208,6 +215,8 @@ func (p Primitive) Name() string {
 		return "string"
 	case Any:
 		return "any"
+	case File:
+		return "file"
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -248,7 +257,7 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any && p != File {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -297,6 +306,8 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 	case Any:
 		// to not make it too complicated, pick one of the primitive types
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
+	case File:
+		return r.String()
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -670,6 +681,9 @@ func (u *UserTypeDefinition) IsCompatible(val interface{}) bool {
 	return u.Type == nil || u.Type.IsCompatible(val)
 }
 
+// IsUnion returns true if the type was defined with OneOf.
+func (u *UserTypeDefinition) IsUnion() bool { return u.Union != nil }
+
 // Finalize merges base type attributes.
 func (u *UserTypeDefinition) Finalize() {
 	if u.Reference != nil {
@@ -879,6 +893,72 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 	return
 }
 
+// ProjectFull creates a MediaTypeDefinition containing the fields rendered by all the views of the
+// media type. The result is the projection onto the default view if it renders the attributes of
+// all the other views, onto the first other view in alphabetical order that does otherwise. If no
+// view renders the attributes of all the others ProjectFull projects the media type onto a view
+// that combines them, the name of the view is returned alongside the projected media type. The
+// combined view is named "full" unless the media type defines a view with that name.
+func (m *MediaTypeDefinition) ProjectFull() (*MediaTypeDefinition, string, error) {
+	p, _, view, err := m.projectFull()
+	return p, view, err
+}
+
+func (m *MediaTypeDefinition) projectFull() (*MediaTypeDefinition, *UserTypeDefinition, string, error) {
+	if m.IsArray() {
+		e := m.ToArray().ElemType.Type.(*MediaTypeDefinition) // validation checked this cast would work
+		pe, le, view, err := e.projectFull()
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("collection element: %s", err)
+		}
+		p, links, err := m.collectionOf(e, pe, le, view)
+		return p, links, view, err
+	}
+	if len(m.Views) == 0 {
+		return nil, nil, "", fmt.Errorf("media type %s defines no view", m.TypeName)
+	}
+	names := make([]string, 0, len(m.Views))
+	for n := range m.Views {
+		if n != "default" {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := m.Views["default"]; ok {
+		names = append([]string{"default"}, names...)
+	}
+	full := make(Object)
+	for _, n := range names {
+		for an, att := range m.Views[n].Type.ToObject() {
+			if _, ok := full[an]; !ok {
+				full[an] = DupAtt(att)
+			}
+		}
+	}
+	for _, n := range names {
+		if len(m.Views[n].Type.ToObject()) == len(full) {
+			p, links, err := m.Project(n)
+			return p, links, n, err
+		}
+	}
+	view := "full"
+	for i := 2; m.Views[view] != nil; i++ {
+		view = fmt.Sprintf("full%d", i)
+	}
+	c := *m
+	c.Views = make(map[string]*ViewDefinition, len(m.Views)+1)
+	for n, v := range m.Views {
+		c.Views[n] = v
+	}
+	c.Views[view] = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: full},
+		Name:                view,
+		Parent:              &c,
+	}
+	p, links, err := c.Project(view)
+	return p, links, view, err
+}
+
 func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefinition, *UserTypeDefinition, error) {
 	// Project the collection element media type
 	e := m.ToArray().ElemType.Type.(*MediaTypeDefinition) // validation checked this cast would work
@@ -886,7 +966,12 @@ func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefiniti
 	if err2 != nil {
 		return nil, nil, fmt.Errorf("collection element: %s", err2)
 	}
+	return m.collectionOf(e, pe, le, view)
+}
 
+// collectionOf builds the projection of the collection media type given the projection pe of its
+// element media type e and the corresponding links type le.
+func (m *MediaTypeDefinition) collectionOf(e, pe *MediaTypeDefinition, le *UserTypeDefinition, view string) (*MediaTypeDefinition, *UserTypeDefinition, error) {
 	// Build the projected collection with the results
 	desc := m.TypeName + " is the media type for an array of " + e.TypeName + " (" + view + " view)"
 	p := &MediaTypeDefinition{
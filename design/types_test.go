@@ -337,6 +337,78 @@ var _ = Describe("Project", func() {
 	})
 })
 
+var _ = Describe("ProjectFull", func() {
+	var mt *MediaTypeDefinition
+	var tiny *AttributeDefinition
+
+	var projected *MediaTypeDefinition
+	var view string
+	var prErr error
+
+	BeforeEach(func() {
+		tiny = &AttributeDefinition{
+			Type: Object{
+				"att2": &AttributeDefinition{Type: String},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		ProjectedMediaTypes = make(map[string]*MediaTypeDefinition)
+		mt = &MediaTypeDefinition{
+			UserTypeDefinition: &UserTypeDefinition{
+				AttributeDefinition: &AttributeDefinition{
+					Type: Object{
+						"att1": &AttributeDefinition{Type: Integer},
+						"att2": &AttributeDefinition{Type: String},
+						"att3": &AttributeDefinition{Type: String},
+					},
+				},
+				TypeName: "Foo",
+			},
+			Identifier: "vnd.application/foo",
+			Views: map[string]*ViewDefinition{
+				"default": {
+					Name: "default",
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"att1": &AttributeDefinition{Type: Integer},
+							"att2": &AttributeDefinition{Type: String},
+						},
+					},
+				},
+				"tiny": {
+					Name:                "tiny",
+					AttributeDefinition: tiny,
+				},
+			},
+		}
+		projected, view, prErr = mt.ProjectFull()
+	})
+
+	Context("with a default view rendering the attributes of the other views", func() {
+		It("uses the default view", func() {
+			Ω(prErr).ShouldNot(HaveOccurred())
+			Ω(view).Should(Equal("default"))
+			Ω(projected.TypeName).Should(Equal("Foo"))
+		})
+	})
+
+	Context("with views rendering different attributes", func() {
+		BeforeEach(func() {
+			tiny.Type.ToObject()["att3"] = &AttributeDefinition{Type: String}
+		})
+
+		It("combines the attributes of all the views", func() {
+			Ω(prErr).ShouldNot(HaveOccurred())
+			Ω(view).Should(Equal("full"))
+			Ω(projected.TypeName).Should(Equal("FooFull"))
+			Ω(projected.Type.ToObject()).Should(HaveLen(3))
+			Ω(mt.Views).ShouldNot(HaveKey("full"))
+		})
+	})
+})
+
 var _ = Describe("UserTypes", func() {
 	var (
 		o         Object
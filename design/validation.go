@@ -85,6 +85,10 @@ func (a *APIDefinition) Validate() error {
 	a.validateLicense(verr)
 	a.validateDocs(verr)
 	a.validateOrigins(verr)
+	a.validateHealthCheck(verr)
+	a.validateServedDocs(verr)
+	a.validateTLS(verr)
+	a.validateVersioning(verr)
 
 	var allRoutes []*routeInfo
 	a.IterateResources(func(r *ResourceDefinition) error {
@@ -160,6 +164,24 @@ func (a *APIDefinition) Validate() error {
 	for _, enc := range a.Produces {
 		verr.Merge(enc.Validate())
 	}
+	if ct := a.DefaultResponseContentType; ct != "" {
+		produces := a.Produces
+		if len(produces) == 0 {
+			produces = DefaultEncoders
+		}
+		found := false
+		for _, enc := range produces {
+			for _, m := range enc.MIMETypes {
+				if m == ct {
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			verr.Add(a, "default response content type %#v is not listed in Produces", ct)
+		}
+	}
 
 	err := verr.AsError()
 	if err == nil {
@@ -199,6 +221,135 @@ func (a *APIDefinition) validateOrigins(verr *dslengine.ValidationErrors) {
 	}
 }
 
+func (a *APIDefinition) validateHealthCheck(verr *dslengine.ValidationErrors) {
+	hc := a.HealthCheck
+	if hc == nil {
+		return
+	}
+	for _, p := range []string{hc.LivePath, hc.ReadyPath} {
+		if !strings.HasPrefix(p, "/") {
+			verr.Add(a, "invalid health check path %#v, path must start with /", p)
+		}
+	}
+	if hc.LivePath == hc.ReadyPath {
+		verr.Add(a, "liveness and readiness probes use the same path %#v", hc.LivePath)
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				if ro.Verb != "GET" {
+					continue
+				}
+				if fp := ro.FullPath(); fp == hc.LivePath || fp == hc.ReadyPath {
+					verr.Add(ac, "route %s %s conflicts with the health check probe", ro.Verb, fp)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (a *APIDefinition) validateServedDocs(verr *dslengine.ValidationErrors) {
+	if a.Docs == nil || a.Docs.OpenAPIPath == "" {
+		return
+	}
+	paths := make(map[string]bool)
+	for _, p := range []string{a.Docs.OpenAPIPath, a.Docs.SwaggerUIPath, a.Docs.ReDocPath} {
+		if p == "" {
+			continue
+		}
+		if !strings.HasPrefix(p, "/") {
+			verr.Add(a, "invalid documentation path %#v, path must start with /", p)
+		}
+		if paths[p] {
+			verr.Add(a, "documentation path %#v is used multiple times", p)
+		}
+		paths[p] = true
+	}
+	if hc := a.HealthCheck; hc != nil && (paths[hc.LivePath] || paths[hc.ReadyPath]) {
+		verr.Add(a, "documentation paths conflict with the health check probes")
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		for _, fs := range r.FileServers {
+			if paths[fs.RequestPath] {
+				verr.Add(fs, "file server path %s conflicts with the documentation", fs.RequestPath)
+			}
+		}
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				if ro.Verb != "GET" {
+					continue
+				}
+				if fp := ro.FullPath(); paths[fp] {
+					verr.Add(ac, "route %s %s conflicts with the documentation", ro.Verb, fp)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func (a *APIDefinition) validateTLS(verr *dslengine.ValidationErrors) {
+	t := a.TLS
+	if t == nil {
+		return
+	}
+	if len(t.Certificates) == 0 {
+		verr.Add(t, "TLS must define at least one certificate")
+	}
+	for _, c := range t.Certificates {
+		if c.CertFile == "" || c.KeyFile == "" {
+			verr.Add(t, "certificate and key files must not be empty")
+		}
+	}
+	https := false
+	for _, s := range a.Schemes {
+		if s == "https" {
+			https = true
+		}
+	}
+	if !https {
+		verr.Add(a, "TLS requires the API to use the https scheme")
+	}
+}
+
+func (a *APIDefinition) validateVersioning(verr *dslengine.ValidationErrors) {
+	v := a.Versioning
+	known := make(map[string]bool)
+	if v != nil {
+		if len(v.Versions) == 0 {
+			verr.Add(v, "versioning requires at least one version, use Versions to list them")
+		}
+		for _, ver := range v.Versions {
+			if ver == "" || strings.ContainsAny(ver, "/ ") {
+				verr.Add(v, "invalid version %#v", ver)
+			}
+			if known[ver] {
+				verr.Add(v, "duplicate version %#v", ver)
+			}
+			known[ver] = true
+		}
+	}
+	check := func(def dslengine.Definition, versions []string) {
+		if len(versions) > 0 && v == nil {
+			verr.Add(def, "versions %s are not declared by the API, use Versions in the API definition", strings.Join(versions, ", "))
+			return
+		}
+		for _, ver := range versions {
+			if !known[ver] {
+				verr.Add(def, "unknown version %#v, the API versions are %s", ver, strings.Join(v.Versions, ", "))
+			}
+		}
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		check(r, r.Versions)
+		return r.IterateActions(func(ac *ActionDefinition) error {
+			check(ac, ac.Versions)
+			return nil
+		})
+	})
+}
+
 // Validate tests whether the resource definition is consistent: action names are valid and each action is
 // valid.
 func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
@@ -219,6 +370,7 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	verr.Merge(validateErrors(r, r.Errors))
 	return verr.AsError()
 }
 
@@ -332,6 +484,58 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 	}
+	if a.StreamingPayload != nil {
+		if a.Payload != nil {
+			verr.Add(a, "Action cannot define both a payload and a streaming payload")
+		}
+		verr.Merge(a.StreamingPayload.Validate("action streaming payload", a))
+	}
+	if a.PayloadMultipart {
+		if a.Payload == nil || !a.Payload.IsObject() {
+			verr.Add(a, "Action using a multipart form must define an object payload")
+		}
+	}
+	verr.Merge(a.validateFiles())
+	verr.Merge(a.validateParamPatterns())
+	verr.Merge(a.validateCredentials())
+	verr.Merge(a.validateTaggedResponses())
+	verr.Merge(validateErrors(a, a.Errors))
+	for _, origin := range a.Origins {
+		verr.Merge(origin.Validate())
+	}
+	if a.ServerSentEvents {
+		if a.StreamingResult == nil {
+			verr.Add(a, "Action using Server-Sent Events must define a streaming result")
+		}
+		if a.StreamingPayload != nil {
+			verr.Add(a, "Action using Server-Sent Events cannot define a streaming payload")
+		}
+	}
+	if a.SkipRequestBodyEncodeDecode {
+		if a.Payload != nil || a.StreamingPayload != nil {
+			verr.Add(a, "Action skipping the request body decoding cannot define a payload")
+		}
+		if a.PayloadMultipart {
+			verr.Add(a, "Action skipping the request body decoding cannot use a multipart form")
+		}
+	}
+	if a.SkipResponseBodyEncodeDecode && a.StreamingResult != nil {
+		verr.Add(a, "Action skipping the response body encoding cannot define a streaming result")
+	}
+	if a.NDJSON {
+		if a.StreamingResult == nil {
+			verr.Add(a, "Action using NDJSON must define a streaming result")
+		}
+		if a.StreamingPayload != nil {
+			verr.Add(a, "Action using NDJSON cannot define a streaming payload")
+		}
+		if a.ServerSentEvents {
+			verr.Add(a, "Action cannot use both NDJSON and Server-Sent Events")
+		}
+	}
+	if a.KeepAlive > 0 && (!a.Streaming() || a.ServerSentEvents || a.NDJSON) {
+		verr.Add(a, "Action using KeepAlive must stream payloads or results over a websocket")
+	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
@@ -345,13 +549,337 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 					continue
 				}
 			}
-			verr.Add(a, "Param %s has an invalid type, action params must be primitives or arrays of primitives", n)
+			if p.Type.IsHash() {
+				verr.Merge(validateMapParam(a, n, p.Type.ToHash()))
+				continue
+			}
+			verr.Add(a, "Param %s has an invalid type, action params must be primitives, arrays of primitives or maps", n)
+		}
+	}
+	if a.Cookies != nil {
+		verr.Merge(validateCookies(a, a.Cookies))
+		for n := range a.Cookies.Type.ToObject() {
+			if a.Params != nil && a.Params.Type.ToObject()[n] != nil {
+				verr.Add(a, "Cookie %s has the same name as a param", n)
+			}
+			if a.Headers != nil && a.Headers.Type.ToObject()[n] != nil {
+				verr.Add(a, "Cookie %s has the same name as a header", n)
+			}
 		}
 	}
 
 	return verr.AsError()
 }
 
+// validateFiles checks that File attributes are only used to define the top level attributes of
+// multipart payloads.
+func (a *ActionDefinition) validateFiles() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	check := func(ctx string, att *AttributeDefinition, allowTop bool) {
+		if att == nil {
+			return
+		}
+		if o := att.Type.ToObject(); o != nil && allowTop {
+			for n, catt := range o {
+				if catt.Type.Kind() == FileKind {
+					continue
+				}
+				if arr := catt.Type.ToArray(); arr != nil && arr.ElemType.Type.Kind() == FileKind {
+					continue
+				}
+				if hasFile(catt) {
+					verr.Add(a, "%s attribute %s: File may only be used at the top level of multipart payloads", ctx, n)
+				}
+			}
+			return
+		}
+		if hasFile(att) {
+			verr.Add(a, "%s: File may only be used in multipart payloads", ctx)
+		}
+	}
+	check("params", a.Params, false)
+	check("headers", a.Headers, false)
+	if a.Payload != nil {
+		check("payload", a.Payload.AttributeDefinition, a.PayloadMultipart)
+	}
+	return verr.AsError()
+}
+
+// validateParamPatterns checks that the patterns defined in the route and base paths only apply
+// to string parameters, that the action routes define the same pattern for a given parameter and
+// that the patterns do not conflict with the patterns of the parameter definitions.
+func (a *ActionDefinition) validateParamPatterns() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	// param looks up the parameter definition the same way initImplicitParams does.
+	param := func(name string) *AttributeDefinition {
+		if a.Params != nil {
+			if att, ok := a.Params.Type.ToObject()[name]; ok {
+				return att
+			}
+		}
+		for r := a.Parent; r != nil; r = r.Parent() {
+			if r.Params != nil {
+				if att, ok := r.Params.Type.ToObject()[name]; ok {
+					return att
+				}
+			}
+		}
+		if Design.Params != nil {
+			return Design.Params.Type.ToObject()[name]
+		}
+		return nil
+	}
+	patterns := make(map[string]string)
+	for _, r := range a.Routes {
+		rpatterns := r.FullPathPatterns()
+		for _, name := range r.Params() {
+			pattern := rpatterns[name]
+			if p, ok := patterns[name]; ok {
+				if p != pattern {
+					verr.Add(a, "path parameter %#v must define the same pattern in all the action routes", name)
+				}
+				continue
+			}
+			patterns[name] = pattern
+			att := param(name)
+			if pattern == "" || att == nil {
+				continue
+			}
+			if att.Type.Kind() != StringKind {
+				verr.Add(a, "path parameter %#v defines a pattern but is not a string", name)
+			} else if att.Validation != nil && att.Validation.Pattern != "" && att.Validation.Pattern != pattern {
+				verr.Add(a, "path parameter %#v defines a pattern that conflicts with the parameter pattern %#v", name, att.Validation.Pattern)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// validateCredentials checks that actions whose payload defines basic auth credentials attributes
+// use a basic auth security scheme and define both the username and the password.
+func (a *ActionDefinition) validateCredentials() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	username, password := a.BasicAuthCredentials()
+	if username == "" && password == "" {
+		return nil
+	}
+	if username == "" || password == "" {
+		verr.Add(a, "payload must define both the basic auth Username and Password attributes")
+	}
+	security := a.Security
+	if security == nil && a.Parent != nil {
+		security = a.Parent.Security
+	}
+	if security == nil {
+		security = Design.Security
+	}
+	if security == nil || security.Scheme == nil || security.Scheme.Kind != BasicAuthSecurityKind {
+		verr.Add(a, "payload defines basic auth credentials but the action does not use a basic auth security scheme")
+	}
+	return verr.AsError()
+}
+
+// validateTaggedResponses checks that the tagged responses of the action all use the same media
+// type and tag attribute, that the tag attribute is a string rendered by the view of each response
+// and that the tag values are unique.
+func (a *ActionDefinition) validateTaggedResponses() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	var first *ResponseDefinition
+	values := make(map[string]string)
+	for _, n := range sortedResponseNames(a.Responses) {
+		r := a.Responses[n]
+		if r.TagAttribute == "" {
+			continue
+		}
+		if other, ok := values[r.TagValue]; ok {
+			verr.Add(r, "tag value %#v is also used by response %s", r.TagValue, other)
+		}
+		values[r.TagValue] = r.Name
+		if _, ok := r.Type.(*MediaTypeDefinition); r.Type != nil && !ok {
+			verr.Add(r, "tagged responses must use a media type, not a type override")
+			continue
+		}
+		mt := Design.MediaTypeWithIdentifier(r.MediaType)
+		if mt == nil {
+			verr.Add(r, "tagged responses must use a media type defined in the design")
+			continue
+		}
+		if view, ok := mt.Views[r.TagView()]; !ok {
+			verr.Add(r, "view %s is not defined by media type %s", r.TagView(), mt.Identifier)
+		} else if _, ok := view.Type.ToObject()[r.TagAttribute]; !ok {
+			verr.Add(r, "tag attribute %s is not rendered by view %s of media type %s", r.TagAttribute, r.TagView(), mt.Identifier)
+		}
+		if first == nil {
+			first = r
+			att, ok := mt.ToObject()[r.TagAttribute]
+			if !ok {
+				verr.Add(r, "tag attribute %s is not defined by media type %s", r.TagAttribute, mt.Identifier)
+			} else if att.Type.Kind() != StringKind {
+				verr.Add(r, "tag attribute %s must be a string", r.TagAttribute)
+			}
+			continue
+		}
+		if r.TagAttribute != first.TagAttribute {
+			verr.Add(r, "tag attribute %s differs from tag attribute %s of response %s", r.TagAttribute, first.TagAttribute, first.Name)
+		}
+		if Design.MediaTypeWithIdentifier(first.MediaType) != mt {
+			verr.Add(r, "tagged responses must use the same media type, response %s uses %s", first.Name, first.MediaType)
+		}
+	}
+	return verr.AsError()
+}
+
+// sortedResponseNames returns the names of the given responses sorted alphabetically.
+func sortedResponseNames(responses map[string]*ResponseDefinition) []string {
+	names := make([]string, len(responses))
+	i := 0
+	for n := range responses {
+		names[i] = n
+		i++
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateHeaderAttributes checks that the attributes mapped to the response headers are
+// primitives or arrays of primitives defined by the response media type.
+func (r *ResponseDefinition) validateHeaderAttributes() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Headers == nil {
+		return nil
+	}
+	for n, h := range r.Headers.Type.ToObject() {
+		attName, ok := h.Metadata["header:attribute"]
+		if !ok || len(attName) == 0 {
+			continue
+		}
+		body := r.Type
+		if body == nil && r.MediaType != "" {
+			if mt := Design.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+				body = mt
+			}
+		}
+		if body == nil || !body.IsObject() {
+			verr.Add(r, "header %s is mapped to attribute %s but the response body is not an object", n, attName[0])
+			continue
+		}
+		att, ok := body.ToObject()[attName[0]]
+		if !ok {
+			verr.Add(r, "header %s is mapped to attribute %s which is not defined by the response body", n, attName[0])
+			continue
+		}
+		if !att.Type.IsPrimitive() {
+			if arr := att.Type.ToArray(); arr == nil || !arr.ElemType.Type.IsPrimitive() {
+				verr.Add(r, "header %s is mapped to attribute %s which is not a primitive or an array of primitives", n, attName[0])
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// validateRedirect checks that redirect responses use a redirect status and that the wildcards
+// of the redirect URL refer to required primitive attributes of the response media type.
+func (r *ResponseDefinition) validateRedirect() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if r.Status < 300 || r.Status > 399 {
+		verr.Add(r, "invalid redirect status %d, must be between 300 and 399", r.Status)
+	}
+	wcs := ExtractWildcards(r.RedirectURL)
+	if len(wcs) == 0 {
+		return verr.AsError()
+	}
+	body := r.Type
+	if body == nil && r.MediaType != "" {
+		if mt := Design.MediaTypeWithIdentifier(r.MediaType); mt != nil {
+			body = mt
+		}
+	}
+	if body == nil || !body.IsObject() {
+		verr.Add(r, "redirect URL %s uses wildcards but the response body is not an object", r.RedirectURL)
+		return verr.AsError()
+	}
+	var (
+		att  *AttributeDefinition
+		view *ViewDefinition
+	)
+	switch actual := body.(type) {
+	case *MediaTypeDefinition:
+		att = actual.AttributeDefinition
+		// The response helper builds the URL from the projection of the media type onto the
+		// response view.
+		if view = actual.Views[r.TagView()]; view == nil {
+			verr.Add(r, "view %s is not defined by media type %s", r.TagView(), actual.Identifier)
+			return verr.AsError()
+		}
+	case *UserTypeDefinition:
+		att = actual.AttributeDefinition
+	default:
+		att = &AttributeDefinition{Type: body}
+	}
+	for _, wc := range wcs {
+		a, ok := body.ToObject()[wc]
+		if !ok {
+			verr.Add(r, "redirect URL wildcard %s is not an attribute of the response body", wc)
+			continue
+		}
+		if view != nil {
+			if _, ok := view.Type.ToObject()[wc]; !ok {
+				verr.Add(r, "redirect URL wildcard %s is not rendered by view %s of the response media type", wc, view.Name)
+				continue
+			}
+		}
+		if !a.Type.IsPrimitive() || !att.IsRequired(wc) {
+			verr.Add(r, "redirect URL wildcard %s must be a required primitive attribute", wc)
+		}
+	}
+	return verr.AsError()
+}
+
+// validateMapParam checks that the map param with the given name is a query string param whose
+// keys are strings, integers or UUIDs and whose values are primitives other than File.
+func validateMapParam(a *ActionDefinition, n string, h *Hash) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	switch h.KeyType.Type.Kind() {
+	case StringKind, IntegerKind, UUIDKind:
+	default:
+		verr.Add(a, "Param %s has an invalid key type, map param keys must be strings, integers or UUIDs", n)
+	}
+	if !h.ElemType.Type.IsPrimitive() || h.ElemType.Type.Kind() == FileKind {
+		verr.Add(a, "Param %s has an invalid value type, map param values must be primitives", n)
+	}
+	for _, r := range a.Routes {
+		for _, w := range r.Params() {
+			if w == n {
+				verr.Add(a, "Param %s is a map and cannot be a path param", n)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// validateCookies checks that the cookies are primitives other than File.
+func validateCookies(parent dslengine.Definition, cookies *AttributeDefinition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	for n, c := range cookies.Type.ToObject() {
+		if !c.Type.IsPrimitive() || c.Type.Kind() == FileKind {
+			verr.Add(parent, "Cookie %s has an invalid type, cookies must be primitives", n)
+		}
+	}
+	return verr.AsError()
+}
+
+// hasFile returns true if the attribute or any of its child attributes is a File.
+func hasFile(att *AttributeDefinition) bool {
+	found := false
+	att.Walk(func(a *AttributeDefinition) error {
+		if a.Type.Kind() == FileKind {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
 // Validate checks the file server is properly initialized.
 func (f *FileServerDefinition) Validate() *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
@@ -413,8 +941,6 @@ func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 		}
 		if p.Type.Kind() == ObjectKind {
 			verr.Add(a, `parameter %s cannot be an object, only action payloads may be of type object`, n)
-		} else if p.Type.Kind() == HashKind {
-			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
 		}
 		ctx := fmt.Sprintf("parameter %s", n)
 		verr.Merge(p.Validate(ctx, a))
@@ -495,9 +1021,46 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Headers != nil {
 		verr.Merge(r.Headers.Validate("response headers", r))
 	}
+	if r.Cookies != nil {
+		verr.Merge(validateCookies(r, r.Cookies))
+	}
+	verr.Merge(r.validateHeaderAttributes())
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.RedirectURL != "" {
+		verr.Merge(r.validateRedirect())
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the error definition is consistent: its status is an error status and
+// its type an object.
+func (e *ErrorDefinition) Validate() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if e.Name == "" {
+		verr.Add(e, "error name cannot be empty")
+	}
+	if e.Status < 400 || e.Status > 599 {
+		verr.Add(e, "invalid error status %d, must be between 400 and 599", e.Status)
+	}
+	if e.Type == nil || !e.Type.IsObject() {
+		verr.Add(e, "error type must be an object")
+	}
+	return verr.AsError()
+}
+
+// validateErrors validates the given error definitions and checks that their names are unique.
+func validateErrors(parent dslengine.Definition, errors []*ErrorDefinition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	names := make(map[string]bool, len(errors))
+	for _, e := range errors {
+		if names[e.Name] {
+			verr.Add(parent, "error %#v is defined more than once", e.Name)
+		}
+		names[e.Name] = true
+		verr.Merge(e.Validate())
+	}
 	return verr.AsError()
 }
 
@@ -511,13 +1074,34 @@ func (r *RouteDefinition) Validate() *dslengine.ValidationErrors {
 }
 
 // Validate checks that the user type definition is consistent: it has a name and the attribute
-// backing the type is valid.
+// backing the type is valid. The variants of OneOf types must be distinct object types that do
+// not define the discriminator attribute.
 func (u *UserTypeDefinition) Validate(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
 	verr := new(dslengine.ValidationErrors)
 	if u.TypeName == "" {
 		verr.Add(parent, "%s - %s", ctx, "User type must have a name")
 	}
 	verr.Merge(u.AttributeDefinition.Validate(ctx, u))
+	if u.IsUnion() {
+		if len(u.Union.Variants) == 0 {
+			verr.Add(u, "OneOf type %s must define at least one variant", u.TypeName)
+		}
+		values := make(map[string]bool)
+		for _, v := range u.Union.Variants {
+			if values[v.Value] {
+				verr.Add(u, "variant %#v is defined twice", v.Value)
+			}
+			values[v.Value] = true
+			if !v.Type.IsObject() || v.Type.IsUnion() {
+				verr.Add(u, "type %s of variant %#v must be an object type", v.Type.TypeName, v.Value)
+				continue
+			}
+			if _, ok := v.Type.ToObject()[u.Union.Discriminator]; ok {
+				verr.Add(u, "type %s of variant %#v must not define the discriminator attribute %#v",
+					v.Type.TypeName, v.Value, u.Union.Discriminator)
+			}
+		}
+	}
 	return verr.AsError()
 }
 
@@ -547,6 +1131,19 @@ func (m *MediaTypeDefinition) Validate() *dslengine.ValidationErrors {
 	} else {
 		obj = m.Type.ToObject()
 	}
+	if etag, ok := m.Metadata["cache:etag"]; ok && len(etag) > 0 && etag[0] != "" {
+		if att := m.Type.ToObject()[etag[0]]; att == nil || !att.Type.IsPrimitive() {
+			verr.Add(m, "ETag attribute %s must be a primitive attribute of the media type", etag[0])
+		}
+	}
+	if lm, ok := m.Metadata["cache:last-modified"]; ok && len(lm) > 0 {
+		if att := m.Type.ToObject()[lm[0]]; att == nil || att.Type.Kind() != DateTimeKind {
+			verr.Add(m, "LastModified attribute %s must be a DateTime attribute of the media type", lm[0])
+		}
+	}
+	if _, ok := m.Metadata["hypermedia"]; ok && !m.Type.IsObject() {
+		verr.Add(m, "hypermedia formats can only be used with object media types, use them on the collection element media type instead")
+	}
 	if obj != nil {
 		for n, att := range obj {
 			verr.Merge(att.Validate("attribute "+n, m))
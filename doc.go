@@ -84,6 +84,30 @@ Middleware can be added to a goa service or a specific controller using the corr
 methods. goa comes with a few stock middleware that handle common needs such as logging, panic
 recovery or using the RequestID header to trace requests across multiple services.
 
+Endpoint middleware wraps the calls to the controller actions made by the generated handlers once
+the request has been decoded and validated. An endpoint middleware is a function that takes and
+returns an Endpoint and can be added with the UseEndpoint methods of the service or controller.
+goagen also generates a transport independent BottleService interface whose methods take the
+action request, for example *ShowBottleRequest which holds the parameters and payload, and return
+the action result, for example *GoaExampleBottle. NewBottleEndpoints returns one endpoint per
+method of a BottleService. The endpoints may be wrapped with middleware using their Use method
+and mounted with MountBottleEndpoints whose HTTP handlers decode the requests and encode the
+results.
+
+The generated MountBottleControllerAt and MountBottleEndpoints functions also accept a base path
+prefix such as "/api/v1" that is prepended to the paths of all the handlers they mount. This makes it
+possible to mount the same generated code under different prefixes without regenerating it. The
+prefix is cleaned with path.Join, "api/v1/" and "/api/v1" mount the handlers under the same paths.
+
+goagen also generates a Routes function that lists the method, path and handler name of each API
+route and a MountRoutes function that mounts a debug handler rendering the route table as JSON,
+including whether each route is actually mounted on the service mux. Both take the base path prefix
+given to the Mount functions so that the listed paths match the registered ones.
+
+The generated handlers are mounted on the default goa mux unless the service mux is replaced with
+UseMux. The mux package provides adapters for routers such as chi, gorilla/mux, httprouter and the
+standard library http.ServeMux.
+
 Error Handling
 
 The controller action methods generated by goagen such as the Update method of the BottleController
@@ -0,0 +1,28 @@
+package goa
+
+import "time"
+
+// Duration is the Go type of the attributes that use the "duration" format. Its values are
+// encoded and decoded using the syntax of time.ParseDuration (e.g. "1h30m") so that they can be
+// used in request and response bodies as well as in parameters, headers and cookies.
+type Duration time.Duration
+
+// String returns the duration formatted like time.Duration does, e.g. "1h30m0s".
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
@@ -0,0 +1,36 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Duration", func() {
+	type body struct {
+		Timeout  goa.Duration  `json:"timeout"`
+		Interval *goa.Duration `json:"interval,omitempty"`
+	}
+
+	It("encodes the values as duration strings", func() {
+		b, err := json.Marshal(body{Timeout: goa.Duration(90 * time.Minute)})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"timeout":"1h30m0s"}`))
+	})
+
+	It("decodes duration strings", func() {
+		var b body
+		Ω(json.Unmarshal([]byte(`{"timeout":"1h30m","interval":"10s"}`), &b)).Should(Succeed())
+		Ω(b.Timeout).Should(Equal(goa.Duration(90 * time.Minute)))
+		Ω(b.Interval).ShouldNot(BeNil())
+		Ω(*b.Interval).Should(Equal(goa.Duration(10 * time.Second)))
+	})
+
+	It("rejects invalid durations", func() {
+		var b body
+		Ω(json.Unmarshal([]byte(`{"timeout":"soon"}`), &b)).Should(HaveOccurred())
+	})
+})
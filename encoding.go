@@ -4,9 +4,12 @@ import (
 	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
-	"fmt"
 	"io"
 	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -56,20 +59,103 @@ type (
 		pool *sync.Pool
 	}
 
+	// Codec groups the encoder and decoder factories of an encoding with the content types
+	// they handle so that both may be registered at once, see RegisterCodec and the
+	// RegisterCodec methods of HTTPEncoder and HTTPDecoder.
+	Codec interface {
+		// ContentTypes lists the content types handled by the codec.
+		ContentTypes() []string
+		// NewEncoder returns an encoder that writes to w.
+		NewEncoder(w io.Writer) Encoder
+		// NewDecoder returns a decoder that reads from r.
+		NewDecoder(r io.Reader) Decoder
+	}
+
 	// HTTPDecoder is a Decoder that decodes HTTP request or response bodies given a set of
 	// known Content-Type to decoder mapping.
 	HTTPDecoder struct {
+		// DefaultContentType is the content type used to decode bodies that do not
+		// specify a Content-Type header. Defaults to "application/json".
+		DefaultContentType string
+
 		pools map[string]*decoderPool // Registered decoders
 	}
 
 	// HTTPEncoder is a Encoder that encodes HTTP request or response bodies given a set of
 	// known Content-Type to encoder mapping.
 	HTTPEncoder struct {
+		// DefaultContentType is the content type used to encode values when the request
+		// does not specify an Accept header. The default "*/*" encoder is used if empty.
+		DefaultContentType string
+		// Strict disables the fallback to the default "*/*" encoder for Accept headers that
+		// do not match any registered content type so that an ErrNotAcceptable error is
+		// returned instead. The default encoder is still used for Accept headers that
+		// accept any media type. The services generated by goagen always register a
+		// default encoder, setting Strict makes them respond with 406 Not Acceptable.
+		Strict bool
+
 		pools        map[string]*encoderPool // Registered encoders
 		contentTypes []string                // List of content types for type negotiation
 	}
 )
 
+var (
+	// registry holds the encoders and decoders registered with RegisterEncoder and
+	// RegisterDecoder.
+	registry = struct {
+		sync.RWMutex
+		encoders     map[string]*encoderPool
+		decoders     map[string]*decoderPool
+		contentTypes []string
+	}{
+		encoders: make(map[string]*encoderPool),
+		decoders: make(map[string]*decoderPool),
+	}
+)
+
+// RegisterEncoder registers an encoder for the given content types with all HTTP encoders.
+// HTTP encoders use the registered encoder when negotiating the response content type and no
+// encoder was registered directly for the content type, that is prior to falling back to the
+// default "*/*" encoder. This makes it possible to add support for new encodings (CBOR,
+// protobuf, custom binary formats etc.) without modifying the generated code, typically in
+// an init function:
+//
+//	func init() {
+//		goa.RegisterEncoder(cbor.NewEncoder, "application/cbor")
+//	}
+func RegisterEncoder(f EncoderFunc, contentTypes ...string) {
+	p := newEncodePool(f)
+	registry.Lock()
+	defer registry.Unlock()
+	for _, contentType := range contentTypes {
+		registry.encoders[parseContentType(contentType)] = p
+	}
+	registry.contentTypes = make([]string, 0, len(registry.encoders))
+	for contentType := range registry.encoders {
+		registry.contentTypes = append(registry.contentTypes, contentType)
+	}
+	sort.Strings(registry.contentTypes)
+}
+
+// RegisterDecoder registers a decoder for the given content types with all HTTP decoders.
+// HTTP decoders use the registered decoder for request bodies whose content type has no
+// decoder registered directly, prior to falling back to the default "*/*" decoder.
+func RegisterDecoder(f DecoderFunc, contentTypes ...string) {
+	p := newDecodePool(f)
+	registry.Lock()
+	defer registry.Unlock()
+	for _, contentType := range contentTypes {
+		registry.decoders[parseContentType(contentType)] = p
+	}
+}
+
+// RegisterCodec registers the encoder and decoder of the given codec with all HTTP encoders and
+// decoders, see RegisterEncoder and RegisterDecoder.
+func RegisterCodec(c Codec) {
+	RegisterEncoder(c.NewEncoder, c.ContentTypes()...)
+	RegisterDecoder(c.NewDecoder, c.ContentTypes()...)
+}
+
 // NewJSONEncoder is an adapter for the encoding package JSON encoder.
 func NewJSONEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
 
@@ -108,14 +194,22 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 	defer MeasureSince([]string{"goa", "decode", contentType}, now)
 	var p *decoderPool
 	if contentType == "" {
-		// Default to JSON
-		contentType = "application/json"
+		contentType = decoder.DefaultContentType
+		if contentType == "" {
+			// Default to JSON
+			contentType = "application/json"
+		}
 	} else {
 		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
 			contentType = mediaType
 		}
 	}
 	p = decoder.pools[contentType]
+	if p == nil {
+		registry.RLock()
+		p = registry.decoders[contentType]
+		registry.RUnlock()
+	}
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
@@ -135,14 +229,15 @@ func (decoder *HTTPDecoder) Register(f DecoderFunc, contentTypes ...string) {
 	p := newDecodePool(f)
 
 	for _, contentType := range contentTypes {
-		mediaType, _, err := mime.ParseMediaType(contentType)
-		if err != nil {
-			mediaType = contentType
-		}
-		decoder.pools[mediaType] = p
+		decoder.pools[parseContentType(contentType)] = p
 	}
 }
 
+// RegisterCodec registers the decoder of the given codec for the content types it handles.
+func (decoder *HTTPDecoder) RegisterCodec(c Codec) {
+	decoder.Register(c.NewDecoder, c.ContentTypes()...)
+}
+
 // newDecodePool checks to see if the DecoderFunc returns reusable decoders and if so, creates a
 // pool.
 func newDecodePool(f DecoderFunc) *decoderPool {
@@ -183,27 +278,46 @@ func (p *decoderPool) Put(d Decoder) {
 }
 
 // Encode uses the registered encoders and given content type to marshal and write the given value
-// using the given writer.
+// using the given writer. The accept argument is the value of the request Accept header, the
+// encoder used is the one registered for the media type that best matches it as described in
+// RFC 7231 section 5.3.2. Encode falls back to the default encoder registered for "*/*" if
+// there is no match and returns an ErrNotAcceptable error if there is no default encoder or the
+// encoder is strict.
 func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string) error {
-	now := time.Now()
-	if accept == "" {
-		accept = "*/*"
+	p, contentType, err := encoder.resolve(accept, false)
+	if err != nil {
+		return err
 	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
-		}
+	return encoder.encodeWith(p, contentType, v, resp)
+}
+
+// resolve returns the pool of the encoder used to encode responses to requests with the given
+// Accept header and the negotiated content type. The default "*/*" encoder is used if there is no
+// match unless the encoder is strict, lenient makes strict encoders fall back as well.
+func (encoder *HTTPEncoder) resolve(accept string, lenient bool) (*encoderPool, string, error) {
+	if accept == "" && encoder.DefaultContentType != "" {
+		// Prefer the default content type but still fall back to the "*/*" encoder
+		accept = encoder.DefaultContentType + ", */*;q=0.5"
 	}
-	defer MeasureSince([]string{"goa", "encode", contentType}, now)
+	contentType := encoder.Negotiate(accept)
 	p := encoder.pools[contentType]
-	if p == nil && contentType != "*/*" {
+	if p == nil {
+		registry.RLock()
+		p = registry.encoders[contentType]
+		registry.RUnlock()
+	}
+	if p == nil && (!encoder.Strict || lenient) {
 		p = encoder.pools["*/*"]
 	}
 	if p == nil {
-		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+		return nil, "", ErrNotAcceptable("no encoder registered for any of the accepted media types", "accept", accept)
 	}
+	return p, contentType, nil
+}
+
+// encodeWith writes v to resp using an encoder of the given pool.
+func (encoder *HTTPEncoder) encodeWith(p *encoderPool, contentType string, v interface{}, resp io.Writer) error {
+	defer MeasureSince([]string{"goa", "encode", contentType}, time.Now())
 
 	// the encoderPool will handle whether or not a pool is actually in use
 	e := p.Get(resp)
@@ -215,16 +329,156 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	return nil
 }
 
+// Negotiate returns the registered content type that best matches the given Accept header
+// value, content types registered with RegisterEncoder are also considered. Media ranges are
+// weighed using their quality values and the most specific range matching a content type
+// applies, so that "application/*;q=0.5, application/json" prefers JSON and
+// "*/*, application/xml;q=0" never selects XML. Negotiate returns "*/*" if the header is empty
+// or only matches using "*/*" and a default encoder is registered. It returns the empty string
+// if no registered content type is acceptable.
+func (encoder *HTTPEncoder) Negotiate(accept string) string {
+	if accept == "" {
+		accept = "*/*"
+	}
+	ranges := parseAccept(accept)
+	var (
+		best     string
+		bestQ    float64
+		bestSpec = -1
+		bestPos  int
+	)
+	registry.RLock()
+	offers := encoder.contentTypes
+	if len(registry.contentTypes) > 0 {
+		n := len(encoder.contentTypes)
+		offers = append(encoder.contentTypes[:n:n], registry.contentTypes...)
+	}
+	registry.RUnlock()
+	for _, ct := range offers {
+		if ct == "*/*" {
+			continue
+		}
+		q, spec, pos := -1.0, -1, 0
+		for _, r := range ranges {
+			if s := r.match(ct); s > spec {
+				q, spec, pos = r.q, s, r.pos
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if q > bestQ || q == bestQ && (spec > bestSpec || spec == bestSpec && pos < bestPos) {
+			best, bestQ, bestSpec, bestPos = ct, q, spec, pos
+		}
+	}
+	if bestSpec <= 0 {
+		if _, ok := encoder.pools["*/*"]; ok {
+			for _, r := range ranges {
+				if r.typ == "*" && r.q > 0 {
+					return "*/*"
+				}
+			}
+		}
+	}
+	return best
+}
+
+// RequestedView returns the name of the media type view requested by the client. The view is
+// read from the "view" query string parameter or else from the "view" parameter of the Accept
+// header media range with the highest quality value, e.g. "application/vnd.bottle+json; view=tiny".
+// RequestedView returns the empty string if the request does not specify a view.
+func RequestedView(req *http.Request) string {
+	if v := req.URL.Query().Get("view"); v != "" {
+		return v
+	}
+	var (
+		view  string
+		bestQ float64
+	)
+	for _, r := range parseAccept(req.Header.Get("Accept")) {
+		if r.view != "" && r.q > bestQ {
+			view, bestQ = r.view, r.q
+		}
+	}
+	return view
+}
+
+// Project copies the fields of src into dst, a pointer to a value of the type generated for a
+// media type view. The copy goes through the JSON representation of src so that only the
+// attributes rendered by both views are copied, nested media types included.
+func Project(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// mediaRange is a parsed Accept header element.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+	pos          int
+	view         string
+	params       map[string]string
+}
+
+// match returns the specificity of the match between the media range and the given media
+// type: 2 for an exact match, 1 for a type wildcard match, 0 for a "*/*" match and -1 if the
+// media type does not match the range.
+func (r *mediaRange) match(mediaType string) int {
+	if r.typ == "*" {
+		return 0
+	}
+	elems := strings.SplitN(mediaType, "/", 2)
+	if len(elems) != 2 || !strings.EqualFold(elems[0], r.typ) {
+		return -1
+	}
+	if r.subtype == "*" {
+		return 1
+	}
+	if strings.EqualFold(elems[1], r.subtype) {
+		return 2
+	}
+	return -1
+}
+
+// parseAccept parses the value of an Accept header into a list of media ranges. Invalid
+// elements are ignored.
+func parseAccept(accept string) []*mediaRange {
+	var ranges []*mediaRange
+	for i, elem := range strings.Split(accept, ",") {
+		elem = strings.TrimSpace(elem)
+		if elem == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(elem)
+		if err != nil {
+			continue
+		}
+		elems := strings.SplitN(mediaType, "/", 2)
+		if len(elems) != 2 || elems[0] == "*" && elems[1] != "*" {
+			continue
+		}
+		r := &mediaRange{typ: elems[0], subtype: elems[1], q: 1, pos: i, view: params["view"], params: params}
+		if qv, ok := params["q"]; ok {
+			q, err := strconv.ParseFloat(qv, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+			r.q = q
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 	p := newEncodePool(f)
 	for _, contentType := range contentTypes {
-		mediaType, _, err := mime.ParseMediaType(contentType)
-		if err != nil {
-			mediaType = contentType
-		}
-		encoder.pools[mediaType] = p
+		encoder.pools[parseContentType(contentType)] = p
 	}
 
 	// Rebuild a unique index of registered content encoders to be used in EncodeResponse
@@ -232,6 +486,22 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 	for contentType := range encoder.pools {
 		encoder.contentTypes = append(encoder.contentTypes, contentType)
 	}
+	sort.Strings(encoder.contentTypes)
+}
+
+// RegisterCodec registers the encoder of the given codec for the content types it handles.
+func (encoder *HTTPEncoder) RegisterCodec(c Codec) {
+	encoder.Register(c.NewEncoder, c.ContentTypes()...)
+}
+
+// parseContentType returns the media type of the given Content-Type header value stripped of
+// any parameter.
+func parseContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
 }
 
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
@@ -1,3 +1,20 @@
+/*
+Package cbor provides a goa adapter to the CBOR (RFC 7049) encoder and decoder implemented by
+github.com/ugorji/go/codec. CBOR is a compact binary encoding well suited to constrained devices.
+
+Use the Consumes and Produces DSL to have the generated code register the adapter with the
+service encoders and decoders:
+
+	Consumes("application/json", "application/cbor")
+	Produces("application/json", "application/cbor")
+
+The adapter may also be registered directly with a service or a client using Codec:
+
+	service.Decoder.RegisterCodec(cbor.Codec)
+	service.Encoder.RegisterCodec(cbor.Codec)
+
+The Handle variable can be used to configure the codec prior to serving requests.
+*/
 package cbor
 
 import (
@@ -11,11 +28,20 @@ var (
 	// Handle used by encoder and decoder.
 	Handle codec.CborHandle
 
+	// ContentTypes lists the MIME types handled by the cbor encoder and decoder.
+	ContentTypes = []string{"application/cbor", "application/x-cbor"}
+
+	// Codec registers the cbor encoder and decoder for ContentTypes.
+	Codec goa.Codec = cborCodec{}
+
 	// Enforce that codec.Decoder satisfies goa.ResettableDecoder at compile time
 	_ goa.ResettableDecoder = (*codec.Decoder)(nil)
 	_ goa.ResettableEncoder = (*codec.Encoder)(nil)
 )
 
+// cborCodec is the goa.Codec exposed as Codec.
+type cborCodec struct{}
+
 // NewDecoder returns a cbor decoder.
 func NewDecoder(r io.Reader) goa.Decoder {
 	return codec.NewDecoder(r, &Handle)
@@ -25,3 +51,12 @@ func NewDecoder(r io.Reader) goa.Decoder {
 func NewEncoder(w io.Writer) goa.Encoder {
 	return codec.NewEncoder(w, &Handle)
 }
+
+// ContentTypes returns ContentTypes.
+func (cborCodec) ContentTypes() []string { return ContentTypes }
+
+// NewEncoder returns a cbor encoder.
+func (cborCodec) NewEncoder(w io.Writer) goa.Encoder { return NewEncoder(w) }
+
+// NewDecoder returns a cbor decoder.
+func (cborCodec) NewDecoder(r io.Reader) goa.Decoder { return NewDecoder(r) }
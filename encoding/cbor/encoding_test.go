@@ -0,0 +1,50 @@
+package cbor_test
+
+import (
+	"bytes"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/encoding/cbor"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CborEncoding", func() {
+	type Payload struct {
+		Name  string
+		Count int
+	}
+
+	var encoder *goa.HTTPEncoder
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+		encoder.RegisterCodec(cbor.Codec)
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+		decoder.RegisterCodec(cbor.Codec)
+	})
+
+	for _, ct := range cbor.ContentTypes {
+		contentType := ct
+
+		It("round trips values using "+contentType, func() {
+			var b bytes.Buffer
+			err := encoder.Encode(&Payload{Name: "goa", Count: 42}, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b.Bytes()[0]).Should(Equal(byte(0xa2))) // map with 2 pairs
+
+			var payload Payload
+			err = decoder.Decode(&payload, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload.Name).Should(Equal("goa"))
+			Ω(payload.Count).Should(Equal(42))
+		})
+	}
+
+	It("negotiates the CBOR encoding", func() {
+		Ω(encoder.Negotiate("application/cbor, application/json;q=0.5")).Should(Equal("application/cbor"))
+	})
+})
@@ -18,6 +18,9 @@ The built-in encoder and decoder media types are:
 	- application/msgpack and application/x-msgpack
 	- application/binc and application/x-binc
 	- application/cbor and application/x-cbor
+	- application/x-protobuf and application/protobuf
+	- application/yaml, application/x-yaml and text/yaml
+	- application/x-ndjson
 
 External encoders and decoders can also be specified via the DSL:
 
@@ -1,3 +1,21 @@
+/*
+Package msgpack provides a goa adapter to the MessagePack encoder and decoder implemented by
+github.com/ugorji/go/codec.
+
+Use the Consumes and Produces DSL to have the generated code register the adapter with the
+service encoders and decoders:
+
+	Consumes("application/json", "application/msgpack")
+	Produces("application/json", "application/msgpack")
+
+The adapter may also be registered directly with a service:
+
+	service.Decoder.Register(msgpack.NewDecoder, msgpack.ContentTypes...)
+	service.Encoder.Register(msgpack.NewEncoder, msgpack.ContentTypes...)
+
+The Handle variable can be used to configure the codec (e.g. to enable RawToString) prior to
+serving requests.
+*/
 package msgpack
 
 import (
@@ -12,7 +30,11 @@ var (
 	_ goa.ResettableDecoder = (*codec.Decoder)(nil)
 	_ goa.ResettableEncoder = (*codec.Encoder)(nil)
 
+	// Handle is the codec handle used by all the msgpack encoders and decoders.
 	Handle codec.MsgpackHandle
+
+	// ContentTypes lists the MIME types handled by the msgpack encoder and decoder.
+	ContentTypes = []string{"application/msgpack", "application/x-msgpack"}
 )
 
 // NewDecoder returns a msgpack decoder.
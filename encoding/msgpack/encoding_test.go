@@ -0,0 +1,46 @@
+package msgpack_test
+
+import (
+	"bytes"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/encoding/msgpack"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MsgpackEncoding", func() {
+	type Payload struct {
+		Name  string
+		Count int
+	}
+
+	var encoder *goa.HTTPEncoder
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+		encoder.Register(msgpack.NewEncoder, msgpack.ContentTypes...)
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+		decoder.Register(msgpack.NewDecoder, msgpack.ContentTypes...)
+	})
+
+	for _, ct := range msgpack.ContentTypes {
+		contentType := ct
+
+		It("round trips values using "+contentType, func() {
+			var b bytes.Buffer
+			err := encoder.Encode(&Payload{Name: "goa", Count: 42}, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b.Bytes()[0]).ShouldNot(Equal(byte('{')))
+
+			var payload Payload
+			err = decoder.Decode(&payload, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload.Name).Should(Equal("goa"))
+			Ω(payload.Count).Should(Equal(42))
+		})
+	}
+})
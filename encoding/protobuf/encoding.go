@@ -0,0 +1,73 @@
+/*
+Package protobuf provides a goa encoder and decoder that encode the generated media types, user
+types and payloads using the Protocol Buffers wire format.
+
+Use the Consumes and Produces DSL to have the generated code register the adapter with the
+service encoders and decoders and the "app" generator write the .proto file describing the
+messages:
+
+	Consumes("application/json", "application/x-protobuf")
+	Produces("application/json", "application/x-protobuf")
+
+The adapter may also be registered directly with a service:
+
+	service.Decoder.Register(protobuf.NewDecoder, protobuf.ContentTypes...)
+	service.Encoder.Register(protobuf.NewEncoder, protobuf.ContentTypes...)
+
+The encoder does not require the types to be generated by protoc, it encodes the exported fields
+of any struct using reflection. The fields are numbered in the order in which they are declared
+starting at 1 unless they have a "protobuf" tag specifying their number, see Marshal. Types
+generated by protoc should use the gogoprotobuf package instead.
+*/
+package protobuf
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/goadesign/goa"
+)
+
+// ContentTypes lists the MIME types handled by the protobuf encoder and decoder.
+var ContentTypes = []string{"application/x-protobuf", "application/protobuf"}
+
+type (
+	// Encoder encodes values using the Protocol Buffers wire format.
+	Encoder struct {
+		w io.Writer
+	}
+
+	// Decoder decodes values encoded using the Protocol Buffers wire format.
+	Decoder struct {
+		r io.Reader
+	}
+)
+
+// NewEncoder returns a protobuf encoder that writes to w.
+func NewEncoder(w io.Writer) goa.Encoder {
+	return &Encoder{w: w}
+}
+
+// NewDecoder returns a protobuf decoder that reads from r.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r}
+}
+
+// Encode writes the protobuf encoding of v, see Marshal.
+func (enc *Encoder) Encode(v interface{}) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.Write(b)
+	return err
+}
+
+// Decode reads the entire input and decodes it into v, see Unmarshal.
+func (dec *Decoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(dec.r)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(b, v)
+}
@@ -0,0 +1,13 @@
+package protobuf_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestProtobufEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Protobuf Encoding Suite")
+}
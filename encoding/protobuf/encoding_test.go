@@ -0,0 +1,113 @@
+package protobuf_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/encoding/protobuf"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ProtobufEncoding", func() {
+	type Tag struct {
+		Name *string
+	}
+
+	type Bottle struct {
+		Color    *string
+		ID       int
+		Meta     map[string]interface{}
+		Rating   *float64
+		Tags     []*Tag
+		Matrix   [][]int
+		Vintages []int
+		Created  time.Time
+		Counts   map[string]int
+		Sparkly  *bool
+	}
+
+	var encoder *goa.HTTPEncoder
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+		encoder.Register(protobuf.NewEncoder, protobuf.ContentTypes...)
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+		decoder.Register(protobuf.NewDecoder, protobuf.ContentTypes...)
+	})
+
+	It("uses the Protocol Buffers wire format", func() {
+		b, err := protobuf.Marshal(&struct{ A int }{A: 150})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(Equal([]byte{0x08, 0x96, 0x01}))
+
+		b, err = protobuf.Marshal(&struct {
+			A string `protobuf:"2"`
+		}{A: "testing"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(Equal([]byte{0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g'}))
+	})
+
+	It("decodes packed repeated fields", func() {
+		var v struct{ A []int }
+		err := protobuf.Unmarshal([]byte{0x0a, 0x03, 0x03, 0x8e, 0x02}, &v)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.A).Should(Equal([]int{3, 270}))
+	})
+
+	for _, ct := range protobuf.ContentTypes {
+		contentType := ct
+
+		It("round trips values using "+contentType, func() {
+			color, name, rating, sparkly := "red", "dry", 4.5, false
+			created := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+			bottle := &Bottle{
+				Color:    &color,
+				ID:       -42,
+				Meta:     map[string]interface{}{"origin": "Napa"},
+				Rating:   &rating,
+				Tags:     []*Tag{{Name: &name}, {}},
+				Matrix:   [][]int{{1, 2}, {3}},
+				Vintages: []int{2010, 2012},
+				Created:  created,
+				Counts:   map[string]int{"a": 1, "b": 2},
+				Sparkly:  &sparkly,
+			}
+			var b bytes.Buffer
+			err := encoder.Encode(bottle, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			var decoded Bottle
+			err = decoder.Decode(&decoded, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(*bottle))
+		})
+	}
+
+	It("encodes slices as messages with a repeated field", func() {
+		name := "dry"
+		b, err := protobuf.Marshal([]*Tag{{Name: &name}, {Name: &name}})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		var tags []*Tag
+		Ω(protobuf.Unmarshal(b, &tags)).ShouldNot(HaveOccurred())
+		Ω(tags).Should(HaveLen(2))
+		Ω(*tags[1].Name).Should(Equal("dry"))
+	})
+
+	It("ignores unknown fields", func() {
+		var v struct{ A int }
+		err := protobuf.Unmarshal([]byte{0x08, 0x01, 0x12, 0x01, 'x'}, &v)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.A).Should(Equal(1))
+	})
+
+	It("reports values that cannot be encoded", func() {
+		_, err := protobuf.Marshal(42)
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,558 @@
+package protobuf
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type (
+	// field is an exported struct field and its protobuf field number.
+	field struct {
+		index int
+		num   int
+	}
+
+	// wireValue is a field value read from an encoded message.
+	wireValue struct {
+		typ int
+		u   uint64
+		b   []byte
+	}
+)
+
+// Marshal returns the protobuf encoding of v which must be a struct, a slice or a pointer to
+// either. The fields of structs are encoded as follows:
+//
+//   - booleans and integers are encoded as bool, int64 or uint64 values.
+//   - floating point numbers are encoded as double values.
+//   - strings, byte slices and values implementing encoding.TextMarshaler (e.g. time.Time)
+//     are encoded as string or bytes values.
+//   - structs are encoded as embedded messages.
+//   - slices are encoded as repeated fields and maps whose keys are strings, integers or
+//     booleans are encoded as map fields.
+//   - interface values, slices of slices or maps and maps of slices or maps are encoded as
+//     bytes values containing their JSON representation.
+//
+// Nil pointers and interfaces are omitted. The field numbers start at 1 and follow the order in
+// which the exported fields are declared, a field number may be set explicitly with a
+// "protobuf" tag. Slices are encoded as a message containing a single repeated field numbered 1.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return appendMessage(nil, rv)
+	case reflect.Slice:
+		return appendField(nil, 1, rv)
+	default:
+		return nil, fmt.Errorf("protobuf: cannot encode value of type %s", rv.Type())
+	}
+}
+
+// Unmarshal decodes the protobuf encoded data into v which must be a pointer to a struct or a
+// slice, see Marshal. Unknown fields are ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("protobuf: cannot decode into non pointer value of type %T", v)
+	}
+	rv = rv.Elem()
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return unmarshalMessage(data, rv)
+	case reflect.Slice:
+		return readMessage(data, func(num int, w wireValue) error {
+			if num != 1 {
+				return nil
+			}
+			return setField(rv, w)
+		})
+	default:
+		return fmt.Errorf("protobuf: cannot decode into value of type %s", rv.Type())
+	}
+}
+
+// fields returns the exported fields of t with their field numbers.
+func fields(t reflect.Type) ([]field, error) {
+	var fs []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("protobuf")
+		if tag == "-" {
+			continue
+		}
+		num := len(fs) + 1
+		if tag != "" {
+			n, err := strconv.Atoi(tag)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("protobuf: invalid field number %q for field %s of %s", tag, f.Name, t)
+			}
+			num = n
+		}
+		fs = append(fs, field{index: i, num: num})
+	}
+	return fs, nil
+}
+
+// appendMessage appends the fields of the struct v to b.
+func appendMessage(b []byte, v reflect.Value) ([]byte, error) {
+	fs, err := fields(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fs {
+		if b, err = appendField(b, f.num, v.Field(f.index)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendField appends the field with number num and value v to b.
+func appendField(b []byte, num int, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return b, nil
+		}
+		return appendField(b, num, v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return b, nil
+		}
+		return appendJSON(b, num, v.Interface())
+	}
+	if isText(v.Type()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(b, num, text), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		var u uint64
+		if v.Bool() {
+			u = 1
+		}
+		return appendVarint(appendKey(b, num, wireVarint), u), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(appendKey(b, num, wireVarint), uint64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendVarint(appendKey(b, num, wireVarint), v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return appendFixed64(appendKey(b, num, wireFixed64), math.Float64bits(v.Float())), nil
+	case reflect.String:
+		return appendBytes(b, num, []byte(v.String())), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendBytes(b, num, v.Bytes()), nil
+		}
+		if nested(v.Type().Elem()) {
+			return appendJSON(b, num, v.Interface())
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			if b, err = appendField(b, num, zeroIfNil(v.Index(i))); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		if !mapKey(v.Type().Key()) || nested(v.Type().Elem()) {
+			return appendJSON(b, num, v.Interface())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			entry, err := appendField(nil, 1, k)
+			if err != nil {
+				return nil, err
+			}
+			if entry, err = appendField(entry, 2, zeroIfNil(v.MapIndex(k))); err != nil {
+				return nil, err
+			}
+			b = appendBytes(b, num, entry)
+		}
+		return b, nil
+	case reflect.Struct:
+		msg, err := appendMessage(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(b, num, msg), nil
+	default:
+		return nil, fmt.Errorf("protobuf: cannot encode value of type %s", v.Type())
+	}
+}
+
+// appendJSON appends a bytes field containing the JSON representation of v to b.
+func appendJSON(b []byte, num int, v interface{}) ([]byte, error) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return appendBytes(b, num, js), nil
+}
+
+// appendKey appends the key of the field with number num and the given wire type to b.
+func appendKey(b []byte, num, typ int) []byte {
+	return appendVarint(b, uint64(num)<<3|uint64(typ))
+}
+
+// appendBytes appends the length delimited field with number num and value v to b.
+func appendBytes(b []byte, num int, v []byte) []byte {
+	b = appendVarint(appendKey(b, num, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// appendVarint appends the varint encoding of u to b.
+func appendVarint(b []byte, u uint64) []byte {
+	for u >= 0x80 {
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(b, byte(u))
+}
+
+// appendFixed64 appends the little endian encoding of u to b.
+func appendFixed64(b []byte, u uint64) []byte {
+	for i := 0; i < 8; i++ {
+		b = append(b, byte(u>>(8*uint(i))))
+	}
+	return b
+}
+
+// unmarshalMessage decodes the message data into the struct v.
+func unmarshalMessage(data []byte, v reflect.Value) error {
+	fs, err := fields(v.Type())
+	if err != nil {
+		return err
+	}
+	byNum := make(map[int]int, len(fs))
+	for _, f := range fs {
+		byNum[f.num] = f.index
+	}
+	return readMessage(data, func(num int, w wireValue) error {
+		i, ok := byNum[num]
+		if !ok {
+			return nil
+		}
+		if err := setField(v.Field(i), w); err != nil {
+			return fmt.Errorf("%s.%s: %s", v.Type(), v.Type().Field(i).Name, err)
+		}
+		return nil
+	})
+}
+
+// setField decodes w into v. Repeated fields and map entries are appended to v.
+func setField(v reflect.Value, w wireValue) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setField(v.Elem(), w)
+	case reflect.Interface:
+		var i interface{}
+		if err := unmarshalJSON(w, &i); err != nil {
+			return err
+		}
+		if i != nil {
+			v.Set(reflect.ValueOf(i))
+		}
+		return nil
+	}
+	if isText(v.Type()) && reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		if w.typ != wireBytes {
+			return wireTypeError(w)
+		}
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(w.b)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if w.typ != wireVarint {
+			return wireTypeError(w)
+		}
+		v.SetBool(w.u != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if w.typ != wireVarint {
+			return wireTypeError(w)
+		}
+		v.SetInt(int64(w.u))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if w.typ != wireVarint {
+			return wireTypeError(w)
+		}
+		v.SetUint(w.u)
+	case reflect.Float32, reflect.Float64:
+		switch w.typ {
+		case wireFixed64:
+			v.SetFloat(math.Float64frombits(w.u))
+		case wireFixed32:
+			v.SetFloat(float64(math.Float32frombits(uint32(w.u))))
+		default:
+			return wireTypeError(w)
+		}
+	case reflect.String:
+		if w.typ != wireBytes {
+			return wireTypeError(w)
+		}
+		v.SetString(string(w.b))
+	case reflect.Slice:
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Uint8 {
+			if w.typ != wireBytes {
+				return wireTypeError(w)
+			}
+			v.SetBytes(append([]byte(nil), w.b...))
+			return nil
+		}
+		if nested(elem) {
+			return unmarshalJSON(w, v.Addr().Interface())
+		}
+		if w.typ == wireBytes && packable(elem) {
+			return readPacked(w.b, elem, func(e wireValue) error {
+				return appendElem(v, e)
+			})
+		}
+		return appendElem(v, w)
+	case reflect.Map:
+		if !mapKey(v.Type().Key()) || nested(v.Type().Elem()) {
+			return unmarshalJSON(w, v.Addr().Interface())
+		}
+		if w.typ != wireBytes {
+			return wireTypeError(w)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		val := reflect.New(v.Type().Elem()).Elem()
+		err := readMessage(w.b, func(num int, e wireValue) error {
+			switch num {
+			case 1:
+				return setField(key, e)
+			case 2:
+				return setField(val, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(key, val)
+	case reflect.Struct:
+		if w.typ != wireBytes {
+			return wireTypeError(w)
+		}
+		return unmarshalMessage(w.b, v)
+	default:
+		return fmt.Errorf("cannot decode into value of type %s", v.Type())
+	}
+	return nil
+}
+
+// appendElem decodes w into a new element and appends it to the slice v.
+func appendElem(v reflect.Value, w wireValue) error {
+	e := reflect.New(v.Type().Elem()).Elem()
+	if err := setField(e, w); err != nil {
+		return err
+	}
+	v.Set(reflect.Append(v, e))
+	return nil
+}
+
+// unmarshalJSON decodes the JSON representation contained in the bytes value w into v.
+func unmarshalJSON(w wireValue, v interface{}) error {
+	if w.typ != wireBytes {
+		return wireTypeError(w)
+	}
+	return json.Unmarshal(w.b, v)
+}
+
+// readMessage calls fn with the number and value of each field of the message data.
+func readMessage(data []byte, fn func(num int, w wireValue) error) error {
+	for len(data) > 0 {
+		key, n := readVarint(data)
+		if n <= 0 {
+			return fmt.Errorf("protobuf: invalid field key")
+		}
+		data = data[n:]
+		w := wireValue{typ: int(key & 7)}
+		switch w.typ {
+		case wireVarint:
+			w.u, n = readVarint(data)
+			if n <= 0 {
+				return fmt.Errorf("protobuf: invalid varint")
+			}
+		case wireFixed64, wireFixed32:
+			n = 8
+			if w.typ == wireFixed32 {
+				n = 4
+			}
+			if len(data) < n {
+				return fmt.Errorf("protobuf: unexpected end of message")
+			}
+			for i := 0; i < n; i++ {
+				w.u |= uint64(data[i]) << (8 * uint(i))
+			}
+		case wireBytes:
+			l, m := readVarint(data)
+			if m <= 0 || uint64(len(data)-m) < l {
+				return fmt.Errorf("protobuf: invalid length delimited field")
+			}
+			n = m + int(l)
+			w.b = data[m:n]
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", w.typ)
+		}
+		data = data[n:]
+		if err := fn(int(key>>3), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPacked calls fn with each element of the packed repeated field data whose elements are of
+// type t.
+func readPacked(data []byte, t reflect.Type, fn func(w wireValue) error) error {
+	for len(data) > 0 {
+		var w wireValue
+		switch t.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if len(data) < 8 {
+				return fmt.Errorf("protobuf: unexpected end of packed field")
+			}
+			w.typ = wireFixed64
+			for i := 0; i < 8; i++ {
+				w.u |= uint64(data[i]) << (8 * uint(i))
+			}
+			data = data[8:]
+		default:
+			u, n := readVarint(data)
+			if n <= 0 {
+				return fmt.Errorf("protobuf: invalid varint")
+			}
+			w.typ, w.u = wireVarint, u
+			data = data[n:]
+		}
+		if err := fn(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVarint decodes the varint at the start of b and returns it with the number of bytes read,
+// 0 or less if b does not start with a valid varint.
+func readVarint(b []byte) (uint64, int) {
+	var u uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		u |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return u, i + 1
+		}
+	}
+	return 0, -1
+}
+
+// isText returns true if the values of type t are encoded using their text representation.
+func isText(t reflect.Type) bool {
+	return t.Kind() != reflect.Interface && t.Implements(textMarshalerType)
+}
+
+// nested returns true if t, once dereferenced, is a slice or a map that cannot be encoded as an
+// element of a repeated or map field.
+func nested(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// mapKey returns true if values of type t may be used as keys of map fields.
+func mapKey(t reflect.Type) bool {
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// packable returns true if the repeated fields with elements of type t may be packed.
+func packable(t reflect.Type) bool {
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// zeroIfNil returns the zero value of the type pointed to by v if v is a nil pointer so that
+// nil elements of repeated and map fields are encoded as empty values.
+func zeroIfNil(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v
+}
+
+// wireTypeError returns the error reported when a field value has an unexpected wire type.
+func wireTypeError(w wireValue) error {
+	return fmt.Errorf("unexpected wire type %d", w.typ)
+}
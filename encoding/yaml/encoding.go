@@ -0,0 +1,158 @@
+/*
+Package yaml provides a goa adapter to the YAML encoder and decoder implemented by
+gopkg.in/yaml.v2. YAML is convenient for configuration style APIs where humans write the request
+bodies.
+
+Use the Consumes and Produces DSL to have the generated code register the adapter with the
+service encoders and decoders:
+
+	Consumes("application/json", "application/yaml")
+	Produces("application/json", "application/yaml")
+
+The adapter may also be registered directly with a service or a client using Codec:
+
+	service.Decoder.RegisterCodec(yaml.Codec)
+	service.Encoder.RegisterCodec(yaml.Codec)
+
+Values go through their JSON representation so that the field names used in YAML documents are
+the same as in JSON, as defined by the json struct tags of the generated types. The decoder
+rejects documents larger than MaxSize. The YAML parser also limits the expansion of aliases so
+that small documents cannot produce arbitrarily large values.
+*/
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/goadesign/goa"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ContentTypes lists the MIME types handled by the yaml encoder and decoder.
+	ContentTypes = []string{"application/yaml", "application/x-yaml", "text/yaml"}
+
+	// MaxSize is the maximum size in bytes of the documents read by the decoders.
+	MaxSize int64 = 1 << 20
+
+	// Codec registers the yaml encoder and decoder for ContentTypes.
+	Codec goa.Codec = yamlCodec{}
+)
+
+type (
+	// Encoder writes the YAML representation of values.
+	Encoder struct {
+		w io.Writer
+	}
+
+	// Decoder reads YAML documents.
+	Decoder struct {
+		r io.Reader
+	}
+
+	// yamlCodec is the goa.Codec exposed as Codec.
+	yamlCodec struct{}
+)
+
+// NewEncoder returns a yaml encoder that writes to w.
+func NewEncoder(w io.Writer) goa.Encoder {
+	return &Encoder{w: w}
+}
+
+// NewDecoder returns a yaml decoder that reads from r.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r}
+}
+
+// Encode writes the YAML representation of v.
+func (enc *Encoder) Encode(v interface{}) error {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(js))
+	d.UseNumber()
+	var raw interface{}
+	if err := d.Decode(&raw); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(fromJSON(raw))
+	if err != nil {
+		return err
+	}
+	_, err = enc.w.Write(b)
+	return err
+}
+
+// Decode reads a YAML document and decodes it into v.
+func (dec *Decoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(io.LimitReader(dec.r, MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(b)) > MaxSize {
+		return fmt.Errorf("YAML document exceeds maximum size of %d bytes", MaxSize)
+	}
+	var raw interface{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	js, err := json.Marshal(toJSON(raw))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// ContentTypes returns ContentTypes.
+func (yamlCodec) ContentTypes() []string { return ContentTypes }
+
+// NewEncoder returns a yaml encoder.
+func (yamlCodec) NewEncoder(w io.Writer) goa.Encoder { return NewEncoder(w) }
+
+// NewDecoder returns a yaml decoder.
+func (yamlCodec) NewDecoder(r io.Reader) goa.Decoder { return NewDecoder(r) }
+
+// toJSON converts the maps decoded by the YAML parser, whose keys may be of any type, into maps
+// with string keys that can be marshaled to JSON.
+func toJSON(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			m[fmt.Sprint(k)] = toJSON(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = toJSON(e)
+		}
+	}
+	return v
+}
+
+// fromJSON converts the numbers decoded from JSON into integers or floats so that they are
+// written as YAML numbers.
+func fromJSON(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case json.Number:
+		if i, err := actual.Int64(); err == nil {
+			return i
+		}
+		f, _ := actual.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range actual {
+			actual[k] = fromJSON(e)
+		}
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = fromJSON(e)
+		}
+	}
+	return v
+}
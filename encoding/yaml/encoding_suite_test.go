@@ -0,0 +1,13 @@
+package yaml_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestYamlEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Yaml Encoding Suite")
+}
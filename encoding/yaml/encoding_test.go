@@ -0,0 +1,75 @@
+package yaml_test
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/encoding/yaml"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("YamlEncoding", func() {
+	type Payload struct {
+		Name   string                 `json:"name"`
+		Count  int                    `json:"count"`
+		Labels map[string]interface{} `json:"labels,omitempty"`
+	}
+
+	var encoder *goa.HTTPEncoder
+	var decoder *goa.HTTPDecoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+		encoder.RegisterCodec(yaml.Codec)
+		decoder = goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+		decoder.RegisterCodec(yaml.Codec)
+	})
+
+	for _, ct := range yaml.ContentTypes {
+		contentType := ct
+
+		It("round trips values using "+contentType, func() {
+			var b bytes.Buffer
+			err := encoder.Encode(&Payload{Name: "goa", Count: 42}, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b.String()).Should(Equal("count: 42\nname: goa\n"))
+
+			var payload Payload
+			err = decoder.Decode(&payload, &b, contentType)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload).Should(Equal(Payload{Name: "goa", Count: 42}))
+		})
+	}
+
+	It("decodes nested maps using the JSON field names", func() {
+		doc := "name: goa\ncount: 1\nlabels:\n  env: prod\n  limits:\n    cpu: 2\n"
+		var payload Payload
+		err := decoder.Decode(&payload, strings.NewReader(doc), "application/yaml")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(payload.Labels["env"]).Should(Equal("prod"))
+		Ω(payload.Labels["limits"]).Should(Equal(map[string]interface{}{"cpu": float64(2)}))
+	})
+
+	Context("with a document larger than the maximum size", func() {
+		var maxSize int64
+
+		BeforeEach(func() {
+			maxSize = yaml.MaxSize
+			yaml.MaxSize = 10
+		})
+
+		AfterEach(func() {
+			yaml.MaxSize = maxSize
+		})
+
+		It("fails", func() {
+			var payload Payload
+			err := decoder.Decode(&payload, strings.NewReader("name: a long name\n"), "application/yaml")
+			Ω(err).Should(MatchError(ContainSubstring("maximum size")))
+		})
+	})
+})
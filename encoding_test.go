@@ -0,0 +1,238 @@
+package goa_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HTTPEncoder", func() {
+	var encoder *goa.HTTPEncoder
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "application/json")
+		encoder.Register(goa.NewXMLEncoder, "application/xml")
+	})
+
+	Describe("Negotiate", func() {
+		cases := []struct{ desc, accept, expected string }{
+			{"exact match", "application/xml", "application/xml"},
+			{"quality values", "application/xml;q=0.9, application/json;q=0.8", "application/xml"},
+			{"quality values reversed", "application/xml;q=0.1, application/json", "application/json"},
+			{"type wildcard", "text/html, application/*;q=0.5", "application/json"},
+			{"more specific range wins", "application/*;q=0.5, application/xml", "application/xml"},
+			{"excluded type", "*/*, application/json;q=0", "application/xml"},
+			{"media type parameters", "application/json; charset=utf-8", "application/json"},
+			{"header order on ties", "application/xml, application/json", "application/xml"},
+			{"no match", "text/html", ""},
+		}
+		for _, c := range cases {
+			c := c
+			It("selects the best content type given "+c.desc, func() {
+				Ω(encoder.Negotiate(c.accept)).Should(Equal(c.expected))
+			})
+		}
+
+		Context("with a default encoder", func() {
+			BeforeEach(func() {
+				encoder.Register(goa.NewJSONEncoder, "*/*")
+			})
+
+			It("uses the default encoder for empty Accept headers", func() {
+				Ω(encoder.Negotiate("")).Should(Equal("*/*"))
+			})
+
+			It("uses the default encoder for */*", func() {
+				Ω(encoder.Negotiate("*/*")).Should(Equal("*/*"))
+			})
+
+			It("prefers explicitly accepted content types", func() {
+				Ω(encoder.Negotiate("*/*;q=0.1, application/xml")).Should(Equal("application/xml"))
+			})
+		})
+	})
+
+	Describe("Encode", func() {
+		var b *bytes.Buffer
+
+		BeforeEach(func() {
+			b = new(bytes.Buffer)
+		})
+
+		It("encodes using the negotiated encoder", func() {
+			err := encoder.Encode(map[string]int{"a": 1}, b, "application/xml;q=0.1, application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b.String()).Should(Equal(`{"a":1}` + "\n"))
+		})
+
+		It("returns a not acceptable error when nothing matches", func() {
+			err := encoder.Encode(map[string]int{"a": 1}, b, "text/html")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+		})
+
+		Context("with a default encoder", func() {
+			BeforeEach(func() {
+				encoder.Register(goa.NewJSONEncoder, "*/*")
+			})
+
+			It("falls back to the default encoder", func() {
+				err := encoder.Encode(map[string]int{"a": 1}, b, "text/html")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(b.String()).Should(Equal(`{"a":1}` + "\n"))
+			})
+
+			Context("and strict negotiation", func() {
+				BeforeEach(func() {
+					encoder.Strict = true
+				})
+
+				It("returns a not acceptable error when nothing matches", func() {
+					err := encoder.Encode(map[string]int{"a": 1}, b, "text/html")
+					Ω(err).Should(HaveOccurred())
+					Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(406))
+				})
+
+				It("uses the default encoder when any media type is accepted", func() {
+					err := encoder.Encode(map[string]int{"a": 1}, b, "text/html, */*;q=0.1")
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(b.String()).Should(Equal(`{"a":1}` + "\n"))
+				})
+			})
+
+			Context("and a default content type", func() {
+				BeforeEach(func() {
+					encoder.DefaultContentType = "application/xml"
+				})
+
+				It("uses the default content type for empty Accept headers", func() {
+					err := encoder.Encode("a", b, "")
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(b.String()).Should(Equal(`<string>a</string>`))
+				})
+			})
+		})
+	})
+})
+
+var _ = Describe("RegisterEncoder", func() {
+	const contentType = "application/x-goa-registry-test"
+
+	type Payload struct {
+		A int
+	}
+
+	BeforeEach(func() {
+		goa.RegisterEncoder(goa.NewXMLEncoder, contentType)
+		goa.RegisterDecoder(goa.NewXMLDecoder, contentType)
+	})
+
+	It("makes the encoder available to all HTTP encoders", func() {
+		encoder := goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, "*/*")
+		Ω(encoder.Negotiate(contentType)).Should(Equal(contentType))
+
+		var b bytes.Buffer
+		err := encoder.Encode(&Payload{A: 1}, &b, contentType)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal("<Payload><A>1</A></Payload>"))
+	})
+
+	It("does not override encoders registered directly", func() {
+		encoder := goa.NewHTTPEncoder()
+		encoder.Register(goa.NewJSONEncoder, contentType)
+
+		var b bytes.Buffer
+		err := encoder.Encode(&Payload{A: 1}, &b, contentType)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal(`{"A":1}` + "\n"))
+	})
+
+	It("makes the decoder available to all HTTP decoders", func() {
+		decoder := goa.NewHTTPDecoder()
+		decoder.Register(goa.NewJSONDecoder, "*/*")
+
+		var v Payload
+		err := decoder.Decode(&v, bytes.NewBufferString("<Payload><A>1</A></Payload>"), contentType+"; charset=utf-8")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.A).Should(Equal(1))
+	})
+})
+
+var _ = Describe("RegisterCodec", func() {
+	const contentType = "application/x-goa-codec-test"
+
+	type Payload struct {
+		A int
+	}
+
+	BeforeEach(func() {
+		goa.RegisterCodec(xmlCodec{contentType})
+	})
+
+	It("makes the encoder and decoder available to all HTTP encoders and decoders", func() {
+		encoder := goa.NewHTTPEncoder()
+		var b bytes.Buffer
+		err := encoder.Encode(&Payload{A: 1}, &b, contentType)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b.String()).Should(Equal("<Payload><A>1</A></Payload>"))
+
+		decoder := goa.NewHTTPDecoder()
+		var v Payload
+		err = decoder.Decode(&v, &b, contentType)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(v.A).Should(Equal(1))
+	})
+})
+
+// xmlCodec is a goa.Codec that encodes XML for the given content type.
+type xmlCodec struct {
+	contentType string
+}
+
+func (c xmlCodec) ContentTypes() []string           { return []string{c.contentType} }
+func (xmlCodec) NewEncoder(w io.Writer) goa.Encoder { return goa.NewXMLEncoder(w) }
+func (xmlCodec) NewDecoder(r io.Reader) goa.Decoder { return goa.NewXMLDecoder(r) }
+
+var _ = Describe("RequestedView", func() {
+	var req *http.Request
+
+	BeforeEach(func() {
+		req, _ = http.NewRequest("GET", "/bottles", nil)
+	})
+
+	It("returns the empty string when no view is requested", func() {
+		Ω(goa.RequestedView(req)).Should(BeEmpty())
+	})
+
+	It("reads the view from the Accept header", func() {
+		req.Header.Set("Accept", "application/json;q=0.5;view=full, application/vnd.bottle+json; view=tiny")
+		Ω(goa.RequestedView(req)).Should(Equal("tiny"))
+	})
+
+	It("gives precedence to the view query string parameter", func() {
+		req.URL.RawQuery = "view=full"
+		req.Header.Set("Accept", "application/vnd.bottle+json; view=tiny")
+		Ω(goa.RequestedView(req)).Should(Equal("full"))
+	})
+})
+
+var _ = Describe("Project", func() {
+	It("copies the attributes rendered by both views", func() {
+		type full struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		type tiny struct {
+			ID int `json:"id"`
+		}
+		var t *tiny
+		Ω(goa.Project(&full{ID: 1, Name: "n"}, &t)).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(&tiny{ID: 1}))
+	})
+})
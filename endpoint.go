@@ -0,0 +1,42 @@
+package goa
+
+import "context"
+
+type (
+	// Endpoint runs the business logic of an action independently of the transport. req is the
+	// request decoded by the generated code, for example *app.ShowBottleRequest, and the returned
+	// value is the result encoded in the response, for example *app.GoaExampleBottle. When the
+	// generated handlers call a controller directly req is the action context, for example
+	// *app.ShowBottleContext, and the result is nil as the action writes its own response.
+	Endpoint func(ctx context.Context, req interface{}) (interface{}, error)
+
+	// EndpointMiddleware wraps an endpoint to implement cross-cutting concerns such as
+	// authorization, auditing or retries independently of the HTTP layer. Endpoint middleware
+	// runs after the request has been decoded and validated, after the HTTP middleware. Use
+	// ContextController and ContextAction to retrieve the name of the controller and action.
+	EndpointMiddleware func(Endpoint) Endpoint
+)
+
+// UseEndpoint adds an endpoint middleware to the service wide endpoint middleware chain. The
+// service endpoint middleware runs before the controller endpoint middleware.
+func (service *Service) UseEndpoint(m EndpointMiddleware) {
+	service.endpointMiddleware = append(service.endpointMiddleware, m)
+}
+
+// UseEndpoint adds an endpoint middleware to the controller.
+// Service-wide endpoint middleware should be added via the Service UseEndpoint method instead.
+func (ctrl *Controller) UseEndpoint(m EndpointMiddleware) {
+	ctrl.endpointMiddleware = append(ctrl.endpointMiddleware, m)
+}
+
+// ServeEndpoint wraps e with the endpoint middleware of the service and controller handling the
+// request and calls it with req. It returns the endpoint result.
+// This function is intended for the controller generated code. User code should not need to call
+// it directly.
+func ServeEndpoint(ctx context.Context, req interface{}, e Endpoint) (interface{}, error) {
+	chain, _ := ctx.Value(endpointMiddlewareKey).([]EndpointMiddleware)
+	for i := len(chain) - 1; i >= 0; i-- {
+		e = chain[i](e)
+	}
+	return e(ctx, req)
+}
@@ -0,0 +1,71 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ServeEndpoint", func() {
+	type ctxKey string
+
+	var (
+		service *goa.Service
+		ctrl    *goa.Controller
+		calls   []string
+		rw      *httptest.ResponseRecorder
+	)
+
+	trace := func(name string) goa.EndpointMiddleware {
+		return func(e goa.Endpoint) goa.Endpoint {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, name+" "+goa.ContextController(ctx)+"."+goa.ContextAction(ctx)+" "+req.(string))
+				return e(context.WithValue(ctx, ctxKey(name), true), req)
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.WithLogger(nil)
+		ctrl = service.NewController("bottle")
+		calls = nil
+		rw = httptest.NewRecorder()
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			res, err := goa.ServeEndpoint(ctx, "request", func(ctx context.Context, req interface{}) (interface{}, error) {
+				calls = append(calls, "endpoint")
+				Ω(ctx.Value(ctxKey("service"))).ShouldNot(BeNil())
+				Ω(ctx.Value(ctxKey("controller"))).ShouldNot(BeNil())
+				return "result", nil
+			})
+			calls = append(calls, res.(string))
+			return err
+		}
+		service.Mux.Handle("GET", "/", ctrl.MuxHandler("show", handler, nil))
+	})
+
+	It("runs the service then the controller endpoint middleware", func() {
+		ctrl.UseEndpoint(trace("controller"))
+		service.UseEndpoint(trace("service"))
+
+		service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+
+		Ω(calls).Should(Equal([]string{
+			"service bottle.show request",
+			"controller bottle.show request",
+			"endpoint",
+			"result",
+		}))
+	})
+
+	It("calls the endpoint directly without endpoint middleware", func() {
+		_, err := goa.ServeEndpoint(context.Background(), nil, func(context.Context, interface{}) (interface{}, error) {
+			return nil, goa.ErrNotFound("not found")
+		})
+		Ω(err).Should(HaveOccurred())
+	})
+})
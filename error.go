@@ -34,6 +34,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 )
 
@@ -41,6 +42,10 @@ var (
 	// ErrorMediaIdentifier is the media type identifier used for error responses.
 	ErrorMediaIdentifier = "application/vnd.goa.error"
 
+	// ProblemMediaIdentifier is the media type identifier used for RFC 7807 problem details
+	// error responses.
+	ProblemMediaIdentifier = "application/problem+json"
+
 	// ErrBadRequest is a generic bad request error.
 	ErrBadRequest = NewErrorClass("bad_request", 400)
 
@@ -73,6 +78,27 @@ var (
 	// handler but not the HTTP method.
 	ErrMethodNotAllowed = NewErrorClass("method_not_allowed", 405)
 
+	// ErrNotAcceptable is the error returned when none of the media types listed in the
+	// request Accept header can be produced and there is no default encoder.
+	ErrNotAcceptable = NewErrorClass("not_acceptable", 406)
+
+	// ErrConflict is the error returned when a request conflicts with another request being
+	// handled, for example a request reusing the idempotency key of a request in progress,
+	// see the Idempotency middleware.
+	ErrConflict = NewErrorClass("conflict", 409)
+
+	// ErrPreconditionFailed is the error returned when the request If-Match header does not
+	// match the current entity tag of the resource.
+	ErrPreconditionFailed = NewErrorClass("precondition_failed", 412)
+
+	// ErrTooManyRequests is the error returned when a request exceeds the rate limit of the
+	// action, see the RateLimit middleware.
+	ErrTooManyRequests = NewErrorClass("too_many_requests", 429)
+
+	// ErrGatewayTimeout is the error returned when an action does not respond before its
+	// timeout expires, see the Timeout middleware.
+	ErrGatewayTimeout = NewErrorClass("gateway_timeout", 504)
+
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
 )
@@ -123,8 +149,59 @@ type (
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
 	}
+
+	// TypedError is a ServiceError whose response body is a value of a type defined in the
+	// design with the Error DSL. The error handler middleware uses Body to build the response.
+	TypedError struct {
+		// ID is the unique error instance identifier.
+		ID string
+		// Name is the name of the error in the design.
+		Name string
+		// Status is the HTTP status code used by responses that cary the error.
+		Status int
+		// Body is the response body.
+		Body interface{}
+		// ContentType is the response Content-Type header value, if any.
+		ContentType string
+	}
+
+	// Violation describes a single validation failure. The errors produced by the generated
+	// validation code list their violations in the "violations" meta key, merging errors
+	// concatenates the lists so that clients get all the failures at once.
+	Violation struct {
+		// Field is the path to the invalid parameter, header or attribute, e.g. "payload.name".
+		Field string `json:"field" xml:"field" form:"field"`
+		// Constraint is the name of the validation that failed, one of "required", "type",
+		// "enum", "format", "pattern", "minimum", "maximum", "min_length" or "max_length".
+		Constraint string `json:"constraint" xml:"constraint" form:"constraint"`
+	}
+
+	// ProblemDetails is the RFC 7807 representation of an error response. It implements
+	// ServiceError. The goa specific ID, code and meta fields are encoded as extension members.
+	// See https://tools.ietf.org/html/rfc7807
+	ProblemDetails struct {
+		// Type is a URI reference that identifies the problem type.
+		Type string `json:"type" xml:"type" form:"type"`
+		// Title is a short, human-readable summary of the problem type.
+		Title string `json:"title" xml:"title" form:"title"`
+		// Status is the HTTP status code used by responses that cary the error.
+		Status int `json:"status" xml:"status" form:"status"`
+		// Detail describes the specific error occurrence.
+		Detail string `json:"detail,omitempty" xml:"detail,omitempty" form:"detail,omitempty"`
+		// Instance is a URI reference that identifies the specific occurrence of the problem.
+		Instance string `json:"instance,omitempty" xml:"instance,omitempty" form:"instance,omitempty"`
+		// ID is the unique error instance identifier.
+		ID string `json:"id,omitempty" xml:"id,omitempty" form:"id,omitempty"`
+		// Code identifies the class of errors.
+		Code string `json:"code,omitempty" xml:"code,omitempty" form:"code,omitempty"`
+		// Meta contains additional key/value pairs useful to clients.
+		Meta map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+	}
 )
 
+// ViolationsKey is the error meta key that holds the list of validation violations.
+const ViolationsKey = "violations"
+
 // NewErrorClass creates a new error class.
 // It is the responsibility of the client to guarantee uniqueness of code.
 func NewErrorClass(code string, status int) ErrorClass {
@@ -166,33 +243,42 @@ func MissingPayloadError() error {
 // defined in the design.
 func InvalidParamTypeError(name string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s", val, name, expected)
-	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected,
+		ViolationsKey, violations(name, "type"))
 }
 
 // MissingParamError is the error produced for requests that are missing path or querystring
 // parameters.
 func MissingParamError(name string) error {
 	msg := fmt.Sprintf("missing required parameter %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return ErrInvalidRequest(msg, "name", name, ViolationsKey, violations(name, "required"))
 }
 
 // InvalidAttributeTypeError is the error produced when the type of payload field does not match
 // the type defined in the design.
 func InvalidAttributeTypeError(ctx string, val interface{}, expected string) error {
 	msg := fmt.Sprintf("type of %s must be %s but got value %#v", ctx, expected, val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected,
+		ViolationsKey, violations(ctx, "type"))
 }
 
 // MissingAttributeError is the error produced when a request payload is missing a required field.
 func MissingAttributeError(ctx, name string) error {
 	msg := fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx)
-	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx)
+	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx,
+		ViolationsKey, violations(ctx+"."+name, "required"))
 }
 
 // MissingHeaderError is the error produced when a request is missing a required header.
 func MissingHeaderError(name string) error {
 	msg := fmt.Sprintf("missing required HTTP header %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	return ErrInvalidRequest(msg, "name", name, ViolationsKey, violations(name, "required"))
+}
+
+// MissingCookieError is the error produced when a request is missing a required cookie.
+func MissingCookieError(name string) error {
+	msg := fmt.Sprintf("missing required cookie %#v", name)
+	return ErrInvalidRequest(msg, "name", name, ViolationsKey, violations(name, "required"))
 }
 
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
@@ -203,43 +289,48 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) e
 		elems[i] = fmt.Sprintf("%#v", a)
 	}
 	msg := fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx, strings.Join(elems, ", "), val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "),
+		ViolationsKey, violations(ctx, "enum"))
 }
 
 // InvalidFormatError is the error produced when the value of a parameter or payload field does not
 // match the format validation defined in the design.
 func InvalidFormatError(ctx, target string, format Format, formatError error) error {
 	msg := fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error())
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error(),
+		ViolationsKey, violations(ctx, "format"))
 }
 
 // InvalidPatternError is the error produced when the value of a parameter or payload field does
 // not match the pattern validation defined in the design.
 func InvalidPatternError(ctx, target string, pattern string) error {
 	msg := fmt.Sprintf("%s must match the regexp %#v but got value %#v", ctx, pattern, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern,
+		ViolationsKey, violations(ctx, "pattern"))
 }
 
 // InvalidRangeError is the error produced when the value of a parameter or payload field does
 // not match the range validation defined in the design. value may be a int or a float64.
 func InvalidRangeError(ctx string, target interface{}, value interface{}, min bool) error {
-	comp := "greater than or equal to"
+	comp, constraint := "greater than or equal to", "minimum"
 	if !min {
-		comp = "less than or equal to"
+		comp, constraint = "less than or equal to", "maximum"
 	}
 	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value,
+		ViolationsKey, violations(ctx, constraint))
 }
 
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
 // not match the length validation defined in the design.
 func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool) error {
-	comp := "greater than or equal to"
+	comp, constraint := "greater than or equal to", "min_length"
 	if !min {
-		comp = "less than or equal to"
+		comp, constraint = "less than or equal to", "max_length"
 	}
 	msg := fmt.Sprintf("length of %s must be %s %d but got value %#v (len=%d)", ctx, comp, value, target, ln)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value,
+		ViolationsKey, violations(ctx, constraint))
 }
 
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
@@ -260,6 +351,17 @@ func MethodNotAllowedError(method string, allowed []string) error {
 	return ErrMethodNotAllowed(msg, "method", method, "allowed", strings.Join(allowed, ", "))
 }
 
+// Violations returns the list of validation violations recorded in err, nil if err is not an
+// error produced by the validation code.
+func Violations(err error) []Violation {
+	e, ok := err.(*ErrorResponse)
+	if !ok {
+		return nil
+	}
+	v, _ := e.Meta[ViolationsKey].([]Violation)
+	return v
+}
+
 // Error returns the error occurrence details.
 func (e *ErrorResponse) Error() string {
 	msg := fmt.Sprintf("[%s] %d %s: %s", e.ID, e.Status, e.Code, e.Detail)
@@ -275,6 +377,60 @@ func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 // Token is the unique error occurrence identifier.
 func (e *ErrorResponse) Token() string { return e.ID }
 
+// NewTypedError creates an error whose response uses the given status and body. contentType is
+// the value of the response Content-Type header, the encoder negotiated with the client is used
+// if empty. The code generated for errors defined in the design with the Error DSL calls
+// NewTypedError.
+func NewTypedError(name string, status int, body interface{}, contentType string) error {
+	return &TypedError{ID: newErrorID(), Name: name, Status: status, Body: body, ContentType: contentType}
+}
+
+// Error returns the error occurrence details.
+func (e *TypedError) Error() string {
+	return fmt.Sprintf("[%s] %d %s: %+v", e.ID, e.Status, e.Name, e.Body)
+}
+
+// ResponseStatus is the status used to build responses.
+func (e *TypedError) ResponseStatus() int { return e.Status }
+
+// Token is the unique error occurrence identifier.
+func (e *TypedError) Token() string { return e.ID }
+
+// NewProblemDetails builds the RFC 7807 representation of err. The status, ID, code and meta
+// fields are initialized from err when it is a ServiceError created via an error class, other
+// errors produce internal errors. instance identifies the occurrence of the problem, typically
+// the request URI. The problem type is "about:blank" and the title the HTTP status text.
+func NewProblemDetails(err error, instance string) *ProblemDetails {
+	p := &ProblemDetails{
+		Type:     "about:blank",
+		Status:   http.StatusInternalServerError,
+		Detail:   err.Error(),
+		Instance: instance,
+	}
+	if serr, ok := err.(ServiceError); ok {
+		p.Status = serr.ResponseStatus()
+		p.ID = serr.Token()
+	}
+	if resp, ok := err.(*ErrorResponse); ok {
+		p.Code = resp.Code
+		p.Detail = resp.Detail
+		p.Meta = resp.Meta
+	}
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// Error returns the problem details.
+func (p *ProblemDetails) Error() string {
+	return fmt.Sprintf("[%s] %d %s: %s", p.ID, p.Status, p.Title, p.Detail)
+}
+
+// ResponseStatus is the status used to build responses.
+func (p *ProblemDetails) ResponseStatus() int { return p.Status }
+
+// Token is the unique error occurrence identifier.
+func (p *ProblemDetails) Token() string { return p.ID }
+
 // MergeErrors updates an error by merging another into it. It first converts other into a
 // ServiceError if not already one - producing an internal error in that case. The merge algorithm
 // is:
@@ -287,7 +443,9 @@ func (e *ErrorResponse) Token() string { return e.ID }
 //
 // The Detail field is updated by concatenating the Detail fields of e and other separated
 // by a semi-colon. The MetaValues field of is updated by merging the map of other MetaValues
-// into e's where values in e with identical keys to values in other get overwritten.
+// into e's where values in e with identical keys to values in other get overwritten. The
+// validation violations listed under ViolationsKey are the exception: the lists of e and other
+// are concatenated.
 //
 // Merge returns the updated error. This is useful in case the error was initially nil in
 // which case other is returned.
@@ -327,12 +485,22 @@ func MergeErrors(err, other error) error {
 	if e.Meta == nil && len(o.Meta) > 0 {
 		e.Meta = make(map[string]interface{})
 	}
+	violations := append(append([]Violation{}, Violations(e)...), Violations(o)...)
 	for k, v := range o.Meta {
 		e.Meta[k] = v
 	}
+	if len(violations) > 0 {
+		e.Meta[ViolationsKey] = violations
+	}
 	return e
 }
 
+// violations returns the ViolationsKey meta value of the error produced when the value of field
+// does not satisfy constraint.
+func violations(field, constraint string) []Violation {
+	return []Violation{{Field: field, Constraint: constraint}}
+}
+
 func asServiceError(err error) ServiceError {
 	e, ok := err.(ServiceError)
 	if !ok {
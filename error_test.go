@@ -104,6 +104,10 @@ var _ = Describe("MissingAttributeError", func() {
 		Ω(err.Detail).Should(ContainSubstring(ctx))
 		Ω(err.Detail).Should(ContainSubstring(name))
 	})
+
+	It("records the violation", func() {
+		Ω(Violations(valErr)).Should(Equal([]Violation{{Field: "ctx.param", Constraint: "required"}}))
+	})
 })
 
 var _ = Describe("MissingHeaderError", func() {
@@ -554,6 +558,24 @@ var _ = Describe("Merge", func() {
 						Ω(mErr.(*ErrorResponse).Meta[commonKey]).Should(Equal(metaValues2[commonKey]))
 					})
 				})
+
+				Context("with validation violations", func() {
+					BeforeEach(func() {
+						err.(*ErrorResponse).Meta = map[string]interface{}{
+							ViolationsKey: []Violation{{Field: "payload.name", Constraint: "required"}},
+						}
+						mErr2.Meta = map[string]interface{}{
+							ViolationsKey: []Violation{{Field: "payload.count", Constraint: "minimum"}},
+						}
+					})
+
+					It("concatenates the violations", func() {
+						Ω(Violations(mErr)).Should(Equal([]Violation{
+							{Field: "payload.name", Constraint: "required"},
+							{Field: "payload.count", Constraint: "minimum"},
+						}))
+					})
+				})
 			})
 		})
 
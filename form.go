@@ -0,0 +1,377 @@
+package goa
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// formDecoder decodes application/x-www-form-urlencoded bodies.
+	formDecoder struct {
+		r io.Reader
+	}
+
+	// formEncoder encodes values using the application/x-www-form-urlencoded format.
+	formEncoder struct {
+		w io.Writer
+	}
+
+	// formNode is a node of the tree built from the form field names. Leaf nodes hold the
+	// field values, inner nodes hold the children indexed by key.
+	formNode struct {
+		values   []string
+		children map[string]*formNode
+	}
+)
+
+// NewFormDecoder returns a decoder that reads application/x-www-form-urlencoded bodies from r.
+// Form fields are mapped to struct fields using the "form" struct tag, falling back to the
+// "json" tag and the field name. Arrays and nested objects use the bracket notation:
+//
+//	name=goa&tags=a&tags=b&tags[]=c&address[city]=Paris&items[0][id]=1&items[1][id]=2
+//
+// String values are coerced into the target field type, fields whose type implement
+// encoding.TextUnmarshaler (time.Time, uuid.UUID etc.) are decoded using UnmarshalText.
+func NewFormDecoder(r io.Reader) Decoder { return &formDecoder{r: r} }
+
+// NewFormEncoder returns an encoder that writes values to w using the
+// application/x-www-form-urlencoded format and the notation accepted by NewFormDecoder.
+func NewFormEncoder(w io.Writer) Encoder { return &formEncoder{w: w} }
+
+// Decode reads the form from the underlying reader and stores the result in v.
+func (d *formDecoder) Decode(v interface{}) error {
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	vals, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+	root := &formNode{}
+	for key, vs := range vals {
+		path, err := parseFormKey(key)
+		if err != nil {
+			return err
+		}
+		root.insert(path, vs)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("form decoder: cannot decode into non-pointer %T", v)
+	}
+	return root.decode(rv.Elem(), "")
+}
+
+// Encode writes the form encoding of v to the underlying writer.
+func (e *formEncoder) Encode(v interface{}) error {
+	vals := make(url.Values)
+	if err := encodeForm(vals, "", reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, vals.Encode())
+	return err
+}
+
+// parseFormKey splits a form key using the bracket notation into its path elements, for
+// example "items[0][id]" produces ["items", "0", "id"].
+func parseFormKey(key string) ([]string, error) {
+	i := strings.IndexByte(key, '[')
+	if i == -1 {
+		return []string{key}, nil
+	}
+	path := []string{key[:i]}
+	rest := key[i:]
+	for len(rest) > 0 {
+		if rest[0] != '[' {
+			return nil, fmt.Errorf("form decoder: invalid field name %q", key)
+		}
+		j := strings.IndexByte(rest, ']')
+		if j == -1 {
+			return nil, fmt.Errorf("form decoder: invalid field name %q", key)
+		}
+		path = append(path, rest[1:j])
+		rest = rest[j+1:]
+	}
+	for _, p := range path[:len(path)-1] {
+		if p == "" {
+			return nil, fmt.Errorf("form decoder: empty brackets may only appear last in %q", key)
+		}
+	}
+	return path, nil
+}
+
+// insert adds the values at the given path, empty brackets append to the values of the parent.
+func (n *formNode) insert(path []string, vals []string) {
+	if len(path) == 0 || path[0] == "" {
+		n.values = append(n.values, vals...)
+		return
+	}
+	if n.children == nil {
+		n.children = make(map[string]*formNode)
+	}
+	child, ok := n.children[path[0]]
+	if !ok {
+		child = &formNode{}
+		n.children[path[0]] = child
+	}
+	child.insert(path[1:], vals)
+}
+
+// decode stores the content of the node in v.
+func (n *formNode) decode(v reflect.Value, name string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return n.decode(v.Elem(), name)
+	}
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if len(n.values) == 0 {
+				return nil
+			}
+			if err := u.UnmarshalText([]byte(n.values[len(n.values)-1])); err != nil {
+				return fmt.Errorf("form decoder: invalid value for %s: %s", name, err)
+			}
+			return nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			fname := formFieldName(f)
+			if fname == "-" {
+				continue
+			}
+			child, ok := n.children[fname]
+			if !ok {
+				continue
+			}
+			if err := child.decode(v.Field(i), joinFormName(name, fname)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("form decoder: unsupported map key type for %s", name)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, k := range n.keys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := n.children[k].decode(elem, joinFormName(name, k)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), elem)
+		}
+	case reflect.Slice:
+		var elems []*formNode
+		for _, val := range n.values {
+			elems = append(elems, &formNode{values: []string{val}})
+		}
+		keys := make([]string, 0, len(n.children))
+		idx := make(map[string]int, len(n.children))
+		for k := range n.children {
+			// Only accept canonical indices so that "01" or "+1" cannot duplicate "1".
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || strconv.Itoa(i) != k {
+				return fmt.Errorf("form decoder: invalid index %q for %s", k, name)
+			}
+			idx[k] = i
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return idx[keys[i]] < idx[keys[j]] })
+		for _, k := range keys {
+			elems = append(elems, n.children[k])
+		}
+		s := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+		for i, e := range elems {
+			if err := e.decode(s.Index(i), fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("form decoder: unsupported type %s for %s", v.Type(), name)
+		}
+		v.Set(reflect.ValueOf(n.raw()))
+	default:
+		if len(n.values) == 0 {
+			return nil
+		}
+		return setFormValue(v, n.values[len(n.values)-1], name)
+	}
+	return nil
+}
+
+// raw returns the content of the node as generic values.
+func (n *formNode) raw() interface{} {
+	if len(n.children) > 0 {
+		m := make(map[string]interface{}, len(n.children))
+		for k, c := range n.children {
+			m[k] = c.raw()
+		}
+		return m
+	}
+	if len(n.values) == 1 {
+		return n.values[0]
+	}
+	vals := make([]interface{}, len(n.values))
+	for i, val := range n.values {
+		vals[i] = val
+	}
+	return vals
+}
+
+// keys returns the sorted keys of the node children.
+func (n *formNode) keys() []string {
+	keys := make([]string, 0, len(n.children))
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setFormValue coerces s into the primitive value v.
+func setFormValue(v reflect.Value, s, name string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("form decoder: invalid boolean value %q for %s", s, name)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("form decoder: invalid integer value %q for %s", s, name)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("form decoder: invalid integer value %q for %s", s, name)
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("form decoder: invalid number value %q for %s", s, name)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("form decoder: unsupported type %s for %s", v.Type(), name)
+	}
+	return nil
+}
+
+// encodeForm adds the form encoding of v to vals using name as prefix.
+func encodeForm(vals url.Values, name string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return err
+		}
+		vals.Add(name, string(b))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			fname := formFieldName(f)
+			if fname == "-" {
+				continue
+			}
+			if err := encodeForm(vals, joinFormName(name, fname), v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sorted := make([]string, len(keys))
+		for i, k := range keys {
+			sorted[i] = fmt.Sprint(k.Interface())
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			key := reflect.ValueOf(k).Convert(v.Type().Key())
+			if err := encodeForm(vals, joinFormName(name, k), v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+				if elem.IsNil() {
+					break
+				}
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct || elem.Kind() == reflect.Map || elem.Kind() == reflect.Slice {
+				if err := encodeForm(vals, fmt.Sprintf("%s[%d]", name, i), elem); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := encodeForm(vals, name, elem); err != nil {
+				return err
+			}
+		}
+	default:
+		if name == "" {
+			return fmt.Errorf("form encoder: cannot encode %s at top level", v.Type())
+		}
+		vals.Add(name, fmt.Sprint(v.Interface()))
+	}
+	return nil
+}
+
+// formFieldName returns the form field name of the given struct field.
+func formFieldName(f reflect.StructField) string {
+	for _, tag := range []string{"form", "json"} {
+		if t := f.Tag.Get(tag); t != "" {
+			if n := strings.Split(t, ",")[0]; n != "" {
+				return n
+			}
+		}
+	}
+	return f.Name
+}
+
+// joinFormName appends key to the form field name prefix using the bracket notation.
+func joinFormName(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "[" + key + "]"
+}
@@ -0,0 +1,144 @@
+package goa_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type (
+	formAddress struct {
+		City *string `form:"city,omitempty" json:"city,omitempty"`
+	}
+
+	formItem struct {
+		ID int `form:"id" json:"id"`
+	}
+
+	formPayload struct {
+		Name     *string            `form:"name,omitempty" json:"name,omitempty"`
+		Count    *int               `form:"count,omitempty" json:"count,omitempty"`
+		Tags     []string           `form:"tags,omitempty" json:"tags,omitempty"`
+		Address  *formAddress       `form:"address,omitempty" json:"address,omitempty"`
+		Items    []*formItem        `form:"items,omitempty" json:"items,omitempty"`
+		Created  *time.Time         `form:"created,omitempty" json:"created,omitempty"`
+		Meta     map[string]string  `form:"meta,omitempty" json:"meta,omitempty"`
+		Settings map[string]*string `json:"settings,omitempty"`
+	}
+)
+
+var _ = Describe("Form encoding", func() {
+	var body string
+	var payload *formPayload
+	var decodeErr error
+
+	JustBeforeEach(func() {
+		payload = new(formPayload)
+		decodeErr = goa.NewFormDecoder(strings.NewReader(body)).Decode(payload)
+	})
+
+	Context("with simple fields", func() {
+		BeforeEach(func() {
+			body = "name=goa&count=42&created=2016-01-02T15:04:05Z&settings[a]=b"
+		})
+
+		It("decodes and coerces the values", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(*payload.Name).Should(Equal("goa"))
+			Ω(*payload.Count).Should(Equal(42))
+			Ω(payload.Created.Equal(time.Date(2016, 1, 2, 15, 4, 5, 0, time.UTC))).Should(BeTrue())
+			Ω(*payload.Settings["a"]).Should(Equal("b"))
+		})
+	})
+
+	Context("with arrays and nested objects", func() {
+		BeforeEach(func() {
+			body = "tags=a&tags=b&tags[]=c&address[city]=Paris&items[1][id]=2&items[0][id]=1&meta[k]=v"
+		})
+
+		It("uses the bracket notation", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			Ω(payload.Tags).Should(ConsistOf("a", "b", "c"))
+			Ω(*payload.Address.City).Should(Equal("Paris"))
+			Ω(payload.Items).Should(HaveLen(2))
+			Ω(payload.Items[0].ID).Should(Equal(1))
+			Ω(payload.Items[1].ID).Should(Equal(2))
+			Ω(payload.Meta).Should(Equal(map[string]string{"k": "v"}))
+		})
+	})
+
+	Context("with an invalid value", func() {
+		BeforeEach(func() {
+			body = "count=foo"
+		})
+
+		It("returns an error", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an invalid field name", func() {
+		BeforeEach(func() {
+			body = "items[0=1"
+		})
+
+		It("returns an error", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a non canonical index", func() {
+		BeforeEach(func() {
+			body = "items[01]=x"
+		})
+
+		It("returns an error", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+			Ω(decodeErr.Error()).Should(ContainSubstring(`invalid index "01"`))
+		})
+	})
+
+	Context("with indices aliasing the same element", func() {
+		BeforeEach(func() {
+			body = "items[1][id]=1&items[+1][id]=2"
+		})
+
+		It("returns an error", func() {
+			Ω(decodeErr).Should(HaveOccurred())
+		})
+	})
+
+	Context("encoding", func() {
+		BeforeEach(func() {
+			body = "name=goa&tags=a&tags=b&address[city]=Paris&items[0][id]=1&items[1][id]=2"
+		})
+
+		It("round trips", func() {
+			Ω(decodeErr).ShouldNot(HaveOccurred())
+			var buf bytes.Buffer
+			Ω(goa.NewFormEncoder(&buf).Encode(payload)).ShouldNot(HaveOccurred())
+			decoded := new(formPayload)
+			Ω(goa.NewFormDecoder(&buf).Decode(decoded)).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(payload))
+		})
+	})
+
+	Context("registered with a HTTP decoder", func() {
+		BeforeEach(func() {
+			body = "name=goa"
+		})
+
+		It("decodes form posts", func() {
+			decoder := goa.NewHTTPDecoder()
+			decoder.Register(goa.NewFormDecoder, "application/x-www-form-urlencoded")
+			var p formPayload
+			err := decoder.Decode(&p, strings.NewReader(body), "application/x-www-form-urlencoded; charset=utf-8")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(*p.Name).Should(Equal("goa"))
+		})
+	})
+})
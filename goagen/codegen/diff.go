@@ -0,0 +1,197 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines printed around the changes by Diff.
+const diffContext = 3
+
+// diffOp is an operation of a line edit script.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Diff returns the unified diff between the existing and generated content of the file with the
+// given name, the empty string if the contents are identical.
+func Diff(name string, existing, generated []byte) string {
+	if bytes.Equal(existing, generated) {
+		return ""
+	}
+	ops := diffLines(splitLines(existing), splitLines(generated))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s (generated)\n", name, name)
+	for start := 0; start < len(ops); {
+		// Find the next change and the end of the hunk containing it.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		end, unchanged := first, 0
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end, unchanged = i+1, 0
+				continue
+			}
+			unchanged++
+			if unchanged > 2*diffContext {
+				break
+			}
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := end + diffContext
+		if to > len(ops) {
+			to = len(ops)
+		}
+		// Compute the hunk line numbers from the operations preceding it.
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		var oldCount, newCount int
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[from:to] {
+			fmt.Fprintf(&buf, "%c%s\n", op.kind, op.line)
+		}
+		start = to
+	}
+	return buf.String()
+}
+
+// DiffDecls compares the functions and methods declared in the existing and generated Go
+// source. It returns the names of the functions that are declared in the generated source only
+// and a description of the functions whose signature differ. Methods are named after their
+// receiver type, e.g. "BottleController.Show".
+func DiffDecls(existing, generated []byte) (added, changed []string, err error) {
+	old, err := funcSignatures(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+	gen, err := funcSignatures(generated)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, sig := range gen {
+		osig, ok := old[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case osig != sig:
+			changed = append(changed, fmt.Sprintf("%s: %s => %s", name, osig, sig))
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	return added, changed, nil
+}
+
+// funcSignatures returns the signatures of the functions and methods declared in the given Go
+// source indexed by name. init functions are ignored as there may be more than one.
+func funcSignatures(src []byte) (map[string]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	sigs := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil && fn.Name.Name == "init" {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			recv := fn.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if id, ok := recv.(*ast.Ident); ok {
+				name = id.Name + "." + name
+			}
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, fn.Type); err != nil {
+			return nil, err
+		}
+		sigs[name] = buf.String()
+	}
+	return sigs, nil
+}
+
+// diffLines computes the edit script transforming a into b using their longest common
+// subsequence.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// splitLines splits the given content into lines.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
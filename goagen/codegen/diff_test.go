@@ -0,0 +1,74 @@
+package codegen_test
+
+import (
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	It("returns the empty string for identical files", func() {
+		Ω(codegen.Diff("a.go", []byte("a\nb\n"), []byte("a\nb\n"))).Should(BeEmpty())
+	})
+
+	It("returns the unified diff", func() {
+		existing := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		generated := "1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+		Ω(codegen.Diff("a.go", []byte(existing), []byte(generated))).Should(Equal(`--- a.go
++++ a.go (generated)
+@@ -1,7 +1,7 @@
+ 1
+ 2
+ 3
+-4
++four
+ 5
+ 6
+ 7
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`))
+	})
+})
+
+var _ = Describe("DiffDecls", func() {
+	const existing = `package main
+
+type C struct{}
+
+func init() {}
+
+func (c *C) Show(id int) error { return nil }
+
+func (c *C) Custom() {}
+
+func helper() {}
+`
+	const generated = `package main
+
+type C struct{}
+
+func init() {}
+
+func (c *C) Show(id string) error { return nil }
+
+func (c *C) Delete(id int) error { return nil }
+
+func helper() {}
+`
+
+	It("returns the new functions and the changed signatures", func() {
+		added, changed, err := codegen.DiffDecls([]byte(existing), []byte(generated))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(added).Should(Equal([]string{"C.Delete"}))
+		Ω(changed).Should(Equal([]string{"C.Show: func(id int) error => func(id string) error"}))
+	})
+
+	It("returns an error for invalid source", func() {
+		_, _, err := codegen.DiffDecls([]byte("package"), []byte(generated))
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -3,5 +3,11 @@ Package codegen contains common code used by all code generators.
 Each sub-package corresponds to a code generator.
 The "meta" sub-package is the generator generator: it contains code that compiles and runs
 a specific generator tool that uses the user metadata.
+
+Plugins registered with RegisterPlugin hook into the generation pipeline: they may inspect the
+evaluated design, contribute generated files and modify the templates used to render the
+generated source files. The import paths of the plugin packages are given to goagen with the
+--plugin flag. The --templates flag overrides the built-in templates with the templates stored in
+a directory, see TemplateDir.
 */
 package codegen
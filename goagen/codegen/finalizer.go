@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 )
@@ -13,12 +14,16 @@ type Finalizer struct {
 	assignmentT      *template.Template
 	arrayAssignmentT *template.Template
 	seen             map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer
+	finalizing       map[string]bool
 }
 
 // NewFinalizer instantiates a finalize code generator.
 func NewFinalizer() *Finalizer {
 	var (
-		f   = &Finalizer{seen: make(map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer)}
+		f = &Finalizer{
+			seen:       make(map[*design.AttributeDefinition]map[*design.AttributeDefinition]*bytes.Buffer),
+			finalizing: make(map[string]bool),
+		}
 		err error
 	)
 	fm := template.FuncMap{
@@ -42,6 +47,10 @@ func NewFinalizer() *Finalizer {
 // Code produces Go code that sets the default values for fields recursively for the given
 // attribute.
 func (f *Finalizer) Code(att *design.AttributeDefinition, target string, depth int) string {
+	if att.Union != nil {
+		// The variant of union values is only known at runtime.
+		return fmt.Sprintf("%s%s.Finalize()", Tabs(depth), target)
+	}
 	buf := f.recurse(att, att, target, depth)
 	return buf.String()
 }
@@ -52,6 +61,18 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		first = true
 	)
 
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok && !ut.IsUnion() {
+		if f.finalizing[ut.TypeName] {
+			// Recursive types rely on the Finalize method of the nested values.
+			if hasDefaultValues(ut) {
+				buf.WriteString(fmt.Sprintf("%s%s.Finalize()", Tabs(depth), target))
+			}
+			return buf
+		}
+		f.finalizing[ut.TypeName] = true
+		defer delete(f.finalizing, ut.TypeName)
+	}
+
 	if s, ok := f.seen[root]; ok {
 		if buf, ok := s[att]; ok {
 			return buf
@@ -61,6 +82,11 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 		f.seen[root] = map[*design.AttributeDefinition]*bytes.Buffer{att: buf}
 	}
 
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok && ut.IsUnion() {
+		// The variant of union values is only known at runtime.
+		buf.WriteString(fmt.Sprintf("%s%s.Finalize()", Tabs(depth), target))
+		return buf
+	}
 	if o := att.Type.ToObject(); o != nil {
 		o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
 			if att.HasDefaultValue(n) {
@@ -70,7 +96,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 					"catt":       catt,
 					"depth":      depth,
 					"isDatetime": catt.Type == design.DateTime,
-					"defaultVal": PrintVal(catt.Type, catt.DefaultValue),
+					"defaultVal": DefaultValue(catt),
 				}
 				if !first {
 					buf.WriteByte('\n')
@@ -107,6 +133,57 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 	return buf
 }
 
+// hasDefaultValues returns true if any attribute of the given user type defines a default value.
+func hasDefaultValues(ut *design.UserTypeDefinition) bool {
+	return defaultValues(ut.AttributeDefinition, map[string]bool{ut.TypeName: true})
+}
+
+// defaultValues returns true if the given attribute or any of its children defines a default
+// value. seen records the user types already traversed to handle recursive types.
+func defaultValues(att *design.AttributeDefinition, seen map[string]bool) bool {
+	if att.DefaultValue != nil {
+		return true
+	}
+	var name string
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		name = actual.TypeName
+	case *design.MediaTypeDefinition:
+		name = actual.TypeName
+	}
+	if name != "" {
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	if o := att.Type.ToObject(); o != nil {
+		for _, cat := range o {
+			if defaultValues(cat, seen) {
+				return true
+			}
+		}
+	}
+	if a := att.Type.ToArray(); a != nil {
+		return defaultValues(a.ElemType, seen)
+	}
+	if h := att.Type.ToHash(); h != nil {
+		return defaultValues(h.KeyType, seen) || defaultValues(h.ElemType, seen)
+	}
+	return false
+}
+
+// DefaultValue returns the Go expression of the default value of the given attribute. The default
+// values of the attributes using the duration format are goa.Duration values.
+func DefaultValue(att *design.AttributeDefinition) string {
+	if att.Scalar() == "duration" {
+		if d, err := time.ParseDuration(att.DefaultValue.(string)); err == nil {
+			return fmt.Sprintf("goa.Duration(%s)", Duration(d))
+		}
+	}
+	return PrintVal(att.Type, att.DefaultValue)
+}
+
 // PrintVal prints the given value corresponding to the given data type.
 // The value is already checked for the compatibility with the data type.
 func PrintVal(t design.DataType, val interface{}) string {
@@ -183,6 +183,26 @@ var _ = Describe("Struct finalize code generation", func() {
 			Ω(code).Should(Equal(recursiveAssignmentCodeB))
 		})
 	})
+
+	Context("given a recursive user type with no default value", func() {
+		BeforeEach(func() {
+			var (
+				rt  = &design.UserTypeDefinition{TypeName: "recursive"}
+				obj = &design.Object{
+					"child": &design.AttributeDefinition{Type: rt},
+					"other": &design.AttributeDefinition{Type: design.String},
+				}
+			)
+			rt.AttributeDefinition = &design.AttributeDefinition{Type: obj}
+
+			att = &design.AttributeDefinition{Type: rt}
+			target = "ut"
+		})
+		It("does not produce finalize code", func() {
+			code := finalizer.Code(att, target, 0)
+			Ω(code).Should(BeEmpty())
+		})
+	})
 })
 
 const (
@@ -214,10 +234,7 @@ if ut.Foo == nil {
 }`
 
 	recursiveAssignmentCodeA = `if ut.Child != nil {
-	var defaultOther = "foo"
-	if ut.Child.Other == nil {
-		ut.Child.Other = &defaultOther
-}
+	ut.Child.Finalize()
 }
 var defaultOther = "foo"
 if ut.Other == nil {
@@ -225,10 +242,7 @@ if ut.Other == nil {
 }`
 
 	recursiveAssignmentCodeB = `	for _, e := range ut.Elems {
-		var defaultOther = "foo"
-		if e.Other == nil {
-			e.Other = &defaultOther
-}
+		e.Finalize()
 	}
 var defaultOther = "foo"
 if ut.Other == nil {
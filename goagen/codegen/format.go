@@ -0,0 +1,46 @@
+package codegen
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallelism is the maximum number of source files formatted and written concurrently by
+// FormatFiles.
+var Parallelism = runtime.NumCPU()
+
+// FormatFiles runs FormatCode on the given source files then closes them, formatting and writing
+// the files concurrently using up to Parallelism goroutines. Only these two steps run
+// concurrently: the generators and the templates that render the files make use of global state
+// (see Tempvar) and must thus be executed sequentially to produce deterministic output. The app
+// generator renders all its files first then formats them all at once with FormatFiles, the
+// client generator does the same for the files it renders once per resource. FormatFiles returns
+// the error of the first file in the list that failed to format.
+func FormatFiles(files []*SourceFile) error {
+	n := Parallelism
+	if n < 1 {
+		n = 1
+	}
+	errs := make([]error, len(files))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, f := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, f *SourceFile) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = f.FormatCode()
+			f.Close()
+		}(i, f)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
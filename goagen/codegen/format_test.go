@@ -0,0 +1,67 @@
+package codegen_test
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FormatFiles", func() {
+	var workspace *codegen.Workspace
+	var files []*codegen.SourceFile
+	var sources []string
+	var formatErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		sources = nil
+		for i := 0; i < 10; i++ {
+			sources = append(sources, fmt.Sprintf("package formattest\nimport \"fmt\"\nimport \"strings\"\nfunc F%d() {   fmt.Println(%d) }\n", i, i))
+		}
+	})
+
+	JustBeforeEach(func() {
+		pkg, err := workspace.NewPackage("formattest")
+		Ω(err).ShouldNot(HaveOccurred())
+		files = nil
+		for i, src := range sources {
+			file, err := pkg.CreateSourceFile(fmt.Sprintf("f%d.go", i))
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = file.Write([]byte(src))
+			Ω(err).ShouldNot(HaveOccurred())
+			files = append(files, file)
+		}
+		formatErr = codegen.FormatFiles(files)
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("formats all the files", func() {
+		Ω(formatErr).ShouldNot(HaveOccurred())
+		for i, file := range files {
+			content, err := ioutil.ReadFile(file.Abs())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal(fmt.Sprintf("package formattest\n\nimport \"fmt\"\n\nfunc F%d() { fmt.Println(%d) }\n", i, i)))
+		}
+	})
+
+	Context("with invalid files", func() {
+		BeforeEach(func() {
+			sources[3] = "package formattest\nfunc F3() {\n"
+			sources[7] = "package formattest\nfunc F7() {\n"
+		})
+
+		It("returns the error of the first invalid file", func() {
+			Ω(formatErr).Should(HaveOccurred())
+			Ω(formatErr.Error()).Should(ContainSubstring("func F3()"))
+			Ω(formatErr.Error()).ShouldNot(ContainSubstring("func F7()"))
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/goadesign/goa/design"
@@ -108,6 +109,30 @@ func Tabs(depth int) string {
 // Add adds two integers and returns the sum of the two.
 func Add(a, b int) int { return a + b }
 
+// Duration returns the Go expression of the given duration, e.g. "2 * time.Second" or
+// "time.Duration(1500)".
+func Duration(d time.Duration) string {
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d != 0 && d%u.d == 0 {
+			if d == u.d {
+				return u.name
+			}
+			return fmt.Sprintf("%d * %s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
 // CanonicalTemplate returns the resource URI template as a format string suitable for use in the
 // fmt.Printf function family.
 func CanonicalTemplate(r *design.ResourceDefinition) string {
@@ -170,3 +195,46 @@ func KebabCase(name string) string {
 	name = SnakeCase(name)
 	return strings.Replace(name, "_", "-", -1)
 }
+
+// AttributeExample returns the example of the given attribute, generating it with the API random
+// generator if the design does not define one. The values of the attributes defined with
+// NoExample are omitted and the keys of hash examples are converted to strings so that the
+// result can be encoded in JSON. AttributeExample returns nil if the attribute has no example.
+func AttributeExample(api *design.APIDefinition, att *design.AttributeDefinition) interface{} {
+	return withoutNoExample(att.GenerateExample(api.RandomGenerator(), nil))
+}
+
+// withoutNoExample removes the "-" values set by NoExample from the given example.
+func withoutNoExample(ex interface{}) interface{} {
+	switch actual := ex.(type) {
+	case string:
+		if actual == "-" {
+			return nil
+		}
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			if v = withoutNoExample(v); v != nil {
+				res[fmt.Sprintf("%v", k)] = v
+			}
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			if v = withoutNoExample(v); v != nil {
+				res[k] = v
+			}
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(actual))
+		for _, v := range actual {
+			if v = withoutNoExample(v); v != nil {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+	return ex
+}
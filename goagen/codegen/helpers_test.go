@@ -2,7 +2,9 @@ package codegen_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
 
 	. "github.com/onsi/gomega"
@@ -17,3 +19,27 @@ func TestHelpers(t *testing.T) {
 	Expect(codegen.KebabCase("testABC")).To(Equal("testabc"))
 	Expect(codegen.KebabCase("testAbc")).To(Equal("test-abc"))
 }
+
+func TestDuration(t *testing.T) {
+	Expect(codegen.Duration(2 * time.Second)).To(Equal("2 * time.Second"))
+	Expect(codegen.Duration(time.Minute)).To(Equal("time.Minute"))
+	Expect(codegen.Duration(1500 * time.Millisecond)).To(Equal("1500 * time.Millisecond"))
+	Expect(codegen.Duration(90 * time.Minute)).To(Equal("90 * time.Minute"))
+	Expect(codegen.Duration(1500)).To(Equal("time.Duration(1500)"))
+}
+
+func TestAttributeExample(t *testing.T) {
+	api := &design.APIDefinition{Name: "test"}
+	att := &design.AttributeDefinition{
+		Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+		Example: map[string]interface{}{
+			"name":   "-",
+			"tags":   []interface{}{"a", "-"},
+			"counts": map[interface{}]interface{}{1: 2},
+		},
+	}
+	Expect(codegen.AttributeExample(api, att)).To(Equal(map[string]interface{}{
+		"tags":   []interface{}{"a"},
+		"counts": map[string]interface{}{"1": 2},
+	}))
+}
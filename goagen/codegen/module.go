@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModuleFor returns the directory and the module path of the Go module containing the given
+// directory. The module is the one declared in the go.mod file of the directory or of its
+// closest parent so that nested modules take precedence over the modules containing them.
+// ModuleFor returns empty strings if the directory is not part of a module.
+func ModuleFor(dir string) (modDir, modPath string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	for {
+		if p := modulePath(filepath.Join(abs, "go.mod")); p != "" {
+			return abs, p
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", ""
+		}
+		abs = parent
+	}
+}
+
+// modulePath returns the module path declared in the given go.mod file, the empty string if the
+// file does not exist or does not declare a module.
+func modulePath(gomod string) string {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		p := strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if p == line {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+		return p
+	}
+	return ""
+}
+
+// modulePackagePath returns the import path of the package in the given absolute directory if
+// the directory is part of a Go module.
+func modulePackagePath(absPath string) (string, bool) {
+	modDir, modPath := ModuleFor(absPath)
+	if modDir == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(modDir, absPath)
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return modPath, true
+	}
+	return modPath + "/" + filepath.ToSlash(rel), true
+}
+
+// moduleSourcePath returns the directory of the package with the given import path if it belongs
+// to the module containing the given directory and exists.
+func moduleSourcePath(pkg, dir string) (string, bool) {
+	modDir, modPath := ModuleFor(dir)
+	if modDir == "" {
+		return "", false
+	}
+	var res string
+	switch {
+	case pkg == modPath:
+		res = modDir
+	case strings.HasPrefix(pkg, modPath+"/"):
+		res = filepath.Join(modDir, filepath.FromSlash(strings.TrimPrefix(pkg, modPath+"/")))
+	default:
+		return "", false
+	}
+	if info, err := os.Stat(res); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return res, true
+}
+
+// unvendor strips the vendor directory prefix from the given import path.
+func unvendor(pkg string) string {
+	if i := strings.LastIndex(pkg, "/vendor/"); i >= 0 {
+		return pkg[i+len("/vendor/"):]
+	}
+	if strings.HasPrefix(pkg, "vendor/") {
+		return strings.TrimPrefix(pkg, "vendor/")
+	}
+	return pkg
+}
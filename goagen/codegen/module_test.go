@@ -0,0 +1,80 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Go modules", func() {
+	var root string
+
+	mkdir := func(elems ...string) string {
+		dir := filepath.Join(append([]string{root}, elems...)...)
+		Ω(os.MkdirAll(dir, 0755)).Should(Succeed())
+		return dir
+	}
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "goagen-module")
+		Ω(err).ShouldNot(HaveOccurred())
+		root, err = filepath.EvalSymlinks(root)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("// Root module\nmodule example.com/root // comment\n\ngo 1.21\n"), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(mkdir("nested"), "go.mod"), []byte("module \"example.com/nested\"\n"), 0644)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	Describe("ModuleFor", func() {
+		It("returns the closest module", func() {
+			modDir, modPath := codegen.ModuleFor(mkdir("app"))
+			Ω(modDir).Should(Equal(root))
+			Ω(modPath).Should(Equal("example.com/root"))
+			modDir, modPath = codegen.ModuleFor(mkdir("nested", "app"))
+			Ω(modDir).Should(Equal(filepath.Join(root, "nested")))
+			Ω(modPath).Should(Equal("example.com/nested"))
+		})
+	})
+
+	Describe("PackagePath", func() {
+		It("infers the package path from go.mod", func() {
+			Ω(codegen.PackagePath(root)).Should(Equal("example.com/root"))
+			Ω(codegen.PackagePath(mkdir("gen", "app"))).Should(Equal("example.com/root/gen/app"))
+			Ω(codegen.PackagePath(mkdir("nested", "app"))).Should(Equal("example.com/nested/app"))
+		})
+
+		It("strips the vendor directory", func() {
+			Ω(codegen.PackagePath(mkdir("vendor", "github.com", "foo", "bar"))).Should(Equal("github.com/foo/bar"))
+		})
+	})
+
+	Describe("PackageSourcePath", func() {
+		var wd string
+
+		BeforeEach(func() {
+			var err error
+			wd, err = os.Getwd()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(os.Chdir(mkdir("cmd"))).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Chdir(wd)
+		})
+
+		It("looks up the packages of the current module", func() {
+			app := mkdir("gen", "app")
+			Ω(codegen.PackageSourcePath("example.com/root/gen/app")).Should(Equal(app))
+			_, err := codegen.PackageSourcePath("example.com/root/missing")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+})
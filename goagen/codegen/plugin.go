@@ -0,0 +1,115 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// Plugin is the interface implemented by code generator plugins. A plugin is a Go package
+	// that calls RegisterPlugin in an init function, the package import path is given to
+	// goagen with the --plugin flag so that it gets compiled in the generator tool. A plugin
+	// hooks into the generation pipeline by implementing one or more of the DesignPlugin,
+	// FilesPlugin and TemplatePlugin interfaces.
+	Plugin interface {
+		// Name returns the name of the plugin used in error messages.
+		Name() string
+	}
+
+	// DesignPlugin is the interface implemented by plugins that inspect or modify the
+	// evaluated design before the generator runs.
+	DesignPlugin interface {
+		Plugin
+		// Prepare is called with the evaluated DSL roots in order of evaluation.
+		Prepare(roots []dslengine.Root) error
+	}
+
+	// FilesPlugin is the interface implemented by plugins that contribute generated files.
+	FilesPlugin interface {
+		Plugin
+		// Generate is called after the generator identified by genfunc (e.g.
+		// "genapp.Generate") ran with the evaluated DSL roots, the output directory and the
+		// generated files. It returns the files generated by the plugin.
+		Generate(genfunc string, roots []dslengine.Root, outDir string, files []string) ([]string, error)
+	}
+
+	// TemplatePlugin is the interface implemented by plugins that modify the templates used to
+	// render the generated source files.
+	TemplatePlugin interface {
+		Plugin
+		// Template is called with the name of the generated file, the name of the section
+		// and the template source prior to rendering the section with
+		// SourceFile.ExecuteTemplate or the file header with SourceFile.WriteHeader (using
+		// the "header" section name). It returns the template source to use.
+		Template(file, section, source string) (string, error)
+	}
+)
+
+// plugins lists the registered plugins in order of registration.
+var plugins []Plugin
+
+// RegisterPlugin registers a code generator plugin. Plugins are run in order of registration.
+func RegisterPlugin(p Plugin) {
+	for _, o := range plugins {
+		if o.Name() == p.Name() {
+			panic(fmt.Sprintf("goagen: duplicate plugin %s", p.Name())) // bug
+		}
+	}
+	plugins = append(plugins, p)
+}
+
+// Plugins returns the registered plugins.
+func Plugins() []Plugin {
+	return plugins
+}
+
+// PreparePlugins calls Prepare on the registered plugins that implement DesignPlugin.
+func PreparePlugins() error {
+	roots, err := dslengine.SortRoots()
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		if dp, ok := p.(DesignPlugin); ok {
+			if err := dp.Prepare(roots); err != nil {
+				return fmt.Errorf("plugin %s: %s", p.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// GeneratePlugins calls Generate on the registered plugins that implement FilesPlugin once the
+// generator identified by genfunc ran. It returns the generated files followed by the files
+// generated by the plugins.
+func GeneratePlugins(genfunc, outDir string, files []string) ([]string, error) {
+	roots, err := dslengine.SortRoots()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		if fp, ok := p.(FilesPlugin); ok {
+			pfiles, err := fp.Generate(genfunc, roots, outDir, files)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %s", p.Name(), err)
+			}
+			files = append(files, pfiles...)
+		}
+	}
+	return files, nil
+}
+
+// pluginTemplate returns the template source of the given section of the given file as
+// modified by the registered plugins that implement TemplatePlugin.
+func pluginTemplate(file, section, source string) (string, error) {
+	for _, p := range plugins {
+		if tp, ok := p.(TemplatePlugin); ok {
+			var err error
+			if source, err = tp.Template(file, section, source); err != nil {
+				return "", fmt.Errorf("plugin %s: %s", p.Name(), err)
+			}
+		}
+	}
+	return source, nil
+}
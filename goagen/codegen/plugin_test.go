@@ -0,0 +1,135 @@
+package codegen_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testPlugin is a plugin that records the calls to its hooks.
+type testPlugin struct {
+	roots   []dslengine.Root
+	genfunc string
+	files   []string
+	// template replaces the source of the sections of the "plugin.go" file if not empty.
+	template string
+}
+
+func (p *testPlugin) Name() string { return "test" }
+
+func (p *testPlugin) Prepare(roots []dslengine.Root) error {
+	p.roots = roots
+	return nil
+}
+
+func (p *testPlugin) Generate(genfunc string, roots []dslengine.Root, outDir string, files []string) ([]string, error) {
+	p.genfunc = genfunc
+	p.files = files
+	return []string{outDir + "/plugin.txt"}, nil
+}
+
+func (p *testPlugin) Template(file, section, source string) (string, error) {
+	if file != "plugin.go" || p.template == "" {
+		return source, nil
+	}
+	if section == "header" {
+		return "// Custom header\n" + source, nil
+	}
+	return p.template, nil
+}
+
+var plugin = &testPlugin{}
+
+func init() {
+	codegen.RegisterPlugin(plugin)
+}
+
+var _ = Describe("Plugins", func() {
+	BeforeEach(func() {
+		*plugin = testPlugin{}
+	})
+
+	It("registers the plugins", func() {
+		Ω(codegen.Plugins()).Should(ContainElement(plugin))
+		Ω(func() { codegen.RegisterPlugin(&testPlugin{}) }).Should(Panic())
+	})
+
+	It("gives the design roots to the plugins", func() {
+		Ω(codegen.PreparePlugins()).Should(Succeed())
+		roots, err := dslengine.SortRoots()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(plugin.roots).Should(Equal(roots))
+	})
+
+	It("appends the files generated by the plugins", func() {
+		files, err := codegen.GeneratePlugins("genfoo.Generate", "/out", []string{"/out/foo.go"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{"/out/foo.go", "/out/plugin.txt"}))
+		Ω(plugin.genfunc).Should(Equal("genfoo.Generate"))
+		Ω(plugin.files).Should(Equal([]string{"/out/foo.go"}))
+	})
+
+	Describe("templates", func() {
+		var workspace *codegen.Workspace
+		var file *codegen.SourceFile
+		var execErr error
+
+		BeforeEach(func() {
+			var err error
+			workspace, err = codegen.NewWorkspace("test")
+			Ω(err).ShouldNot(HaveOccurred())
+			pkg, err := workspace.NewPackage("plugintest")
+			Ω(err).ShouldNot(HaveOccurred())
+			file, err = pkg.CreateSourceFile("plugin.go")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			Ω(file.WriteHeader("Plugin", "plugintest", nil)).Should(Succeed())
+			execErr = file.ExecuteTemplate("hello", "// Hello {{ . }}\n", nil, "world")
+			file.Close()
+		})
+
+		AfterEach(func() {
+			workspace.Delete()
+		})
+
+		It("uses the built-in templates", func() {
+			Ω(execErr).ShouldNot(HaveOccurred())
+			content, err := ioutil.ReadFile(file.Abs())
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).ShouldNot(ContainSubstring("Custom header"))
+			Ω(string(content)).Should(ContainSubstring("// Hello world\n"))
+		})
+
+		Context("with a plugin modifying the templates", func() {
+			BeforeEach(func() {
+				plugin.template = "// Bonjour {{ . }}\n"
+			})
+
+			It("uses the modified templates", func() {
+				Ω(execErr).ShouldNot(HaveOccurred())
+				content, err := ioutil.ReadFile(file.Abs())
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(strings.HasPrefix(string(content), "// Custom header\n")).Should(BeTrue())
+				Ω(string(content)).Should(ContainSubstring("// Bonjour world\n"))
+			})
+		})
+
+		Context("with a plugin producing an invalid template", func() {
+			BeforeEach(func() {
+				plugin.template = "{{ .Foo "
+			})
+
+			It("returns an error", func() {
+				Ω(execErr).Should(HaveOccurred())
+				Ω(fmt.Sprint(execErr)).Should(ContainSubstring("invalid hello template"))
+			})
+		})
+	})
+})
@@ -0,0 +1,392 @@
+package codegen
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SumFile is the name of the file that records the checksums of the generated files in the
+// output directory when generating incrementally.
+const SumFile = ".goagen.sum"
+
+type (
+	// Snapshot records the state of the files produced by the previous run of a generator so
+	// that the files whose content does not change are not rewritten. The files produced by
+	// each generator and their checksums are listed in the SumFile file of the output
+	// directory.
+	//
+	// While a snapshot is active, that is between the calls to NewSnapshot and Update, the
+	// source files and the files written with WriteFile are only written if their content
+	// changed and the directories removed with RemoveAll are kept until Update deletes the
+	// files they contain that were not generated again.
+	Snapshot struct {
+		// OutDir is the generator output directory.
+		OutDir string
+		// Genfunc identifies the generator, e.g. "genapp.Generate".
+		Genfunc string
+		// files records the state of the files produced by the previous run indexed by
+		// path relative to the output directory.
+		files map[string]*fileState
+
+		// mu protects the fields below, source files may be written concurrently.
+		mu sync.Mutex
+		// written records the absolute paths of the files written during the run.
+		written map[string]bool
+		// removed lists the absolute paths of the directories removed during the run.
+		removed []string
+		// err is the first error that occurred while writing a source file.
+		err error
+	}
+
+	// Summary lists the files added, updated, removed and left unchanged by a generator run.
+	// The paths are relative to the output directory.
+	Summary struct {
+		Added     []string
+		Updated   []string
+		Removed   []string
+		Unchanged []string
+	}
+
+	// fileState is the state of a generated file.
+	fileState struct {
+		sum string
+	}
+)
+
+// active is the snapshot of the generator being run, nil if not generating incrementally.
+var active *Snapshot
+
+// NewSnapshot records the state of the files produced by the previous run of the generator
+// identified by genfunc in the given output directory and makes the snapshot active until Update
+// is called.
+func NewSnapshot(outDir, genfunc string) (*Snapshot, error) {
+	s := &Snapshot{
+		OutDir:  outDir,
+		Genfunc: genfunc,
+		files:   make(map[string]*fileState),
+		written: make(map[string]bool),
+	}
+	lines, err := s.readSums()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		fields := strings.SplitN(l, " ", 3)
+		if len(fields) != 3 || fields[0] != genfunc {
+			continue
+		}
+		s.files[fields[2]] = nil
+		info, err := os.Stat(s.abs(fields[2]))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		sum, err := checksum(s.abs(fields[2]))
+		if err != nil {
+			return nil, err
+		}
+		s.files[fields[2]] = &fileState{sum: sum}
+	}
+	active = s
+	return s, nil
+}
+
+// RemoveAll removes path and any children it contains like os.RemoveAll. If a snapshot is active
+// the removal is deferred to Update so that the files generated again are not rewritten when
+// their content does not change.
+func RemoveAll(path string) error {
+	if s := active; s != nil {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.removed = append(s.removed, abs)
+		s.mu.Unlock()
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// WriteFile writes content to the file with the given path. The file is left untouched if it
+// already exists with the same content: the checksums of the contents are compared so that
+// unchanged files are not rewritten and keep their modification time.
+func WriteFile(path string, content []byte) error {
+	if s := active; s != nil {
+		s.wrote(path)
+	}
+	if sum, err := checksum(path); err == nil && sum == checksumOf(content) {
+		return nil
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// Update compares the files produced by the generator with the snapshot and records the checksums
+// of the generated files. files may list directories in which case all the files they contain
+// are considered. The files contained in the directories removed with RemoveAll that were not
+// generated again are deleted. Files produced by the previous run that are not produced anymore
+// are reported as removed if they do not exist anymore. Update deactivates the snapshot.
+func (s *Snapshot) Update(files []string) (*Summary, error) {
+	if active == s {
+		active = nil
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if err := s.removeStale(files); err != nil {
+		return nil, err
+	}
+	generated, err := s.expand(files)
+	if err != nil {
+		return nil, err
+	}
+	var summary Summary
+	sums := make(map[string]string, len(generated))
+	for _, f := range generated {
+		sum, err := checksum(s.abs(f))
+		if err != nil {
+			return nil, err
+		}
+		sums[f] = sum
+		prev, ok := s.files[f]
+		switch {
+		case !ok:
+			summary.Added = append(summary.Added, f)
+		case prev != nil && prev.sum == sum:
+			summary.Unchanged = append(summary.Unchanged, f)
+		default:
+			summary.Updated = append(summary.Updated, f)
+		}
+	}
+	for f := range s.files {
+		if _, ok := sums[f]; ok {
+			continue
+		}
+		if _, err := os.Stat(s.abs(f)); os.IsNotExist(err) {
+			summary.Removed = append(summary.Removed, f)
+		}
+	}
+	sort.Strings(summary.Removed)
+	if err := s.writeSums(generated, sums); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// String returns a description of the summary listing the counts of files followed by the
+// added (A), updated (M) and removed (D) files.
+func (s *Summary) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%d added, %d updated, %d removed, %d unchanged",
+		len(s.Added), len(s.Updated), len(s.Removed), len(s.Unchanged))
+	for _, l := range []struct {
+		status string
+		files  []string
+	}{{"A", s.Added}, {"M", s.Updated}, {"D", s.Removed}} {
+		for _, f := range l.files {
+			fmt.Fprintf(&buf, "\n%s %s", l.status, f)
+		}
+	}
+	return buf.String()
+}
+
+// wrote records that the file with the given path was written during the run.
+func (s *Snapshot) wrote(path string) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	s.mu.Lock()
+	s.written[abs] = true
+	s.mu.Unlock()
+}
+
+// fail records the error that occurred while writing a file so that Update returns it.
+func (s *Snapshot) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+}
+
+// isStale returns true if the file with the given path was contained in a directory removed with
+// RemoveAll and was not written since.
+func (s *Snapshot) isStale(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.written[abs] {
+		return false
+	}
+	for _, dir := range s.removed {
+		if abs == dir || strings.HasPrefix(abs, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeStale deletes the files contained in the directories removed with RemoveAll that were
+// neither written during the run nor listed in files, then the directories left empty.
+func (s *Snapshot) removeStale(files []string) error {
+	listed := make(map[string]bool, len(files))
+	for _, f := range files {
+		if abs, err := filepath.Abs(f); err == nil {
+			listed[abs] = true
+		}
+	}
+	for _, dir := range s.removed {
+		var dirs []string
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				dirs = append(dirs, path)
+				return nil
+			}
+			if !listed[path] && s.isStale(path) {
+				return os.Remove(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i]) // only succeeds if the directory is empty
+		}
+	}
+	return nil
+}
+
+// expand returns the sorted paths relative to the output directory of the given files and of
+// the files contained in the given directories.
+func (s *Snapshot) expand(files []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var res []string
+	add := func(path string) {
+		rel := s.rel(path)
+		if rel != SumFile && !seen[rel] {
+			seen[rel] = true
+			res = append(res, rel)
+		}
+	}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(f)
+			continue
+		}
+		err = filepath.Walk(f, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// readSums returns the lines of the sum file.
+func (s *Snapshot) readSums() ([]string, error) {
+	f, err := os.Open(filepath.Join(s.OutDir, SumFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// writeSums replaces the checksums of the files produced by the generator in the sum file. Each
+// line of the file lists the generator, the checksum and the path of a file.
+func (s *Snapshot) writeSums(files []string, sums map[string]string) error {
+	lines, err := s.readSums()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, l := range lines {
+		if !strings.HasPrefix(l, s.Genfunc+" ") && l != "" {
+			buf.WriteString(l + "\n")
+		}
+	}
+	for _, f := range files {
+		fmt.Fprintf(&buf, "%s %s %s\n", s.Genfunc, sums[f], f)
+	}
+	return ioutil.WriteFile(filepath.Join(s.OutDir, SumFile), buf.Bytes(), 0644)
+}
+
+// abs returns the absolute path of the file with the given path relative to the output
+// directory.
+func (s *Snapshot) abs(rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(s.OutDir, filepath.FromSlash(rel))
+}
+
+// rel returns the path of the given file relative to the output directory, the absolute path if
+// the file is not in the output directory.
+func (s *Snapshot) rel(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(s.OutDir, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return abs
+	}
+	return filepath.ToSlash(rel)
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the content of the given file.
+func checksum(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return checksumOf(b), nil
+}
+
+// checksumOf returns the hex encoded SHA-256 checksum of the given content.
+func checksumOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,166 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Snapshot", func() {
+	const genfunc = "genfoo.Generate"
+
+	var outDir string
+	var past time.Time
+
+	write := func(name, content string) string {
+		path := filepath.Join(outDir, name)
+		Ω(os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(path, []byte(content), 0644)).Should(Succeed())
+		return path
+	}
+
+	// generate simulates a generator run that recreates the app directory.
+	generate := func(files map[string]string) *codegen.Summary {
+		snapshot, err := codegen.NewSnapshot(outDir, genfunc)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(codegen.RemoveAll(filepath.Join(outDir, "app"))).Should(Succeed())
+		for name, content := range files {
+			path := filepath.Join(outDir, name)
+			Ω(os.MkdirAll(filepath.Dir(path), 0755)).Should(Succeed())
+			Ω(codegen.WriteFile(path, []byte(content))).Should(Succeed())
+		}
+		summary, err := snapshot.Update([]string{filepath.Join(outDir, "app")})
+		Ω(err).ShouldNot(HaveOccurred())
+		return summary
+	}
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "snapshot")
+		Ω(err).ShouldNot(HaveOccurred())
+		past = time.Now().Add(-time.Hour).Truncate(time.Second)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("reports added files on the first run", func() {
+		summary := generate(map[string]string{"app/a.go": "a", "app/b.go": "b"})
+		Ω(summary.Added).Should(Equal([]string{"app/a.go", "app/b.go"}))
+		Ω(summary.Updated).Should(BeEmpty())
+		Ω(summary.Removed).Should(BeEmpty())
+		Ω(summary.Unchanged).Should(BeEmpty())
+		_, err := os.Stat(filepath.Join(outDir, codegen.SumFile))
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("with a previous run", func() {
+		var summary *codegen.Summary
+
+		BeforeEach(func() {
+			generate(map[string]string{"app/a.go": "a", "app/b.go": "b", "app/c.go": "c"})
+			for _, f := range []string{"app/a.go", "app/b.go"} {
+				Ω(os.Chtimes(filepath.Join(outDir, f), past, past)).Should(Succeed())
+			}
+			summary = generate(map[string]string{"app/a.go": "a", "app/b.go": "b2", "app/d.go": "d"})
+		})
+
+		It("reports the added, updated, removed and unchanged files", func() {
+			Ω(summary.Added).Should(Equal([]string{"app/d.go"}))
+			Ω(summary.Updated).Should(Equal([]string{"app/b.go"}))
+			Ω(summary.Removed).Should(Equal([]string{"app/c.go"}))
+			Ω(summary.Unchanged).Should(Equal([]string{"app/a.go"}))
+			Ω(summary.String()).Should(Equal("1 added, 1 updated, 1 removed, 1 unchanged\nA app/d.go\nM app/b.go\nD app/c.go"))
+		})
+
+		It("keeps the modification time of the unchanged files", func() {
+			info, err := os.Stat(filepath.Join(outDir, "app", "a.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.ModTime().Equal(past)).Should(BeTrue())
+			info, err = os.Stat(filepath.Join(outDir, "app", "b.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.ModTime().After(past)).Should(BeTrue())
+		})
+
+		It("keeps the checksums of the other generators", func() {
+			snapshot, err := codegen.NewSnapshot(outDir, "genbar.Generate")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = snapshot.Update([]string{write("bar.go", "bar")})
+			Ω(err).ShouldNot(HaveOccurred())
+			summary = generate(map[string]string{"app/a.go": "a", "app/b.go": "b2", "app/d.go": "d"})
+			Ω(summary.Unchanged).Should(Equal([]string{"app/a.go", "app/b.go", "app/d.go"}))
+			sums, err := ioutil.ReadFile(filepath.Join(outDir, codegen.SumFile))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(sums)).Should(ContainSubstring("genbar.Generate "))
+			Ω(string(sums)).Should(ContainSubstring(" bar.go\n"))
+		})
+
+		It("deletes the files that are not generated anymore", func() {
+			_, err := os.Stat(filepath.Join(outDir, "app", "c.go"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+	})
+
+	Context("with source files", func() {
+		var workspace *codegen.Workspace
+		var pkg *codegen.Package
+		var filename string
+
+		// render simulates a generator run that renders and formats a Go source file.
+		render := func(src string) {
+			snapshot, err := codegen.NewSnapshot(pkg.Abs(), genfunc)
+			Ω(err).ShouldNot(HaveOccurred())
+			file, err := pkg.CreateSourceFile("a.go")
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = file.Write([]byte(src))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(file.FormatCode()).Should(Succeed())
+			file.Close()
+			filename = file.Abs()
+			_, err = snapshot.Update([]string{filename})
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			workspace, err = codegen.NewWorkspace("snapshot")
+			Ω(err).ShouldNot(HaveOccurred())
+			pkg, err = workspace.NewPackage("snapshot")
+			Ω(err).ShouldNot(HaveOccurred())
+			render("package snapshot\nfunc A() {   }\n")
+			Ω(os.Chtimes(filename, past, past)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			workspace.Delete()
+		})
+
+		It("does not rewrite the files whose content does not change", func() {
+			render("package snapshot\nfunc A() {   }\n")
+			info, err := os.Stat(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.ModTime().Equal(past)).Should(BeTrue())
+			content, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("package snapshot\n\nfunc A() {}\n"))
+			_, err = os.Stat(filename + "~")
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+		})
+
+		It("rewrites the files whose content changes", func() {
+			render("package snapshot\nfunc B() {   }\n")
+			info, err := os.Stat(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(info.ModTime().After(past)).Should(BeTrue())
+			content, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("package snapshot\n\nfunc B() {}\n"))
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package codegen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// TemplateDir is a plugin that overrides the built-in templates with the templates stored in
+// the directory at the given path. The template of a section is read from the file named after
+// the section with the ".tmpl" extension, for example "header.tmpl" overrides the header of all
+// the generated files. Templates stored in a sub-directory named after a generated file override
+// the sections of that file only and take precedence, for example "contexts.go/context.tmpl"
+// overrides the context section of the contexts.go file.
+type TemplateDir string
+
+// Name returns the name of the plugin.
+func (d TemplateDir) Name() string {
+	return "templates"
+}
+
+// Template returns the content of the template overriding the given section of the given file
+// if any, source otherwise.
+func (d TemplateDir) Template(file, section, source string) (string, error) {
+	for _, path := range []string{
+		filepath.Join(string(d), file, section+".tmpl"),
+		filepath.Join(string(d), section+".tmpl"),
+	} {
+		b, err := ioutil.ReadFile(path)
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return source, nil
+}
@@ -0,0 +1,80 @@
+package codegen_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateDir", func() {
+	var dir string
+	var file, section string
+	var source string
+	var tmplErr error
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "templates")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(os.MkdirAll(filepath.Join(dir, "contexts.go"), 0755)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "header.tmpl"), []byte("custom header"), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "context.tmpl"), []byte("custom context"), 0644)).Should(Succeed())
+		Ω(ioutil.WriteFile(filepath.Join(dir, "contexts.go", "context.tmpl"), []byte("custom contexts.go context"), 0644)).Should(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		source, tmplErr = codegen.TemplateDir(dir).Template(file, section, "built-in")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("with a section overridden for all files", func() {
+		BeforeEach(func() {
+			file, section = "controllers.go", "header"
+		})
+
+		It("returns the override", func() {
+			Ω(tmplErr).ShouldNot(HaveOccurred())
+			Ω(source).Should(Equal("custom header"))
+		})
+	})
+
+	Context("with a section overridden for a specific file", func() {
+		BeforeEach(func() {
+			file, section = "contexts.go", "context"
+		})
+
+		It("returns the file specific override", func() {
+			Ω(tmplErr).ShouldNot(HaveOccurred())
+			Ω(source).Should(Equal("custom contexts.go context"))
+		})
+	})
+
+	Context("with a section overridden for other files", func() {
+		BeforeEach(func() {
+			file, section = "media_types.go", "context"
+		})
+
+		It("returns the override for all files", func() {
+			Ω(tmplErr).ShouldNot(HaveOccurred())
+			Ω(source).Should(Equal("custom context"))
+		})
+	})
+
+	Context("with a section that is not overridden", func() {
+		BeforeEach(func() {
+			file, section = "contexts.go", "payload"
+		})
+
+		It("returns the built-in template", func() {
+			Ω(tmplErr).ShouldNot(HaveOccurred())
+			Ω(source).Should(Equal("built-in"))
+		})
+	})
+})
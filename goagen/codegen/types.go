@@ -101,10 +101,16 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 	}
 }
 
-// goTypeDefObject returns the Go code that defines a Go struct.
+// goTypeDefObject returns the Go code that defines a Go struct. The structs of types that extend
+// other types embed the base type structs, see embeddedBases.
 func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs int, jsonTags, private bool) string {
 	var buffer bytes.Buffer
 	buffer.WriteString("struct {\n")
+	bases, inherited := embeddedBases(obj, def, tabs, jsonTags, private)
+	for _, base := range bases {
+		WriteTabs(&buffer, tabs+1)
+		buffer.WriteString(GoTypeName(base, nil, tabs+1, private) + "\n")
+	}
 	keys := make([]string, len(obj))
 	i := 0
 	for n := range obj {
@@ -113,32 +119,100 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 	}
 	sort.Strings(keys)
 	for _, name := range keys {
-		WriteTabs(&buffer, tabs+1)
-		field := obj[name]
-		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (field.Type.IsPrimitive() && private) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
-			typedef = "*" + typedef
-		}
-		fname := GoifyAtt(field, name, true)
-		var tags string
-		if jsonTags {
-			tags = attributeTags(def, field, name, private)
-		}
-		desc := obj[name].Description
-		if desc != "" {
-			desc = strings.Replace(desc, "\n", "\n\t// ", -1)
-			desc = fmt.Sprintf("// %s\n\t", desc)
+		if inherited[name] {
+			continue
 		}
-		buffer.WriteString(fmt.Sprintf("%s%s %s%s\n", desc, fname, typedef, tags))
+		WriteTabs(&buffer, tabs+1)
+		buffer.WriteString(goTypeDefField(obj, def, name, tabs, jsonTags, private))
 	}
 	WriteTabs(&buffer, tabs)
 	buffer.WriteString("}")
 	return buffer.String()
 }
 
-// attributeTags computes the struct field tags.
+// goTypeDefField returns the Go code that defines the struct field corresponding to the object
+// attribute with the given name.
+func goTypeDefField(obj design.Object, def *design.AttributeDefinition, name string, tabs int, jsonTags, private bool) string {
+	field := obj[name]
+	typedef := GoTypeDef(field, tabs+1, jsonTags, private)
+	if (field.Type.IsPrimitive() && private) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+		typedef = "*" + typedef
+	}
+	fname := GoifyAtt(field, name, true)
+	var tags string
+	if jsonTags {
+		tags = attributeTags(def, field, name, private)
+	}
+	desc := field.Description
+	if desc != "" {
+		desc = strings.Replace(desc, "\n", "\n\t// ", -1)
+		desc = fmt.Sprintf("// %s\n\t", desc)
+	}
+	return fmt.Sprintf("%s%s %s%s\n", desc, fname, typedef, tags)
+}
+
+// embeddedBases returns the base types extended by the given object definition whose structs can
+// be embedded in the struct of the object together with the names of the attributes whose fields
+// are promoted from the embedded structs. A base type struct can only be embedded if the object
+// defines all the base type attributes. The object struct declares the fields that differ from the
+// base type fields (overridden attributes or attributes with different required-ness) as well as
+// the fields that would be ambiguous because they are defined by multiple base types.
+func embeddedBases(obj design.Object, def *design.AttributeDefinition, tabs int, jsonTags, private bool) ([]*design.UserTypeDefinition, map[string]bool) {
+	var bases []*design.UserTypeDefinition
+	for _, b := range def.Bases {
+		base, ok := b.(*design.UserTypeDefinition)
+		if !ok || base.IsUnion() || base.Type == nil || !base.Type.IsObject() {
+			continue
+		}
+		name := GoTypeName(base, nil, 0, private)
+		embeddable := true
+		for n, att := range obj {
+			if GoifyAtt(att, n, true) == name {
+				embeddable = false
+				break
+			}
+		}
+		for n := range base.ToObject() {
+			if _, ok := obj[n]; !ok {
+				embeddable = false
+				break
+			}
+		}
+		if embeddable {
+			bases = append(bases, base)
+		}
+	}
+	if len(bases) == 0 {
+		return nil, nil
+	}
+	counts := make(map[string]int)
+	for _, base := range bases {
+		for n := range base.ToObject() {
+			counts[n]++
+		}
+	}
+	inherited := make(map[string]bool)
+	for _, base := range bases {
+		bobj := base.ToObject()
+		for n := range bobj {
+			if counts[n] > 1 {
+				continue
+			}
+			field := goTypeDefField(obj, def, n, tabs, jsonTags, private)
+			if field == goTypeDefField(bobj, base.AttributeDefinition, n, tabs, jsonTags, private) {
+				inherited[n] = true
+			}
+		}
+	}
+	return bases, inherited
+}
+
+// attributeTags computes the struct field tags. The "struct:tag:xxx" attribute metadata replaces
+// the default form, json and xml tags while the "struct:tags" metadata of the parent lists
+// additional tags generated for all fields using the attribute name as value.
 func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
 	var elems []string
+	custom := make(map[string]bool)
 	keys := make([]string, len(att.Metadata))
 	i := 0
 	for k := range att.Metadata {
@@ -152,17 +226,25 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 			name := key[11:]
 			value := strings.Join(val, ",")
 			elems = append(elems, fmt.Sprintf("%s:\"%s\"", name, value))
+			custom[name] = true
 		}
 	}
-	if len(elems) > 0 {
-		return " `" + strings.Join(elems, " ") + "`"
-	}
-	// Default algorithm
 	var omit string
 	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
 		omit = ",omitempty"
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", name, omit, name, omit, name, omit)
+	if len(elems) == 0 {
+		// Default algorithm
+		for _, tag := range []string{"form", "json", "xml"} {
+			elems = append(elems, fmt.Sprintf("%s:\"%s%s\"", tag, name, omit))
+		}
+	}
+	for _, tag := range parent.Metadata["struct:tags"] {
+		if !custom[tag] {
+			elems = append(elems, fmt.Sprintf("%s:\"%s%s\"", tag, name, omit))
+		}
+	}
+	return " `" + strings.Join(elems, " ") + "`"
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -241,6 +323,8 @@ func GoNativeType(t design.DataType) string {
 			return "uuid.UUID"
 		case design.AnyKind:
 			return "interface{}"
+		case design.FileKind:
+			return "multipart.FileHeader"
 		default:
 			panic(fmt.Sprintf("goa bug: unknown primitive type %#v", actual))
 		}
@@ -237,6 +237,32 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using struct tags parent metadata", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"struct:tag:bson": []string{"_id"},
+						}
+						required = &dslengine.ValidationDefinition{Required: []string{"bar"}}
+					})
+
+					JustBeforeEach(func() {
+						att.Metadata = dslengine.MetadataDefinition{
+							"struct:tags": []string{"bson", "db"},
+						}
+						st = codegen.GoTypeDef(att, 0, true, false)
+					})
+
+					It("adds the tags to all the fields", func() {
+						expected := "struct {\n" +
+							"	Bar string `form:\"bar\" json:\"bar\" xml:\"bar\" bson:\"bar\" db:\"bar\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\" bson:\"baz,omitempty\" db:\"baz,omitempty\"`\n" +
+							"	Foo *int `bson:\"_id\" db:\"foo,omitempty\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\" bson:\"qux,omitempty\" db:\"qux,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
 				Context("using struct field name metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -394,6 +420,35 @@ var _ = Describe("code generation", func() {
 
 		})
 
+		Context("given a type extending a base type", func() {
+			var st string
+
+			BeforeEach(func() {
+				dslengine.Reset()
+				entity := Type("Entity", func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Required("id")
+				})
+				bottle := Type("Bottle", func() {
+					Extend(entity)
+					Attribute("vintage", Integer)
+					Required("name")
+				})
+				Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+				st = codegen.GoTypeDef(bottle.AttributeDefinition, 0, true, false)
+			})
+
+			It("embeds the base type and declares the fields that differ", func() {
+				expected := "struct {\n" +
+					"	Entity\n" +
+					"	Name string `form:\"name\" json:\"name\" xml:\"name\"`\n" +
+					"	Vintage *int `form:\"vintage,omitempty\" json:\"vintage,omitempty\" xml:\"vintage,omitempty\"`\n" +
+					"}"
+				Ω(st).Should(Equal(expected))
+			})
+		})
+
 		Context("given an array", func() {
 			var elemType *AttributeDefinition
 			var source string
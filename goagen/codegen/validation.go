@@ -57,12 +57,14 @@ type Validator struct {
 	hashValT  *template.Template
 	userValT  *template.Template
 	seen      map[string]*bytes.Buffer
+	pending   map[string]bool
+	cycles    int
 }
 
 // NewValidator instantiates a validate code generator.
 func NewValidator() *Validator {
 	var (
-		v   = &Validator{seen: make(map[string]*bytes.Buffer)}
+		v   = &Validator{seen: make(map[string]*bytes.Buffer), pending: make(map[string]bool)}
 		err error
 	)
 	fm := template.FuncMap{
@@ -168,7 +170,7 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 				"depth":  depth + 2,
 				"target": "k",
 			})
-			keyVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), keyVal, Tabs(depth+1))
+			keyVal = fmt.Sprintf("%sif k != nil {\n%s\n%s}", Tabs(depth+1), keyVal, Tabs(depth+1))
 		}
 	}
 	elemVal := v.Code(h.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
@@ -207,18 +209,41 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 		first = true
 	)
 
+	// The variant of union values is only known at runtime
+	if ut, ok := att.Type.(*design.UserTypeDefinition); att.Union != nil || ok && ut.IsUnion() {
+		buf.WriteString(RunTemplate(v.userValT, map[string]interface{}{
+			"depth":  depth,
+			"target": target,
+		}))
+		return buf
+	}
+
 	// Break infinite recursions
+	var name string
 	switch dt := att.Type.(type) {
 	case *design.MediaTypeDefinition:
-		if buf, ok := v.seen[dt.TypeName]; ok {
-			return buf
-		}
-		v.seen[dt.TypeName] = buf
+		name = dt.TypeName
 	case *design.UserTypeDefinition:
-		if buf, ok := v.seen[dt.TypeName]; ok {
+		name = dt.TypeName
+	}
+	if name != "" {
+		if buf, ok := v.seen[name]; ok {
+			if v.pending[name] {
+				v.cycles++
+			}
 			return buf
 		}
-		v.seen[dt.TypeName] = buf
+		v.seen[name] = buf
+		v.pending[name] = true
+		cycles := v.cycles
+		defer func() {
+			delete(v.pending, name)
+			if v.cycles != cycles {
+				// The code depends on types whose code was still being generated, do not
+				// reuse it for other types of the cycle.
+				delete(v.seen, name)
+			}
+		}()
 	}
 
 	if o := att.Type.ToObject(); o != nil {
@@ -302,7 +327,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		}
 		validation = v.recurse(
 			catt,
-			att.IsNonZero(n),
+			att.IsNonZero(n) || att.IsNonPointer(n),
 			att.IsRequired(n),
 			att.HasDefaultValue(n),
 			fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
@@ -310,6 +335,15 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 			dp,
 			private,
 		).String()
+		if validation != "" && !private && att.IsNonPointer(n) && !att.IsNonZero(n) &&
+			!att.IsRequired(n) && !att.HasDefaultValue(n) {
+			// The zero value of optional non-pointer fields denotes the absence of value.
+			field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+			if cond := nonZeroCheck(catt, field); cond != "" {
+				validation = fmt.Sprintf("%sif %s {\n%s\n%s}",
+					Tabs(depth), cond, Indent(validation, "\t"), Tabs(depth))
+			}
+		}
 	}
 	if validation != "" {
 		if catt.Type.IsObject() {
@@ -320,6 +354,27 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 	return validation
 }
 
+// nonZeroCheck returns the condition that checks that the field holding a value of the given
+// primitive attribute is not the zero value, the empty string if there is no such condition.
+func nonZeroCheck(att *design.AttributeDefinition, field string) string {
+	if _, ok := att.Metadata["struct:field:type"]; ok {
+		return ""
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return field
+	case design.IntegerKind, design.NumberKind:
+		return field + " != 0"
+	case design.StringKind:
+		return field + ` != ""`
+	case design.DateTimeKind:
+		return "!" + field + ".IsZero()"
+	case design.UUIDKind:
+		return field + " != (uuid.UUID{})"
+	}
+	return ""
+}
+
 // ValidationChecker produces Go code that runs the validation defined in the given attribute
 // definition against the content of the variable named target recursively.
 // context is used to keep track of recursion to produce helpful error messages in case of type
@@ -331,11 +386,24 @@ func ValidationChecker(att *design.AttributeDefinition, nonzero, required, hasDe
 	if att.Validation == nil {
 		return ""
 	}
+	if att.Scalar() != "" {
+		// Custom scalars validate their values when unmarshaling them
+		return ""
+	}
 	t := target
 	isPointer := private || (!required && !hasDefault && !nonzero)
 	if isPointer && att.Type.IsPrimitive() {
 		t = "*" + t
 	}
+	if _, ok := att.Metadata["struct:field:type"]; ok && att.Type.IsPrimitive() {
+		// Validate the underlying value of the custom Go type
+		switch att.Type.Kind() {
+		case design.StringKind, design.IntegerKind, design.NumberKind:
+			t = fmt.Sprintf("%s(%s)", GoNativeType(att.Type), t)
+		default:
+			return ""
+		}
+	}
 	data := map[string]interface{}{
 		"attribute": att,
 		"isPointer": private || isPointer,
@@ -474,6 +542,8 @@ func constant(formatName string) string {
 		return "goa.FormatRegexp"
 	case "rfc1123":
 		return "goa.FormatRFC1123"
+	case "duration":
+		return "goa.FormatDuration"
 	}
 	panic("unknown format") // bug
 }
@@ -522,7 +592,7 @@ const (
 {{ end }}{{ tabs .depth }}}`
 
 	lengthValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
-*/}}{{ $target := or (and (or (or .array .hash) .nonzero) .target) .targetVal }}{{/*
+*/}}{{ $target := or (and (or .array .hash) .target) .targetVal }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
 {{ end }}{{ tabs .depth }}	if {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }} {{ if .isMinLength }}<{{ else }}>{{ end }} {{ if .isMinLength }}{{ .minLength }}{{ else }}{{ .maxLength }}{{ end }} {
 {{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `{{ .context }}` + "`" + `, {{ $target }}, {{ if .string }}utf8.RuneCountInString({{ $target }}){{ else }}len({{ $target }}){{ end }}, {{ if .isMinLength }}{{ .minLength }}, true{{ else }}{{ .maxLength }}, false{{ end }}))
@@ -530,7 +600,7 @@ const (
 {{ end }}{{ tabs .depth }}}`
 
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
-*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
+*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) (not $att.Scalar) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
 {{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
@@ -349,6 +349,91 @@ var _ = Describe("validation code generation", func() {
 					Ω(code).Should(BeEmpty())
 				})
 			})
+
+			Context("with a required child attribute using a custom scalar", func() {
+				BeforeEach(func() {
+					attType = &design.Object{
+						"foo": &design.AttributeDefinition{
+							Type: design.String,
+							Metadata: map[string][]string{
+								"struct:field:type":   {"big.Float", "math/big"},
+								"struct:field:scalar": {"BigFloat"},
+							},
+							Validation: &dslengine.ValidationDefinition{Pattern: ".*"},
+						},
+					}
+					validation = &dslengine.ValidationDefinition{
+						Required: []string{"foo"},
+					}
+				})
+
+				JustBeforeEach(func() {
+					code = codegen.NewValidator().Code(att, true, false, false, target, context, 1, false)
+				})
+
+				It("does not produce validation code for the child attribute", func() {
+					Ω(code).Should(BeEmpty())
+				})
+			})
+
+			Context("with a child attribute with a custom type metadata", func() {
+				BeforeEach(func() {
+					attType = &design.Object{
+						"foo": &design.AttributeDefinition{
+							Type:       design.String,
+							Metadata:   map[string][]string{"struct:field:type": {"Name"}},
+							Validation: &dslengine.ValidationDefinition{Pattern: "^a"},
+						},
+					}
+					validation = nil
+				})
+
+				JustBeforeEach(func() {
+					code = codegen.NewValidator().Code(att, true, false, false, target, context, 1, false)
+				})
+
+				It("validates the underlying value", func() {
+					Ω(code).Should(ContainSubstring("if val.Foo != nil {"))
+					Ω(code).Should(ContainSubstring("goa.ValidatePattern(`^a`, string(*val.Foo))"))
+				})
+			})
+		})
+	})
+
+	Describe("Validator", func() {
+		Context("given mutually recursive types", func() {
+			var a, b *design.UserTypeDefinition
+
+			BeforeEach(func() {
+				a = &design.UserTypeDefinition{TypeName: "A"}
+				b = &design.UserTypeDefinition{TypeName: "B"}
+				a.AttributeDefinition = &design.AttributeDefinition{
+					Type: design.Object{
+						"bs": &design.AttributeDefinition{
+							Type: &design.Array{ElemType: &design.AttributeDefinition{Type: b}},
+						},
+					},
+				}
+				b.AttributeDefinition = &design.AttributeDefinition{
+					Type: design.Object{
+						"as": &design.AttributeDefinition{
+							Type: &design.Array{ElemType: &design.AttributeDefinition{Type: a}},
+						},
+						"name": &design.AttributeDefinition{Type: design.String},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+				}
+			})
+
+			It("validates the nested values of both types", func() {
+				v := codegen.NewValidator()
+				codeA := v.Code(a.AttributeDefinition, false, false, false, "ut", "type", 1, false)
+				codeB := v.Code(b.AttributeDefinition, false, false, false, "ut", "type", 1, false)
+				Ω(codeA).Should(ContainSubstring("for _, e := range ut.Bs {"))
+				Ω(codeA).Should(ContainSubstring("e.Validate()"))
+				Ω(codeB).Should(ContainSubstring("for _, e := range ut.As {"))
+				Ω(codeB).Should(ContainSubstring("e.Validate()"))
+			})
 		})
 	})
 })
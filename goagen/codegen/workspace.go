@@ -47,6 +47,9 @@ type (
 		Package *Package
 		// osFile is the underlying OS file.
 		osFile *os.File
+		// staged is the path of the file the content is written to until Close if a
+		// snapshot is active, see Snapshot.
+		staged string
 	}
 )
 
@@ -59,6 +62,7 @@ var (
 		"add":                 func(a, b int) int { return a + b },
 		"commandLine":         CommandLine,
 		"comment":             Comment,
+		"duration":            Duration,
 		"goify":               Goify,
 		"goifyatt":            GoifyAtt,
 		"gonative":            GoNativeType,
@@ -179,15 +183,37 @@ func (p *Package) Abs() string {
 // CreateSourceFile creates a Go source file in the given package. If the file
 // already exists it is overwritten.
 func (p *Package) CreateSourceFile(name string) (*SourceFile, error) {
-	os.RemoveAll(filepath.Join(p.Abs(), name))
-	return p.OpenSourceFile(name)
+	return p.openSourceFile(name, false)
 }
 
 // OpenSourceFile opens an existing file to append to it. If the file does not
 // exist OpenSourceFile creates it.
 func (p *Package) OpenSourceFile(name string) (*SourceFile, error) {
+	return p.openSourceFile(name, true)
+}
+
+// openSourceFile opens the source file with the given name, appending keeps the existing content.
+// If a snapshot is active the content is written to a staging file that Close moves to the
+// source file only if the content changed.
+func (p *Package) openSourceFile(name string, appending bool) (*SourceFile, error) {
 	f := &SourceFile{Name: name, Package: p}
-	file, err := os.OpenFile(f.Abs(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	path := f.Abs()
+	if s := active; s != nil {
+		var content []byte
+		if appending && !s.isStale(path) {
+			if b, err := ioutil.ReadFile(path); err == nil {
+				content = b
+			}
+		}
+		f.staged = path + "~"
+		if err := ioutil.WriteFile(f.staged, content, 0644); err != nil {
+			return nil, err
+		}
+		path = f.staged
+	} else if !appending {
+		os.RemoveAll(path)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		return nil, err
 	}
@@ -240,7 +266,17 @@ func (f *SourceFile) WriteHeader(title, pack string, imports []*ImportSpec) erro
 		"Pkg":         pack,
 		"Imports":     imports,
 	}
-	if err := headerTmpl.Execute(f, ctx); err != nil {
+	tmpl := headerTmpl
+	source, err := pluginTemplate(f.Name, "header", headerT)
+	if err != nil {
+		return err
+	}
+	if source != headerT {
+		if tmpl, err = template.New("header").Funcs(DefaultFuncMap).Parse(source); err != nil {
+			return fmt.Errorf("invalid header template: %s", err)
+		}
+	}
+	if err := tmpl.Execute(f, ctx); err != nil {
 		return fmt.Errorf("failed to generate contexts: %s", err)
 	}
 	return nil
@@ -252,23 +288,50 @@ func (f *SourceFile) Write(b []byte) (int, error) {
 	return f.osFile.Write(b)
 }
 
-// Close closes the underlying OS file.
+// Close closes the underlying OS file. If a snapshot is active Close writes the content to the
+// source file unless it did not change, errors are reported by Snapshot.Update.
 func (f *SourceFile) Close() {
 	if err := f.osFile.Close(); err != nil {
 		panic(err) // bug
 	}
+	if f.staged == "" {
+		return
+	}
+	staged := f.staged
+	f.staged = ""
+	content, err := ioutil.ReadFile(staged)
+	if err == nil {
+		err = WriteFile(f.Abs(), content)
+	}
+	if err == nil {
+		err = os.Remove(staged)
+	}
+	if err != nil {
+		if s := active; s != nil {
+			s.fail(err)
+		}
+	}
 }
 
-// FormatCode performs the equivalent of "goimports -w" on the source file.
+// FormatCode performs the equivalent of "goimports -w" on the source file. Calling FormatCode
+// before Close avoids writing the unformatted content to the source file when a snapshot is
+// active.
 func (f *SourceFile) FormatCode() error {
+	path := f.Abs()
+	if f.staged != "" {
+		path = f.staged
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
 	// Parse file into AST
 	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, f.Abs(), nil, parser.ParseComments)
+	file, err := parser.ParseFile(fset, f.Abs(), src, parser.ParseComments)
 	if err != nil {
-		content, _ := ioutil.ReadFile(f.Abs())
 		var buf bytes.Buffer
 		scanner.PrintError(&buf, err)
-		return fmt.Errorf("%s\n========\nContent:\n%s", buf.String(), content)
+		return fmt.Errorf("%s\n========\nContent:\n%s", buf.String(), src)
 	}
 	// Clean unused imports
 	imports := astutil.Imports(fset, file)
@@ -285,14 +348,15 @@ func (f *SourceFile) FormatCode() error {
 		}
 	}
 	ast.SortImports(fset, file)
-	// Open file to be written
-	w, err := os.OpenFile(f.Abs(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
-	if err != nil {
+	// Write formatted code without unused imports
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
 		return err
 	}
-	defer w.Close()
-	// Write formatted code without unused imports
-	return format.Node(w, fset, file)
+	if path == f.staged {
+		return ioutil.WriteFile(path, buf.Bytes(), 0644)
+	}
+	return WriteFile(path, buf.Bytes())
 }
 
 // Abs returne the source file absolute filename
@@ -300,22 +364,35 @@ func (f *SourceFile) Abs() string {
 	return filepath.Join(f.Package.Abs(), f.Name)
 }
 
-// ExecuteTemplate executes the template and writes the output to the file.
+// ExecuteTemplate executes the template and writes the output to the file. The template source
+// may be modified by the registered plugins.
 func (f *SourceFile) ExecuteTemplate(name, source string, funcMap template.FuncMap, data interface{}) error {
-	tmpl, err := template.New(name).Funcs(DefaultFuncMap).Funcs(funcMap).Parse(source)
+	modified, err := pluginTemplate(f.Name, name, source)
+	if err != nil {
+		return err
+	}
+	tmpl, err := template.New(name).Funcs(DefaultFuncMap).Funcs(funcMap).Parse(modified)
 	if err != nil {
+		if modified != source {
+			return fmt.Errorf("invalid %s template: %s", name, err)
+		}
 		panic(err) // bug
 	}
 	return tmpl.Execute(f, data)
 }
 
 // PackagePath returns the Go package path for the directory that lives under the given absolute
-// file path.
+// file path. The package path is computed from the module path declared in the go.mod file of the
+// closest enclosing Go module if any, from GOPATH otherwise. The vendor directory prefix of
+// vendored packages is stripped.
 func PackagePath(path string) (string, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		absPath = path
 	}
+	if pkg, ok := modulePackagePath(absPath); ok {
+		return unvendor(pkg), nil
+	}
 	gopaths := filepath.SplitList(os.Getenv("GOPATH"))
 	for _, gopath := range gopaths {
 		if gp, err := filepath.Abs(gopath); err == nil {
@@ -324,13 +401,15 @@ func PackagePath(path string) (string, error) {
 		if filepath.HasPrefix(absPath, gopath) {
 			base := filepath.FromSlash(gopath + "/src")
 			rel, err := filepath.Rel(base, absPath)
-			return filepath.ToSlash(rel), err
+			return unvendor(filepath.ToSlash(rel)), err
 		}
 	}
 	return "", fmt.Errorf("%s does not contain a Go package", absPath)
 }
 
-// PackageSourcePath returns the absolute path to the given package source.
+// PackageSourcePath returns the absolute path to the given package source. Packages that belong
+// to the Go module containing the current working directory are looked up in the module
+// directory.
 func PackageSourcePath(pkg string) (string, error) {
 	buildCtx := build.Default
 	buildCtx.GOPATH = os.Getenv("GOPATH") // Reevaluate each time to be nice to tests
@@ -338,6 +417,9 @@ func PackageSourcePath(pkg string) (string, error) {
 	if err != nil {
 		wd = "."
 	}
+	if dir, ok := moduleSourcePath(pkg, wd); ok {
+		return dir, nil
+	}
 	p, err := buildCtx.Import(pkg, wd, 0)
 	if err != nil {
 		return "", err
@@ -0,0 +1,247 @@
+package genapp
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// BuildConversions builds the template data needed to render the methods that convert the user
+// types to and from the external Go structs listed with ConvertTo and CreateFrom. It also returns
+// the imports of the packages defining the external types. BuildConversions returns an error if
+// the type of an attribute cannot be converted to the type of the matching external field.
+func BuildConversions(api *design.APIDefinition) ([]*ConversionTemplateData, []*codegen.ImportSpec, error) {
+	var (
+		data    []*ConversionTemplateData
+		imports []*codegen.ImportSpec
+	)
+	err := api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		for _, c := range ut.Conversions {
+			d, imps, err := buildConversion(ut, c)
+			if err != nil {
+				return err
+			}
+			data = append(data, d)
+			for _, imp := range imps {
+				imports = appendImport(imports, imp)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, imports, nil
+}
+
+// ConversionMethod returns the name of the user type method that implements the given conversion,
+// e.g. "ConvertToModelsUser" or "CreateFromModelsUser". The name includes the name of the package
+// of the external type so that conversions to types with the same name do not collide.
+func ConversionMethod(c *design.ConversionDefinition) string {
+	name := codegen.Goify(typeImport(c.External).Name, true) + c.External.Name()
+	if c.Create {
+		return "CreateFrom" + name
+	}
+	return "ConvertTo" + name
+}
+
+// buildConversion computes the code that assigns the fields of the external struct from the user
+// type fields or vice versa.
+func buildConversion(ut *design.UserTypeDefinition, c *design.ConversionDefinition) (*ConversionTemplateData, []*codegen.ImportSpec, error) {
+	if ut.IsUnion() || !ut.Type.IsObject() {
+		return nil, nil, fmt.Errorf("type %s: conversions require an object type", ut.TypeName)
+	}
+	imports := []*codegen.ImportSpec{typeImport(c.External)}
+	obj := ut.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var code bytes.Buffer
+	for _, n := range names {
+		att := obj[n]
+		field, ok := externalField(c.External, att, n)
+		if !ok {
+			continue
+		}
+		fname := codegen.GoifyAtt(att, n, true)
+		src, dst := "ut."+fname, "t."+field.Name
+		if c.Create {
+			src, dst = "v."+field.Name, "ut."+fname
+		}
+		assign, imps, err := conversionCode(ut, att, n, field.Type, c.Create, src, dst)
+		if err != nil {
+			return nil, nil, fmt.Errorf("type %s attribute %#v: %s", ut.TypeName, n, err)
+		}
+		code.WriteString(assign)
+		imports = append(imports, imps...)
+	}
+	return &ConversionTemplateData{
+		TypeName: codegen.GoTypeName(ut, nil, 0, false),
+		External: c.External.String(),
+		Method:   ConversionMethod(c),
+		Create:   c.Create,
+		Code:     code.String(),
+	}, imports, nil
+}
+
+// externalField returns the exported field of the external struct that matches the attribute
+// with the given name.
+func externalField(t reflect.Type, att *design.AttributeDefinition, name string) (reflect.StructField, bool) {
+	if f, ok := att.Metadata["convert:field"]; ok && len(f) > 0 {
+		if f[0] == "-" {
+			return reflect.StructField{}, false
+		}
+		return t.FieldByName(f[0])
+	}
+	norm := func(s string) string { return strings.ToLower(strings.Replace(s, "_", "", -1)) }
+	fname := norm(codegen.GoifyAtt(att, name, true))
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		if norm(f.Name) == fname {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// conversionCode returns the code that assigns src to dst where src is the user type field and dst
+// the external field or vice versa if create is true.
+func conversionCode(ut *design.UserTypeDefinition, att *design.AttributeDefinition, name string, ext reflect.Type, create bool, src, dst string) (string, []*codegen.ImportSpec, error) {
+	extPtr := ext.Kind() == reflect.Ptr
+	if extPtr {
+		ext = ext.Elem()
+	}
+	if nested, ok := att.Type.(*design.UserTypeDefinition); ok {
+		return nestedConversionCode(nested, ext, extPtr, create, src, dst)
+	}
+	gtype := codegen.GoTypeDef(att, 0, false, false)
+	if !att.Type.IsPrimitive() {
+		if extPtr || ext.String() != gtype {
+			return "", nil, fmt.Errorf("cannot convert %s to %s", gtype, ext)
+		}
+		return fmt.Sprintf("\t%s = %s\n", dst, src), nil, nil
+	}
+	var conv string // name of type used to convert the values, empty if types are identical
+	if ext.String() != gtype {
+		if !convertible(att, ext) {
+			return "", nil, fmt.Errorf("cannot convert %s to %s", gtype, ext)
+		}
+		conv = ext.String()
+		if create {
+			conv = gtype
+		}
+	}
+	var imports []*codegen.ImportSpec
+	if conv != "" && !create && ext.PkgPath() != "" {
+		imports = append(imports, typeImport(ext))
+	}
+	srcPtr, dstPtr := ut.IsPrimitivePointer(name), extPtr
+	if create {
+		srcPtr, dstPtr = extPtr, ut.IsPrimitivePointer(name)
+	}
+	value := func(v string) string {
+		if conv == "" {
+			return v
+		}
+		return fmt.Sprintf("%s(%s)", conv, v)
+	}
+	switch {
+	case srcPtr && dstPtr && conv == "":
+		return fmt.Sprintf("\t%s = %s\n", dst, src), imports, nil
+	case srcPtr && dstPtr:
+		tmp := codegen.Tempvar()
+		return fmt.Sprintf("\tif %s != nil {\n\t\t%s := %s\n\t\t%s = &%s\n\t}\n", src, tmp, value("*"+src), dst, tmp), imports, nil
+	case srcPtr:
+		return fmt.Sprintf("\tif %s != nil {\n\t\t%s = %s\n\t}\n", src, dst, value("*"+src)), imports, nil
+	case dstPtr:
+		tmp := codegen.Tempvar()
+		return fmt.Sprintf("\t%s := %s\n\t%s = &%s\n", tmp, value(src), dst, tmp), imports, nil
+	default:
+		return fmt.Sprintf("\t%s = %s\n", dst, value(src)), imports, nil
+	}
+}
+
+// nestedConversionCode returns the code that converts a nested user type field by calling the
+// conversion method of the nested user type.
+func nestedConversionCode(nested *design.UserTypeDefinition, ext reflect.Type, extPtr, create bool, src, dst string) (string, []*codegen.ImportSpec, error) {
+	var conv *design.ConversionDefinition
+	for _, c := range nested.Conversions {
+		if c.External == ext && c.Create == create {
+			conv = c
+			break
+		}
+	}
+	if conv == nil {
+		verb := "ConvertTo"
+		if create {
+			verb = "CreateFrom"
+		}
+		return "", nil, fmt.Errorf("type %s does not define %s(%s)", nested.TypeName, verb, ext)
+	}
+	method := ConversionMethod(conv)
+	if create {
+		ref := "&" + src
+		if extPtr {
+			ref = src
+		}
+		code := fmt.Sprintf("\t%s = new(%s)\n\t%s.%s(%s)\n", dst, codegen.GoTypeName(nested, nil, 0, false), dst, method, ref)
+		if extPtr {
+			code = fmt.Sprintf("\tif %s != nil {\n%s\t}\n", src, strings.Replace(code, "\t", "\t\t", -1))
+		}
+		return code, nil, nil
+	}
+	deref := "*"
+	if extPtr {
+		deref = ""
+	}
+	return fmt.Sprintf("\tif %s != nil {\n\t\t%s = %s%s.%s()\n\t}\n", src, dst, deref, src, method), nil, nil
+}
+
+// convertible returns true if the values of the primitive attribute can be converted to values of
+// the given external type with a Go type conversion.
+func convertible(att *design.AttributeDefinition, ext reflect.Type) bool {
+	if _, ok := att.Metadata["struct:field:type"]; ok || att.Scalar() != "" {
+		return false
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return ext.Kind() == reflect.Bool
+	case design.StringKind:
+		return ext.Kind() == reflect.String
+	case design.IntegerKind, design.NumberKind:
+		switch ext.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+	}
+	return false
+}
+
+// typeImport returns the import of the package that defines the given named type.
+func typeImport(t reflect.Type) *codegen.ImportSpec {
+	name := t.String()
+	name = name[:strings.Index(name, ".")]
+	return codegen.NewImport(name, t.PkgPath())
+}
+
+// appendImport appends imp to imports unless an import with the same path is already listed.
+func appendImport(imports []*codegen.ImportSpec, imp *codegen.ImportSpec) []*codegen.ImportSpec {
+	for _, i := range imports {
+		if i.Path == imp.Path {
+			return imports
+		}
+	}
+	return append(imports, imp)
+}
@@ -0,0 +1,138 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FuzzTemplateData contains the information required to generate the fuzz target of an action.
+type FuzzTemplateData struct {
+	Name      string // Name of the fuzz target, e.g. "FuzzShowBottle"
+	Action    string // Action name
+	Resource  string // Resource name
+	Context   string // Name of the action context data structure
+	Unmarshal string // Name of the payload unmarshal function if any
+	Params    string // URL encoded example parameters used to seed the corpus
+	Headers   string // URL encoded example headers used to seed the corpus
+	Body      string // JSON encoded example payload used to seed the corpus
+}
+
+// generateFuzz generates the fuzz_test.go file that contains a fuzz target for each action. The
+// targets run the code generated to load the action contexts and to decode and validate the
+// payloads with arbitrary parameters, headers and bodies. The file requires Go 1.18 or later.
+func (g *Generator) generateFuzz() (err error) {
+	if len(g.API.Resources) == 0 {
+		return nil
+	}
+	fuzzTmpl := template.Must(template.New("fuzz").Parse(fuzzT))
+	filename := filepath.Join(g.OutDir, "fuzz_test.go")
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		g.queue(file, err)
+	}()
+	g.genfiles = append(g.genfiles, filename)
+	if _, err := file.Write([]byte("// +build go1.18\n\n")); err != nil {
+		return err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	title := fmt.Sprintf("%s: Fuzz Targets", g.API.Context())
+	if err := file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	return g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			data, err := fuzzData(g.API, a)
+			if err != nil {
+				return err
+			}
+			return fuzzTmpl.Execute(file, data)
+		})
+	})
+}
+
+// fuzzData builds the fuzz target template data of the given action.
+func fuzzData(api *design.APIDefinition, a *design.ActionDefinition) (*FuzzTemplateData, error) {
+	name := InterceptorMethod(a)
+	data := &FuzzTemplateData{
+		Name:     "Fuzz" + name,
+		Action:   a.Name,
+		Resource: a.Parent.Name,
+		Context:  name + "Context",
+	}
+	params := make(url.Values)
+	for n, att := range a.AllParams().Type.ToObject() {
+		ex := codegen.AttributeExample(api, att)
+		if ex == nil {
+			continue
+		}
+		if m := reflect.ValueOf(ex); att.Type.IsHash() && m.Kind() == reflect.Map {
+			for _, k := range m.MapKeys() {
+				params.Set(fmt.Sprintf("%s[%v]", n, k), fmt.Sprintf("%v", m.MapIndex(k)))
+			}
+			continue
+		}
+		params.Set(n, fmt.Sprintf("%v", ex))
+	}
+	data.Params = params.Encode()
+	headers := make(url.Values)
+	a.IterateHeaders(func(n string, _ bool, att *design.AttributeDefinition) error {
+		if ex := codegen.AttributeExample(api, att); ex != nil {
+			headers.Set(n, fmt.Sprintf("%v", ex))
+		}
+		return nil
+	})
+	data.Headers = headers.Encode()
+	if a.Payload != nil && !a.PayloadMultipart {
+		data.Unmarshal = "unmarshal" + name + "Payload"
+		if ex := codegen.AttributeExample(api, a.Payload.AttributeDefinition); ex != nil {
+			b, err := json.Marshal(ex)
+			if err != nil {
+				return nil, err
+			}
+			data.Body = string(b)
+		}
+	}
+	return data, nil
+}
+
+// fuzzT generates the fuzz target of an action.
+// template input: *FuzzTemplateData
+const fuzzT = `
+// {{ .Name }} loads the {{ .Action }} {{ .Resource }} context{{ if .Unmarshal }} and decodes the payload{{ end }} using
+// arbitrary parameters, headers and body. The parameters and headers are URL encoded.
+func {{ .Name }}(f *testing.F) {
+	f.Add({{ printf "%q" .Params }}, {{ printf "%q" .Headers }}, []byte({{ printf "%q" .Body }}))
+	service := goa.New("fuzz")
+	initService(service)
+	f.Fuzz(func(t *testing.T, params, headers string, body []byte) {
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		h, _ := url.ParseQuery(headers)
+		for n, vals := range h {
+			req.Header[http.CanonicalHeaderKey(n)] = vals
+		}
+		p, _ := url.ParseQuery(params)
+		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, p)
+		New{{ .Context }}(ctx, req, service)
+{{ if .Unmarshal }}		{{ .Unmarshal }}(ctx, service, req)
+{{ end }}	})
+}
+`
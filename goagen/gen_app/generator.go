@@ -1,18 +1,21 @@
 package genapp
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_swagger"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
-//NewGenerator returns an initialized instance of an Application Generator
+// NewGenerator returns an initialized instance of an Application Generator
 func NewGenerator(options ...Option) *Generator {
 	g := &Generator{}
 	g.validator = codegen.NewValidator()
@@ -30,7 +33,10 @@ type Generator struct {
 	OutDir    string                // Path to output directory
 	Target    string                // Name of generated package
 	NoTest    bool                  // Whether to skip test generation
+	Mocks     bool                  // Whether to generate the mocks package
+	Fuzz      bool                  // Whether to generate the fuzz targets
 	genfiles  []string              // Generated files
+	rendered  []*codegen.SourceFile // Rendered files waiting to be formatted, see queue
 	validator *codegen.Validator    // Validation code generator
 }
 
@@ -38,7 +44,7 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, target, ver string
-		notest, regen                bool
+		notest, mocks, fuzz, regen   bool
 	)
 
 	set := flag.NewFlagSet("app", flag.PanicOnError)
@@ -48,8 +54,12 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.StringVar(&toolDir, "tooldir", "tool", "")
 	set.BoolVar(&notest, "notest", false, "")
+	set.BoolVar(&mocks, "mocks", false, "")
+	set.BoolVar(&fuzz, "fuzz", false, "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("force", false, "")
+	set.Bool("openapi3", false, "")
+	set.Bool("metrics", false, "")
 	set.Parse(os.Args[1:])
 	outDir = filepath.Join(outDir, target)
 
@@ -58,7 +68,7 @@ func Generate() (files []string, err error) {
 	}
 
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, API: design.Design, validator: codegen.NewValidator()}
+	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, Mocks: mocks, Fuzz: fuzz, API: design.Design, validator: codegen.NewValidator()}
 
 	return g.Generate()
 }
@@ -71,15 +81,20 @@ func (g *Generator) Generate() (_ []string, err error) {
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
+	g.rendered = nil
 	defer func() {
 		if err != nil {
+			for _, f := range g.rendered {
+				f.Close()
+			}
+			g.rendered = nil
 			g.Cleanup()
 		}
 	}()
 
 	codegen.Reserved[g.Target] = true
 
-	os.RemoveAll(g.OutDir)
+	codegen.RemoveAll(g.OutDir)
 
 	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
 		return nil, err
@@ -94,6 +109,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateSecurity(); err != nil {
 		return nil, err
 	}
+	if err := g.generateInterceptors(); err != nil {
+		return nil, err
+	}
 	if err := g.generateHrefs(); err != nil {
 		return nil, err
 	}
@@ -103,15 +121,50 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateUserTypes(); err != nil {
 		return nil, err
 	}
+	if err := g.generateConversions(); err != nil {
+		return nil, err
+	}
+	if g.API.UsesProtobuf() {
+		if err := g.generateProto(); err != nil {
+			return nil, err
+		}
+	}
 	if !g.NoTest {
 		if err := g.generateResourceTest(); err != nil {
 			return nil, err
 		}
 	}
+	if g.Mocks {
+		if err := g.generateMocks(); err != nil {
+			return nil, err
+		}
+	}
+	if g.Fuzz {
+		if err := g.generateFuzz(); err != nil {
+			return nil, err
+		}
+	}
+	files := g.rendered
+	g.rendered = nil
+	if err := codegen.FormatFiles(files); err != nil {
+		return nil, err
+	}
 
 	return g.genfiles, nil
 }
 
+// queue queues the given rendered file so that it gets formatted and written together with the
+// other files once they are all rendered, the file is closed instead if rendering failed. The
+// templates make use of global state (see codegen.Tempvar) so that the files must be rendered one
+// after the other, formatting them is what takes most of the time and runs concurrently.
+func (g *Generator) queue(file *codegen.SourceFile, err error) {
+	if err != nil {
+		file.Close()
+		return
+	}
+	g.rendered = append(g.rendered, file)
+}
+
 // Cleanup removes the entire "app" directory if it was created by this generator.
 func (g *Generator) Cleanup() {
 	if len(g.genfiles) == 0 {
@@ -136,15 +189,16 @@ func (g *Generator) generateContexts() (err error) {
 		}
 	}
 	defer func() {
-		ctxWr.Close()
-		if err == nil {
-			err = ctxWr.FormatCode()
-		}
+		g.queue(ctxWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
@@ -152,12 +206,18 @@ func (g *Generator) generateContexts() (err error) {
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("golang.org/x/net/websocket"),
 	}
 	g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
 			if a.Payload != nil {
 				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
 			}
+			for _, att := range []*design.AttributeDefinition{a.AllParams(), r.Headers, a.Headers, a.Cookies} {
+				if att != nil {
+					imports = codegen.AttributeImports(att, imports, nil)
+				}
+			}
 			return nil
 		})
 	})
@@ -188,24 +248,53 @@ func (g *Generator) generateContexts() (err error) {
 				params = nil // So that {{if .Params}} returns false in templates
 			}
 
+			cookies := a.Cookies
+			if cookies != nil && len(cookies.Type.ToObject()) == 0 {
+				cookies = nil // So that {{if .Cookies}} returns false in templates
+			}
+
 			non101 := make(map[string]*design.ResponseDefinition)
+			var respCookies *design.AttributeDefinition
 			for k, v := range a.Responses {
 				if v.Status != 101 {
 					non101[k] = v
 				}
+				if v.Cookies != nil && len(v.Cookies.Type.ToObject()) > 0 {
+					if respCookies == nil {
+						respCookies = &design.AttributeDefinition{Type: design.Object{}}
+					}
+					respCookies.Merge(v.Cookies)
+				}
 			}
 			ctxData := ContextTemplateData{
-				Name:         ctxName,
-				ResourceName: r.Name,
-				ActionName:   a.Name,
-				Payload:      a.Payload,
-				Params:       params,
-				Headers:      headers,
-				Routes:       a.Routes,
-				Responses:    non101,
-				API:          g.API,
-				DefaultPkg:   g.Target,
-				Security:     a.Security,
+				Name:             ctxName,
+				ResourceName:     r.Name,
+				ActionName:       a.Name,
+				Payload:          a.Payload,
+				Params:           params,
+				Headers:          headers,
+				Cookies:          cookies,
+				ResponseCookies:  respCookies,
+				Routes:           a.Routes,
+				Responses:        non101,
+				StreamingPayload: a.StreamingPayload,
+				StreamingResult:  a.StreamingResult,
+				ServerSentEvents: a.ServerSentEvents,
+				NDJSON:           a.NDJSON,
+				KeepAlive:        a.KeepAlive,
+				MaxBodyLength:    a.EffectiveMaxBodyLength(),
+				SkipRequestBody:  a.SkipRequestBodyEncodeDecode,
+				SkipResponseBody: a.SkipResponseBodyEncodeDecode,
+				Pagination:       a.Pagination,
+				IfMatch:          a.IfMatch,
+				Async:            a.Async,
+				API:              g.API,
+				DefaultPkg:       g.Target,
+				Security:         a.Security,
+				Errors:           a.AllErrors(),
+				Interceptors:     a.AllInterceptors(),
+				PayloadExample:   payloadExample(g.API, a),
+				Endpoint:         actionEndpoint(a),
 			}
 			return ctxWr.Execute(&ctxData)
 		})
@@ -228,19 +317,24 @@ func (g *Generator) generateControllers() (err error) {
 		}
 	}
 	defer func() {
-		ctlWr.Close()
-		if err == nil {
-			err = ctlWr.FormatCode()
-		}
+		g.queue(ctlWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application Controllers", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("path"),
+		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
 		codegen.SimpleImport("regexp"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("mime/multipart"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
 	encoders, err := BuildEncoders(g.API.Produces, true)
 	if err != nil {
@@ -267,12 +361,36 @@ func (g *Generator) generateControllers() (err error) {
 	for _, packagePath := range packagePaths {
 		imports = append(imports, codegen.SimpleImport(packagePath))
 	}
+	if g.API.HealthCheck != nil {
+		imports = append(imports, codegen.SimpleImport("github.com/goadesign/goa/health"))
+	}
+	if g.API.Docs != nil && g.API.Docs.OpenAPIPath != "" {
+		imports = append(imports, codegen.SimpleImport("github.com/goadesign/goa/apidocs"))
+	}
 	if err = ctlWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
 	if err = ctlWr.WriteInitService(encoders, decoders); err != nil {
 		return err
 	}
+	if g.API.HealthCheck != nil {
+		if err = ctlWr.WriteHealthCheck(g.API.HealthCheck); err != nil {
+			return err
+		}
+	}
+	if g.API.Docs != nil && g.API.Docs.OpenAPIPath != "" {
+		spec, err := genswagger.NewV3(g.API)
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(spec)
+		if err != nil {
+			return err
+		}
+		if err = ctlWr.WriteDocs(g.API.Docs, raw); err != nil {
+			return err
+		}
+	}
 
 	g.genfiles = append(g.genfiles, ctlFile)
 	var controllersData []*ControllerTemplateData
@@ -284,38 +402,85 @@ func (g *Generator) generateControllers() (err error) {
 				rpath := design.WildcardRegex.ReplaceAllLiteralString(fs.RequestPath, "")
 				rpath += "/"
 				fileServers = append(fileServers, &design.FileServerDefinition{
-					Parent:      fs.Parent,
-					Description: fs.Description,
-					Docs:        fs.Docs,
-					FilePath:    filepath.Join(fs.FilePath, "index.html"),
-					RequestPath: rpath,
-					Metadata:    fs.Metadata,
-					Security:    fs.Security,
+					Parent:       fs.Parent,
+					Description:  fs.Description,
+					Docs:         fs.Docs,
+					FilePath:     filepath.Join(fs.FilePath, "index.html"),
+					RequestPath:  rpath,
+					Metadata:     fs.Metadata,
+					Security:     fs.Security,
+					CacheControl: fs.CacheControl,
+					MaxAge:       fs.MaxAge,
 				})
 			}
 		}
 		data := &ControllerTemplateData{
-			API:            g.API,
-			Resource:       codegen.Goify(r.Name, true),
-			PreflightPaths: r.PreflightPaths(),
-			FileServers:    fileServers,
+			API:         g.API,
+			Resource:    codegen.Goify(r.Name, true),
+			FileServers: fileServers,
 		}
+		// Preflight requests sent to paths of actions that define their own CORS policies
+		// are handled using these policies.
+		actionPreflight := make(map[string]bool)
 		r.IterateActions(func(a *design.ActionDefinition) error {
+			var origins []*design.CORSDefinition
+			var preflightPaths []string
+			if len(a.Origins) > 0 {
+				origins = a.AllOrigins()
+				for _, route := range a.Routes {
+					if route.Verb == "OPTIONS" {
+						continue
+					}
+					for _, fp := range route.VersionedPaths() {
+						if actionPreflight[fp] {
+							continue
+						}
+						actionPreflight[fp] = true
+						preflightPaths = append(preflightPaths, fp)
+					}
+				}
+			}
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			unmarshal := fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
 			action := map[string]interface{}{
-				"Name":            codegen.Goify(a.Name, true),
-				"DesignName":      a.Name,
-				"Routes":          a.Routes,
-				"Context":         context,
-				"Unmarshal":       unmarshal,
-				"Payload":         a.Payload,
-				"PayloadOptional": a.PayloadOptional,
-				"Security":        a.Security,
+				"Name":             codegen.Goify(a.Name, true),
+				"DesignName":       a.Name,
+				"Routes":           a.Routes,
+				"Context":          context,
+				"Unmarshal":        unmarshal,
+				"Payload":          a.Payload,
+				"PayloadOptional":  a.PayloadOptional,
+				"Security":         a.Security,
+				"RateLimit":        a.EffectiveRateLimit(),
+				"Timeout":          a.Timeout,
+				"MaxBodyLength":    a.EffectiveMaxBodyLength(),
+				"PayloadMultipart": a.PayloadMultipart,
+				"Origins":          origins,
+				"PreflightPaths":   preflightPaths,
+			}
+			if username, password := a.BasicAuthCredentials(); username != "" && password != "" {
+				obj := a.Payload.ToObject()
+				action["Username"] = codegen.GoifyAtt(obj[username], username, true)
+				action["Password"] = codegen.GoifyAtt(obj[password], password, true)
+				action["Realm"] = a.Security.Scheme.SchemeName
+			}
+			if len(a.AllInterceptors()) > 0 {
+				action["Interceptors"] = actionInterceptors(a)
+			}
+			if endpoint := actionEndpoint(a); endpoint != nil {
+				action["Endpoint"] = endpoint
+			}
+			if versions := a.EffectiveVersions(); len(versions) > 0 {
+				action["Mounts"] = versionedMounts(a, versions)
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
 		})
+		for _, p := range r.PreflightPaths() {
+			if !actionPreflight[p] {
+				data.PreflightPaths = append(data.PreflightPaths, p)
+			}
+		}
 		if len(data.Actions) > 0 || len(data.FileServers) > 0 {
 			data.Encoders = encoders
 			data.Decoders = decoders
@@ -324,10 +489,104 @@ func (g *Generator) generateControllers() (err error) {
 		}
 		return nil
 	})
-	err = ctlWr.Execute(controllersData)
+	if err = ctlWr.Execute(controllersData); err != nil {
+		return
+	}
+	if len(controllersData) > 0 {
+		err = ctlWr.WriteRoutes(controllersData)
+	}
 	return
 }
 
+// actionEndpoint returns the data needed to render the transport independent endpoint of the given
+// action. It returns nil if the action is bound to HTTP: the action streams, does not encode or
+// decode its bodies, uses interceptors or its success response cannot be sent from a value.
+func actionEndpoint(a *design.ActionDefinition) *EndpointTemplateData {
+	if a.StreamingPayload != nil || a.StreamingResult != nil || a.ServerSentEvents || a.NDJSON ||
+		a.SkipRequestBodyEncodeDecode || a.SkipResponseBodyEncodeDecode || len(a.AllInterceptors()) > 0 {
+		return nil
+	}
+	var resp *design.ResponseDefinition
+	for _, r := range a.Responses {
+		if r.Status >= 200 && r.Status < 300 && (resp == nil || r.Status < resp.Status) {
+			resp = r
+		}
+	}
+	if resp == nil || resp.RedirectURL != "" {
+		return nil
+	}
+	data := &EndpointTemplateData{
+		Request:   fmt.Sprintf("%s%sRequest", codegen.Goify(a.Name, true), codegen.Goify(a.Parent.Name, true)),
+		Responder: codegen.Goify(resp.Name, true),
+	}
+	mt, ok := resp.Type.(*design.MediaTypeDefinition)
+	if resp.Type != nil && !ok {
+		data.Result = codegen.GoTypeRef(resp.Type, nil, 0, false)
+		return data
+	}
+	if resp.Type == nil {
+		mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
+	}
+	if mt == nil {
+		if resp.MediaType != "" {
+			data.Result = "[]byte"
+		}
+		return data
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = "default"
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return nil
+	}
+	if view != "default" {
+		data.Responder = codegen.Goify(fmt.Sprintf("%s%s", resp.Name, strings.Title(view)), true)
+	}
+	data.Result = codegen.GoTypeRef(projected, projected.AllRequired(), 0, false)
+	return data
+}
+
+// payloadExample returns the JSON representation of the example of the action payload. It returns
+// the empty string if the action has no payload or if the example cannot be represented in JSON.
+func payloadExample(api *design.APIDefinition, a *design.ActionDefinition) string {
+	if a.Payload == nil {
+		return ""
+	}
+	ex := codegen.AttributeExample(api, a.Payload.AttributeDefinition)
+	if ex == nil {
+		return ""
+	}
+	js, err := json.Marshal(ex)
+	if err != nil {
+		return ""
+	}
+	return string(js)
+}
+
+// examplePayloadFunc returns the name of the function that builds the payload example of the
+// action whose context type has the given name.
+func examplePayloadFunc(ctxName string) string {
+	return "NewExample" + strings.TrimSuffix(ctxName, "Context") + "Payload"
+}
+
+// versionedMounts returns the mux registrations of the handler of an action of a versioned API:
+// the handler is mounted once per route and API version that serves the action.
+func versionedMounts(a *design.ActionDefinition, versions []string) []map[string]interface{} {
+	var res []map[string]interface{}
+	for _, r := range a.Routes {
+		for _, v := range versions {
+			mux := "service.Mux"
+			if design.Design.Versioning.Scheme != design.VersionSchemePath {
+				mux = fmt.Sprintf("versionMux(service, %q)", v)
+			}
+			res = append(res, map[string]interface{}{"Mux": mux, "Verb": r.Verb, "Path": r.VersionedPath(v), "Version": v})
+		}
+	}
+	return res
+}
+
 // generateControllers iterates through the API resources and generates the low level
 // controllers.
 func (g *Generator) generateSecurity() (err error) {
@@ -347,10 +606,7 @@ func (g *Generator) generateSecurity() (err error) {
 		}
 	}
 	defer func() {
-		secWr.Close()
-		if err == nil {
-			err = secWr.FormatCode()
-		}
+		g.queue(secWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application Security", g.API.Context())
 	imports := []*codegen.ImportSpec{
@@ -368,6 +624,37 @@ func (g *Generator) generateSecurity() (err error) {
 	return
 }
 
+// generateInterceptors generates the interfaces of the interceptors used by the API actions.
+func (g *Generator) generateInterceptors() (err error) {
+	data := BuildInterceptors(g.API)
+	if len(data) == 0 {
+		return nil
+	}
+
+	var (
+		intFile string
+		intWr   *InterceptorsWriter
+	)
+	{
+		intFile = filepath.Join(g.OutDir, "interceptors.go")
+		intWr, err = NewInterceptorsWriter(intFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		g.queue(intWr.SourceFile, err)
+	}()
+	title := fmt.Sprintf("%s: Application Interceptors", g.API.Context())
+	if err = intWr.WriteHeader(title, g.Target, nil); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, intFile)
+	err = intWr.Execute(data)
+
+	return
+}
+
 // generateHrefs iterates through the API resources and generates the href factory methods.
 func (g *Generator) generateHrefs() (err error) {
 	var (
@@ -382,10 +669,7 @@ func (g *Generator) generateHrefs() (err error) {
 		}
 	}
 	defer func() {
-		resWr.Close()
-		if err == nil {
-			err = resWr.FormatCode()
-		}
+		g.queue(resWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application Resource Href Factories", g.API.Context())
 	imports := []*codegen.ImportSpec{
@@ -432,10 +716,7 @@ func (g *Generator) generateMediaTypes() (err error) {
 		}
 	}
 	defer func() {
-		mtWr.Close()
-		if err == nil {
-			err = mtWr.FormatCode()
-		}
+		g.queue(mtWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
@@ -479,14 +760,14 @@ func (g *Generator) generateUserTypes() (err error) {
 		}
 	}
 	defer func() {
-		utWr.Close()
-		if err == nil {
-			err = utWr.FormatCode()
-		}
+		g.queue(utWr.SourceFile, err)
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
@@ -504,3 +785,51 @@ func (g *Generator) generateUserTypes() (err error) {
 	})
 	return
 }
+
+// generateConversions generates the methods that convert the user types to and from the external
+// Go types listed in the design with ConvertTo and CreateFrom.
+func (g *Generator) generateConversions() (err error) {
+	data, imports, err := BuildConversions(g.API)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+
+	var (
+		convFile string
+		convWr   *ConversionsWriter
+	)
+	{
+		convFile = filepath.Join(g.OutDir, "conversions.go")
+		convWr, err = NewConversionsWriter(convFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		g.queue(convWr.SourceFile, err)
+	}()
+	title := fmt.Sprintf("%s: Application User Type Conversions", g.API.Context())
+	if err = convWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, convFile)
+	err = convWr.Execute(data)
+
+	return
+}
+
+// generateProto generates the .proto file describing the messages encoded by the protobuf
+// encoder for the APIs that consume or produce Protocol Buffers.
+func (g *Generator) generateProto() error {
+	p, err := newProtoFile(g.API)
+	if err != nil {
+		return err
+	}
+	protoFile := filepath.Join(g.OutDir, codegen.SnakeCase(codegen.Goify(g.API.Name, true))+".proto")
+	title := fmt.Sprintf("%s: Application Protocol Buffers Messages", g.API.Context())
+	if err := codegen.WriteFile(protoFile, p.Bytes(protoHeader(title), g.Target)); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, protoFile)
+	return nil
+}
@@ -3,12 +3,14 @@ package genapp_test
 import (
 	"bytes"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_app"
@@ -17,6 +19,10 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// dslDesign is the API definition registered with the DSL engine, the tests that do not use
+// the DSL replace design.Design.
+var dslDesign = design.Design
+
 var _ = Describe("Generate", func() {
 	var workspace *codegen.Workspace
 	var outDir string
@@ -69,6 +75,778 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an API that produces Protocol Buffers", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.Consumes("application/json", "application/x-protobuf")
+				apidsl.Produces("application/json", "application/x-protobuf")
+			})
+			widget := apidsl.MediaType("application/vnd.widget", func() {
+				apidsl.Description("A widget")
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+					apidsl.Attribute("name", design.String, func() {
+						apidsl.Metadata("struct:tag:protobuf", "10")
+					})
+					apidsl.Attribute("tags", apidsl.ArrayOf(design.String))
+					apidsl.Attribute("labels", apidsl.HashOf(design.String, design.Any))
+					apidsl.Attribute("size", func() {
+						apidsl.Attribute("height", design.Number)
+					})
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+					apidsl.Attribute("name")
+					apidsl.Attribute("tags")
+					apidsl.Attribute("labels")
+					apidsl.Attribute("size")
+				})
+			})
+			apidsl.Resource("widget", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(design.OK, apidsl.CollectionOf(widget))
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the .proto file describing the messages", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "testapi.proto"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(protoMessages))
+		})
+	})
+
+	Context("with interceptors", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			bottle := apidsl.MediaType("application/vnd.bottle", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("id", design.Integer)
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("id")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Interceptor("audit")
+				apidsl.Action("show", func() {
+					apidsl.Interceptor("cache")
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.OK, bottle)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		read := func(name string) string {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", name))
+			Ω(err).ShouldNot(HaveOccurred())
+			return string(content)
+		}
+
+		It("generates the interceptor interfaces", func() {
+			Ω(genErr).Should(BeNil())
+			code := read("interceptors.go")
+			Ω(code).Should(ContainSubstring("type AuditInterceptor interface {"))
+			Ω(code).Should(ContainSubstring("\tShowBottle(ctx *ShowBottleContext, next func() error) error\n"))
+			Ω(code).Should(ContainSubstring("func UseCacheInterceptor(i CacheInterceptor) {"))
+		})
+
+		It("runs the interceptors around the action in order", func() {
+			Ω(read("controllers.go")).Should(ContainSubstring(`			next := func() error { return ctrl.Show(rctx) }
+			if cacheInterceptor != nil {
+				n := next
+				next = func() error { return cacheInterceptor.ShowBottle(rctx, n) }
+			}
+			if auditInterceptor != nil {
+				n := next
+				next = func() error { return auditInterceptor.ShowBottle(rctx, n) }
+			}
+			return nil, next()`))
+		})
+
+		It("records the action result in the context", func() {
+			code := read("contexts.go")
+			Ω(code).Should(ContainSubstring("\tResult interface{}\n"))
+			Ω(code).Should(ContainSubstring("\tctx.Result = r\n"))
+		})
+	})
+
+	Context("with a payload example", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/"))
+					apidsl.Payload(func() {
+						apidsl.Member("name", design.String, func() {
+							apidsl.Example("Number 8")
+						})
+						apidsl.Member("vintage", design.Integer, func() {
+							apidsl.NoExample()
+						})
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the payload example constructor", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("func NewExampleCreateBottlePayload() *CreateBottlePayload {"))
+			Ω(code).Should(ContainSubstring(`json.Unmarshal([]byte("{\"name\":\"Number 8\"}"), &payload)`))
+		})
+	})
+
+	Context("with mocks", func() {
+		BeforeEach(func() {
+			os.Args = append(os.Args, "--mocks")
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer)
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the mock controllers", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "mocks", "bottle_mock.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "mocks", "bottle_mock.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("package mocks"))
+			Ω(code).Should(ContainSubstring("\tShowFunc func(*app.ShowBottleContext) error\n"))
+			Ω(code).Should(ContainSubstring("func NewBottleController(service *goa.Service) *BottleController {"))
+			Ω(code).Should(ContainSubstring(`return fmt.Errorf("unexpected call to BottleController.Show")`))
+			Ω(code).Should(ContainSubstring("func (m *BottleController) ShowCalls() []*app.ShowBottleContext {"))
+		})
+	})
+
+	Context("with fuzz targets", func() {
+		BeforeEach(func() {
+			os.Args = append(os.Args, "--fuzz")
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST("/:id"))
+					apidsl.Params(func() {
+						apidsl.Param("id", design.Integer, func() {
+							apidsl.Example(1)
+						})
+					})
+					apidsl.Payload(func() {
+						apidsl.Member("name", design.String, func() {
+							apidsl.Example("Number 8")
+						})
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the fuzz targets", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "fuzz_test.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "fuzz_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("// +build go1.18"))
+			Ω(code).Should(ContainSubstring("func FuzzCreateBottle(f *testing.F) {"))
+			Ω(code).Should(ContainSubstring(`f.Add("id=1", "", []byte("{\"name\":\"Number 8\"}"))`))
+			Ω(code).Should(ContainSubstring("NewCreateBottleContext(ctx, req, service)"))
+			Ω(code).Should(ContainSubstring("unmarshalCreateBottlePayload(ctx, service, req)"))
+		})
+	})
+
+	Context("with custom scalar parameters", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			money := apidsl.Scalar("Money", "big.Float", "math/big")
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("price", money)
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("decodes the parameters with the scalar Go type", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring(`"math/big"`))
+			Ω(code).Should(ContainSubstring("Price *big.Float"))
+			Ω(code).Should(ContainSubstring("UnmarshalText([]byte(rawPrice))"))
+			Ω(code).Should(ContainSubstring(`goa.InvalidParamTypeError("price", rawPrice, "Money")`))
+		})
+	})
+
+	Context("with formatted parameters", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			job := apidsl.MediaType("application/vnd.job", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("elapsed", design.String, func() {
+						apidsl.Format(apidsl.FormatDuration)
+					})
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("elapsed")
+				})
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("since", design.String, func() {
+							apidsl.Format(apidsl.FormatDateTime)
+						})
+						apidsl.Param("timeout", design.String, func() {
+							apidsl.Format(apidsl.FormatDuration)
+							apidsl.Default("30s")
+						})
+						apidsl.Param("origin", design.String, func() {
+							apidsl.Format(apidsl.FormatIP)
+						})
+					})
+					apidsl.Response(design.NoContent)
+				})
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(func() {
+						apidsl.Attribute("ttl", design.String, func() {
+							apidsl.Format(apidsl.FormatDuration)
+							apidsl.Default("1h")
+						})
+					})
+					apidsl.Response(design.OK, job)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("decodes the parameters into the format Go types", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("Since   *time.Time"))
+			Ω(code).Should(ContainSubstring("Timeout goa.Duration"))
+			Ω(code).Should(ContainSubstring("Origin  *net.IP"))
+			Ω(code).Should(ContainSubstring("rctx.Timeout = goa.Duration(30 * time.Second)"))
+			Ω(code).Should(ContainSubstring("UnmarshalText([]byte(rawTimeout))"))
+			Ω(code).Should(ContainSubstring("UnmarshalText([]byte(rawOrigin))"))
+			Ω(code).ShouldNot(ContainSubstring("goa.FormatDuration"))
+		})
+
+		It("decodes the payload and media type durations into goa.Duration", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("TTL *goa.Duration `form:\"ttl,omitempty\" json:\"ttl,omitempty\""))
+			Ω(code).Should(ContainSubstring("TTL goa.Duration `form:\"ttl\" json:\"ttl\""))
+			Ω(code).Should(ContainSubstring("var defaultTTL = goa.Duration(time.Hour)"))
+			Ω(code).ShouldNot(ContainSubstring("goa.FormatDuration"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("Elapsed *goa.Duration"))
+		})
+	})
+
+	Context("with map parameters", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Params(func() {
+						apidsl.Param("ratings", apidsl.HashOf(design.Integer, design.Integer, func() {
+							apidsl.Minimum(1)
+						}, func() {
+							apidsl.Maximum(5)
+						}))
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("decodes the query string map syntax", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("Ratings map[int]int"))
+			Ω(code).Should(ContainSubstring(`paramRatings := goa.MapParamValues(req.Params, "ratings")`))
+			Ω(code).Should(ContainSubstring("for rawRatingsKey, rawRatings := range paramRatings {"))
+			Ω(code).Should(ContainSubstring(`goa.InvalidParamTypeError("ratings[key]", rawRatingsKey, "integer")`))
+			Ω(code).Should(ContainSubstring("params[k] = v"))
+			Ω(code).Should(ContainSubstring("for k, e := range rctx.Ratings {"))
+			Ω(code).Should(ContainSubstring("if k < 1 {"))
+			Ω(code).Should(ContainSubstring("if e > 5 {"))
+		})
+	})
+
+	Context("with a recursive payload", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			comment := apidsl.Type("Comment", func() {
+				apidsl.Attribute("body", design.String, func() {
+					apidsl.MinLength(1)
+				})
+				apidsl.Attribute("votes", design.Integer, func() {
+					apidsl.Default(0)
+				})
+				apidsl.Attribute("replies", apidsl.ArrayOf("Comment"))
+				apidsl.Required("body")
+			})
+			apidsl.API("test api", nil)
+			apidsl.Resource("comment", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(comment)
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates self-referencing types", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(MatchRegexp(`Replies\s+\[\]\*comment`))
+			Ω(code).Should(MatchRegexp(`Replies\s+\[\]\*Comment`))
+			Ω(code).Should(ContainSubstring("for _, e := range ut.Replies {"))
+			Ω(code).Should(ContainSubstring("e.Validate()"))
+			Ω(code).Should(ContainSubstring("e.Finalize()"))
+		})
+	})
+
+	Context("with an optional non-pointer enum", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			bottle := apidsl.Type("Bottle", func() {
+				apidsl.Attribute("color", design.String, func() {
+					apidsl.Metadata("struct:field:pointer", "false")
+					apidsl.Enum("red", "white")
+				})
+			})
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(bottle)
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("does not validate the zero value", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(MatchRegexp(`Color\s+string`))
+			Ω(code).Should(ContainSubstring(`if ut.Color != "" {
+		if !(ut.Color == "red" || ut.Color == "white") {`))
+			Ω(code).Should(ContainSubstring("if ut.Color != nil {"))
+		})
+	})
+
+	Context("with type conversions", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			user := apidsl.Type("User", func() {
+				apidsl.Attribute("name", design.String)
+				apidsl.Attribute("age", design.Integer)
+				apidsl.Attribute("email", design.String)
+				apidsl.Attribute("password", design.String, func() {
+					apidsl.Metadata("convert:field", "-")
+				})
+				apidsl.Required("name")
+				apidsl.ConvertTo(ExternalUser{})
+				apidsl.CreateFrom(&ExternalUser{})
+			})
+			apidsl.API("test api", nil)
+			apidsl.Resource("user", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(user)
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the conversion methods", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "conversions.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("func (ut *User) ConvertToGenappTestExternalUser() *genapp_test.ExternalUser {"))
+			Ω(code).Should(ContainSubstring("t.Age = int64(*ut.Age)"))
+			Ω(code).Should(ContainSubstring("t.Email = ut.Email"))
+			Ω(code).Should(ContainSubstring("t.Name = ut.Name"))
+			Ω(code).Should(ContainSubstring("func (ut *User) CreateFromGenappTestExternalUser(v *genapp_test.ExternalUser) {"))
+			Ω(code).Should(MatchRegexp(`(tmp\d+) := int\(v\.Age\)\s+ut\.Age = &(tmp\d+)`))
+			Ω(code).Should(ContainSubstring("ut.Name = v.Name"))
+			Ω(code).ShouldNot(ContainSubstring("Password"))
+		})
+	})
+
+	Context("with conversions to types with the same name", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			link := apidsl.Type("Link", func() {
+				apidsl.Attribute("host", design.String)
+				apidsl.ConvertTo(url.URL{})
+				apidsl.ConvertTo(URL{})
+			})
+			apidsl.API("test api", nil)
+			apidsl.Resource("link", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(link)
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("qualifies the conversion methods with the package names", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "conversions.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("func (ut *Link) ConvertToURLURL() *url.URL {"))
+			Ω(code).Should(ContainSubstring("func (ut *Link) ConvertToGenappTestURL() *genapp_test.URL {"))
+		})
+	})
+
+	Context("with a union payload", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			dog := apidsl.Type("Dog", func() {
+				apidsl.Attribute("name", design.String)
+				apidsl.Required("name")
+			})
+			cat := apidsl.Type("Cat", func() {
+				apidsl.Attribute("lives", design.Integer, func() {
+					apidsl.Default(9)
+				})
+			})
+			pet := apidsl.OneOf("Pet", func() {
+				apidsl.Discriminator("kind")
+				apidsl.Variant("dog", dog)
+				apidsl.Variant("cat", cat)
+			})
+			apidsl.API("test api", nil)
+			apidsl.Resource("pet", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Payload(pet)
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the sum types", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("type Pet struct {"))
+			Ω(code).Should(ContainSubstring("Value PetVariant"))
+			Ω(code).Should(ContainSubstring("func (*Dog) isPetVariant() {}"))
+			Ω(code).Should(ContainSubstring("func (*Cat) isPetVariant() {}"))
+			Ω(code).Should(ContainSubstring(`tag = "\"kind\":\"dog\""`))
+			Ω(code).Should(ContainSubstring("func (ut *Pet) UnmarshalJSON(data []byte) error {"))
+			Ω(code).Should(ContainSubstring(`goa.InvalidEnumValueError("request.kind", *disc.Value, []interface{}{"dog", "cat"})`))
+			Ω(code).Should(ContainSubstring("func (ut *pet) Finalize() {"))
+		})
+
+		It("validates the decoded payload", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("payload := &pet{}"))
+			Ω(code).Should(ContainSubstring("payload.Finalize()"))
+			Ω(code).Should(ContainSubstring("payload.Validate()"))
+		})
+	})
+
+	Context("with basic auth credentials in the payload", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", func() {})
+			basic := apidsl.BasicAuthSecurity("password")
+			apidsl.Resource("session", func() {
+				apidsl.Action("login", func() {
+					apidsl.Security(basic)
+					apidsl.Routing(apidsl.POST("/login"))
+					apidsl.Payload(func() {
+						apidsl.Username("user")
+						apidsl.Password("pass")
+						apidsl.Attribute("remember", design.Boolean)
+						apidsl.Required("user", "pass")
+					})
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("sets the payload attributes from the Authorization header", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring("user, pass, ok := req.BasicAuth()"))
+			Ω(code).Should(ContainSubstring(`goa.ContextResponse(ctx).Header().Set("WWW-Authenticate", "Basic realm=\"password\"")`))
+			Ω(code).Should(ContainSubstring(`return goa.ErrUnauthorized("missing basic auth credentials")`))
+			Ω(code).Should(ContainSubstring("payload.User = &user"))
+			Ω(code).Should(ContainSubstring("payload.Pass = &pass"))
+			Ω(code).Should(ContainSubstring("if err := unmarshalLoginSessionPayload(ctx, service, req); err != nil {"))
+		})
+	})
+
+	Context("with a versioned API", func() {
+		// define defines a versioned API using the given DSL to select the versioning scheme.
+		define := func(scheme func()) {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.BasePath("/api")
+				apidsl.Versions("v1", "v2")
+				scheme()
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("rate", func() {
+					apidsl.Versions("v2")
+					apidsl.Routing(apidsl.PUT("/:id/rating"))
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			define(func() {})
+		})
+
+		controllers := func() string {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			return string(content)
+		}
+
+		It("mounts the actions under the version path prefixes", func() {
+			Ω(genErr).Should(BeNil())
+			code := controllers()
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("GET", prefix+"/api/v1/bottles/:id", ctrl.MuxHandler("show", h, nil))`))
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("GET", prefix+"/api/v2/bottles/:id", ctrl.MuxHandler("show", h, nil))`))
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("PUT", prefix+"/api/v2/bottles/:id/rating", ctrl.MuxHandler("rate", h, nil))`))
+			Ω(code).ShouldNot(ContainSubstring(`/api/v1/bottles/:id/rating`))
+			Ω(code).ShouldNot(ContainSubstring("func versionMux"))
+		})
+
+		It("generates a single controller interface", func() {
+			Ω(controllers()).Should(ContainSubstring("type BottleController interface {\n\tgoa.Muxer\n\tRate(*RateBottleContext) error\n\tShow(*ShowBottleContext) error\n}"))
+		})
+
+		Context("using a header to select the version", func() {
+			BeforeEach(func() {
+				define(func() {
+					apidsl.VersionHeader("X-API-Version")
+				})
+			})
+
+			It("mounts the actions on the version muxes", func() {
+				Ω(genErr).Should(BeNil())
+				code := controllers()
+				Ω(code).Should(ContainSubstring(`goa.NewVersionMux(service.Mux, goa.HeaderSelectVersionFunc("X-API-Version"), goa.HeaderEncodeVersionFunc("X-API-Version"))`))
+				Ω(code).Should(ContainSubstring(`versionMux(service, "v1").Handle("GET", prefix+"/api/bottles/:id", ctrl.MuxHandler("show", h, nil))`))
+				Ω(code).Should(ContainSubstring(`versionMux(service, "v2").Handle("GET", prefix+"/api/bottles/:id", ctrl.MuxHandler("show", h, nil))`))
+				Ω(code).Should(ContainSubstring(`versionMux(service, "v2").Handle("PUT", prefix+"/api/bottles/:id/rating", ctrl.MuxHandler("rate", h, nil))`))
+				Ω(code).ShouldNot(ContainSubstring(`versionMux(service, "v1").Handle("PUT"`))
+			})
+		})
+	})
+
+	Context("with routing options", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.TrailingSlash("absorb")
+				apidsl.CaseInsensitiveRouting()
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("configures the service mux", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`service.SetRouting("absorb", true)`))
+		})
+	})
+
+	Context("with GET and HEAD routes", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", func() {
+				apidsl.MethodOverride()
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("count", func() {
+					apidsl.Routing(apidsl.HEAD("/bottles"))
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("mounts the GET handlers on HEAD requests unless a HEAD route is defined", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("HEAD", prefix+"/bottles/:id", goa.HeadHandler(ctrl.MuxHandler("show", h, nil)))`))
+			Ω(code).ShouldNot(ContainSubstring(`goa.HeadHandler(ctrl.MuxHandler("list", h, nil))`))
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("HEAD", prefix+"/bottles", ctrl.MuxHandler("count", h, nil))`))
+			Ω(code).Should(ContainSubstring(`service.SetMethodOverride(true)`))
+		})
+	})
+
+	Context("with a multipart form payload", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("user", func() {
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.POST("/upload"))
+					apidsl.MultipartForm()
+					apidsl.Payload(func() {
+						apidsl.Member("avatar", design.File)
+						apidsl.Member("name", design.String)
+					})
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("parses the form using the service memory limit", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).Should(ContainSubstring(`req.ParseMultipartForm(service.MaxMultipartMemory)`))
+			Ω(code).Should(ContainSubstring(`req.MultipartForm.File["avatar"]`))
+		})
+	})
+
+	Context("with GET and HEAD routes using different wildcard names", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response(design.OK)
+				})
+				apidsl.Action("check", func() {
+					apidsl.Routing(apidsl.HEAD("//bottles/:bottleID"))
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("does not mount the GET handler on HEAD requests", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+			Ω(code).ShouldNot(ContainSubstring(`goa.HeadHandler(ctrl.MuxHandler("show", h, nil))`))
+			Ω(code).Should(ContainSubstring(`service.Mux.Handle("HEAD", prefix+"/bottles/:bottleID", ctrl.MuxHandler("check", h, nil))`))
+		})
+	})
+
 	Context("with a simple API", func() {
 		var contextsCode, controllersCode, hrefsCode, mediaTypesCode string
 		var payload *design.UserTypeDefinition
@@ -218,6 +996,7 @@ var _ = Describe("Generate", func() {
 				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(string(contextsContent)).Should(ContainSubstring(controllersSlicePayloadCode))
+				Ω(string(contextsContent)).Should(ContainSubstring(controllersUnmarshalPayloadCode))
 			})
 		})
 
@@ -241,6 +1020,7 @@ var _ = Describe("Generate", func() {
 				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(string(contextsContent)).Should(ContainSubstring(controllersOptionalPayloadCode))
+				Ω(string(contextsContent)).Should(ContainSubstring(controllersUnmarshalPayloadCode))
 			})
 		})
 
@@ -311,6 +1091,18 @@ type GetWidgetContext struct {
 	ID string
 }
 
+// GetWidgetRequest is the request of the Widget get endpoint.
+type GetWidgetRequest struct {
+	ID string
+}
+
+// endpointRequest returns the request given to the Widget get endpoint.
+func (ctx *GetWidgetContext) endpointRequest() *GetWidgetRequest {
+	return &GetWidgetRequest{
+		ID: ctx.ID,
+	}
+}
+
 // NewGetWidgetContext parses the incoming request URL and body, performs validations and creates the
 // context used by the Widget controller get action.
 func NewGetWidgetContext(ctx context.Context, r *http.Request, service *goa.Service) (*GetWidgetContext, error) {
@@ -351,6 +1143,8 @@ import (
 	"context"
 	"github.com/goadesign/goa"
 	"net/http"
+	"path"
+	"strings"
 )
 
 // initService sets up the service encoders, decoders and mux.
@@ -368,23 +1162,126 @@ type WidgetController interface {
 
 // MountWidgetController "mounts" a Widget resource controller on the given service.
 func MountWidgetController(service *goa.Service, ctrl WidgetController) {
+	MountWidgetControllerAt(service, ctrl, "")
+}
+
+// MountWidgetControllerAt "mounts" a Widget resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountWidgetControllerAt(service *goa.Service, ctrl WidgetController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Build the context
+		rctx, err := NewGetWidgetContext(ctx, req, service)
 		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
+		if err != nil {
 			return err
 		}
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*GetWidgetContext)
+			rctx.Context = ctx
+			return nil, ctrl.Get(rctx)
+		})
+		return err
+	}
+	service.Mux.Handle("GET", prefix+"/:id", ctrl.MuxHandler("get", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/:id", goa.HeadHandler(ctrl.MuxHandler("get", h, nil)))
+	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET "+prefix+"/:id")
+}
+
+// WidgetService is the transport independent interface of the Widget actions called by
+// the Widget endpoints. The actions that stream, do not encode or decode their bodies, use
+// interceptors or do not have a success response that can be sent from a value are bound to HTTP
+// and are only served by the WidgetController.
+type WidgetService interface {
+	Get(context.Context, *GetWidgetRequest) (ID, error)
+}
+
+// WidgetEndpoints lists the endpoints of the Widget actions.
+type WidgetEndpoints struct {
+	Get goa.Endpoint
+}
+
+// NewWidgetEndpoints returns the endpoints that call the methods of the given service.
+func NewWidgetEndpoints(s WidgetService) *WidgetEndpoints {
+	return &WidgetEndpoints{
+		Get: NewGetWidgetEndpoint(s),
+	}
+}
+
+// Use wraps all the Widget endpoints with the given endpoint middleware.
+func (e *WidgetEndpoints) Use(m goa.EndpointMiddleware) {
+	e.Get = m(e.Get)
+}
+
+// NewGetWidgetEndpoint returns the endpoint that calls the Get method of the given
+// service. The endpoint request is a *GetWidgetRequest and its result type is ID.
+func NewGetWidgetEndpoint(s WidgetService) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.Get(ctx, req.(*GetWidgetRequest))
+	}
+}
+
+// MountWidgetEndpoints "mounts" the Widget endpoints on the given service under the given
+// base path prefix, e.g. "/api/v1", cleaned as in MountWidgetControllerAt. The HTTP handlers
+// decode the requests, call the endpoints and encode their results. The actions that are not part
+// of WidgetService are not mounted.
+func MountWidgetEndpoints(service *goa.Service, endpoints *WidgetEndpoints, prefix string) {
+	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	ctrl := service.NewController("WidgetEndpoints")
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
-		return ctrl.Get(rctx)
+		res, err := goa.ServeEndpoint(ctx, rctx.endpointRequest(), endpoints.Get)
+		if err != nil {
+			return err
+		}
+		r, _ := res.(ID)
+		return rctx.OK(r)
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))
-	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
+	service.Mux.Handle("GET", prefix+"/:id", ctrl.MuxHandler("get", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/:id", goa.HeadHandler(ctrl.MuxHandler("get", h, nil)))
+	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET "+prefix+"/:id")
+}
+
+// Routes returns the method, path and handler name of the routes of all the API actions and file
+// servers mounted under the given base path prefix, the prefix is cleaned as in the Mount functions.
+// The list includes the HEAD routes served by the GET action handlers.
+func Routes(prefix string) []goa.Route {
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	return []goa.Route{
+		{Method: "GET", Path: prefix + "/:id", Handler: "Widget.get"},
+		{Method: "HEAD", Path: prefix + "/:id", Handler: "Widget.get"},
+	}
+}
+
+// MountRoutes mounts a handler under path on the service mux that renders the routes returned by
+// Routes for the given prefix as JSON and reports whether each route is mounted. The handler is not
+// affected by the service and controller middlewares.
+func MountRoutes(service *goa.Service, path, prefix string) {
+	goa.MountRoutes(service, path, Routes(prefix))
 }
 `
 
@@ -428,16 +1325,27 @@ package app
 const controllersSlicePayloadCode = `
 // MountWidgetController "mounts" a Widget resource controller on the given service.
 func MountWidgetController(service *goa.Service, ctrl WidgetController) {
+	MountWidgetControllerAt(service, ctrl, "")
+}
+
+// MountWidgetControllerAt "mounts" a Widget resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountWidgetControllerAt(service *goa.Service, ctrl WidgetController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
-		}
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
@@ -447,12 +1355,20 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		} else {
 			return goa.MissingPayloadError()
 		}
-		return ctrl.Get(rctx)
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*GetWidgetContext)
+			rctx.Context = ctx
+			return nil, ctrl.Get(rctx)
+		})
+		return err
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
-	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
+	service.Mux.Handle("GET", prefix+"/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	service.Mux.Handle("HEAD", prefix+"/:id", goa.HeadHandler(ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload)))
+	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET "+prefix+"/:id")
 }
+`
 
+const controllersUnmarshalPayloadCode = `
 // unmarshalGetWidgetPayload unmarshals the request body into the context request data Payload field.
 func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *http.Request) error {
 	var payload Collection
@@ -467,16 +1383,27 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 const controllersOptionalPayloadCode = `
 // MountWidgetController "mounts" a Widget resource controller on the given service.
 func MountWidgetController(service *goa.Service, ctrl WidgetController) {
+	MountWidgetControllerAt(service, ctrl, "")
+}
+
+// MountWidgetControllerAt "mounts" a Widget resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountWidgetControllerAt(service *goa.Service, ctrl WidgetController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
-		}
 		// Build the context
 		rctx, err := NewGetWidgetContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
@@ -484,19 +1411,47 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.(Collection)
 		}
-		return ctrl.Get(rctx)
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*GetWidgetContext)
+			rctx.Context = ctx
+			return nil, ctrl.Get(rctx)
+		})
+		return err
 	}
-	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
-	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
+	service.Mux.Handle("GET", prefix+"/:id", ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload))
+	service.Mux.Handle("HEAD", prefix+"/:id", goa.HeadHandler(ctrl.MuxHandler("get", h, unmarshalGetWidgetPayload)))
+	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET "+prefix+"/:id")
 }
+`
 
-// unmarshalGetWidgetPayload unmarshals the request body into the context request data Payload field.
-func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *http.Request) error {
-	var payload Collection
-	if err := service.DecodeRequest(req, &payload); err != nil {
-		return err
-	}
-	goa.ContextRequest(ctx).Payload = payload
-	return nil
+const protoMessages = `syntax = "proto3";
+
+package app;
+
+// A widget (default view)
+message Widget {
+  int64 id = 1;
+  map<string, bytes> labels = 2;
+  string name = 10;
+  Size size = 4;
+  repeated string tags = 5;
+
+  message Size {
+    double height = 1;
+  }
 }
 `
+
+// ExternalUser is the external type used to test the generation of type conversions.
+type ExternalUser struct {
+	Name     string
+	Age      int64
+	Email    *string
+	Password []byte
+}
+
+// URL is the external type used to test conversions to types with the same name as a type of
+// another package.
+type URL struct {
+	Host *string
+}
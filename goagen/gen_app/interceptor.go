@@ -0,0 +1,54 @@
+package genapp
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// BuildInterceptors builds the template data needed to render the interfaces of the interceptors
+// used by the API actions. The data is sorted by interceptor name.
+func BuildInterceptors(api *design.APIDefinition) []*InterceptorTemplateData {
+	var data []*InterceptorTemplateData
+	for _, name := range api.Interceptors() {
+		d := &InterceptorTemplateData{
+			Name:     name,
+			TypeName: codegen.Goify(name, true) + "Interceptor",
+			VarName:  codegen.Goify(name, false) + "Interceptor",
+		}
+		api.IterateResources(func(r *design.ResourceDefinition) error {
+			return r.IterateActions(func(a *design.ActionDefinition) error {
+				for _, n := range a.AllInterceptors() {
+					if n == name {
+						d.Actions = append(d.Actions, a)
+						break
+					}
+				}
+				return nil
+			})
+		})
+		data = append(data, d)
+	}
+	return data
+}
+
+// InterceptorMethod returns the name of the interceptor interface method called in place of the
+// given action.
+func InterceptorMethod(a *design.ActionDefinition) string {
+	return codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true)
+}
+
+// actionInterceptors returns the data needed to run the interceptors of the given action: the
+// name of the variable holding each interceptor and the method called in place of the action.
+// The interceptors are listed in reverse order so that wrapping the action with each interceptor
+// in turn makes the first interceptor run first.
+func actionInterceptors(a *design.ActionDefinition) []map[string]string {
+	names := a.AllInterceptors()
+	res := make([]map[string]string, len(names))
+	for i, n := range names {
+		res[len(names)-1-i] = map[string]string{
+			"Var":    codegen.Goify(n, false) + "Interceptor",
+			"Method": InterceptorMethod(a),
+		}
+	}
+	return res
+}
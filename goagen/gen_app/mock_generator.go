@@ -0,0 +1,121 @@
+package genapp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// MockTemplateData contains the information required to generate the mock implementation of a
+// controller interface.
+type MockTemplateData struct {
+	Resource string                     // Goified resource name, e.g. "Bottle"
+	Actions  []*design.ActionDefinition // Resource actions
+	AppPkg   string                     // Name of the app package
+}
+
+// generateMocks generates the "mocks" package that contains a mock implementation of each
+// controller interface.
+func (g *Generator) generateMocks() error {
+	if len(g.API.Resources) == 0 {
+		return nil
+	}
+	funcs := template.FuncMap{
+		"goify":   codegen.Goify,
+		"context": func(a *design.ActionDefinition) string { return InterceptorMethod(a) + "Context" },
+	}
+	mockTmpl := template.Must(template.New("mock").Funcs(funcs).Parse(mockT))
+	outDir := filepath.Join(g.OutDir, "mocks")
+	if err := codegen.RemoveAll(outDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+	appPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return err
+	}
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("sync"),
+		codegen.SimpleImport(appPkg),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+
+	// Render the mocks sequentially then format and write them concurrently.
+	var files []*codegen.SourceFile
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
+		filename := filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_mock.go")
+		var file *codegen.SourceFile
+		file, err = codegen.SourceFileFor(filename)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		title := fmt.Sprintf("%s: %s Mocks", g.API.Context(), res.Name)
+		if err = file.WriteHeader(title, "mocks", imports); err != nil {
+			return err
+		}
+		data := &MockTemplateData{Resource: codegen.Goify(res.Name, true), AppPkg: g.Target}
+		res.IterateActions(func(a *design.ActionDefinition) error {
+			data.Actions = append(data.Actions, a)
+			return nil
+		})
+		g.genfiles = append(g.genfiles, filename)
+		return mockTmpl.Execute(file, data)
+	})
+	if err != nil {
+		for _, file := range files {
+			file.Close()
+		}
+		return err
+	}
+	g.rendered = append(g.rendered, files...)
+	return nil
+}
+
+// mockT generates the mock implementation of a controller interface.
+// template input: *MockTemplateData
+const mockT = `{{ $mock := printf "%sController" .Resource }}{{ $app := .AppPkg }}
+// {{ $mock }} is a mock implementation of {{ $app }}.{{ $mock }}. The {{ .Resource }}
+// actions call the corresponding function fields and record the contexts they are given. An
+// action whose function field is nil returns an error.
+type {{ $mock }} struct {
+	*goa.Controller
+{{ range .Actions }}	// {{ goify .Name true }}Func implements the {{ .Name }} action.
+	{{ goify .Name true }}Func func(*{{ $app }}.{{ context . }}) error
+{{ end }}
+	mu sync.Mutex
+{{ range .Actions }}	{{ goify .Name false }}Calls []*{{ $app }}.{{ context . }}
+{{ end }}}
+
+// New{{ $mock }} creates a {{ $mock }} mock whose actions all return an error until their
+// function fields are set.
+func New{{ $mock }}(service *goa.Service) *{{ $mock }} {
+	return &{{ $mock }}{Controller: service.NewController("{{ $mock }}")}
+}
+{{ range .Actions }}{{ $name := goify .Name true }}
+// {{ $name }} records the call and runs {{ $name }}Func.
+func (m *{{ $mock }}) {{ $name }}(ctx *{{ $app }}.{{ context . }}) error {
+	m.mu.Lock()
+	m.{{ goify .Name false }}Calls = append(m.{{ goify .Name false }}Calls, ctx)
+	m.mu.Unlock()
+	if m.{{ $name }}Func == nil {
+		return fmt.Errorf("unexpected call to {{ $mock }}.{{ $name }}")
+	}
+	return m.{{ $name }}Func(ctx)
+}
+
+// {{ $name }}Calls returns the contexts given to the {{ .Name }} action in call order.
+func (m *{{ $mock }}) {{ $name }}Calls() []*{{ $app }}.{{ context . }} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*{{ $app }}.{{ context . }}(nil), m.{{ goify .Name false }}Calls...)
+}
+{{ end }}`
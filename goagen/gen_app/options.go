@@ -32,3 +32,17 @@ func NoTest(noTest bool) Option {
 		g.NoTest = noTest
 	}
 }
+
+//Mocks Whether to generate the mocks package
+func Mocks(mocks bool) Option {
+	return func(g *Generator) {
+		g.Mocks = mocks
+	}
+}
+
+//Fuzz Whether to generate the fuzz targets
+func Fuzz(fuzz bool) Option {
+	return func(g *Generator) {
+		g.Fuzz = fuzz
+	}
+}
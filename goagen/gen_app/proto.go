@@ -0,0 +1,211 @@
+package genapp
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/version"
+)
+
+// protoFile builds the Protocol Buffers messages that describe the media types, user types and
+// payloads encoded by the protobuf encoder, see package github.com/goadesign/goa/encoding/protobuf.
+// The fields of each message are numbered following the order of the fields of the generated
+// Go struct, i.e. sorted by attribute name, unless the attribute defines the
+// "struct:tag:protobuf" metadata.
+type protoFile struct {
+	// messages contains the top level message definitions indexed by name.
+	messages map[string]string
+	// pending lists the user types referenced by the messages that have not been added yet.
+	pending []*design.UserTypeDefinition
+}
+
+// newProtoFile returns a .proto file builder initialized with the media types, user types and
+// payloads of api.
+func newProtoFile(api *design.APIDefinition) (*protoFile, error) {
+	p := &protoFile{messages: make(map[string]string)}
+	err := api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			pt, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			p.add(pt.UserTypeDefinition, codegen.GoTypeName(pt, nil, 0, false))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		p.add(ut, codegen.GoTypeName(ut, nil, 0, false))
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				p.add(a.Payload, codegen.GoTypeName(a.Payload, nil, 0, false))
+			}
+			return nil
+		})
+	})
+	for len(p.pending) > 0 {
+		ut := p.pending[0]
+		p.pending = p.pending[1:]
+		p.add(ut, codegen.GoTypeName(ut, nil, 0, false))
+	}
+	return p, nil
+}
+
+// Bytes returns the content of the .proto file with the given header and package.
+func (p *protoFile) Bytes(header, pkg string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	fmt.Fprintf(&buf, "syntax = \"proto3\";\n\npackage %s;\n", pkg)
+	names := make([]string, 0, len(p.messages))
+	for n := range p.messages {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		buf.WriteString("\n")
+		buf.WriteString(p.messages[n])
+	}
+	return buf.Bytes()
+}
+
+// add adds the message describing the user type ut with the given name if not already added.
+// Only objects and arrays are described by messages, arrays are described by a message with a
+// single repeated field.
+func (p *protoFile) add(ut *design.UserTypeDefinition, name string) {
+	if _, ok := p.messages[name]; ok {
+		return
+	}
+	var att *design.AttributeDefinition
+	switch {
+	case ut.IsObject():
+		att = ut.AttributeDefinition
+	case ut.IsArray():
+		att = &design.AttributeDefinition{Type: design.Object{"items": ut.AttributeDefinition}}
+	default:
+		return
+	}
+	p.messages[name] = ""
+	p.messages[name] = p.message(name, ut.Description, att, "")
+}
+
+// message returns the definition of the message with the given name describing the object att.
+func (p *protoFile) message(name, desc string, att *design.AttributeDefinition, indent string) string {
+	obj := att.Type.ToObject()
+	keys := make([]string, 0, len(obj))
+	for n := range obj {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	if desc != "" {
+		buf.WriteString(codegen.Indent(codegen.Comment(desc), indent))
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(&buf, "%smessage %s {\n", indent, name)
+	var nested []string
+	for i, n := range keys {
+		num := fmt.Sprint(i + 1)
+		if tag, ok := obj[n].Metadata["struct:tag:protobuf"]; ok && len(tag) > 0 {
+			num = tag[0]
+		}
+		typ := p.fieldType(obj[n], codegen.Goify(n, true), indent+"  ", &nested)
+		fmt.Fprintf(&buf, "%s  %s %s = %s;\n", indent, typ, codegen.SnakeCase(codegen.Goify(n, true)), num)
+	}
+	for _, m := range nested {
+		buf.WriteString("\n")
+		buf.WriteString(m)
+	}
+	fmt.Fprintf(&buf, "%s}\n", indent)
+	return buf.String()
+}
+
+// fieldType returns the type of the field describing att. Inline objects are described by
+// nested messages named after the field which are appended to nested.
+func (p *protoFile) fieldType(att *design.AttributeDefinition, name, indent string, nested *[]string) string {
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		return protoScalar(actual)
+	case *design.Array:
+		if actual.ElemType.Type.IsArray() || actual.ElemType.Type.IsHash() {
+			return "bytes"
+		}
+		return "repeated " + p.fieldType(actual.ElemType, name, indent, nested)
+	case *design.Hash:
+		switch actual.KeyType.Type.Kind() {
+		case design.StringKind, design.IntegerKind, design.BooleanKind:
+		default:
+			return "bytes"
+		}
+		if actual.ElemType.Type.IsArray() || actual.ElemType.Type.IsHash() {
+			return "bytes"
+		}
+		key := p.fieldType(actual.KeyType, name+"Key", indent, nested)
+		elem := p.fieldType(actual.ElemType, name+"Value", indent, nested)
+		return fmt.Sprintf("map<%s, %s>", key, elem)
+	case design.Object:
+		*nested = append(*nested, p.message(name, att.Description, att, indent))
+		return name
+	case *design.MediaTypeDefinition:
+		return p.userType(actual.UserTypeDefinition, codegen.GoTypeName(actual, nil, 0, false), name, indent, nested)
+	case *design.UserTypeDefinition:
+		return p.userType(actual, codegen.GoTypeName(actual, nil, 0, false), name, indent, nested)
+	default:
+		panic(fmt.Sprintf("goa bug: unknown type %#v", actual))
+	}
+}
+
+// userType returns the type of the field whose type is the user type ut with the given Go type
+// name. Objects are referred to by name and added to the file, other types are described
+// inline.
+func (p *protoFile) userType(ut *design.UserTypeDefinition, typeName, name, indent string, nested *[]string) string {
+	if !ut.IsObject() {
+		return p.fieldType(ut.AttributeDefinition, name, indent, nested)
+	}
+	if _, ok := p.messages[typeName]; !ok {
+		p.pending = append(p.pending, ut)
+	}
+	return typeName
+}
+
+// protoScalar returns the scalar type used to encode values of the primitive type t.
+func protoScalar(t design.Primitive) string {
+	switch t.Kind() {
+	case design.BooleanKind:
+		return "bool"
+	case design.IntegerKind:
+		return "int64"
+	case design.NumberKind:
+		return "double"
+	case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		return "string"
+	default:
+		// Any values are encoded as JSON, files are not encoded.
+		return "bytes"
+	}
+}
+
+// protoHeader returns the comment written at the top of the generated .proto file.
+func protoHeader(title string) string {
+	return strings.Join([]string{
+		"// Code generated by goagen " + version.String() + ", DO NOT EDIT.",
+		"//",
+		"// " + title,
+		"//",
+		"// Command:",
+		codegen.Comment(codegen.CommandLine()),
+		"",
+		"",
+	}, "\n")
+}
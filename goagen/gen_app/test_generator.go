@@ -16,7 +16,7 @@ import (
 
 func makeTestDir(g *Generator, apiName string) (outDir string, err error) {
 	outDir = filepath.Join(g.OutDir, "test")
-	if err = os.RemoveAll(outDir); err != nil {
+	if err = codegen.RemoveAll(outDir); err != nil {
 		return
 	}
 	if err = os.MkdirAll(outDir, 0755); err != nil {
@@ -37,6 +37,8 @@ type TestMethod struct {
 	ContextType       string
 	RouteVerb         string
 	FullPath          string
+	VersionHeader     string // Name of the header that selects the API version if any
+	VersionValue      string // Value of the header that selects the API version
 	Status            int
 	ReturnType        *ObjectType
 	ReturnsErrorMedia bool
@@ -44,9 +46,18 @@ type TestMethod struct {
 	QueryParams       []*ObjectType
 	Headers           []*ObjectType
 	Payload           *ObjectType
+	Body              *ObjectType
+	Example           string // Name of the payload example constructor
 	reservedNames     map[string]bool
 }
 
+// TestHarness is the template data used to render the helpers that exercise a resource through
+// a goatest.Server.
+type TestHarness struct {
+	Requests  []*TestMethod // Request builders, one per action route
+	Responses []*TestMethod // Response helpers, one per action response and view
+}
+
 // Escape escapes given string.
 func (t *TestMethod) Escape(s string) string {
 	if ok := t.reservedNames[s]; ok {
@@ -71,8 +82,10 @@ func (g *Generator) generateResourceTest() error {
 	}
 	funcs := template.FuncMap{
 		"isSlice": isSlice,
+		"isMap":   isMap,
 	}
 	testTmpl := template.Must(template.New("test").Funcs(funcs).Parse(testTmpl))
+	harnessTmpl := template.Must(template.New("harness").Funcs(funcs).Parse(harnessTmpl))
 	outDir, err := makeTestDir(g, g.API.Name)
 	if err != nil {
 		return err
@@ -99,27 +112,32 @@ func (g *Generator) generateResourceTest() error {
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
 
-	return g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
+	// Render the test helpers sequentially then format and write them concurrently.
+	var files []*codegen.SourceFile
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
 		filename := filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_testing.go")
 		var file *codegen.SourceFile
 		file, err = codegen.SourceFileFor(filename)
 		if err != nil {
 			return err
 		}
-		defer func() {
-			file.Close()
-			if err == nil {
-				err = file.FormatCode()
-			}
-		}()
+		files = append(files, file)
 		title := fmt.Sprintf("%s: %s TestHelpers", g.API.Context(), res.Name)
 		if err = file.WriteHeader(title, "test", imports); err != nil {
 			return err
 		}
 
-		var methods []*TestMethod
+		var (
+			methods []*TestMethod
+			harness TestHarness
+		)
 
 		if err = res.IterateActions(func(action *design.ActionDefinition) error {
+			if !action.WebSocket() {
+				for routeIndex, route := range action.Routes {
+					harness.Requests = append(harness.Requests, g.createTestRequest(res, action, route, routeIndex))
+				}
+			}
 			if err := action.IterateResponses(func(response *design.ResponseDefinition) error {
 				if response.Status == 101 { // SwitchingProtocols, Don't currently handle WebSocket endpoints
 					return nil
@@ -128,9 +146,15 @@ func (g *Generator) generateResourceTest() error {
 					mediaType := design.Design.MediaTypeWithIdentifier(response.MediaType)
 					if mediaType == nil {
 						methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, nil, nil))
+						if routeIndex == 0 && !action.WebSocket() {
+							harness.Responses = append(harness.Responses, methods[len(methods)-1])
+						}
 					} else {
 						if err := mediaType.IterateViews(func(view *design.ViewDefinition) error {
 							methods = append(methods, g.createTestMethod(res, action, response, route, routeIndex, mediaType, view))
+							if routeIndex == 0 && !action.WebSocket() {
+								harness.Responses = append(harness.Responses, methods[len(methods)-1])
+							}
 							return nil
 						}); err != nil {
 							return err
@@ -146,9 +170,20 @@ func (g *Generator) generateResourceTest() error {
 			return err
 		}
 		g.genfiles = append(g.genfiles, filename)
-		err = testTmpl.Execute(file, methods)
+		if err = testTmpl.Execute(file, methods); err != nil {
+			return
+		}
+		err = harnessTmpl.Execute(file, &harness)
 		return
 	})
+	if err != nil {
+		for _, file := range files {
+			file.Close()
+		}
+		return err
+	}
+	g.rendered = append(g.rendered, files...)
+	return nil
 }
 
 func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action *design.ActionDefinition,
@@ -164,6 +199,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		header                                       []*ObjectType
 		returnType                                   *ObjectType
 		payload                                      *ObjectType
+		body                                         *ObjectType
 	)
 
 	actionName = codegen.Goify(action.Name, true)
@@ -197,6 +233,8 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	comment = "runs the method " + actionName + " of the given controller with the given parameters"
 	if action.Payload != nil {
 		comment += " and payload"
+	} else if action.SkipRequestBodyEncodeDecode {
+		comment += " and request body"
 	}
 	comment += ".\n// It returns the response writer so it's possible to inspect the response headers"
 	if hasReturnValue {
@@ -207,6 +245,8 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	path = pathParams(action, route)
 	query = queryParams(action)
 	header = headers(action, resource.Headers)
+	version := action.LatestVersion()
+	versionHeader, versionValue := g.API.Versioning.RequestHeader(version)
 
 	if action.Payload != nil {
 		payload = &ObjectType{}
@@ -222,6 +262,10 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		}
 	}
 
+	if action.SkipRequestBodyEncodeDecode {
+		body = &ObjectType{Name: "body", Type: "io.Reader"}
+	}
+
 	return &TestMethod{
 		Name:              fmt.Sprintf("%s%s%s%s%s", actionName, ctrlName, respQualifier, routeQualifier, viewQualifier),
 		ActionName:        actionName,
@@ -231,6 +275,7 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		QueryParams:       query,
 		Headers:           header,
 		Payload:           payload,
+		Body:              body,
 		ReturnType:        returnType,
 		ReturnsErrorMedia: mediaType == design.ErrorMedia,
 		ControllerName:    fmt.Sprintf("%s.%sController", g.Target, ctrlName),
@@ -238,11 +283,56 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 		ContextType:       fmt.Sprintf("%s.New%s%sContext", g.Target, actionName, ctrlName),
 		RouteVerb:         route.Verb,
 		Status:            response.Status,
-		FullPath:          goPathFormat(route.FullPath()),
+		FullPath:          goPathFormat(route.VersionedPath(version)),
+		VersionHeader:     versionHeader,
+		VersionValue:      versionValue,
 		reservedNames:     reservedNames(path, query, header, payload, returnType),
 	}
 }
 
+// createTestRequest builds the template data used to render the request builder of the given
+// action route. The example payload is only rendered with the builder of the first route.
+func (g *Generator) createTestRequest(resource *design.ResourceDefinition, action *design.ActionDefinition,
+	route *design.RouteDefinition, routeIndex int) *TestMethod {
+
+	var payload, body *ObjectType
+	if action.Payload != nil {
+		payload = &ObjectType{Name: "payload"}
+		payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
+		if !action.Payload.IsPrimitive() && !action.Payload.IsArray() && !action.Payload.IsHash() {
+			payload.Pointer = "*"
+		}
+	}
+	if action.SkipRequestBodyEncodeDecode {
+		body = &ObjectType{Name: "body", Type: "io.Reader"}
+	}
+	path := pathParams(action, route)
+	query := queryParams(action)
+	header := headers(action, resource.Headers)
+	version := action.LatestVersion()
+	versionHeader, versionValue := g.API.Versioning.RequestHeader(version)
+	var example string
+	if routeIndex == 0 && payloadExample(g.API, action) != "" {
+		example = fmt.Sprintf("%s.NewExample%s%sPayload", g.Target, codegen.Goify(action.Name, true), codegen.Goify(resource.Name, true))
+	}
+	return &TestMethod{
+		Name:          codegen.Goify(action.Name, true) + codegen.Goify(resource.Name, true) + suffixRoute(action.Routes, routeIndex),
+		ResourceName:  resource.Name,
+		ActionName:    action.Name,
+		Params:        path,
+		QueryParams:   query,
+		Headers:       header,
+		Payload:       payload,
+		Body:          body,
+		Example:       example,
+		RouteVerb:     route.Verb,
+		FullPath:      goPathFormat(route.VersionedPath(version)),
+		VersionHeader: versionHeader,
+		VersionValue:  versionValue,
+		reservedNames: reservedNames(path, query, header, payload, nil),
+	}
+}
+
 // pathParams returns the path params for the given action and route.
 func pathParams(action *design.ActionDefinition, route *design.RouteDefinition) []*ObjectType {
 	return paramFromNames(action, route.Params())
@@ -345,6 +435,10 @@ func isSlice(typeName string) bool {
 	return strings.HasPrefix(typeName, "[]")
 }
 
+func isMap(typeName string) bool {
+	return strings.HasPrefix(typeName, "map[")
+}
+
 var convertParamTmpl = `{{ if eq .Type "string" }}		sliceVal := []string{ {{ if .Pointer }}*{{ end }}{{ .Name }}}{{/*
 */}}{{ else if eq .Type "int" }}		sliceVal := []string{strconv.Itoa({{ if .Pointer }}*{{ end }}{{ .Name }})}{{/*
 */}}{{ else if eq .Type "[]string" }}		sliceVal := {{ .Name }}{{/*
@@ -364,7 +458,8 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 */}}{{ range $param := $test.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $param := $test.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
 */}}{{ range $header := $test.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
-*/}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}){{/*
+*/}}{{ if $test.Payload }}, {{ $test.Payload.Name }} {{ $test.Payload.Pointer }}{{ $test.Payload.Type }}{{ end }}{{/*
+*/}}{{ if $test.Body }}, {{ $test.Body.Name }} {{ $test.Body.Type }}{{ end }}){{/*
 */}} (http.ResponseWriter{{ if $test.ReturnType }}, {{ $test.ReturnType.Pointer }}{{ $test.ReturnType.Type }}{{ end }}) {
 	// Setup service
 	var (
@@ -397,15 +492,18 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	// Setup request context
 	{{ $rw := $test.Escape "rw" }}{{ $rw }} := httptest.NewRecorder()
 {{ $query := $test.Escape "query" }}{{ if $test.QueryParams}}	{{ $query }} := url.Values{}
-{{ range $param := $test.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ range $param := $test.QueryParams }}{{ if isMap $param.Type }}	for k, v := range {{ $param.Name }} {
+		{{ $query }}[fmt.Sprintf("{{ $param.Label }}[%v]", k)] = []string{fmt.Sprintf("%v", v)}
+	}
+{{ else }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
 {{ template "convertParam" $param }}
 		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
+{{ end }}{{ end }}{{ end }}	{{ $u := $test.Escape "u" }}{{ $u }}:= &url.URL{
 		Path: fmt.Sprintf({{ printf "%q" $test.FullPath }}{{ range $param := $test.Params }}, {{ $param.Name }}{{ end }}),
 {{ if $test.QueryParams }}		RawQuery: {{ $query }}.Encode(),
 {{ end }}	}
-	{{ $req := $test.Escape "req" }}{{ $req }}, {{ $err := $test.Escape "err" }}{{ $err }}:= http.NewRequest("{{ $test.RouteVerb }}", {{ $u }}.String(), nil)
+	{{ $req := $test.Escape "req" }}{{ $req }}, {{ $err := $test.Escape "err" }}{{ $err }}:= http.NewRequest("{{ $test.RouteVerb }}", {{ $u }}.String(), {{ if $test.Body }}{{ $test.Body.Name }}{{ else }}nil{{ end }})
 	if {{ $err }} != nil {
 		panic("invalid test " + {{ $err }}.Error()) // bug
 	}
@@ -413,13 +511,17 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 {{ template "convertParam" $header }}
 		{{ $req }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
 	}
+{{ end }}{{ if $test.VersionHeader }}	{{ $req }}.Header.Set({{ printf "%q" $test.VersionHeader }}, {{ printf "%q" $test.VersionValue }})
 {{ end }} {{ $prms := $test.Escape "prms" }}{{ $prms }} := url.Values{}
 {{ range $param := $test.Params }}	{{ $prms }}["{{ $param.Label }}"] = []string{fmt.Sprintf("%v",{{ $param.Name}})}
-{{ end }}{{ range $param := $test.QueryParams }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
+{{ end }}{{ range $param := $test.QueryParams }}{{ if isMap $param.Type }}	for k, v := range {{ $param.Name }} {
+		{{ $prms }}[fmt.Sprintf("{{ $param.Label }}[%v]", k)] = []string{fmt.Sprintf("%v", v)}
+	}
+{{ else }}{{ if $param.Pointer }} if {{ $param.Name }} != nil {{ end }} {
 {{ template "convertParam" $param }}
 		{{ $prms }}[{{ printf "%q" $param.Label }}] = sliceVal
 	}
-{{ end }}	if ctx == nil {
+{{ end }}{{ end }}	if ctx == nil {
 		ctx = context.Background()
 	}
 	{{ $goaCtx := $test.Escape "goaCtx" }}{{ $goaCtx }} := goa.NewContext(goa.WithAction(ctx, "{{ $test.ResourceName }}Test"), {{ $rw }}, {{ $req }}, {{ $prms }})
@@ -456,3 +558,64 @@ func {{ $test.Name }}(t goatest.TInterface, ctx context.Context, service *goa.Se
 	return {{ $rw }}{{ if $test.ReturnType }}, mt{{ end }}
 }
 {{ end }}`
+
+// template input: *TestHarness
+var harnessTmpl = `{{ define "convertParam" }}` + convertParamTmpl + `{{ end }}` + `
+{{ range $req := .Requests }}{{ if $req.Example }}
+// Example{{ $req.Name }}Payload returns a payload of the {{ $req.ActionName }} action of the {{ $req.ResourceName }} resource
+// built from the design examples.
+func Example{{ $req.Name }}Payload() {{ $req.Payload.Pointer }}{{ $req.Payload.Type }} {
+	return {{ $req.Example }}()
+}
+{{ end }}
+// New{{ $req.Name }}Request creates a request for the {{ $req.ActionName }} action of the {{ $req.ResourceName }} resource sent to the
+// given test server.
+func New{{ $req.Name }}Request(server *goatest.Server{{/*
+*/}}{{ range $param := $req.Params }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $param := $req.QueryParams }}, {{ $param.Name }} {{ $param.Pointer }}{{ $param.Type }}{{ end }}{{/*
+*/}}{{ range $header := $req.Headers }}, {{ $header.Name }} {{ $header.Pointer }}{{ $header.Type }}{{ end }}{{/*
+*/}}{{ if $req.Payload }}, {{ $req.Payload.Name }} {{ $req.Payload.Pointer }}{{ $req.Payload.Type }}{{ end }}{{/*
+*/}}{{ if $req.Body }}, {{ $req.Body.Name }} {{ $req.Body.Type }}{{ end }}) (*http.Request, error) {
+	{{ $query := $req.Escape "query" }}{{ $query }} := url.Values{}
+{{ range $param := $req.QueryParams }}{{ if $param.Pointer }}	if {{ $param.Name }} != nil {{ end }}{
+{{ template "convertParam" $param }}
+		{{ $query }}[{{ printf "%q" $param.Label }}] = sliceVal
+	}
+{{ end }}	var {{ $data := $req.Escape "data" }}{{ $data }} interface{}
+{{ if $req.Payload }}{{ if $req.Payload.Pointer }}	if {{ $req.Payload.Name }} != nil {
+		{{ $data }} = {{ $req.Payload.Name }}
+	}
+{{ else }}	{{ $data }} = {{ $req.Payload.Name }}
+{{ end }}{{ else if $req.Body }}	if {{ $req.Body.Name }} != nil {
+		{{ $data }} = {{ $req.Body.Name }}
+	}
+{{ end }}	{{ $r := $req.Escape "req" }}{{ $r }}, {{ $err := $req.Escape "err" }}{{ $err }} := server.NewRequest("{{ $req.RouteVerb }}", fmt.Sprintf({{ printf "%q" $req.FullPath }}{{ range $param := $req.Params }}, {{ $param.Name }}{{ end }}), {{ $query }}, {{ $data }})
+	if {{ $err }} != nil {
+		return nil, {{ $err }}
+	}
+{{ range $header := $req.Headers }}{{ if $header.Pointer }}	if {{ $header.Name }} != nil {{ end }}{
+{{ template "convertParam" $header }}
+		{{ $r }}.Header[{{ printf "%q" $header.Label }}] = sliceVal
+	}
+{{ end }}{{ if $req.VersionHeader }}	{{ $r }}.Header.Set({{ printf "%q" $req.VersionHeader }}, {{ printf "%q" $req.VersionValue }})
+{{ end }}	return {{ $r }}, nil
+}
+{{ end }}{{ range $resp := .Responses }}
+// Do{{ $resp.Name }} sends the request to the test server and checks that the response status is {{ $resp.Status }}.
+{{ if $resp.ReturnType }}// It returns the response and the decoded response body.
+func Do{{ $resp.Name }}(server *goatest.Server, req *http.Request) (*http.Response, {{ if $resp.ReturnsErrorMedia }}*goa.ErrorResponse{{ else }}{{ $resp.ReturnType.Pointer }}{{ $resp.ReturnType.Type }}{{ end }}, error) {
+	var mt {{ if $resp.ReturnsErrorMedia }}goa.ErrorResponse{{ else }}{{ $resp.ReturnType.Type }}{{ end }}
+	resp, err := server.Do(req, {{ $resp.Status }}, &mt)
+	if err != nil {
+		return resp, nil, err
+	}
+{{ if and $resp.ReturnType.Validatable (not $resp.ReturnsErrorMedia) }}	if err := mt.Validate(); err != nil {
+		return resp, nil, err
+	}
+{{ end }}	return resp, {{ if or $resp.ReturnType.Pointer $resp.ReturnsErrorMedia }}&{{ end }}mt, nil
+}
+{{ else }}// The caller is responsible for closing the response body.
+func Do{{ $resp.Name }}(server *goatest.Server, req *http.Request) (*http.Response, error) {
+	return server.Do(req, {{ $resp.Status }}, nil)
+}
+{{ end }}{{ end }}`
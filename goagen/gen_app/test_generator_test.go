@@ -250,6 +250,19 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("ctrl.Show("))
 		})
 
+		It("generates the test server request builders and response helpers", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			code := string(content)
+
+			Ω(code).Should(ContainSubstring("func NewShowFooRequest(server *goatest.Server, "))
+			Ω(code).Should(ContainSubstring("func NewShowFoo1Request(server *goatest.Server, "))
+			Ω(code).Should(ContainSubstring(`_query["query"] = sliceVal`))
+			Ω(code).Should(ContainSubstring("func DoShowFooOK(server *goatest.Server, req *http.Request) (*http.Response, *app.IntContainer, error) {"))
+			Ω(code).Should(ContainSubstring("func ExampleGetFooPayload() app.CustomName {"))
+			Ω(code).Should(ContainSubstring("func DoGetFooOK(server *goatest.Server, req *http.Request) (*http.Response, *goa.ErrorResponse, error) {"))
+		})
+
 		It("generates non pointer references to primitive/array/hash payloads", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
@@ -262,5 +275,50 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(strings.Split(string(content), "\n")).Should(ContainElement(MatchRegexp(`^// Code generated .* DO NOT EDIT\.$`)))
 		})
+
+		Context("with a versioned API", func() {
+			var scheme design.VersionScheme
+
+			BeforeEach(func() {
+				scheme = design.VersionSchemeHeader
+			})
+
+			JustBeforeEach(func() {
+				design.Design.Versioning = &design.VersioningDefinition{
+					Versions: []string{"v1", "v2"},
+					Scheme:   scheme,
+					Name:     "X-API-Version",
+				}
+				files, genErr = genapp.Generate()
+			})
+
+			AfterEach(func() {
+				design.Design.Versioning = nil
+			})
+
+			It("selects the latest version in the requests", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				code := string(content)
+				Ω(strings.Count(code, `.Header.Set("X-API-Version", "v2")`)).Should(Equal(6))
+				Ω(code).ShouldNot(ContainSubstring(`"v1"`))
+			})
+
+			Context("using path versioning", func() {
+				BeforeEach(func() {
+					scheme = design.VersionSchemePath
+				})
+
+				It("prefixes the request paths with the latest version", func() {
+					Ω(genErr).Should(BeNil())
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					code := string(content)
+					Ω(code).Should(ContainSubstring(`fmt.Sprintf("/v2/p/%v/u/%v/%v", param, uuid, required)`))
+					Ω(code).ShouldNot(ContainSubstring(`.Header.Set("X-API-Version"`))
+				})
+			})
+		})
 	})
 })
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"sort"
 
@@ -46,6 +47,17 @@ type (
 		SecurityTmpl *template.Template
 	}
 
+	// InterceptorsWriter generate the interfaces of the interceptors used by the API actions.
+	InterceptorsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// ConversionsWriter generate the methods that convert user types to and from external Go
+	// types.
+	ConversionsWriter struct {
+		*codegen.SourceFile
+	}
+
 	// ResourcesWriter generate code for a goa application resources.
 	// Resources are data structures initialized by the application handlers and passed to controller
 	// actions.
@@ -74,24 +86,54 @@ type (
 	// ContextTemplateData contains all the information used by the template to render the context
 	// code for an action.
 	ContextTemplateData struct {
-		Name         string // e.g. "ListBottleContext"
-		ResourceName string // e.g. "bottles"
-		ActionName   string // e.g. "list"
-		Params       *design.AttributeDefinition
-		Payload      *design.UserTypeDefinition
-		Headers      *design.AttributeDefinition
-		Routes       []*design.RouteDefinition
-		Responses    map[string]*design.ResponseDefinition
-		API          *design.APIDefinition
-		DefaultPkg   string
-		Security     *design.SecurityDefinition
+		Name             string // e.g. "ListBottleContext"
+		ResourceName     string // e.g. "bottles"
+		ActionName       string // e.g. "list"
+		Params           *design.AttributeDefinition
+		Payload          *design.UserTypeDefinition
+		Headers          *design.AttributeDefinition
+		Cookies          *design.AttributeDefinition
+		ResponseCookies  *design.AttributeDefinition
+		Routes           []*design.RouteDefinition
+		StreamingPayload *design.UserTypeDefinition
+		StreamingResult  *design.UserTypeDefinition
+		ServerSentEvents bool
+		NDJSON           bool
+		KeepAlive        time.Duration
+		MaxBodyLength    int64
+		SkipRequestBody  bool
+		SkipResponseBody bool
+		Pagination       string
+		IfMatch          bool
+		Async            bool
+		Responses        map[string]*design.ResponseDefinition
+		API              *design.APIDefinition
+		DefaultPkg       string
+		Security         *design.SecurityDefinition
+		Errors           []*design.ErrorDefinition
+		Interceptors     []string
+		PayloadExample   string // JSON representation of the payload example
+		Endpoint         *EndpointTemplateData
+	}
+
+	// EndpointTemplateData contains the information needed to render the transport independent
+	// endpoint of an action.
+	EndpointTemplateData struct {
+		// Request is the name of the endpoint request type, e.g. "ShowBottleRequest".
+		Request string
+		// Result is the Go type of the endpoint result, e.g. "*GoaExampleBottle". It is empty
+		// if the success response has no body.
+		Result string
+		// Responder is the name of the action context method that sends the success response
+		// given the result, e.g. "OK".
+		Responder string
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
 	ControllerTemplateData struct {
 		API            *design.APIDefinition          // API definition
 		Resource       string                         // Lower case plural resource name, e.g. "bottles"
-		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Context" and "Unmarshal"
+		Actions        []map[string]interface{}       // Array of actions, each action has keys "Name", "DesignName", "Routes", "Mounts", "Context", "Unmarshal" and "Endpoint"
 		FileServers    []*design.FileServerDefinition // File servers
 		Encoders       []*EncoderTemplateData         // Encoder data
 		Decoders       []*EncoderTemplateData         // Decoder data
@@ -109,6 +151,32 @@ type (
 		CanonicalParams   []string                    // CanonicalParams is the list of parameter names that appear in the resource canonical path in order.
 	}
 
+	// InterceptorTemplateData contains the data needed to render the interface of an interceptor.
+	InterceptorTemplateData struct {
+		// Name is the name of the interceptor as declared in the design, e.g. "cache".
+		Name string
+		// TypeName is the name of the interceptor interface, e.g. "CacheInterceptor".
+		TypeName string
+		// VarName is the name of the variable holding the registered interceptor.
+		VarName string
+		// Actions lists the intercepted actions.
+		Actions []*design.ActionDefinition
+	}
+
+	// ConversionTemplateData contains the data needed to render a user type conversion method.
+	ConversionTemplateData struct {
+		// TypeName is the name of the user type Go struct, e.g. "User".
+		TypeName string
+		// External is the qualified name of the external Go struct, e.g. "models.User".
+		External string
+		// Method is the name of the conversion method, e.g. "ConvertToModelsUser".
+		Method string
+		// Create is true if the method initializes the user type from the external type.
+		Create bool
+		// Code is the code that assigns the fields.
+		Code string
+	}
+
 	// EncoderTemplateData contains the data needed to render the registration code for a single
 	// encoder or decoder package.
 	EncoderTemplateData struct {
@@ -190,6 +258,17 @@ func (c *ContextTemplateData) IterateResponses(it func(*design.ResponseDefinitio
 	return nil
 }
 
+// HasEndpoints returns true if at least one of the controller actions has a transport independent
+// endpoint.
+func (c *ControllerTemplateData) HasEndpoints() bool {
+	for _, a := range c.Actions {
+		if a["Endpoint"] != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // NewContextsWriter returns a contexts code writer.
 // Contexts provide the glue between the underlying request data and the user controller.
 func NewContextsWriter(filename string) (*ContextsWriter, error) {
@@ -206,19 +285,78 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 
 // Execute writes the code for the context types to the writer.
 func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
-	if err := w.ExecuteTemplate("context", ctxT, nil, data); err != nil {
+	ctxFn := template.FuncMap{
+		"fieldTypeRef": fieldTypeRef,
+	}
+	if err := w.ExecuteTemplate("context", ctxT, ctxFn, data); err != nil {
 		return err
 	}
+	if data.Endpoint != nil {
+		if err := w.ExecuteTemplate("request", requestT, ctxFn, data); err != nil {
+			return err
+		}
+	}
 	fn := template.FuncMap{
 		"newCoerceData":      newCoerceData,
 		"arrayAttribute":     arrayAttribute,
-		"printVal":           codegen.PrintVal,
+		"hashType":           hashType,
+		"scalarType":         scalarType,
+		"fieldTypeRef":       fieldTypeRef,
+		"defaultValue":       defaultValue,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
 		"isPathParam":        data.IsPathParam,
+		"validationCode":     w.Validator.Code,
 	}
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
 	}
+	if data.ResponseCookies != nil {
+		fn := template.FuncMap{
+			"formatValue": formatValue,
+			"sameSite":    sameSite,
+		}
+		if err := w.ExecuteTemplate("cookies", ctxCookiesT, fn, data); err != nil {
+			return err
+		}
+	}
+	if data.Pagination != "" {
+		if err := w.ExecuteTemplate("paginate", ctxPaginateT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.IfMatch {
+		if err := w.ExecuteTemplate("ifMatch", ctxIfMatchT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.Async {
+		if err := w.ExecuteTemplate("async", ctxAsyncT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.ServerSentEvents {
+		if err := w.ExecuteTemplate("sse", ctxSSET, nil, data); err != nil {
+			return err
+		}
+	} else if data.NDJSON {
+		if err := w.ExecuteTemplate("ndjson", ctxNDJSONT, nil, data); err != nil {
+			return err
+		}
+	} else if data.StreamingPayload != nil || data.StreamingResult != nil {
+		if err := w.ExecuteTemplate("stream", ctxStreamT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.SkipResponseBody {
+		if err := w.ExecuteTemplate("writeBody", ctxWriteBodyT, nil, data); err != nil {
+			return err
+		}
+	}
+	if len(data.Errors) > 0 {
+		if err := w.ExecuteTemplate("errors", ctxErrorsT, nil, data); err != nil {
+			return err
+		}
+	}
 	if data.Payload != nil {
 		found := false
 		for _, t := range design.Design.Types {
@@ -231,16 +369,27 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
 				"validationCode": w.Validator.Code,
+				"redactedFields": redactedFields,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
 				return err
 			}
 		}
+		if data.PayloadExample != "" {
+			fn := template.FuncMap{"exampleFunc": examplePayloadFunc}
+			if err := w.ExecuteTemplate("examplePayload", ctxExamplePayloadT, fn, data); err != nil {
+				return err
+			}
+		}
 	}
-	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
+	err := data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
 			"Response": resp,
+			"Cache":    cacheHeaders(resp),
+		}
+		if resp.RedirectURL != "" {
+			return w.writeRedirect(resp, respData)
 		}
 		var mt *design.MediaTypeDefinition
 		if resp.Type != nil {
@@ -248,6 +397,11 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
+				body := &design.AttributeDefinition{Type: resp.Type}
+				if ut, ok := resp.Type.(*design.UserTypeDefinition); ok {
+					body = ut.AttributeDefinition
+				}
+				respData["Headers"] = responseHeaders(resp, body)
 				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
 			}
 		} else {
@@ -272,8 +426,14 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					return err
 				}
 				respData["Projected"] = projected
+				respData["Headers"] = responseHeaders(resp, projected.AttributeDefinition)
+				respData["Conditional"] = nil
+				if resp.Status == 200 {
+					respData["Conditional"] = conditionalHeaders(mt, projected.AttributeDefinition)
+				}
 				respData["ViewName"] = view
 				respData["MediaType"] = mt
+				respData["JSONAPI"] = hypermediaFormat(mt) == "jsonapi"
 				respData["ContentType"] = mt.ContentType
 				if view == "default" {
 					respData["RespName"] = codegen.Goify(resp.Name, true)
@@ -285,10 +445,94 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 					return err
 				}
 			}
+			if resp.ViewName == "" {
+				return w.writeViewResponse(resp, mt, views, respData)
+			}
 			return nil
 		}
 		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
 	})
+	if err != nil {
+		return err
+	}
+	if tagged := taggedResponses(data); tagged != nil {
+		return w.ExecuteTemplate("respond", ctxRespondT, nil, tagged)
+	}
+	return nil
+}
+
+// writeViewResponse writes the response helper that renders the view requested by the client
+// for media types that define multiple views. The helper accepts the full projection of the media
+// type, see design.MediaTypeDefinition.ProjectFull, and projects it onto the requested view.
+func (w *ContextsWriter) writeViewResponse(resp *design.ResponseDefinition, mt *design.MediaTypeDefinition, views []string, respData map[string]interface{}) error {
+	if len(views) < 2 || mt.Views["default"] == nil || mt.Views["view"] != nil {
+		return nil
+	}
+	full, fullView, err := mt.ProjectFull()
+	if err != nil {
+		return err
+	}
+	var (
+		def    map[string]interface{}
+		others []map[string]interface{}
+	)
+	for _, view := range views {
+		p, _, err := mt.Project(view)
+		if err != nil {
+			return err
+		}
+		data := map[string]interface{}{
+			"Name":      view,
+			"Projected": p,
+			"Project":   view != fullView,
+			"Responder": viewResponder(resp.Name, view),
+		}
+		if view == "default" {
+			def = data
+			continue
+		}
+		others = append(others, data)
+	}
+	respData["Full"] = full
+	respData["Default"] = def
+	respData["Views"] = others
+	return w.ExecuteTemplate("viewResponse", ctxViewRespT, nil, respData)
+}
+
+// viewResponder returns the name of the helper that sends the response with the given name using
+// the given view.
+func viewResponder(respName, view string) string {
+	if view == "default" {
+		return codegen.Goify(respName, true)
+	}
+	return codegen.Goify(respName+strings.Title(view), true)
+}
+
+// writeRedirect writes the response helper of a redirect response. The helper accepts the
+// response media type as argument if the redirect URL refers to its attributes.
+func (w *ContextsWriter) writeRedirect(resp *design.ResponseDefinition, respData map[string]interface{}) error {
+	var body *design.AttributeDefinition
+	if design.ExtractWildcards(resp.RedirectURL) != nil {
+		dt := resp.Type
+		if mt := design.Design.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+			if _, ok := dt.(*design.MediaTypeDefinition); dt == nil || ok {
+				projected, _, err := mt.Project(resp.TagView())
+				if err != nil {
+					return err
+				}
+				dt = projected
+			}
+		}
+		if dt != nil {
+			body = &design.AttributeDefinition{Type: dt}
+			if ut, ok := dt.(*design.UserTypeDefinition); ok {
+				body = ut.AttributeDefinition
+			}
+			respData["Type"] = dt
+		}
+	}
+	respData["Location"] = redirectLocation(resp.RedirectURL, body)
+	return w.ExecuteTemplate("response", ctxRedirectT, nil, respData)
 }
 
 // NewControllersWriter returns a handlers code writer.
@@ -305,6 +549,53 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 	}, nil
 }
 
+// actionMounts returns the mux registrations of the action handler listed under the "Mounts" key
+// of the given action data or else the registrations of its routes on the service mux. The "Head"
+// key of the GET registrations is true unless an action of the API defines a HEAD route with the
+// same path so that the handler also serves HEAD requests.
+func actionMounts(action map[string]interface{}) []map[string]interface{} {
+	res, ok := action["Mounts"].([]map[string]interface{})
+	if !ok {
+		routes, _ := action["Routes"].([]*design.RouteDefinition)
+		res = make([]map[string]interface{}, len(routes))
+		for i, r := range routes {
+			res[i] = map[string]interface{}{"Mux": "service.Mux", "Verb": r.Verb, "Path": r.FullPath()}
+		}
+	}
+	for _, m := range res {
+		path, _ := m["Path"].(string)
+		m["Head"] = m["Verb"] == "GET" && !hasHeadRoute(path)
+	}
+	return res
+}
+
+// hasHeadRoute returns true if an action of the API defines a HEAD route with the given path. The
+// wildcard names are ignored as the mux treats "/bottles/:id" and "/bottles/:bottleID" as the same
+// route.
+func hasHeadRoute(path string) bool {
+	if design.Design == nil {
+		return false
+	}
+	key := design.WildcardRegex.ReplaceAllLiteralString(path, "*")
+	found := false
+	design.Design.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, ro := range a.Routes {
+				if ro.Verb != "HEAD" {
+					continue
+				}
+				for _, p := range ro.VersionedPaths() {
+					if design.WildcardRegex.ReplaceAllLiteralString(p, "*") == key {
+						found = true
+					}
+				}
+			}
+			return nil
+		})
+	})
+	return found
+}
+
 // WriteInitService writes the initService function
 func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTemplateData) error {
 	ctx := map[string]interface{}{
@@ -315,6 +606,27 @@ func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTempla
 	return w.ExecuteTemplate("service", serviceT, nil, ctx)
 }
 
+// WriteHealthCheck writes the function that mounts the health check probes.
+func (w *ControllersWriter) WriteHealthCheck(hc *design.HealthCheckDefinition) error {
+	return w.ExecuteTemplate("healthCheck", healthCheckT, nil, hc)
+}
+
+// WriteDocs writes the function that mounts the handlers serving the OpenAPI document spec and
+// the pages rendering it.
+func (w *ControllersWriter) WriteDocs(docs *design.DocsDefinition, spec []byte) error {
+	data := map[string]interface{}{
+		"Docs": docs,
+		"Spec": string(spec),
+	}
+	return w.ExecuteTemplate("docs", docsT, nil, data)
+}
+
+// WriteRoutes writes the function that lists the routes of the given controllers and the function
+// that mounts the route table debug handler.
+func (w *ControllersWriter) WriteRoutes(data []*ControllerTemplateData) error {
+	return w.ExecuteTemplate("routes", routesT, template.FuncMap{"mounts": actionMounts}, data)
+}
+
 // Execute writes the handlers GoGenerator
 func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	if len(data) == 0 {
@@ -324,17 +636,43 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
 			return err
 		}
-		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
+		if err := w.ExecuteTemplate("mount", mountT, template.FuncMap{"mounts": actionMounts}, d); err != nil {
 			return err
 		}
+		if d.HasEndpoints() {
+			if err := w.ExecuteTemplate("endpoints", endpointsT, nil, d); err != nil {
+				return err
+			}
+			if err := w.ExecuteTemplate("mountEndpoints", mountEndpointsT, template.FuncMap{"mounts": actionMounts}, d); err != nil {
+				return err
+			}
+		}
 		if len(d.Origins) > 0 {
-			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, d); err != nil {
+			ctx := map[string]interface{}{
+				"Handler": fmt.Sprintf("handle%sOrigin", d.Resource),
+				"Origins": d.Origins,
+			}
+			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, ctx); err != nil {
 				return err
 			}
 		}
+		for _, a := range d.Actions {
+			if origins, ok := a["Origins"].([]*design.CORSDefinition); ok && len(origins) > 0 {
+				ctx := map[string]interface{}{
+					"Handler": fmt.Sprintf("handle%s%sOrigin", a["Name"], d.Resource),
+					"Origins": origins,
+				}
+				if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, ctx); err != nil {
+					return err
+				}
+			}
+		}
 		fn := template.FuncMap{
 			"finalizeCode":   w.Finalizer.Code,
 			"validationCode": w.Validator.Code,
+			"newCoerceData":  newCoerceData,
+			"arrayAttribute": arrayAttribute,
+			"isFile":         isFile,
 		}
 		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
 			return err
@@ -343,6 +681,45 @@ func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
 	return nil
 }
 
+// NewInterceptorsWriter returns a writer that generates the interfaces of the interceptors.
+func NewInterceptorsWriter(filename string) (*InterceptorsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &InterceptorsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the interceptor interfaces and the functions that register them.
+func (w *InterceptorsWriter) Execute(data []*InterceptorTemplateData) error {
+	fn := template.FuncMap{"method": InterceptorMethod}
+	for _, d := range data {
+		if err := w.ExecuteTemplate("interceptor", interceptorT, fn, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewConversionsWriter returns a writer that generates the user type conversion methods.
+func NewConversionsWriter(filename string) (*ConversionsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ConversionsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the conversion methods.
+func (w *ConversionsWriter) Execute(data []*ConversionTemplateData) error {
+	for _, d := range data {
+		if err := w.ExecuteTemplate("conversion", conversionT, nil, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // NewSecurityWriter returns a security functionality code writer.
 // Those functionalities are there to support action-middleware related to security.
 func NewSecurityWriter(filename string) (*SecurityWriter, error) {
@@ -397,11 +774,39 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 		if err != nil {
 			return err
 		}
-		return w.ExecuteTemplate("mediatype", mediaTypeT, fn, p)
+		if err := w.ExecuteTemplate("mediatype", mediaTypeT, fn, p); err != nil {
+			return err
+		}
+		if format, ok := mt.Metadata["hypermedia"]; ok && len(format) > 0 && mt.IsObject() {
+			resourceType := codegen.SnakeCase(mt.TypeName)
+			if t, ok := mt.Metadata["hypermedia:type"]; ok && len(t) > 0 {
+				resourceType = t[0]
+			}
+			data := map[string]interface{}{
+				"MediaType":    p,
+				"Format":       format[0],
+				"ResourceType": resourceType,
+			}
+			return w.ExecuteTemplate("hypermedia", mediaTypeHypermediaT, nil, data)
+		}
+		return nil
 	})
 	if err != nil {
 		return err
 	}
+	if len(mt.Views) > 1 {
+		// The response helpers that project onto the views accept a type that combines the
+		// attributes of all the views if no view renders them all.
+		full, view, err := mt.ProjectFull()
+		if err != nil {
+			return err
+		}
+		if _, ok := mt.Views[view]; !ok {
+			if err := w.ExecuteTemplate("mediatype", mediaTypeT, fn, full); err != nil {
+				return err
+			}
+		}
+	}
 	if mLinks != nil {
 		if err := w.ExecuteTemplate("mediatypelink", mediaTypeLinkT, fn, mLinks); err != nil {
 			return err
@@ -429,10 +834,20 @@ func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
 		"validationCode": w.Validator.Code,
+		"redactedFields": redactedFields,
+		"unionTag":       unionTag,
+	}
+	if t.IsUnion() {
+		return w.ExecuteTemplate("union", unionT, fn, t)
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
 
+// unionTag returns the Go literal of the JSON member that identifies a union variant.
+func unionTag(discriminator, value string) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%q:%q", discriminator, value))
+}
+
 // newCoerceData is a helper function that creates a map that can be given to the "Coerce" template.
 func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, pkg string, depth int) map[string]interface{} {
 	return map[string]interface{}{
@@ -440,62 +855,451 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 		"VarName":   codegen.Goify(name, false),
 		"Pointer":   pointer,
 		"Attribute": att,
+		"Scalar":    scalarType(att),
 		"Pkg":       pkg,
 		"Depth":     depth,
 	}
 }
 
-// arrayAttribute returns the array element attribute definition.
-func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
-	return a.Type.(*design.Array).ElemType
+// formatValue returns the code that converts the variable v holding a value of the type of att
+// into a string, e.g. to produce a cookie or header value.
+func formatValue(att *design.AttributeDefinition, v string) string {
+	if att.Scalar() != "" {
+		return fmt.Sprintf("%s.String()", v)
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind:
+		return fmt.Sprintf("strconv.FormatBool(%s)", v)
+	case design.IntegerKind:
+		return fmt.Sprintf("strconv.Itoa(%s)", v)
+	case design.NumberKind:
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'f', -1, 64)", v)
+	case design.StringKind:
+		return v
+	case design.DateTimeKind:
+		return fmt.Sprintf("%s.Format(time.RFC3339)", v)
+	case design.UUIDKind:
+		return fmt.Sprintf("%s.String()", v)
+	default:
+		return fmt.Sprintf("fmt.Sprintf(\"%%v\", %s)", v)
+	}
 }
 
-const (
-	// ctxT generates the code for the context data type.
-	// template input: *ContextTemplateData
-	ctxT = `// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
-type {{ .Name }} struct {
-	context.Context
-	*goa.ResponseData
-	*goa.RequestData
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
-{{ end }}}
-`
-	// coerceT generates the code that coerces the generic deserialized
-	// data to the actual type.
-	// template input: map[string]interface{} as returned by newCoerceData
-	coerceT = `{{ if eq .Attribute.Type.Kind 1 }}{{/*
+// responseHeaders returns the code that sets the response headers mapped to the attributes of
+// the response body r.
+func responseHeaders(resp *design.ResponseDefinition, body *design.AttributeDefinition) []string {
+	if resp.Headers == nil || !body.Type.IsObject() {
+		return nil
+	}
+	var code []string
+	obj := body.Type.ToObject()
+	resp.Headers.Type.ToObject().IterateAttributes(func(n string, h *design.AttributeDefinition) error {
+		attName, ok := h.Metadata["header:attribute"]
+		if !ok || len(attName) == 0 {
+			return nil
+		}
+		att, ok := obj[attName[0]]
+		if !ok {
+			return nil // attribute not rendered by the view
+		}
+		field := "r." + codegen.GoifyAtt(att, attName[0], true)
+		if arr := att.Type.ToArray(); arr != nil {
+			code = append(code, fmt.Sprintf("\t\tfor _, v := range %s {\n\t\t\tctx.ResponseData.Header().Add(%q, %s)\n\t\t}",
+				field, n, formatHeader(arr.ElemType, "v")))
+			return nil
+		}
+		if body.IsPrimitivePointer(attName[0]) {
+			v := "*" + field
+			if att.Type.Kind() != design.StringKind {
+				v = "(" + v + ")"
+			}
+			code = append(code, fmt.Sprintf("\t\tif %s != nil {\n\t\t\tctx.ResponseData.Header().Set(%q, %s)\n\t\t}",
+				field, n, formatHeader(att, v)))
+			return nil
+		}
+		code = append(code, fmt.Sprintf("\t\tctx.ResponseData.Header().Set(%q, %s)", n, formatHeader(att, field)))
+		return nil
+	})
+	return code
+}
 
-*/}}{{/* BooleanType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseBool(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
-{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 2 }}{{/*
+// taggedResponses returns the data used to render the Respond method of the context, nil if the
+// action does not define tagged responses. Respond accepts the full projection of the media type
+// and projects it onto the view of the response selected by the tag.
+func taggedResponses(data *ContextTemplateData) map[string]interface{} {
+	var tagged []*design.ResponseDefinition
+	data.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if resp.TagAttribute != "" {
+			tagged = append(tagged, resp)
+		}
+		return nil
+	})
+	if len(tagged) == 0 {
+		return nil
+	}
+	mt := design.Design.MediaTypeWithIdentifier(tagged[0].MediaType)
+	if mt == nil {
+		return nil
+	}
+	full, fullView, err := mt.ProjectFull()
+	if err != nil {
+		return nil
+	}
+	responder := func(resp *design.ResponseDefinition) map[string]interface{} {
+		view := resp.TagView()
+		projected, _, err := mt.Project(view)
+		if err != nil {
+			return nil
+		}
+		return map[string]interface{}{
+			"Value":     resp.TagValue,
+			"Projected": projected,
+			"Project":   view != fullView,
+			"Responder": viewResponder(resp.Name, view),
+		}
+	}
+	cases := make([]map[string]interface{}, len(tagged))
+	for i, resp := range tagged {
+		if cases[i] = responder(resp); cases[i] == nil {
+			return nil
+		}
+	}
+	var def map[string]interface{}
+	data.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if def != nil || resp.TagAttribute != "" {
+			return nil
+		}
+		if _, ok := resp.Type.(*design.MediaTypeDefinition); resp.Type != nil && !ok {
+			return nil
+		}
+		if design.Design.MediaTypeWithIdentifier(resp.MediaType) != mt {
+			return nil
+		}
+		def = responder(resp)
+		return nil
+	})
+	tagAtt := tagged[0].TagAttribute
+	return map[string]interface{}{
+		"Context": data,
+		"Full":    full,
+		"Tag":     tagAtt,
+		"Field":   codegen.GoifyAtt(full.ToObject()[tagAtt], tagAtt, true),
+		"Pointer": full.IsPrimitivePointer(tagAtt),
+		"Cases":   cases,
+		"Default": def,
+	}
+}
 
-*/}}{{/* IntegerType */}}{{/*
-*/}}{{ $tmp := tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
-{{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
-{{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
-{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
-{{ end }}{{ tabs .Depth }}} else {
-{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
-{{ tabs .Depth }}}
-{{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
+// redirectLocation returns the code that builds the value of the Location header from the
+// redirect URL replacing its wildcards with the values of the attributes of the response body r.
+func redirectLocation(url string, body *design.AttributeDefinition) string {
+	var parts []string
+	start := 0
+	for _, m := range design.WildcardRegex.FindAllStringSubmatchIndex(url, -1) {
+		parts = append(parts, fmt.Sprintf("%q", url[start:m[0]+1]))
+		name := url[m[2]:m[3]]
+		var att *design.AttributeDefinition
+		if body != nil && body.Type.IsObject() {
+			att = body.Type.ToObject()[name]
+		}
+		if att == nil {
+			// Design validation rejects wildcards that the body does not define, keep the
+			// wildcard so that the segment is not lost.
+			parts = append(parts, fmt.Sprintf("%q", url[m[0]+1:m[1]]))
+		} else {
+			field := "r." + codegen.GoifyAtt(att, name, true)
+			parts = append(parts, fmt.Sprintf("url.PathEscape(%s)", formatValue(att, field)))
+		}
+		start = m[1]
+	}
+	if start < len(url) || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", url[start:]))
+	}
+	return strings.Join(parts, "+")
+}
 
-*/}}{{/* NumberType */}}{{/*
-*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+// conditionalHeaders returns the code that sets the ETag and Last-Modified response headers of
+// cacheable media types from the attributes of the response body r, nil if the media type is not
+// cacheable.
+func conditionalHeaders(mt *design.MediaTypeDefinition, body *design.AttributeDefinition) []string {
+	if !body.Type.IsObject() {
+		return nil
+	}
+	var code []string
+	obj := body.Type.ToObject()
+	set := func(header, attName string, format func(*design.AttributeDefinition, string) string) {
+		att, ok := obj[attName]
+		if !ok {
+			return
+		}
+		field := "r." + codegen.GoifyAtt(att, attName, true)
+		if body.IsPrimitivePointer(attName) {
+			v := "*" + field
+			if att.Type.Kind() != design.StringKind {
+				v = "(" + v + ")"
+			}
+			code = append(code, fmt.Sprintf("\tif r != nil && %s != nil {\n\t\tctx.ResponseData.Header().Set(%q, %s)\n\t}",
+				field, header, format(att, v)))
+			return
+		}
+		code = append(code, fmt.Sprintf("\tif r != nil {\n\t\tctx.ResponseData.Header().Set(%q, %s)\n\t}",
+			header, format(att, field)))
+	}
+	if etag, ok := mt.Metadata["cache:etag"]; ok && len(etag) > 0 {
+		if etag[0] == "" {
+			code = append(code, "\tif etag, err := goa.ComputeETag(r); err == nil {\n\t\tctx.ResponseData.Header().Set(\"ETag\", etag)\n\t}")
+		} else {
+			set("ETag", etag[0], func(att *design.AttributeDefinition, v string) string {
+				return fmt.Sprintf("goa.QuoteETag(%s)", formatValue(att, v))
+			})
+		}
+	}
+	if lm, ok := mt.Metadata["cache:last-modified"]; ok && len(lm) > 0 {
+		set("Last-Modified", lm[0], formatHeader)
+	}
+	return code
+}
+
+// hypermediaFormat returns the hypermedia format used to render the media type or the elements
+// of the collection media type, the empty string if there is none.
+func hypermediaFormat(mt *design.MediaTypeDefinition) string {
+	if arr := mt.ToArray(); arr != nil {
+		if elem, ok := arr.ElemType.Type.(*design.MediaTypeDefinition); ok {
+			mt = elem
+		}
+	}
+	if format, ok := mt.Metadata["hypermedia"]; ok && len(format) > 0 {
+		return format[0]
+	}
+	return ""
+}
+
+// cacheHeaders returns the code that sets the Cache-Control, Expires and Vary headers of the
+// response as defined with the CacheControl and Vary DSL.
+func cacheHeaders(resp *design.ResponseDefinition) []string {
+	var code []string
+	if len(resp.CacheControl) > 0 {
+		code = append(code, fmt.Sprintf("\tctx.ResponseData.Header().Set(\"Cache-Control\", %q)",
+			strings.Join(resp.CacheControl, ", ")))
+		if strings.HasPrefix(resp.CacheControl[0], "max-age=") {
+			code = append(code, fmt.Sprintf("\tctx.ResponseData.Header().Set(\"Expires\", time.Now().Add(%d*time.Second).UTC().Format(http.TimeFormat))",
+				resp.MaxAge))
+		}
+	}
+	if len(resp.Vary) > 0 {
+		code = append(code, fmt.Sprintf("\tctx.ResponseData.Header().Set(\"Vary\", %q)",
+			strings.Join(resp.Vary, ", ")))
+	}
+	return code
+}
+
+// redactedFields returns the sorted names of the attributes of att and of its child attributes
+// flagged with the "log:redact" metadata.
+func redactedFields(att *design.AttributeDefinition) []string {
+	names := make(map[string]bool)
+	seen := make(map[string]bool)
+	var collect func(*design.AttributeDefinition)
+	collect = func(att *design.AttributeDefinition) {
+		if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+			if seen[ut.TypeName] {
+				return
+			}
+			seen[ut.TypeName] = true
+		} else if mt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+			if seen[mt.Identifier] {
+				return
+			}
+			seen[mt.Identifier] = true
+		}
+		if a := att.Type.ToArray(); a != nil {
+			collect(a.ElemType)
+			return
+		}
+		if h := att.Type.ToHash(); h != nil {
+			collect(h.ElemType)
+			return
+		}
+		for n, child := range att.Type.ToObject() {
+			if _, ok := child.Metadata["log:redact"]; ok {
+				names[n] = true
+			}
+			collect(child)
+		}
+	}
+	collect(att)
+	if len(names) == 0 {
+		return nil
+	}
+	res := make([]string, 0, len(names))
+	for n := range names {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// formatHeader is similar to formatValue but uses the HTTP date format for date times.
+func formatHeader(att *design.AttributeDefinition, v string) string {
+	if att.Type.Kind() == design.DateTimeKind {
+		return fmt.Sprintf("%s.UTC().Format(http.TimeFormat)", v)
+	}
+	return formatValue(att, v)
+}
+
+// sameSite returns the http.SameSite constant corresponding to the given SameSite mode.
+func sameSite(mode string) string {
+	switch mode {
+	case "strict":
+		return "http.SameSiteStrictMode"
+	case "lax":
+		return "http.SameSiteLaxMode"
+	case "none":
+		return "http.SameSiteNoneMode"
+	default:
+		return "http.SameSiteDefaultMode"
+	}
+}
+
+// arrayAttribute returns the array element attribute definition.
+func arrayAttribute(a *design.AttributeDefinition) *design.AttributeDefinition {
+	return a.Type.(*design.Array).ElemType
+}
+
+// isFile returns true if the attribute is a File attribute.
+func isFile(a *design.AttributeDefinition) bool {
+	return a.Type.Kind() == design.FileKind
+}
+
+// hashType returns the hash map type of the given attribute.
+func hashType(a *design.AttributeDefinition) *design.Hash {
+	return a.Type.ToHash()
+}
+
+// scalarType returns the Go type of the custom scalar used by the given attribute, the empty
+// string if the attribute does not use a custom scalar.
+func scalarType(att *design.AttributeDefinition) string {
+	if att.Scalar() == "" {
+		return ""
+	}
+	return att.Metadata["struct:field:type"][0]
+}
+
+// defaultValue returns the Go expression of the default value of the given header, parameter or
+// cookie.
+func defaultValue(att *design.AttributeDefinition) string {
+	return codegen.DefaultValue(att)
+}
+
+// fieldTypeRef returns the Go type of the context field that holds the value of the given header,
+// parameter or cookie.
+func fieldTypeRef(att *design.AttributeDefinition) string {
+	if t := scalarType(att); t != "" {
+		return t
+	}
+	if arr := att.Type.ToArray(); arr != nil {
+		if t := scalarType(arr.ElemType); t != "" {
+			return "[]" + t
+		}
+	}
+	if h := att.Type.ToHash(); h != nil {
+		if t := scalarType(h.ElemType); t != "" {
+			return fmt.Sprintf("map[%s]%s", fieldTypeRef(h.KeyType), t)
+		}
+	}
+	return codegen.GoTypeRef(att.Type, nil, 0, false)
+}
+
+const (
+	// ctxT generates the code for the context data type.
+	// template input: *ContextTemplateData
+	ctxT = `// {{ .Name }} provides the {{ .ResourceName }} {{ .ActionName }} action context.
+type {{ .Name }} struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+` + ctxFieldsT + `{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
+{{ end }}{{ if .SkipRequestBody }}	// Body is the request body, it is not decoded and must be read by the action.
+	Body io.ReadCloser
+{{ end }}{{ if .Interceptors }}	// Result is the value given to the last response method called by the action, it gives
+	// the interceptors access to the action result.
+	Result interface{}
+{{ end }}}
+`
+	// ctxFieldsT generates the context fields that hold the action headers, parameters and
+	// cookies.
+	// template input: *ContextTemplateData
+	ctxFieldsT = `{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ fieldTypeRef . }}
+{{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ fieldTypeRef . }}
+{{ end }}{{ end }}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}	{{ goifyatt $att $name true }} {{ if $.Cookies.IsPrimitivePointer $name }}*{{ end }}{{ fieldTypeRef . }}
+{{ end }}{{ end }}`
+
+	// requestT generates the transport independent request of the action endpoint and the
+	// context method that builds it.
+	// template input: *ContextTemplateData
+	requestT = `
+// {{ .Endpoint.Request }} is the request of the {{ .ResourceName }} {{ .ActionName }} endpoint.
+type {{ .Endpoint.Request }} struct {
+` + ctxFieldsT + `{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
+{{ end }}}
+
+// endpointRequest returns the request given to the {{ .ResourceName }} {{ .ActionName }} endpoint.
+func (ctx *{{ .Name }}) endpointRequest() *{{ .Endpoint.Request }} {
+	return &{{ .Endpoint.Request }}{
+{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ if not ($.HasParamAndHeader $name) }}{{/*
+*/}}		{{ goifyatt $att $name true }}: ctx.{{ goifyatt $att $name true }},
+{{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
+*/}}		{{ goifyatt $att $name true }}: ctx.{{ goifyatt $att $name true }},
+{{ end }}{{ end }}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}		{{ goifyatt $att $name true }}: ctx.{{ goifyatt $att $name true }},
+{{ end }}{{ end }}{{ if .Payload }}		Payload: ctx.Payload,
+{{ end }}	}
+}
+`
+
+	// coerceT generates the code that coerces the generic deserialized
+	// data to the actual type.
+	// template input: map[string]interface{} as returned by newCoerceData
+	coerceT = `{{ if .Scalar }}{{/*
+
+*/}}{{/* Custom scalar */}}{{/*
+*/}}{{ $tmp := tempvar }}{{/*
+*/}}{{ tabs .Depth }}var {{ $tmp }} {{ .Scalar }}
+{{ tabs .Depth }}if err2 := {{ $tmp }}.UnmarshalText([]byte(raw{{ goify .Name true }})); err2 == nil {
+{{ tabs .Depth }}	{{ .Pkg }} = {{ if .Pointer }}&{{ end }}{{ $tmp }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "{{ .Attribute.Scalar }}"))
+{{ tabs .Depth }}}
+{{ else }}{{ if eq .Attribute.Type.Kind 1 }}{{/*
+
+*/}}{{/* BooleanType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseBool(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "boolean"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 2 }}{{/*
+
+*/}}{{/* IntegerType */}}{{/*
+*/}}{{ $tmp := tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
+{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
+{{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
+{{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
+{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
+{{ end }}{{ tabs .Depth }}} else {
+{{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
+{{ tabs .Depth }}}
+{{ end }}{{ if eq .Attribute.Type.Kind 3 }}{{/*
+
+*/}}{{/* NumberType */}}{{/*
+*/}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
+{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
@@ -530,7 +1334,7 @@ type {{ .Name }} struct {
 */}}{{ if .Pointer }}{{ $tmp := tempvar }}{{ tabs .Depth }}{{ $tmp }} := interface{}(raw{{ goify .Name true }})
 {{ tabs .Depth }}{{ .Pkg }} = &{{ $tmp }}
 {{ else }}{{ tabs .Depth }}{{ .Pkg }} = raw{{ goify .Name true }}
-{{ end }}{{ end }}`
+{{ end }}{{ end }}{{ end }}`
 
 	// ctxNewT generates the code for the context factory method.
 	// template input: *ContextTemplateData
@@ -545,14 +1349,20 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	req.Request = r
 	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
 */}}
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
+{{ if .SkipRequestBody }}{{ if .MaxBodyLength }}	if r.ContentLength > {{ .MaxBodyLength }} {
+		return nil, goa.ErrRequestBodyTooLarge("request body length exceeds {{ .MaxBodyLength }} bytes")
+	}
+	rctx.Body = http.MaxBytesReader(resp, r.Body, {{ .MaxBodyLength }})
+{{ else }}	rctx.Body = r.Body
+{{ end }}{{ end }}{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
 {{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
 		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $name }}"))
 	} else {
 {{ else }}	if len(header{{ goify $name true }}) > 0 {
 {{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}		req.Params["{{ $name }}"] = header{{ goify $name true }}
-{{ if eq (arrayAttribute $att).Type.Kind 4 }}		headers := header{{ goify $name true }}
-{{ else }}		headers := make({{ gotypedef $att 2 true false }}, len(header{{ goify $name true }}))
+{{ if and (eq (arrayAttribute $att).Type.Kind 4) (not (scalarType (arrayAttribute $att))) }}		headers := header{{ goify $name true }}
+{{ else }}{{ if ne (arrayAttribute $att).Type.Kind 7 }}		header{{ goify $name true }} = goa.SplitParamValues(header{{ goify $name true }})
+{{ end }}		headers := make({{ fieldTypeRef $att }}, len(header{{ goify $name true }}))
 		for i, raw{{ goify $name true}} := range header{{ goify $name true}} {
 {{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Headers.IsPrimitivePointer $name) "headers[i]" 3) }}{{/*
 */}}		}
@@ -560,33 +1370,268 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 {{ else }}		raw{{ goify $name true}} := header{{ goify $name true}}[0]
 		req.Params["{{ $name }}"] = []string{raw{{ goify $name true }}}
 {{ template "Coerce" (newCoerceData $name $att ($.Headers.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
-*/}}{{ $validation := validationChecker $att ($.Headers.IsNonZero $name) ($.Headers.IsRequired $name) ($.Headers.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ $validation := validationChecker $att (or ($.Headers.IsNonZero $name) ($.Headers.IsNonPointer $name)) ($.Headers.IsRequired $name) ($.Headers.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
 */}}{{ if $validation }}{{ $validation }}
 {{ end }}	}
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
 */}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	param{{ goify $name true }} := req.Params["{{ $name }}"]
+*/}}	param{{ goify $name true }} := {{ if $att.Type.IsHash }}goa.MapParamValues(req.Params, "{{ $name }}"){{ else }}req.Params["{{ $name }}"]{{ end }}
 {{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
-		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}{{else}}{{/*
+		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ defaultValue $att }}{{else}}{{/*
 */}}err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}")){{end}}
 	} else {
 {{ else }}{{ if $.Params.HasDefaultValue $name }}	if len(param{{ goify $name true }}) == 0 {
-		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}
+		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ defaultValue $att }}
 	} else {
 {{ else }}	if len(param{{ goify $name true }}) > 0 {
-{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
-{{ else }}		params := make({{ gotypedef $att 2 true false }}, len(param{{ goify $name true }}))
+{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if and (eq (arrayAttribute $att).Type.Kind 4) (not (scalarType (arrayAttribute $att))) }}		params := param{{ goify $name true }}
+{{ else }}{{ if ne (arrayAttribute $att).Type.Kind 7 }}		param{{ goify $name true }} = goa.SplitParamValues(param{{ goify $name true }})
+{{ end }}		params := make({{ fieldTypeRef $att }}, len(param{{ goify $name true }}))
 		for i, raw{{ goify $name true}} := range param{{ goify $name true}} {
 {{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
 */}}		}
 {{ end }}		{{ printf "rctx.%s" (goifyatt $att $name true) }} = params
+{{ else if $att.Type.IsHash }}{{ $key := printf "%s[key]" $name }}		params := make({{ fieldTypeRef $att }}, len(param{{ goify $name true }}))
+		for raw{{ goify $key true }}, raw{{ goify $name true }} := range param{{ goify $name true }} {
+			var k {{ fieldTypeRef (hashType $att).KeyType }}
+{{ template "Coerce" (newCoerceData $key (hashType $att).KeyType false "k" 3) }}{{/*
+*/}}			var v {{ fieldTypeRef (hashType $att).ElemType }}
+{{ template "Coerce" (newCoerceData $name (hashType $att).ElemType false "v" 3) }}{{/*
+*/}}			params[k] = v
+		}
+		{{ printf "rctx.%s" (goifyatt $att $name true) }} = params
 {{ else }}		raw{{ goify $name true}} := param{{ goify $name true}}[0]
 {{ template "Coerce" (newCoerceData $name $att ($.Params.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
-*/}}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ $validation := or (and $att.Type.IsHash (validationCode $att (or ($.Params.IsNonZero $name) ($.Params.IsNonPointer $name)) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false)) (validationChecker $att (or ($.Params.IsNonZero $name) ($.Params.IsNonPointer $name)) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false) }}{{/*
 */}}{{ if $validation }}{{ $validation }}
 {{ end }}	}
-{{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
+{{ end }}{{ end }}{{/* if .Params */}}{{ if .Cookies }}{{ range $name, $att := .Cookies.Type.ToObject }}{{/*
+*/}}	if cookie{{ goify $name true }}, err2 := r.Cookie("{{ $name }}"); err2 == nil {
+		raw{{ goify $name true }} := cookie{{ goify $name true }}.Value
+{{ template "Coerce" (newCoerceData $name $att ($.Cookies.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{/*
+*/}}{{ $validation := validationChecker $att (or ($.Cookies.IsNonZero $name) ($.Cookies.IsNonPointer $name)) ($.Cookies.IsRequired $name) ($.Cookies.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+*/}}{{ if $validation }}{{ $validation }}
+{{ end }}	}{{ if $.Cookies.IsRequired $name }} else {
+		err = goa.MergeErrors(err, goa.MissingCookieError("{{ $name }}"))
+	}{{ else if $.Cookies.HasDefaultValue $name }} else {
+		{{ printf "rctx.%s" (goifyatt $att $name true) }} = {{ defaultValue $att }}
+	}{{ end }}
+{{ end }}{{ end }}{{/* if .Cookies */}}	return &rctx, err
+}
+`
+
+	// ctxCookiesT generates the methods that set the response cookies.
+	// template input: *ContextTemplateData
+	ctxCookiesT = `{{ $ctx := . }}{{ range $name, $att := .ResponseCookies.Type.ToObject }}
+// Set{{ goify $name true }}Cookie sets the {{ printf "%q" $name }} response cookie.
+func (ctx *{{ $ctx.Name }}) Set{{ goify $name true }}Cookie(v {{ gotyperef $att.Type nil 0 false }}) {
+	http.SetCookie(ctx.ResponseData, &http.Cookie{
+		Name: {{ printf "%q" $name }},
+		Value: {{ formatValue $att "v" }},{{ with index $att.Metadata "cookie:max-age" }}
+		MaxAge: {{ index . 0 }},{{ end }}{{ if index $att.Metadata "cookie:secure" }}
+		Secure: true,{{ end }}{{ if index $att.Metadata "cookie:http-only" }}
+		HttpOnly: true,{{ end }}{{ with index $att.Metadata "cookie:same-site" }}
+		SameSite: {{ sameSite (index . 0) }},{{ end }}
+	})
+}
+{{ end }}`
+
+	// ctxErrorsT generates the error classes and constructors of the errors defined in the
+	// design with the Error DSL.
+	// template input: *ContextTemplateData
+	ctxErrorsT = `{{ $ctx := . }}{{ range .Errors }}{{/*
+*/}}{{ $name := printf "%s%s%s" (goify $ctx.ActionName true) (goify $ctx.ResourceName true) (goify .Name true) }}{{/*
+*/}}{{ if eq .MediaType "application/vnd.goa.error" }}
+// Err{{ $name }} is the class of {{ printf "%q" .Name }} errors of the {{ $ctx.ResourceName }} {{ $ctx.ActionName }} action.
+// The error handler middleware maps the errors to {{ .Status }} responses.{{ if .Description }}
+{{ comment .Description }}{{ end }}
+var Err{{ $name }} = goa.NewErrorClass({{ printf "%q" .Name }}, {{ .Status }})
+{{ else }}
+// New{{ $name }}Error creates a {{ printf "%q" .Name }} error of the {{ $ctx.ResourceName }} {{ $ctx.ActionName }} action.
+// The error handler middleware maps the error to a {{ .Status }} response whose body is v.{{ if .Description }}
+{{ comment .Description }}{{ end }}
+func New{{ $name }}Error(v {{ gotyperef .Type nil 0 false }}) error {
+	return goa.NewTypedError({{ printf "%q" .Name }}, {{ .Status }}, v, {{ printf "%q" .MediaType }})
+}
+{{ end }}{{ end }}`
+
+	// ctxSSET generates the event stream of actions that stream their results using Server-Sent
+	// Events.
+	// template input: *ContextTemplateData
+	ctxSSET = `{{ $stream := printf "%s%sStream" (goify .ActionName true) (goify .ResourceName true) }}{{/*
+*/}}// {{ $stream }} streams the results of the {{ .ResourceName }} {{ .ActionName }} action using Server-Sent Events.
+type {{ $stream }} struct {
+	*goa.EventStream
+}
+
+// Stream writes the event stream response headers and returns the stream used to send events.
+// The stream LastEventID field is set when the client reconnects and can be used to resume
+// the stream.
+func (ctx *{{ .Name }}) Stream() (*{{ $stream }}, error) {
+	es, err := goa.NewEventStream(ctx.ResponseData, ctx.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &{{ $stream }}{EventStream: es}, nil
+}
+
+// Send sends an event to the client, id is optional and is sent back by the client in the
+// Last-Event-ID header when it reconnects.
+func (s *{{ $stream }}) Send(id string, v {{ gotyperef .StreamingResult nil 0 false }}) error {
+	return s.EventStream.Send(id, "", v)
+}
+`
+
+	// ctxNDJSONT generates the stream of actions that stream their results as newline delimited
+	// JSON.
+	// template input: *ContextTemplateData
+	ctxNDJSONT = `{{ $stream := printf "%s%sStream" (goify .ActionName true) (goify .ResourceName true) }}{{/*
+*/}}// {{ $stream }} streams the results of the {{ .ResourceName }} {{ .ActionName }} action as newline delimited JSON.
+type {{ $stream }} struct {
+	*goa.NDJSONStream
+}
+
+// Stream writes the response headers and returns the stream used to send results.
+func (ctx *{{ .Name }}) Stream() (*{{ $stream }}, error) {
+	s, err := goa.NewNDJSONStream(ctx.ResponseData, ctx.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &{{ $stream }}{NDJSONStream: s}, nil
+}
+
+// Send writes a result on its own line and flushes it to the client.
+func (s *{{ $stream }}) Send(v {{ gotyperef .StreamingResult nil 0 false }}) error {
+	return s.NDJSONStream.Send(v)
+}
+`
+
+	// ctxWriteBodyT generates the method that writes the response body of actions that skip
+	// the response body encoding.
+	// template input: *ContextTemplateData
+	ctxWriteBodyT = `// WriteBody sends a HTTP response with the given status code and content type whose body is
+// copied from body without being encoded or buffered. body is closed once copied if it
+// implements io.Closer.
+func (ctx *{{ .Name }}) WriteBody(code int, contentType string, body io.Reader) error {
+	if c, ok := body.(io.Closer); ok {
+		defer c.Close()
+	}
+	if contentType != "" {
+		ctx.ResponseData.Header().Set("Content-Type", contentType)
+	}
+	ctx.ResponseData.WriteHeader(code)
+	_, err := io.Copy(ctx.ResponseData, body)
+	return err
+}
+`
+
+	// ctxStreamT generates the stream wrapper of actions that define a streaming payload or a
+	// streaming result.
+	// template input: *ContextTemplateData
+	ctxStreamT = `{{ $stream := printf "%s%sStream" (goify .ActionName true) (goify .ResourceName true) }}{{/*
+*/}}// {{ $stream }} wraps the websocket connection of the {{ .ResourceName }} {{ .ActionName }} action.
+type {{ $stream }} struct {
+	*websocket.Conn
+	stop func()
+}
+
+// Stream returns the stream wrapping the given websocket connection.
+func (ctx *{{ .Name }}) Stream(ws *websocket.Conn) *{{ $stream }} {
+	s := &{{ $stream }}{Conn: ws}
+{{- if .KeepAlive }}
+	s.stop = goa.KeepAlive(ws, {{ duration .KeepAlive }})
+{{- end }}
+	return s
+}
+
+// Upgrade upgrades the request connection to a websocket and calls fn with the corresponding
+// stream. The stream is closed once fn returns. The errors returned by fn are logged as the
+// response has already been sent.
+func (ctx *{{ .Name }}) Upgrade(fn func(*{{ $stream }}) error) error {
+	websocket.Handler(func(ws *websocket.Conn) {
+		s := ctx.Stream(ws)
+		defer s.Close()
+		if err := fn(s); err != nil {
+			goa.LogError(ctx, "stream failed", "err", err)
+		}
+	}).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	return nil
+}
+{{ if .StreamingResult }}
+// Send sends a message to the client.
+func (s *{{ $stream }}) Send(v {{ gotyperef .StreamingResult nil 0 false }}) error {
+	return websocket.JSON.Send(s.Conn, v)
+}
+{{ end }}{{ if .StreamingPayload }}
+// Recv receives the next message sent by the client.
+func (s *{{ $stream }}) Recv() ({{ gotyperef .StreamingPayload nil 0 false }}, error) {
+	var v {{ gotyperef .StreamingPayload nil 0 false }}
+	err := websocket.JSON.Receive(s.Conn, &v)
+	return v, err
+}
+{{ end }}
+// Close stops the keep alive pings and closes the websocket connection.
+func (s *{{ $stream }}) Close() error {
+	if s.stop != nil {
+		s.stop()
+	}
+	return s.Conn.Close()
+}
+`
+
+	// ctxPaginateT generates the method that sets the pagination response headers.
+	// template input: *ContextTemplateData
+	ctxPaginateT = `{{ if eq .Pagination "cursor" }}
+// Paginate sets the Link response header given the cursor of the next page, the empty string if
+// the current page is the last one. Paginate returns the page links so they can also be rendered
+// in the response body.
+func (ctx *{{ .Name }}) Paginate(next string) *goa.Page {
+	page := goa.CursorPage(ctx.URL, next){{ else }}
+// Paginate sets the Link and X-Total-Count response headers given the total number of items in
+// the collection. Paginate returns the page links so they can also be rendered in the response
+// body.
+func (ctx *{{ .Name }}) Paginate(total int) *goa.Page {
+	page := goa.{{ if eq .Pagination "page" }}NumberedPage(ctx.URL, ctx.Page, ctx.PageSize, total){{ else }}OffsetPage(ctx.URL, ctx.Offset, ctx.Limit, total){{ end }}{{ end }}
+	page.SetHeaders(ctx.ResponseData.Header())
+	return page
+}
+`
+
+	// ctxIfMatchT generates the method that checks the If-Match request header.
+	// template input: *ContextTemplateData
+	ctxIfMatchT = `
+// CheckETag returns an error that results in a 412 Precondition Failed response if the request
+// If-Match header does not match etag, the current entity tag of the resource.
+func (ctx *{{ .Name }}) CheckETag(etag string) error {
+	return goa.CheckIfMatch(ctx.Request, etag)
+}
+`
+
+	// ctxExamplePayloadT generates the constructor of the action payload example.
+	// template input: *ContextTemplateData
+	ctxExamplePayloadT = `
+// {{ exampleFunc .Name }} returns a {{ .ResourceName }} {{ .ActionName }} action payload built
+// from the design examples.
+func {{ exampleFunc .Name }}() {{ gotyperef .Payload .Payload.AllRequired 0 false }} {
+	var payload {{ gotypename .Payload .Payload.AllRequired 0 false }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .PayloadExample }}), &payload); err != nil {
+		panic("invalid example " + err.Error()) // bug
+	}
+	return {{ if .Payload.IsObject }}&{{ end }}payload
+}
+`
+
+	// ctxAsyncT generates the AcceptedJob helper of asynchronous actions.
+	// template input: *ContextTemplateData
+	ctxAsyncT = `
+// AcceptedJob sends a HTTP response with status code 202 describing the job started by the
+// request. It sets the job Href field and the Location header to the path of the job status.
+func (ctx *{{ .Name }}) AcceptedJob(r *GoaJob) error {
+	href := goa.JobHref(ctx.Request, r.ID)
+	r.Href = &href
+	ctx.ResponseData.Header().Set("Location", href)
+	return ctx.Accepted(r)
 }
 `
 
@@ -594,11 +1639,21 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	// template input: map[string]interface{}
 	ctxMTRespT = `// {{ goify .RespName true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
-	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
-{{ if .Projected.Type.IsArray }}	if r == nil {
+{{ if .Context.Interceptors }}	ctx.Result = r
+{{ end }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+{{ range .Cache }}{{ . }}
+{{ end }}{{ if .Headers }}	if r != nil {
+{{ range .Headers }}{{ . }}
+{{ end }}	}
+{{ end }}{{ if .Conditional }}{{ range .Conditional }}{{ . }}
+{{ end }}	if goa.NotModified(ctx.Request, ctx.ResponseData.Header()) {
+		ctx.ResponseData.WriteHeader(304)
+		return nil
+	}
+{{ end }}{{ if .Projected.Type.IsArray }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
-{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, {{ if .JSONAPI }}&goa.JSONAPIDocument{Data: r}{{ else }}r{{ end }})
 }
 `
 
@@ -606,9 +1661,79 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 	// template input: map[string]interface{}
 	ctxTRespT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .Type nil 0 false }}) error {
-	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ if .Context.Interceptors }}	ctx.Result = r
+{{ end }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
+{{ range .Cache }}{{ . }}
+{{ end }}{{ if .Headers }}	if r != nil {
+{{ range .Headers }}{{ . }}
+{{ end }}	}
+{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
+`
+
+	// ctxViewRespT generates the response helpers that render the view requested by the client.
+	// template input: map[string]interface{}
+	ctxViewRespT = `// {{ goify .Response.Name true }}View sends a HTTP response with status code {{ .Response.Status }} using the view requested by
+// the client, see goa.RequestedView. r is projected onto the requested view. The default view is
+// used if the client does not request a view or requests an unknown view.
+func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}View(r {{ gotyperef .Full .Full.AllRequired 0 false }}) error {
+	switch goa.RequestedView(ctx.Request) {
+{{ range .Views }}	case {{ printf "%q" .Name }}:
+{{ if .Project }}		var p {{ gotyperef .Projected .Projected.AllRequired 0 false }}
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.{{ .Responder }}(p)
+{{ else }}		return ctx.{{ .Responder }}(r)
+{{ end }}{{ end }}	}
+{{ with .Default }}{{ if .Project }}	var p {{ gotyperef .Projected .Projected.AllRequired 0 false }}
+	if err := goa.Project(r, &p); err != nil {
+		return err
+	}
+	return ctx.{{ .Responder }}(p)
+{{ else }}	return ctx.{{ .Responder }}(r)
+{{ end }}{{ end }}}
+`
+
+	// ctxRedirectT generates the response helpers for redirect responses.
+	// template input: map[string]interface{}
+	ctxRedirectT = `// {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }} redirecting to {{ .Response.RedirectURL }}.
+func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Type }}r {{ gotyperef .Type nil 0 false }}{{ end }}) error {
+	ctx.ResponseData.Header().Set("Location", {{ .Location }})
+{{ range .Cache }}{{ . }}
+{{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }})
+	return nil
+}
+`
+
+	// ctxRespondT generates the method that sends the response selected by the tag attribute.
+	// template input: map[string]interface{}
+	ctxRespondT = `// Respond sends the response selected by the value of the "{{ .Tag }}" attribute of r. r is
+// projected onto the view of the selected response.
+func (ctx *{{ .Context.Name }}) Respond(r {{ gotyperef .Full .Full.AllRequired 0 false }}) error {
+	var tag string
+	if r != nil {{ if .Pointer }}&& r.{{ .Field }} != nil {
+		tag = *r.{{ .Field }}
+	}{{ else }}{
+		tag = r.{{ .Field }}
+	}{{ end }}
+	switch tag {
+{{ range .Cases }}	case {{ printf "%q" .Value }}:
+{{ if .Project }}		var p {{ gotyperef .Projected .Projected.AllRequired 0 false }}
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.{{ .Responder }}(p)
+{{ else }}		return ctx.{{ .Responder }}(r)
+{{ end }}{{ end }}	}
+{{ with .Default }}{{ if .Project }}	var p {{ gotyperef .Projected .Projected.AllRequired 0 false }}
+	if err := goa.Project(r, &p); err != nil {
+		return err
+	}
+	return ctx.{{ .Responder }}(p)
+{{ else }}	return ctx.{{ .Responder }}(r)
+{{ end }}{{ else }}	return goa.ErrInternal("no response matches tag", "{{ .Tag }}", tag)
+{{ end }}}
 `
 
 	// ctxNoMTRespT generates the response helpers for responses with no known media type.
@@ -617,6 +1742,7 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
 func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
 {{ if .Response.MediaType }}	ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
+{{ end }}{{ range .Cache }}{{ . }}
 {{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
 	_, err := ctx.ResponseData.Write(resp)
 	return err{{ else }}
@@ -650,13 +1776,22 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-
+{{ if index .Payload.Metadata "validate:custom" }}
+// {{ gotypename .Payload nil 1 false }}Validator implements the custom validations of {{ gotypename .Payload nil 1 false }}, the
+// generated request decoding code calls it once the validation rules defined in the design succeed.
+var {{ gotypename .Payload nil 1 false }}Validator func(context.Context, {{ gotyperef .Payload .Payload.AllRequired 0 false }}) error
+{{ end }}
 {{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
-`
+{{ $redacted := redactedFields .Payload.AttributeDefinition }}{{ if $redacted }}
+// RedactedFields returns the names of the payload fields that must not be logged.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) RedactedFields() []string {
+	return {{ printf "%#v" $redacted }}
+}
+{{ end }}`
 	// ctrlT generates the controller interface for a given resource.
 	// template input: *ControllerTemplateData
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
@@ -667,6 +1802,45 @@ type {{ .Resource }}Controller interface {
 {{ end }}}
 `
 
+	// endpointsT generates the transport independent service interface and the endpoints that
+	// wrap it.
+	// template input: *ControllerTemplateData
+	endpointsT = `
+// {{ .Resource }}Service is the transport independent interface of the {{ .Resource }} actions called by
+// the {{ .Resource }} endpoints. The actions that stream, do not encode or decode their bodies, use
+// interceptors or do not have a success response that can be sent from a value are bound to HTTP
+// and are only served by the {{ .Resource }}Controller.
+type {{ .Resource }}Service interface {
+{{ range .Actions }}{{ if .Endpoint }}	{{ .Name }}(context.Context, *{{ .Endpoint.Request }}) {{ if .Endpoint.Result }}({{ .Endpoint.Result }}, error){{ else }}error{{ end }}
+{{ end }}{{ end }}}
+
+// {{ .Resource }}Endpoints lists the endpoints of the {{ .Resource }} actions.
+type {{ .Resource }}Endpoints struct {
+{{ range .Actions }}{{ if .Endpoint }}	{{ .Name }} goa.Endpoint
+{{ end }}{{ end }}}
+
+// New{{ .Resource }}Endpoints returns the endpoints that call the methods of the given service.
+func New{{ .Resource }}Endpoints(s {{ .Resource }}Service) *{{ .Resource }}Endpoints {
+	return &{{ .Resource }}Endpoints{
+{{ range .Actions }}{{ if .Endpoint }}		{{ .Name }}: New{{ .Name }}{{ $.Resource }}Endpoint(s),
+{{ end }}{{ end }}	}
+}
+
+// Use wraps all the {{ .Resource }} endpoints with the given endpoint middleware.
+func (e *{{ .Resource }}Endpoints) Use(m goa.EndpointMiddleware) {
+{{ range .Actions }}{{ if .Endpoint }}	e.{{ .Name }} = m(e.{{ .Name }})
+{{ end }}{{ end }}}
+{{ range .Actions }}{{ if .Endpoint }}
+// New{{ .Name }}{{ $.Resource }}Endpoint returns the endpoint that calls the {{ .Name }} method of the given
+// service. The endpoint request is a *{{ .Endpoint.Request }}{{ if .Endpoint.Result }} and its result type is {{ .Endpoint.Result }}{{ end }}.
+func New{{ .Name }}{{ $.Resource }}Endpoint(s {{ $.Resource }}Service) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+{{ if .Endpoint.Result }}		return s.{{ .Name }}(ctx, req.(*{{ .Endpoint.Request }}))
+{{ else }}		return nil, s.{{ .Name }}(ctx, req.(*{{ .Endpoint.Request }}))
+{{ end }}	}
+}
+{{ end }}{{ end }}`
+
 	// serviceT generates the service initialization code.
 	// template input: *ControllerTemplateData
 	serviceT = `
@@ -684,54 +1858,200 @@ func initService(service *goa.Service) {
 */}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}}
+{{ end }}{{ end }}{{ if .API.DefaultResponseContentType }}	service.SetDefaultContentType("{{ .API.DefaultResponseContentType }}")
+{{ end }}{{ if or .API.TrailingSlash .API.CaseInsensitiveRouting }}	service.SetRouting({{ printf "%q" .API.TrailingSlash }}, {{ .API.CaseInsensitiveRouting }})
+{{ end }}{{ if .API.MethodOverride }}	service.SetMethodOverride(true)
+{{ end }}}
+{{ with .API.Versioning }}{{ if ne .Scheme "path" }}
+// versionMux returns the service mux that routes the requests that select the given API version.
+func versionMux(service *goa.Service, version string) goa.ServeMux {
+	vm, ok := service.Mux.(*goa.VersionMux)
+	if !ok {
+{{ if eq .Scheme "header" }}		vm = goa.NewVersionMux(service.Mux, goa.HeaderSelectVersionFunc({{ printf "%q" .Name }}), goa.HeaderEncodeVersionFunc({{ printf "%q" .Name }}))
+{{ else }}		vm = goa.NewVersionMux(service.Mux, goa.MediaTypeSelectVersionFunc({{ printf "%q" .Name }}), goa.MediaTypeEncodeVersionFunc({{ printf "%q" .Name }}))
+{{ end }}		service.Mux = vm
+		service.Server.Handler = vm
+	}
+	return vm.Version(version)
+}
+{{ end }}{{ end }}`
+
+	// healthCheckT generates the code of the function that mounts the health check probes.
+	// template input: *design.HealthCheckDefinition
+	healthCheckT = `
+// MountHealthCheck mounts the liveness and readiness probes on the service mux under "{{ .LivePath }}"
+// and "{{ .ReadyPath }}". The readiness probe runs the given checkers. The probes are not affected by the
+// service and controller middlewares.
+func MountHealthCheck(service *goa.Service, checkers ...health.Checker) {
+	health.Mount(service, "{{ .LivePath }}", "{{ .ReadyPath }}", checkers...)
+}
 `
 
-	// mountT generates the code for a resource "Mount" function.
+	// docsT generates the code of the function that mounts the OpenAPI document handlers.
+	// template input: map[string]interface{}
+	docsT = `
+// MountDocs mounts the handler serving the OpenAPI document under "{{ .Docs.OpenAPIPath }}" on the service mux.
+{{ if .Docs.SwaggerUIPath }}// The Swagger UI page is served under "{{ .Docs.SwaggerUIPath }}".
+{{ end }}{{ if .Docs.ReDocPath }}// The ReDoc page is served under "{{ .Docs.ReDocPath }}".
+{{ end }}// The handlers are not affected by the service and controller middlewares.
+func MountDocs(service *goa.Service) {
+	apidocs.Mount(service, "{{ .Docs.OpenAPIPath }}", []byte(openAPISpec){{/*
+*/}}{{ if .Docs.SwaggerUIPath }}, apidocs.SwaggerUI("{{ .Docs.SwaggerUIPath }}"){{ end }}{{/*
+*/}}{{ if .Docs.ReDocPath }}, apidocs.ReDoc("{{ .Docs.ReDocPath }}"){{ end }})
+}
+
+// openAPISpec is the OpenAPI document describing the API.
+const openAPISpec = {{ printf "%q" .Spec }}
+`
+
+	// routesT generates the code of the functions that list the API routes and mount the route
+	// table debug handler.
+	// template input: []*ControllerTemplateData
+	routesT = `
+// Routes returns the method, path and handler name of the routes of all the API actions and file
+// servers mounted under the given base path prefix, the prefix is cleaned as in the Mount functions.
+// The list includes the HEAD routes served by the GET action handlers.
+func Routes(prefix string) []goa.Route {
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	return []goa.Route{
+{{ range . }}{{ $res := .Resource }}{{ range .Actions }}{{ $action := . }}{{ range mounts . }}{{/*
+*/}}		{Method: "{{ .Verb }}", Path: prefix + {{ printf "%q" .Path }}, Handler: "{{ $res }}.{{ $action.DesignName }}"},
+{{ if .Head }}		{Method: "HEAD", Path: prefix + {{ printf "%q" .Path }}, Handler: "{{ $res }}.{{ $action.DesignName }}"},
+{{ end }}{{ end }}{{ end }}{{ range .FileServers }}{{/*
+*/}}		{Method: "GET", Path: prefix + {{ printf "%q" .RequestPath }}, Handler: "{{ $res }}.serve"},
+{{ end }}{{ end }}	}
+}
+
+// MountRoutes mounts a handler under path on the service mux that renders the routes returned by
+// Routes for the given prefix as JSON and reports whether each route is mounted. The handler is not
+// affected by the service and controller middlewares.
+func MountRoutes(service *goa.Service, path, prefix string) {
+	goa.MountRoutes(service, path, Routes(prefix))
+}
+`
+
+	// mountPreflightT generates the registrations of the CORS preflight handlers.
 	// template input: *ControllerTemplateData
-	mountT = `
-// Mount{{ .Resource }}Controller "mounts" a {{ .Resource }} resource controller on the given service.
-func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
-	initService(service)
-	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
-*/}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
-{{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	mountPreflightT = `{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+*/}}	service.Mux.Handle("OPTIONS", prefix+{{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
+{{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+*/}}	service.Mux.Handle("OPTIONS", prefix+{{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $action.Name }}{{ $res }}Origin(cors.HandlePreflight()), nil))
+{{ end }}{{ end }}{{ end }}`
+
+	// mountDecodeT generates the code of an action handler that builds the action context and
+	// payload from the request.
+	// template input: map[string]interface{}
+	mountDecodeT = `		// Build the context
+		rctx, err := New{{ .Context }}(ctx, req, service)
 		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
 		}
-		// Build the context
-		rctx, err := New{{ .Context }}(ctx, req, service)
 		if err != nil {
 			return err
 		}
 {{ if .Payload }}		// Build the payload
-		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
+{{ if .Username }}		if goa.ContextRequest(ctx).Payload == nil {
+			// The payload holds the basic auth credentials, build it even if the request has no body.
+			if err := {{ .Unmarshal }}(ctx, service, req); err != nil {
+				return err
+			}
+		}
+{{ end }}		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.({{ gotyperef .Payload nil 1 false }})
 {{ if not .PayloadOptional }}		} else {
 			return goa.MissingPayloadError()
 {{ end }}		}
-{{ end }}		return ctrl.{{ .Name }}(rctx)
+{{ end }}`
+
+	// mountRegisterT generates the code that wraps an action handler with the action middleware
+	// and registers it on the service mux. It expects the $res and $action variables to be set.
+	// template input: map[string]interface{}
+	mountRegisterT = `{{ if .Timeout }}	h = middleware.Timeout({{ duration .Timeout }})(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ with .RateLimit }}	h = middleware.RateLimit({{ .RPS }}, {{ .Burst }}, {{ if .Header }}middleware.HeaderKey({{ printf "%q" .Header }}){{ else if .ByIP }}middleware.RemoteIP{{ else }}nil{{ end }})(h)
+{{ end }}{{ if .Origins }}	h = handle{{ .Name }}{{ $res }}Origin(h)
+{{ else if $.Origins }}	h = handle{{ $res }}Origin(h)
+{{ end }}{{ range mounts . }}	{{ .Mux }}.Handle("{{ .Verb }}", prefix+{{ printf "%q" .Path }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ if .Head }}	{{ .Mux }}.Handle("HEAD", prefix+{{ printf "%q" .Path }}, goa.HeadHandler(ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }})))
+{{ end }}	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", "{{ .Verb }} "+prefix+{{ printf "%q" .Path }}{{ with .Version }}, "version", {{ printf "%q" . }}{{ end }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ end }}`
+
+	// mountT generates the code for a resource "Mount" function.
+	// template input: *ControllerTemplateData
+	mountT = `
+// Mount{{ .Resource }}Controller "mounts" a {{ .Resource }} resource controller on the given service.
+func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
+	Mount{{ .Resource }}ControllerAt(service, ctrl, "")
+}
+
+// Mount{{ .Resource }}ControllerAt "mounts" a {{ .Resource }} resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func Mount{{ .Resource }}ControllerAt(service *goa.Service, ctrl {{ .Resource }}Controller, prefix string) {
+	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	var h goa.Handler
+` + mountPreflightT + `{{ range .Actions }}{{ $action := . }}
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+` + mountDecodeT + `		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*{{ .Context }})
+			rctx.Context = ctx
+{{ if .Interceptors }}			next := func() error { return ctrl.{{ .Name }}(rctx) }
+{{ range .Interceptors }}			if {{ .Var }} != nil {
+				n := next
+				next = func() error { return {{ .Var }}.{{ .Method }}(rctx, n) }
+			}
+{{ end }}			return nil, next()
+{{ else }}			return nil, ctrl.{{ .Name }}(rctx)
+{{ end }}		})
+		return err
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
-{{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
-	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
-{{ end }}{{ end }}{{ range .FileServers }}
+` + mountRegisterT + `{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .CacheControl }}	h = middleware.CacheControl({{ printf "%q" (join .CacheControl ", ") }}, {{ .MaxAge }})(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
-	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ end }}	service.Mux.Handle("GET", prefix+"{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", "GET "+prefix+{{ printf "%q" .RequestPath }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}}
 `
 
-	// handleCORST generates the code that checks whether a CORS request is authorized
+	// mountEndpointsT generates the code of the function that mounts the resource endpoints.
 	// template input: *ControllerTemplateData
-	handleCORST = `// handle{{ .Resource }}Origin applies the CORS response headers corresponding to the origin.
-func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
+	mountEndpointsT = `
+// Mount{{ .Resource }}Endpoints "mounts" the {{ .Resource }} endpoints on the given service under the given
+// base path prefix, e.g. "/api/v1", cleaned as in Mount{{ .Resource }}ControllerAt. The HTTP handlers
+// decode the requests, call the endpoints and encode their results. The actions that are not part
+// of {{ .Resource }}Service are not mounted.
+func Mount{{ .Resource }}Endpoints(service *goa.Service, endpoints *{{ .Resource }}Endpoints, prefix string) {
+	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	ctrl := service.NewController("{{ .Resource }}Endpoints")
+	var h goa.Handler
+` + mountPreflightT + `{{ range .Actions }}{{ $action := . }}{{ if .Endpoint }}
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+` + mountDecodeT + `{{ if .Endpoint.Result }}		res, err := goa.ServeEndpoint(ctx, rctx.endpointRequest(), endpoints.{{ .Name }})
+		if err != nil {
+			return err
+		}
+		r, _ := res.({{ .Endpoint.Result }})
+		return rctx.{{ .Endpoint.Responder }}(r)
+{{ else }}		if _, err := goa.ServeEndpoint(ctx, rctx.endpointRequest(), endpoints.{{ .Name }}); err != nil {
+			return err
+		}
+		return rctx.{{ .Endpoint.Responder }}()
+{{ end }}	}
+` + mountRegisterT + `{{ end }}{{ end }}}
+`
+
+	// handleCORST generates the code that checks whether a CORS request is authorized
+	// template input: map[string]interface{}
+	handleCORST = `// {{ .Handler }} applies the CORS response headers corresponding to the origin.
+func {{ .Handler }}(h goa.Handler) goa.Handler {
 {{ range $i, $policy := .Origins }}{{ if $policy.Regexp }}	spec{{$i}} := regexp.MustCompile({{ printf "%q" $policy.Origin }})
 {{ end }}{{ end }}
 	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -760,25 +2080,96 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 }
 `
 
+	// multipartT generates the code that decodes multipart request bodies into payloads.
+	// template input: map[string]interface{}
+	multipartT = `	var err error
+	if err = req.ParseMultipartForm(service.MaxMultipartMemory); err != nil {
+{{ template "decodeErr" . }}	}
+	payload := &{{ gotypename .Payload nil 1 true }}{}
+{{ range $name, $att := .Payload.Type.ToObject }}{{ $field := printf "payload.%s" (goifyatt $att $name true) }}{{/*
+
+// FILE
+*/}}{{ if isFile $att }}	if files := req.MultipartForm.File["{{ $name }}"]; len(files) > 0 {
+		{{ $field }} = files[0]
+	}
+{{/*
+
+// ARRAY OF FILES
+*/}}{{ else if and $att.Type.IsArray (isFile (arrayAttribute $att)) }}	if files := req.MultipartForm.File["{{ $name }}"]; len(files) > 0 {
+		{{ $field }} = make({{ gotypedef $att 2 true true }}, len(files))
+		for i, f := range files {
+			{{ $field }}[i] = *f
+		}
+	}
+{{/*
+
+// PRIMITIVE
+*/}}{{ else if $att.Type.IsPrimitive }}	if vals := req.MultipartForm.Value["{{ $name }}"]; len(vals) > 0 {
+		raw{{ goify $name true }} := vals[0]
+{{ template "Coerce" (newCoerceData $name $att true $field 2) }}	}
+{{/*
+
+// ARRAY OF PRIMITIVES
+*/}}{{ else if and $att.Type.IsArray (arrayAttribute $att).Type.IsPrimitive }}	if vals := req.MultipartForm.Value["{{ $name }}"]; len(vals) > 0 {
+		{{ $field }} = make({{ gotypedef $att 2 true true }}, len(vals))
+		for i, raw{{ goify $name true }} := range vals {
+{{ template "Coerce" (newCoerceData $name (arrayAttribute $att) false (printf "%s[i]" $field) 3) }}		}
+	}
+{{/*
+
+// OTHER TYPES ARE JSON ENCODED
+*/}}{{ else }}	if vals := req.MultipartForm.Value["{{ $name }}"]; len(vals) > 0 {
+		if err2 := json.Unmarshal([]byte(vals[0]), &{{ $field }}); err2 != nil {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ $name }}", vals[0], "{{ $att.Type.Name }}"))
+		}
+	}
+{{ end }}{{ end }}	if err != nil {
+		return err
+	}`
+
 	// unmarshalT generates the code for an action payload unmarshal function.
 	// template input: *ControllerTemplateData
-	unmarshalT = `{{ range .Actions }}{{ if .Payload }}
+	unmarshalT = `{{ define "Coerce" }}` + coerceT + `{{ end }}{{ define "multipart" }}` + multipartT + `{{ end }}{{ define "decodeErr" }}{{ if .MaxBodyLength }}		if err.Error() == "http: request body too large" {
+			return goa.ErrRequestBodyTooLarge("request body length exceeds {{ .MaxBodyLength }} bytes")
+		}
+{{ end }}		return err
+{{ end }}{{ range .Actions }}{{ if .Payload }}
 // {{ .Unmarshal }} unmarshals the request body into the context request data Payload field.
 func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Request) error {
-	{{ if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
-	if err := service.DecodeRequest(req, payload); err != nil {
-		return err
-	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
-	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
+{{ if .MaxBodyLength }}	if req.ContentLength > {{ .MaxBodyLength }} {
+		return goa.ErrRequestBodyTooLarge("request body length exceeds {{ .MaxBodyLength }} bytes")
+	}
+	req.Body = http.MaxBytesReader(goa.ContextResponse(ctx), req.Body, {{ .MaxBodyLength }})
+{{ end }}{{ if .PayloadMultipart }}{{ template "multipart" . }}{{ else if .Payload.IsObject }}	payload := &{{ gotypename .Payload nil 1 true }}{}
+{{ if .Username }}	if req.ContentLength != 0 {
+		if err := service.DecodeRequest(req, payload); err != nil {
+{{ template "decodeErr" . }}		}
+	}
+	user, pass, ok := req.BasicAuth()
+	if !ok {
+		goa.ContextResponse(ctx).Header().Set("WWW-Authenticate", {{ printf "%q" (printf "Basic realm=%q" .Realm) }})
+		return goa.ErrUnauthorized("missing basic auth credentials")
+	}
+	payload.{{ .Username }} = &user
+	payload.{{ .Password }} = &pass{{ else }}	if err := service.DecodeRequest(req, payload); err != nil {
+{{ template "decodeErr" . }}	}{{ end }}{{ else }}	var payload {{ gotypename .Payload nil 1 false }}
 	if err := service.DecodeRequest(req, &payload); err != nil {
-		return err
-	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
+{{ template "decodeErr" . }}	}{{ end }}{{ if .Payload.IsObject }}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
+	payload.Finalize(){{ end }}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
 	if err := payload.Validate(); err != nil {
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
 		return err
-	}{{ end }}
-	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
+	}{{ end }}{{ if and .Payload.IsObject (index .Payload.Metadata "validate:custom") }}
+	pub := payload.Publicize()
+	if {{ gotypename .Payload nil 1 false }}Validator != nil {
+		if err := {{ gotypename .Payload nil 1 false }}Validator(ctx, pub); err != nil {
+			goa.ContextRequest(ctx).Payload = payload
+			return err
+		}
+	}
+	goa.ContextRequest(ctx).Payload = pub{{ else }}
+	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}{{ end }}
 	return nil
 }
 {{ end }}
@@ -807,6 +2198,16 @@ func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 	return
 }
 {{ end }}
+`
+
+	// mediaTypeHypermediaT generates the JSON marshaler of media types that use a hypermedia
+	// format.
+	// template input: map[string]interface{}
+	mediaTypeHypermediaT = `{{ $typeName := gotypename .MediaType .MediaType.AllRequired 0 false }}// MarshalJSON renders the {{ $typeName }} media type instance as a {{ if eq .Format "hal" }}HAL resource{{ else }}JSON:API resource object{{ end }}.
+func (mt {{ gotyperef .MediaType .MediaType.AllRequired 0 false }}) MarshalJSON() ([]byte, error) {
+	type plain {{ $typeName }}
+	return {{ if eq .Format "hal" }}goa.MarshalHAL((*plain)(mt)){{ else }}goa.MarshalJSONAPI((*plain)(mt), {{ printf "%q" .ResourceType }}){{ end }}
+}
 `
 
 	// mediaTypeLinkT generates the code for a media type link.
@@ -843,11 +2244,192 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ if index .Metadata "validate:custom" }}
+// {{ $typeName }}Validator implements the custom validations of {{ $typeName }}, the generated
+// request decoding code calls it once the validation rules defined in the design succeed.
+var {{ $typeName }}Validator func(context.Context, {{ gotyperef . .AllRequired 0 false }}) error
+{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
+{{ $redacted := redactedFields .AttributeDefinition }}{{ if $redacted }}
+// RedactedFields returns the names of the {{ $typeName }} fields that must not be logged.
+func (ut {{ gotyperef . .AllRequired 0 false }}) RedactedFields() []string {
+	return {{ printf "%#v" $redacted }}
+}
+{{ end }}`
+
+	// unionT generates the code for a user type defined with OneOf.
+	// template input: *design.UserTypeDefinition
+	unionT = `{{ $privateTypeName := gotypename . .AllRequired 0 true }}{{ $typeName := gotypename . .AllRequired 0 false }}{{/*
+*/}}{{ $disc := .Union.Discriminator }}{{ $variantName := printf "%sVariant" $typeName }}{{/*
+*/}}// {{ gotypedesc . false }}
+type {{ $privateTypeName }} struct {
+	// Value is the variant value, one of {{ range $i, $v := .Union.Variants }}{{ if $i }}, {{ end }}*{{ gotypename $v.Type $v.Type.AllRequired 0 true }}{{ end }}.
+	Value interface{}
+}
+
+// UnmarshalJSON decodes the variant identified by the {{ printf "%q" $disc }} attribute.
+func (ut *{{ $privateTypeName }}) UnmarshalJSON(data []byte) error {
+	var disc struct {
+		Value *string {{ printf "json:%q" $disc | printf "%q" }}
+	}
+	if err := json.Unmarshal(data, &disc); err != nil {
+		return err
+	}
+	if disc.Value == nil {
+		return goa.MissingAttributeError("request", {{ printf "%q" $disc }})
+	}
+	switch *disc.Value {
+{{ range .Union.Variants }}	case {{ printf "%q" .Value }}:
+		var v {{ gotypename .Type .Type.AllRequired 0 true }}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		ut.Value = &v
+{{ end }}	default:
+		return goa.InvalidEnumValueError({{ printf "request.%s" $disc | printf "%q" }}, *disc.Value, []interface{}{ {{- range $i, $v := .Union.Variants }}{{ if $i }}, {{ end }}{{ printf "%q" $v.Value }}{{ end -}} })
+	}
+	return nil
+}
+
+// Finalize sets the default values of the {{ $privateTypeName }} variant value.
+func (ut *{{ $privateTypeName }}) Finalize() {
+	if v, ok := ut.Value.(interface {
+		Finalize()
+	}); ok {
+		v.Finalize()
+	}
+}
+
+// Validate validates the {{ $privateTypeName }} variant value.
+func (ut *{{ $privateTypeName }}) Validate() (err error) {
+	if ut.Value == nil {
+		return goa.MissingAttributeError("request", {{ printf "%q" $disc }})
+	}
+	if v, ok := ut.Value.(interface {
+		Validate() error
+	}); ok {
+		err = v.Validate()
+	}
+	return
+}
+
+// Publicize creates {{ $typeName }} from {{ $privateTypeName }}
+func (ut *{{ $privateTypeName }}) Publicize() *{{ $typeName }} {
+	var pub {{ $typeName }}
+	switch v := ut.Value.(type) {
+{{ range .Union.Variants }}	case *{{ gotypename .Type .Type.AllRequired 0 true }}:
+		pub.Value = v.Publicize()
+{{ end }}	}
+	return &pub
+}
+
+// {{ gotypedesc . true }}
+type {{ $typeName }} struct {
+	// Value is the variant value, one of {{ range $i, $v := .Union.Variants }}{{ if $i }}, {{ end }}*{{ gotypename $v.Type $v.Type.AllRequired 0 false }}{{ end }}.
+	Value {{ $variantName }}
+}
+
+// {{ $variantName }} is the interface implemented by the {{ $typeName }} variants.
+type {{ $variantName }} interface {
+	is{{ $variantName }}()
+}
+{{ range .Union.Variants }}
+func (*{{ gotypename .Type .Type.AllRequired 0 false }}) is{{ $variantName }}() {}
+{{ end }}
+// MarshalJSON encodes the variant value along with the {{ printf "%q" $disc }} attribute that
+// identifies it.
+func (ut {{ $typeName }}) MarshalJSON() ([]byte, error) {
+	var tag string
+	switch ut.Value.(type) {
+{{ range .Union.Variants }}	case *{{ gotypename .Type .Type.AllRequired 0 false }}:
+		tag = {{ unionTag $disc .Value }}
+{{ end }}	case nil:
+		return []byte("null"), nil
+	default:
+		return nil, fmt.Errorf("invalid {{ $typeName }} variant %T", ut.Value)
+	}
+	body, err := json.Marshal(ut.Value)
+	if err != nil || len(body) < 2 || body[0] != '{' {
+		return body, err
+	}
+	if len(body) == 2 {
+		return []byte("{" + tag + "}"), nil
+	}
+	return append([]byte("{"+tag+","), body[1:]...), nil
+}
+
+// UnmarshalJSON decodes the variant identified by the {{ printf "%q" $disc }} attribute.
+func (ut *{{ $typeName }}) UnmarshalJSON(data []byte) error {
+	var disc struct {
+		Value *string {{ printf "json:%q" $disc | printf "%q" }}
+	}
+	if err := json.Unmarshal(data, &disc); err != nil {
+		return err
+	}
+	if disc.Value == nil {
+		return goa.MissingAttributeError("type", {{ printf "%q" $disc }})
+	}
+	switch *disc.Value {
+{{ range .Union.Variants }}	case {{ printf "%q" .Value }}:
+		var v {{ gotypename .Type .Type.AllRequired 0 false }}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		ut.Value = &v
+{{ end }}	default:
+		return goa.InvalidEnumValueError({{ printf "type.%s" $disc | printf "%q" }}, *disc.Value, []interface{}{ {{- range $i, $v := .Union.Variants }}{{ if $i }}, {{ end }}{{ printf "%q" $v.Value }}{{ end -}} })
+	}
+	return nil
+}
+
+// Validate validates the {{ $typeName }} variant value.
+func (ut *{{ $typeName }}) Validate() (err error) {
+	if ut.Value == nil {
+		return goa.MissingAttributeError("type", {{ printf "%q" $disc }})
+	}
+	if v, ok := ut.Value.(interface {
+		Validate() error
+	}); ok {
+		err = v.Validate()
+	}
+	return
+}
+`
+
+	// conversionT generates a method that converts a user type to or from an external type.
+	// template input: *ConversionTemplateData
+	conversionT = `{{ if .Create }}// {{ .Method }} initializes the fields of ut from the fields of v.
+func (ut *{{ .TypeName }}) {{ .Method }}(v *{{ .External }}) {
+{{ .Code }}}
+{{ else }}// {{ .Method }} creates an instance of {{ .External }} initialized from the fields of ut.
+func (ut *{{ .TypeName }}) {{ .Method }}() *{{ .External }} {
+	t := &{{ .External }}{}
+{{ .Code }}	return t
+}
+{{ end }}`
+
+	// interceptorT generates the interface of an interceptor and the function that registers it.
+	// template input: *InterceptorTemplateData
+	interceptorT = `// {{ .TypeName }} is the interface implemented by the {{ printf "%q" .Name }} interceptor. Its
+// methods are called in place of the intercepted actions with the action context and a function
+// that runs the action. A method may respond in place of the action by calling the context
+// response methods without calling next.
+type {{ .TypeName }} interface {
+{{ range .Actions }}	// {{ method . }} intercepts the {{ .Name }} action of the {{ .Parent.Name }} resource.
+	{{ method . }}(ctx *{{ method . }}Context, next func() error) error
+{{ end }}}
+
+// {{ .VarName }} is the {{ printf "%q" .Name }} interceptor registered with Use{{ .TypeName }}.
+var {{ .VarName }} {{ .TypeName }}
+
+// Use{{ .TypeName }} registers the {{ printf "%q" .Name }} interceptor, it must be called before the
+// service starts. The intercepted actions run without the interceptor until it is registered.
+func Use{{ .TypeName }}(i {{ .TypeName }}) {
+	{{ .VarName }} = i
+}
 `
 
 	// securitySchemesT generates the code for the security module.
@@ -868,7 +2450,7 @@ func {{ $funcName }}(service *goa.Service, middleware goa.Middleware) {
 func {{ $funcName }}() *goa.{{ .Context }} {
 	def := goa.{{ .Context }}{
 {{ if eq .Context "APIKeySecurity" }}{{/*
-*/}}		In:   {{ if eq .In "header" }}goa.LocHeader{{ else }}goa.LocQuery{{ end }},
+*/}}		In:   {{ if eq .In "header" }}goa.LocHeader{{ else if eq .In "cookie" }}goa.LocCookie{{ else }}goa.LocQuery{{ end }},
 		Name: {{ printf "%q" .Name }},
 {{ else if eq .Context "OAuth2Security" }}{{/*
 */}}		Flow:             {{ printf "%q" .Flow }},
@@ -880,7 +2462,7 @@ func {{ $funcName }}() *goa.{{ .Context }} {
 */}}		},{{ end }}{{/*
 */}}{{ else if eq .Context "BasicAuthSecurity" }}{{/*
 */}}{{ else if eq .Context "JWTSecurity" }}{{/*
-*/}}		In:   {{ if eq .In "header" }}goa.LocHeader{{ else }}goa.LocQuery{{ end }},
+*/}}		In:   {{ if eq .In "header" }}goa.LocHeader{{ else if eq .In "cookie" }}goa.LocCookie{{ else }}goa.LocQuery{{ end }},
 		Name:             {{ printf "%q" .Name }},
 		TokenURL:         {{ printf "%q" .TokenURL }},{{ with .Scopes }}
 		Scopes: map[string]string{
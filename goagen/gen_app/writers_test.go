@@ -3,6 +3,7 @@ package genapp_test
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/design/apidsl"
@@ -94,6 +95,140 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with cookies", func() {
+				It("writes the cookies code", func() {
+					data.Cookies = &design.AttributeDefinition{
+						Type: design.Object{
+							"sid": &design.AttributeDefinition{Type: design.String},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"sid"}},
+					}
+					data.ResponseCookies = &design.AttributeDefinition{
+						Type: design.Object{
+							"session_id": &design.AttributeDefinition{
+								Type: design.String,
+								Metadata: dslengine.MetadataDefinition{
+									"cookie:max-age":   {"3600"},
+									"cookie:http-only": {"true"},
+									"cookie:same-site": {"lax"},
+								},
+							},
+						},
+					}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("	Sid string\n"))
+					Ω(written).Should(ContainSubstring(cookieContextFactory))
+					Ω(written).Should(ContainSubstring(cookieSetter))
+				})
+			})
+
+			Context("with pagination", func() {
+				It("writes the Paginate method", func() {
+					data.Pagination = "offset"
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(offsetPaginate))
+				})
+			})
+
+			Context("with If-Match support", func() {
+				It("writes the CheckETag method", func() {
+					data.IfMatch = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(checkETag))
+				})
+			})
+
+			Context("with an asynchronous action", func() {
+				It("writes the AcceptedJob method", func() {
+					data.Async = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(acceptedJob))
+				})
+			})
+
+			Context("with caching rules", func() {
+				BeforeEach(func() {
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:         "OK",
+						Status:       200,
+						CacheControl: []string{"max-age=300", "public"},
+						MaxAge:       300,
+						Vary:         []string{"Accept", "Accept-Encoding"},
+					}}
+				})
+
+				It("the generated code sets the cache headers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(cacheHeaders))
+				})
+			})
+
+			Context("with a cacheable media type", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"version":    {Type: design.Integer},
+									"updated_at": {Type: design.DateTime},
+								},
+								Validation: &dslengine.ValidationDefinition{Required: []string{"version"}},
+								Metadata: dslengine.MetadataDefinition{
+									"cache:etag":          {"version"},
+									"cache:last-modified": {"updated_at"},
+								},
+							},
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+					}}
+				})
+
+				It("the generated code sets the validators and handles conditional requests", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(conditionalRespond))
+				})
+			})
+
 			Context("with a media type setting a ContentType", func() {
 				var contentType = "application/json"
 
@@ -136,6 +271,304 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with response headers mapped to media type attributes", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"etag": {Type: design.String},
+									"tags": {Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}},
+								},
+							},
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					headers := &design.AttributeDefinition{
+						Type: design.Object{
+							"ETag": {
+								Type:     design.String,
+								Metadata: dslengine.MetadataDefinition{"header:attribute": {"etag"}},
+							},
+							"X-Tags": {
+								Type:     design.String,
+								Metadata: dslengine.MetadataDefinition{"header:attribute": {"tags"}},
+							},
+						},
+					}
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+						Headers:   headers,
+					}}
+				})
+
+				It("the generated code sets the headers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`	if r != nil {
+		if r.Etag != nil {
+			ctx.ResponseData.Header().Set("ETag", *r.Etag)
+		}
+		for _, v := range r.Tags {
+			ctx.ResponseData.Header().Add("X-Tags", strconv.Itoa(v))
+		}
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
+				})
+			})
+
+			Context("with tagged responses", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"outcome": {Type: design.String}},
+							},
+							TypeName: "GoaTest",
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+						},
+						"Created": {
+							Name:         "Created",
+							Status:       201,
+							MediaType:    mediaType.Identifier,
+							TagAttribute: "outcome",
+							TagValue:     "created",
+						},
+						"Accepted": {
+							Name:         "Accepted",
+							Status:       202,
+							MediaType:    mediaType.Identifier,
+							TagAttribute: "outcome",
+							TagValue:     "accepted",
+						},
+					}
+				})
+
+				It("the generated code selects the response using the tag", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(tagRespond))
+				})
+
+				Context("and no untagged response", func() {
+					BeforeEach(func() {
+						delete(responses, "OK")
+					})
+
+					It("the generated code returns an error when no tag matches", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`	return goa.ErrInternal("no response matches tag", "outcome", tag)
+}`))
+					})
+				})
+
+				Context("and a response using another view", func() {
+					BeforeEach(func() {
+						mediaType := design.Design.MediaTypes["application/vnd.goa.test"]
+						mediaType.Type.ToObject()["id"] = &design.AttributeDefinition{Type: design.Integer}
+						mediaType.Type.ToObject()["name"] = &design.AttributeDefinition{Type: design.String}
+						mediaType.Views["tiny"] = &design.ViewDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":      {Type: design.Integer},
+									"outcome": {Type: design.String},
+								},
+							},
+							Name:   "tiny",
+							Parent: mediaType,
+						}
+						mediaType.Views["default"] = &design.ViewDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"name":    {Type: design.String},
+									"outcome": {Type: design.String},
+								},
+							},
+							Name:   "default",
+							Parent: mediaType,
+						}
+						responses["Accepted"].ViewName = "tiny"
+					})
+
+					It("the generated code projects the result onto the view of the response", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(tagRespondView))
+					})
+				})
+			})
+
+			Context("with a media type that defines multiple views", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":   {Type: design.Integer},
+									"name": {Type: design.String},
+								},
+							},
+							TypeName: "GoaTest",
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{
+						"default": {
+							AttributeDefinition: mediaType.AttributeDefinition,
+							Name:                "default",
+							Parent:              mediaType,
+						},
+						"tiny": {
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"id": {Type: design.Integer}},
+							},
+							Name:   "tiny",
+							Parent: mediaType,
+						},
+					}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{"OK": {
+						Name:      "OK",
+						Status:    200,
+						MediaType: mediaType.Identifier,
+					}}
+				})
+
+				It("the generated code renders the requested view", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("func (ctx *ListBottleContext) OKTiny(r *GoaTestTiny) error {"))
+					Ω(written).Should(ContainSubstring(viewResponse))
+				})
+
+				Context("that render attributes the default view does not render", func() {
+					BeforeEach(func() {
+						mediaType := design.Design.MediaTypes["application/vnd.goa.test"]
+						mediaType.Type.ToObject()["origin"] = &design.AttributeDefinition{Type: design.String}
+						mediaType.Views["tiny"].Type.ToObject()["origin"] = &design.AttributeDefinition{Type: design.String}
+						mediaType.Views["default"] = &design.ViewDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id":   {Type: design.Integer},
+									"name": {Type: design.String},
+								},
+							},
+							Name:   "default",
+							Parent: mediaType,
+						}
+					})
+
+					It("the generated code projects the full media type onto the requested view", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(fullViewResponse))
+					})
+				})
+			})
+
+			Context("with a redirect response", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type:       design.Object{"id": {Type: design.Integer}},
+								Validation: &dslengine.ValidationDefinition{Required: []string{"id"}},
+							},
+							TypeName: "GoaTest",
+						},
+						Identifier: "application/vnd.goa.test",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"Found": {
+							Name:        "Found",
+							Status:      302,
+							MediaType:   mediaType.Identifier,
+							RedirectURL: "/bottles/:id",
+						},
+						"MovedPermanently": {
+							Name:        "MovedPermanently",
+							Status:      301,
+							RedirectURL: "/new",
+						},
+					}
+				})
+
+				It("the generated code sets the Location header", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(staticRedirect))
+					Ω(written).Should(ContainSubstring(attributeRedirect))
+				})
+			})
+
 			Context("with a collection media type", func() {
 				BeforeEach(func() {
 					elemType := &design.MediaTypeDefinition{
@@ -816,6 +1249,76 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with streaming types", func() {
+				It("writes the stream code", func() {
+					msg := &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+							"body": {Type: design.String},
+						}},
+						TypeName: "Message",
+					}
+					data.StreamingPayload = msg
+					data.StreamingResult = msg
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(streamContext))
+				})
+
+				It("starts the keep alive pings", func() {
+					data.StreamingResult = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{Type: design.String},
+						TypeName:            "Message",
+					}
+					data.KeepAlive = 30 * time.Second
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(ContainSubstring(streamKeepAliveContext))
+				})
+
+				It("writes the newline delimited JSON stream code", func() {
+					data.StreamingResult = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+							"body": {Type: design.String},
+						}},
+						TypeName: "Message",
+					}
+					data.NDJSON = true
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(ndjsonStreamContext))
+					Ω(written).ShouldNot(ContainSubstring("websocket"))
+				})
+			})
+
+			Context("with errors", func() {
+				It("writes the error classes and constructors", func() {
+					conflict := &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+							"reason": {Type: design.String},
+						}},
+						TypeName: "Conflict",
+					}
+					data.Errors = []*design.ErrorDefinition{
+						{Name: "not_found", Type: design.ErrorMedia, Status: 404},
+						{Name: "conflict", Type: conflict, Status: 409},
+					}
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(errorsContext))
+				})
+			})
+
 			Context("with a simple payload", func() {
 				BeforeEach(func() {
 					design.Design = new(design.APIDefinition)
@@ -837,6 +1340,50 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with a raw request and response body", func() {
+				It("exposes the request body and writes the WriteBody method", func() {
+					data.SkipRequestBody = true
+					data.SkipResponseBody = true
+					data.MaxBodyLength = 1024
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(rawBodyContext))
+					Ω(written).Should(ContainSubstring(rawBodyContextFactory))
+					Ω(written).Should(ContainSubstring(rawBodyWriteBody))
+				})
+			})
+
+			Context("with a payload with sensitive fields", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					sensitive := dslengine.MetadataDefinition{"log:redact": nil}
+					payload = &design.UserTypeDefinition{
+						AttributeDefinition: &design.AttributeDefinition{
+							Type: design.Object{
+								"name":     {Type: design.String},
+								"password": {Type: design.String, Metadata: sensitive},
+								"card": {Type: design.Object{
+									"number": {Type: design.String, Metadata: sensitive},
+								}},
+							},
+						},
+						TypeName: "ListBottlePayload",
+					}
+				})
+
+				It("writes the RedactedFields method", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadRedactedFields))
+				})
+			})
+
 			Context("with a object payload", func() {
 				BeforeEach(func() {
 					design.Design = new(design.APIDefinition)
@@ -932,19 +1479,25 @@ var _ = Describe("ControllersWriter", func() {
 			filePath := "swagger/swagger.json"
 			var origins []*design.CORSDefinition
 			var preflightPaths []string
+			var cacheControl []string
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
 				origins = nil
 				preflightPaths = nil
+				cacheControl = nil
 			})
 
 			JustBeforeEach(func() {
 				codegen.TempCount = 0
 				fileServer := &design.FileServerDefinition{
-					FilePath:    filePath,
-					RequestPath: requestPath,
+					FilePath:     filePath,
+					RequestPath:  requestPath,
+					CacheControl: cacheControl,
+				}
+				if cacheControl != nil {
+					fileServer.MaxAge = 3600
 				}
 				d := &genapp.ControllerTemplateData{
 					API:            &design.APIDefinition{},
@@ -966,6 +1519,22 @@ var _ = Describe("ControllersWriter", func() {
 				Ω(written).Should(ContainSubstring(simpleFileServer))
 			})
 
+			Context("with caching rules", func() {
+				BeforeEach(func() {
+					cacheControl = []string{"max-age=3600", "public"}
+				})
+
+				It("applies the caching rules", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(ContainSubstring(`	h = ctrl.FileHandler("/swagger.json", "swagger/swagger.json")
+	h = middleware.CacheControl("max-age=3600, public", 3600)(h)
+`))
+				})
+			})
+
 			Context("with CORS", func() {
 				BeforeEach(func() {
 					origins = []*design.CORSDefinition{
@@ -997,11 +1566,17 @@ var _ = Describe("ControllersWriter", func() {
 			var actions, verbs, paths, contexts, unmarshals []string
 			var payloads []*design.UserTypeDefinition
 			var encoders, decoders []*genapp.EncoderTemplateData
-			var origins []*design.CORSDefinition
+			var origins, actionOrigins []*design.CORSDefinition
+			var rateLimit *design.RateLimitDefinition
+			var timeout time.Duration
+			var endpoints []*genapp.EndpointTemplateData
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
+				actionOrigins = nil
+				rateLimit = nil
+				timeout = 0
 				actions = nil
 				verbs = nil
 				paths = nil
@@ -1011,6 +1586,7 @@ var _ = Describe("ControllersWriter", func() {
 				encoders = nil
 				decoders = nil
 				origins = nil
+				endpoints = nil
 			})
 
 			JustBeforeEach(func() {
@@ -1042,6 +1618,19 @@ var _ = Describe("ControllersWriter", func() {
 						"Unmarshal": unmarshal,
 						"Payload":   payload,
 					}
+					if actionOrigins != nil {
+						as[i]["Origins"] = actionOrigins
+						as[i]["PreflightPaths"] = []string{paths[i]}
+					}
+					if rateLimit != nil {
+						as[i]["RateLimit"] = rateLimit
+					}
+					if timeout != 0 {
+						as[i]["Timeout"] = timeout
+					}
+					if i < len(endpoints) {
+						as[i]["Endpoint"] = endpoints[i]
+					}
 				}
 				if len(as) > 0 {
 					d.API = api
@@ -1082,6 +1671,106 @@ var _ = Describe("ControllersWriter", func() {
 					Ω(written).ShouldNot(BeEmpty())
 					Ω(written).Should(ContainSubstring(simpleController))
 					Ω(written).Should(ContainSubstring(simpleMount))
+					Ω(written).ShouldNot(ContainSubstring("BottlesEndpoints"))
+				})
+
+				It("writes the routes", func() {
+					err := writer.WriteRoutes(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(ContainSubstring(`func Routes(prefix string) []goa.Route {
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	return []goa.Route{
+		{Method: "GET", Path: prefix + "/accounts/:accountID/bottles", Handler: "Bottles.list"},
+		{Method: "HEAD", Path: prefix + "/accounts/:accountID/bottles", Handler: "Bottles.list"},
+	}`))
+					Ω(string(b)).Should(ContainSubstring(`goa.MountRoutes(service, path, Routes(prefix))`))
+				})
+			})
+
+			Context("with an action endpoint", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					endpoints = []*genapp.EndpointTemplateData{
+						{Request: "ListBottleRequest", Result: "BottleCollection", Responder: "OK"},
+					}
+				})
+
+				It("writes the service interface, the endpoints and the function that mounts them", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(simpleMount))
+					Ω(written).Should(ContainSubstring(simpleEndpoints))
+					Ω(written).Should(ContainSubstring(simpleEndpointsMount))
+				})
+
+				Context("with no result", func() {
+					BeforeEach(func() {
+						endpoints[0].Result = ""
+						endpoints[0].Responder = "NoContent"
+					})
+
+					It("calls the response method without argument", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`	List(context.Context, *ListBottleRequest) error
+`))
+						Ω(written).Should(ContainSubstring(`		return nil, s.List(ctx, req.(*ListBottleRequest))
+`))
+						Ω(written).Should(ContainSubstring(`		if _, err := goa.ServeEndpoint(ctx, rctx.endpointRequest(), endpoints.List); err != nil {
+			return err
+		}
+		return rctx.NoContent()
+`))
+					})
+				})
+			})
+
+			Context("with a rate limited action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					rateLimit = &design.RateLimitDefinition{RPS: 2.5, Burst: 10, Header: "X-API-Key"}
+				})
+
+				It("wraps the handler with the rate limiter", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(ContainSubstring(`	h = middleware.RateLimit(2.5, 10, middleware.HeaderKey("X-API-Key"))(h)
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))`))
+				})
+			})
+
+			Context("with an action with a timeout", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					timeout = 2 * time.Second
+				})
+
+				It("wraps the handler with the timeout middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(b)).Should(ContainSubstring(`	h = middleware.Timeout(2 * time.Second)(h)
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))`))
 				})
 			})
 
@@ -1150,6 +1839,38 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with actions that take a payload with custom validation", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					unmarshals = []string{"unmarshalListBottlePayload"}
+					payloads = []*design.UserTypeDefinition{
+						{
+							TypeName: "ListBottlePayload",
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"id": &design.AttributeDefinition{
+										Type: design.String,
+									},
+								},
+								Metadata: dslengine.MetadataDefinition{"validate:custom": {"true"}},
+							},
+						},
+					}
+				})
+
+				It("writes the payload unmarshal function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadCustomValidationObjUnmarshal))
+				})
+			})
+
 			Context("with multiple controllers", func() {
 				BeforeEach(func() {
 					actions = []string{"list", "show"}
@@ -1238,6 +1959,33 @@ var _ = Describe("ControllersWriter", func() {
 				})
 			})
 
+			Context("with action origins", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts"}
+					contexts = []string{"ListBottleContext"}
+					actionOrigins = []*design.CORSDefinition{
+						{
+							Origin:  "here.example.com",
+							Methods: []string{"GET"},
+						},
+					}
+				})
+
+				It("writes the controller code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(actionOriginsPreflight))
+					Ω(written).Should(ContainSubstring(actionOriginsIntegration))
+					Ω(written).Should(ContainSubstring(actionOriginsHandler))
+				})
+			})
+
 			Context("with regexp origins", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
@@ -1275,6 +2023,27 @@ var _ = Describe("ControllersWriter", func() {
 			})
 
 		})
+
+		Context("with a health check", func() {
+			It("writes the function mounting the probes", func() {
+				err := writer.WriteHealthCheck(&design.HealthCheckDefinition{LivePath: "/healthz", ReadyPath: "/readyz"})
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).Should(ContainSubstring(healthCheck))
+			})
+		})
+
+		Context("with served documentation", func() {
+			It("writes the function mounting the documentation handlers", func() {
+				docs := &design.DocsDefinition{OpenAPIPath: "/openapi.json", SwaggerUIPath: "/docs"}
+				err := writer.WriteDocs(docs, []byte(`{"openapi":"3.0.3"}`))
+				Ω(err).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(filename)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(b)).Should(ContainSubstring(mountDocs))
+			})
+		})
 	})
 })
 
@@ -1480,6 +2249,28 @@ var _ = Describe("UserTypesWriter", func() {
 				})
 			})
 
+			Context("with a user type with custom validation", func() {
+				BeforeEach(func() {
+					attDef = &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{
+								Type: design.String,
+							},
+						},
+						Metadata: dslengine.MetadataDefinition{"validate:custom": {"true"}},
+					}
+					typeName = "SimplePayload"
+				})
+				It("writes the validator variable", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(customValidationUserType))
+				})
+			})
+
 			Context("with a user type including hash", func() {
 				BeforeEach(func() {
 					attDef = &design.AttributeDefinition{
@@ -1525,7 +2316,123 @@ var _ = Describe("UserTypesWriter", func() {
 	})
 })
 
+var _ = Describe("MediaTypesWriter", func() {
+	var writer *genapp.MediaTypesWriter
+	var workspace *codegen.Workspace
+	var filename string
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		pkg, err := workspace.NewPackage("controllers")
+		Ω(err).ShouldNot(HaveOccurred())
+		src, err := pkg.CreateSourceFile("test.go")
+		Ω(err).ShouldNot(HaveOccurred())
+		defer src.Close()
+		filename = src.Abs()
+		design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		writer, err = genapp.NewMediaTypesWriter(filename)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with a media type using a hypermedia format", func() {
+		var mt *design.MediaTypeDefinition
+
+		BeforeEach(func() {
+			mt = &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"id":   {Type: design.Integer},
+							"href": {Type: design.String},
+						},
+						Metadata: dslengine.MetadataDefinition{"hypermedia": {"jsonapi"}},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			mt.Views = map[string]*design.ViewDefinition{"default": {
+				AttributeDefinition: mt.AttributeDefinition,
+				Name:                "default",
+				Parent:              mt,
+			}}
+		})
+
+		It("writes the JSON marshaler", func() {
+			err := writer.Execute(mt)
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring(hypermediaMarshaler))
+		})
+	})
+
+	Context("with views rendering different attributes", func() {
+		var mt *design.MediaTypeDefinition
+
+		BeforeEach(func() {
+			mt = &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"id":     {Type: design.Integer},
+							"origin": {Type: design.String},
+						},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"id": {Type: design.Integer}},
+					},
+					Name:   "default",
+					Parent: mt,
+				},
+				"origin": {
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{"origin": {Type: design.String}},
+					},
+					Name:   "origin",
+					Parent: mt,
+				},
+			}
+		})
+
+		It("writes the type that combines the views", func() {
+			err := writer.Execute(mt)
+			Ω(err).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(filename)
+			Ω(err).ShouldNot(HaveOccurred())
+			written := string(b)
+			Ω(written).Should(ContainSubstring("type Bottle struct {"))
+			Ω(written).Should(ContainSubstring("type BottleOrigin struct {"))
+			Ω(written).Should(ContainSubstring("type BottleFull struct {"))
+		})
+	})
+})
+
 const (
+	hypermediaMarshaler = `// MarshalJSON renders the Bottle media type instance as a JSON:API resource object.
+func (mt *Bottle) MarshalJSON() ([]byte, error) {
+	type plain Bottle
+	return goa.MarshalJSONAPI((*plain)(mt), "bottle")
+}
+`
+
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -2056,6 +2963,165 @@ type ListBottleContext struct {
 	*goa.RequestData
 	Param []int
 }
+`
+
+	cookieContextFactory = `
+	if cookieSid, err2 := r.Cookie("sid"); err2 == nil {
+		rawSid := cookieSid.Value
+		rctx.Sid = rawSid
+	} else {
+		err = goa.MergeErrors(err, goa.MissingCookieError("sid"))
+	}
+	return &rctx, err
+}
+`
+
+	offsetPaginate = `func (ctx *ListBottleContext) Paginate(total int) *goa.Page {
+	page := goa.OffsetPage(ctx.URL, ctx.Offset, ctx.Limit, total)
+	page.SetHeaders(ctx.ResponseData.Header())
+	return page
+}
+`
+
+	payloadRedactedFields = `
+// RedactedFields returns the names of the payload fields that must not be logged.
+func (payload *ListBottlePayload) RedactedFields() []string {
+	return []string{"number", "password"}
+}
+`
+
+	cacheHeaders = `	ctx.ResponseData.Header().Set("Cache-Control", "max-age=300, public")
+	ctx.ResponseData.Header().Set("Expires", time.Now().Add(300*time.Second).UTC().Format(http.TimeFormat))
+	ctx.ResponseData.Header().Set("Vary", "Accept, Accept-Encoding")
+	ctx.ResponseData.WriteHeader(200)
+`
+
+	acceptedJob = `func (ctx *ListBottleContext) AcceptedJob(r *GoaJob) error {
+	href := goa.JobHref(ctx.Request, r.ID)
+	r.Href = &href
+	ctx.ResponseData.Header().Set("Location", href)
+	return ctx.Accepted(r)
+}
+`
+
+	checkETag = `func (ctx *ListBottleContext) CheckETag(etag string) error {
+	return goa.CheckIfMatch(ctx.Request, etag)
+}
+`
+
+	conditionalRespond = `	if r != nil {
+		ctx.ResponseData.Header().Set("ETag", goa.QuoteETag(strconv.Itoa(r.Version)))
+	}
+	if r != nil && r.UpdatedAt != nil {
+		ctx.ResponseData.Header().Set("Last-Modified", (*r.UpdatedAt).UTC().Format(http.TimeFormat))
+	}
+	if goa.NotModified(ctx.Request, ctx.ResponseData.Header()) {
+		ctx.ResponseData.WriteHeader(304)
+		return nil
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`
+
+	cookieSetter = `
+// SetSessionIDCookie sets the "session_id" response cookie.
+func (ctx *ListBottleContext) SetSessionIDCookie(v string) {
+	http.SetCookie(ctx.ResponseData, &http.Cookie{
+		Name: "session_id",
+		Value: v,
+		MaxAge: 3600,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+`
+
+	viewResponse = `func (ctx *ListBottleContext) OKView(r *GoaTest) error {
+	switch goa.RequestedView(ctx.Request) {
+	case "tiny":
+		var p *GoaTestTiny
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.OKTiny(p)
+	}
+	return ctx.OK(r)
+}
+`
+
+	fullViewResponse = `func (ctx *ListBottleContext) OKView(r *GoaTestFull) error {
+	switch goa.RequestedView(ctx.Request) {
+	case "tiny":
+		var p *GoaTestTiny
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.OKTiny(p)
+	}
+	var p *GoaTest
+	if err := goa.Project(r, &p); err != nil {
+		return err
+	}
+	return ctx.OK(p)
+}
+`
+
+	staticRedirect = `// MovedPermanently sends a HTTP response with status code 301 redirecting to /new.
+func (ctx *ListBottleContext) MovedPermanently() error {
+	ctx.ResponseData.Header().Set("Location", "/new")
+	ctx.ResponseData.WriteHeader(301)
+	return nil
+}
+`
+
+	attributeRedirect = `// Found sends a HTTP response with status code 302 redirecting to /bottles/:id.
+func (ctx *ListBottleContext) Found(r *GoaTest) error {
+	ctx.ResponseData.Header().Set("Location", "/bottles/"+url.PathEscape(strconv.Itoa(r.ID)))
+	ctx.ResponseData.WriteHeader(302)
+	return nil
+}
+`
+
+	tagRespond = `// Respond sends the response selected by the value of the "outcome" attribute of r. r is
+// projected onto the view of the selected response.
+func (ctx *ListBottleContext) Respond(r *GoaTest) error {
+	var tag string
+	if r != nil && r.Outcome != nil {
+		tag = *r.Outcome
+	}
+	switch tag {
+	case "created":
+		return ctx.Created(r)
+	case "accepted":
+		return ctx.Accepted(r)
+	}
+	return ctx.OK(r)
+}
+`
+
+	tagRespondView = `func (ctx *ListBottleContext) Respond(r *GoaTestFull) error {
+	var tag string
+	if r != nil && r.Outcome != nil {
+		tag = *r.Outcome
+	}
+	switch tag {
+	case "created":
+		var p *GoaTest
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.Created(p)
+	case "accepted":
+		var p *GoaTestTiny
+		if err := goa.Project(r, &p); err != nil {
+			return err
+		}
+		return ctx.AcceptedTiny(p)
+	}
+	var p *GoaTest
+	if err := goa.Project(r, &p); err != nil {
+		return err
+	}
+	return ctx.OK(p)
+}
 `
 
 	intArrayContextFactory = `
@@ -2068,6 +3134,7 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
 	paramParam := req.Params["param"]
 	if len(paramParam) > 0 {
+		paramParam = goa.SplitParamValues(paramParam)
 		params := make([]int, len(paramParam))
 		for i, rawParam := range paramParam {
 			if param, err2 := strconv.Atoi(rawParam); err2 == nil {
@@ -2094,6 +3161,7 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 	if len(paramParam) == 0 {
 		rctx.Param = []int{1, 1, 2, 3, 5, 8}
 	} else {
+		paramParam = goa.SplitParamValues(paramParam)
 		params := make([]int, len(paramParam))
 		for i, rawParam := range paramParam {
 			if param, err2 := strconv.Atoi(rawParam); err2 == nil {
@@ -2120,6 +3188,7 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 	if len(paramParam) == 0 {
 		err = goa.MergeErrors(err, goa.MissingParamError("param"))
 	} else {
+		paramParam = goa.SplitParamValues(paramParam)
 		params := make([]int, len(paramParam))
 		for i, rawParam := range paramParam {
 			if param, err2 := strconv.Atoi(rawParam); err2 == nil {
@@ -2273,6 +3342,23 @@ func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *
 	goa.ContextRequest(ctx).Payload = payload.Publicize()
 	return nil
 }
+`
+	payloadCustomValidationObjUnmarshal = `
+func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	payload := &listBottlePayload{}
+	if err := service.DecodeRequest(req, payload); err != nil {
+		return err
+	}
+	pub := payload.Publicize()
+	if ListBottlePayloadValidator != nil {
+		if err := ListBottlePayloadValidator(ctx, pub); err != nil {
+			goa.ContextRequest(ctx).Payload = payload
+			return err
+		}
+	}
+	goa.ContextRequest(ctx).Payload = pub
+	return nil
+}
 `
 	payloadNoValidationsObjUnmarshal = `
 func unmarshalListBottlePayload(ctx context.Context, service *goa.Service, req *http.Request) error {
@@ -2292,7 +3378,7 @@ type PublicController interface {
 }
 `
 
-	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
+	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", prefix+"/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
 
 	simpleController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
@@ -2305,6 +3391,16 @@ type BottlesController interface {
 	h = handleBottlesOrigin(h)
 	service.Mux.Handle`
 
+	actionOriginsPreflight = `service.Mux.Handle("OPTIONS", prefix+"/accounts", ctrl.MuxHandler("preflight", handleListBottlesOrigin(cors.HandlePreflight()), nil))`
+
+	actionOriginsIntegration = `}
+	h = handleListBottlesOrigin(h)
+	service.Mux.Handle`
+
+	actionOriginsHandler = `// handleListBottlesOrigin applies the CORS response headers corresponding to the origin.
+func handleListBottlesOrigin(h goa.Handler) goa.Handler {
+`
+
 	originsHandler = `// handleBottlesOrigin applies the CORS response headers corresponding to the origin.
 func handleBottlesOrigin(h goa.Handler) goa.Handler {
 
@@ -2384,49 +3480,174 @@ func handleBottlesOrigin(h goa.Handler) goa.Handler {
 		return h(ctx, rw, req)
 	}
 }
+`
+
+	healthCheck = `
+// MountHealthCheck mounts the liveness and readiness probes on the service mux under "/healthz"
+// and "/readyz". The readiness probe runs the given checkers. The probes are not affected by the
+// service and controller middlewares.
+func MountHealthCheck(service *goa.Service, checkers ...health.Checker) {
+	health.Mount(service, "/healthz", "/readyz", checkers...)
+}
+`
+
+	mountDocs = `
+// MountDocs mounts the handler serving the OpenAPI document under "/openapi.json" on the service mux.
+// The Swagger UI page is served under "/docs".
+// The handlers are not affected by the service and controller middlewares.
+func MountDocs(service *goa.Service) {
+	apidocs.Mount(service, "/openapi.json", []byte(openAPISpec), apidocs.SwaggerUI("/docs"))
+}
+
+// openAPISpec is the OpenAPI document describing the API.
+const openAPISpec = "{\"openapi\":\"3.0.3\"}"
 `
 
 	encoderController = `
 // MountBottlesController "mounts" a Bottles resource controller on the given service.
 func MountBottlesController(service *goa.Service, ctrl BottlesController) {
+	MountBottlesControllerAt(service, ctrl, "")
+}
+
+// MountBottlesControllerAt "mounts" a Bottles resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountBottlesControllerAt(service *goa.Service, ctrl BottlesController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
-		}
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
-		return ctrl.List(rctx)
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*ListBottleContext)
+			rctx.Context = ctx
+			return nil, ctrl.List(rctx)
+		})
+		return err
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
-	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/accounts/:accountID/bottles", goa.HeadHandler(ctrl.MuxHandler("list", h, nil)))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET "+prefix+"/accounts/:accountID/bottles")
 }
 `
 
 	simpleMount = `func MountBottlesController(service *goa.Service, ctrl BottlesController) {
+	MountBottlesControllerAt(service, ctrl, "")
+}
+
+// MountBottlesControllerAt "mounts" a Bottles resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountBottlesControllerAt(service *goa.Service, ctrl BottlesController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Build the context
+		rctx, err := NewListBottleContext(ctx, req, service)
 		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
+		if err != nil {
 			return err
 		}
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*ListBottleContext)
+			rctx.Context = ctx
+			return nil, ctrl.List(rctx)
+		})
+		return err
+	}
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/accounts/:accountID/bottles", goa.HeadHandler(ctrl.MuxHandler("list", h, nil)))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET "+prefix+"/accounts/:accountID/bottles")
+}
+`
+
+	simpleEndpoints = `// BottlesService is the transport independent interface of the Bottles actions called by
+// the Bottles endpoints. The actions that stream, do not encode or decode their bodies, use
+// interceptors or do not have a success response that can be sent from a value are bound to HTTP
+// and are only served by the BottlesController.
+type BottlesService interface {
+	List(context.Context, *ListBottleRequest) (BottleCollection, error)
+}
+
+// BottlesEndpoints lists the endpoints of the Bottles actions.
+type BottlesEndpoints struct {
+	List goa.Endpoint
+}
+
+// NewBottlesEndpoints returns the endpoints that call the methods of the given service.
+func NewBottlesEndpoints(s BottlesService) *BottlesEndpoints {
+	return &BottlesEndpoints{
+		List: NewListBottlesEndpoint(s),
+	}
+}
+
+// Use wraps all the Bottles endpoints with the given endpoint middleware.
+func (e *BottlesEndpoints) Use(m goa.EndpointMiddleware) {
+	e.List = m(e.List)
+}
+
+// NewListBottlesEndpoint returns the endpoint that calls the List method of the given
+// service. The endpoint request is a *ListBottleRequest and its result type is BottleCollection.
+func NewListBottlesEndpoint(s BottlesService) goa.Endpoint {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return s.List(ctx, req.(*ListBottleRequest))
+	}
+}
+`
+
+	simpleEndpointsMount = `// MountBottlesEndpoints "mounts" the Bottles endpoints on the given service under the given
+// base path prefix, e.g. "/api/v1", cleaned as in MountBottlesControllerAt. The HTTP handlers
+// decode the requests, call the endpoints and encode their results. The actions that are not part
+// of BottlesService are not mounted.
+func MountBottlesEndpoints(service *goa.Service, endpoints *BottlesEndpoints, prefix string) {
+	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
+	ctrl := service.NewController("BottlesEndpoints")
+	var h goa.Handler
+
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
-		return ctrl.List(rctx)
+		res, err := goa.ServeEndpoint(ctx, rctx.endpointRequest(), endpoints.List)
+		if err != nil {
+			return err
+		}
+		r, _ := res.(BottleCollection)
+		return rctx.OK(r)
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
-	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/accounts/:accountID/bottles", goa.HeadHandler(ctrl.MuxHandler("list", h, nil)))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET "+prefix+"/accounts/:accountID/bottles")
 }
 `
 
@@ -2439,38 +3660,64 @@ type BottlesController interface {
 `
 
 	multiMount = `func MountBottlesController(service *goa.Service, ctrl BottlesController) {
+	MountBottlesControllerAt(service, ctrl, "")
+}
+
+// MountBottlesControllerAt "mounts" a Bottles resource controller on the given service
+// under the given base path prefix, e.g. "/api/v1". The prefix is cleaned with path.Join so that
+// "api/v1/" also mounts the handlers under "/api/v1".
+func MountBottlesControllerAt(service *goa.Service, ctrl BottlesController, prefix string) {
 	initService(service)
+	prefix = strings.TrimSuffix(path.Join("/", prefix), "/")
 	var h goa.Handler
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
-		}
 		// Build the context
 		rctx, err := NewListBottleContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
-		return ctrl.List(rctx)
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*ListBottleContext)
+			rctx.Context = ctx
+			return nil, ctrl.List(rctx)
+		})
+		return err
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
-	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/accounts/:accountID/bottles", goa.HeadHandler(ctrl.MuxHandler("list", h, nil)))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET "+prefix+"/accounts/:accountID/bottles")
 
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-		// Check if there was an error loading the request
-		if err := goa.ContextError(ctx); err != nil {
-			return err
-		}
 		// Build the context
 		rctx, err := NewShowBottleContext(ctx, req, service)
+		// Check if there was an error loading the request
+		if lerr := goa.ContextError(ctx); lerr != nil {
+			if err != nil {
+				return goa.MergeErrors(lerr, err)
+			}
+			return lerr
+		}
 		if err != nil {
 			return err
 		}
-		return ctrl.Show(rctx)
+		_, err = goa.ServeEndpoint(ctx, rctx, func(ctx context.Context, req interface{}) (interface{}, error) {
+			rctx := req.(*ShowBottleContext)
+			rctx.Context = ctx
+			return nil, ctrl.Show(rctx)
+		})
+		return err
 	}
-	service.Mux.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
-	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET /accounts/:accountID/bottles/:id")
+	service.Mux.Handle("GET", prefix+"/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
+	service.Mux.Handle("HEAD", prefix+"/accounts/:accountID/bottles/:id", goa.HeadHandler(ctrl.MuxHandler("show", h, nil)))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "Show", "route", "GET "+prefix+"/accounts/:accountID/bottles/:id")
 }
 `
 
@@ -2504,6 +3751,16 @@ func (ut *simplePayload) Publicize() *SimplePayload {
 type SimplePayload struct {
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	customValidationUserType = `// SimplePayload user type.
+type SimplePayload struct {
+	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"` + "`" + `
+}
+
+// SimplePayloadValidator implements the custom validations of SimplePayload, the generated
+// request decoding code calls it once the validation rules defined in the design succeed.
+var SimplePayloadValidator func(context.Context, *SimplePayload) error
 `
 
 	userTypeIncludingHash = `// complexPayload user type.
@@ -2539,5 +3796,131 @@ type ComplexPayload struct {
 	Misc map[int]*MiscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"` + "`" + `
 }
+`
+
+	errorsContext = `
+// ErrListBottlesNotFound is the class of "not_found" errors of the bottles list action.
+// The error handler middleware maps the errors to 404 responses.
+var ErrListBottlesNotFound = goa.NewErrorClass("not_found", 404)
+
+// NewListBottlesConflictError creates a "conflict" error of the bottles list action.
+// The error handler middleware maps the error to a 409 response whose body is v.
+func NewListBottlesConflictError(v *Conflict) error {
+	return goa.NewTypedError("conflict", 409, v, "")
+}
+`
+
+	streamContext = `
+// ListBottlesStream wraps the websocket connection of the bottles list action.
+type ListBottlesStream struct {
+	*websocket.Conn
+	stop func()
+}
+
+// Stream returns the stream wrapping the given websocket connection.
+func (ctx *ListBottleContext) Stream(ws *websocket.Conn) *ListBottlesStream {
+	s := &ListBottlesStream{Conn: ws}
+	return s
+}
+
+// Upgrade upgrades the request connection to a websocket and calls fn with the corresponding
+// stream. The stream is closed once fn returns. The errors returned by fn are logged as the
+// response has already been sent.
+func (ctx *ListBottleContext) Upgrade(fn func(*ListBottlesStream) error) error {
+	websocket.Handler(func(ws *websocket.Conn) {
+		s := ctx.Stream(ws)
+		defer s.Close()
+		if err := fn(s); err != nil {
+			goa.LogError(ctx, "stream failed", "err", err)
+		}
+	}).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	return nil
+}
+
+// Send sends a message to the client.
+func (s *ListBottlesStream) Send(v *Message) error {
+	return websocket.JSON.Send(s.Conn, v)
+}
+
+// Recv receives the next message sent by the client.
+func (s *ListBottlesStream) Recv() (*Message, error) {
+	var v *Message
+	err := websocket.JSON.Receive(s.Conn, &v)
+	return v, err
+}
+
+// Close stops the keep alive pings and closes the websocket connection.
+func (s *ListBottlesStream) Close() error {
+	if s.stop != nil {
+		s.stop()
+	}
+	return s.Conn.Close()
+}
+`
+
+	streamKeepAliveContext = `
+func (ctx *ListBottleContext) Stream(ws *websocket.Conn) *ListBottlesStream {
+	s := &ListBottlesStream{Conn: ws}
+	s.stop = goa.KeepAlive(ws, 30 * time.Second)
+	return s
+}
+`
+
+	ndjsonStreamContext = `
+// ListBottlesStream streams the results of the bottles list action as newline delimited JSON.
+type ListBottlesStream struct {
+	*goa.NDJSONStream
+}
+
+// Stream writes the response headers and returns the stream used to send results.
+func (ctx *ListBottleContext) Stream() (*ListBottlesStream, error) {
+	s, err := goa.NewNDJSONStream(ctx.ResponseData, ctx.Request)
+	if err != nil {
+		return nil, err
+	}
+	return &ListBottlesStream{NDJSONStream: s}, nil
+}
+
+// Send writes a result on its own line and flushes it to the client.
+func (s *ListBottlesStream) Send(v *Message) error {
+	return s.NDJSONStream.Send(v)
+}
+`
+
+	rawBodyContext = `
+type ListBottleContext struct {
+	context.Context
+	*goa.ResponseData
+	*goa.RequestData
+	// Body is the request body, it is not decoded and must be read by the action.
+	Body io.ReadCloser
+}
+`
+
+	rawBodyContextFactory = `
+	rctx := ListBottleContext{Context: ctx, ResponseData: resp, RequestData: req}
+	if r.ContentLength > 1024 {
+		return nil, goa.ErrRequestBodyTooLarge("request body length exceeds 1024 bytes")
+	}
+	rctx.Body = http.MaxBytesReader(resp, r.Body, 1024)
+	return &rctx, err
+}
+`
+
+	rawBodyWriteBody = `
+// WriteBody sends a HTTP response with the given status code and content type whose body is
+// copied from body without being encoded or buffered. body is closed once copied if it
+// implements io.Closer.
+func (ctx *ListBottleContext) WriteBody(code int, contentType string, body io.Reader) error {
+	if c, ok := body.(io.Closer); ok {
+		defer c.Close()
+	}
+	if contentType != "" {
+		ctx.ResponseData.Header().Set("Content-Type", contentType)
+	}
+	ctx.ResponseData.WriteHeader(code)
+	_, err := io.Copy(ctx.ResponseData, body)
+	return err
+}
 `
 )
@@ -21,10 +21,10 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		return err
 	}
 	defer func() {
-		file.Close()
 		if err == nil {
 			err = file.FormatCode()
 		}
+		file.Close()
 	}()
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
@@ -101,10 +101,10 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 		return err
 	}
 	defer func() {
-		file.Close()
 		if err == nil {
 			err = file.FormatCode()
 		}
+		file.Close()
 	}()
 
 	funcs["defaultRouteParams"] = defaultRouteParams
@@ -117,6 +117,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["formatExample"] = formatExample
 	funcs["shouldAddExample"] = shouldAddExample
 	funcs["kebabCase"] = codegen.KebabCase
+	funcs["payloadFields"] = payloadFields
 
 	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(commandTypesTmpl))
 	commandsTmpl := template.Must(template.New("commands").Funcs(funcs).Parse(commandsTmpl))
@@ -127,6 +128,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("log"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
@@ -271,9 +273,9 @@ func defaultRouteParams(a *design.ActionDefinition) *design.AttributeDefinition
 	return &design.AttributeDefinition{Type: o, NonZeroAttributes: nz}
 }
 
-// produces a fmt template to render the first route of action.
+// produces a fmt template to render the first route of action served by the latest API version.
 func defaultRouteTemplate(a *design.ActionDefinition) string {
-	return design.WildcardRegex.ReplaceAllLiteralString(a.Routes[0].FullPath(), "/%v")
+	return design.WildcardRegex.ReplaceAllLiteralString(a.Routes[0].VersionedPath(a.LatestVersion()), "/%v")
 }
 
 // return a ',' joined list of Params as a reference to cmd.XFieldName
@@ -320,7 +322,9 @@ func joinNames(useNil bool, atts ...*design.AttributeDefinition) string {
 		for _, n := range keys {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
-			if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) {
+			if a.Type.IsHash() {
+				field = "%s"
+			} else if !a.Type.IsArray() && !att.IsRequired(n) && !att.IsNonZero(n) && !att.IsNonPointer(n) {
 				if useNil {
 					field = flagTypeVal(a, n, field)
 				} else {
@@ -428,6 +432,23 @@ func handleSpecialTypes(atts ...*design.AttributeDefinition) specialTypeResult {
 			a := obj[n]
 			field := fmt.Sprintf("cmd.%s", codegen.Goify(n, true))
 			typ := cmdFieldType(a.Type, true)
+			if a.Type.IsHash() {
+				tmpVar := codegen.Tempvar()
+				if att.IsRequired(n) {
+					names = append(names, tmpVar)
+				} else {
+					optNames = append(optNames, tmpVar)
+				}
+				result.Output += fmt.Sprintf(`
+	var %s %s
+	if %s != "" {
+		if err := json.Unmarshal([]byte(%s), &%s); err != nil {
+			goa.LogError(ctx, "failed to parse flag into %s value", "flag", "--%s", "err", err)
+			return err
+		}
+	}`, tmpVar, typ, field, field, tmpVar, typ, n)
+				continue
+			}
 			var typeHandler, nilVal string
 			if !a.Type.IsArray() {
 				nilVal = `""`
@@ -503,7 +524,7 @@ func routes(action *design.ActionDefinition) string {
 	}
 	paths := make([]string, len(routes))
 	for i, r := range routes {
-		path := r.FullPath()
+		path := r.VersionedPath(action.LatestVersion())
 		matches := design.WildcardRegex.FindAllStringSubmatch(path, -1)
 		for _, match := range matches {
 			paramName := match[1]
@@ -570,6 +591,8 @@ func flagType(att *design.AttributeDefinition) string {
 		return "String"
 	case design.AnyKind:
 		return "String"
+	case design.HashKind:
+		return "String"
 	case design.ArrayKind:
 		switch att.Type.ToArray().ElemType.Type.Kind() {
 		case design.NumberKind:
@@ -588,6 +611,59 @@ func flagType(att *design.AttributeDefinition) string {
 	}
 }
 
+// payloadField describes a payload attribute exposed as a dedicated command flag.
+type payloadField struct {
+	// Name is the name of the attribute and of the flag.
+	Name string
+	// Field is the name of the command struct field holding the flag value.
+	Field string
+	// Description is the attribute description.
+	Description string
+	// IsString is true if the flag value is used as is, false if it is a JSON literal.
+	IsString bool
+}
+
+// payloadFields returns the payload attributes of the action that can be set with dedicated
+// command flags: the primitive top level attributes of object payloads whose names do not
+// clash with the other flags of the command.
+func payloadFields(action *design.ActionDefinition) []*payloadField {
+	if action.Payload == nil {
+		return nil
+	}
+	obj := action.Payload.Type.ToObject()
+	if obj == nil {
+		return nil
+	}
+	reserved := map[string]bool{
+		"payload": true, "content": true, "pp": true, "help": true,
+		"scheme": true, "host": true, "timeout": true, "dump": true,
+		"user": true, "pass": true, "key": true, "format": true, "token": true, "token-type": true,
+	}
+	for _, params := range []*design.AttributeDefinition{action.AllParams(), action.Headers} {
+		if params == nil {
+			continue
+		}
+		for n := range params.Type.ToObject() {
+			reserved[n] = true
+		}
+	}
+	var fields []*payloadField
+	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+		if reserved[n] || !at.Type.IsPrimitive() || at.Type.Kind() == design.AnyKind {
+			return nil
+		}
+		kind := at.Type.Kind()
+		fields = append(fields, &payloadField{
+			Name:        n,
+			Field:       "Payload" + codegen.Goify(n, true),
+			Description: at.Description,
+			IsString:    kind == design.StringKind || kind == design.DateTimeKind || kind == design.UUIDKind,
+		})
+		return nil
+	})
+	return fields
+}
+
 func shouldAddExample(ut *design.UserTypeDefinition) bool {
 	if ut == nil {
 		return false
@@ -620,7 +696,21 @@ func main() {
 	app.PersistentFlags().StringVarP(&c.Host, "host", "H", "{{ .API.Host }}", "API hostname")
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20) * time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
-
+{{ if .API.TLS }}
+	// Register TLS flags
+	var certFile, keyFile, caFile string
+	app.PersistentFlags().StringVar(&certFile, "tls-cert", "", "Client certificate file used for mutual TLS")
+	app.PersistentFlags().StringVar(&keyFile, "tls-key", "", "Client certificate key file used for mutual TLS")
+	app.PersistentFlags().StringVar(&caFile, "tls-ca", "", "CA certificates file used to verify the service certificate")
+	app.PersistentPreRunE = func(*cobra.Command, []string) error {
+		cfg, err := goaclient.TLSConfig(certFile, keyFile, caFile)
+		if err != nil {
+			return err
+		}
+		httpClient.Transport = &http.Transport{TLSClientConfig: cfg}
+		return nil
+	}
+{{ end }}
 {{ if .HasSigners }}	// Register signer flags
 {{ if .HasBasicAuthSigners }} var user, pass string
 	app.PersistentFlags().StringVar(&user, "user", "", "Username used for authentication")
@@ -673,9 +763,10 @@ func new{{ goify $security.SchemeName true }}Signer({{ signerSignature $security
 	}
 {{ else if eq .Type "apiKey" }}	return &goaclient.APIKeySigner{
 		SignQuery: {{ if eq $security.In "query" }}true{{ else }}false{{ end }},
+		SignCookie: {{ if eq $security.In "cookie" }}true{{ else }}false{{ end }},
 		KeyName: "{{ $security.Name }}",
 		KeyValue: key,
-		Format: {{ if eq $security.In "query" }}"%s"{{ else }}format{{ end }},
+		Format: {{ if or (eq $security.In "query") (eq $security.In "cookie") }}"%s"{{ else }}format{{ end }},
 	}
 {{ else if eq .Type "jwt" }}	return &goaclient.JWTSigner{
 		TokenSource: source,
@@ -692,6 +783,11 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 	{{ $cmdName }} struct {
 {{ if .Payload }}		Payload string
 		ContentType string
+{{ range payloadFields . }}		// {{ .Field }} sets the {{ .Name }} payload attribute.
+		{{ .Field }} string
+{{ end }}{{ end }}{{ if .SkipRequestBodyEncodeDecode }}		// File is the path to the file containing the request body.
+		File string
+		ContentType string
 {{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
 {{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
@@ -789,6 +885,9 @@ const registerTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Action.Name (t
 func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Client) {
 {{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", "Request body encoded in JSON")
 	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
+{{ range payloadFields .Action }}	cc.Flags().StringVar(&cmd.{{ .Field }}, "{{ .Name }}", "", ` + "`" + `{{ if .Description }}{{ escapeBackticks .Description }}{{ else }}Request body {{ .Name }} attribute{{ end }}` + "`" + `)
+{{ end }}{{ end }}{{ if .Action.SkipRequestBodyEncodeDecode }}	cc.Flags().StringVar(&cmd.File, "file", "", "Path to the file containing the request body")
+	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request body content type, e.g. 'application/octet-stream'")
 {{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
@@ -812,7 +911,14 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ $default := defaultPath .Action }}{{ if $default }}	path = "{{ $default }}"
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action) }}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
-{{ if .Action.Payload }}var payload {{ gotyperefext .Action.Payload 2 .Package }}
+{{ if .Action.Payload }}{{ range payloadFields .Action }}	if cmd.{{ .Field }} != "" {
+		p, err := goaclient.SetPayloadField(cmd.Payload, "{{ .Name }}", cmd.{{ .Field }}, {{ .IsString }})
+		if err != nil {
+			return err
+		}
+		cmd.Payload = p
+	}
+{{ end }}var payload {{ gotyperefext .Action.Payload 2 .Package }}
 	if cmd.Payload != "" {
 		err := json.Unmarshal([]byte(cmd.Payload), &payload)
 		if err != nil {
@@ -820,10 +926,19 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}			return fmt.Errorf("failed to deserialize payload: %s", err)
 {{ end }}		}
 	}
+{{ end }}{{ if .Action.SkipRequestBodyEncodeDecode }}	var body io.Reader
+	if cmd.File != "" {
+		f, err := os.Open(cmd.File)
+		if err != nil {
+			return fmt.Errorf("failed to open request body file: %s", err)
+		}
+		defer f.Close()
+		body = f
+	}
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
-	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
+	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else if .Action.SkipRequestBodyEncodeDecode }}, body, cmd.ContentType{{ end }}{{/*
 	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
 	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
 	if err != nil {
@@ -63,6 +63,23 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
+		Context("with TLS", func() {
+			BeforeEach(func() {
+				design.Design.Schemes = []string{"https"}
+				design.Design.TLS = &design.TLSDefinition{
+					Certificates: []*design.CertificateDefinition{{CertFile: "cert.pem", KeyFile: "key.pem"}},
+				}
+			})
+
+			It("registers the TLS flags", func() {
+				Ω(genErr).Should(BeNil())
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(c)).Should(ContainSubstring(`app.PersistentFlags().StringVar(&certFile, "tls-cert", "", "Client certificate file used for mutual TLS")`))
+				Ω(string(c)).Should(ContainSubstring("goaclient.TLSConfig(certFile, keyFile, caFile)"))
+			})
+		})
+
 		Context("generated commands.go", func() {
 			var commandHeader string
 
@@ -434,6 +451,68 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action with an object payload", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				TypeName: "CreatePayload",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name":  &design.AttributeDefinition{Type: design.String, Description: "Name of thing"},
+						"count": &design.AttributeDefinition{Type: design.Integer},
+						"id":    &design.AttributeDefinition{Type: design.String},
+						"tags":  &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+					},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name: "create",
+								Params: &design.AttributeDefinition{
+									Type: design.Object{
+										"id": &design.AttributeDefinition{Type: design.String},
+									},
+								},
+								Payload: payload,
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "POST",
+										Path: "resource/:id",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("generates flags for the primitive payload attributes", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`cc.Flags().StringVar(&cmd.PayloadName, "name", "", ` + "`Name of thing`)"))
+			Ω(content).Should(ContainSubstring(`cc.Flags().StringVar(&cmd.PayloadCount, "count", "", ` + "`Request body count attribute`)"))
+			Ω(content).Should(ContainSubstring(`goaclient.SetPayloadField(cmd.Payload, "name", cmd.PayloadName, true)`))
+			Ω(content).Should(ContainSubstring(`goaclient.SetPayloadField(cmd.Payload, "count", cmd.PayloadCount, false)`))
+			Ω(content).ShouldNot(ContainSubstring("PayloadID"))
+			Ω(content).ShouldNot(ContainSubstring("PayloadTags"))
+		})
+	})
+
 	Context("with an action with security configured", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -3,12 +3,15 @@ package genclient
 import (
 	"flag"
 	"fmt"
+	"mime"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
@@ -64,6 +67,10 @@ func Generate() (files []string, err error) {
 	set.String("design", "", "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
+	set.Bool("mocks", false, "")
+	set.Bool("fuzz", false, "")
+	set.Bool("openapi3", false, "")
+	set.Bool("metrics", false, "")
 	set.Parse(os.Args[1:])
 
 	// First check compatibility
@@ -119,7 +126,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			}
 
 			cliDir = filepath.Join(g.OutDir, g.ToolDirName, "cli")
-			if err = os.RemoveAll(cliDir); err != nil {
+			if err = codegen.RemoveAll(cliDir); err != nil {
 				return
 			}
 			if err = os.MkdirAll(cliDir, 0755); err != nil {
@@ -128,7 +135,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 		}
 
 		pkgDir = filepath.Join(g.OutDir, g.Target)
-		if err = os.RemoveAll(pkgDir); err != nil {
+		if err = codegen.RemoveAll(pkgDir); err != nil {
 			return
 		}
 		if err = os.MkdirAll(pkgDir, 0755); err != nil {
@@ -144,6 +151,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"add":                func(a, b int) int { return a + b },
 			"cmdFieldType":       cmdFieldType,
 			"defaultPath":        defaultPath,
+			"duration":           codegen.Duration,
 			"escapeBackticks":    escapeBackticks,
 			"goify":              codegen.Goify,
 			"gotypedef":          codegen.GoTypeDef,
@@ -232,10 +240,10 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 		}
 	}
 	defer func() {
-		file.Close()
 		if err == nil {
 			err = file.FormatCode()
 		}
+		file.Close()
 	}()
 	clientTmpl := template.Must(template.New("client").Funcs(funcs).Parse(clientTmpl))
 
@@ -265,6 +273,7 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 
 	// Setup codegen
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
@@ -280,24 +289,85 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 	g.genfiles = append(g.genfiles, clientFile)
 
 	// Generate
+	policies, err := retryPolicies(g.API)
+	if err != nil {
+		return err
+	}
 	data := struct {
-		API      *design.APIDefinition
-		Encoders []*genapp.EncoderTemplateData
-		Decoders []*genapp.EncoderTemplateData
+		API           *design.APIDefinition
+		Encoders      []*genapp.EncoderTemplateData
+		Decoders      []*genapp.EncoderTemplateData
+		RetryPolicies []*retryPolicy
+		Interceptors  []*genapp.InterceptorTemplateData
 	}{
-		API:      g.API,
-		Encoders: encoders,
-		Decoders: decoders,
+		API:           g.API,
+		Encoders:      encoders,
+		Decoders:      decoders,
+		RetryPolicies: policies,
+		Interceptors:  genapp.BuildInterceptors(g.API),
 	}
 	err = clientTmpl.Execute(file, data)
 	return
 }
 
+// retryPolicy is the retry policy of an endpoint defined with the "retry:attempts",
+// "retry:idempotent" and "retry:disable" metadata.
+type retryPolicy struct {
+	Endpoint    string
+	MaxAttempts int
+	Idempotent  bool
+	Disabled    bool
+}
+
+// retryPolicies returns the retry policies defined in the metadata of the API actions or of their
+// resources.
+func retryPolicies(api *design.APIDefinition) ([]*retryPolicy, error) {
+	var policies []*retryPolicy
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			lookup := func(key string) ([]string, bool) {
+				if v, ok := a.Metadata[key]; ok {
+					return v, true
+				}
+				v, ok := res.Metadata[key]
+				return v, ok
+			}
+			p := &retryPolicy{Endpoint: res.Name + "." + a.Name}
+			if v, ok := lookup("retry:attempts"); ok && len(v) > 0 {
+				n, err := strconv.Atoi(v[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid retry:attempts metadata %#v for action %s of resource %s, must be a positive integer", v[0], a.Name, res.Name)
+				}
+				p.MaxAttempts = n
+			}
+			_, p.Idempotent = lookup("retry:idempotent")
+			_, p.Disabled = lookup("retry:disable")
+			if p.MaxAttempts > 0 || p.Idempotent || p.Disabled {
+				policies = append(policies, p)
+			}
+			return nil
+		})
+	})
+	return policies, err
+}
+
 func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs template.FuncMap) error {
+	// Render the resource clients sequentially then format and write them concurrently.
+	var files []*codegen.SourceFile
 	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
-		return g.generateResourceClient(pkgDir, res, funcs)
+		file, err := g.generateResourceClient(pkgDir, res, funcs)
+		if file != nil {
+			files = append(files, file)
+		}
+		return err
 	})
 	if err != nil {
+		for _, file := range files {
+			file.Close()
+		}
+		return err
+	}
+	if err := codegen.FormatFiles(files); err != nil {
 		return err
 	}
 	if err := g.generateUserTypes(pkgDir); err != nil {
@@ -307,7 +377,9 @@ func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs temp
 	return g.generateMediaTypes(pkgDir, funcs)
 }
 
-func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDefinition, funcs template.FuncMap) (err error) {
+// generateResourceClient renders the client of the given resource and returns the source file, it
+// is up to the caller to format and close the file.
+func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDefinition, funcs template.FuncMap) (file *codegen.SourceFile, err error) {
 	payloadTmpl := template.Must(template.New("payload").Funcs(funcs).Parse(payloadTmpl))
 	pathTmpl := template.Must(template.New("pathTemplate").Funcs(funcs).Parse(pathTmpl))
 
@@ -318,23 +390,17 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 	}
 	filename := filepath.Join(pkgDir, resFilename+".go")
 
-	var file *codegen.SourceFile
 	file, err = codegen.SourceFileFor(filename)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		file.Close()
-		if err == nil {
-			err = file.FormatCode()
-		}
-	}()
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("bytes"),
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
 		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
@@ -344,11 +410,13 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
-		return err
+		return
 	}
 	g.genfiles = append(g.genfiles, filename)
 
@@ -356,7 +424,7 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		return g.generateFileServer(file, fs, funcs)
 	})
 	if err != nil {
-		return err
+		return
 	}
 
 	err = res.IterateActions(func(action *design.ActionDefinition) error {
@@ -461,10 +529,16 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
 		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
 		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+
+		clientsStreamTmpl = template.Must(template.New("clientsstream").Funcs(funcs).Parse(clientsStreamTmpl))
+		clientsNDJSONTmpl = template.Must(template.New("clientsndjson").Funcs(funcs).Parse(clientsNDJSONTmpl))
 	)
 	if action.Payload != nil {
 		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
 		names = append(names, "payload")
+	} else if action.SkipRequestBodyEncodeDecode {
+		params = append(params, "body io.Reader", "contentType string")
+		names = append(names, "body", "contentType")
 	}
 
 	initParamsScoped := func(att *design.AttributeDefinition) []*paramData {
@@ -497,6 +571,7 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Routes             []*design.RouteDefinition
 		HasPayload         bool
 		HasMultiContent    bool
+		RawBody            bool
 		DefaultContentType string
 		Params             string
 		ParamNames         string
@@ -504,12 +579,21 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer             string
 		QueryParams        []*paramData
 		Headers            []*paramData
+		StreamingPayload   *design.UserTypeDefinition
+		StreamingResult    *design.UserTypeDefinition
+		NDJSON             bool
+		VersionHeader      string
+		VersionValue       string
+		Timeout            time.Duration
+		KeepAlive          time.Duration
+		Interceptors       []string
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
 		Description:        action.Description,
 		Routes:             action.Routes,
 		HasPayload:         action.Payload != nil,
+		RawBody:            action.SkipRequestBodyEncodeDecode,
 		HasMultiContent:    len(design.Design.Consumes) > 1,
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
@@ -518,13 +602,36 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		Signer:             signer,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		StreamingPayload:   action.StreamingPayload,
+		StreamingResult:    action.StreamingResult,
+		NDJSON:             action.NDJSON,
+		Timeout:            action.Timeout,
+		KeepAlive:          action.KeepAlive,
+	}
+	data.VersionHeader, data.VersionValue = versionHeader(action)
+	// List the interceptors in reverse order so that wrapping the request with each interceptor
+	// in turn makes the first interceptor run first.
+	interceptors := action.AllInterceptors()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		data.Interceptors = append(data.Interceptors, codegen.Goify(interceptors[i], true)+"Interceptor")
 	}
 	if action.WebSocket() {
-		return clientsWSTmpl.Execute(file, data)
+		if err := clientsWSTmpl.Execute(file, data); err != nil {
+			return err
+		}
+		if action.Streaming() {
+			return clientsStreamTmpl.Execute(file, data)
+		}
+		return nil
 	}
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
+	if action.NDJSON {
+		if err := clientsNDJSONTmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
 	return requestsTmpl.Execute(file, data)
 }
 
@@ -576,10 +683,10 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 		}
 	}
 	defer func() {
-		mtWr.Close()
 		if err == nil {
 			err = mtWr.FormatCode()
 		}
+		mtWr.Close()
 	}()
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
@@ -608,7 +715,15 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 			if err != nil {
 				return err
 			}
-			return typeDecodeTmpl.Execute(mtWr.SourceFile, p)
+			// Media types only have a Validate method if the media type writer
+			// generated validation code for them.
+			validate := (mt.Type.IsObject() || mt.Type.IsArray()) && !mt.IsError() &&
+				mtWr.Validator.Code(p.AttributeDefinition, false, false, false, "mt", "response", 1, false) != ""
+			data := map[string]interface{}{
+				"MediaType": p,
+				"Validate":  validate,
+			}
+			return typeDecodeTmpl.Execute(mtWr.SourceFile, data)
 		})
 		return err
 	})
@@ -630,13 +745,15 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 		}
 	}
 	defer func() {
-		utWr.Close()
 		if err == nil {
 			err = utWr.FormatCode()
 		}
+		utWr.Close()
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
@@ -735,7 +852,7 @@ func decodeGoTypeName(t design.DataType, required []string, tabs int, private bo
 // cmdFieldType computes the Go type name used to store command flags of the given design type.
 func cmdFieldType(t design.DataType, point bool) string {
 	var pointer, suffix string
-	if point && !t.IsArray() {
+	if point && !t.IsArray() && !t.IsHash() {
 		pointer = "*"
 	}
 	suffix = codegen.GoNativeType(t)
@@ -745,10 +862,13 @@ func cmdFieldType(t design.DataType, point bool) string {
 // cmdFieldTypeString computes the Go type name used to store command flags of the given design type. Complex types are String
 func cmdFieldTypeString(t design.DataType, point bool) string {
 	var pointer, suffix string
-	if point && !t.IsArray() {
+	if point && !t.IsArray() && !t.IsHash() {
 		pointer = "*"
 	}
-	if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
+	if t.IsHash() {
+		// Maps are given as JSON objects on the command line.
+		suffix = "string"
+	} else if t.Kind() == design.UUIDKind || t.Kind() == design.DateTimeKind || t.Kind() == design.AnyKind || t.Kind() == design.NumberKind || t.Kind() == design.BooleanKind {
 		suffix = "string"
 	} else if isArrayOfType(t, design.UUIDKind, design.DateTimeKind, design.AnyKind, design.NumberKind, design.BooleanKind) {
 		suffix = "[]string"
@@ -813,7 +933,7 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 // empty string if none.
 func defaultPath(action *design.ActionDefinition) string {
 	for _, r := range action.Routes {
-		candidate := r.FullPath()
+		candidate := r.VersionedPath(action.LatestVersion())
 		if !strings.ContainsRune(candidate, ':') {
 			return candidate
 		}
@@ -836,9 +956,22 @@ func signerType(scheme *design.SecuritySchemeDefinition) string {
 	return ""
 }
 
-// pathTemplate returns a fmt format suitable to build a request path to the route.
+// pathTemplate returns a fmt format suitable to build a request path to the route. The path
+// targets the latest API version that serves the route action.
 func pathTemplate(r *design.RouteDefinition) string {
-	return design.WildcardRegex.ReplaceAllLiteralString(r.FullPath(), "/%s")
+	return design.WildcardRegex.ReplaceAllLiteralString(r.VersionedPath(r.Parent.LatestVersion()), "/%s")
+}
+
+// versionHeader returns the name and value of the request header that selects the latest API
+// version that serves the action, empty strings if none. The version parameter is added to the
+// NDJSON media type when the API uses media type versioning.
+func versionHeader(action *design.ActionDefinition) (name, value string) {
+	version := action.LatestVersion()
+	name, value = design.Design.Versioning.RequestHeader(version)
+	if name == "Accept" && action.NDJSON {
+		value = mime.FormatMediaType("application/x-ndjson", map[string]string{design.Design.Versioning.Name: version})
+	}
+	return
 }
 
 // pathParams return the function signature of the path factory function for the given route.
@@ -888,6 +1021,10 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 			if q.Type.IsArray() {
 				param.IsArray = true
 				param.ElemAttribute = q.Type.ToArray().ElemType
+			} else if h := q.Type.ToHash(); h != nil {
+				param.IsHash = true
+				param.KeyAttribute = h.KeyType
+				param.ElemAttribute = h.ElemType
 			}
 			param.MustToString = true
 			param.ValueName = varName
@@ -910,9 +1047,11 @@ type paramData struct {
 	VarName       string
 	ValueName     string
 	Attribute     *design.AttributeDefinition
+	KeyAttribute  *design.AttributeDefinition
 	ElemAttribute *design.AttributeDefinition
 	MustToString  bool
 	IsArray       bool
+	IsHash        bool
 	CheckNil      bool
 }
 
@@ -934,11 +1073,14 @@ const (
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
 `
 
-	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
-func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
-	var decoded {{ decodegotypename . .AllRequired 0 false }}
+	typeDecodeTmpl = `{{ $mt := .MediaType }}{{ $typeName := typeName $mt }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
+func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef $mt $mt.AllRequired 0 false }}, error) {
+	var decoded {{ decodegotypename $mt $mt.AllRequired 0 false }}
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
-	return {{ if .IsObject }}&{{ end }}decoded, err
+{{ if .Validate }}	if err == nil && c.ValidateResponses {
+		err = decoded.Validate()
+	}
+{{ end }}	return {{ if $mt.IsObject }}&{{ end }}decoded, err
 }
 `
 
@@ -960,8 +1102,19 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	if err != nil {
 		return nil, err
 	}
-	return c.Client.Do(ctx, req)
-}
+{{ if .Timeout }}	ctx = goaclient.SetContextTimeout(ctx, {{ duration .Timeout }})
+{{ end }}{{ if .Interceptors }}	do := func(ctx context.Context, req *http.Request) (*http.Response, error) {
+		return c.Client.Do(ctx, req)
+	}
+{{ range .Interceptors }}	if c.{{ . }} != nil {
+		next := do
+		do = func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			return c.{{ . }}.{{ $funcName }}(ctx, req{{ if $.HasPayload }}, payload{{ end }}, next)
+		}
+	}
+{{ end }}	return do(goaclient.SetContextEndpoint(ctx, "{{ .ResourceName }}.{{ .Name }}"), req)
+{{ else }}	return c.Client.Do(goaclient.SetContextEndpoint(ctx, "{{ .ResourceName }}.{{ .Name }}"), req)
+{{ end }}}
 `
 
 	clientsWSTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
@@ -983,6 +1136,13 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ else }}			values.Add("{{ .Name }}", {{ .ValueName }})
 {{ end }}}{{/*
 
+// MAP
+*/}}{{ else if .IsHash }}		for k, v := range {{ .VarName }} {
+{{ $tk := tempvar }}{{ $tv := tempvar }}			{{ toString "k" $tk .KeyAttribute }}
+			{{ toString "v" $tv .ElemAttribute }}
+			values.Set("{{ .Name }}["+{{ $tk }}+"]", {{ $tv }})
+		}{{/*
+
 // NON STRING
 */}}{{ else if .MustToString }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
 	values.Set("{{ .Name }}", {{ $tmp }})
@@ -999,8 +1159,91 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 {{ range $header := .Headers }}{{ $tmp := tempvar }}	{{ toString $header.VarName $tmp $header.Attribute }}
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
+{{ end }}{{ if .VersionHeader }}	cfg.Header.Set({{ printf "%q" .VersionHeader }}, {{ printf "%q" .VersionValue }})
 {{ end }}	return websocket.DialConfig(cfg)
 }
+`
+
+	clientsStreamTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}{{ $stream := printf "%sStream" $funcName }}
+// {{ $stream }} wraps the websocket connection to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
+type {{ $stream }} struct {
+	*websocket.Conn
+	stop func()
+}
+
+// {{ $funcName }}Stream establishes a websocket connection to the {{ .Name }} action endpoint of
+// the {{ .ResourceName }} resource and returns the corresponding stream.
+func (c *Client) {{ $funcName }}Stream(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}) (*{{ $stream }}, error) {
+	ws, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	s := &{{ $stream }}{Conn: ws}
+{{- if .KeepAlive }}
+	s.stop = goa.KeepAlive(ws, {{ duration .KeepAlive }})
+{{- end }}
+	return s, nil
+}
+{{ if .StreamingPayload }}
+// Send sends a message to the service.
+func (s *{{ $stream }}) Send(v {{ gotyperef .StreamingPayload nil 0 false }}) error {
+	return websocket.JSON.Send(s.Conn, v)
+}
+{{ end }}{{ if .StreamingResult }}
+// Recv receives the next message sent by the service.
+func (s *{{ $stream }}) Recv() ({{ gotyperef .StreamingResult nil 0 false }}, error) {
+	var v {{ gotyperef .StreamingResult nil 0 false }}
+	err := websocket.JSON.Receive(s.Conn, &v)
+	return v, err
+}
+{{ end }}
+// Close stops the keep alive pings and closes the websocket connection.
+func (s *{{ $stream }}) Close() error {
+	if s.stop != nil {
+		s.stop()
+	}
+	return s.Conn.Close()
+}
+`
+
+	clientsNDJSONTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}{{ $stream := printf "%sStream" $funcName }}{{ $multi := and .HasPayload .HasMultiContent }}
+// {{ $stream }} iterates over the newline delimited JSON results streamed by the {{ .Name }}
+// action endpoint of the {{ .ResourceName }} resource.
+type {{ $stream }} struct {
+	body io.ReadCloser
+	dec  *json.Decoder
+}
+
+// {{ $funcName }}Stream makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }}
+// resource and returns the stream iterating over the results. It returns an error if the response
+// status is not a success status.
+func (c *Client) {{ $funcName }}Stream(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if $multi }}, contentType string{{ end }}) (*{{ $stream }}, error) {
+	resp, err := c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if $multi }}, contentType{{ end }})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected response %s: %s", resp.Status, body)
+	}
+	return &{{ $stream }}{body: resp.Body, dec: json.NewDecoder(resp.Body)}, nil
+}
+
+// Next decodes the next result sent by the service, it returns io.EOF once all the results have
+// been read.
+func (s *{{ $stream }}) Next() ({{ gotyperef .StreamingResult nil 0 false }}, error) {
+	var v {{ gotyperef .StreamingResult nil 0 false }}
+	err := s.dec.Decode(&v)
+	return v, err
+}
+
+// Close closes the response body, it must be called once the results have been read.
+func (s *{{ $stream }}) Close() error {
+	return s.body.Close()
+}
 `
 
 	fsTmpl = `// {{ .Name }} downloads {{ if .DirName }}{{ .DirName }}files with the given filename{{ else }}{{ .FileName }}{{ end }} and writes it to the file dest.
@@ -1066,6 +1309,14 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ end }}	 }
 {{/*
 
+// MAP
+*/}}{{ else if .IsHash }}	for k, v := range {{ .VarName }} {
+{{ $tk := tempvar }}{{ $tv := tempvar }}		{{ toString "k" $tk .KeyAttribute }}
+		{{ toString "v" $tv .ElemAttribute }}
+		values.Set("{{ .Name }}["+{{ $tk }}+"]", {{ $tv }})
+	}
+{{/*
+
 // NON STRING
 */}}{{ else if .MustToString }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
@@ -1079,11 +1330,17 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ if .CheckNil }}	}
 {{ end }}{{ end }}{{ end }}	u.RawQuery = values.Encode()
 {{ end }}{{ if .HasPayload }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), &body)
+{{ else if .RawBody }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), body)
 {{ else }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), nil)
 {{ end }}	if err != nil {
 		return nil, err
 	}
-{{ if or .HasPayload .Headers }}	header := req.Header
+{{ if .NDJSON }}	req.Header.Set("Accept", "application/x-ndjson")
+{{ end }}{{ if .VersionHeader }}	req.Header.Set({{ printf "%q" .VersionHeader }}, {{ printf "%q" .VersionValue }})
+{{ end }}{{ if .RawBody }}	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+{{ end }}{{ if or .HasPayload .Headers }}	header := req.Header
 {{ if .HasPayload }}{{ if .HasMultiContent }}	if contentType == "*/*" {
 		header.Set("Content-Type", "{{ .DefaultContentType }}")
 	} else {
@@ -1109,11 +1366,109 @@ type Client struct {
 	*goaclient.Client{{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}
 	{{ goify $security.SchemeName true }}Signer goaclient.Signer{{ end }}{{ end }}
 	Encoder *goa.HTTPEncoder
-	Decoder *goa.HTTPDecoder
+	Decoder *goa.HTTPDecoder{{ range .Interceptors }}
+	{{ .TypeName }} {{ .TypeName }}{{ end }}
 }
+{{ range .Interceptors }}
+// {{ .TypeName }} is the client side interface of the {{ printf "%q" .Name }} interceptor. Its
+// methods are called in place of the requests made to the intercepted actions with the request,
+// the request payload if any and a function that sends the request. A method may return a
+// response without calling next.
+type {{ .TypeName }} interface {
+{{ range .Actions }}{{ if not .WebSocket }}{{ $funcName := goify (printf "%s%s" .Name (title .Parent.Name)) true }}{{/*
+*/}}	// {{ $funcName }} intercepts the requests made to the {{ .Name }} action of the {{ .Parent.Name }} resource.
+	{{ $funcName }}(ctx context.Context, req *http.Request{{ if .Payload }}, payload {{ gotyperef .Payload .Payload.AllRequired 1 false }}{{ end }}, next func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error)
+{{ end }}{{ end }}}
+{{ end }}
+// ClientOption configures the client instantiated by New.
+type ClientOption func(*Client)
 
-// New instantiates the client.
-func New(c goaclient.Doer) *Client {
+// WithScheme sets the scheme used by the client requests, it overrides the action schemes.
+func WithScheme(scheme string) ClientOption {
+	return func(c *Client) {
+		c.Scheme = scheme
+	}
+}
+
+// WithHost sets the hostname of the service called by the client.
+func WithHost(host string) ClientOption {
+	return func(c *Client) {
+		c.Host = host
+	}
+}
+
+// WithUserAgent sets the user agent set in the requests made by the client.
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.UserAgent = ua
+	}
+}
+
+// WithEncoder registers the encoder used to encode request bodies with the given content types.
+func WithEncoder(f goa.EncoderFunc, contentTypes ...string) ClientOption {
+	return func(c *Client) {
+		c.Encoder.Register(f, contentTypes...)
+	}
+}
+
+// WithDecoder registers the decoder used to decode response bodies with the given content types.
+func WithDecoder(f goa.DecoderFunc, contentTypes ...string) ClientOption {
+	return func(c *Client) {
+		c.Decoder.Register(f, contentTypes...)
+	}
+}
+
+// WithResponseValidation sets whether the client validates the decoded responses against the
+// validations defined in the design. Validation may also be toggled at runtime by setting the
+// ValidateResponses field of the client.
+func WithResponseValidation(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.ValidateResponses = enabled
+	}
+}
+
+// WithCircuitBreaker wraps each endpoint of the client with a circuit breaker so that requests
+// sent to a failing endpoint fail fast with goaclient.ErrCircuitOpen, see goaclient.BreakerDoer.
+func WithCircuitBreaker(opts ...goaclient.BreakerOption) ClientOption {
+	return func(c *Client) {
+		c.Doer = goaclient.BreakerDoer(c.Doer, opts...)
+	}
+}
+
+// WithRetry retries the failed requests sent to idempotent endpoints, see goaclient.RetryDoer.
+// The retry policies defined in the design apply to their endpoints unless overridden with
+// goaclient.EndpointRetry.
+func WithRetry(opts ...goaclient.RetryOption) ClientOption {
+	return func(c *Client) {
+{{- if .RetryPolicies }}
+		opts := append([]goaclient.RetryOption{
+{{- range .RetryPolicies }}
+			goaclient.EndpointRetry({{ printf "%q" .Endpoint }}, goaclient.RetryPolicy{ {{- if .MaxAttempts }}MaxAttempts: {{ .MaxAttempts }}, {{ end }}{{ if .Idempotent }}Idempotent: true, {{ end }}{{ if .Disabled }}Disabled: true{{ end }}}),
+{{- end }}
+		}, opts...)
+{{- end }}
+		c.Doer = goaclient.RetryDoer(c.Doer, opts...)
+	}
+}
+
+{{ range .Interceptors }}// With{{ .TypeName }} sets the client side {{ printf "%q" .Name }} interceptor.
+func With{{ .TypeName }}(i {{ .TypeName }}) ClientOption {
+	return func(c *Client) {
+		c.{{ .TypeName }} = i
+	}
+}
+
+{{ end }}// WithDebug dumps the requests and responses at the debug level using the logger stored in the
+// request context, see goaclient.DebugDoer.
+func WithDebug(opts ...goaclient.DebugOption) ClientOption {
+	return func(c *Client) {
+		c.Doer = goaclient.DebugDoer(c.Doer, opts...)
+	}
+}
+
+// New instantiates the client. Use goaclient.HTTPClientDoer to create a client from a
+// *http.Client.
+func New(c goaclient.Doer, opts ...ClientOption) *Client {
 	client := &Client{
 		Client: goaclient.New(c),
 		Encoder: goa.NewHTTPEncoder(),
@@ -1133,7 +1488,10 @@ func New(c goaclient.Doer) *Client {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}
-{{ end }}	return client
+{{ end }}	for _, opt := range opts {
+		opt(client)
+	}
+	return client
 }
 
 {{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
 	"github.com/goadesign/goa/goagen/codegen"
 	"github.com/goadesign/goa/goagen/gen_client"
@@ -17,6 +18,10 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+// dslDesign is the API definition registered with the DSL engine, the tests that do not use
+// the DSL replace design.Design.
+var dslDesign = design.Design
+
 var _ = Describe("Generate", func() {
 	const testgenPackagePath = "github.com/goadesign/goa/goagen/gen_client/test_"
 
@@ -130,6 +135,27 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(string(content)).Should(HavePrefix(userTypesHeader))
 		})
+
+		It("generates a constructor accepting functional options", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func New(c goaclient.Doer, opts ...ClientOption) *Client {"))
+			Ω(string(content)).Should(ContainSubstring("func WithHost(host string) ClientOption {"))
+			Ω(string(content)).Should(ContainSubstring("func WithEncoder(f goa.EncoderFunc, contentTypes ...string) ClientOption {"))
+			Ω(string(content)).Should(ContainSubstring("for _, opt := range opts {\n\t\topt(client)\n\t}"))
+		})
+
+		It("names the endpoints for the circuit breakers", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`return c.Client.Do(goaclient.SetContextEndpoint(ctx, "foo.show"), req)`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func WithCircuitBreaker(opts ...goaclient.BreakerOption) ClientOption {"))
+			Ω(string(content)).Should(ContainSubstring("func WithDebug(opts ...goaclient.DebugOption) ClientOption {"))
+		})
 	})
 
 	Context("with a required UUID header", func() {
@@ -565,6 +591,273 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("uuid \"github.com/goadesign/goa/uuid\""))
 		})
 	})
+
+	Context("with a media type with validations", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			widget := apidsl.MediaType("application/vnd.widget", func() {
+				apidsl.Attributes(func() {
+					apidsl.Attribute("name", design.String, func() {
+						apidsl.MinLength(2)
+					})
+					apidsl.Required("name")
+				})
+				apidsl.View("default", func() {
+					apidsl.Attribute("name")
+				})
+			})
+			apidsl.Resource("widget", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET(""))
+					apidsl.Response(design.OK, widget)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("validates decoded responses when enabled", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("if err == nil && c.ValidateResponses {\n\t\terr = decoded.Validate()\n\t}"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func WithResponseValidation(enabled bool) ClientOption {"))
+		})
+	})
+
+	Context("with an action with a timeout", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			apidsl.Resource("widget", func() {
+				apidsl.Action("export", func() {
+					apidsl.Routing(apidsl.POST("/export"))
+					apidsl.Timeout("30s")
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("sets the request timeout", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "widget.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`ctx = goaclient.SetContextTimeout(ctx, 30*time.Second)
+	return c.Client.Do(goaclient.SetContextEndpoint(ctx, "widget.export"), req)`))
+		})
+	})
+
+	Context("with an action with interceptors", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			apidsl.Resource("widget", func() {
+				apidsl.Interceptor("audit")
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Interceptor("cache")
+					apidsl.Payload(func() {
+						apidsl.Member("name", design.String)
+					})
+					apidsl.Response(design.Created)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates the interceptor interfaces and runs them around the requests", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("CreateWidget(ctx context.Context, req *http.Request, payload *CreateWidgetPayload, next func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error)"))
+			Ω(string(content)).Should(ContainSubstring("func WithCacheInterceptor(i CacheInterceptor) ClientOption {"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "client", "widget.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`	if c.CacheInterceptor != nil {
+		next := do
+		do = func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			return c.CacheInterceptor.CreateWidget(ctx, req, payload, next)
+		}
+	}
+	if c.AuditInterceptor != nil {`))
+		})
+	})
+
+	Context("with a bidirectional streaming action", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			msg := apidsl.Type("Message", func() {
+				apidsl.Attribute("body", design.String)
+			})
+			apidsl.Resource("room", func() {
+				apidsl.Action("chat", func() {
+					apidsl.Routing(apidsl.GET("/chat"))
+					apidsl.StreamingPayload(msg)
+					apidsl.StreamingResult(msg)
+					apidsl.KeepAlive("30s")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates a stream with typed Send, Recv and Close methods", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "room.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("s.stop = goa.KeepAlive(ws, 30*time.Second)"))
+			Ω(string(content)).Should(ContainSubstring("func (s *ChatRoomStream) Send(v *Message) error {"))
+			Ω(string(content)).Should(ContainSubstring("func (s *ChatRoomStream) Recv() (*Message, error) {"))
+			Ω(string(content)).Should(ContainSubstring("func (s *ChatRoomStream) Close() error {"))
+		})
+	})
+
+	Context("with a newline delimited JSON streaming action", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			item := apidsl.Type("Item", func() {
+				apidsl.Attribute("name", design.String)
+			})
+			apidsl.Resource("widget", func() {
+				apidsl.Action("export", func() {
+					apidsl.Routing(apidsl.GET("/export"))
+					apidsl.Params(func() {
+						apidsl.Param("since", design.DateTime)
+					})
+					apidsl.StreamingResult(item)
+					apidsl.NDJSON()
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("generates an iterator over the results", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "widget.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`req.Header.Set("Accept", "application/x-ndjson")`))
+			Ω(string(content)).Should(ContainSubstring("func (c *Client) ExportWidgetStream(ctx context.Context, path string, since *time.Time) (*ExportWidgetStream, error) {"))
+			Ω(string(content)).Should(ContainSubstring("return &ExportWidgetStream{body: resp.Body, dec: json.NewDecoder(resp.Body)}, nil"))
+			Ω(string(content)).Should(ContainSubstring("func (s *ExportWidgetStream) Next() (*Item, error) {"))
+			Ω(string(content)).Should(ContainSubstring("func (s *ExportWidgetStream) Close() error {"))
+			Ω(string(content)).ShouldNot(ContainSubstring("websocket"))
+		})
+	})
+
+	Context("with a versioned API", func() {
+		// define defines a versioned API using the given DSL to select the versioning scheme.
+		define := func(scheme func()) {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", func() {
+				apidsl.Versions("v1", "v2")
+				scheme()
+			})
+			apidsl.Resource("bottle", func() {
+				apidsl.BasePath("/bottles")
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/:id"))
+					apidsl.Response(design.OK)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			define(func() {
+				apidsl.VersionMediaType("version")
+			})
+		})
+
+		It("selects the latest version in the requests", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "bottle.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`req.Header.Set("Accept", "*/*; version=v2")`))
+			Ω(string(content)).Should(ContainSubstring(`fmt.Sprintf("/bottles/%s", param0)`))
+		})
+
+		Context("using path versioning", func() {
+			BeforeEach(func() {
+				define(func() {})
+			})
+
+			It("prefixes the request paths with the latest version", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "bottle.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`fmt.Sprintf("/v2/bottles/%s", param0)`))
+				Ω(string(content)).ShouldNot(ContainSubstring(`req.Header.Set("Accept"`))
+			})
+		})
+	})
+
+	Context("with an action skipping the request body decoding", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			apidsl.Resource("file", func() {
+				apidsl.Action("upload", func() {
+					apidsl.Routing(apidsl.PUT("/files/:name"))
+					apidsl.Params(func() {
+						apidsl.Param("name", design.String)
+					})
+					apidsl.SkipRequestBodyEncodeDecode()
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("sends the given reader as request body", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "file.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (c *Client) UploadFile(ctx context.Context, path string, body io.Reader, contentType string) (*http.Response, error) {"))
+			Ω(string(content)).Should(ContainSubstring(`req, err := http.NewRequest("PUT", u.String(), body)`))
+			Ω(string(content)).Should(ContainSubstring(`req.Header.Set("Content-Type", contentType)`))
+		})
+	})
+
+	Context("with retry metadata", func() {
+		BeforeEach(func() {
+			design.Design = dslDesign
+			dslengine.Reset()
+			apidsl.API("testapi", nil)
+			apidsl.Resource("widget", func() {
+				apidsl.Action("create", func() {
+					apidsl.Routing(apidsl.POST(""))
+					apidsl.Metadata("retry:attempts", "5")
+					apidsl.Metadata("retry:idempotent")
+					apidsl.Response(design.Created)
+				})
+				apidsl.Action("delete", func() {
+					apidsl.Routing(apidsl.DELETE("/:id"))
+					apidsl.Metadata("retry:disable")
+					apidsl.Response(design.NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("applies the endpoint retry policies", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`goaclient.EndpointRetry("widget.create", goaclient.RetryPolicy{MaxAttempts: 5, Idempotent: true}),`))
+			Ω(string(content)).Should(ContainSubstring(`goaclient.EndpointRetry("widget.delete", goaclient.RetryPolicy{Disabled: true}),`))
+		})
+	})
 })
 
 var _ = Describe("NewGenerator", func() {
@@ -0,0 +1,22 @@
+/*
+Package gendesign provides a generator that produces a goa design package from an existing API
+description so that teams migrating existing APIs do not have to translate it by hand.
+
+The generator reads OpenAPI 2.0 (Swagger) and OpenAPI 3.0 documents in JSON or YAML and produces:
+
+  - the API definition from the document info, host, schemes and base path,
+  - a media type for each schema used in a response body and a type for the other schemas,
+  - a resource for each operation tag - or first path segment for operations without tags -
+    with an action for each operation including its routing, parameters, headers, cookies,
+    payload and responses.
+
+The generator also reads .proto files and produces a type for each message so that teams using
+protocol buffers as the source of truth for their data model can keep the design types in sync.
+Enums are described with Enum validations and the fields of oneofs are flattened into the type,
+services are ignored.
+
+The generated design is a starting point: security schemes, examples of complex values and
+schemas that cannot be described with the goa DSL (for example "oneOf") are not imported and
+must be completed by hand.
+*/
+package gendesign
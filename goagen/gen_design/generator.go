@@ -0,0 +1,77 @@
+package gendesign
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//NewGenerator returns an initialized instance of a design generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{Pkg: "design"}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design generator.
+type Generator struct {
+	Spec     string   // Path to the imported API description file
+	OutDir   string   // Path to output directory
+	Pkg      string   // Name of the generated design package
+	genfiles []string // Generated files
+}
+
+// Generate reads the API description and writes the design package, it does not overwrite an
+// existing design file. Files with the ".proto" extension are imported with Proto, other files
+// with OpenAPI.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.Spec == "" {
+		return nil, fmt.Errorf("missing API description file")
+	}
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	data, err := ioutil.ReadFile(g.Spec)
+	if err != nil {
+		return nil, err
+	}
+	importer := OpenAPI
+	if filepath.Ext(g.Spec) == ".proto" {
+		importer = Proto
+	}
+	src, err := importer(data, g.Pkg, filepath.Base(g.Spec))
+	if err != nil {
+		return nil, fmt.Errorf("failed to import %s: %s", g.Spec, err)
+	}
+
+	dir := filepath.Join(g.OutDir, g.Pkg)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	file := filepath.Join(dir, "design.go")
+	if _, err := os.Stat(file); err == nil {
+		return nil, fmt.Errorf("%s already exists", file)
+	}
+	if err = ioutil.WriteFile(file, src, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, file)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,47 @@
+package gendesign_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/gen_design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "gendesign")
+		Ω(err).ShouldNot(HaveOccurred())
+		spec := filepath.Join(outDir, "openapi.yaml")
+		Ω(ioutil.WriteFile(spec, []byte(openAPI3Spec), 0644)).Should(Succeed())
+		gen := gendesign.NewGenerator(gendesign.Spec(spec), gendesign.OutDir(outDir))
+		files, genErr = gen.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("writes the design package", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{filepath.Join(outDir, "design", "design.go")}))
+		content, err := ioutil.ReadFile(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(content)).Should(ContainSubstring("package design\n"))
+	})
+
+	It("does not overwrite an existing design", func() {
+		gen := gendesign.NewGenerator(gendesign.Spec(filepath.Join(outDir, "openapi.yaml")), gendesign.OutDir(outDir))
+		_, err := gen.Generate()
+		Ω(err).Should(HaveOccurred())
+		_, err = os.Stat(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,1167 @@
+package gendesign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// openAPIDoc is the subset of the OpenAPI 2.0 and 3.0 documents imported by OpenAPI.
+	openAPIDoc struct {
+		Swagger     string                                `json:"swagger"`
+		OpenAPI     string                                `json:"openapi"`
+		Info        *openAPIInfo                          `json:"info"`
+		Host        string                                `json:"host"`
+		BasePath    string                                `json:"basePath"`
+		Schemes     []string                              `json:"schemes"`
+		Servers     []*openAPIServer                      `json:"servers"`
+		Consumes    []string                              `json:"consumes"`
+		Produces    []string                              `json:"produces"`
+		Paths       map[string]map[string]json.RawMessage `json:"paths"`
+		Definitions map[string]*openAPISchema             `json:"definitions"`
+		Parameters  map[string]*openAPIParameter          `json:"parameters"`
+		Responses   map[string]*openAPIResponse           `json:"responses"`
+		Components  *openAPIComponents                    `json:"components"`
+	}
+
+	// openAPIInfo describes the API.
+	openAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	}
+
+	// openAPIServer is an OpenAPI 3.0 server.
+	openAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	// openAPIComponents contains the OpenAPI 3.0 reusable objects.
+	openAPIComponents struct {
+		Schemas       map[string]*openAPISchema      `json:"schemas"`
+		Parameters    map[string]*openAPIParameter   `json:"parameters"`
+		Responses     map[string]*openAPIResponse    `json:"responses"`
+		RequestBodies map[string]*openAPIRequestBody `json:"requestBodies"`
+	}
+
+	// openAPISchema is a schema object.
+	openAPISchema struct {
+		Ref                  string                    `json:"$ref"`
+		Type                 string                    `json:"type"`
+		Format               string                    `json:"format"`
+		Description          string                    `json:"description"`
+		Items                *openAPISchema            `json:"items"`
+		Properties           map[string]*openAPISchema `json:"properties"`
+		AdditionalProperties json.RawMessage           `json:"additionalProperties"`
+		Required             []string                  `json:"required"`
+		AllOf                []*openAPISchema          `json:"allOf"`
+		Enum                 []interface{}             `json:"enum"`
+		Default              interface{}               `json:"default"`
+		Example              interface{}               `json:"example"`
+		Pattern              string                    `json:"pattern"`
+		Minimum              *float64                  `json:"minimum"`
+		Maximum              *float64                  `json:"maximum"`
+		MinLength            *int                      `json:"minLength"`
+		MaxLength            *int                      `json:"maxLength"`
+		MinItems             *int                      `json:"minItems"`
+		MaxItems             *int                      `json:"maxItems"`
+	}
+
+	// openAPIParameter is a parameter object. OpenAPI 2.0 parameters other than body
+	// parameters describe their type inline, OpenAPI 3.0 parameters use a schema.
+	openAPIParameter struct {
+		Ref         string         `json:"$ref"`
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description"`
+		Required    bool           `json:"required"`
+		Schema      *openAPISchema `json:"schema"`
+		openAPISchema
+	}
+
+	// openAPIRequestBody is an OpenAPI 3.0 request body.
+	openAPIRequestBody struct {
+		Ref         string                       `json:"$ref"`
+		Description string                       `json:"description"`
+		Required    bool                         `json:"required"`
+		Content     map[string]*openAPIMediaType `json:"content"`
+	}
+
+	// openAPIMediaType is an OpenAPI 3.0 media type object.
+	openAPIMediaType struct {
+		Schema *openAPISchema `json:"schema"`
+	}
+
+	// openAPIResponse is a response object. OpenAPI 2.0 responses define a schema, OpenAPI
+	// 3.0 responses define content.
+	openAPIResponse struct {
+		Ref         string                       `json:"$ref"`
+		Description string                       `json:"description"`
+		Schema      *openAPISchema               `json:"schema"`
+		Content     map[string]*openAPIMediaType `json:"content"`
+		Headers     map[string]*openAPIParameter `json:"headers"`
+	}
+
+	// openAPIOperation is an operation object.
+	openAPIOperation struct {
+		OperationID string                      `json:"operationId"`
+		Summary     string                      `json:"summary"`
+		Description string                      `json:"description"`
+		Tags        []string                    `json:"tags"`
+		Consumes    []string                    `json:"consumes"`
+		Parameters  []*openAPIParameter         `json:"parameters"`
+		RequestBody *openAPIRequestBody         `json:"requestBody"`
+		Responses   map[string]*openAPIResponse `json:"responses"`
+	}
+
+	// openAPIImporter builds the design of an OpenAPI document.
+	openAPIImporter struct {
+		doc *openAPIDoc
+		// schemas contains the object schemas declared as types indexed by type name.
+		schemas map[string]*openAPISchema
+		// names lists the type names in order of declaration.
+		names []string
+		// declared contains the names of the declared types indexed by schema.
+		declared map[*openAPISchema]string
+		// media records the names of the types declared as media types.
+		media map[string]bool
+		// resolving records the references being resolved to detect cycles.
+		resolving map[string]bool
+		// expanding records the references to schemas described inline being written to
+		// detect cycles.
+		expanding map[string]bool
+	}
+
+	// importedResource is a resource built from the operations sharing a tag.
+	importedResource struct {
+		name    string
+		actions []*importedAction
+	}
+
+	// importedAction is an action built from an operation.
+	importedAction struct {
+		name   string
+		verb   string
+		path   string
+		op     *openAPIOperation
+		params []*openAPIParameter
+	}
+)
+
+// openAPIVerbs lists the operation keys of path items in order of generation.
+var openAPIVerbs = []string{"get", "put", "post", "patch", "delete", "head", "options", "trace"}
+
+// openAPIPathParamRegex captures the OpenAPI path parameters.
+var openAPIPathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+// openAPINonIdentRegex matches the characters of operation IDs that separate words.
+var openAPINonIdentRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// reservedVarNames lists the identifiers dot-imported from the DSL packages that may clash with
+// the names of the variables holding the imported types and media types.
+var reservedVarNames = map[string]bool{
+	"ContentType":          true,
+	"DataType":             true,
+	"DefaultMedia":         true,
+	"ErrorMedia":           true,
+	"JobMedia":             true,
+	"UnsupportedMediaType": true,
+}
+
+// responseNames contains the names of the goa default responses indexed by status code.
+var responseNames = func() map[int]string {
+	names := make(map[int]string)
+	for n, r := range design.NewAPIDefinition().DefaultResponses {
+		names[r.Status] = n
+	}
+	return names
+}()
+
+// OpenAPI returns the source code of a design package named pkg describing the API defined by the
+// given OpenAPI 2.0 or 3.0 document. The document may be encoded in JSON or YAML. source is the
+// name of the document mentioned in the generated file header.
+func OpenAPI(data []byte, pkg, source string) ([]byte, error) {
+	doc, err := parseOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+	return newOpenAPIImporter(doc).design(pkg, source)
+}
+
+// newOpenAPIImporter returns an importer for the given document.
+func newOpenAPIImporter(doc *openAPIDoc) *openAPIImporter {
+	return &openAPIImporter{
+		doc:       doc,
+		schemas:   make(map[string]*openAPISchema),
+		declared:  make(map[*openAPISchema]string),
+		media:     make(map[string]bool),
+		resolving: make(map[string]bool),
+		expanding: make(map[string]bool),
+	}
+}
+
+// parseOpenAPI decodes the given JSON or YAML OpenAPI document.
+func parseOpenAPI(data []byte) (*openAPIDoc, error) {
+	js := bytes.TrimSpace(data)
+	if len(js) == 0 || js[0] != '{' {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if js, err = json.Marshal(JSONCompatible(v)); err != nil {
+			return nil, err
+		}
+	}
+	var doc openAPIDoc
+	if err := json.Unmarshal(js, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.Swagger, "2.") && !strings.HasPrefix(doc.OpenAPI, "3.0") {
+		return nil, fmt.Errorf("not an OpenAPI 2.0 or 3.0 document")
+	}
+	if doc.Info == nil {
+		doc.Info = &openAPIInfo{}
+	}
+	if doc.Components == nil {
+		doc.Components = &openAPIComponents{}
+	}
+	return &doc, nil
+}
+
+// JSONCompatible converts the maps produced by the YAML decoder so that they can be encoded in
+// JSON.
+func JSONCompatible(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			m[fmt.Sprint(k)] = JSONCompatible(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = JSONCompatible(e)
+		}
+	}
+	return v
+}
+
+// design produces the design package source.
+func (im *openAPIImporter) design(pkg, source string) ([]byte, error) {
+	defs := im.doc.Definitions
+	if len(im.doc.Components.Schemas) > 0 {
+		defs = im.doc.Components.Schemas
+	}
+	keys := make([]string, 0, len(defs))
+	for n := range defs {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	for _, n := range keys {
+		if im.isObject(defs[n]) {
+			im.declare(codegen.Goify(n, true), defs[n])
+		}
+	}
+	resources, err := im.resources()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, pkg, source)
+	im.writeAPI(&buf)
+	for _, r := range resources {
+		if err := im.writeResource(&buf, r); err != nil {
+			return nil, err
+		}
+	}
+	return im.writeTypes(&buf)
+}
+
+// writeHeader writes the design package header.
+func writeHeader(buf *bytes.Buffer, pkg, source string) {
+	fmt.Fprintf(buf, "// Package %s contains the design imported from %s by goagen import. The design is a\n", pkg, source)
+	buf.WriteString("// starting point, review it and complete it as needed.\n")
+	fmt.Fprintf(buf, "package %s\n\nimport (\n\t. \"github.com/goadesign/goa/design\"\n\t. \"github.com/goadesign/goa/design/apidsl\"\n)\n\n", pkg)
+}
+
+// writeTypes writes the declared types and returns the formatted design package source.
+func (im *openAPIImporter) writeTypes(buf *bytes.Buffer) ([]byte, error) {
+	// Types may be declared while writing other types.
+	for i := 0; i < len(im.names); i++ {
+		im.writeType(buf, im.names[i])
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated design: %s", err)
+	}
+	return src, nil
+}
+
+// declare records the declaration of the type with the given name described by s and returns
+// the name actually used. Declaring the same schema multiple times returns the same name.
+func (im *openAPIImporter) declare(name string, s *openAPISchema) string {
+	if n, ok := im.declared[s]; ok {
+		return n
+	}
+	base := name
+	for i := 2; im.schemas[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	im.schemas[name] = s
+	im.declared[s] = name
+	im.names = append(im.names, name)
+	return name
+}
+
+// resources builds the resources from the document operations.
+func (im *openAPIImporter) resources() ([]*importedResource, error) {
+	paths := make([]string, 0, len(im.doc.Paths))
+	for p := range im.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var (
+		res    []*importedResource
+		byName = make(map[string]*importedResource)
+		used   = make(map[string]map[string]bool)
+	)
+	for _, p := range paths {
+		item := im.doc.Paths[p]
+		var common []*openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &common); err != nil {
+				return nil, fmt.Errorf("invalid parameters of path %s: %s", p, err)
+			}
+		}
+		for _, verb := range openAPIVerbs {
+			raw, ok := item[verb]
+			if !ok {
+				continue
+			}
+			var op openAPIOperation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid %s operation of path %s: %s", strings.ToUpper(verb), p, err)
+			}
+			rname := resourceName(p, &op)
+			r, ok := byName[rname]
+			if !ok {
+				r = &importedResource{name: rname}
+				byName[rname] = r
+				used[rname] = make(map[string]bool)
+				res = append(res, r)
+			}
+			name := actionName(verb, p, &op)
+			for base, i := name, 2; used[rname][name]; i++ {
+				name = fmt.Sprintf("%s_%d", base, i)
+			}
+			used[rname][name] = true
+			params, err := im.operationParams(common, op.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %s", strings.ToUpper(verb), p, err)
+			}
+			a := &importedAction{
+				name:   name,
+				verb:   strings.ToUpper(verb),
+				path:   openAPIPathParamRegex.ReplaceAllString(p, ":$1"),
+				op:     &op,
+				params: params,
+			}
+			if err := im.declareResponses(a); err != nil {
+				return nil, fmt.Errorf("%s %s: %s", a.verb, p, err)
+			}
+			r.actions = append(r.actions, a)
+		}
+	}
+	return res, nil
+}
+
+// resourceName returns the name of the resource of the given operation: its first tag or the
+// first segment of its path.
+func resourceName(path string, op *openAPIOperation) string {
+	if len(op.Tags) > 0 && op.Tags[0] != "" {
+		return op.Tags[0]
+	}
+	for _, s := range strings.Split(path, "/") {
+		if s != "" && !strings.HasPrefix(s, "{") {
+			return s
+		}
+	}
+	return "root"
+}
+
+// actionName returns the name of the action of the given operation: the snake case version of
+// its operation ID or a name built from the verb and path. Operation IDs of the form
+// "resource#action" produced by goagen swagger are stripped of their resource prefix.
+func actionName(verb, path string, op *openAPIOperation) string {
+	if id := op.OperationID; id != "" {
+		if i := strings.LastIndex(id, "#"); i >= 0 && i < len(id)-1 {
+			id = id[i+1:]
+		}
+		return codegen.SnakeCase(openAPINonIdentRegex.ReplaceAllString(id, "_"))
+	}
+	parts := []string{verb}
+	for _, s := range strings.Split(path, "/") {
+		s = strings.Trim(s, "{}")
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return codegen.SnakeCase(codegen.Goify(strings.Join(parts, "_"), true))
+}
+
+// operationParams returns the parameters of an operation given the parameters common to the
+// path and the operation parameters, operation parameters override common parameters.
+func (im *openAPIImporter) operationParams(common, params []*openAPIParameter) ([]*openAPIParameter, error) {
+	var res []*openAPIParameter
+	index := make(map[string]int)
+	for _, p := range append(append([]*openAPIParameter{}, common...), params...) {
+		p, err := im.resolveParam(p)
+		if err != nil {
+			return nil, err
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			res[i] = p
+			continue
+		}
+		index[key] = len(res)
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+// resolveParam returns the parameter p refers to if it is a reference, p otherwise.
+func (im *openAPIImporter) resolveParam(p *openAPIParameter) (*openAPIParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "#/parameters/", "#/components/parameters/")
+	if err != nil {
+		return nil, err
+	}
+	rp, ok := im.doc.Parameters[name]
+	if !ok {
+		rp, ok = im.doc.Components.Parameters[name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter %s", p.Ref)
+	}
+	return im.resolveParam(rp)
+}
+
+// resolveResponse returns the response r refers to if it is a reference, r otherwise.
+func (im *openAPIImporter) resolveResponse(r *openAPIResponse) (*openAPIResponse, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+	name, err := refName(r.Ref, "#/responses/", "#/components/responses/")
+	if err != nil {
+		return nil, err
+	}
+	rr, ok := im.doc.Responses[name]
+	if !ok {
+		rr, ok = im.doc.Components.Responses[name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown response %s", r.Ref)
+	}
+	return im.resolveResponse(rr)
+}
+
+// resolveBody returns the request body b refers to if it is a reference, b otherwise.
+func (im *openAPIImporter) resolveBody(b *openAPIRequestBody) (*openAPIRequestBody, error) {
+	if b.Ref == "" {
+		return b, nil
+	}
+	name, err := refName(b.Ref, "#/components/requestBodies/")
+	if err != nil {
+		return nil, err
+	}
+	rb, ok := im.doc.Components.RequestBodies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown request body %s", b.Ref)
+	}
+	return im.resolveBody(rb)
+}
+
+// resolve returns the schema s refers to if it is a reference, s otherwise.
+func (im *openAPIImporter) resolve(s *openAPISchema) *openAPISchema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+	name, err := refName(s.Ref, "#/definitions/", "#/components/schemas/")
+	if err != nil {
+		return &openAPISchema{}
+	}
+	rs, ok := im.doc.Definitions[name]
+	if !ok {
+		rs, ok = im.doc.Components.Schemas[name]
+	}
+	if !ok || im.resolving[s.Ref] {
+		return &openAPISchema{}
+	}
+	im.resolving[s.Ref] = true
+	defer delete(im.resolving, s.Ref)
+	return im.resolve(rs)
+}
+
+// refName returns the name of the object referred to by the local reference ref that must
+// start with one of the given prefixes.
+func refName(ref string, prefixes ...string) (string, error) {
+	for _, p := range prefixes {
+		if strings.HasPrefix(ref, p) {
+			return strings.Replace(strings.Replace(ref[len(p):], "~1", "/", -1), "~0", "~", -1), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported reference %s", ref)
+}
+
+// typeName returns the name of the type declared for the object schema s refers to if any.
+func (im *openAPIImporter) typeName(s *openAPISchema) string {
+	if s == nil || s.Ref == "" {
+		return ""
+	}
+	name, err := refName(s.Ref, "#/definitions/", "#/components/schemas/")
+	if err != nil {
+		return ""
+	}
+	if n := codegen.Goify(name, true); im.schemas[n] != nil {
+		return n
+	}
+	return ""
+}
+
+// isObject returns true if s describes an object with properties.
+func (im *openAPIImporter) isObject(s *openAPISchema) bool {
+	props, _ := im.properties(s)
+	return len(props) > 0
+}
+
+// properties returns the properties and required properties of the object described by s
+// including the properties of the schemas listed in allOf.
+func (im *openAPIImporter) properties(s *openAPISchema) (map[string]*openAPISchema, []string) {
+	s = im.resolve(s)
+	if s == nil {
+		return nil, nil
+	}
+	if len(s.AllOf) == 0 {
+		return s.Properties, s.Required
+	}
+	props := make(map[string]*openAPISchema)
+	required := s.Required
+	for n, p := range s.Properties {
+		props[n] = p
+	}
+	for _, a := range s.AllOf {
+		ps, req := im.properties(a)
+		for n, p := range ps {
+			props[n] = p
+		}
+		for _, n := range req {
+			if !contains(required, n) {
+				required = append(required, n)
+			}
+		}
+	}
+	return props, required
+}
+
+// contains returns true if vals contains v.
+func contains(vals []string, v string) bool {
+	for _, val := range vals {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
+
+// declareResponses declares the media types describing the action response bodies.
+func (im *openAPIImporter) declareResponses(a *importedAction) error {
+	for code, r := range a.op.Responses {
+		r, err := im.resolveResponse(r)
+		if err != nil {
+			return err
+		}
+		if name, _ := im.responseType(a, code, r); name != "" {
+			im.media[name] = true
+		}
+	}
+	return nil
+}
+
+// responseType returns the name of the type describing the body of the response with the given
+// status code of a and whether the body is a collection of this type. Inline objects are declared
+// as types named after the action and response.
+func (im *openAPIImporter) responseType(a *importedAction, code string, r *openAPIResponse) (string, bool) {
+	s := im.responseSchema(r)
+	if s == nil {
+		return "", false
+	}
+	if n := im.typeName(s); n != "" {
+		return n, false
+	}
+	name := codegen.Goify(a.name, true) + codegen.Goify(responseName(code), true) + "Response"
+	rs := im.resolve(s)
+	if rs.Type == "array" && rs.Items != nil {
+		if n := im.typeName(rs.Items); n != "" {
+			return n, true
+		}
+		if items := im.resolve(rs.Items); im.isObject(items) {
+			return im.declare(name, items), true
+		}
+		return "", false
+	}
+	if im.isObject(rs) {
+		return im.declare(name, rs), false
+	}
+	return "", false
+}
+
+// responseSchema returns the schema of the response body if any.
+func (im *openAPIImporter) responseSchema(r *openAPIResponse) *openAPISchema {
+	if r.Schema != nil {
+		return r.Schema
+	}
+	return contentSchema(r.Content)
+}
+
+// contentSchema returns the schema of the JSON content if any or of the first content that
+// defines a schema otherwise.
+func contentSchema(content map[string]*openAPIMediaType) *openAPISchema {
+	if c, ok := content["application/json"]; ok && c.Schema != nil {
+		return c.Schema
+	}
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if content[k].Schema != nil {
+			return content[k].Schema
+		}
+	}
+	return nil
+}
+
+// responseName returns the name of the goa response with the given status code, a name derived
+// from the status text for status codes that do not correspond to a goa default response.
+func responseName(code string) string {
+	status, err := strconv.Atoi(code)
+	if err != nil {
+		return ""
+	}
+	if n, ok := responseNames[status]; ok {
+		return n
+	}
+	if text := http.StatusText(status); text != "" {
+		return codegen.Goify(strings.ToLower(text), true)
+	}
+	return "Status" + code
+}
+
+// writeAPI writes the API definition.
+func (im *openAPIImporter) writeAPI(buf *bytes.Buffer) {
+	info := im.doc.Info
+	name := info.Title
+	if name == "" {
+		name = "api"
+	}
+	fmt.Fprintf(buf, "var _ = API(%q, func() {\n", codegen.KebabCase(codegen.Goify(name, true)))
+	if info.Title != "" {
+		fmt.Fprintf(buf, "Title(%q)\n", info.Title)
+	}
+	if info.Description != "" {
+		fmt.Fprintf(buf, "Description(%q)\n", info.Description)
+	}
+	if info.Version != "" {
+		fmt.Fprintf(buf, "Version(%q)\n", info.Version)
+	}
+	host, schemes, basePath := im.doc.Host, im.doc.Schemes, im.doc.BasePath
+	if len(im.doc.Servers) > 0 {
+		if u, err := url.Parse(im.doc.Servers[0].URL); err == nil {
+			host, basePath = u.Host, u.Path
+			if u.Scheme != "" {
+				schemes = []string{u.Scheme}
+			}
+		}
+	}
+	if host != "" {
+		fmt.Fprintf(buf, "Host(%q)\n", host)
+	}
+	if len(schemes) > 0 {
+		fmt.Fprintf(buf, "Scheme(%s)\n", quoteAll(schemes))
+	}
+	if basePath != "" && basePath != "/" {
+		fmt.Fprintf(buf, "BasePath(%q)\n", strings.TrimSuffix(basePath, "/"))
+	}
+	for _, c := range knownEncodings(im.doc.Consumes) {
+		fmt.Fprintf(buf, "Consumes(%q)\n", c)
+	}
+	for _, p := range knownEncodings(im.doc.Produces) {
+		fmt.Fprintf(buf, "Produces(%q)\n", p)
+	}
+	buf.WriteString("})\n\n")
+}
+
+// knownEncodings returns the MIME types that have a known goa encoder.
+func knownEncodings(mimeTypes []string) []string {
+	var res []string
+	for _, m := range mimeTypes {
+		if design.HasKnownEncoder(m) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// writeResource writes the definition of r.
+func (im *openAPIImporter) writeResource(buf *bytes.Buffer, r *importedResource) error {
+	fmt.Fprintf(buf, "var _ = Resource(%q, func() {\n", r.name)
+	for _, a := range r.actions {
+		if err := im.writeAction(buf, a); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("})\n\n")
+	return nil
+}
+
+// writeAction writes the definition of a.
+func (im *openAPIImporter) writeAction(buf *bytes.Buffer, a *importedAction) error {
+	op := a.op
+	fmt.Fprintf(buf, "Action(%q, func() {\n", a.name)
+	if desc := strings.TrimSpace(op.Summary + "\n\n" + op.Description); desc != "" {
+		fmt.Fprintf(buf, "Description(%q)\n", desc)
+	}
+	fmt.Fprintf(buf, "Routing(%s(%q))\n", a.verb, a.path)
+
+	var params, headers, cookies, form []*openAPIParameter
+	var body *openAPIParameter
+	for _, p := range a.params {
+		switch p.In {
+		case "path", "query":
+			params = append(params, p)
+		case "header":
+			headers = append(headers, p)
+		case "cookie":
+			cookies = append(cookies, p)
+		case "formData":
+			form = append(form, p)
+		case "body":
+			body = p
+		}
+	}
+	base := codegen.Goify(a.name, true)
+	im.writeParams(buf, "Params", "Param", params, base)
+	im.writeParams(buf, "Headers", "Header", headers, base)
+	im.writeParams(buf, "Cookies", "Cookie", cookies, base)
+
+	switch {
+	case body != nil:
+		im.writePayload(buf, body.Schema, body.Required, base)
+	case len(form) > 0:
+		multipart := false
+		for _, c := range op.Consumes {
+			multipart = multipart || c == "multipart/form-data"
+		}
+		s := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+		for _, p := range form {
+			s.Properties[p.Name] = paramSchema(p)
+			if p.Required {
+				s.Required = append(s.Required, p.Name)
+			}
+			multipart = multipart || p.Type == "file"
+		}
+		if multipart {
+			buf.WriteString("MultipartForm()\n")
+		}
+		im.writePayload(buf, s, true, base)
+	case op.RequestBody != nil:
+		rb, err := im.resolveBody(op.RequestBody)
+		if err != nil {
+			return err
+		}
+		if s := contentSchema(rb.Content); s != nil {
+			if _, ok := rb.Content["application/json"]; !ok && rb.Content["multipart/form-data"] != nil {
+				buf.WriteString("MultipartForm()\n")
+			}
+			im.writePayload(buf, s, rb.Required, base)
+		}
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for c := range op.Responses {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		name := responseName(code)
+		if name == "" {
+			continue
+		}
+		r, err := im.resolveResponse(op.Responses[code])
+		if err != nil {
+			return err
+		}
+		im.writeResponse(buf, a, code, name, r, base)
+	}
+	buf.WriteString("})\n")
+	return nil
+}
+
+// writeParams writes the given parameters using the container DSL function with the given name
+// and the parameter DSL function fn.
+func (im *openAPIImporter) writeParams(buf *bytes.Buffer, container, fn string, params []*openAPIParameter, base string) {
+	if len(params) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "%s(func() {\n", container)
+	var required []string
+	for _, p := range params {
+		im.writeAttribute(buf, fn, p.Name, paramSchema(p), base+codegen.Goify(p.Name, true))
+		if p.Required && p.In != "path" {
+			required = append(required, p.Name)
+		}
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(buf, "Required(%s)\n", quoteAll(required))
+	}
+	buf.WriteString("})\n")
+}
+
+// paramSchema returns the schema of the parameter p, the schema description is the parameter
+// description if not set.
+func paramSchema(p *openAPIParameter) *openAPISchema {
+	s := p.openAPISchema
+	if p.Schema != nil {
+		s = *p.Schema
+	}
+	if s.Description == "" {
+		s.Description = p.Description
+	}
+	return &s
+}
+
+// writePayload writes the action payload described by s.
+func (im *openAPIImporter) writePayload(buf *bytes.Buffer, s *openAPISchema, required bool, base string) {
+	fn := "Payload"
+	if !required {
+		fn = "OptionalPayload"
+	}
+	if n := im.typeName(s); n != "" {
+		fmt.Fprintf(buf, "%s(%s)\n", fn, im.varName(n))
+		return
+	}
+	if im.isObject(s) {
+		fmt.Fprintf(buf, "%s(func() {\n", fn)
+		im.writeAttributes(buf, "Member", s, base+"Payload")
+		buf.WriteString("})\n")
+		return
+	}
+	fmt.Fprintf(buf, "%s(%s)\n", fn, im.typeExpr(s, base+"Payload"))
+}
+
+// writeResponse writes the response of a with the given status code and goa response name.
+func (im *openAPIImporter) writeResponse(buf *bytes.Buffer, a *importedAction, code, name string, r *openAPIResponse, base string) {
+	var media string
+	if n, collection := im.responseType(a, code, r); n != "" {
+		media = strconv.Quote(mediaTypeIdentifier(n))
+		if collection {
+			media = "CollectionOf(" + media + ")"
+		}
+	}
+	_, isDefault := responseNames[atoi(code)]
+	if isDefault && len(r.Headers) == 0 {
+		// Only the OK response template accepts the media type as parameter.
+		if media == "" {
+			fmt.Fprintf(buf, "Response(%s)\n", name)
+			return
+		}
+		if name == design.OK {
+			fmt.Fprintf(buf, "Response(%s, %s)\n", name, media)
+			return
+		}
+	}
+	if isDefault {
+		fmt.Fprintf(buf, "Response(%s, func() {\n", name)
+	} else {
+		fmt.Fprintf(buf, "Response(%q, func() {\nStatus(%s)\n", name, code)
+	}
+	if r.Description != "" && r.Description != http.StatusText(atoi(code)) {
+		fmt.Fprintf(buf, "Description(%q)\n", r.Description)
+	}
+	if media != "" {
+		fmt.Fprintf(buf, "Media(%s)\n", media)
+	}
+	if len(r.Headers) > 0 {
+		names := make([]string, 0, len(r.Headers))
+		for n := range r.Headers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		buf.WriteString("Headers(func() {\n")
+		for _, n := range names {
+			im.writeAttribute(buf, "Header", n, paramSchema(r.Headers[n]), base+codegen.Goify(n, true))
+		}
+		buf.WriteString("})\n")
+	}
+	buf.WriteString("})\n")
+}
+
+// atoi returns the integer value of s or 0.
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
+
+// mediaTypeIdentifier returns the identifier of the media type declared for the type with the
+// given name.
+func mediaTypeIdentifier(name string) string {
+	return "application/vnd." + codegen.KebabCase(name)
+}
+
+// varName returns the name of the variable holding the type or media type with the given name.
+// The suffix avoids most clashes with the identifiers dot-imported from the DSL packages,
+// reservedVarNames lists the remaining ones.
+func (im *openAPIImporter) varName(name string) string {
+	v := name + "Type"
+	if im.media[name] {
+		v = name + "Media"
+	}
+	if reservedVarNames[v] {
+		v = "Imported" + v
+	}
+	return v
+}
+
+// writeType writes the declaration of the type or media type with the given name.
+func (im *openAPIImporter) writeType(buf *bytes.Buffer, name string) {
+	s := im.resolve(im.schemas[name])
+	if !im.media[name] {
+		fmt.Fprintf(buf, "var %s = Type(%q, func() {\n", im.varName(name), name)
+		if s.Description != "" {
+			fmt.Fprintf(buf, "Description(%q)\n", s.Description)
+		}
+		im.writeAttributes(buf, "Attribute", s, name)
+		buf.WriteString("})\n\n")
+		return
+	}
+	fmt.Fprintf(buf, "var %s = MediaType(%q, func() {\n", im.varName(name), mediaTypeIdentifier(name))
+	if s.Description != "" {
+		fmt.Fprintf(buf, "Description(%q)\n", s.Description)
+	}
+	fmt.Fprintf(buf, "TypeName(%q)\n", name)
+	buf.WriteString("Attributes(func() {\n")
+	im.writeAttributes(buf, "Attribute", s, name)
+	buf.WriteString("})\nView(\"default\", func() {\n")
+	props, _ := im.properties(s)
+	for _, n := range sortedKeys(props) {
+		fmt.Fprintf(buf, "Attribute(%q)\n", n)
+	}
+	buf.WriteString("})\n})\n\n")
+}
+
+// writeAttributes writes the attributes of the object described by s using the DSL function fn.
+func (im *openAPIImporter) writeAttributes(buf *bytes.Buffer, fn string, s *openAPISchema, base string) {
+	props, required := im.properties(s)
+	for _, n := range sortedKeys(props) {
+		im.writeAttribute(buf, fn, n, props[n], base+codegen.Goify(n, true))
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(buf, "Required(%s)\n", quoteAll(required))
+	}
+}
+
+// writeAttribute writes the attribute with the given name described by s using the DSL function
+// fn. base is the name of the type declared for inline objects that cannot be described inline.
+func (im *openAPIImporter) writeAttribute(buf *bytes.Buffer, fn, name string, s *openAPISchema, base string) {
+	if s.Ref == "" && im.isObject(s) && fn != "Param" && fn != "Header" && fn != "Cookie" {
+		fmt.Fprintf(buf, "%s(%q, func() {\n", fn, name)
+		if s.Description != "" {
+			fmt.Fprintf(buf, "Description(%q)\n", s.Description)
+		}
+		im.writeAttributes(buf, "Attribute", s, base)
+		buf.WriteString("})\n")
+		return
+	}
+	fmt.Fprintf(buf, "%s(%q, %s", fn, name, im.typeExpr(s, base))
+	if s.Description != "" {
+		fmt.Fprintf(buf, ", %q", s.Description)
+	}
+	v := s
+	if im.typeName(s) != "" {
+		v = nil
+	} else if s.Ref != "" {
+		v = im.resolve(s)
+	}
+	if dsl := validations(v); dsl != "" {
+		fmt.Fprintf(buf, ", func() {\n%s}", dsl)
+	}
+	buf.WriteString(")\n")
+}
+
+// typeExpr returns the DSL expression of the type described by s. Inline objects are declared
+// as types named after base.
+func (im *openAPIImporter) typeExpr(s *openAPISchema, base string) string {
+	if n := im.typeName(s); n != "" {
+		if im.media[n] {
+			return strconv.Quote(mediaTypeIdentifier(n))
+		}
+		return strconv.Quote(n)
+	}
+	if s != nil && s.Ref != "" {
+		if im.expanding[s.Ref] {
+			return "Any"
+		}
+		im.expanding[s.Ref] = true
+		defer delete(im.expanding, s.Ref)
+	}
+	r := im.resolve(s)
+	if r == nil {
+		return "Any"
+	}
+	if im.isObject(r) {
+		return strconv.Quote(im.declare(base, r))
+	}
+	switch r.Type {
+	case "string":
+		switch r.Format {
+		case "date-time":
+			return "DateTime"
+		case "uuid":
+			return "UUID"
+		case "binary":
+			return "File"
+		}
+		return "String"
+	case "integer":
+		return "Integer"
+	case "number":
+		return "Number"
+	case "boolean":
+		return "Boolean"
+	case "file":
+		return "File"
+	case "array":
+		if r.Items == nil {
+			return "ArrayOf(Any)"
+		}
+		return fmt.Sprintf("ArrayOf(%s)", im.typeExpr(r.Items, base+"Item"))
+	case "object":
+		elem := "Any"
+		if len(r.AdditionalProperties) > 0 {
+			var as openAPISchema
+			if err := json.Unmarshal(r.AdditionalProperties, &as); err == nil {
+				if t := im.typeExpr(&as, base+"Value"); as.Type != "" || as.Ref != "" {
+					elem = t
+				}
+			}
+		}
+		return fmt.Sprintf("HashOf(String, %s)", elem)
+	}
+	return "Any"
+}
+
+// validations returns the DSL describing the validations, default value and example of s.
+func validations(s *openAPISchema) string {
+	if s == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if len(s.Enum) > 0 {
+		vals := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			vals[i] = literal(v)
+		}
+		fmt.Fprintf(&buf, "Enum(%s)\n", strings.Join(vals, ", "))
+	}
+	for _, f := range apidsl.SupportedValidationFormats {
+		if s.Format == f && f != apidsl.FormatDateTime && f != apidsl.FormatUUID {
+			fmt.Fprintf(&buf, "Format(%q)\n", f)
+		}
+	}
+	if s.Pattern != "" {
+		fmt.Fprintf(&buf, "Pattern(%q)\n", s.Pattern)
+	}
+	if s.Minimum != nil {
+		fmt.Fprintf(&buf, "Minimum(%s)\n", literal(*s.Minimum))
+	}
+	if s.Maximum != nil {
+		fmt.Fprintf(&buf, "Maximum(%s)\n", literal(*s.Maximum))
+	}
+	for _, l := range []*int{s.MinLength, s.MinItems} {
+		if l != nil {
+			fmt.Fprintf(&buf, "MinLength(%d)\n", *l)
+		}
+	}
+	for _, l := range []*int{s.MaxLength, s.MaxItems} {
+		if l != nil {
+			fmt.Fprintf(&buf, "MaxLength(%d)\n", *l)
+		}
+	}
+	if isScalar(s.Default) {
+		fmt.Fprintf(&buf, "Default(%s)\n", literal(s.Default))
+	}
+	if isScalar(s.Example) {
+		fmt.Fprintf(&buf, "Example(%s)\n", literal(s.Example))
+	}
+	return buf.String()
+}
+
+// isScalar returns true if v is a non nil string, number or boolean.
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+// literal returns the Go literal of the JSON scalar v.
+func literal(v interface{}) string {
+	switch actual := v.(type) {
+	case string:
+		return strconv.Quote(actual)
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// quoteAll returns the comma separated list of quoted strings.
+func quoteAll(vals []string) string {
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string]*openAPISchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,239 @@
+package gendesign_test
+
+import (
+	"github.com/goadesign/goa/goagen/gen_design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenAPI", func() {
+	var spec string
+	var src string
+	var importErr error
+
+	JustBeforeEach(func() {
+		var out []byte
+		out, importErr = gendesign.OpenAPI([]byte(spec), "design", "spec")
+		src = string(out)
+	})
+
+	Context("with an OpenAPI 3 document", func() {
+		BeforeEach(func() {
+			spec = openAPI3Spec
+		})
+
+		It("generates the API definition", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring("package design\n"))
+			Ω(src).Should(ContainSubstring(`var _ = API("petstore", func() {`))
+			Ω(src).Should(ContainSubstring(`Title("Petstore")`))
+			Ω(src).Should(ContainSubstring(`Host("petstore.goa.design")`))
+			Ω(src).Should(ContainSubstring(`Scheme("https")`))
+			Ω(src).Should(ContainSubstring(`BasePath("/v1")`))
+		})
+
+		It("generates the resources and actions", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`var _ = Resource("pets", func() {`))
+			Ω(src).Should(ContainSubstring(`Action("list_pets", func() {`))
+			Ω(src).Should(ContainSubstring(`Routing(GET("/pets"))`))
+			Ω(src).Should(ContainSubstring(`Param("limit", Integer, "Maximum number of pets", func() {`))
+			Ω(src).Should(ContainSubstring(`Response(OK, CollectionOf("application/vnd.pet"))`))
+			Ω(src).Should(ContainSubstring(`Action("show_pet_by_id", func() {`))
+			Ω(src).Should(ContainSubstring(`Routing(GET("/pets/:petId"))`))
+			Ω(src).Should(ContainSubstring(`Param("petId", UUID)`))
+			Ω(src).Should(ContainSubstring(`Response(NotFound)`))
+		})
+
+		It("generates the payloads", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Routing(POST("/pets"))`))
+			Ω(src).Should(ContainSubstring(`Payload(NewPetType)`))
+			Ω(src).Should(ContainSubstring(`Response("TooManyRequests", func() {`))
+			Ω(src).Should(ContainSubstring(`Status(429)`))
+		})
+
+		It("generates the types and media types", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`var NewPetType = Type("NewPet", func() {`))
+			Ω(src).Should(ContainSubstring(`var PetMedia = MediaType("application/vnd.pet", func() {`))
+			Ω(src).Should(ContainSubstring(`TypeName("Pet")`))
+			Ω(src).Should(ContainSubstring(`Attribute("id", UUID)`))
+			Ω(src).Should(ContainSubstring(`Enum("cat", "dog")`))
+			Ω(src).Should(ContainSubstring(`Required("name", "id")`))
+			Ω(src).Should(ContainSubstring(`View("default", func() {`))
+		})
+	})
+
+	Context("with a Swagger 2 document", func() {
+		BeforeEach(func() {
+			spec = swagger2Spec
+		})
+
+		It("generates the actions", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Consumes("application/json")`))
+			Ω(src).Should(ContainSubstring(`var _ = Resource("bottle", func() {`))
+			Ω(src).Should(ContainSubstring(`Action("show", func() {`))
+			Ω(src).Should(ContainSubstring(`Routing(GET("/bottles/:bottleID"))`))
+			Ω(src).Should(ContainSubstring(`Response(OK, "application/vnd.bottle")`))
+			Ω(src).Should(ContainSubstring(`Payload(CreateBottlePayloadType)`))
+		})
+
+		It("generates multipart form payloads", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring("MultipartForm()\n"))
+			Ω(src).Should(ContainSubstring(`Member("file", File)`))
+		})
+
+		It("avoids clashes with the DSL identifiers", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`var ImportedErrorMedia = MediaType("application/vnd.error", func() {`))
+			Ω(src).Should(ContainSubstring(`Media("application/vnd.error")`))
+		})
+	})
+
+	Context("with a document that is not an OpenAPI document", func() {
+		BeforeEach(func() {
+			spec = `{"foo": "bar"}`
+		})
+
+		It("returns an error", func() {
+			Ω(importErr).Should(HaveOccurred())
+		})
+	})
+})
+
+const openAPI3Spec = `
+openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://petstore.goa.design/v1
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          description: Maximum number of pets
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      tags: [pets]
+      operationId: createPets
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/NewPet"
+      responses:
+        "201":
+          description: Created
+        "429":
+          description: Too many requests
+  /pets/{petId}:
+    get:
+      tags: [pets]
+      operationId: showPetById
+      parameters:
+        - name: petId
+          in: path
+          required: true
+          schema:
+            type: string
+            format: uuid
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+        "404":
+          description: Not Found
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        tag:
+          type: string
+          enum: [cat, dog]
+    Pet:
+      allOf:
+        - $ref: "#/components/schemas/NewPet"
+        - type: object
+          required: [id]
+          properties:
+            id:
+              type: string
+              format: uuid
+`
+
+const swagger2Spec = `{
+  "swagger": "2.0",
+  "info": {"title": "Cellar", "version": "1.0"},
+  "host": "cellar.goa.design",
+  "consumes": ["application/json"],
+  "paths": {
+    "/bottles": {
+      "post": {
+        "tags": ["bottle"],
+        "operationId": "bottle#create",
+        "parameters": [{"name": "payload", "in": "body", "required": true, "schema": {"$ref": "#/definitions/CreateBottlePayload"}}],
+        "responses": {
+          "201": {"description": "Created"},
+          "400": {"description": "Bad Request", "schema": {"$ref": "#/definitions/error"}}
+        }
+      }
+    },
+    "/bottles/{bottleID}": {
+      "get": {
+        "tags": ["bottle"],
+        "operationId": "bottle#show",
+        "parameters": [{"name": "bottleID", "in": "path", "required": true, "type": "integer"}],
+        "responses": {"200": {"description": "OK", "schema": {"$ref": "#/definitions/Bottle"}}}
+      }
+    },
+    "/upload": {
+      "post": {
+        "consumes": ["multipart/form-data"],
+        "parameters": [{"name": "file", "in": "formData", "type": "file", "required": true}],
+        "responses": {"204": {"description": "No Content"}}
+      }
+    }
+  },
+  "definitions": {
+    "Bottle": {
+      "type": "object",
+      "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}
+    },
+    "CreateBottlePayload": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string"}}
+    },
+    "error": {
+      "type": "object",
+      "properties": {"detail": {"type": "string"}}
+    }
+  }
+}`
@@ -0,0 +1,25 @@
+package gendesign
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//Spec Path to the imported API description file
+func Spec(spec string) Option {
+	return func(g *Generator) {
+		g.Spec = spec
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Pkg Name of the generated design package
+func Pkg(pkg string) Option {
+	return func(g *Generator) {
+		g.Pkg = pkg
+	}
+}
@@ -0,0 +1,493 @@
+package gendesign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type (
+	// protoParser parses the message and enum definitions of a .proto file.
+	protoParser struct {
+		tokens []*protoToken
+		pos    int
+		// pkg is the proto package name.
+		pkg string
+		// defs contains the schemas describing the messages and enums indexed by full name.
+		defs map[string]*openAPISchema
+		// names lists the full names of the messages in order of definition.
+		names []string
+		// fields records the field types of each message to resolve them once all the
+		// definitions have been parsed.
+		fields []*protoField
+		// maps contains the schemas of the map values indexed by map field schema.
+		maps map[*openAPISchema]*openAPISchema
+	}
+
+	// protoToken is a token of a .proto file.
+	protoToken struct {
+		text string
+		// comment is the comment immediately preceding the token.
+		comment string
+		line    int
+	}
+
+	// protoField is a message field whose type has not been resolved yet.
+	protoField struct {
+		// scope is the full name of the message defining the field.
+		scope string
+		// typ is the proto type of the field, the type of the values for map fields.
+		typ string
+		// schema is the field schema, its type is set once resolved.
+		schema *openAPISchema
+	}
+)
+
+// protoScalars maps the proto scalar and well-known types to the corresponding schemas.
+var protoScalars = map[string]*openAPISchema{
+	"double":                      {Type: "number"},
+	"float":                       {Type: "number"},
+	"int32":                       {Type: "integer"},
+	"int64":                       {Type: "integer"},
+	"uint32":                      {Type: "integer"},
+	"uint64":                      {Type: "integer"},
+	"sint32":                      {Type: "integer"},
+	"sint64":                      {Type: "integer"},
+	"fixed32":                     {Type: "integer"},
+	"fixed64":                     {Type: "integer"},
+	"sfixed32":                    {Type: "integer"},
+	"sfixed64":                    {Type: "integer"},
+	"bool":                        {Type: "boolean"},
+	"string":                      {Type: "string"},
+	"bytes":                       {Type: "string"},
+	"google.protobuf.Timestamp":   {Type: "string", Format: "date-time"},
+	"google.protobuf.Duration":    {Type: "string"},
+	"google.protobuf.DoubleValue": {Type: "number"},
+	"google.protobuf.FloatValue":  {Type: "number"},
+	"google.protobuf.Int32Value":  {Type: "integer"},
+	"google.protobuf.Int64Value":  {Type: "integer"},
+	"google.protobuf.UInt32Value": {Type: "integer"},
+	"google.protobuf.UInt64Value": {Type: "integer"},
+	"google.protobuf.BoolValue":   {Type: "boolean"},
+	"google.protobuf.StringValue": {Type: "string"},
+	"google.protobuf.BytesValue":  {Type: "string"},
+	"google.protobuf.Struct":      {Type: "object"},
+	"google.protobuf.ListValue":   {Type: "array"},
+	"google.protobuf.Value":       {},
+	"google.protobuf.Any":         {},
+}
+
+// Proto returns the source code of a design package named pkg declaring a type for each message
+// defined in the given .proto file. Enums are described with Enum validations, repeated fields
+// with arrays, map fields with hashes and the fields of oneofs are flattened into the message.
+// Messages defined in imported files are described with Any. source is the name of the file
+// mentioned in the generated file header.
+func Proto(data []byte, pkg, source string) ([]byte, error) {
+	tokens, err := tokenizeProto(string(data))
+	if err != nil {
+		return nil, err
+	}
+	p := &protoParser{
+		tokens: tokens,
+		defs:   make(map[string]*openAPISchema),
+		maps:   make(map[*openAPISchema]*openAPISchema),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	p.resolve()
+
+	im := newOpenAPIImporter(&openAPIDoc{Definitions: p.defs, Components: &openAPIComponents{}})
+	for _, n := range p.names {
+		im.declare(protoTypeName(n), p.defs[n])
+	}
+	var buf bytes.Buffer
+	writeHeader(&buf, pkg, source)
+	return im.writeTypes(&buf)
+}
+
+// protoTypeName returns the name of the type declared for the message with the given full name.
+func protoTypeName(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// tokenizeProto splits the content of a .proto file into tokens recording the comments. Only
+// the comments on the lines immediately preceding a token are recorded, trailing comments are
+// ignored.
+func tokenizeProto(src string) ([]*protoToken, error) {
+	var (
+		tokens      []*protoToken
+		comments    []string
+		commentLine int // line of the end of the last comment
+		tokenLine   int // line of the last token
+		line        = 1
+		runes       = []rune(src)
+	)
+	comment := func(text string) {
+		if tokenLine == line {
+			return
+		}
+		if commentLine < line-1 {
+			comments = nil
+		}
+		comments = append(comments, text)
+	}
+	token := func(text string) {
+		t := &protoToken{text: text, line: line}
+		if len(comments) > 0 && commentLine >= line-1 {
+			t.comment = strings.Join(comments, " ")
+		}
+		tokens = append(tokens, t)
+		comments = nil
+		tokenLine = line
+	}
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			j := i
+			for j < len(runes) && runes[j] != '\n' {
+				j++
+			}
+			comment(strings.TrimSpace(string(runes[i+2 : j])))
+			commentLine = line
+			i = j
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start := line
+			j := i + 2
+			for j+1 < len(runes) && !(runes[j] == '*' && runes[j+1] == '/') {
+				if runes[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, fmt.Errorf("line %d: unterminated comment", start)
+			}
+			var lines []string
+			for _, l := range strings.Split(string(runes[i+2:j]), "\n") {
+				if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "*")); l != "" {
+					lines = append(lines, l)
+				}
+			}
+			end := line
+			line = start
+			comment(strings.Join(lines, " "))
+			line, commentLine = end, end
+			i = j + 2
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != c && runes[j] != '\n' {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) || runes[j] != c {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			token(string(runes[i : j+1]))
+			i = j + 1
+		case c == '_' || c == '.' || c == '-' || c == '+' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(runes) && (runes[j] == '_' || runes[j] == '.' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			token(string(runes[i:j]))
+			i = j
+		default:
+			token(string(c))
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// parse parses the top level definitions.
+func (p *protoParser) parse() error {
+	for !p.done() {
+		t := p.next()
+		switch t.text {
+		case "syntax", "import", "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "package":
+			name := p.next()
+			p.pkg = name.text
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "message":
+			if err := p.parseMessage("", t.comment); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum("", t.comment); err != nil {
+				return err
+			}
+		case "service", "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case ";":
+		default:
+			return fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+		}
+	}
+	return nil
+}
+
+// parseMessage parses the message definition following the "message" keyword. scope is the full
+// name of the enclosing message if any.
+func (p *protoParser) parseMessage(scope, comment string) error {
+	name := p.qualify(scope, p.next().text)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	s := &openAPISchema{Type: "object", Description: comment, Properties: make(map[string]*openAPISchema)}
+	p.defs[name] = s
+	p.names = append(p.names, name)
+	return p.parseFields(name, s, "}")
+}
+
+// parseFields parses the fields and nested definitions of the message with the given full name
+// until the closing token end.
+func (p *protoParser) parseFields(name string, s *openAPISchema, end string) error {
+	for {
+		if p.done() {
+			return fmt.Errorf("message %s: unexpected end of file", name)
+		}
+		t := p.next()
+		switch t.text {
+		case end:
+			return nil
+		case ";":
+		case "message":
+			if err := p.parseMessage(name, t.comment); err != nil {
+				return err
+			}
+		case "enum":
+			if err := p.parseEnum(name, t.comment); err != nil {
+				return err
+			}
+		case "oneof":
+			p.next()
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseFields(name, s, "}"); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions", "extend", "group":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "map":
+			if err := p.expect("<"); err != nil {
+				return err
+			}
+			p.next()
+			if err := p.expect(","); err != nil {
+				return err
+			}
+			elem := &openAPISchema{}
+			p.fields = append(p.fields, &protoField{scope: name, typ: p.next().text, schema: elem})
+			if err := p.expect(">"); err != nil {
+				return err
+			}
+			fs := &openAPISchema{Type: "object"}
+			p.maps[fs] = elem
+			if err := p.parseField(s, t.comment, fs); err != nil {
+				return err
+			}
+		default:
+			label, typ := "", t.text
+			if typ == "repeated" || typ == "optional" || typ == "required" {
+				label, typ = typ, p.next().text
+			}
+			fs := &openAPISchema{}
+			p.fields = append(p.fields, &protoField{scope: name, typ: typ, schema: fs})
+			if label == "repeated" {
+				fs = &openAPISchema{Type: "array", Items: fs}
+			}
+			if label == "required" {
+				s.Required = append(s.Required, p.peek().text)
+			}
+			if err := p.parseField(s, t.comment, fs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseField parses the name, number and options of a field described by fs and adds it to the
+// properties of s.
+func (p *protoParser) parseField(s *openAPISchema, comment string, fs *openAPISchema) error {
+	name := p.next()
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	p.next()
+	if err := p.skipStatement(); err != nil {
+		return err
+	}
+	fs.Description = comment
+	s.Properties[name.text] = fs
+	return nil
+}
+
+// parseEnum parses the enum definition following the "enum" keyword. scope is the full name of
+// the enclosing message if any.
+func (p *protoParser) parseEnum(scope, comment string) error {
+	name := p.qualify(scope, p.next().text)
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	s := &openAPISchema{Type: "string", Description: comment}
+	p.defs[name] = s
+	for {
+		if p.done() {
+			return fmt.Errorf("enum %s: unexpected end of file", name)
+		}
+		t := p.next()
+		switch t.text {
+		case "}":
+			return nil
+		case ";":
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			s.Enum = append(s.Enum, t.text)
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// resolve sets the types of the message fields now that all the definitions are known.
+func (p *protoParser) resolve() {
+	for _, f := range p.fields {
+		if s, ok := protoScalars[strings.TrimPrefix(f.typ, ".")]; ok {
+			desc := f.schema.Description
+			*f.schema = *s
+			f.schema.Description = desc
+			continue
+		}
+		if name := p.lookup(f.scope, f.typ); name != "" {
+			f.schema.Ref = "#/definitions/" + name
+		}
+	}
+	// Map fields are described with objects whose additional properties are the values.
+	for fs, elem := range p.maps {
+		fs.AdditionalProperties, _ = json.Marshal(elem)
+	}
+}
+
+// lookup returns the full name of the definition the type name refers to from the message with
+// the given full name following the proto scoping rules, the empty string if there is none.
+func (p *protoParser) lookup(scope, name string) string {
+	if strings.HasPrefix(name, ".") {
+		name = strings.TrimPrefix(name[1:], p.pkg+".")
+		if _, ok := p.defs[name]; ok {
+			return name
+		}
+		return ""
+	}
+	for {
+		candidate := p.qualify(scope, name)
+		if _, ok := p.defs[candidate]; ok {
+			return candidate
+		}
+		if scope == "" {
+			break
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+	if p.pkg != "" && strings.HasPrefix(name, p.pkg+".") {
+		return p.lookup("", name[len(p.pkg)+1:])
+	}
+	return ""
+}
+
+// qualify returns the full name of the definition with the given name in scope.
+func (p *protoParser) qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// skipStatement skips the tokens up to and including the next semicolon or block.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for !p.done() {
+		switch p.next().text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+// expect consumes the next token and returns an error if it is not text.
+func (p *protoParser) expect(text string) error {
+	if p.done() {
+		return fmt.Errorf("expected %q, got end of file", text)
+	}
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("line %d: expected %q, got %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+// done returns true if all the tokens have been consumed.
+func (p *protoParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// next consumes and returns the next token, it returns an empty token at the end of the file.
+func (p *protoParser) next() *protoToken {
+	t := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return t
+}
+
+// peek returns the next token without consuming it.
+func (p *protoParser) peek() *protoToken {
+	if p.done() {
+		return &protoToken{}
+	}
+	return p.tokens[p.pos]
+}
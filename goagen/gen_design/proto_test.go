@@ -0,0 +1,127 @@
+package gendesign_test
+
+import (
+	"github.com/goadesign/goa/goagen/gen_design"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Proto", func() {
+	var proto string
+	var src string
+	var importErr error
+
+	JustBeforeEach(func() {
+		var out []byte
+		out, importErr = gendesign.Proto([]byte(proto), "design", "spec.proto")
+		src = string(out)
+	})
+
+	Context("with messages", func() {
+		BeforeEach(func() {
+			proto = protoSpec
+		})
+
+		It("declares a type for each message", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring("package design\n"))
+			Ω(src).Should(ContainSubstring(`var BottleType = Type("Bottle", func() {`))
+			Ω(src).Should(ContainSubstring(`Description("Bottle describes a bottle of wine.")`))
+			Ω(src).Should(ContainSubstring(`var BottleReviewType = Type("BottleReview", func() {`))
+			Ω(src).Should(ContainSubstring(`var WineryType = Type("Winery", func() {`))
+			Ω(src).ShouldNot(ContainSubstring("BottleService"))
+		})
+
+		It("describes the fields", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Attribute("id", Integer, "Unique bottle ID")`))
+			Ω(src).Should(ContainSubstring(`Attribute("varietals", ArrayOf(String))`))
+			Ω(src).Should(ContainSubstring(`Attribute("ratings", HashOf(String, Integer))`))
+			Ω(src).Should(ContainSubstring(`Attribute("created_at", DateTime)`))
+			Ω(src).Should(ContainSubstring(`Attribute("reviews", ArrayOf("BottleReview"))`))
+			Ω(src).Should(ContainSubstring(`Attribute("winery", "Winery")`))
+			Ω(src).Should(ContainSubstring(`Attribute("parent", "Winery")`))
+			Ω(src).Should(ContainSubstring(`Attribute("other", Any)`))
+		})
+
+		It("describes enums with validations", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Enum("COLOR_UNSPECIFIED", "RED", "WHITE")`))
+			Ω(src).Should(ContainSubstring(`Enum("RATING_UNSPECIFIED", "GOOD", "GREAT")`))
+		})
+
+		It("flattens oneofs", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Attribute("vintage", Integer)`))
+			Ω(src).Should(ContainSubstring(`Attribute("non_vintage", Boolean)`))
+		})
+
+		It("records required fields", func() {
+			Ω(importErr).ShouldNot(HaveOccurred())
+			Ω(src).Should(ContainSubstring(`Required("name")`))
+		})
+	})
+
+	Context("with an invalid file", func() {
+		BeforeEach(func() {
+			proto = "message Bottle {\n  string name = 1;\n"
+		})
+
+		It("returns an error", func() {
+			Ω(importErr).Should(HaveOccurred())
+		})
+	})
+})
+
+const protoSpec = `
+syntax = "proto2";
+
+package cellar;
+
+import "google/protobuf/timestamp.proto";
+
+// Bottle describes a bottle of wine.
+message Bottle {
+  // Unique bottle ID
+  optional int64 id = 1;
+  required string name = 2 [default = "unnamed"];
+  optional Color color = 3;
+  repeated string varietals = 4;
+  map<string, int32> ratings = 5;
+  optional google.protobuf.Timestamp created_at = 6;
+  optional Winery winery = 7;
+  repeated Review reviews = 8;
+  oneof vintage_info {
+    int32 vintage = 9;
+    bool non_vintage = 10;
+  }
+  reserved 11, 12;
+
+  message Review {
+    optional string author = 1;
+    optional Rating rating = 2;
+    enum Rating {
+      RATING_UNSPECIFIED = 0;
+      GOOD = 1;
+      GREAT = 2;
+    }
+  }
+}
+
+enum Color {
+  COLOR_UNSPECIFIED = 0;
+  RED = 1;
+  WHITE = 2;
+}
+
+message Winery {
+  optional .cellar.Winery parent = 1;
+  optional other.Unknown other = 2;
+}
+
+service BottleService {
+  rpc Show(Bottle) returns (Bottle) {
+    option (google.api.http) = { get: "/bottles/{id}" };
+  }
+}
+`
@@ -0,0 +1,155 @@
+package gendiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BreakingChanges compares the API described by the base OpenAPI document with the API described
+// by the current document and returns the changes that break existing clients: removed
+// endpoints, parameters and request body attributes that became required, type changes, removed
+// response attributes and removed status codes. Both documents may be OpenAPI 2.0 or 3.0
+// documents encoded in JSON or YAML.
+func BreakingChanges(base, current []byte) ([]string, error) {
+	old, err := parseContract(base)
+	if err != nil {
+		return nil, fmt.Errorf("base document: %s", err)
+	}
+	cur, err := parseContract(current)
+	if err != nil {
+		return nil, fmt.Errorf("current document: %s", err)
+	}
+	var changes []string
+	for _, key := range sortedKeys(old.endpoints) {
+		oe := old.endpoints[key]
+		ne, ok := cur.endpoints[key]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s %s: endpoint removed", oe.verb, oe.path))
+			continue
+		}
+		var cs []string
+		report := func(format string, args ...interface{}) {
+			cs = append(cs, fmt.Sprintf(format, args...))
+		}
+		compareParams(oe, ne, report)
+		switch {
+		case ne.body != nil && ne.bodyRequired && (oe.body == nil || !oe.bodyRequired):
+			report("request body is now required")
+		case oe.body != nil && ne.body != nil:
+			c := &comparison{report: report, request: true, seen: make(map[[2]*schema]bool)}
+			c.compare("request body", oe.body, ne.body)
+		}
+		var codes []string
+		for code := range oe.responses {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			ns, ok := ne.responses[code]
+			if !ok {
+				report("response %s removed", code)
+				continue
+			}
+			c := &comparison{report: report, seen: make(map[[2]*schema]bool)}
+			c.compare("response "+code+" body", oe.responses[code], ns)
+		}
+		for _, c := range cs {
+			changes = append(changes, fmt.Sprintf("%s %s: %s", ne.verb, ne.path, c))
+		}
+	}
+	return changes, nil
+}
+
+// compareParams reports the parameters of ne that became required or whose type changed.
+func compareParams(oe, ne *endpoint, report func(string, ...interface{})) {
+	for _, key := range sortedKeys(ne.params) {
+		np := ne.params[key]
+		if np.in == "path" {
+			continue
+		}
+		op, ok := oe.params[key]
+		switch {
+		case !ok && np.required:
+			report("new required %s parameter %s", np.in, np.name)
+		case ok && np.required && !op.required:
+			report("%s parameter %s is now required", np.in, np.name)
+		}
+		if ok && op.schema != nil && np.schema != nil && op.schema.typ != np.schema.typ {
+			report("%s parameter %s type changed from %s to %s", np.in, np.name, op.schema.typ, np.schema.typ)
+		}
+	}
+}
+
+// comparison compares the schemas of a request or response body.
+type comparison struct {
+	report  func(string, ...interface{})
+	request bool
+	// seen records the pairs of schemas already compared to handle recursive schemas.
+	seen map[[2]*schema]bool
+}
+
+// compare reports the breaking changes between the old and new schemas of the given location.
+func (c *comparison) compare(loc string, o, n *schema) {
+	if o == nil || n == nil || c.seen[[2]*schema{o, n}] {
+		return
+	}
+	c.seen[[2]*schema{o, n}] = true
+	if o.typ != "" && n.typ != "" && o.typ != n.typ {
+		c.report("%s type changed from %s to %s", loc, o.typ, n.typ)
+		return
+	}
+	if c.request {
+		for _, name := range sortedKeys(n.required) {
+			if !o.required[name] {
+				c.report("%s attribute %s is now required", loc, name)
+			}
+		}
+	} else {
+		for _, name := range sortedKeys(o.props) {
+			if _, ok := n.props[name]; !ok {
+				c.report("%s attribute %s removed", loc, name)
+			}
+		}
+	}
+	for _, name := range sortedKeys(o.props) {
+		if ns, ok := n.props[name]; ok {
+			c.compare(loc+" attribute "+name, o.props[name], ns)
+		}
+	}
+	c.compare(loc+" items", o.items, n.items)
+	c.compare(loc+" values", o.additional, n.additional)
+}
+
+// sortedKeys returns the sorted keys of the given map.
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*endpoint:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*param:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*schema:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]bool:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Report returns the description of the given breaking changes.
+func Report(changes []string) string {
+	if len(changes) == 0 {
+		return "no breaking change"
+	}
+	return fmt.Sprintf("%d breaking change(s):\n%s", len(changes), strings.Join(changes, "\n"))
+}
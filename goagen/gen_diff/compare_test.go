@@ -0,0 +1,209 @@
+package gendiff_test
+
+import (
+	"github.com/goadesign/goa/goagen/gen_diff"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BreakingChanges", func() {
+	const base = `{
+  "swagger": "2.0",
+  "basePath": "/api",
+  "paths": {
+    "/bottles/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "view", "in": "query", "type": "string"}
+        ],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Bottle"}},
+          "404": {"description": "Not found"}
+        }
+      },
+      "delete": {
+        "parameters": [{"name": "id", "in": "path", "required": true, "type": "integer"}],
+        "responses": {"204": {"description": "No content"}}
+      }
+    },
+    "/bottles": {
+      "post": {
+        "parameters": [
+          {"name": "payload", "in": "body", "required": true, "schema": {"$ref": "#/definitions/BottlePayload"}}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "definitions": {
+    "Bottle": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "integer", "format": "int64"},
+        "name": {"type": "string"},
+        "vintage": {"type": "integer"},
+        "parent": {"$ref": "#/definitions/Bottle"}
+      },
+      "required": ["id"]
+    },
+    "BottlePayload": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "vintage": {"type": "integer"}
+      },
+      "required": ["name"]
+    }
+  }
+}`
+
+	var current string
+	var changes []string
+	var err error
+
+	JustBeforeEach(func() {
+		changes, err = gendiff.BreakingChanges([]byte(base), []byte(current))
+	})
+
+	Context("with the same API described in OpenAPI 3.0", func() {
+		BeforeEach(func() {
+			current = `
+openapi: 3.0.0
+servers:
+  - url: https://example.com/api
+paths:
+  /bottles/{bottleID}:
+    get:
+      parameters:
+        - {name: bottleID, in: path, required: true, schema: {type: integer}}
+        - {name: view, in: query, schema: {type: string}}
+        - {name: fields, in: query, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Bottle"}
+        "404": {description: Not found}
+    delete:
+      parameters:
+        - {name: bottleID, in: path, required: true, schema: {type: integer}}
+      responses:
+        "204": {description: No content}
+  /bottles:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+                vintage: {type: integer}
+                color: {type: string}
+              required: [name]
+      responses:
+        "201": {description: Created}
+components:
+  schemas:
+    Bottle:
+      allOf:
+        - {$ref: "#/components/schemas/BottleBase"}
+        - type: object
+          properties:
+            parent: {$ref: "#/components/schemas/Bottle"}
+            rating: {type: number}
+    BottleBase:
+      type: object
+      properties:
+        id: {type: integer, format: int64}
+        name: {type: string}
+        vintage: {type: integer}
+      required: [id]
+`
+		})
+
+		It("reports no breaking change", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(changes).Should(BeEmpty())
+			Ω(gendiff.Report(changes)).Should(Equal("no breaking change"))
+		})
+	})
+
+	Context("with breaking changes", func() {
+		BeforeEach(func() {
+			current = `{
+  "swagger": "2.0",
+  "basePath": "/api",
+  "paths": {
+    "/bottles/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "type": "integer"},
+          {"name": "view", "in": "query", "required": true, "type": "integer"},
+          {"name": "X-Account", "in": "header", "required": true, "type": "string"}
+        ],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Bottle"}},
+          "410": {"description": "Gone"}
+        }
+      }
+    },
+    "/bottles": {
+      "post": {
+        "parameters": [
+          {"name": "payload", "in": "body", "required": true, "schema": {"$ref": "#/definitions/BottlePayload"}}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "definitions": {
+    "Bottle": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "string"},
+        "vintage": {"type": "integer"},
+        "parent": {"$ref": "#/definitions/Bottle"}
+      }
+    },
+    "BottlePayload": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "vintage": {"type": "integer"}
+      },
+      "required": ["name", "vintage"]
+    }
+  }
+}`
+		})
+
+		It("reports the breaking changes", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(changes).Should(Equal([]string{
+				"DELETE /api/bottles/{id}: endpoint removed",
+				"GET /api/bottles/{id}: new required header parameter X-Account",
+				"GET /api/bottles/{id}: query parameter view is now required",
+				"GET /api/bottles/{id}: query parameter view type changed from string to integer",
+				"GET /api/bottles/{id}: response 200 body attribute name removed",
+				"GET /api/bottles/{id}: response 200 body attribute id type changed from integer/int64 to string",
+				"GET /api/bottles/{id}: response 404 removed",
+				"POST /api/bottles: request body attribute vintage is now required",
+			}))
+			Ω(gendiff.Report(changes)).Should(HavePrefix("8 breaking change(s):\n"))
+		})
+	})
+
+	Context("with an invalid document", func() {
+		BeforeEach(func() {
+			current = `{"info": {}}`
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(MatchError("current document: not an OpenAPI 2.0 or 3.0 document"))
+		})
+	})
+})
@@ -0,0 +1,302 @@
+package gendiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/goagen/gen_design"
+)
+
+type (
+	// contract is the part of an API described by an OpenAPI document that clients depend on.
+	contract struct {
+		// endpoints indexed by verb and path with the path parameters names removed.
+		endpoints map[string]*endpoint
+	}
+
+	// endpoint describes an API operation.
+	endpoint struct {
+		verb string
+		path string
+		// params indexed by location and name, e.g. "query limit".
+		params map[string]*param
+		// body is the request body schema if any.
+		body *schema
+		// bodyRequired is true if the request body is required.
+		bodyRequired bool
+		// responses indexed by status code, the schema is nil for responses without body.
+		responses map[string]*schema
+	}
+
+	// param describes an operation parameter.
+	param struct {
+		name     string
+		in       string
+		required bool
+		schema   *schema
+	}
+
+	// schema describes the structure of a body or parameter.
+	schema struct {
+		// typ is the schema type and format if any, e.g. "integer/int64".
+		typ        string
+		props      map[string]*schema
+		required   map[string]bool
+		items      *schema
+		additional *schema
+	}
+
+	// parser builds the contract of an OpenAPI 2.0 or 3.0 document.
+	parser struct {
+		doc     map[string]interface{}
+		v3      bool
+		schemas map[string]*schema
+	}
+)
+
+// pathParamRegex matches the path parameters of OpenAPI paths.
+var pathParamRegex = regexp.MustCompile(`{[^}]*}`)
+
+// verbs lists the operation keys of OpenAPI path items.
+var verbs = []string{"get", "put", "post", "patch", "delete", "head", "options", "trace"}
+
+// parseContract returns the contract described by the given JSON or YAML OpenAPI 2.0 or 3.0
+// document.
+func parseContract(data []byte) (*contract, error) {
+	js := bytes.TrimSpace(data)
+	if len(js) == 0 || js[0] != '{' {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		var err error
+		if js, err = json.Marshal(gendesign.JSONCompatible(v)); err != nil {
+			return nil, err
+		}
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(js, &doc); err != nil {
+		return nil, err
+	}
+	p := &parser{doc: doc, schemas: make(map[string]*schema)}
+	switch {
+	case strings.HasPrefix(str(doc["swagger"]), "2."):
+	case strings.HasPrefix(str(doc["openapi"]), "3."):
+		p.v3 = true
+	default:
+		return nil, fmt.Errorf("not an OpenAPI 2.0 or 3.0 document")
+	}
+	return p.contract(), nil
+}
+
+// contract builds the contract of the document.
+func (p *parser) contract() *contract {
+	base := str(p.doc["basePath"])
+	if p.v3 {
+		base = ""
+		if servers := list(p.doc["servers"]); len(servers) > 0 {
+			if u, err := url.Parse(str(obj(servers[0])["url"])); err == nil {
+				base = u.Path
+			}
+		}
+	}
+	c := &contract{endpoints: make(map[string]*endpoint)}
+	for pth, item := range obj(p.doc["paths"]) {
+		item := obj(item)
+		full := path.Join("/", base, pth)
+		for _, verb := range verbs {
+			op := obj(item[verb])
+			if op == nil {
+				continue
+			}
+			e := &endpoint{
+				verb:      strings.ToUpper(verb),
+				path:      full,
+				params:    make(map[string]*param),
+				responses: make(map[string]*schema),
+			}
+			for _, raw := range append(list(item["parameters"]), list(op["parameters"])...) {
+				p.param(e, p.ref(obj(raw), "#/parameters/", "#/components/parameters/"))
+			}
+			if rb := p.ref(obj(op["requestBody"]), "#/components/requestBodies/"); rb != nil {
+				e.body = p.content(rb)
+				e.bodyRequired, _ = rb["required"].(bool)
+			}
+			for code, raw := range obj(op["responses"]) {
+				r := p.ref(obj(raw), "#/responses/", "#/components/responses/")
+				if p.v3 {
+					e.responses[code] = p.content(r)
+				} else {
+					e.responses[code] = p.schema(obj(r["schema"]))
+				}
+			}
+			c.endpoints[e.key()] = e
+		}
+	}
+	return c
+}
+
+// param adds the parameter described by the given parameter object to e.
+func (p *parser) param(e *endpoint, raw map[string]interface{}) {
+	if raw == nil {
+		return
+	}
+	in := str(raw["in"])
+	required, _ := raw["required"].(bool)
+	if in == "body" {
+		e.body = p.schema(obj(raw["schema"]))
+		e.bodyRequired = required
+		return
+	}
+	s := raw
+	if p.v3 {
+		s = obj(raw["schema"])
+	}
+	name := str(raw["name"])
+	e.params[in+" "+name] = &param{name: name, in: in, required: required, schema: p.schema(s)}
+}
+
+// content returns the schema of the first media type of the given OpenAPI 3.0 request body or
+// response content.
+func (p *parser) content(raw map[string]interface{}) *schema {
+	content := obj(raw["content"])
+	var mts []string
+	for mt := range content {
+		mts = append(mts, mt)
+	}
+	if len(mts) == 0 {
+		return nil
+	}
+	sort.Strings(mts)
+	return p.schema(obj(obj(content[mts[0]])["schema"]))
+}
+
+// ref returns the object the given object refers to if it is a reference.
+func (p *parser) ref(raw map[string]interface{}, prefixes ...string) map[string]interface{} {
+	for i := 0; raw != nil && i < 10; i++ {
+		ref := str(raw["$ref"])
+		if ref == "" {
+			return raw
+		}
+		raw = p.lookup(ref, prefixes...)
+	}
+	return raw
+}
+
+// lookup returns the object with the given local reference.
+func (p *parser) lookup(ref string, prefixes ...string) map[string]interface{} {
+	for _, prefix := range prefixes {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		name := strings.Replace(strings.Replace(ref[len(prefix):], "~1", "/", -1), "~0", "~", -1)
+		var container interface{} = p.doc
+		for _, elem := range strings.Split(strings.Trim(prefix, "#/"), "/") {
+			container = obj(container)[elem]
+		}
+		return obj(obj(container)[name])
+	}
+	return nil
+}
+
+// schema returns the schema described by the given schema object.
+func (p *parser) schema(raw map[string]interface{}) *schema {
+	if raw == nil {
+		return nil
+	}
+	if ref := str(raw["$ref"]); ref != "" {
+		if s, ok := p.schemas[ref]; ok {
+			return s
+		}
+		// Record the schema before building it to support recursive schemas.
+		s := &schema{}
+		p.schemas[ref] = s
+		if target := p.lookup(ref, "#/definitions/", "#/components/schemas/"); target != nil {
+			*s = *p.schema(target)
+		}
+		return s
+	}
+	s := &schema{typ: str(raw["type"])}
+	if f := str(raw["format"]); f != "" {
+		s.typ += "/" + f
+	}
+	if items := obj(raw["items"]); items != nil {
+		s.items = p.schema(items)
+	}
+	if add := obj(raw["additionalProperties"]); add != nil {
+		s.additional = p.schema(add)
+	}
+	p.properties(s, raw)
+	for _, sub := range list(raw["allOf"]) {
+		if sub := p.schema(obj(sub)); sub != nil {
+			if s.typ == "" {
+				s.typ = sub.typ
+			}
+			for n, ps := range sub.props {
+				s.setProp(n, ps)
+			}
+			for n := range sub.required {
+				s.setRequired(n)
+			}
+		}
+	}
+	return s
+}
+
+// properties sets the properties and required properties of s from the given schema object.
+func (p *parser) properties(s *schema, raw map[string]interface{}) {
+	for n, ps := range obj(raw["properties"]) {
+		s.setProp(n, p.schema(obj(ps)))
+	}
+	for _, n := range list(raw["required"]) {
+		s.setRequired(str(n))
+	}
+}
+
+// setProp sets the schema of the given property.
+func (s *schema) setProp(name string, ps *schema) {
+	if s.props == nil {
+		s.props = make(map[string]*schema)
+	}
+	s.props[name] = ps
+}
+
+// setRequired marks the given property as required.
+func (s *schema) setRequired(name string) {
+	if s.required == nil {
+		s.required = make(map[string]bool)
+	}
+	s.required[name] = true
+}
+
+// key returns the key of the endpoint in the contract: the verb followed by the path with the
+// names of the path parameters removed.
+func (e *endpoint) key() string {
+	return e.verb + " " + pathParamRegex.ReplaceAllString(e.path, "{}")
+}
+
+// obj returns v if it is a JSON object, nil otherwise.
+func obj(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// list returns v if it is a JSON array, nil otherwise.
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+// str returns v if it is a JSON string, the empty string otherwise.
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,24 @@
+/*
+Package gendiff implements the goagen diff command which reports the changes made to the design
+that break existing clients.
+
+The design is compared with an OpenAPI 2.0 or 3.0 document (JSON or YAML) describing the base
+version of the API given with the --base flag. The document may have been produced by the goagen
+swagger command from a previous version of the design, for example:
+
+	git worktree add /tmp/base v1.2.0
+	(cd /tmp/base && goagen swagger -d github.com/foo/bar/design -o /tmp/base)
+	goagen diff -d github.com/foo/bar/design --base /tmp/base/swagger/swagger.json
+
+The following changes are reported as breaking:
+
+  - removed endpoints
+  - new required parameters and parameters that became required
+  - parameters, request and response attributes whose type changed
+  - request bodies and request body attributes that became required
+  - removed response attributes
+  - removed response status codes
+
+The command fails if there is any breaking change making it suitable for gating changes in CI.
+*/
+package gendiff
@@ -0,0 +1,84 @@
+package gendiff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+)
+
+//NewGenerator returns an initialized instance of a breaking change detector
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the breaking change detector, it does not generate files.
+type Generator struct {
+	API  *design.APIDefinition // The API definition
+	Base string                // Path to the OpenAPI document describing the base version of the API
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var base, ver string
+	set := flag.NewFlagSet("diff", flag.PanicOnError)
+	set.String("out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&base, "base", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{API: design.Design, Base: base}
+
+	return g.Generate()
+}
+
+// Generate compares the design with the base OpenAPI document. It returns an error listing the
+// breaking changes if there are any.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	if g.Base == "" {
+		return nil, fmt.Errorf("missing base OpenAPI document, use --base")
+	}
+	base, err := ioutil.ReadFile(g.Base)
+	if err != nil {
+		return nil, err
+	}
+	s, err := genswagger.New(g.API)
+	if err != nil {
+		return nil, err
+	}
+	current, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := BreakingChanges(base, current)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		return nil, fmt.Errorf("%s", Report(changes))
+	}
+	fmt.Fprintln(os.Stderr, Report(changes))
+	return nil, nil
+}
+
+// Cleanup does nothing as the detector does not generate files.
+func (g *Generator) Cleanup() {}
@@ -0,0 +1,107 @@
+package gendiff_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_diff"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var dir, base string
+	var genErr error
+
+	// define defines the design, v2 removes the delete action and adds a required attribute
+	// to the create payload.
+	define := func(v2 bool) {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		API("test", func() {})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Member("name", String)
+					Member("vintage", Integer)
+					Required("name")
+					if v2 {
+						Required("vintage")
+					}
+				})
+				Response(Created)
+			})
+			if !v2 {
+				Action("delete", func() {
+					Routing(DELETE("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(NoContent)
+				})
+			}
+		})
+		Ω(dslengine.Run()).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "gendiff")
+		Ω(err).ShouldNot(HaveOccurred())
+		define(false)
+		s, err := genswagger.New(Design)
+		Ω(err).ShouldNot(HaveOccurred())
+		b, err := json.Marshal(s)
+		Ω(err).ShouldNot(HaveOccurred())
+		base = filepath.Join(dir, "swagger.json")
+		Ω(ioutil.WriteFile(base, b, 0644)).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	Context("with an unchanged design", func() {
+		JustBeforeEach(func() {
+			define(false)
+			_, genErr = gendiff.NewGenerator(gendiff.API(Design), gendiff.Base(base)).Generate()
+		})
+
+		It("succeeds", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with breaking changes", func() {
+		JustBeforeEach(func() {
+			define(true)
+			_, genErr = gendiff.NewGenerator(gendiff.API(Design), gendiff.Base(base)).Generate()
+		})
+
+		It("fails and lists the breaking changes", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("2 breaking change(s)"))
+			Ω(genErr.Error()).Should(ContainSubstring("DELETE /bottles/{id}: endpoint removed"))
+			Ω(genErr.Error()).Should(ContainSubstring("POST /bottles: request body attribute vintage is now required"))
+		})
+	})
+
+	Context("without base document", func() {
+		JustBeforeEach(func() {
+			_, genErr = gendiff.NewGenerator(gendiff.API(Design)).Generate()
+		})
+
+		It("returns an error", func() {
+			Ω(genErr).Should(MatchError("missing base OpenAPI document, use --base"))
+		})
+	})
+})
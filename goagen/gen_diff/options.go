@@ -0,0 +1,20 @@
+package gendiff
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//Base Path to the OpenAPI document describing the base version of the API
+func Base(base string) Option {
+	return func(g *Generator) {
+		g.Base = base
+	}
+}
@@ -0,0 +1,16 @@
+/*
+Package genexample generates a buildable example implementation of the API: a main.go file and
+one controller file per resource whose actions return dummy responses. The files are generated in
+the "example" sub-directory of the output directory, a main package of its own that does not
+collide with the files generated by "goagen main".
+
+The generator only creates the files that don't exist yet so that it can be run again safely as
+resources are added to the design. Each controller file registers the function that mounts the
+controller in an init function, main.go mounts all the registered controllers so that the
+controllers of new resources are mounted without changes to the existing files.
+
+With the --diff flag the generator does not write any file, instead it prints the files that
+would be created, the functions that are missing from the existing files, the functions whose
+signature changed and the unified diff between the existing and generated files.
+*/
+package genexample
@@ -0,0 +1,13 @@
+package genexample_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenExample(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenExample Suite")
+}
@@ -0,0 +1,347 @@
+package genexample
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_main"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of an example implementation generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Dir is the name of the directory relative to the output directory where the example is
+// generated. The example is a package of its own so that it does not collide with the main
+// package generated with "goagen main".
+const Dir = "example"
+
+// Generator is the example implementation generator.
+type Generator struct {
+	API       *design.APIDefinition // The API definition
+	OutDir    string                // Path to output directory
+	DesignPkg string                // Path to design package, only used to mark generated files.
+	AppPkg    string                // Import path of generated "app" package, may be relative to OutDir
+	Diff      bool                  // Whether to print the differences with the existing files instead of generating
+	genfiles  []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, designPkg, appPkg, ver string
+		diff                           bool
+	)
+
+	set := flag.NewFlagSet("example", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&designPkg, "design", "", "")
+	set.StringVar(&appPkg, "app-pkg", "app", "")
+	set.StringVar(&ver, "version", "", "")
+	set.BoolVar(&diff, "diff", false, "")
+	set.Bool("notest", false, "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Diff: diff, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the main.go file and the controller files that do not exist yet in the
+// example directory.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	if g.AppPkg == "" {
+		g.AppPkg = "app"
+	}
+	elems := strings.Split(g.AppPkg, "/")
+	pkgName := elems[len(elems)-1]
+	codegen.Reserved[pkgName] = true
+
+	exampleDir := filepath.Join(g.OutDir, Dir)
+	if err = os.MkdirAll(exampleDir, 0755); err != nil {
+		return nil, err
+	}
+	appPkg, err := g.appImportPath()
+	if err != nil {
+		return nil, err
+	}
+	if g.Diff {
+		return nil, g.diff(os.Stderr, appPkg, pkgName)
+	}
+
+	mainFile := filepath.Join(exampleDir, "main.go")
+	if _, e := os.Stat(mainFile); e != nil {
+		g.genfiles = append(g.genfiles, mainFile)
+		if err = createMainFile(g.API, mainFile, appPkg, pkgName); err != nil {
+			return nil, err
+		}
+	}
+
+	ctrlAppPkg := g.controllerAppPkg()
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		filename, err := genmain.GenerateController(false, false, ctrlAppPkg, exampleDir, "main", r.Name, r)
+		if err != nil || filename == "" {
+			return err
+		}
+		g.genfiles = append(g.genfiles, filename)
+		return registerController(filename, pkgName, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// diff renders the example files in a temporary directory and writes the differences with the
+// files of the example directory to w: the new files, the functions missing from the existing
+// files, the functions whose signature changed and the unified diff of the files.
+func (g *Generator) diff(w io.Writer, appPkg, pkgName string) error {
+	tmpDir, err := ioutil.TempDir(g.OutDir, ".goagen-diff")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := createMainFile(g.API, filepath.Join(tmpDir, "main.go"), appPkg, pkgName); err != nil {
+		return err
+	}
+	// The temporary directory is a sibling of the example directory so that the relative "app"
+	// package path is the same.
+	ctrlAppPkg := g.controllerAppPkg()
+	var names []string
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		filename, err := genmain.GenerateController(true, false, ctrlAppPkg, tmpDir, "main", r.Name, r)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.Base(filename))
+		return registerController(filename, pkgName, r)
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{"main.go"}, names...) {
+		generated, err := ioutil.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil {
+			return err
+		}
+		existing, err := ioutil.ReadFile(filepath.Join(g.OutDir, Dir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Fprintf(w, "new file %s\n", name)
+				continue
+			}
+			return err
+		}
+		d := codegen.Diff(name, existing, generated)
+		if d == "" {
+			continue
+		}
+		added, changed, err := codegen.DiffDecls(existing, generated)
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		for _, a := range added {
+			fmt.Fprintf(w, "%s: new %s\n", name, a)
+		}
+		for _, c := range changed {
+			fmt.Fprintf(w, "%s: changed %s\n", name, c)
+		}
+		fmt.Fprint(w, d)
+	}
+	return nil
+}
+
+// createMainFile generates the main.go file that mounts the registered controllers.
+func createMainFile(api *design.APIDefinition, mainFile, appPkg, pkgName string) (err error) {
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(mainFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = file.FormatCode()
+		}
+		file.Close()
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.SimpleImport(appPkg),
+	}
+	if err = file.WriteHeader("", "main", imports); err != nil {
+		return err
+	}
+	tls := api.TLS != nil
+	for _, scheme := range api.Schemes {
+		if scheme == "https" {
+			tls = true
+		}
+	}
+	funcs := template.FuncMap{
+		"targetPkg": func() string { return pkgName },
+		"getPort": func(hostport string) string {
+			_, port, err := net.SplitHostPort(hostport)
+			if err != nil {
+				return "8080"
+			}
+			return port
+		},
+	}
+	data := map[string]interface{}{
+		"Name": api.Name,
+		"API":  api,
+		"TLS":  tls,
+	}
+	err = file.ExecuteTemplate("main", mainT, funcs, data)
+	return
+}
+
+// registerController appends the init function registering the function that mounts the
+// controller of the given resource to the controller file.
+func registerController(filename, pkgName string, r *design.ResourceDefinition) (err error) {
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == nil {
+			err = file.FormatCode()
+		}
+		file.Close()
+	}()
+	funcs := template.FuncMap{"targetPkg": func() string { return pkgName }}
+	return file.ExecuteTemplate("register", registerT, funcs, r)
+}
+
+// controllerAppPkg returns the "app" package path given to genmain.GenerateController, the path
+// is adjusted if it is relative to the output directory as the controllers are generated in a
+// sub-directory.
+func (g *Generator) controllerAppPkg() string {
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err == nil {
+		return g.AppPkg
+	}
+	return path.Join("..", g.AppPkg)
+}
+
+// appImportPath returns the import path of the "app" package.
+func (g *Generator) appImportPath() (string, error) {
+	if _, err := codegen.PackageSourcePath(g.AppPkg); err == nil {
+		return g.AppPkg, nil
+	}
+	outPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(filepath.ToSlash(outPkg), g.AppPkg), nil
+}
+
+const mainT = `
+// mounts lists the functions that mount the controllers on the service, each controller file
+// registers its function in an init function.
+var mounts []func(service *goa.Service)
+
+func main() {
+	var (
+		addr            = flag.String("addr", ":{{ getPort .API.Host }}", "Listen address")
+		shutdownTimeout = flag.Duration("shutdown-timeout", 30*time.Second, "Maximum duration of the graceful shutdown")
+	)
+	flag.Parse()
+
+	// Create service
+	service := goa.New({{ printf "%q" .Name }})
+
+	// Mount middleware
+	service.Use(middleware.RequestID())
+	service.Use(middleware.LogRequest(true))
+	service.Use(middleware.ErrorHandler(service, true))
+	service.Use(middleware.Recover())
+
+	// Mount controllers
+	for _, mount := range mounts {
+		mount(service)
+	}
+{{- if .API.HealthCheck }}
+
+	// Mount the health check probes
+	{{ targetPkg }}.MountHealthCheck(service)
+{{- end }}
+{{- if .API.Docs }}{{ if .API.Docs.OpenAPIPath }}
+
+	// Serve the OpenAPI document
+	{{ targetPkg }}.MountDocs(service)
+{{- end }}{{ end }}
+
+	// Start service, shut down gracefully on SIGINT or SIGTERM.
+	opts := []goa.RunOption{
+{{- if .API.TLS }}
+{{- range .API.TLS.Certificates }}
+		goa.TLSFiles({{ printf "%q" .CertFile }}, {{ printf "%q" .KeyFile }}),
+{{- end }}
+{{- if .API.TLS.ClientCA }}
+		goa.ClientCAFile({{ printf "%q" .API.TLS.ClientCA }}),
+{{- end }}
+{{- else if .TLS }}
+		goa.TLSFiles("cert.pem", "key.pem"),
+{{- end }}
+		goa.ShutdownTimeout(*shutdownTimeout),
+	}
+	if err := service.Run(*addr, opts...); err != nil {
+		service.LogError("startup", "err", err)
+	}
+}
+`
+
+const registerT = `{{ $name := goify .Name true }}
+func init() {
+	mounts = append(mounts, func(service *goa.Service) {
+		{{ targetPkg }}.Mount{{ $name }}Controller(service, New{{ $name }}Controller(service))
+	})
+}
+`
@@ -0,0 +1,147 @@
+package genexample_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_example"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir, exampleDir string
+	var files []string
+	var genErr error
+
+	newResource := func(name string) *design.ResourceDefinition {
+		res := &design.ResourceDefinition{
+			Name: name,
+			Actions: map[string]*design.ActionDefinition{
+				"show": {
+					Name:   "show",
+					Routes: []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+				},
+			},
+		}
+		show := res.Actions["show"]
+		show.Parent = res
+		show.Routes[0].Parent = show
+		return res
+	}
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		exampleDir = filepath.Join(outDir, genexample.Dir)
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		design.Design = &design.APIDefinition{
+			Name:      "testapi",
+			Resources: map[string]*design.ResourceDefinition{"foo": newResource("foo")},
+		}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genexample.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates main and the controllers in the example directory", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(ConsistOf(filepath.Join(exampleDir, "main.go"), filepath.Join(exampleDir, "foo.go")))
+		main, err := ioutil.ReadFile(filepath.Join(exampleDir, "main.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(main)).Should(ContainSubstring("var mounts []func(service *goa.Service)"))
+		Ω(string(main)).Should(ContainSubstring("for _, mount := range mounts {"))
+		ctrl, err := ioutil.ReadFile(filepath.Join(exampleDir, "foo.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(ctrl)).Should(ContainSubstring("func (c *FooController) Show(ctx *app.ShowFooContext) error {"))
+		Ω(string(ctrl)).Should(ContainSubstring(`"` + filepath.Base(outDir) + `/app"`))
+		Ω(string(ctrl)).Should(ContainSubstring("app.MountFooController(service, NewFooController(service))"))
+	})
+
+	Context("run again after adding a resource", func() {
+		var main []byte
+
+		JustBeforeEach(func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			var err error
+			main, err = ioutil.ReadFile(filepath.Join(exampleDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(filepath.Join(exampleDir, "foo.go"), []byte("package main\n"), 0644)).Should(Succeed())
+			design.Design.Resources["bar"] = newResource("bar")
+			files, genErr = genexample.Generate()
+		})
+
+		It("only creates the missing files", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(Equal([]string{filepath.Join(exampleDir, "bar.go")}))
+			content, err := ioutil.ReadFile(filepath.Join(exampleDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(Equal(main))
+			content, err = ioutil.ReadFile(filepath.Join(exampleDir, "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("package main\n"))
+			content, err = ioutil.ReadFile(filepath.Join(exampleDir, "bar.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("app.MountBarController(service, NewBarController(service))"))
+		})
+	})
+
+	Context("with --diff", func() {
+		var stderr string
+
+		JustBeforeEach(func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			ctrl := filepath.Join(exampleDir, "foo.go")
+			content, err := ioutil.ReadFile(ctrl)
+			Ω(err).ShouldNot(HaveOccurred())
+			edited := strings.Replace(string(content), "func (c *FooController) Show(", "func (c *FooController) Custom() {}\n\nfunc (c *FooController) Show(", 1)
+			Ω(ioutil.WriteFile(ctrl, []byte(edited), 0644)).Should(Succeed())
+			list := newResource("foo").Actions["show"]
+			list.Name = "list"
+			list.Parent = design.Design.Resources["foo"]
+			design.Design.Resources["foo"].Actions["list"] = list
+			design.Design.Resources["bar"] = newResource("bar")
+			os.Args = append(os.Args, "--diff")
+
+			r, w, err := os.Pipe()
+			Ω(err).ShouldNot(HaveOccurred())
+			orig := os.Stderr
+			os.Stderr = w
+			files, genErr = genexample.Generate()
+			os.Stderr = orig
+			w.Close()
+			out, err := ioutil.ReadAll(r)
+			Ω(err).ShouldNot(HaveOccurred())
+			stderr = string(out)
+		})
+
+		It("prints the differences without writing files", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(BeEmpty())
+			_, err := os.Stat(filepath.Join(exampleDir, "bar.go"))
+			Ω(os.IsNotExist(err)).Should(BeTrue())
+			Ω(stderr).Should(ContainSubstring("new file bar.go\n"))
+			Ω(stderr).Should(ContainSubstring("foo.go: new FooController.List\n"))
+			Ω(stderr).Should(ContainSubstring("--- foo.go\n+++ foo.go (generated)\n"))
+			Ω(stderr).Should(ContainSubstring("\n-func (c *FooController) Custom() {}\n"))
+			Ω(stderr).ShouldNot(ContainSubstring("main.go"))
+			entries, err := ioutil.ReadDir(exampleDir)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(entries).Should(HaveLen(2))
+		})
+	})
+})
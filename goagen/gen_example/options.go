@@ -0,0 +1,41 @@
+package genexample
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//DesignPkg Path to design package, only used to mark generated files.
+func DesignPkg(designPkg string) Option {
+	return func(g *Generator) {
+		g.DesignPkg = designPkg
+	}
+}
+
+//AppPkg Name of generated "app" package
+func AppPkg(pkg string) Option {
+	return func(g *Generator) {
+		g.AppPkg = pkg
+	}
+}
+
+//Diff Whether to print the differences with the existing files instead of generating
+func Diff(diff bool) Option {
+	return func(g *Generator) {
+		g.Diff = diff
+	}
+}
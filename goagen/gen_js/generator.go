@@ -3,7 +3,6 @@ package genjs
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -99,7 +98,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 
 	g.OutDir = filepath.Join(g.OutDir, "js")
-	if err := os.RemoveAll(g.OutDir); err != nil {
+	if err := codegen.RemoveAll(g.OutDir); err != nil {
 		return nil, err
 	}
 	if err := os.MkdirAll(g.OutDir, 0755); err != nil {
@@ -174,7 +173,11 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 			if exampleAction == nil && a.Routes[0].Verb == "GET" {
 				exampleAction = a
 			}
-			data := map[string]interface{}{"Action": a}
+			data := map[string]interface{}{"Action": a, "Path": a.Routes[0].VersionedPath(a.LatestVersion())}
+			if name, value := g.API.Versioning.RequestHeader(a.LatestVersion()); name != "" {
+				data["VersionHeader"] = name
+				data["VersionValue"] = value
+			}
 			funcs := template.FuncMap{"params": params}
 			if err = file.ExecuteTemplate("jsFuncs", jsFuncsT, funcs, data); err != nil {
 				return
@@ -205,7 +208,7 @@ func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *design.Act
 		}
 		args = strings.Join(argValues, ", ")
 	}
-	examplePath := exampleAction.Routes[0].FullPath()
+	examplePath := exampleAction.Routes[0].VersionedPath(exampleAction.LatestVersion())
 	pathParams := exampleAction.Routes[0].Params()
 	if len(pathParams) > 0 {
 		pathVars := exampleAction.AllParams().Type.ToObject()
@@ -237,7 +240,7 @@ func (g *Generator) generateIndexHTML(htmlFile string, exampleAction *design.Act
 
 func (g *Generator) generateAxiosJS() error {
 	filePath := filepath.Join(g.OutDir, "axios.min.js")
-	if err := ioutil.WriteFile(filePath, []byte(axios), 0644); err != nil {
+	if err := codegen.WriteFile(filePath, []byte(axios)); err != nil {
 		return err
 	}
 	g.genfiles = append(g.genfiles, filePath)
@@ -317,7 +320,7 @@ const moduleTend = `  return client;
 
 const jsFuncsT = `{{$params := params .Action}}
   {{$name := printf "%s%s" .Action.Name (title .Action.Parent.Name)}}// {{if .Action.Description}}{{.Action.Description}}{{else}}{{$name}} calls the {{.Action.Name}} action of the {{.Action.Parent.Name}} resource.{{end}}
-  // path is the request path, the format is "{{(index .Action.Routes 0).FullPath}}"
+  // path is the request path, the format is "{{.Path}}"
   {{if .Action.Payload}}// data contains the action payload (request body)
   {{end}}{{if $params}}// {{join $params ", "}} {{if gt (len $params) 1}}are{{else}}is{{end}} used to build the request query string.
   {{end}}// config is an optional object to be merged into the config built by the function prior to making the request.
@@ -333,6 +336,9 @@ const jsFuncsT = `{{$params := params .Action}}
 {{end}}        {{$param}}: {{$param}}{{end}}
       },
 {{end}}{{if .Action.Payload}}    data: data,
+{{end}}{{if .VersionHeader}}      headers: {
+        {{printf "%q" .VersionHeader}}: {{printf "%q" .VersionValue}}
+      },
 {{end}}      responseType: 'json'
     };
     if (config) {
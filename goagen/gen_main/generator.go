@@ -39,6 +39,7 @@ type Generator struct {
 	Target    string                // Name of generated "app" package
 	Force     bool                  // Whether to override existing files
 	Regen     bool                  // Whether to regenerate scaffolding in place, maintaining controller implementation
+	Metrics   bool                  // Whether to mount the Prometheus metrics middleware and endpoint in main
 	genfiles  []string              // Generated files
 }
 
@@ -46,7 +47,7 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, designPkg, target, ver string
-		force, regen                            bool
+		force, regen, metrics                   bool
 	)
 
 	set := flag.NewFlagSet("main", flag.PanicOnError)
@@ -58,6 +59,10 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("notest", false, "")
+	set.Bool("mocks", false, "")
+	set.Bool("fuzz", false, "")
+	set.Bool("openapi3", false, "")
+	set.BoolVar(&metrics, "metrics", false, "")
 	set.Parse(os.Args[1:])
 
 	if err := codegen.CheckVersion(ver); err != nil {
@@ -65,7 +70,7 @@ func Generate() (files []string, err error) {
 	}
 
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, API: design.Design}
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, Metrics: metrics, API: design.Design}
 
 	return g.Generate()
 }
@@ -146,10 +151,10 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		return "", err
 	}
 	defer func() {
-		file.Close()
 		if err == nil {
 			err = file.FormatCode()
 		}
+		file.Close()
 	}()
 
 	elems := strings.Split(appPkg, "/")
@@ -192,6 +197,9 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		return "", err
 	}
 	err = r.IterateActions(func(a *design.ActionDefinition) error {
+		if a.Streaming() && !a.ServerSentEvents && !a.NDJSON {
+			return file.ExecuteTemplate("actionStream", actionStreamT, funcs, a)
+		}
 		if a.WebSocket() {
 			return file.ExecuteTemplate("actionWS", actionWST, funcs, a)
 		}
@@ -269,10 +277,10 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		return err
 	}
 	defer func() {
-		file.Close()
 		if err == nil {
 			err = file.FormatCode()
 		}
+		file.Close()
 	}()
 	g.genfiles = append(g.genfiles, mainFile)
 	funcs["getPort"] = func(hostport string) string {
@@ -288,25 +296,30 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 	}
 	appPkg := path.Join(outPkg, "app")
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("flag"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
 		codegen.SimpleImport(appPkg),
 	}
+	if g.Metrics {
+		imports = append(imports, codegen.NewImport("goaprometheus", "github.com/goadesign/goa/middleware/prometheus"))
+	}
 	file.Write([]byte("//go:generate goagen bootstrap -d " + g.DesignPkg + "\n\n"))
 	if err = file.WriteHeader("", "main", imports); err != nil {
 		return err
 	}
-	tls := false
+	tls := g.API.TLS != nil
 	for _, scheme := range g.API.Schemes {
 		if scheme == "https" {
 			tls = true
 		}
 	}
 	data := map[string]interface{}{
-		"Name": g.API.Name,
-		"API":  g.API,
-		"TLS":  tls,
+		"Name":    g.API.Name,
+		"API":     g.API,
+		"TLS":     tls,
+		"Metrics": g.Metrics,
 	}
 	err = file.ExecuteTemplate("main", mainT, funcs, data)
 	return
@@ -447,32 +460,92 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx *{{ targetPkg }}.{
 	}
 }`
 
+const actionStreamT = `
+{{- $ctrlName := printf "%s%s" (goify .Parent.Name true) "Controller" -}}
+{{- $actionDescr := printf "%s_%s" $ctrlName (goify .Name true) -}}
+// {{ goify .Name true }} runs the {{ .Name }} action.
+func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
+	return ctx.Upgrade(func(s *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Stream) error {
+		// {{ $actionDescr }}: start_implement
+
+		{{ actionBody $actionDescr }}
+
+		// {{ $actionDescr }}: end_implement
+		return nil
+	})
+}
+`
+
 const mainT = `
 func main() {
+	var (
+		addr              = flag.String("addr", ":{{ getPort .API.Host }}", "Listen address")
+{{- if not .TLS }}
+		h2c               = flag.Bool("h2c", false, "Serve HTTP/2 over cleartext connections (h2c)")
+{{- end }}
+		readHeaderTimeout = flag.Duration("read-header-timeout", 10*time.Second, "Maximum duration for reading request headers")
+		idleTimeout       = flag.Duration("idle-timeout", 2*time.Minute, "Maximum duration to wait for the next request on keep-alive connections")
+		shutdownTimeout   = flag.Duration("shutdown-timeout", 30*time.Second, "Maximum duration of the graceful shutdown")
+	)
+	flag.Parse()
+
 	// Create service
 	service := goa.New({{ printf "%q" .Name }})
 
 	// Mount middleware
 	service.Use(middleware.RequestID())
 	service.Use(middleware.LogRequest(true))
+{{- if .Metrics }}
+	service.Use(goaprometheus.New({{ printf "%q" .Name }}))
+{{- end }}
 	service.Use(middleware.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
+	// Uncomment to compress responses, the middleware can also be mounted on a
+	// single controller with the controller Use method.
+	// service.Use(gzip.Middleware(gzip.DefaultCompression))
 {{ $api := .API }}
 {{ range $name, $res := $api.Resources }}{{ $name := goify $res.Name true }} // Mount "{{$res.Name}}" controller
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
 	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
 {{ end }}
+{{- if .API.HealthCheck }}
+	// Mount the health check probes, pass checkers to check the service dependencies, e.g.
+	// {{ targetPkg }}.MountHealthCheck(service, health.Ping("db", db))
+	{{ targetPkg }}.MountHealthCheck(service)
+{{ end }}
+{{- if .API.Docs }}{{ if .API.Docs.OpenAPIPath }}
+	// Serve the OpenAPI document
+	{{ targetPkg }}.MountDocs(service)
+{{ end }}{{ end }}
+{{- if .Metrics }}
+	// Serve the Prometheus metrics
+	goaprometheus.Mount(service, "/metrics")
+{{ end }}
 
-{{ if .TLS }}
-	// Start service
-	if err := service.ListenAndServeTLS(":{{ getPort .API.Host }}", "cert.pem", "key.pem"); err != nil {
+	// Start service, shut down gracefully on SIGINT or SIGTERM. Use goa.OnShutdown to flush
+	// loggers and tracers on shutdown.
+	opts := []goa.RunOption{
+{{- if .API.TLS }}
+{{- range .API.TLS.Certificates }}
+		goa.TLSFiles({{ printf "%q" .CertFile }}, {{ printf "%q" .KeyFile }}),
+{{- end }}
+{{- if .API.TLS.ClientCA }}
+		goa.ClientCAFile({{ printf "%q" .API.TLS.ClientCA }}),
+{{- end }}
+{{- else if .TLS }}
+		goa.TLSFiles("cert.pem", "key.pem"),
+{{- end }}
+		goa.ReadHeaderTimeout(*readHeaderTimeout),
+		goa.IdleTimeout(*idleTimeout),
+		goa.ShutdownTimeout(*shutdownTimeout),
+	}
+{{- if not .TLS }}
+	if *h2c {
+		opts = append(opts, goa.H2C())
+	}
+{{- end }}
+	if err := service.Run(*addr, opts...); err != nil {
 		service.LogError("startup", "err", err)
 	}
-{{ else }}
-	// Start service
-	if err := service.ListenAndServe(":{{ getPort .API.Host }}"); err != nil {
-		service.LogError("startup", "err", err)
-	}
-{{ end }}
 }
 `
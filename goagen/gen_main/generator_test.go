@@ -54,6 +54,7 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(len(strings.Split(string(content), "\n"))).Should(BeNumerically(">=", 16))
 			Ω(string(content)).Should(ContainSubstring(listenAndServeCode))
+			Ω(string(content)).Should(ContainSubstring(`addr              = flag.String("addr", ":8080", "Listen address")`))
 			_, err = gexec.Build(testgenPackagePath)
 			Ω(err).ShouldNot(HaveOccurred())
 		})
@@ -75,6 +76,69 @@ var _ = Describe("Generate", func() {
 			})
 
 		})
+
+		Context("with a health check", func() {
+			BeforeEach(func() {
+				design.Design.HealthCheck = &design.HealthCheckDefinition{LivePath: "/healthz", ReadyPath: "/readyz"}
+			})
+
+			It("mounts the probes", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(".MountHealthCheck(service)"))
+			})
+		})
+
+		Context("with served documentation", func() {
+			BeforeEach(func() {
+				design.Design.Docs = &design.DocsDefinition{OpenAPIPath: "/openapi.json"}
+			})
+
+			It("mounts the documentation handlers", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(".MountDocs(service)"))
+			})
+		})
+
+		Context("with TLS", func() {
+			BeforeEach(func() {
+				design.Design.TLS = &design.TLSDefinition{
+					Certificates: []*design.CertificateDefinition{
+						{CertFile: "a.pem", KeyFile: "a-key.pem"},
+						{CertFile: "b.pem", KeyFile: "b-key.pem"},
+					},
+					ClientCA: "ca.pem",
+				}
+			})
+
+			It("serves HTTPS with the certificates and client CA", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(tlsFilesCode))
+				Ω(string(content)).ShouldNot(ContainSubstring("h2c"))
+			})
+		})
+
+		Context("with metrics", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--metrics")
+			})
+
+			It("mounts the Prometheus middleware and endpoint", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`goaprometheus "github.com/goadesign/goa/middleware/prometheus"`))
+				Ω(string(content)).Should(ContainSubstring(metricsCode))
+				Ω(string(content)).Should(ContainSubstring(`goaprometheus.Mount(service, "/metrics")`))
+				_, err = gexec.Build(testgenPackagePath)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
 	})
 
 	Context("with resources", func() {
@@ -198,6 +262,7 @@ var _ = Describe("NewGenerator", func() {
 		target    string
 		force     bool
 		regen     bool
+		metrics   bool
 		noExample bool
 	}{
 		api: &design.APIDefinition{
@@ -208,6 +273,7 @@ var _ = Describe("NewGenerator", func() {
 		target:    "app",
 		force:     false,
 		regen:     false,
+		metrics:   true,
 	}
 
 	Context("with options all options set", func() {
@@ -220,6 +286,7 @@ var _ = Describe("NewGenerator", func() {
 				genmain.Target(args.target),
 				genmain.Force(args.force),
 				genmain.Regen(args.regen),
+				genmain.Metrics(args.metrics),
 			)
 		})
 
@@ -231,19 +298,48 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.Target).Should(Equal(args.target))
 			Ω(generator.Force).Should(Equal(args.force))
 			Ω(generator.Regen).Should(Equal(args.regen))
+			Ω(generator.Metrics).Should(Equal(args.metrics))
 		})
 
 	})
 })
 
 const listenAndServeCode = `
-	if err := service.ListenAndServe(":8080"); err != nil {
+	opts := []goa.RunOption{
+		goa.ReadHeaderTimeout(*readHeaderTimeout),
+		goa.IdleTimeout(*idleTimeout),
+		goa.ShutdownTimeout(*shutdownTimeout),
+	}
+	if *h2c {
+		opts = append(opts, goa.H2C())
+	}
+	if err := service.Run(*addr, opts...); err != nil {
 		service.LogError("startup", "err", err)
 	}
 `
 
+const metricsCode = `
+	service.Use(middleware.LogRequest(true))
+	service.Use(goaprometheus.New("test api"))
+	service.Use(middleware.ErrorHandler(service, true))
+`
+
 const listenAndServeTLSCode = `
-	if err := service.ListenAndServeTLS(":8080", "cert.pem", "key.pem"); err != nil {
+	opts := []goa.RunOption{
+		goa.TLSFiles("cert.pem", "key.pem"),
+		goa.ReadHeaderTimeout(*readHeaderTimeout),
+		goa.IdleTimeout(*idleTimeout),
+		goa.ShutdownTimeout(*shutdownTimeout),
+	}
+	if err := service.Run(*addr, opts...); err != nil {
 		service.LogError("startup", "err", err)
 	}
 `
+
+const tlsFilesCode = `
+	opts := []goa.RunOption{
+		goa.TLSFiles("a.pem", "a-key.pem"),
+		goa.TLSFiles("b.pem", "b-key.pem"),
+		goa.ClientCAFile("ca.pem"),
+		goa.ReadHeaderTimeout(*readHeaderTimeout),
+`
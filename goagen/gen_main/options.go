@@ -46,3 +46,10 @@ func Regen(regen bool) Option {
 		g.Regen = regen
 	}
 }
+
+//Metrics Whether to mount the Prometheus metrics middleware and endpoint in main
+func Metrics(metrics bool) Option {
+	return func(g *Generator) {
+		g.Metrics = metrics
+	}
+}
@@ -0,0 +1,24 @@
+/*
+Package genpact implements the goagen pact command which generates Pact consumer contracts from
+the design.
+
+The contract follows version 2 of the Pact specification (https://docs.pact.io) and contains one
+interaction per action route and response. The requests are built from the examples of the path
+parameters, of the required query string parameters and headers and of the payload. The response
+bodies are the examples of the response media types rendered with the response view. The
+contract only requires the response body values to have the same types as the examples so that
+the examples do not need to match the data served by the provider.
+
+The contract is written to pact/<consumer>-<api>.json where the consumer and API names are snake
+cased with the spaces replaced with underscores, e.g. pact/web-cellar_api.json for the "web"
+consumer of the "Cellar API" API. The consumer name defaults to the API name followed by
+"-client". Any Pact provider verifier can then check a deployed service against the design in CI,
+for example:
+
+	goagen pact -d github.com/foo/bar/design --consumer web
+	pact-provider-verifier pact/web-bar.json --provider-base-url http://localhost:8080
+
+The provider states are named after the resource, action and response, e.g. "bottle show responds
+with OK", and let the verifier set up the data the provider needs to return the response.
+*/
+package genpact
@@ -0,0 +1,106 @@
+package genpact
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a Pact contract generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Pact contract generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Consumer string                // Name of the consumer, defaults to the API name followed by "-client"
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, consumer, ver string
+	set := flag.NewFlagSet("pact", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.StringVar(&consumer, "consumer", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: design.Design, Consumer: consumer}
+
+	return g.Generate()
+}
+
+// Generate produces the contract file.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	consumer := g.Consumer
+	if consumer == "" {
+		consumer = g.API.Name + "-client"
+	}
+	p, err := New(g.API, consumer)
+	if err != nil {
+		return
+	}
+	js, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return
+	}
+
+	outDir := filepath.Join(g.OutDir, "pact")
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return
+	}
+	pactFile := filepath.Join(outDir, fmt.Sprintf("%s-%s.json", fileName(consumer), fileName(g.API.Name)))
+	if err = codegen.WriteFile(pactFile, js); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, pactFile)
+
+	return g.genfiles, nil
+}
+
+// fileName returns the snake cased name with the spaces replaced with underscores used to name
+// the contract file, e.g. "cellar_api" for "Cellar API".
+func fileName(name string) string {
+	return strings.Replace(codegen.SnakeCase(name), " ", "_", -1)
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
@@ -0,0 +1,203 @@
+package genpact
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// Pact is a consumer contract that follows version 2 of the Pact specification.
+	Pact struct {
+		Consumer     *Pacticipant           `json:"consumer"`
+		Provider     *Pacticipant           `json:"provider"`
+		Interactions []*Interaction         `json:"interactions"`
+		Metadata     map[string]interface{} `json:"metadata"`
+	}
+
+	// Pacticipant identifies the consumer or the provider of a contract.
+	Pacticipant struct {
+		Name string `json:"name"`
+	}
+
+	// Interaction describes a request sent by the consumer and the response the provider
+	// must return.
+	Interaction struct {
+		// Description is unique within the contract.
+		Description string `json:"description"`
+		// ProviderState is the state the provider must be in for the response to be
+		// returned, provider verification tools use it to set up test data.
+		ProviderState string    `json:"providerState,omitempty"`
+		Request       *Request  `json:"request"`
+		Response      *Response `json:"response"`
+	}
+
+	// Request is the request sent by the consumer.
+	Request struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Query   string            `json:"query,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body,omitempty"`
+	}
+
+	// Response is the response returned by the provider.
+	Response struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body,omitempty"`
+		// MatchingRules relax the comparison of the response with the contract, the
+		// generated contracts only require the body values to have the example types.
+		MatchingRules map[string]interface{} `json:"matchingRules,omitempty"`
+	}
+)
+
+// New creates the contract between the given consumer and the API. The contract contains one
+// interaction per action route and response. The requests and responses are built from the
+// design examples.
+func New(api *design.APIDefinition, consumer string) (*Pact, error) {
+	p := &Pact{
+		Consumer: &Pacticipant{Name: consumer},
+		Provider: &Pacticipant{Name: api.Name},
+		Metadata: map[string]interface{}{
+			"pactSpecification": map[string]interface{}{"version": "2.0.0"},
+		},
+	}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			return a.IterateResponses(func(resp *design.ResponseDefinition) error {
+				if resp.Status == 101 {
+					return nil
+				}
+				for i, r := range a.Routes {
+					in, err := interaction(api, a, r, resp)
+					if err != nil {
+						return err
+					}
+					if len(a.Routes) > 1 {
+						in.Description += fmt.Sprintf(" (%s %s)", r.Verb, r.VersionedPath(a.LatestVersion()))
+						if i > 0 {
+							in.ProviderState += fmt.Sprintf(" on route %d", i)
+						}
+					}
+					p.Interactions = append(p.Interactions, in)
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// interaction builds the interaction for the given action route and response.
+func interaction(api *design.APIDefinition, a *design.ActionDefinition, r *design.RouteDefinition, resp *design.ResponseDefinition) (*Interaction, error) {
+	req := &Request{Method: r.Verb, Path: examplePath(api, a, r)}
+	if a.QueryParams != nil {
+		query := make(url.Values)
+		for n, att := range a.QueryParams.Type.ToObject() {
+			if !a.QueryParams.IsRequired(n) {
+				continue
+			}
+			if ex := codegen.AttributeExample(api, att); ex != nil {
+				addQueryExample(query, n, ex)
+			}
+		}
+		req.Query = query.Encode()
+	}
+	headers := make(map[string]string)
+	a.IterateHeaders(func(n string, required bool, att *design.AttributeDefinition) error {
+		if required {
+			if ex := codegen.AttributeExample(api, att); ex != nil {
+				headers[n] = fmt.Sprintf("%v", ex)
+			}
+		}
+		return nil
+	})
+	if name, value := api.Versioning.RequestHeader(a.LatestVersion()); name != "" {
+		headers[name] = value
+	}
+	if a.Payload != nil {
+		req.Body = codegen.AttributeExample(api, a.Payload.AttributeDefinition)
+		if req.Body != nil {
+			headers["Content-Type"] = "application/json"
+		}
+	}
+	if len(headers) > 0 {
+		req.Headers = headers
+	}
+
+	res := &Response{Status: resp.Status}
+	if mt := api.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+		view := resp.ViewName
+		if view == "" {
+			view = design.DefaultView
+		}
+		if _, ok := mt.Views[view]; ok {
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return nil, err
+			}
+			res.Body = codegen.AttributeExample(api, p.AttributeDefinition)
+		}
+	}
+	if res.Body != nil {
+		res.MatchingRules = map[string]interface{}{
+			"$.body": map[string]interface{}{"match": "type"},
+		}
+	}
+
+	return &Interaction{
+		Description:   fmt.Sprintf("%s %s %s", a.Name, a.Parent.Name, resp.Name),
+		ProviderState: fmt.Sprintf("%s %s responds with %s", a.Parent.Name, a.Name, resp.Name),
+		Request:       req,
+		Response:      res,
+	}, nil
+}
+
+// addQueryExample adds the example of the query string parameter with the given name to query.
+// The elements of array examples are added as repeated parameters and the entries of map
+// examples use the "name[key]=value" syntax.
+func addQueryExample(query url.Values, name string, ex interface{}) {
+	v := reflect.ValueOf(ex)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			query.Add(name, fmt.Sprintf("%v", v.Index(i).Interface()))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			query.Add(fmt.Sprintf("%s[%v]", name, k.Interface()), fmt.Sprintf("%v", v.MapIndex(k).Interface()))
+		}
+	default:
+		query.Add(name, fmt.Sprintf("%v", ex))
+	}
+}
+
+// examplePath returns the full path of the route served by the latest API version with the
+// wildcards replaced with the example values of the corresponding parameters.
+func examplePath(api *design.APIDefinition, a *design.ActionDefinition, r *design.RouteDefinition) string {
+	params := a.AllParams().Type.ToObject()
+	return design.WildcardRegex.ReplaceAllStringFunc(r.VersionedPath(a.LatestVersion()), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		val := name
+		if att, ok := params[name]; ok {
+			if ex := codegen.AttributeExample(api, att); ex != nil {
+				val = fmt.Sprintf("%v", ex)
+			}
+		}
+		if strings.HasPrefix(w, "/*") {
+			return "/" + val
+		}
+		return "/" + url.PathEscape(val)
+	})
+}
@@ -0,0 +1,202 @@
+package genpact_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_pact"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "genpact")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		ProjectedMediaTypes = make(MediaTypeRoot)
+		API("cellar", func() {
+			BasePath("/cellar")
+		})
+		BottleMedia := MediaType("application/vnd.goa.bottle", func() {
+			Attributes(func() {
+				Attribute("id", Integer, func() {
+					Example(1)
+				})
+				Attribute("name", String, func() {
+					Example("Number 8")
+				})
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			BasePath("/bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer, func() {
+						Example(42)
+					})
+				})
+				Headers(func() {
+					Header("X-Account", String, func() {
+						Example("acme")
+					})
+					Required("X-Account")
+				})
+				Response(OK, BottleMedia)
+				Response(NotFound)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Params(func() {
+					Param("dry", Boolean, func() {
+						Example(true)
+					})
+					Param("notify", Boolean)
+					Param("tags", ArrayOf(String), func() {
+						Example([]string{"red", "dry"})
+					})
+					Required("dry", "tags")
+				})
+				Payload(func() {
+					Member("name", String, func() {
+						Example("Number 9")
+					})
+					Member("secret", String, func() {
+						NoExample()
+					})
+				})
+				Response(Created)
+			})
+		})
+		Ω(dslengine.Run()).Should(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genpact.NewGenerator(
+			genpact.API(Design),
+			genpact.OutDir(outDir),
+			genpact.Consumer("web"),
+		).Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("generates the contract", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(Equal([]string{filepath.Join(outDir, "pact", "web-cellar.json")}))
+		b, err := ioutil.ReadFile(files[0])
+		Ω(err).ShouldNot(HaveOccurred())
+		var p genpact.Pact
+		Ω(json.Unmarshal(b, &p)).Should(Succeed())
+
+		Ω(p.Consumer.Name).Should(Equal("web"))
+		Ω(p.Provider.Name).Should(Equal("cellar"))
+		Ω(p.Metadata).Should(HaveKeyWithValue("pactSpecification", map[string]interface{}{"version": "2.0.0"}))
+		Ω(p.Interactions).Should(HaveLen(3))
+
+		create := p.Interactions[0]
+		Ω(create.Description).Should(Equal("create bottle Created"))
+		Ω(create.ProviderState).Should(Equal("bottle create responds with Created"))
+		Ω(create.Request.Method).Should(Equal("POST"))
+		Ω(create.Request.Path).Should(Equal("/cellar/bottles"))
+		Ω(create.Request.Query).Should(Equal("dry=true&tags=red&tags=dry"))
+		Ω(create.Request.Headers).Should(Equal(map[string]string{"Content-Type": "application/json"}))
+		Ω(create.Request.Body).Should(Equal(map[string]interface{}{"name": "Number 9"}))
+		Ω(create.Response.Status).Should(Equal(201))
+		Ω(create.Response.Body).Should(BeNil())
+		Ω(create.Response.MatchingRules).Should(BeEmpty())
+
+		notFound := p.Interactions[1]
+		Ω(notFound.Description).Should(Equal("show bottle NotFound"))
+		Ω(notFound.Response.Status).Should(Equal(404))
+
+		ok := p.Interactions[2]
+		Ω(ok.Description).Should(Equal("show bottle OK"))
+		Ω(ok.Request.Method).Should(Equal("GET"))
+		Ω(ok.Request.Path).Should(Equal("/cellar/bottles/42"))
+		Ω(ok.Request.Query).Should(BeEmpty())
+		Ω(ok.Request.Headers).Should(Equal(map[string]string{"X-Account": "acme"}))
+		Ω(ok.Request.Body).Should(BeNil())
+		Ω(ok.Response.Status).Should(Equal(200))
+		Ω(ok.Response.Body).Should(Equal(map[string]interface{}{"id": 1.0, "name": "Number 8"}))
+		Ω(ok.Response.MatchingRules).Should(HaveKey("$.body"))
+	})
+
+	Context("with a versioned API", func() {
+		BeforeEach(func() {
+			Design.Versioning = &VersioningDefinition{Versions: []string{"v1", "v2"}, Scheme: VersionSchemeHeader, Name: "X-API-Version"}
+		})
+
+		AfterEach(func() {
+			Design.Versioning = nil
+		})
+
+		It("selects the latest version", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			b, err := ioutil.ReadFile(files[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			var p genpact.Pact
+			Ω(json.Unmarshal(b, &p)).Should(Succeed())
+			ok := p.Interactions[2]
+			Ω(ok.Request.Path).Should(Equal("/cellar/bottles/42"))
+			Ω(ok.Request.Headers).Should(Equal(map[string]string{"X-Account": "acme", "X-API-Version": "v2"}))
+		})
+
+		Context("using path versioning", func() {
+			BeforeEach(func() {
+				Design.Versioning.Scheme = VersionSchemePath
+			})
+
+			It("prefixes the paths with the latest version", func() {
+				Ω(genErr).ShouldNot(HaveOccurred())
+				b, err := ioutil.ReadFile(files[0])
+				Ω(err).ShouldNot(HaveOccurred())
+				var p genpact.Pact
+				Ω(json.Unmarshal(b, &p)).Should(Succeed())
+				Ω(p.Interactions[2].Request.Path).Should(Equal("/cellar/v2/bottles/42"))
+				Ω(p.Interactions[2].Request.Headers).Should(Equal(map[string]string{"X-Account": "acme"}))
+			})
+		})
+	})
+
+	Context("with no consumer", func() {
+		JustBeforeEach(func() {
+			files, genErr = genpact.NewGenerator(genpact.API(Design), genpact.OutDir(outDir)).Generate()
+		})
+
+		It("names the consumer after the API", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(Equal([]string{filepath.Join(outDir, "pact", "cellar-client-cellar.json")}))
+		})
+	})
+
+	Context("with names that are not snake cased", func() {
+		JustBeforeEach(func() {
+			Design.Name = "Cellar API"
+			files, genErr = genpact.NewGenerator(genpact.API(Design), genpact.OutDir(outDir), genpact.Consumer("WebApp")).Generate()
+		})
+
+		It("snake cases the names in the file name", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(Equal([]string{filepath.Join(outDir, "pact", "web_app-cellar_api.json")}))
+		})
+	})
+})
@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 type (
@@ -44,11 +46,23 @@ type (
 		MaxLength            *int          `json:"maxLength,omitempty"`
 		Required             []string      `json:"required,omitempty"`
 		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
+		// AdditionalPropertiesSchema describes the values of the additional properties, it
+		// is rendered as the "additionalProperties" field when set.
+		AdditionalPropertiesSchema *JSONSchema `json:"-"`
 
-		// Union
-		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+		// Union and composition
+		AllOf         []*JSONSchema      `json:"allOf,omitempty"`
+		AnyOf         []*JSONSchema      `json:"anyOf,omitempty"`
+		OneOf         []*JSONSchema      `json:"oneOf,omitempty"`
+		Discriminator *JSONDiscriminator `json:"discriminator,omitempty"`
+
+		// Extensions lists the "x-" extensions of the schema, see Extensions.
+		Extensions map[string]interface{} `json:"-"`
 	}
 
+	// _JSONSchema is used by MarshalJSON to avoid recursive calls to json.Marshal.
+	_JSONSchema JSONSchema
+
 	// JSONType is the JSON type enum.
 	JSONType string
 
@@ -58,6 +72,13 @@ type (
 		Type           string `json:"type,omitempty"`
 	}
 
+	// JSONDiscriminator represents the "discriminator" field of the schemas of types defined with
+	// OneOf, it maps the values of the discriminator property to the schemas of the variants.
+	JSONDiscriminator struct {
+		PropertyName string            `json:"propertyName"`
+		Mapping      map[string]string `json:"mapping,omitempty"`
+	}
+
 	// JSONLink represents a "link" field in a JSON hyper schema.
 	JSONLink struct {
 		Title        string      `json:"title,omitempty"`
@@ -123,6 +144,56 @@ func (s *JSONSchema) JSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// MarshalJSON returns the JSON encoding of s including its extensions and the schema of its
+// additional properties.
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(_JSONSchema(s))
+	if err != nil || len(s.Extensions) == 0 && s.AdditionalPropertiesSchema == nil {
+		return b, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	if s.AdditionalPropertiesSchema != nil {
+		fields["additionalProperties"] = s.AdditionalPropertiesSchema
+	}
+	for k, v := range s.Extensions {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// Extensions returns the "x-" extensions defined by the given metadata. Extensions are defined
+// with keys of the form "openapi:extension:x-name" or "swagger:extension:x-name". The metadata
+// value is used as is unless it is valid JSON in which case it is decoded first.
+func Extensions(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	extensions := make(map[string]interface{})
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if chunks[0] != "swagger" && chunks[0] != "openapi" || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") || len(value) == 0 {
+			continue
+		}
+		val := value[0]
+		var ival interface{}
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+	return extensions
+}
+
 // APISchema produces the API JSON hyper schema.
 func APISchema(api *design.APIDefinition) *JSONSchema {
 	api.IterateResources(func(r *design.ResourceDefinition) error {
@@ -241,6 +312,10 @@ func MediaTypeRef(api *design.APIDefinition, mt *design.MediaTypeDefinition, vie
 	}
 	if _, ok := Definitions[projected.TypeName]; !ok {
 		GenerateMediaTypeDefinition(api, projected, "default")
+		// Projected media types do not retain the metadata of the media type.
+		if ext := Extensions(mt.Metadata); ext != nil {
+			Definitions[projected.TypeName].Extensions = ext
+		}
 	}
 	ref := fmt.Sprintf("#/definitions/%s", projected.TypeName)
 	return ref
@@ -275,6 +350,22 @@ func GenerateTypeDefinition(api *design.APIDefinition, ut *design.UserTypeDefini
 	s.Title = ut.TypeName
 	Definitions[ut.TypeName] = s
 	buildAttributeSchema(api, s, ut.AttributeDefinition)
+	for _, b := range ut.Bases {
+		if base, ok := b.(*design.UserTypeDefinition); ok {
+			s.AllOf = append(s.AllOf, &JSONSchema{Ref: TypeRef(api, base)})
+		}
+	}
+	if ut.IsUnion() {
+		s.Discriminator = &JSONDiscriminator{
+			PropertyName: ut.Union.Discriminator,
+			Mapping:      make(map[string]string, len(ut.Union.Variants)),
+		}
+		for _, v := range ut.Union.Variants {
+			ref := TypeRef(api, v.Type)
+			s.OneOf = append(s.OneOf, &JSONSchema{Ref: ref})
+			s.Discriminator.Mapping[v.Value] = ref
+		}
+	}
 }
 
 // TypeSchema produces the JSON schema corresponding to the given data type.
@@ -308,7 +399,13 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 		}
 	case *design.Hash:
 		s.Type = JSONObject
-		s.AdditionalProperties = true
+		if actual.ElemType.Type.Kind() == design.AnyKind {
+			s.AdditionalProperties = true
+		} else {
+			elem := NewJSONSchema()
+			buildAttributeSchema(api, elem, actual.ElemType)
+			s.AdditionalPropertiesSchema = elem
+		}
 	case *design.UserTypeDefinition:
 		s.Ref = TypeRef(api, actual)
 	case *design.MediaTypeDefinition:
@@ -338,6 +435,8 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
 		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.AdditionalPropertiesSchema, other.AdditionalPropertiesSchema, s.AdditionalPropertiesSchema == nil},
+		{&s.Extensions, other.Extensions, s.Extensions == nil},
 		{
 			a: s.Minimum, b: other.Minimum,
 			needed: (s.Minimum == nil && s.Minimum != nil) ||
@@ -417,6 +516,10 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		MaxLength:            s.MaxLength,
 		Required:             s.Required,
 		AdditionalProperties: s.AdditionalProperties,
+		AllOf:                s.AllOf,
+		OneOf:                s.OneOf,
+		Discriminator:        s.Discriminator,
+		Extensions:           s.Extensions,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -424,6 +527,9 @@ func (s *JSONSchema) Dup() *JSONSchema {
 	if s.Items != nil {
 		js.Items = s.Items.Dup()
 	}
+	if s.AdditionalPropertiesSchema != nil {
+		js.AdditionalPropertiesSchema = s.AdditionalPropertiesSchema.Dup()
+	}
 	for n, d := range s.Definitions {
 		js.Definitions[n] = d.Dup()
 	}
@@ -446,12 +552,20 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.DefaultValue = toStringMap(at.DefaultValue)
 	s.Description = at.Description
 	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
+	if ext := Extensions(at.Metadata); ext != nil {
+		s.Extensions = ext
+	}
+	if scalar := at.Scalar(); scalar != "" {
+		s.Format = scalar
+	}
 	val := at.Validation
 	if val == nil {
 		return s
 	}
 	s.Enum = val.Values
-	s.Format = val.Format
+	if val.Format != "" || at.Scalar() == "" {
+		s.Format = val.Format
+	}
 	s.Pattern = val.Pattern
 	if val.Minimum != nil {
 		s.Minimum = val.Minimum
@@ -505,15 +619,15 @@ func toString(val interface{}) string {
 	}
 }
 
-// toSchemaHref produces a href that replaces the path wildcards with JSON schema references when
-// appropriate.
+// toSchemaHref produces a href to the route served by the latest API version that replaces the
+// path wildcards with JSON schema references when appropriate.
 func toSchemaHref(api *design.APIDefinition, r *design.RouteDefinition) string {
 	params := r.Params()
 	args := make([]interface{}, len(params))
 	for i, p := range params {
 		args[i] = fmt.Sprintf("/{%s}", p)
 	}
-	tmpl := design.WildcardRegex.ReplaceAllLiteralString(r.FullPath(), "%s")
+	tmpl := design.WildcardRegex.ReplaceAllLiteralString(r.VersionedPath(r.Parent.LatestVersion()), "%s")
 	return fmt.Sprintf(tmpl, args...)
 }
 
@@ -1,6 +1,8 @@
 package genschema_test
 
 import (
+	"encoding/json"
+
 	"github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -78,4 +80,147 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with a type extending a base type", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			base := Type("Entity", func() {
+				Attribute("id", design.Integer)
+			})
+			Type("Bottle", func() {
+				Extend(base)
+				Attribute("name", design.String)
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Bottle"]
+		})
+
+		It("lists the base type under allOf", func() {
+			Ω(s.Ref).Should(Equal("#/definitions/Bottle"))
+			def := genschema.Definitions["Bottle"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.AllOf).Should(HaveLen(1))
+			Ω(def.AllOf[0].Ref).Should(Equal("#/definitions/Entity"))
+			Ω(def.Properties).Should(HaveKey("id"))
+			Ω(def.Properties).Should(HaveKey("name"))
+		})
+	})
+
+	Context("with a hash", func() {
+		BeforeEach(func() {
+			typ = HashOf(design.Integer, design.String, nil, func() {
+				MinLength(2)
+			})
+		})
+
+		It("describes the values with additionalProperties", func() {
+			Ω(s.Type).Should(Equal(genschema.JSONType(genschema.JSONObject)))
+			Ω(s.AdditionalProperties).Should(BeFalse())
+			values := s.AdditionalPropertiesSchema
+			Ω(values).ShouldNot(BeNil())
+			Ω(values.Type).Should(Equal(genschema.JSONType(genschema.JSONString)))
+			Ω(*values.MinLength).Should(Equal(2))
+			b, err := json.Marshal(s)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"additionalProperties":{"type":"string"`))
+		})
+	})
+
+	Context("with a hash of any values", func() {
+		BeforeEach(func() {
+			typ = HashOf(design.String, design.Any)
+		})
+
+		It("allows any additional property", func() {
+			Ω(s.AdditionalProperties).Should(BeTrue())
+			Ω(s.AdditionalPropertiesSchema).Should(BeNil())
+		})
+	})
+
+	Context("with a self-referencing user type", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			Type("Comment", func() {
+				Attribute("body", design.String)
+				Attribute("replies", ArrayOf("Comment"))
+				Attribute("parent", "Comment")
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Comment"]
+		})
+
+		It("references the type definition", func() {
+			Ω(s.Ref).Should(Equal("#/definitions/Comment"))
+			def := genschema.Definitions["Comment"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties).Should(HaveKey("replies"))
+			Ω(def.Properties["replies"].Items.Ref).Should(Equal("#/definitions/Comment"))
+			Ω(def.Properties["parent"].Ref).Should(Equal("#/definitions/Comment"))
+		})
+	})
+
+	Context("with a union type", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			dog := Type("Dog", func() {
+				Attribute("name", design.String)
+			})
+			cat := Type("Cat", func() {
+				Attribute("lives", design.Integer)
+			})
+			OneOf("Pet", func() {
+				Discriminator("kind")
+				Variant("dog", dog)
+				Variant("cat", cat)
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Pet"]
+		})
+
+		It("defines the variants with oneOf and a discriminator", func() {
+			Ω(s.Ref).Should(Equal("#/definitions/Pet"))
+			def := genschema.Definitions["Pet"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.OneOf).Should(HaveLen(2))
+			Ω(def.OneOf[0].Ref).Should(Equal("#/definitions/Dog"))
+			Ω(def.OneOf[1].Ref).Should(Equal("#/definitions/Cat"))
+			Ω(def.Discriminator).ShouldNot(BeNil())
+			Ω(def.Discriminator.PropertyName).Should(Equal("kind"))
+			Ω(def.Discriminator.Mapping).Should(Equal(map[string]string{
+				"dog": "#/definitions/Dog",
+				"cat": "#/definitions/Cat",
+			}))
+			Ω(def.Properties).Should(HaveKey("kind"))
+			Ω(def.Required).Should(Equal([]string{"kind"}))
+			Ω(genschema.Definitions).Should(HaveKey("Dog"))
+			Ω(genschema.Definitions).Should(HaveKey("Cat"))
+		})
+	})
+})
+
+var _ = Describe("Extensions", func() {
+	It("returns the extensions defined by the metadata", func() {
+		ext := genschema.Extensions(dslengine.MetadataDefinition{
+			"openapi:extension:x-kong":    {`{"plugins":["rate-limiting"]}`},
+			"swagger:extension:x-string":  {"foo"},
+			"openapi:extension:not-x":     {"ignored"},
+			"struct:tag:json":             {"ignored"},
+			"openapi:extension:x-ignored": nil,
+		})
+		Ω(ext).Should(Equal(map[string]interface{}{
+			"x-kong":   map[string]interface{}{"plugins": []interface{}{"rate-limiting"}},
+			"x-string": "foo",
+		}))
+	})
+
+	It("serializes the extensions with the schema", func() {
+		s := genschema.NewJSONSchema()
+		s.Type = genschema.JSONString
+		s.Extensions = map[string]interface{}{"x-nullable": true}
+		b, err := json.Marshal(s)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"type":"string","x-nullable":true}`))
+	})
 })
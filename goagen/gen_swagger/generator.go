@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -30,6 +29,7 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
+	OpenAPI3 bool                  // Whether to generate an OpenAPI 3.0 document instead of Swagger 2.0
 	genfiles []string              // Generated files
 }
 
@@ -37,7 +37,7 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, toolDir, target, ver string
-		regen                        bool
+		regen, openapi3              bool
 	)
 
 	set := flag.NewFlagSet("swagger", flag.PanicOnError)
@@ -49,13 +49,17 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("force", false, "")
 	set.Bool("notest", false, "")
+	set.Bool("mocks", false, "")
+	set.Bool("fuzz", false, "")
+	set.BoolVar(&openapi3, "openapi3", false, "")
+	set.Bool("metrics", false, "")
 	set.Parse(os.Args[1:])
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	g := &Generator{OutDir: outDir, OpenAPI3: openapi3, API: design.Design}
 
 	return g.Generate()
 }
@@ -74,13 +78,22 @@ func (g *Generator) Generate() (_ []string, err error) {
 		}
 	}()
 
-	s, err := New(g.API)
+	var (
+		s    interface{}
+		name = "swagger"
+	)
+	if g.OpenAPI3 {
+		s, err = NewV3(g.API)
+		name = "openapi"
+	} else {
+		s, err = New(g.API)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	swaggerDir := filepath.Join(g.OutDir, "swagger")
-	os.RemoveAll(swaggerDir)
+	codegen.RemoveAll(swaggerDir)
 	if err = os.MkdirAll(swaggerDir, 0755); err != nil {
 		return nil, err
 	}
@@ -91,8 +104,8 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
-	swaggerFile := filepath.Join(swaggerDir, "swagger.json")
-	if err := ioutil.WriteFile(swaggerFile, rawJSON, 0644); err != nil {
+	swaggerFile := filepath.Join(swaggerDir, name+".json")
+	if err := codegen.WriteFile(swaggerFile, rawJSON); err != nil {
 		return nil, err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
@@ -107,8 +120,8 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
-	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
-	if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
+	swaggerFile = filepath.Join(swaggerDir, name+".yaml")
+	if err := codegen.WriteFile(swaggerFile, rawYAML); err != nil {
 		return nil, err
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
@@ -0,0 +1,861 @@
+package genswagger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+)
+
+type (
+	// OpenAPI represents an instance of an OpenAPI 3.0 document.
+	// See https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md
+	OpenAPI struct {
+		OpenAPI      string                 `json:"openapi"`
+		Info         *Info                  `json:"info"`
+		Servers      []*Server              `json:"servers,omitempty"`
+		Paths        map[string]interface{} `json:"paths"`
+		Components   *Components            `json:"components,omitempty"`
+		Security     []map[string][]string  `json:"security,omitempty"`
+		Tags         []*Tag                 `json:"tags,omitempty"`
+		ExternalDocs *ExternalDocs          `json:"externalDocs,omitempty"`
+	}
+
+	// Server represents a server hosting the API.
+	Server struct {
+		// URL to the target host, may be relative to the location of the document.
+		URL string `json:"url"`
+		// Description of the host designated by the URL.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Components holds the reusable objects referenced from the rest of the document.
+	Components struct {
+		// Schemas holds the reusable schemas, typically the schemas of the design types
+		// and media types.
+		Schemas map[string]*genschema.JSONSchema `json:"schemas,omitempty"`
+		// SecuritySchemes holds the security schemes used by the API operations.
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// PathItem describes the operations available on a single path.
+	PathItem struct {
+		// Get defines a GET operation on this path.
+		Get *OpenAPIOperation `json:"get,omitempty"`
+		// Put defines a PUT operation on this path.
+		Put *OpenAPIOperation `json:"put,omitempty"`
+		// Post defines a POST operation on this path.
+		Post *OpenAPIOperation `json:"post,omitempty"`
+		// Delete defines a DELETE operation on this path.
+		Delete *OpenAPIOperation `json:"delete,omitempty"`
+		// Options defines a OPTIONS operation on this path.
+		Options *OpenAPIOperation `json:"options,omitempty"`
+		// Head defines a HEAD operation on this path.
+		Head *OpenAPIOperation `json:"head,omitempty"`
+		// Patch defines a PATCH operation on this path.
+		Patch *OpenAPIOperation `json:"patch,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIOperation describes a single API operation on a path.
+	OpenAPIOperation struct {
+		// Tags is a list of tags for API documentation control.
+		Tags []string `json:"tags,omitempty"`
+		// Summary is a short summary of what the operation does.
+		Summary string `json:"summary,omitempty"`
+		// Description is a verbose explanation of the operation behavior.
+		Description string `json:"description,omitempty"`
+		// ExternalDocs points to additional external documentation for this operation.
+		ExternalDocs *ExternalDocs `json:"externalDocs,omitempty"`
+		// OperationID is a unique string used to identify the operation.
+		OperationID string `json:"operationId,omitempty"`
+		// Parameters is a list of parameters that are applicable for this operation.
+		Parameters []*OpenAPIParameter `json:"parameters,omitempty"`
+		// RequestBody describes the request body, if any.
+		RequestBody *RequestBody `json:"requestBody,omitempty"`
+		// Responses is the list of possible responses indexed by HTTP status code.
+		Responses map[string]*OpenAPIResponse `json:"responses"`
+		// Callbacks is a map of possible out-of band callbacks related to the operation.
+		Callbacks map[string]Callback `json:"callbacks,omitempty"`
+		// Deprecated declares this operation to be deprecated.
+		Deprecated bool `json:"deprecated,omitempty"`
+		// Security is a declaration of which security schemes are applied for this operation.
+		Security []map[string][]string `json:"security,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIParameter describes a single operation parameter.
+	OpenAPIParameter struct {
+		// Name of the parameter. Parameter names are case sensitive.
+		Name string `json:"name"`
+		// In is the location of the parameter.
+		// Possible values are "query", "header", "path" or "cookie".
+		In string `json:"in"`
+		// Description is a brief description of the parameter.
+		Description string `json:"description,omitempty"`
+		// Required determines whether this parameter is mandatory.
+		Required bool `json:"required"`
+		// Style describes how the parameter value is serialized.
+		Style string `json:"style,omitempty"`
+		// Explode causes array values to generate separate parameters.
+		Explode bool `json:"explode,omitempty"`
+		// Schema defines the type used for the parameter.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+		// Example of the parameter value.
+		Example interface{} `json:"example,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// RequestBody describes a single request body.
+	RequestBody struct {
+		// Description is a brief description of the request body.
+		Description string `json:"description,omitempty"`
+		// Content maps the supported media types to their schemas.
+		Content map[string]*MediaType `json:"content"`
+		// Required determines if the request body is required in the request.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// MediaType provides the schema for the media type identified by its key.
+	MediaType struct {
+		// Schema defines the type used for the content.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// OpenAPIResponse describes a single response of an operation.
+	OpenAPIResponse struct {
+		// Description of the response.
+		Description string `json:"description"`
+		// Headers maps header names to their definitions.
+		Headers map[string]*OpenAPIHeader `json:"headers,omitempty"`
+		// Content maps the media types of the response body to their schemas.
+		Content map[string]*MediaType `json:"content,omitempty"`
+		// Links maps link names to operations that can be followed from the response.
+		Links map[string]*Link `json:"links,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OpenAPIHeader describes a response header.
+	OpenAPIHeader struct {
+		// Description is a brief description of the header.
+		Description string `json:"description,omitempty"`
+		// Schema defines the type used for the header.
+		Schema *genschema.JSONSchema `json:"schema,omitempty"`
+	}
+
+	// Link represents a possible design-time link for a response.
+	Link struct {
+		// OperationID is the name of the target operation.
+		OperationID string `json:"operationId"`
+		// Parameters maps the target operation parameters to runtime expressions.
+		Parameters map[string]interface{} `json:"parameters,omitempty"`
+		// Description of the link.
+		Description string `json:"description,omitempty"`
+	}
+
+	// Callback maps runtime expressions computing the callback URLs to the requests
+	// sent by the API.
+	Callback map[string]*PathItem
+
+	// SecurityScheme defines a security scheme that can be used by the operations.
+	SecurityScheme struct {
+		// Type of the security scheme. Valid values are "apiKey", "http" or "oauth2".
+		Type string `json:"type"`
+		// Description for security scheme.
+		Description string `json:"description,omitempty"`
+		// Name of the header, query or cookie parameter to be used when type is "apiKey".
+		Name string `json:"name,omitempty"`
+		// In is the location of the API key when type is "apiKey".
+		In string `json:"in,omitempty"`
+		// Scheme is the name of the HTTP Authorization scheme when type is "http".
+		Scheme string `json:"scheme,omitempty"`
+		// BearerFormat is a hint to the client to identify how the bearer token is formatted.
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		// Flows contains configuration information for the flow types supported.
+		Flows *OAuthFlows `json:"flows,omitempty"`
+		// Extensions defines the OpenAPI extensions.
+		Extensions map[string]interface{} `json:"-"`
+	}
+
+	// OAuthFlows allows configuration of the supported OAuth2 flows.
+	OAuthFlows struct {
+		Implicit          *OAuthFlow `json:"implicit,omitempty"`
+		Password          *OAuthFlow `json:"password,omitempty"`
+		ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+		AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	}
+
+	// OAuthFlow contains the configuration of a single OAuth2 flow.
+	OAuthFlow struct {
+		// AuthorizationURL is the authorization URL to be used for this flow.
+		AuthorizationURL string `json:"authorizationUrl,omitempty"`
+		// TokenURL is the token URL to be used for this flow.
+		TokenURL string `json:"tokenUrl,omitempty"`
+		// Scopes lists the available scopes for the OAuth2 security scheme.
+		Scopes map[string]string `json:"scopes"`
+	}
+
+	// These types are used in marshalJSON() to avoid recursive call of json.Marshal().
+	_PathItem         PathItem
+	_OpenAPIOperation OpenAPIOperation
+	_OpenAPIParameter OpenAPIParameter
+	_OpenAPIResponse  OpenAPIResponse
+	_SecurityScheme   SecurityScheme
+)
+
+// MarshalJSON returns the JSON encoding of p.
+func (p PathItem) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_PathItem(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of o.
+func (o OpenAPIOperation) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIOperation(o), o.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of p.
+func (p OpenAPIParameter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIParameter(p), p.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of r.
+func (r OpenAPIResponse) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_OpenAPIResponse(r), r.Extensions)
+}
+
+// MarshalJSON returns the JSON encoding of s.
+func (s SecurityScheme) MarshalJSON() ([]byte, error) {
+	return marshalJSON(_SecurityScheme(s), s.Extensions)
+}
+
+// NewV3 creates an OpenAPI 3.0 document from an API definition.
+func NewV3(api *design.APIDefinition) (*OpenAPI, error) {
+	if api == nil {
+		return nil, nil
+	}
+	basePath := api.BasePath
+	if hasAbsoluteRoutes(api) {
+		basePath = ""
+	}
+	o := &OpenAPI{
+		OpenAPI: "3.0.0",
+		Info: &Info{
+			Title:          api.Title,
+			Description:    api.Description,
+			TermsOfService: api.TermsOfService,
+			Contact:        api.Contact,
+			License:        api.License,
+			Version:        api.Version,
+			Extensions:     genschema.Extensions(api.Metadata),
+		},
+		Servers:      serversFromDefinition(api, basePath),
+		Paths:        make(map[string]interface{}),
+		Tags:         tagsFromDefinition(api.Metadata),
+		ExternalDocs: docsFromDefinition(api.Docs),
+	}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		for k, v := range genschema.Extensions(res.Metadata) {
+			o.Paths[k] = v
+		}
+		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			if !mustGenerate(fs.Metadata) {
+				return nil
+			}
+			return buildPathItemFromFileServer(o, api, fs)
+		})
+		if err != nil {
+			return err
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !mustGenerate(a.Metadata) {
+				return nil
+			}
+			for _, route := range a.Routes {
+				operation, err := operationFromDefinition(api, route, true)
+				if err != nil {
+					return err
+				}
+				for _, version := range pathVersions(api, a) {
+					op := operation
+					if version != "" {
+						versioned := *operation
+						versioned.OperationID = fmt.Sprintf("%s@%s", operation.OperationID, version)
+						op = &versioned
+					}
+					path := pathItem(o, pathKey(route.VersionedPath(version), basePath))
+					setOperation(path, route.Verb, op)
+					path.Extensions = genschema.Extensions(a.Metadata)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.Components = &Components{SecuritySchemes: securitySchemesFromDefinition(api.SecuritySchemes)}
+	if len(genschema.Definitions) > 0 {
+		o.Components.Schemas = make(map[string]*genschema.JSONSchema)
+		for n, d := range genschema.Definitions {
+			o.Components.Schemas[n] = schemaV3(d)
+		}
+	}
+	return o, nil
+}
+
+// serversFromDefinition returns the servers defined by the API host, schemes and base path.
+func serversFromDefinition(api *design.APIDefinition, basePath string) []*Server {
+	bp := pathKey(basePath, "")
+	if bp == "/" {
+		bp = ""
+	}
+	if api.Host == "" {
+		if bp == "" {
+			return nil
+		}
+		return []*Server{{URL: bp}}
+	}
+	schemes := api.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]*Server, len(schemes))
+	for i, s := range schemes {
+		servers[i] = &Server{URL: fmt.Sprintf("%s://%s%s", s, api.Host, bp)}
+	}
+	return servers
+}
+
+// pathKey converts the given path to the OpenAPI notation and makes it relative to the base
+// path.
+func pathKey(path, basePath string) string {
+	convert := func(p string) string {
+		return design.WildcardRegex.ReplaceAllStringFunc(p, func(w string) string {
+			return fmt.Sprintf("/{%s}", w[2:])
+		})
+	}
+	key := convert(path)
+	if bp := convert(basePath); bp != "/" {
+		key = strings.TrimPrefix(key, bp)
+	}
+	if key == "" {
+		key = "/"
+	}
+	return key
+}
+
+// pathItem returns the path item with the given key, creating it if needed.
+func pathItem(o *OpenAPI, key string) *PathItem {
+	if p, ok := o.Paths[key]; ok {
+		return p.(*PathItem)
+	}
+	p := new(PathItem)
+	o.Paths[key] = p
+	return p
+}
+
+// setOperation sets the operation for the given HTTP method on the path item.
+func setOperation(p *PathItem, verb string, operation *OpenAPIOperation) {
+	switch verb {
+	case "GET":
+		p.Get = operation
+	case "PUT":
+		p.Put = operation
+	case "POST":
+		p.Post = operation
+	case "DELETE":
+		p.Delete = operation
+	case "OPTIONS":
+		p.Options = operation
+	case "HEAD":
+		p.Head = operation
+	case "PATCH":
+		p.Patch = operation
+	}
+}
+
+func buildPathItemFromFileServer(o *OpenAPI, api *design.APIDefinition, fs *design.FileServerDefinition) error {
+	wcs := design.ExtractWildcards(fs.RequestPath)
+	var params []*OpenAPIParameter
+	if len(wcs) > 0 {
+		params = []*OpenAPIParameter{{
+			In:          "path",
+			Name:        wcs[0],
+			Description: "Relative file path",
+			Required:    true,
+			Schema:      &genschema.JSONSchema{Type: genschema.JSONString},
+		}}
+	}
+	responses := map[string]*OpenAPIResponse{
+		"200": {
+			Description: "File downloaded",
+			Content: map[string]*MediaType{
+				"application/octet-stream": {
+					Schema: &genschema.JSONSchema{Type: genschema.JSONString, Format: "binary"},
+				},
+			},
+		},
+	}
+	if len(wcs) > 0 {
+		responses["404"] = &OpenAPIResponse{
+			Description: "File not found",
+			Content:     contentFor(api, genschema.TypeSchema(api, design.ErrorMedia), design.ErrorMedia.Identifier),
+		}
+	}
+	operation := &OpenAPIOperation{
+		Description:  fs.Description,
+		Summary:      summaryFromDefinition(fmt.Sprintf("Download %s", fs.FilePath), fs.Metadata),
+		ExternalDocs: docsFromDefinition(fs.Docs),
+		OperationID:  fmt.Sprintf("%s#%s", fs.Parent.Name, fs.RequestPath),
+		Parameters:   params,
+		Responses:    responses,
+		Security:     securityRequirement(fs.Security),
+	}
+	p := pathItem(o, pathKey(fs.RequestPath, ""))
+	p.Get = operation
+	p.Extensions = genschema.Extensions(fs.Metadata)
+	return nil
+}
+
+// operationFromDefinition builds the operation corresponding to the given route. Callbacks are
+// only computed if withCallbacks is true so that operations used as callbacks do not recurse.
+func operationFromDefinition(api *design.APIDefinition, route *design.RouteDefinition, withCallbacks bool) (*OpenAPIOperation, error) {
+	action := route.Parent
+
+	tagNames := tagNamesFromDefinitions(action.Parent.Metadata, action.Metadata)
+	if len(tagNames) == 0 {
+		tagNames = []string{action.Parent.Name}
+	}
+
+	var params []*OpenAPIParameter
+	if ps := action.AllParams(); ps != nil {
+		obj := ps.Type.ToObject()
+		if obj == nil {
+			return nil, fmt.Errorf("invalid parameters definition, not an object")
+		}
+		wildcards := design.ExtractWildcards(route.FullPath())
+		obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+			in, required := "query", ps.IsRequired(n)
+			for _, w := range wildcards {
+				if n == w {
+					in, required = "path", true
+					break
+				}
+			}
+			params = append(params, parameterV3(api, at, n, in, required))
+			return nil
+		})
+	}
+	action.IterateHeaders(func(name string, required bool, header *design.AttributeDefinition) error {
+		params = append(params, parameterV3(api, header, name, "header", required))
+		return nil
+	})
+	if name, at := versionParam(api, action); at != nil {
+		params = append(params, parameterV3(api, at, name, "header", true))
+	}
+	if action.Cookies != nil {
+		action.Cookies.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+			params = append(params, parameterV3(api, at, n, "cookie", action.Cookies.IsRequired(n)))
+			return nil
+		})
+	}
+
+	var body *RequestBody
+	if action.Payload != nil {
+		body = &RequestBody{
+			Description: action.Payload.Description,
+			Required:    !action.PayloadOptional,
+		}
+		schema := genschema.TypeSchema(api, action.Payload)
+		if action.PayloadMultipart {
+			body.Content = map[string]*MediaType{"multipart/form-data": {Schema: schema}}
+		} else {
+			var mimes []string
+			for _, c := range api.Consumes {
+				mimes = append(mimes, c.MIMETypes...)
+			}
+			if len(mimes) == 0 {
+				mimes = []string{"application/json"}
+			}
+			body.Content = make(map[string]*MediaType, len(mimes))
+			for _, m := range mimes {
+				body.Content[m] = &MediaType{Schema: schema}
+			}
+		}
+	}
+
+	responses := make(map[string]*OpenAPIResponse, len(action.Responses))
+	for _, r := range action.Responses {
+		resp, err := responseV3(api, r)
+		if err != nil {
+			return nil, err
+		}
+		responses[strconv.Itoa(r.Status)] = resp
+	}
+	for _, e := range action.AllErrors() {
+		status := strconv.Itoa(e.Status)
+		if _, ok := responses[status]; ok {
+			continue
+		}
+		desc := e.Description
+		if desc == "" {
+			desc = e.Name
+		}
+		var identifier string
+		if mt, ok := e.Type.(*design.MediaTypeDefinition); ok {
+			identifier = mt.Identifier
+		}
+		responses[status] = &OpenAPIResponse{
+			Description: desc,
+			Content:     contentFor(api, genschema.TypeSchema(api, e.Type), identifier),
+		}
+	}
+
+	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	for i, rt := range action.Routes {
+		if rt == route && i > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, i)
+			break
+		}
+	}
+
+	operation := &OpenAPIOperation{
+		Tags:         tagNames,
+		Description:  action.Description,
+		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		ExternalDocs: docsFromDefinition(action.Docs),
+		OperationID:  operationID,
+		Parameters:   params,
+		RequestBody:  body,
+		Responses:    responses,
+		Security:     securityRequirement(action.Security),
+		Extensions:   genschema.Extensions(route.Metadata),
+	}
+	if withCallbacks {
+		callbacks, err := callbacksFromDefinition(api, action)
+		if err != nil {
+			return nil, err
+		}
+		operation.Callbacks = callbacks
+	}
+	return operation, nil
+}
+
+// parameterV3 builds the parameter with the given name and location from the attribute.
+func parameterV3(api *design.APIDefinition, at *design.AttributeDefinition, name, in string, required bool) *OpenAPIParameter {
+	p := &OpenAPIParameter{
+		Name:        name,
+		In:          in,
+		Description: at.Description,
+		Required:    required,
+		Schema:      attributeSchemaV3(api, at),
+		Extensions:  genschema.Extensions(at.Metadata),
+	}
+	if ex := at.GenerateExample(api.RandomGenerator(), nil); ex != "-" { // "-" is set by NoExample
+		p.Example = toStringMap(ex)
+	}
+	if at.Type.IsArray() && in == "query" {
+		p.Style = "form"
+		p.Explode = true
+	}
+	if at.Type.IsHash() && in == "query" {
+		// Map parameters use the "name[key]=value" syntax.
+		p.Style = "deepObject"
+		p.Explode = true
+	}
+	return p
+}
+
+// attributeSchemaV3 returns the schema of the given attribute including its validations.
+func attributeSchemaV3(api *design.APIDefinition, at *design.AttributeDefinition) *genschema.JSONSchema {
+	s := schemaV3(genschema.TypeSchema(api, at.Type))
+	if s.Ref != "" {
+		return s
+	}
+	s.DefaultValue = toStringMap(at.DefaultValue)
+	if scalar := at.Scalar(); scalar != "" {
+		s.Format = scalar
+	}
+	if val := at.Validation; val != nil {
+		s.Enum = val.Values
+		if val.Format != "" {
+			s.Format = val.Format
+		}
+		s.Pattern = val.Pattern
+		s.Minimum = val.Minimum
+		s.Maximum = val.Maximum
+		s.MinLength = val.MinLength
+		s.MaxLength = val.MaxLength
+	}
+	return s
+}
+
+// responseV3 builds the OpenAPI response corresponding to the given response definition.
+func responseV3(api *design.APIDefinition, r *design.ResponseDefinition) (*OpenAPIResponse, error) {
+	resp := &OpenAPIResponse{
+		Description: r.Description,
+		Links:       linksFromDefinition(r.Metadata),
+		Extensions:  genschema.Extensions(r.Metadata),
+	}
+	if resp.Description == "" {
+		resp.Description = r.Name
+	}
+	if r.MediaType != "" {
+		if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+			view := r.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			schema := genschema.NewJSONSchema()
+			schema.Ref = genschema.MediaTypeRef(api, mt, view)
+			resp.Content = contentFor(api, schema, r.MediaType)
+		}
+	}
+	if r.Headers != nil {
+		obj := r.Headers.Type.ToObject()
+		if obj == nil {
+			return nil, fmt.Errorf("invalid headers definition, not an object")
+		}
+		resp.Headers = make(map[string]*OpenAPIHeader, len(obj))
+		obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+			resp.Headers[n] = &OpenAPIHeader{
+				Description: at.Description,
+				Schema:      attributeSchemaV3(api, at),
+			}
+			return nil
+		})
+	}
+	return resp, nil
+}
+
+// contentFor returns the content of a response body with the given schema. The content lists
+// the media type identifier if any and all the MIME types produced by the API.
+func contentFor(api *design.APIDefinition, schema *genschema.JSONSchema, identifier string) map[string]*MediaType {
+	schema = schemaV3(schema)
+	content := make(map[string]*MediaType)
+	if identifier != "" {
+		content[design.CanonicalIdentifier(identifier)] = &MediaType{Schema: schema}
+	}
+	for _, p := range api.Produces {
+		for _, m := range p.MIMETypes {
+			content[m] = &MediaType{Schema: schema}
+		}
+	}
+	if len(content) == 0 {
+		content["application/json"] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+// linksFromDefinition returns the links defined with the "openapi:link:xxx" metadata.
+func linksFromDefinition(mdata dslengine.MetadataDefinition) map[string]*Link {
+	links := make(map[string]*Link)
+	for key, values := range mdata {
+		if !strings.HasPrefix(key, "openapi:link:") || len(values) == 0 {
+			continue
+		}
+		link := &Link{OperationID: values[0]}
+		for _, v := range values[1:] {
+			elems := strings.SplitN(v, "=", 2)
+			if len(elems) != 2 {
+				continue
+			}
+			if link.Parameters == nil {
+				link.Parameters = make(map[string]interface{})
+			}
+			link.Parameters[elems[0]] = elems[1]
+		}
+		links[key[len("openapi:link:"):]] = link
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
+
+// callbacksFromDefinition returns the callbacks defined with the "openapi:callback:xxx"
+// metadata. The metadata values are the runtime expression used to compute the callback URL
+// followed by the name of the resource and action describing the callback request in the form
+// "resource#action".
+func callbacksFromDefinition(api *design.APIDefinition, action *design.ActionDefinition) (map[string]Callback, error) {
+	var keys []string
+	for key := range action.Metadata {
+		if strings.HasPrefix(key, "openapi:callback:") {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	sort.Strings(keys)
+	callbacks := make(map[string]Callback, len(keys))
+	for _, key := range keys {
+		values := action.Metadata[key]
+		if len(values) != 2 {
+			return nil, fmt.Errorf(`invalid %s metadata of action %s, must define the callback URL expression and the "resource#action" target`, key, action.Name)
+		}
+		elems := strings.Split(values[1], "#")
+		var target *design.ActionDefinition
+		if len(elems) == 2 {
+			if res, ok := api.Resources[elems[0]]; ok {
+				target = res.Actions[elems[1]]
+			}
+		}
+		if target == nil || len(target.Routes) == 0 {
+			return nil, fmt.Errorf("invalid %s metadata of action %s, unknown action %q", key, action.Name, values[1])
+		}
+		route := target.Routes[0]
+		operation, err := operationFromDefinition(api, route, false)
+		if err != nil {
+			return nil, err
+		}
+		p := new(PathItem)
+		setOperation(p, route.Verb, operation)
+		callbacks[key[len("openapi:callback:"):]] = Callback{values[0]: p}
+	}
+	return callbacks, nil
+}
+
+// securityRequirement returns the security requirement of an operation.
+func securityRequirement(security *design.SecurityDefinition) []map[string][]string {
+	if security == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return nil
+	}
+	scopes := security.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+	return []map[string][]string{{security.Scheme.SchemeName: scopes}}
+}
+
+// securitySchemesFromDefinition returns the OpenAPI security schemes corresponding to the
+// design security schemes.
+func securitySchemesFromDefinition(schemes []*design.SecuritySchemeDefinition) map[string]*SecurityScheme {
+	if len(schemes) == 0 {
+		return nil
+	}
+	defs := make(map[string]*SecurityScheme)
+	for _, scheme := range schemes {
+		def := &SecurityScheme{
+			Description: scheme.Description,
+			Extensions:  genschema.Extensions(scheme.Metadata),
+		}
+		switch scheme.Kind {
+		case design.BasicAuthSecurityKind:
+			def.Type = "http"
+			def.Scheme = "basic"
+		case design.APIKeySecurityKind:
+			def.Type = "apiKey"
+			def.Name = scheme.Name
+			def.In = scheme.In
+		case design.JWTSecurityKind:
+			if scheme.In == "header" && scheme.Name == "Authorization" {
+				def.Type = "http"
+				def.Scheme = "bearer"
+				def.BearerFormat = "JWT"
+			} else {
+				def.Type = "apiKey"
+				def.Name = scheme.Name
+				def.In = scheme.In
+			}
+			if scheme.TokenURL != "" {
+				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", scheme.TokenURL)
+			}
+			if len(scheme.Scopes) != 0 {
+				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(scheme.Scopes))
+			}
+		case design.OAuth2SecurityKind:
+			def.Type = "oauth2"
+			scopes := scheme.Scopes
+			if scopes == nil {
+				scopes = make(map[string]string)
+			}
+			flow := &OAuthFlow{
+				AuthorizationURL: scheme.AuthorizationURL,
+				TokenURL:         scheme.TokenURL,
+				Scopes:           scopes,
+			}
+			def.Flows = &OAuthFlows{}
+			switch scheme.Flow {
+			case "implicit":
+				def.Flows.Implicit = flow
+			case "password":
+				def.Flows.Password = flow
+			case "application":
+				def.Flows.ClientCredentials = flow
+			case "accessCode":
+				def.Flows.AuthorizationCode = flow
+			}
+		default:
+			continue
+		}
+		defs[scheme.SchemeName] = def
+	}
+	return defs
+}
+
+// schemaV3 returns a copy of the given JSON schema suitable for OpenAPI 3.0: references point
+// to the document components and the hyper-schema fields are removed.
+func schemaV3(s *genschema.JSONSchema) *genschema.JSONSchema {
+	if s == nil {
+		return nil
+	}
+	js := *s
+	js.Media = nil
+	js.Links = nil
+	js.Definitions = nil
+	if strings.HasPrefix(js.Ref, "#/definitions/") {
+		js.Ref = "#/components/schemas/" + js.Ref[len("#/definitions/"):]
+	}
+	if js.Type == genschema.JSONFile {
+		js.Type = genschema.JSONString
+		js.Format = "binary"
+	}
+	js.Items = schemaV3(s.Items)
+	if s.AdditionalPropertiesSchema != nil {
+		js.AdditionalPropertiesSchema = schemaV3(s.AdditionalPropertiesSchema)
+	}
+	if len(s.Properties) > 0 {
+		js.Properties = make(map[string]*genschema.JSONSchema, len(s.Properties))
+		for n, p := range s.Properties {
+			js.Properties[n] = schemaV3(p)
+		}
+	} else {
+		js.Properties = nil
+	}
+	if len(s.AllOf) > 0 {
+		js.AllOf = make([]*genschema.JSONSchema, len(s.AllOf))
+		for i, a := range s.AllOf {
+			js.AllOf[i] = schemaV3(a)
+		}
+	}
+	if len(s.AnyOf) > 0 {
+		js.AnyOf = make([]*genschema.JSONSchema, len(s.AnyOf))
+		for i, a := range s.AnyOf {
+			js.AnyOf[i] = schemaV3(a)
+		}
+	}
+	if len(s.OneOf) > 0 {
+		js.OneOf = make([]*genschema.JSONSchema, len(s.OneOf))
+		for i, o := range s.OneOf {
+			js.OneOf[i] = schemaV3(o)
+		}
+	}
+	if s.Discriminator != nil {
+		d := *s.Discriminator
+		d.Mapping = make(map[string]string, len(s.Discriminator.Mapping))
+		for v, ref := range s.Discriminator.Mapping {
+			d.Mapping[v] = "#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/")
+		}
+		js.Discriminator = &d
+	}
+	return &js
+}
@@ -0,0 +1,215 @@
+package genswagger_test
+
+import (
+	"encoding/json"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
+	"github.com/goadesign/goa/goagen/gen_swagger"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewV3", func() {
+	var openapi *genswagger.OpenAPI
+	var newErr error
+
+	BeforeEach(func() {
+		openapi = nil
+		newErr = nil
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		openapi, newErr = genswagger.NewV3(Design)
+	})
+
+	Context("with a valid API definition", func() {
+		BeforeEach(func() {
+			API("test", func() {
+				Title("title")
+				Host("goa.design")
+				Scheme("https")
+				BasePath("/api")
+				Consumes("application/json")
+				Consumes("application/xml")
+				Produces("application/json")
+				Produces("application/xml")
+				JWTSecurity("jwt", func() {
+					Header("Authorization")
+				})
+				OAuth2Security("oauth2", func() {
+					AccessCodeFlow("/authorization", "/token")
+					Scope("read")
+				})
+			})
+			Bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			Resource("hooks", func() {
+				Action("rated", func() {
+					Routing(POST("/rated"))
+					Payload(Bottle)
+					Response(NoContent)
+				})
+			})
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Security("jwt")
+					Params(func() {
+						Param("id", Integer, func() {
+							Minimum(1)
+							Example(7)
+						})
+					})
+					Response(OK, Bottle, func() {
+						Metadata("openapi:link:Rate", "bottle#rate", "id=$request.path.id")
+					})
+				})
+				Action("rate", func() {
+					Routing(PUT("/:id/rate"))
+					Metadata("openapi:callback:onRated", "{$request.body#/callback}", "hooks#rated")
+					Payload(func() {
+						Member("rating", Integer)
+						Member("callback", String)
+						Required("rating")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("sets the basic fields", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.OpenAPI).Should(Equal("3.0.0"))
+			Ω(openapi.Info.Title).Should(Equal("title"))
+			Ω(openapi.Servers).Should(Equal([]*genswagger.Server{{URL: "https://goa.design/api"}}))
+		})
+
+		It("generates the schemas in the components", func() {
+			Ω(openapi.Components.Schemas).Should(HaveKey("Bottle"))
+			show := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Get
+			Ω(show.Parameters).Should(HaveLen(1))
+			Ω(show.Parameters[0].In).Should(Equal("path"))
+			Ω(*show.Parameters[0].Schema.Minimum).Should(Equal(1.0))
+			Ω(show.Parameters[0].Example).Should(Equal(7))
+			content := show.Responses["200"].Content
+			Ω(content).Should(HaveLen(3))
+			Ω(content).Should(HaveKey("application/vnd.bottle"))
+			Ω(content["application/xml"].Schema.Ref).Should(Equal("#/components/schemas/Bottle"))
+		})
+
+		It("generates the request bodies", func() {
+			rate := openapi.Paths["/bottles/{id}/rate"].(*genswagger.PathItem).Put
+			Ω(rate.RequestBody).ShouldNot(BeNil())
+			Ω(rate.RequestBody.Required).Should(BeTrue())
+			Ω(rate.RequestBody.Content).Should(HaveKey("application/json"))
+			Ω(rate.RequestBody.Content).Should(HaveKey("application/xml"))
+			Ω(rate.Parameters).Should(HaveLen(1))
+		})
+
+		It("generates the links and callbacks", func() {
+			show := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Get
+			Ω(show.Responses["200"].Links).Should(Equal(map[string]*genswagger.Link{
+				"Rate": {OperationID: "bottle#rate", Parameters: map[string]interface{}{"id": "$request.path.id"}},
+			}))
+			rate := openapi.Paths["/bottles/{id}/rate"].(*genswagger.PathItem).Put
+			Ω(rate.Callbacks).Should(HaveKey("onRated"))
+			cb := rate.Callbacks["onRated"]["{$request.body#/callback}"]
+			Ω(cb).ShouldNot(BeNil())
+			Ω(cb.Post).ShouldNot(BeNil())
+			Ω(cb.Post.OperationID).Should(Equal("hooks#rated"))
+		})
+
+		It("generates the security schemes", func() {
+			schemes := openapi.Components.SecuritySchemes
+			Ω(schemes["jwt"].Type).Should(Equal("http"))
+			Ω(schemes["jwt"].Scheme).Should(Equal("bearer"))
+			Ω(schemes["oauth2"].Flows.AuthorizationCode.TokenURL).Should(Equal("https://goa.design/token"))
+			show := openapi.Paths["/bottles/{id}"].(*genswagger.PathItem).Get
+			Ω(show.Security).Should(Equal([]map[string][]string{{"jwt": {}}}))
+		})
+	})
+
+	Context("with extensions", func() {
+		BeforeEach(func() {
+			ProjectedMediaTypes = make(MediaTypeRoot)
+			API("test", func() {
+				Metadata("openapi:extension:x-api", `{"foo":"bar"}`)
+			})
+			Bottle := MediaType("application/vnd.bottle", func() {
+				Metadata("openapi:extension:x-type", "bottle")
+				Attributes(func() {
+					Attribute("id", Integer, func() {
+						Metadata("openapi:extension:x-attribute", "true")
+					})
+				})
+				View("default", func() {
+					Attribute("id")
+				})
+			})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Metadata("openapi:extension:x-action", "show")
+					Routing(GET("/:id", func() {
+						Metadata("openapi:extension:x-amazon-apigateway-integration", `{"type":"http_proxy"}`)
+					}))
+					Response(OK, Bottle, func() {
+						Metadata("openapi:extension:x-response", "ok")
+					})
+				})
+			})
+		})
+
+		It("sets the extensions", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(openapi.Info.Extensions).Should(Equal(map[string]interface{}{"x-api": map[string]interface{}{"foo": "bar"}}))
+			path := openapi.Paths["/{id}"].(*genswagger.PathItem)
+			Ω(path.Extensions).Should(Equal(map[string]interface{}{"x-action": "show"}))
+			Ω(path.Get.Extensions).Should(Equal(map[string]interface{}{
+				"x-amazon-apigateway-integration": map[string]interface{}{"type": "http_proxy"},
+			}))
+			Ω(path.Get.Responses["200"].Extensions).Should(Equal(map[string]interface{}{"x-response": "ok"}))
+		})
+
+		It("sets the schema extensions", func() {
+			schema := openapi.Components.Schemas["Bottle"]
+			Ω(schema.Extensions).Should(Equal(map[string]interface{}{"x-type": "bottle"}))
+			Ω(schema.Properties["id"].Extensions).Should(Equal(map[string]interface{}{"x-attribute": true}))
+			b, err := json.Marshal(schema)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"x-type":"bottle"`))
+			Ω(string(b)).Should(ContainSubstring(`"x-attribute":true`))
+		})
+	})
+
+	Context("with an invalid callback", func() {
+		BeforeEach(func() {
+			Resource("bottle", func() {
+				Action("rate", func() {
+					Routing(PUT("/:id/rate"))
+					Metadata("openapi:callback:onRated", "{$request.body#/callback}", "hooks#unknown")
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(newErr).Should(HaveOccurred())
+		})
+	})
+})
@@ -18,3 +18,10 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//OpenAPI3 Generate an OpenAPI 3.0 document instead of Swagger 2.0
+func OpenAPI3(openapi3 bool) Option {
+	return func(g *Generator) {
+		g.OpenAPI3 = openapi3
+	}
+}
@@ -396,7 +396,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			Contact:        api.Contact,
 			License:        api.License,
 			Version:        api.Version,
-			Extensions:     extensionsFromDefinition(api.Metadata),
+			Extensions:     genschema.Extensions(api.Metadata),
 		},
 		Host:                api.Host,
 		BasePath:            basePath,
@@ -425,7 +425,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		return nil, err
 	}
 	err = api.IterateResources(func(res *design.ResourceDefinition) error {
-		for k, v := range extensionsFromDefinition(res.Metadata) {
+		for k, v := range genschema.Extensions(res.Metadata) {
 			s.Paths[k] = v
 		}
 		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
@@ -458,6 +458,9 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 			// sad but swagger doesn't support these
 			d.Media = nil
 			d.Links = nil
+			if len(d.OneOf) > 0 {
+				d = oneOfV2(d)
+			}
 			s.Definitions[n] = d
 		}
 	}
@@ -525,7 +528,14 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 			AuthorizationURL: scheme.AuthorizationURL,
 			TokenURL:         scheme.TokenURL,
 			Scopes:           scheme.Scopes,
-			Extensions:       extensionsFromDefinition(scheme.Metadata),
+			Extensions:       genschema.Extensions(scheme.Metadata),
+		}
+		if scheme.In == "cookie" {
+			// Swagger 2.0 does not support API keys in cookies, document the cookie in
+			// the description and use the Cookie header instead.
+			def.In = "header"
+			def.Name = "Cookie"
+			def.Description += fmt.Sprintf("\n\n**Cookie**: %s", scheme.Name)
 		}
 		if scheme.Kind == design.JWTSecurityKind {
 			if def.TokenURL != "" {
@@ -597,7 +607,7 @@ func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
 			tag.ExternalDocs = docs
 		}
 
-		tag.Extensions = extensionsFromDefinition(mdata)
+		tag.Extensions = genschema.Extensions(mdata)
 
 		tags = append(tags, tag)
 	}
@@ -624,33 +634,6 @@ func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) s
 	return name
 }
 
-func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
-	extensions := make(map[string]interface{})
-	for key, value := range mdata {
-		chunks := strings.Split(key, ":")
-		if len(chunks) != 3 {
-			continue
-		}
-		if chunks[0] != "swagger" || chunks[1] != "extension" {
-			continue
-		}
-		if strings.HasPrefix(chunks[2], "x-") != true {
-			continue
-		}
-		val := value[0]
-		ival := interface{}(val)
-		if err := json.Unmarshal([]byte(val), &ival); err != nil {
-			extensions[chunks[2]] = val
-			continue
-		}
-		extensions[chunks[2]] = ival
-	}
-	if len(extensions) == 0 {
-		return nil
-	}
-	return extensions
-}
-
 func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*Parameter, error) {
 	if params == nil {
 		return nil, nil
@@ -703,7 +686,16 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
 		p.CollectionFormat = "multi"
 	}
-	p.Extensions = extensionsFromDefinition(at.Metadata)
+	p.Extensions = genschema.Extensions(at.Metadata)
+	if at.Type.IsHash() {
+		// Swagger 2.0 does not support object parameters, describe the "name[key]=value"
+		// syntax with the OpenAPI 3 style.
+		p.Type = "string"
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]interface{})
+		}
+		p.Extensions["x-style"] = "deepObject"
+	}
 	initValidations(at, p)
 	return p
 }
@@ -773,7 +765,7 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 		Description: r.Description,
 		Schema:      schema,
 		Headers:     headers,
-		Extensions:  extensionsFromDefinition(r.Metadata),
+		Extensions:  genschema.Extensions(r.Metadata),
 	}, nil
 }
 
@@ -879,7 +871,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 	}
 	p := path.(*Path)
 	p.Get = operation
-	p.Extensions = extensionsFromDefinition(fs.Metadata)
+	p.Extensions = genschema.Extensions(fs.Metadata)
 
 	return nil
 }
@@ -898,6 +890,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	}
 
 	params = append(params, paramsFromHeaders(action)...)
+	if name, at := versionParam(api, action); at != nil {
+		params = append(params, paramFor(at, name, "header", true))
+	}
 
 	responses := make(map[string]*Response, len(action.Responses))
 	for _, r := range action.Responses {
@@ -907,8 +902,25 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
+	for _, e := range action.AllErrors() {
+		status := strconv.Itoa(e.Status)
+		if _, ok := responses[status]; ok {
+			continue
+		}
+		desc := e.Description
+		if desc == "" {
+			desc = e.Name
+		}
+		responses[status] = &Response{Description: desc, Schema: genschema.TypeSchema(api, e.Type)}
+	}
 
-	if action.Payload != nil {
+	if action.Payload != nil && action.PayloadMultipart {
+		payload := action.Payload.AttributeDefinition
+		payload.Type.ToObject().IterateAttributes(func(n string, at *design.AttributeDefinition) error {
+			params = append(params, paramFor(at, n, "formData", payload.IsRequired(n)))
+			return nil
+		})
+	} else if action.Payload != nil {
 		payloadSchema := genschema.TypeSchema(api, action.Payload)
 		pp := &Parameter{
 			Name:        "payload",
@@ -947,55 +959,103 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		Responses:    responses,
 		Schemes:      schemes,
 		Deprecated:   false,
-		Extensions:   extensionsFromDefinition(route.Metadata),
+		Extensions:   genschema.Extensions(route.Metadata),
+	}
+
+	if action.PayloadMultipart {
+		operation.Consumes = []string{"multipart/form-data"}
 	}
 
 	computeProduces(operation, s, action)
 	applySecurity(operation, action.Security)
 
-	key := design.WildcardRegex.ReplaceAllStringFunc(
-		route.FullPath(),
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
-	)
-	bp := design.WildcardRegex.ReplaceAllStringFunc(
-		basePath,
-		func(w string) string {
-			return fmt.Sprintf("/{%s}", w[2:])
-		},
+	// Path based versioning serves the operation under one path per version.
+	for _, version := range pathVersions(api, action) {
+		op := operation
+		if version != "" {
+			versioned := *operation
+			versioned.OperationID = fmt.Sprintf("%s@%s", operationID, version)
+			op = &versioned
+		}
+		key := design.WildcardRegex.ReplaceAllStringFunc(
+			route.VersionedPath(version),
+			func(w string) string {
+				return fmt.Sprintf("/{%s}", w[2:])
+			},
+		)
+		bp := design.WildcardRegex.ReplaceAllStringFunc(
+			basePath,
+			func(w string) string {
+				return fmt.Sprintf("/{%s}", w[2:])
+			},
+		)
+		if bp != "/" {
+			key = strings.TrimPrefix(key, bp)
+		}
+		if key == "" {
+			key = "/"
+		}
+		var path interface{}
+		var ok bool
+		if path, ok = s.Paths[key]; !ok {
+			path = new(Path)
+			s.Paths[key] = path
+		}
+		p := path.(*Path)
+		switch route.Verb {
+		case "GET":
+			p.Get = op
+		case "PUT":
+			p.Put = op
+		case "POST":
+			p.Post = op
+		case "DELETE":
+			p.Delete = op
+		case "OPTIONS":
+			p.Options = op
+		case "HEAD":
+			p.Head = op
+		case "PATCH":
+			p.Patch = op
+		}
+		p.Extensions = genschema.Extensions(route.Parent.Metadata)
+	}
+	return nil
+}
+
+// pathVersions returns the API versions that prefix the paths of the action routes. It returns a
+// single empty version if the API does not use path based versioning.
+func pathVersions(api *design.APIDefinition, action *design.ActionDefinition) []string {
+	if api.Versioning == nil || api.Versioning.Scheme != design.VersionSchemePath {
+		return []string{""}
+	}
+	return action.EffectiveVersions()
+}
+
+// versionParam returns the name and the attribute of the request header that selects the API
+// version when the API uses header or media type based versioning, nil otherwise. The attribute
+// enumerates the header values that select the versions serving the action.
+func versionParam(api *design.APIDefinition, action *design.ActionDefinition) (string, *design.AttributeDefinition) {
+	var (
+		name   string
+		values []interface{}
 	)
-	if bp != "/" {
-		key = strings.TrimPrefix(key, bp)
+	for _, v := range action.EffectiveVersions() {
+		n, value := api.Versioning.RequestHeader(v)
+		if n == "" {
+			return "", nil
+		}
+		name = n
+		values = append(values, value)
 	}
-	if key == "" {
-		key = "/"
+	if name == "" {
+		return "", nil
 	}
-	var path interface{}
-	var ok bool
-	if path, ok = s.Paths[key]; !ok {
-		path = new(Path)
-		s.Paths[key] = path
+	return name, &design.AttributeDefinition{
+		Type:        design.String,
+		Description: "API version",
+		Validation:  &dslengine.ValidationDefinition{Values: values},
 	}
-	p := path.(*Path)
-	switch route.Verb {
-	case "GET":
-		p.Get = operation
-	case "PUT":
-		p.Put = operation
-	case "POST":
-		p.Post = operation
-	case "DELETE":
-		p.Delete = operation
-	case "OPTIONS":
-		p.Options = operation
-	case "HEAD":
-		p.Head = operation
-	case "PATCH":
-		p.Patch = operation
-	}
-	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
-	return nil
 }
 
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
@@ -1160,12 +1220,17 @@ func initMaxLengthValidation(def interface{}, isArray bool, max *int) {
 }
 
 func initValidations(attr *design.AttributeDefinition, def interface{}) {
+	if scalar := attr.Scalar(); scalar != "" {
+		initFormatValidation(def, scalar)
+	}
 	val := attr.Validation
 	if val == nil {
 		return
 	}
 	initEnumValidation(def, val.Values)
-	initFormatValidation(def, val.Format)
+	if val.Format != "" || attr.Scalar() == "" {
+		initFormatValidation(def, val.Format)
+	}
 	initPatternValidation(def, val.Pattern)
 	if val.Minimum != nil {
 		initMinimumValidation(def, val.Minimum)
@@ -1180,3 +1245,21 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 		initMaxLengthValidation(def, attr.Type.IsArray(), val.MaxLength)
 	}
 }
+
+// oneOfV2 returns a copy of the given union schema suitable for Swagger 2.0 which supports neither
+// oneOf nor discriminator objects: the variant references are listed in the "x-oneOf" extension.
+func oneOfV2(s *genschema.JSONSchema) *genschema.JSONSchema {
+	js := *s
+	refs := make([]string, len(s.OneOf))
+	for i, o := range s.OneOf {
+		refs[i] = o.Ref
+	}
+	js.Extensions = make(map[string]interface{}, len(s.Extensions)+1)
+	for k, v := range s.Extensions {
+		js.Extensions[k] = v
+	}
+	js.Extensions["x-oneOf"] = refs
+	js.OneOf = nil
+	js.Discriminator = nil
+	return &js
+}
@@ -123,7 +123,7 @@ var _ = Describe("New", func() {
 				BasePath: basePath,
 				Schemes:  []string{"https"},
 				Paths:    make(map[string]interface{}),
-				Consumes: []string{"application/json", "application/xml", "application/gob", "application/x-gob"},
+				Consumes: []string{"application/json", "application/xml", "application/gob", "application/x-gob", "application/x-www-form-urlencoded"},
 				Produces: []string{"application/json", "application/xml", "application/gob", "application/x-gob"},
 				Tags: []*genswagger.Tag{{Name: tag, Description: "Tag desc.", ExternalDocs: &genswagger.ExternalDocs{
 					URL: "http://example.com/tag", Description: "Huge docs",
@@ -598,6 +598,55 @@ var _ = Describe("New", func() {
 			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 		})
 
+		Context("with versions", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("act", func() {
+						Routing(GET("/:id"))
+						Params(func() {
+							Param("id", Integer)
+						})
+						Response(NoContent)
+					})
+				})
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					Versions("v1", "v2")
+				}
+			})
+
+			It("sets the paths of each version", func() {
+				Ω(swagger.Paths).Should(HaveLen(2))
+				Ω(swagger.Paths["/v1/{id}"].(*genswagger.Path).Get.OperationID).Should(Equal("res#act@v1"))
+				Ω(swagger.Paths["/v2/{id}"].(*genswagger.Path).Get.OperationID).Should(Equal("res#act@v2"))
+			})
+
+			It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+
+			Context("using a header", func() {
+				BeforeEach(func() {
+					base := Design.DSLFunc
+					Design.DSLFunc = func() {
+						base()
+						VersionHeader("X-API-Version")
+					}
+				})
+
+				It("sets the version header parameter", func() {
+					Ω(swagger.Paths).Should(HaveLen(1))
+					params := swagger.Paths["/{id}"].(*genswagger.Path).Get.Parameters
+					version := params[len(params)-1]
+					Ω(version.Name).Should(Equal("X-API-Version"))
+					Ω(version.In).Should(Equal("header"))
+					Ω(version.Required).Should(BeTrue())
+					Ω(version.Enum).Should(Equal([]interface{}{"v1", "v2"}))
+				})
+
+				It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
+			})
+		})
+
 		Context("with metadata", func() {
 			const gat = "gat"
 			const extension = `{"foo":"bar"}`
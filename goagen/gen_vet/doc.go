@@ -0,0 +1,23 @@
+/*
+Package genvet implements the goagen vet command which analyzes the design for common mistakes.
+
+The analysis applies the following rules:
+
+  - route-collision: two actions have routes with the same verb and path.
+  - missing-description: an attribute of a type, media type, action payload or parameters has
+    no description.
+  - missing-error: a response with a 4xx status does not define the error media type.
+  - unused-type: a type or media type is not used by any action.
+  - payload-param-conflict: an action payload attribute has the same name as one of the action
+    path or query string parameters.
+
+Each rule reports issues with a severity that defaults to "error" for route-collision and to
+"warning" for the other rules. The severities may be changed with the --severity flag which
+accepts a comma separated list of rule=severity pairs where severity is one of "error",
+"warning" or "off", for example:
+
+	goagen vet -d github.com/foo/bar/design --severity missing-description=off,unused-type=error
+
+The issues are printed on stderr, the command fails if any issue has the "error" severity.
+*/
+package genvet
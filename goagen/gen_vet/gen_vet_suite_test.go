@@ -0,0 +1,13 @@
+package genvet_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenVet(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenVet Suite")
+}
@@ -0,0 +1,107 @@
+package genvet
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+//NewGenerator returns an initialized instance of a design linter
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design linter, it does not generate files.
+type Generator struct {
+	API        *design.APIDefinition // The API definition
+	Severities map[string]Severity   // Severities of the rules overriding the defaults
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var severity, ver string
+	set := flag.NewFlagSet("vet", flag.PanicOnError)
+	set.String("out", "", "")
+	set.String("design", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&severity, "severity", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	severities, err := ParseSeverities(severity)
+	if err != nil {
+		return nil, err
+	}
+	g := &Generator{API: design.Design, Severities: severities}
+
+	return g.Generate()
+}
+
+// Generate analyzes the design and prints the issues on stderr. It returns an error if any issue
+// has the error severity.
+func (g *Generator) Generate() ([]string, error) {
+	return nil, g.vet(os.Stderr)
+}
+
+// vet analyzes the design and writes the issues to w.
+func (g *Generator) vet(w io.Writer) error {
+	if g.API == nil {
+		return fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	issues := Vet(g.API, g.Severities)
+	var errs int
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+		if issue.Severity == SeverityError {
+			errs++
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("design has %d error(s)", errs)
+	}
+	return nil
+}
+
+// Cleanup does nothing as the linter does not generate files.
+func (g *Generator) Cleanup() {}
+
+// ParseSeverities parses the value of the --severity flag: a comma separated list of
+// rule=severity pairs.
+func ParseSeverities(val string) (map[string]Severity, error) {
+	severities := make(map[string]Severity)
+	for _, pair := range strings.Split(val, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		elems := strings.SplitN(pair, "=", 2)
+		if len(elems) != 2 {
+			return nil, fmt.Errorf("invalid severity %#v, must be of the form rule=severity", pair)
+		}
+		rule, sev := strings.TrimSpace(elems[0]), Severity(strings.TrimSpace(elems[1]))
+		if _, ok := DefaultSeverities[rule]; !ok {
+			return nil, fmt.Errorf("unknown rule %#v, must be one of %s", rule, strings.Join(Rules(), ", "))
+		}
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityOff:
+		default:
+			return nil, fmt.Errorf("invalid severity %#v for rule %s, must be one of error, warning or off", sev, rule)
+		}
+		severities[rule] = sev
+	}
+	return severities, nil
+}
@@ -0,0 +1,20 @@
+package genvet
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//Severities Severities of the rules indexed by rule name, overrides the defaults
+func Severities(severities map[string]Severity) Option {
+	return func(g *Generator) {
+		g.Severities = severities
+	}
+}
@@ -0,0 +1,256 @@
+package genvet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+)
+
+// Severity is the severity of an issue.
+type Severity string
+
+const (
+	// SeverityError is the severity of issues that make the vet command fail.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of issues that are reported without failing.
+	SeverityWarning Severity = "warning"
+	// SeverityOff disables a rule.
+	SeverityOff Severity = "off"
+)
+
+// Rule names.
+const (
+	RouteCollision       = "route-collision"
+	MissingDescription   = "missing-description"
+	MissingError         = "missing-error"
+	UnusedType           = "unused-type"
+	PayloadParamConflict = "payload-param-conflict"
+)
+
+// DefaultSeverities lists the default severity of each rule.
+var DefaultSeverities = map[string]Severity{
+	RouteCollision:       SeverityError,
+	MissingDescription:   SeverityWarning,
+	MissingError:         SeverityWarning,
+	UnusedType:           SeverityWarning,
+	PayloadParamConflict: SeverityWarning,
+}
+
+// Issue is a problem found in the design.
+type Issue struct {
+	// Rule is the name of the rule that reported the issue.
+	Rule string
+	// Severity is the severity of the issue.
+	Severity Severity
+	// Message describes the issue.
+	Message string
+}
+
+// String returns the issue formatted as "severity: rule: message".
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Rule, i.Message)
+}
+
+// Rules returns the sorted names of the rules.
+func Rules() []string {
+	rules := make([]string, 0, len(DefaultSeverities))
+	for r := range DefaultSeverities {
+		rules = append(rules, r)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+// Vet analyzes the API design and returns the issues found sorted by rule and message. severities
+// overrides the default severity of the rules, the rules whose severity is "off" are not run.
+func Vet(api *design.APIDefinition, severities map[string]Severity) []*Issue {
+	checks := map[string]func(*design.APIDefinition) []string{
+		RouteCollision:       routeCollisions,
+		MissingDescription:   missingDescriptions,
+		MissingError:         missingErrors,
+		UnusedType:           unusedTypes,
+		PayloadParamConflict: payloadParamConflicts,
+	}
+	var issues []*Issue
+	for _, rule := range Rules() {
+		sev, ok := severities[rule]
+		if !ok {
+			sev = DefaultSeverities[rule]
+		}
+		if sev == SeverityOff {
+			continue
+		}
+		msgs := checks[rule](api)
+		sort.Strings(msgs)
+		for _, msg := range msgs {
+			issues = append(issues, &Issue{Rule: rule, Severity: sev, Message: msg})
+		}
+	}
+	return issues
+}
+
+// routeCollisions reports the routes that have the same verb and path, wildcards being equivalent
+// whatever their name.
+func routeCollisions(api *design.APIDefinition) []string {
+	var msgs []string
+	routes := make(map[string]*design.RouteDefinition)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for _, r := range a.Routes {
+				key := r.Verb + " " + design.WildcardRegex.ReplaceAllString(r.FullPath(), "/*")
+				if other, ok := routes[key]; ok {
+					msgs = append(msgs, fmt.Sprintf("%s %s of action %s of resource %s collides with %s %s of action %s of resource %s",
+						r.Verb, r.FullPath(), a.Name, res.Name,
+						other.Verb, other.FullPath(), other.Parent.Name, other.Parent.Parent.Name))
+					continue
+				}
+				routes[key] = r
+			}
+			return nil
+		})
+	})
+	return msgs
+}
+
+// missingDescriptions reports the attributes of the types, media types, action payloads and
+// parameters that have no description.
+func missingDescriptions(api *design.APIDefinition) []string {
+	var msgs []string
+	var check func(context, prefix string, att *design.AttributeDefinition)
+	check = func(context, prefix string, att *design.AttributeDefinition) {
+		if att == nil {
+			return
+		}
+		obj, ok := att.Type.(design.Object)
+		if !ok {
+			return
+		}
+		for n, child := range obj {
+			if child.Description == "" {
+				msgs = append(msgs, fmt.Sprintf("attribute %s of %s has no description", prefix+n, context))
+			}
+			check(context, prefix+n+".", child)
+		}
+	}
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		check("type "+ut.TypeName, "", ut.AttributeDefinition)
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.Identifier != design.ErrorMediaIdentifier {
+			check("media type "+mt.Identifier, "", mt.AttributeDefinition)
+		}
+		return nil
+	})
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			context := fmt.Sprintf("action %s of resource %s", a.Name, res.Name)
+			check(context+" parameters", "", a.Params)
+			if a.Payload != nil && api.Types[a.Payload.TypeName] == nil {
+				check(context+" payload", "", a.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
+	return msgs
+}
+
+// missingErrors reports the responses with a 4xx status that do not define a media type.
+func missingErrors(api *design.APIDefinition) []string {
+	var msgs []string
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			return a.IterateResponses(func(r *design.ResponseDefinition) error {
+				if r.Status >= 400 && r.Status < 500 && r.MediaType == "" && r.Type == nil {
+					msgs = append(msgs, fmt.Sprintf("response %s (%d) of action %s of resource %s does not define the error media type",
+						r.Name, r.Status, a.Name, res.Name))
+				}
+				return nil
+			})
+		})
+	})
+	return msgs
+}
+
+// unusedTypes reports the types and media types that are not used by any action, directly or
+// through other types.
+func unusedTypes(api *design.APIDefinition) []string {
+	used := make(map[string]bool)
+	use := func(dt design.DataType) {
+		if dt == nil {
+			return
+		}
+		for n := range design.UserTypes(dt) {
+			used[n] = true
+		}
+	}
+	useMediaType := func(id string) {
+		if id == "" {
+			return
+		}
+		if mt := api.MediaTypeWithIdentifier(id); mt != nil {
+			use(mt)
+		}
+	}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		useMediaType(res.MediaType)
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				use(a.Payload)
+			}
+			for _, att := range []*design.AttributeDefinition{a.Params, a.Headers} {
+				if att != nil {
+					use(att.Type)
+				}
+			}
+			return a.IterateResponses(func(r *design.ResponseDefinition) error {
+				useMediaType(r.MediaType)
+				use(r.Type)
+				return nil
+			})
+		})
+	})
+	var msgs []string
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if !used[ut.TypeName] {
+			msgs = append(msgs, fmt.Sprintf("type %s is not used by any action", ut.TypeName))
+		}
+		return nil
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if !used[mt.TypeName] && mt.Identifier != design.ErrorMediaIdentifier {
+			msgs = append(msgs, fmt.Sprintf("media type %s is not used by any action", mt.Identifier))
+		}
+		return nil
+	})
+	return msgs
+}
+
+// payloadParamConflicts reports the action payload attributes that have the same name as an
+// action path or query string parameter.
+func payloadParamConflicts(api *design.APIDefinition) []string {
+	var msgs []string
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.Params == nil || a.Params.Type == nil {
+				return nil
+			}
+			payload := a.Payload.ToObject()
+			params := a.Params.Type.ToObject()
+			var names []string
+			for n := range payload {
+				if _, ok := params[n]; ok {
+					names = append(names, n)
+				}
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				msgs = append(msgs, fmt.Sprintf("attribute %s of action %s of resource %s is both a payload attribute and a parameter",
+					n, a.Name, res.Name))
+			}
+			return nil
+		})
+	})
+	return msgs
+}
@@ -0,0 +1,155 @@
+package genvet_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_vet"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vet", func() {
+	var severities map[string]genvet.Severity
+	var issues []*genvet.Issue
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		severities = nil
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).Should(Succeed())
+		issues = genvet.Vet(Design, severities)
+	})
+
+	messages := func(rule string) []string {
+		var msgs []string
+		for _, i := range issues {
+			if i.Rule == rule {
+				msgs = append(msgs, i.Message)
+			}
+		}
+		return msgs
+	}
+
+	Context("with a clean design", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+					})
+					Response(OK)
+					Response(NotFound, ErrorMedia)
+				})
+			})
+		})
+
+		It("reports no issue", func() {
+			Ω(issues).Should(BeEmpty())
+		})
+	})
+
+	Context("with mistakes", func() {
+		BeforeEach(func() {
+			API("test", func() {})
+			Type("Unused", func() {
+				Attribute("name", String, "Name")
+			})
+			Type("Used", func() {
+				Attribute("name", String)
+			})
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+					})
+					Response(OK)
+					Response(NotFound)
+				})
+				Action("get", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+					})
+					Response(OK)
+				})
+				Action("update", func() {
+					Routing(PUT("/:id"))
+					Params(func() {
+						Param("id", Integer, "Bottle ID")
+						Param("name", String, "Name")
+					})
+					Payload(func() {
+						Member("name", String, "Name")
+						Member("used", "Used", "Used")
+					})
+					Response(NoContent)
+				})
+			})
+		})
+
+		It("reports the issues", func() {
+			Ω(messages(genvet.RouteCollision)).Should(Equal([]string{
+				"GET /:id of action show of resource bottle collides with GET /:id of action get of resource bottle",
+			}))
+			Ω(messages(genvet.MissingDescription)).Should(Equal([]string{
+				"attribute name of type Used has no description",
+			}))
+			Ω(messages(genvet.MissingError)).Should(Equal([]string{
+				"response NotFound (404) of action show of resource bottle does not define the error media type",
+			}))
+			Ω(messages(genvet.UnusedType)).Should(Equal([]string{
+				"type Unused is not used by any action",
+			}))
+			Ω(messages(genvet.PayloadParamConflict)).Should(Equal([]string{
+				"attribute name of action update of resource bottle is both a payload attribute and a parameter",
+			}))
+			Ω(issues[0].String()).Should(HavePrefix("warning: missing-description: "))
+		})
+
+		Context("with custom severities", func() {
+			BeforeEach(func() {
+				severities = map[string]genvet.Severity{
+					genvet.MissingDescription: genvet.SeverityOff,
+					genvet.UnusedType:         genvet.SeverityError,
+				}
+			})
+
+			It("applies the severities", func() {
+				Ω(messages(genvet.MissingDescription)).Should(BeEmpty())
+				for _, i := range issues {
+					if i.Rule == genvet.UnusedType || i.Rule == genvet.RouteCollision {
+						Ω(i.Severity).Should(Equal(genvet.SeverityError))
+					} else {
+						Ω(i.Severity).Should(Equal(genvet.SeverityWarning))
+					}
+				}
+			})
+		})
+	})
+})
+
+var _ = Describe("ParseSeverities", func() {
+	It("parses rule=severity pairs", func() {
+		sev, err := genvet.ParseSeverities("unused-type=error, missing-description=off")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(sev).Should(Equal(map[string]genvet.Severity{
+			genvet.UnusedType:         genvet.SeverityError,
+			genvet.MissingDescription: genvet.SeverityOff,
+		}))
+	})
+
+	It("rejects unknown rules and severities", func() {
+		_, err := genvet.ParseSeverities("foo=error")
+		Ω(err).Should(HaveOccurred())
+		_, err = genvet.ParseSeverities("unused-type=fatal")
+		Ω(err).Should(HaveOccurred())
+		_, err = genvet.ParseSeverities("unused-type")
+		Ω(err).Should(HaveOccurred())
+	})
+})
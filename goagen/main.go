@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_design"
 	"github.com/goadesign/goa/goagen/meta"
 	"github.com/goadesign/goa/goagen/utils"
 	"github.com/goadesign/goa/version"
@@ -41,12 +42,24 @@ controllers supporting code and main skeleton code (if not already present) as w
 package and tool and the Swagger specification for the API.
 `}
 	var (
-		designPkg string
-		debug     bool
+		designPkg   string
+		templates   string
+		plugins     []string
+		incremental bool
+		debug       bool
 	)
 
-	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory")
+	rootCmd.PersistentFlags().StringP("out", "o", ".", "output directory, also accepted as --output. The import paths of the generated packages are inferred from the go.mod file of the enclosing module if any, from GOPATH otherwise")
+	rootCmd.SetGlobalNormalizationFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "output" {
+			name = "out"
+		}
+		return pflag.NormalizedName(name)
+	})
 	rootCmd.PersistentFlags().StringVarP(&designPkg, "design", "d", "", "design package import path")
+	rootCmd.PersistentFlags().StringVar(&templates, "templates", "", "directory containing templates overriding the built-in templates")
+	rootCmd.PersistentFlags().StringSliceVar(&plugins, "plugin", nil, "import path of a generator plugin package, may be repeated")
+	rootCmd.PersistentFlags().BoolVar(&incremental, "incremental", false, "only rewrite the generated files whose content changed and report the added, updated and removed files")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug mode, does not cleanup temporary files.")
 
 	// versionCmd implements the "version" command
@@ -61,8 +74,8 @@ package and tool and the Swagger specification for the API.
 
 	// appCmd implements the "app" command.
 	var (
-		pkg    string
-		notest bool
+		pkg                 string
+		notest, mocks, fuzz bool
 	)
 	appCmd := &cobra.Command{
 		Use:   "app",
@@ -71,11 +84,13 @@ package and tool and the Swagger specification for the API.
 	}
 	appCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
 	appCmd.Flags().BoolVar(&notest, "notest", false, "Prevent generation of test helpers")
+	appCmd.Flags().BoolVar(&mocks, "mocks", false, "Generate a mocks package with mock implementations of the controller interfaces")
+	appCmd.Flags().BoolVar(&fuzz, "fuzz", false, "Generate fuzz targets for the context loaders and payload decoders (requires Go 1.18)")
 	rootCmd.AddCommand(appCmd)
 
 	// mainCmd implements the "main" command.
 	var (
-		force, regen bool
+		force, regen, metrics bool
 	)
 	mainCmd := &cobra.Command{
 		Use:   "main",
@@ -84,6 +99,7 @@ package and tool and the Swagger specification for the API.
 	}
 	mainCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
 	mainCmd.Flags().BoolVar(&regen, "regen", false, "regenerate scaffolding, maintaining controller implementations")
+	mainCmd.Flags().BoolVar(&metrics, "metrics", false, "mount the Prometheus metrics middleware and the /metrics endpoint in main")
 	rootCmd.AddCommand(mainCmd)
 
 	// clientCmd implements the "client" command.
@@ -103,11 +119,15 @@ package and tool and the Swagger specification for the API.
 	rootCmd.AddCommand(clientCmd)
 
 	// swaggerCmd implements the "swagger" command.
+	var (
+		openapi3 bool
+	)
 	swaggerCmd := &cobra.Command{
 		Use:   "swagger",
 		Short: "Generate Swagger",
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("genswagger", c) },
 	}
+	swaggerCmd.Flags().BoolVar(&openapi3, "openapi3", false, "Generate an OpenAPI 3.0 document instead of Swagger 2.0")
 	rootCmd.AddCommand(swaggerCmd)
 
 	// jsCmd implements the "js" command.
@@ -135,6 +155,24 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// importCmd implements the "import" command.
+	var (
+		spec      string
+		designOut string
+	)
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Generate a design package from an OpenAPI document or .proto file",
+		Long: `The import command reads a Swagger 2.0 or OpenAPI 3.0 document (JSON or YAML) and generates
+a design package describing its types, resources, actions and responses. Files with the .proto
+extension are read as protocol buffers definitions and produce a type for each message. The
+generated design is a starting point that should be reviewed and completed.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = runImport(c, spec, designOut) },
+	}
+	importCmd.Flags().StringVar(&spec, "spec", "", "path to the OpenAPI document or .proto file to import")
+	importCmd.Flags().StringVar(&designOut, "pkg", "design", "name of the generated design `package`")
+	rootCmd.AddCommand(importCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
@@ -199,6 +237,72 @@ package and tool and the Swagger specification for the API.
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// exampleCmd implements the "example" command.
+	var (
+		diff bool
+	)
+	exampleCmd := &cobra.Command{
+		Use:   "example",
+		Short: "Generate a buildable example implementation of the API",
+		Long: `Generate a main.go file and one controller file per resource with dummy implementations
+of the actions in the "example" directory. Existing files are never overwritten so the command
+may be run again to create the controllers of the resources added to the design. The --diff
+flag prints the new functions, the changed signatures and the differences between the existing
+files and the files that would be generated without writing them.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genexample", c) },
+	}
+	exampleCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
+	exampleCmd.Flags().BoolVar(&diff, "diff", false, "print the differences between the existing files and the files that would be generated instead of generating")
+	rootCmd.AddCommand(exampleCmd)
+
+	// vetCmd implements the "vet" command.
+	var (
+		severity string
+	)
+	vetCmd := &cobra.Command{
+		Use:   "vet",
+		Short: "Analyze the design for common mistakes",
+		Long: `Analyze the design for common mistakes: routes that collide, attributes without
+descriptions, 4xx responses without error media type, unused types and payload attributes that
+are also parameters. The issues are printed on stderr, the command fails if any issue has the
+"error" severity.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genvet", c) },
+	}
+	vetCmd.Flags().StringVar(&severity, "severity", "", "comma separated list of `rule=severity` pairs overriding the default severities, severity is one of error, warning or off")
+	rootCmd.AddCommand(vetCmd)
+
+	// diffCmd implements the "diff" command.
+	var (
+		base string
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Report the breaking changes made to the design",
+		Long: `Compare the design with the OpenAPI document describing the base version of the API
+and report the changes that break existing clients: removed endpoints, type changes, newly
+required parameters and attributes, removed response attributes and status codes. The command
+fails if there is any breaking change.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("gendiff", c) },
+	}
+	diffCmd.Flags().StringVar(&base, "base", "", "path to the Swagger 2.0 or OpenAPI 3.0 `file` describing the base version of the API")
+	rootCmd.AddCommand(diffCmd)
+
+	// pactCmd implements the "pact" command.
+	var (
+		consumer string
+	)
+	pactCmd := &cobra.Command{
+		Use:   "pact",
+		Short: "Generate Pact consumer contracts",
+		Long: `Generate a Pact consumer contract with one interaction per action route and response built
+from the design examples. The contract is written to pact/<consumer>-<api>.json with the names
+snake cased and can be given to a Pact provider verifier to check a deployed service against the
+design.`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genpact", c) },
+	}
+	pactCmd.Flags().StringVar(&consumer, "consumer", "", "`name` of the consumer of the contract, defaults to the API name followed by \"-client\"")
+	rootCmd.AddCommand(pactCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -271,6 +375,22 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 	return generate(pkgName, pkgPath, c, args)
 }
 
+func runImport(c *cobra.Command, spec, pkg string) ([]string, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("missing --spec flag")
+	}
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	gen := gendesign.NewGenerator(
+		gendesign.Spec(spec),
+		gendesign.OutDir(out),
+		gendesign.Pkg(pkg),
+	)
+	return gen.Generate()
+}
+
 func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
@@ -287,6 +407,11 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	if t, ok := m["templates"]; ok {
+		if m["templates"], err = filepath.Abs(t); err != nil {
+			return nil, err
+		}
+	}
 
 	gen, err := meta.NewGenerator(
 		pkgName+".Generate",
@@ -353,11 +478,11 @@ func flagJSON(fl *pflag.Flag) *flag {
 	f := &flag{Long: fl.Name, Short: fl.Shorthand, Description: fl.Usage}
 	f.Required = fl.Name == "pkg-path" || fl.Name == "design"
 	switch fl.Name {
-	case "out":
+	case "out", "templates":
 		f.Argument = "$DIR"
 	case "design":
 		f.Argument = "$DESIGN_PKG"
-	case "pkg-path":
+	case "pkg-path", "plugin":
 		f.Argument = "$PKG"
 	}
 	return f
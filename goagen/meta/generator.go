@@ -1,6 +1,7 @@
 package meta
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -43,6 +44,17 @@ type Generator struct {
 	// DesignPkgPath is the Go import path to the design package.
 	DesignPkgPath string
 
+	// Plugins lists the import paths of the plugin packages compiled in the generator.
+	Plugins []string
+
+	// TemplateDir is the path to the directory containing the templates that override the
+	// built-in templates if any.
+	TemplateDir string
+
+	// Incremental is true if the generated files whose content does not change must not be
+	// rewritten, see codegen.Snapshot.
+	Incremental bool
+
 	debug bool
 }
 
@@ -51,7 +63,9 @@ type Generator struct {
 func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[string]string, customflags []string) (*Generator, error) {
 	var (
 		outDir, designPkgPath string
-		debug                 bool
+		templateDir           string
+		plugins               []string
+		incremental, debug    bool
 	)
 
 	if o, ok := flags["out"]; ok {
@@ -60,6 +74,23 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 	if d, ok := flags["design"]; ok {
 		designPkgPath = d
 	}
+	if t, ok := flags["templates"]; ok {
+		templateDir = t
+	}
+	if p, ok := flags["plugin"]; ok {
+		for _, path := range strings.Split(strings.Trim(p, "[]"), ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				plugins = append(plugins, path)
+			}
+		}
+	}
+	if i, ok := flags["incremental"]; ok {
+		var err error
+		incremental, err = strconv.ParseBool(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse incremental flag: %s", err)
+		}
+	}
 	if d, ok := flags["debug"]; ok {
 		var err error
 		debug, err = strconv.ParseBool(d)
@@ -75,6 +106,9 @@ func NewGenerator(genfunc string, imports []*codegen.ImportSpec, flags map[strin
 		CustomFlags:   customflags,
 		OutDir:        outDir,
 		DesignPkgPath: designPkgPath,
+		Plugins:       plugins,
+		TemplateDir:   templateDir,
+		Incremental:   incremental,
 		debug:         debug,
 	}, nil
 }
@@ -91,6 +125,11 @@ func (m *Generator) Generate() ([]string, error) {
 	if m.DesignPkgPath == "" {
 		return nil, fmt.Errorf("missing design package flag")
 	}
+	if m.TemplateDir != "" {
+		if info, err := os.Stat(m.TemplateDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf(`invalid templates directory "%s"`, m.TemplateDir)
+		}
+	}
 
 	// Create output directory
 	if err := os.MkdirAll(m.OutDir, 0755); err != nil {
@@ -156,8 +195,15 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("github.com/goadesign/goa/dslengine"),
+		codegen.SimpleImport("github.com/goadesign/goa/goagen/codegen"),
 		codegen.NewImport("_", filepath.ToSlash(m.DesignPkgPath)),
 	)
+	if m.Incremental {
+		imports = append(imports, codegen.SimpleImport("os"))
+	}
+	for _, p := range m.Plugins {
+		imports = append(imports, codegen.NewImport("_", p))
+	}
 	file.WriteHeader("Code Generator", "main", imports)
 	tmpl, err := template.New("generator").Parse(mainTmpl)
 	if err != nil {
@@ -167,10 +213,13 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 	if err != nil {
 		panic(err)
 	}
-	context := map[string]string{
+	context := map[string]interface{}{
 		"Genfunc":       m.Genfunc,
 		"DesignPackage": m.DesignPkgPath,
 		"PkgName":       pkgName,
+		"OutDir":        m.OutDir,
+		"TemplateDir":   m.TemplateDir,
+		"Incremental":   m.Incremental,
 	}
 	if err := tmpl.Execute(file, context); err != nil {
 		panic(err) // bug
@@ -182,7 +231,7 @@ func (m *Generator) generateToolSourceCode(pkg *codegen.Package) {
 func (m *Generator) spawn(genbin string) ([]string, error) {
 	var args []string
 	for k, v := range m.Flags {
-		if k == "debug" {
+		if k == "debug" || k == "plugin" || k == "templates" || k == "incremental" {
 			continue
 		}
 		args = append(args, fmt.Sprintf("--%s=%s", k, v))
@@ -191,11 +240,15 @@ func (m *Generator) spawn(genbin string) ([]string, error) {
 	args = append(args, "--version="+version.String())
 	args = append(args, m.CustomFlags...)
 	cmd := exec.Command(genbin, args...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("%s\n%s", err, string(out))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s\n%s%s", err, stderr.String(), stdout.String())
 	}
-	res := strings.Split(string(out), "\n")
+	// The generator reports the incremental generation summary on stderr.
+	os.Stderr.Write(stderr.Bytes())
+	res := strings.Split(stdout.String(), "\n")
 	for (len(res) > 0) && (res[len(res)-1] == "") {
 		res = res[:len(res)-1]
 	}
@@ -204,15 +257,40 @@ func (m *Generator) spawn(genbin string) ([]string, error) {
 
 const mainTmpl = `
 func main() {
+{{- if .TemplateDir }}
+	// Override the built-in templates
+	codegen.RegisterPlugin(codegen.TemplateDir({{ printf "%q" .TemplateDir }}))
+{{ end }}
 	// Check if there were errors while running the first DSL pass
 	dslengine.FailOnError(dslengine.Errors)
 
 	// Now run the secondary DSLs
 	dslengine.FailOnError(dslengine.Run())
 
+	// Let the plugins inspect the design
+	dslengine.FailOnError(codegen.PreparePlugins())
+{{- if .Incremental }}
+
+	// Record the state of the files produced by the previous run, the files whose content
+	// does not change are not rewritten
+	snapshot, err := codegen.NewSnapshot({{printf "%q" .OutDir}}, {{printf "%q" .Genfunc}})
+	dslengine.FailOnError(err)
+{{- end }}
+
 	files, err := {{.Genfunc}}()
 	dslengine.FailOnError(err)
 
+	// Let the plugins contribute files
+	files, err = codegen.GeneratePlugins({{printf "%q" .Genfunc}}, {{printf "%q" .OutDir}}, files)
+	dslengine.FailOnError(err)
+{{- if .Incremental }}
+
+	// Delete the files not generated anymore and record the checksums of the generated files
+	summary, err := snapshot.Update(files)
+	dslengine.FailOnError(err)
+	fmt.Fprintln(os.Stderr, summary)
+{{- end }}
+
 	// We're done
 	fmt.Println(strings.Join(files, "\n"))
 }`
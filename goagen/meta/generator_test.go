@@ -305,3 +305,47 @@ func Generate() ([]string, error) {
 }
 `
 )
+
+var _ = Describe("NewGenerator", func() {
+	var flags map[string]string
+	var m *meta.Generator
+
+	JustBeforeEach(func() {
+		var err error
+		m, err = meta.NewGenerator("gen.Generate", nil, flags, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("with plugins", func() {
+		BeforeEach(func() {
+			flags = map[string]string{"out": "/out", "design": "design", "plugin": "[example.com/foo,example.com/bar]"}
+		})
+
+		It("records the plugin import paths", func() {
+			Ω(m.Plugins).Should(Equal([]string{"example.com/foo", "example.com/bar"}))
+			Ω(m.OutDir).Should(Equal("/out"))
+			Ω(m.DesignPkgPath).Should(Equal("design"))
+		})
+	})
+
+	Context("with a templates directory", func() {
+		BeforeEach(func() {
+			flags = map[string]string{"out": "/out", "design": "design", "templates": "/templates"}
+		})
+
+		It("records the templates directory", func() {
+			Ω(m.TemplateDir).Should(Equal("/templates"))
+			Ω(m.Plugins).Should(BeEmpty())
+		})
+	})
+
+	Context("with incremental generation", func() {
+		BeforeEach(func() {
+			flags = map[string]string{"out": "/out", "design": "design", "incremental": "true"}
+		})
+
+		It("enables incremental generation", func() {
+			Ω(m.Incremental).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package goatest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+)
+
+// Server is a test HTTP server that handles the requests with the mux of a goa service. The
+// helpers generated in the "test" package of the application build the requests sent to the
+// server and decode the responses.
+type Server struct {
+	*httptest.Server
+	// Service is the service whose controllers handle the requests.
+	Service *goa.Service
+	// ContentType is the content type used to encode the request payloads,
+	// "application/json" by default.
+	ContentType string
+}
+
+// NewServer starts a Server that handles the requests with the service handler. The controllers
+// must be mounted on the service before requests are sent. Close stops the server.
+func NewServer(service *goa.Service) *Server {
+	return &Server{
+		Server:      httptest.NewServer(service.Server.Handler),
+		Service:     service,
+		ContentType: "application/json",
+	}
+}
+
+// NewRequest creates a request with the given method, path and query string sent to the server.
+// The request body is the payload encoded with the service encoder unless the payload is nil or
+// an io.Reader in which case it is used as is.
+func (s *Server) NewRequest(method, path string, query url.Values, payload interface{}) (*http.Request, error) {
+	u := s.URL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var (
+		body        io.Reader
+		contentType string
+	)
+	switch p := payload.(type) {
+	case nil:
+	case io.Reader:
+		body = p
+	default:
+		var buf bytes.Buffer
+		if err := s.Service.Encoder.Encode(payload, &buf, s.ContentType); err != nil {
+			return nil, err
+		}
+		body, contentType = &buf, s.ContentType
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// Do sends the request to the server and checks that the response status is the given status.
+// Do decodes the response body into v using the service decoder and closes it unless v is nil in
+// which case the body is left for the caller to read and close.
+func (s *Server) Do(req *http.Request, status int, v interface{}) (*http.Response, error) {
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != status {
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, fmt.Errorf("invalid response status code: got %d, expected %d: %s", resp.StatusCode, status, bytes.TrimSpace(body))
+	}
+	if v == nil {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if err := s.Service.Decoder.Decode(v, resp.Body, resp.Header.Get("Content-Type")); err != nil {
+		return resp, fmt.Errorf("failed to decode response body: %s", err)
+	}
+	return resp, nil
+}
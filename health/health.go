@@ -0,0 +1,175 @@
+/*
+Package health provides the liveness and readiness probes mounted by the code generated for APIs
+that use the HealthCheck DSL.
+
+The liveness probe always responds with 200 OK as long as the service is able to handle requests.
+The readiness probe runs the registered checkers concurrently and responds with 200 OK if all the
+checks succeed or with 503 Service Unavailable otherwise. Both probes respond with a JSON
+document describing the status of the service and of each check:
+
+	{"status": "unavailable", "checks": {"db": "ok", "billing": "connection refused"}}
+*/
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// StatusOK is the status of healthy services and checks.
+	StatusOK = "ok"
+	// StatusUnavailable is the status of services that are not ready to handle requests.
+	StatusUnavailable = "unavailable"
+)
+
+// Timeout is the maximum duration of the checks run by the readiness probe.
+var Timeout = 5 * time.Second
+
+type (
+	// Checker checks the health of a dependency of the service.
+	Checker interface {
+		// Name is the name of the dependency reported in the readiness probe response.
+		Name() string
+		// Check returns an error if the dependency is not available.
+		Check(context.Context) error
+	}
+
+	// Pinger is the interface implemented by clients that can check the connection to a
+	// server, for example *sql.DB.
+	Pinger interface {
+		PingContext(context.Context) error
+	}
+
+	// Response is the body of the probe responses.
+	Response struct {
+		// Status is StatusOK or StatusUnavailable.
+		Status string `json:"status"`
+		// Checks contains the result of each check indexed by checker name, StatusOK if
+		// the check succeeded or the check error message otherwise.
+		Checks map[string]string `json:"checks,omitempty"`
+	}
+
+	// checker is the Checker implementation returned by NewChecker.
+	checker struct {
+		name  string
+		check func(context.Context) error
+	}
+)
+
+// NewChecker returns a checker with the given name that runs the given function.
+func NewChecker(name string, check func(context.Context) error) Checker {
+	return &checker{name: name, check: check}
+}
+
+// Ping returns a checker that pings the server p connects to, for example a database.
+func Ping(name string, p Pinger) Checker {
+	return NewChecker(name, p.PingContext)
+}
+
+// Downstream returns a checker that sends a GET request to the given URL, typically the
+// readiness probe of another service, and fails if the response status is not 2xx.
+func Downstream(name, u string) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%s responded with status %d", u, resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// Mount mounts the liveness probe under livePath and the readiness probe under readyPath on the
+// service mux. The probes are not controller actions so that the service and controller
+// middlewares (security, logging, tracing etc.) do not apply to them. Mount does not mount a
+// probe if its path is empty.
+func Mount(service *goa.Service, livePath, readyPath string, checkers ...Checker) {
+	mount := func(path string, h http.Handler) {
+		if path == "" {
+			return
+		}
+		service.Mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			h.ServeHTTP(rw, req)
+		})
+	}
+	mount(livePath, LiveHandler())
+	mount(readyPath, ReadyHandler(checkers...))
+}
+
+// LiveHandler returns the handler of the liveness probe.
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		respond(rw, &Response{Status: StatusOK})
+	})
+}
+
+// ReadyHandler returns the handler of the readiness probe that runs the given checkers.
+func ReadyHandler(checkers ...Checker) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		respond(rw, Check(req.Context(), checkers...))
+	})
+}
+
+// Check runs the given checkers concurrently and returns the resulting response. The checks are
+// canceled after Timeout.
+func Check(ctx context.Context, checkers ...Checker) *Response {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	var (
+		res = &Response{Status: StatusOK, Checks: make(map[string]string, len(checkers))}
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+	)
+	for _, c := range checkers {
+		wg.Add(1)
+		go func(c Checker) {
+			defer wg.Done()
+			status := StatusOK
+			if err := c.Check(ctx); err != nil {
+				status = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[c.Name()] = status
+			if status != StatusOK {
+				res.Status = StatusUnavailable
+			}
+		}(c)
+	}
+	wg.Wait()
+	return res
+}
+
+// Name returns the checker name.
+func (c *checker) Name() string { return c.name }
+
+// Check runs the check function.
+func (c *checker) Check(ctx context.Context) error { return c.check(ctx) }
+
+// respond writes the probe response.
+func respond(rw http.ResponseWriter, res *Response) {
+	status := http.StatusOK
+	if res.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(status)
+	json.NewEncoder(rw).Encode(res)
+}
@@ -0,0 +1,75 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/health"
+)
+
+type pinger struct{ err error }
+
+func (p *pinger) PingContext(context.Context) error { return p.err }
+
+func TestMount(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/readyz" {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer downstream.Close()
+
+	cases := map[string]struct {
+		Path     string
+		Checkers []health.Checker
+		Status   int
+		Response health.Response
+	}{
+		"live": {"/healthz", []health.Checker{health.Ping("db", &pinger{errors.New("down")})},
+			http.StatusOK, health.Response{Status: "ok"}},
+		"ready": {"/readyz", []health.Checker{health.Ping("db", &pinger{}), health.Downstream("billing", downstream.URL+"/readyz")},
+			http.StatusOK, health.Response{Status: "ok", Checks: map[string]string{"db": "ok", "billing": "ok"}}},
+		"not ready": {"/readyz", []health.Checker{health.Ping("db", &pinger{errors.New("down")}), health.Downstream("billing", downstream.URL+"/other")},
+			http.StatusServiceUnavailable, health.Response{Status: "unavailable", Checks: map[string]string{
+				"db":      "down",
+				"billing": downstream.URL + "/other responded with status 503",
+			}}},
+	}
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			service := goa.New("test")
+			service.Use(func(goa.Handler) goa.Handler {
+				t.Error("service middleware applied to probe")
+				return nil
+			})
+			health.Mount(service, "/healthz", "/readyz", c.Checkers...)
+
+			rw := httptest.NewRecorder()
+			service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", c.Path, nil))
+
+			if rw.Code != c.Status {
+				t.Errorf("got status %d, expected %d", rw.Code, c.Status)
+			}
+			var res health.Response
+			if err := json.Unmarshal(rw.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Status != c.Response.Status {
+				t.Errorf("got status %q, expected %q", res.Status, c.Response.Status)
+			}
+			if len(res.Checks) != len(c.Response.Checks) {
+				t.Errorf("got checks %v, expected %v", res.Checks, c.Response.Checks)
+			}
+			for n, s := range c.Response.Checks {
+				if res.Checks[n] != s {
+					t.Errorf("got check %s status %q, expected %q", n, res.Checks[n], s)
+				}
+			}
+		})
+	}
+}
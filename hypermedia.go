@@ -0,0 +1,161 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONAPIDocument is the top level document of JSON:API responses. The code generated by goagen
+// wraps the bodies of responses whose media type uses the JSON:API hypermedia format in a
+// JSONAPIDocument. See http://jsonapi.org/format/#document-top-level.
+type JSONAPIDocument struct {
+	// Data contains the primary data of the document: a resource or a list of resources.
+	Data interface{} `json:"data"`
+}
+
+// MarshalHAL returns the HAL encoding of v, see
+// https://tools.ietf.org/html/draft-kelly-json-hal. MarshalHAL first encodes v to JSON then turns
+// the "href" member into the "self" link and the members of the "links" object into the other
+// links of the "_links" object. Members that are themselves HAL resources - that is objects or
+// lists of objects with a "_links" member - are moved to the "_embedded" object.
+func MarshalHAL(v interface{}) ([]byte, error) {
+	m, err := jsonObject(v)
+	if err != nil || m == nil {
+		return json.Marshal(v)
+	}
+	links := make(map[string]interface{})
+	if href, ok := m["href"]; ok {
+		links["self"] = map[string]interface{}{"href": href}
+		delete(m, "href")
+	}
+	if l, ok := m["links"].(map[string]interface{}); ok {
+		for rel, link := range l {
+			if link != nil {
+				links[rel] = halLink(link)
+			}
+		}
+		delete(m, "links")
+	}
+	embedded := make(map[string]interface{})
+	for n, val := range m {
+		if isHALResource(val) {
+			embedded[n] = val
+			delete(m, n)
+		}
+	}
+	if len(links) > 0 {
+		m["_links"] = links
+	}
+	if len(embedded) > 0 {
+		m["_embedded"] = embedded
+	}
+	return json.Marshal(m)
+}
+
+// MarshalJSONAPI returns the JSON:API resource object encoding of v using typ as resource type,
+// see http://jsonapi.org/format/#document-resource-objects. MarshalJSONAPI first encodes v to
+// JSON then uses the "id" member as resource identifier, the "href" member as "self" link and
+// the members of the "links" object as relationships. The other members are the resource
+// attributes.
+func MarshalJSONAPI(v interface{}, typ string) ([]byte, error) {
+	m, err := jsonObject(v)
+	if err != nil || m == nil {
+		return json.Marshal(v)
+	}
+	res := map[string]interface{}{"type": typ}
+	if id, ok := m["id"]; ok {
+		res["id"] = fmt.Sprintf("%v", id)
+		delete(m, "id")
+	}
+	if href, ok := m["href"]; ok {
+		res["links"] = map[string]interface{}{"self": href}
+		delete(m, "href")
+	}
+	if l, ok := m["links"].(map[string]interface{}); ok {
+		rels := make(map[string]interface{}, len(l))
+		for rel, link := range l {
+			if link == nil {
+				continue
+			}
+			if list, ok := link.([]interface{}); ok {
+				hrefs := make([]interface{}, len(list))
+				for i, elem := range list {
+					hrefs[i] = linkHref(elem)
+				}
+				rels[rel] = map[string]interface{}{"meta": map[string]interface{}{"links": hrefs}}
+				continue
+			}
+			rels[rel] = map[string]interface{}{"links": map[string]interface{}{"related": linkHref(link)}}
+		}
+		res["relationships"] = rels
+		delete(m, "links")
+	}
+	if len(m) > 0 {
+		res["attributes"] = m
+	}
+	return json.Marshal(res)
+}
+
+// jsonObject returns the JSON object v encodes to, nil if v does not encode to a JSON object.
+func jsonObject(v interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// halLink returns the HAL link object or list of link objects for the given rendered link.
+func halLink(link interface{}) interface{} {
+	if list, ok := link.([]interface{}); ok {
+		links := make([]interface{}, len(list))
+		for i, elem := range list {
+			links[i] = map[string]interface{}{"href": linkHref(elem)}
+		}
+		return links
+	}
+	return map[string]interface{}{"href": linkHref(link)}
+}
+
+// linkHref returns the value of the "href" member of the rendered link or the href of its self
+// link if the link is itself a HAL resource, the link itself otherwise.
+func linkHref(link interface{}) interface{} {
+	if o, ok := link.(map[string]interface{}); ok {
+		if href, ok := o["href"]; ok {
+			return href
+		}
+		if links, ok := o["_links"].(map[string]interface{}); ok {
+			if self, ok := links["self"].(map[string]interface{}); ok {
+				return self["href"]
+			}
+		}
+	}
+	return link
+}
+
+// isHALResource returns true if v is a HAL resource or a non empty list of HAL resources.
+func isHALResource(v interface{}) bool {
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		_, ok := actual["_links"]
+		return ok
+	case []interface{}:
+		if len(actual) == 0 {
+			return false
+		}
+		for _, elem := range actual {
+			if !isHALResource(elem) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
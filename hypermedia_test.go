@@ -0,0 +1,97 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type hmAccount struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type hmBottleLinks struct {
+	Account *hmAccount   `json:"account"`
+	Tags    []*hmAccount `json:"tags"`
+}
+
+type hmBottle struct {
+	ID    int            `json:"id"`
+	Href  string         `json:"href"`
+	Name  string         `json:"name"`
+	Links *hmBottleLinks `json:"links"`
+}
+
+var _ = Describe("MarshalHAL", func() {
+	It("renders the links", func() {
+		b, err := goa.MarshalHAL(&hmBottle{
+			ID:   1,
+			Href: "/bottles/1",
+			Name: "n",
+			Links: &hmBottleLinks{
+				Account: &hmAccount{Href: "/accounts/1"},
+				Tags:    []*hmAccount{{Href: "/tags/1"}},
+			},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`{
+			"id": 1,
+			"name": "n",
+			"_links": {
+				"self": {"href": "/bottles/1"},
+				"account": {"href": "/accounts/1"},
+				"tags": [{"href": "/tags/1"}]
+			}
+		}`))
+	})
+
+	It("moves embedded resources", func() {
+		b, err := goa.MarshalHAL(map[string]interface{}{
+			"href":  "/bottles/1",
+			"owner": map[string]interface{}{"_links": map[string]interface{}{"self": map[string]interface{}{"href": "/accounts/1"}}},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`{
+			"_links": {"self": {"href": "/bottles/1"}},
+			"_embedded": {"owner": {"_links": {"self": {"href": "/accounts/1"}}}}
+		}`))
+	})
+
+	It("renders links to HAL resources", func() {
+		b, err := goa.MarshalHAL(map[string]interface{}{
+			"links": map[string]interface{}{
+				"account": map[string]interface{}{"_links": map[string]interface{}{"self": map[string]interface{}{"href": "/accounts/1"}}},
+			},
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`{"_links": {"account": {"href": "/accounts/1"}}}`))
+	})
+
+	It("leaves values that are not objects untouched", func() {
+		b, err := goa.MarshalHAL([]int{1, 2})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`[1, 2]`))
+	})
+})
+
+var _ = Describe("MarshalJSONAPI", func() {
+	It("renders a resource object", func() {
+		b, err := goa.MarshalJSONAPI(&hmBottle{
+			ID:    1,
+			Href:  "/bottles/1",
+			Name:  "n",
+			Links: &hmBottleLinks{Account: &hmAccount{Href: "/accounts/1"}},
+		}, "bottles")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(b).Should(MatchJSON(`{
+			"type": "bottles",
+			"id": "1",
+			"links": {"self": "/bottles/1"},
+			"relationships": {
+				"account": {"links": {"related": "/accounts/1"}}
+			},
+			"attributes": {"name": "n"}
+		}`))
+	})
+})
@@ -0,0 +1,140 @@
+package goa
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/goadesign/goa/uuid"
+)
+
+// Job statuses reported by the job status endpoints generated for the actions that use the Async
+// DSL.
+const (
+	// JobPending is the status of jobs that have not started yet.
+	JobPending = "pending"
+	// JobRunning is the status of jobs in progress.
+	JobRunning = "running"
+	// JobSucceeded is the status of jobs that completed successfully.
+	JobSucceeded = "succeeded"
+	// JobFailed is the status of jobs that failed.
+	JobFailed = "failed"
+)
+
+type (
+	// Job describes the status of a long running job started by an asynchronous action.
+	Job struct {
+		// ID is the job unique identifier.
+		ID string
+		// Status is one of JobPending, JobRunning, JobSucceeded or JobFailed.
+		Status string
+		// Progress is the completion percentage of the job.
+		Progress int
+		// Message describes the current step of the job or why it failed.
+		Message string
+	}
+
+	// JobReporter is the interface used by the services to report the progress of the jobs
+	// started by asynchronous actions.
+	JobReporter interface {
+		// Progress records the completion percentage of a running job and a message
+		// describing its current step.
+		Progress(ctx context.Context, id string, percent int, msg string) error
+		// Complete marks a job as succeeded.
+		Complete(ctx context.Context, id string) error
+		// Fail marks a job as failed with the given error.
+		Fail(ctx context.Context, id string, err error) error
+	}
+
+	// JobStore keeps track of the jobs started by asynchronous actions. The actions create
+	// the jobs and respond with their status, the job status actions retrieve them.
+	// Implementations must be safe for concurrent use.
+	JobStore interface {
+		JobReporter
+		// Create creates a pending job with a new unique ID.
+		Create(ctx context.Context) (*Job, error)
+		// Job returns the job with the given ID or an error of class ErrNotFound.
+		Job(ctx context.Context, id string) (*Job, error)
+	}
+
+	// memoryJobStore is the JobStore returned by NewMemoryJobStore.
+	memoryJobStore struct {
+		mu   sync.Mutex
+		jobs map[string]*Job
+	}
+)
+
+// NewMemoryJobStore returns a JobStore that keeps the jobs in memory. The jobs are not shared
+// between service instances and are never discarded.
+func NewMemoryJobStore() JobStore {
+	return &memoryJobStore{jobs: make(map[string]*Job)}
+}
+
+// JobHref returns the URL path of the status endpoint of the job with the given ID started by the
+// given request. The generated code sets the Location header of the 202 Accepted responses of the
+// asynchronous actions to this path.
+func JobHref(req *http.Request, id string) string {
+	return strings.TrimSuffix(req.URL.Path, "/") + "/jobs/" + url.PathEscape(id)
+}
+
+// Create creates a pending job.
+func (s *memoryJobStore) Create(context.Context) (*Job, error) {
+	j := &Job{ID: uuid.NewV4().String(), Status: JobPending}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[j.ID] = j
+	c := *j
+	return &c, nil
+}
+
+// Job returns a copy of the job with the given ID.
+func (s *memoryJobStore) Job(_ context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound("job not found", "id", id)
+	}
+	c := *j
+	return &c, nil
+}
+
+// Progress marks the job as running and records its progress.
+func (s *memoryJobStore) Progress(_ context.Context, id string, percent int, msg string) error {
+	return s.update(id, func(j *Job) {
+		j.Status = JobRunning
+		j.Progress = percent
+		j.Message = msg
+	})
+}
+
+// Complete marks the job as succeeded.
+func (s *memoryJobStore) Complete(_ context.Context, id string) error {
+	return s.update(id, func(j *Job) {
+		j.Status = JobSucceeded
+		j.Progress = 100
+		j.Message = ""
+	})
+}
+
+// Fail marks the job as failed.
+func (s *memoryJobStore) Fail(_ context.Context, id string, err error) error {
+	return s.update(id, func(j *Job) {
+		j.Status = JobFailed
+		j.Message = err.Error()
+	})
+}
+
+// update applies fn to the job with the given ID.
+func (s *memoryJobStore) update(id string, fn func(*Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return ErrNotFound("job not found", "id", id)
+	}
+	fn(j)
+	return nil
+}
@@ -0,0 +1,62 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryJobStore", func() {
+	var (
+		store goa.JobStore
+		ctx   context.Context
+	)
+
+	BeforeEach(func() {
+		store = goa.NewMemoryJobStore()
+		ctx = context.Background()
+	})
+
+	It("tracks the progress of the jobs", func() {
+		j, err := store.Create(ctx)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(j.ID).ShouldNot(BeEmpty())
+		Ω(j.Status).Should(Equal(goa.JobPending))
+
+		Ω(store.Progress(ctx, j.ID, 40, "exporting")).ShouldNot(HaveOccurred())
+		j, err = store.Job(ctx, j.ID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(*j).Should(Equal(goa.Job{ID: j.ID, Status: goa.JobRunning, Progress: 40, Message: "exporting"}))
+
+		Ω(store.Complete(ctx, j.ID)).ShouldNot(HaveOccurred())
+		j, _ = store.Job(ctx, j.ID)
+		Ω(j.Status).Should(Equal(goa.JobSucceeded))
+		Ω(j.Progress).Should(Equal(100))
+	})
+
+	It("records the failures", func() {
+		j, _ := store.Create(ctx)
+		Ω(store.Fail(ctx, j.ID, errors.New("disk full"))).ShouldNot(HaveOccurred())
+		j, _ = store.Job(ctx, j.ID)
+		Ω(j.Status).Should(Equal(goa.JobFailed))
+		Ω(j.Message).Should(Equal("disk full"))
+	})
+
+	It("returns not found errors for unknown jobs", func() {
+		_, err := store.Job(ctx, "unknown")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(404))
+		Ω(store.Progress(ctx, "unknown", 10, "")).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("JobHref", func() {
+	It("appends the job path to the request path", func() {
+		req := httptest.NewRequest("POST", "/bottles/1/export/", nil)
+		Ω(goa.JobHref(req, "abc")).Should(Equal("/bottles/1/export/jobs/abc"))
+	})
+})
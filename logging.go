@@ -25,6 +25,17 @@ type (
 		New(keyvals ...interface{}) LogAdapter
 	}
 
+	// LeveledLogAdapter is implemented by the logger adapters that support the debug and
+	// warning levels on top of the informational and error levels. LogDebug and LogWarn fall
+	// back to Info for adapters that do not implement it.
+	LeveledLogAdapter interface {
+		LogAdapter
+		// Debug logs a debug message.
+		Debug(msg string, keyvals ...interface{})
+		// Warn logs a warning.
+		Warn(msg string, keyvals ...interface{})
+	}
+
 	// adapter is the stdlib logger adapter.
 	adapter struct {
 		*log.Logger
@@ -46,12 +57,20 @@ func Logger(ctx context.Context) *log.Logger {
 	return nil
 }
 
+func (a *adapter) Debug(msg string, keyvals ...interface{}) {
+	a.logit(msg, keyvals, "DBUG")
+}
+
 func (a *adapter) Info(msg string, keyvals ...interface{}) {
-	a.logit(msg, keyvals, false)
+	a.logit(msg, keyvals, "INFO")
+}
+
+func (a *adapter) Warn(msg string, keyvals ...interface{}) {
+	a.logit(msg, keyvals, "WARN")
 }
 
 func (a *adapter) Error(msg string, keyvals ...interface{}) {
-	a.logit(msg, keyvals, true)
+	a.logit(msg, keyvals, "EROR") // Not a typo. It ensures all level strings are 4-chars long.
 }
 
 func (a *adapter) New(keyvals ...interface{}) LogAdapter {
@@ -70,7 +89,7 @@ func (a *adapter) New(keyvals ...interface{}) LogAdapter {
 	}
 }
 
-func (a *adapter) logit(msg string, keyvals []interface{}, iserror bool) {
+func (a *adapter) logit(msg string, keyvals []interface{}, lvl string) {
 	n := (len(keyvals) + 1) / 2
 	if len(keyvals)%2 != 0 {
 		keyvals = append(keyvals, ErrMissingLogValue)
@@ -78,10 +97,6 @@ func (a *adapter) logit(msg string, keyvals []interface{}, iserror bool) {
 	m := (len(a.keyvals) + 1) / 2
 	n += m
 	var fm bytes.Buffer
-	lvl := "INFO"
-	if iserror {
-		lvl = "EROR" // Not a typo. It ensures all level strings are 4-chars long.
-	}
 	fm.WriteString(fmt.Sprintf("[%s] %s", lvl, msg))
 	vals := make([]interface{}, n)
 	offset := len(a.keyvals)
@@ -100,6 +115,20 @@ func (a *adapter) logit(msg string, keyvals []interface{}, iserror bool) {
 	a.Logger.Printf(fm.String(), vals...)
 }
 
+// LogDebug extracts the logger from the given context and calls Debug on it if it implements
+// LeveledLogAdapter, Info otherwise.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogDebug(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		if logger, ok := l.(LeveledLogAdapter); ok {
+			logger.Debug(msg, keyvals...)
+		} else if logger, ok := l.(LogAdapter); ok {
+			logger.Info(msg, keyvals...)
+		}
+	}
+}
+
 // LogInfo extracts the logger from the given context and calls Info on it.
 // This is intended for code that needs portable logging such as the internal code of goa and
 // middleware. User code should use the log adapters instead.
@@ -111,6 +140,20 @@ func LogInfo(ctx context.Context, msg string, keyvals ...interface{}) {
 	}
 }
 
+// LogWarn extracts the logger from the given context and calls Warn on it if it implements
+// LeveledLogAdapter, Info otherwise.
+// This is intended for code that needs portable logging such as the internal code of goa and
+// middleware. User code should use the log adapters instead.
+func LogWarn(ctx context.Context, msg string, keyvals ...interface{}) {
+	if l := ctx.Value(logKey); l != nil {
+		if logger, ok := l.(LeveledLogAdapter); ok {
+			logger.Warn(msg, keyvals...)
+		} else if logger, ok := l.(LogAdapter); ok {
+			logger.Info(msg, keyvals...)
+		}
+	}
+}
+
 // LogError extracts the logger from the given context and calls Error on it.
 // This is intended for code that needs portable logging such as the internal code of goa and
 // middleware. User code should use the log adapters instead.
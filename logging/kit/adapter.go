@@ -40,6 +40,13 @@ func Logger(ctx context.Context) log.Logger {
 	return nil
 }
 
+// Debug logs debug messages using go-kit.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	ctx := []interface{}{"lvl", "debug", "msg", msg}
+	ctx = append(ctx, data...)
+	a.Logger.Log(ctx...)
+}
+
 // Info logs informational messages using go-kit.
 func (a *adapter) Info(msg string, data ...interface{}) {
 	ctx := []interface{}{"lvl", "info", "msg", msg}
@@ -47,6 +54,13 @@ func (a *adapter) Info(msg string, data ...interface{}) {
 	a.Logger.Log(ctx...)
 }
 
+// Warn logs warning messages using go-kit.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	ctx := []interface{}{"lvl", "warn", "msg", msg}
+	ctx = append(ctx, data...)
+	a.Logger.Log(ctx...)
+}
+
 // Error logs error messages using go-kit.
 func (a *adapter) Error(msg string, data ...interface{}) {
 	ctx := []interface{}{"lvl", "error", "msg", msg}
@@ -25,4 +25,10 @@ var _ = Describe("New", func() {
 		adapter.Info(msg)
 		Ω(buf.String()).Should(Equal("lvl=info msg=" + msg + "\n"))
 	})
+
+	It("supports the debug and warning levels", func() {
+		buf.Reset()
+		adapter.(goa.LeveledLogAdapter).Warn("msg", "key", "val")
+		Ω(buf.String()).Should(Equal("lvl=warn msg=msg key=val\n"))
+	})
 })
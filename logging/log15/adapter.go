@@ -39,11 +39,21 @@ func Logger(ctx context.Context) log15.Logger {
 	return nil
 }
 
+// Debug logs debug messages using log15.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, data...)
+}
+
 // Info logs informational messages using log15.
 func (a *adapter) Info(msg string, data ...interface{}) {
 	a.Logger.Info(msg, data...)
 }
 
+// Warn logs warning messages using log15.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, data...)
+}
+
 // Error logs error messages using log15.
 func (a *adapter) Error(msg string, data ...interface{}) {
 	a.Logger.Error(msg, data...)
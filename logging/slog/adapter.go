@@ -0,0 +1,82 @@
+// +build go1.21
+
+/*
+Package goaslog contains an adapter that makes it possible to configure goa so it uses the
+standard library log/slog package as logger backend.
+Usage:
+
+    logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+    // Initialize logger handler using slog package
+    service.WithLogger(goaslog.New(logger))
+    // ... Proceed with configuring and starting the goa service
+
+    // In handlers:
+    goaslog.Logger(ctx).Info("foo", "bar", "baz")
+*/
+package goaslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+)
+
+// adapter is the slog goa logger adapter.
+type adapter struct {
+	*slog.Logger
+}
+
+// New wraps a slog logger into a goa logger.
+func New(logger *slog.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the slog logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Debug logs debug messages using slog.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, data2slog(data)...)
+}
+
+// Info logs messages using slog.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info(msg, data2slog(data)...)
+}
+
+// Warn logs warnings using slog.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, data2slog(data)...)
+}
+
+// Error logs errors using slog.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error(msg, data2slog(data)...)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With(data2slog(data)...)}
+}
+
+func data2slog(keyvals []interface{}) []interface{} {
+	n := (len(keyvals) + 1) / 2
+	res := make([]interface{}, 0, n)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i]
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res = append(res, slog.Any(fmt.Sprintf("%v", k), v))
+	}
+	return res
+}
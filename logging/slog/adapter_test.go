@@ -0,0 +1,49 @@
+// +build go1.21
+
+package goaslog_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/slog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("goaslog", func() {
+	var logger *slog.Logger
+	var adapter goa.LogAdapter
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf.Reset()
+		noTime := func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		}
+		logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: noTime}))
+		adapter = goaslog.New(logger)
+	})
+
+	It("adapts info messages", func() {
+		adapter.New("ctrl", "bottle").Info("msg", "status", 200, "missing")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"INFO","msg":"msg","ctrl":"bottle","status":200,"missing":"MISSING"}`))
+	})
+
+	It("adapts error messages", func() {
+		adapter.Error("msg")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"ERROR","msg":"msg"}`))
+	})
+
+	Context("Logger", func() {
+		It("extracts the slog logger", func() {
+			ctx := goa.WithLogger(context.Background(), adapter)
+			Ω(goaslog.Logger(ctx)).Should(Equal(logger))
+		})
+	})
+})
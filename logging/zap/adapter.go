@@ -0,0 +1,80 @@
+/*
+Package goazap contains an adapter that makes it possible to configure goa so it uses zap as
+logger backend.
+Usage:
+
+    logger, _ := zap.NewProduction()
+    // Initialize logger handler using zap package
+    service.WithLogger(goazap.New(logger))
+    // ... Proceed with configuring and starting the goa service
+
+    // In handlers:
+    goazap.Logger(ctx).Info("foo", zap.String("bar", "baz"))
+*/
+package goazap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goadesign/goa"
+	"go.uber.org/zap"
+)
+
+// adapter is the zap goa logger adapter.
+type adapter struct {
+	*zap.Logger
+}
+
+// New wraps a zap logger into a goa logger.
+func New(logger *zap.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the zap logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *zap.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Debug logs debug messages using zap.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug(msg, data2zap(data)...)
+}
+
+// Info logs messages using zap.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info(msg, data2zap(data)...)
+}
+
+// Warn logs warnings using zap.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn(msg, data2zap(data)...)
+}
+
+// Error logs errors using zap.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error(msg, data2zap(data)...)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With(data2zap(data)...)}
+}
+
+func data2zap(keyvals []interface{}) []zap.Field {
+	n := (len(keyvals) + 1) / 2
+	res := make([]zap.Field, 0, n)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i]
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res = append(res, zap.Any(fmt.Sprintf("%v", k), v))
+	}
+	return res
+}
@@ -0,0 +1,50 @@
+package goazap_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/zap"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ = Describe("goazap", func() {
+	var logger *zap.Logger
+	var adapter goa.LogAdapter
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf.Reset()
+		enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder})
+		logger = zap.New(zapcore.NewCore(enc, zapcore.AddSync(&buf), zap.DebugLevel))
+		adapter = goazap.New(logger)
+	})
+
+	It("adapts info messages", func() {
+		adapter.New("ctrl", "bottle").Info("msg", "status", 200, "missing")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"info","msg":"msg","ctrl":"bottle","status":200,"missing":"MISSING"}`))
+	})
+
+	It("adapts debug and warning messages", func() {
+		leveled := adapter.(goa.LeveledLogAdapter)
+		leveled.Debug("debug")
+		leveled.Warn("warn")
+		Ω(buf.String()).Should(Equal(`{"level":"debug","msg":"debug"}` + "\n" + `{"level":"warn","msg":"warn"}` + "\n"))
+	})
+
+	It("adapts error messages", func() {
+		adapter.Error("msg")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"error","msg":"msg"}`))
+	})
+
+	Context("Logger", func() {
+		It("extracts the zap logger", func() {
+			ctx := goa.WithLogger(context.Background(), adapter)
+			Ω(goazap.Logger(ctx)).Should(Equal(logger))
+		})
+	})
+})
@@ -0,0 +1,80 @@
+/*
+Package goazerolog contains an adapter that makes it possible to configure goa so it uses zerolog
+as logger backend.
+Usage:
+
+    logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+    // Initialize logger handler using zerolog package
+    service.WithLogger(goazerolog.New(logger))
+    // ... Proceed with configuring and starting the goa service
+
+    // In handlers:
+    goazerolog.Logger(ctx).Info().Str("foo", "bar").Msg("baz")
+*/
+package goazerolog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goadesign/goa"
+	"github.com/rs/zerolog"
+)
+
+// adapter is the zerolog goa logger adapter.
+type adapter struct {
+	zerolog.Logger
+}
+
+// New wraps a zerolog logger into a goa logger.
+func New(logger zerolog.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the zerolog logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *zerolog.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return &a.Logger
+	}
+	return nil
+}
+
+// Debug logs debug messages using zerolog.
+func (a *adapter) Debug(msg string, data ...interface{}) {
+	a.Logger.Debug().Fields(data2zerolog(data)).Msg(msg)
+}
+
+// Info logs messages using zerolog.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info().Fields(data2zerolog(data)).Msg(msg)
+}
+
+// Warn logs warnings using zerolog.
+func (a *adapter) Warn(msg string, data ...interface{}) {
+	a.Logger.Warn().Fields(data2zerolog(data)).Msg(msg)
+}
+
+// Error logs errors using zerolog.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error().Fields(data2zerolog(data)).Msg(msg)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With().Fields(data2zerolog(data)).Logger()}
+}
+
+func data2zerolog(keyvals []interface{}) map[string]interface{} {
+	n := (len(keyvals) + 1) / 2
+	res := make(map[string]interface{}, n)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i]
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		res[fmt.Sprintf("%v", k)] = v
+	}
+	return res
+}
@@ -0,0 +1,41 @@
+package goazerolog_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/zerolog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/rs/zerolog"
+)
+
+var _ = Describe("goazerolog", func() {
+	var logger zerolog.Logger
+	var adapter goa.LogAdapter
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf.Reset()
+		logger = zerolog.New(&buf)
+		adapter = goazerolog.New(logger)
+	})
+
+	It("adapts info messages", func() {
+		adapter.New("ctrl", "bottle").Info("msg", "status", 200, "missing")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"info","message":"msg","ctrl":"bottle","status":200,"missing":"MISSING"}`))
+	})
+
+	It("adapts error messages", func() {
+		adapter.Error("msg")
+		Ω(buf.String()).Should(MatchJSON(`{"level":"error","message":"msg"}`))
+	})
+
+	Context("Logger", func() {
+		It("extracts the zerolog logger", func() {
+			ctx := goa.WithLogger(context.Background(), adapter)
+			Ω(goazerolog.Logger(ctx)).ShouldNot(BeNil())
+		})
+	})
+})
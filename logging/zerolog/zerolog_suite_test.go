@@ -0,0 +1,13 @@
+package goazerolog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestZerolog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Zerolog Suite")
+}
@@ -49,5 +49,43 @@ var _ = Describe("LogAdapter", func() {
 			logger.Error(msg, data...)
 			Ω(out.String()).Should(ContainSubstring(msg + " data=foo"))
 		})
+
+		It("Debug and Warn log with their levels", func() {
+			leveled, ok := logger.(goa.LeveledLogAdapter)
+			Ω(ok).Should(BeTrue())
+			leveled.Debug(msg, data...)
+			Ω(out.String()).Should(ContainSubstring("[DBUG] " + msg + " data=foo"))
+			leveled.Warn(msg, data...)
+			Ω(out.String()).Should(ContainSubstring("[WARN] " + msg + " data=foo"))
+		})
+	})
+})
+
+var _ = Describe("LogDebug", func() {
+	var out bytes.Buffer
+	var ctx context.Context
+
+	BeforeEach(func() {
+		out.Reset()
+		ctx = goa.WithLogger(context.Background(), &infoOnlyLogger{&out})
+	})
+
+	It("falls back to Info for adapters that do not support levels", func() {
+		goa.LogDebug(ctx, "debug")
+		goa.LogWarn(ctx, "warn")
+		Ω(out.String()).Should(Equal("debug\nwarn\n"))
 	})
 })
+
+// infoOnlyLogger is a LogAdapter that does not implement LeveledLogAdapter.
+type infoOnlyLogger struct {
+	out *bytes.Buffer
+}
+
+func (l *infoOnlyLogger) Info(msg string, keyvals ...interface{}) {
+	l.out.WriteString(msg + "\n")
+}
+
+func (l *infoOnlyLogger) Error(msg string, keyvals ...interface{}) {}
+
+func (l *infoOnlyLogger) New(keyvals ...interface{}) goa.LogAdapter { return l }
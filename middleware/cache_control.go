@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+
+	"context"
+)
+
+// CacheControl sets the Cache-Control response header to value. It also sets the Expires header
+// to the current time plus maxAge seconds if value starts with a max-age directive. The headers
+// are removed if the handler returns an error so that error responses are not cached. The
+// generated code uses CacheControl to apply the caching rules defined in file servers with the
+// CacheControl DSL.
+func CacheControl(value string, maxAge int) goa.Middleware {
+	expires := strings.HasPrefix(value, "max-age=")
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			header := rw.Header()
+			header.Set("Cache-Control", value)
+			if expires {
+				header.Set("Expires", time.Now().Add(time.Duration(maxAge)*time.Second).UTC().Format(http.TimeFormat))
+			}
+			err := h(ctx, rw, req)
+			if err != nil {
+				header.Del("Cache-Control")
+				header.Del("Expires")
+			}
+			return err
+		}
+	}
+}
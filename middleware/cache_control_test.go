@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CacheControl", func() {
+	var rw *testResponseWriter
+	var req *http.Request
+	var handlerErr error
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("GET", "/public/app.js", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		handlerErr = nil
+	})
+
+	handle := func(value string, maxAge int) error {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return handlerErr
+		}
+		return middleware.CacheControl(value, maxAge)(h)(context.Background(), rw, req)
+	}
+
+	It("sets the caching headers", func() {
+		Ω(handle("max-age=3600, public", 3600)).ShouldNot(HaveOccurred())
+		Ω(rw.ParentHeader.Get("Cache-Control")).Should(Equal("max-age=3600, public"))
+		Ω(rw.ParentHeader.Get("Expires")).ShouldNot(BeEmpty())
+	})
+
+	It("omits the Expires header without max-age directive", func() {
+		Ω(handle("no-store", 0)).ShouldNot(HaveOccurred())
+		Ω(rw.ParentHeader.Get("Cache-Control")).Should(Equal("no-store"))
+		Ω(rw.ParentHeader.Get("Expires")).Should(BeEmpty())
+	})
+
+	It("removes the headers when the handler fails", func() {
+		handlerErr = goa.ErrNotFound(errors.New("not found"))
+		Ω(handle("max-age=3600", 3600)).Should(HaveOccurred())
+		Ω(rw.ParentHeader.Get("Cache-Control")).Should(BeEmpty())
+		Ω(rw.ParentHeader.Get("Expires")).Should(BeEmpty())
+	})
+})
@@ -9,49 +9,126 @@ import (
 	"github.com/goadesign/goa"
 )
 
+type (
+	// ErrorHandlerOption customizes the behavior of the ErrorHandler middleware.
+	ErrorHandlerOption func(*errorHandlerOptions)
+
+	// errorHandlerOptions holds the ErrorHandler middleware settings.
+	errorHandlerOptions struct {
+		problemDetails bool
+	}
+)
+
+// WithProblemDetails causes the ErrorHandler middleware to write RFC 7807 problem details
+// responses using the "application/problem+json" content type instead of the goa error media
+// type. The response bodies contain the type, title, status, detail and instance members as
+// well as the goa error ID, code and meta as extension members. See goa.ProblemDetails.
+func WithProblemDetails() ErrorHandlerOption {
+	return func(o *errorHandlerOptions) {
+		o.problemDetails = true
+	}
+}
+
 // ErrorHandler turns a Go error into an HTTP response. It should be placed in the middleware chain
 // below the logger middleware so the logger properly logs the HTTP response. ErrorHandler
 // understands instances of goa.ServiceError and returns the status and response body embodied in
-// them, it turns other Go error types into a 500 internal error response.
+// them, it turns other Go error types into a 500 internal error response. Instances of
+// goa.TypedError produced by the code generated for the Error DSL use the designed body.
+// If an upstream middleware allocated an error ID with goa.ContextWithErrorID then that ID is
+// used in the error response and logs instead of the ID of the error. If the RequestID middleware
+// is mounted upstream the request ID is added to the meta of the error responses under the
+// "request_id" key.
 // If verbose is false the details of internal errors is not included in HTTP responses.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
-func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
+func ErrorHandler(service *goa.Service, verbose bool, opts ...ErrorHandlerOption) goa.Middleware {
+	var o errorHandlerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			e := h(ctx, rw, req)
 			if e == nil {
 				return nil
 			}
+			// Use the error ID allocated by upstream middlewares if any so that their output
+			// can be correlated with the response.
+			errID := goa.ContextErrorID(ctx)
+			reqID := ContextRequestID(ctx)
 			cause := cause(e)
+			if terr, ok := cause.(*goa.TypedError); ok {
+				// Errors defined in the design carry their own response body
+				if errID == "" {
+					errID = terr.Token()
+				}
+				goa.ContextResponse(ctx).ErrorCode = errID
+				if terr.ContentType != "" {
+					rw.Header().Set("Content-Type", terr.ContentType)
+				}
+				if terr.Status == http.StatusInternalServerError {
+					goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", e), "id", errID)
+				}
+				return service.Send(ctx, terr.Status, terr.Body)
+			}
 			status := http.StatusInternalServerError
 			var respBody interface{}
 			if err, ok := cause.(goa.ServiceError); ok {
 				status = err.ResponseStatus()
 				respBody = err
-				goa.ContextResponse(ctx).ErrorCode = err.Token()
+				if errID == "" {
+					errID = err.Token()
+				} else if resp, ok := err.(*goa.ErrorResponse); ok {
+					r := *resp
+					r.ID = errID
+					respBody = &r
+				}
+				goa.ContextResponse(ctx).ErrorCode = errID
 				rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
 			} else {
 				respBody = e.Error()
 				rw.Header().Set("Content-Type", "text/plain")
 			}
 			if status == http.StatusInternalServerError {
-				reqID := ctx.Value(reqIDKey)
-				if reqID == nil {
-					reqID = shortID()
-					ctx = context.WithValue(ctx, reqIDKey, reqID)
+				id := errID
+				if id == "" {
+					id = reqID
+					if id == "" {
+						id = shortID()
+						ctx = context.WithValue(ctx, reqIDKey, id)
+					}
 				}
-				goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", e), "id", reqID, "msg", respBody)
+				goa.LogError(ctx, "uncaught error", "err", fmt.Sprintf("%+v", e), "id", id, "msg", respBody)
 				if !verbose {
 					rw.Header().Set("Content-Type", goa.ErrorMediaIdentifier)
-					msg := fmt.Sprintf("%s [%s]", http.StatusText(http.StatusInternalServerError), reqID)
+					msg := fmt.Sprintf("%s [%s]", http.StatusText(http.StatusInternalServerError), id)
 					respBody = goa.ErrInternal(msg)
 					// Preserve the ID of the original error as that's what gets logged, the client
 					// received error ID must match the original
-					if origErrID := goa.ContextResponse(ctx).ErrorCode; origErrID != "" {
-						respBody.(*goa.ErrorResponse).ID = origErrID
+					if errID != "" {
+						respBody.(*goa.ErrorResponse).ID = errID
 					}
 				}
 			}
+			if reqID != "" {
+				if resp, ok := respBody.(*goa.ErrorResponse); ok {
+					r := *resp
+					r.Meta = make(map[string]interface{}, len(resp.Meta)+1)
+					for k, v := range resp.Meta {
+						r.Meta[k] = v
+					}
+					r.Meta["request_id"] = reqID
+					respBody = &r
+				}
+			}
+			if o.problemDetails {
+				rw.Header().Set("Content-Type", goa.ProblemMediaIdentifier)
+				switch actual := respBody.(type) {
+				case error:
+					respBody = goa.NewProblemDetails(actual, req.URL.RequestURI())
+				default:
+					respBody = goa.NewProblemDetails(fmt.Errorf("%v", actual), req.URL.RequestURI())
+				}
+			}
 			return service.Send(ctx, status, respBody)
 		}
 	}
@@ -45,6 +45,9 @@ var _ = Describe("ErrorHandler", func() {
 	var service *goa.Service
 	var h goa.Handler
 	var verbose bool
+	var opts []middleware.ErrorHandlerOption
+	var withErrorID bool
+	var errID string
 
 	var rw *testResponseWriter
 
@@ -52,15 +55,21 @@ var _ = Describe("ErrorHandler", func() {
 		service = nil
 		h = nil
 		verbose = true
+		opts = nil
+		withErrorID = false
+		errID = ""
 		rw = nil
 	})
 
 	JustBeforeEach(func() {
 		rw = newTestResponseWriter()
-		eh := middleware.ErrorHandler(service, verbose)(h)
+		eh := middleware.ErrorHandler(service, verbose, opts...)(h)
 		req, err := http.NewRequest("GET", "/foo", nil)
 		Ω(err).ShouldNot(HaveOccurred())
 		ctx := newContext(service, rw, req, nil)
+		if withErrorID {
+			ctx, errID = goa.ContextWithErrorID(ctx)
+		}
 		err = eh(ctx, rw, req)
 		Ω(err).ShouldNot(HaveOccurred())
 	})
@@ -163,6 +172,118 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with an error ID allocated in the context", func() {
+		BeforeEach(func() {
+			service = newService(nil)
+			withErrorID = true
+		})
+
+		Context("and a goa error", func() {
+			BeforeEach(func() {
+				h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return goa.ErrBadRequest("boom")
+				}
+			})
+
+			It("uses the context error ID", func() {
+				var decoded errorResponse
+				Ω(errID).ShouldNot(BeEmpty())
+				Ω(rw.Status).Should(Equal(400))
+				err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.ID).Should(Equal(errID))
+			})
+		})
+
+		Context("and a Go error", func() {
+			BeforeEach(func() {
+				verbose = false
+				h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return errors.New("boom")
+				}
+			})
+
+			It("uses the context error ID", func() {
+				var decoded errorResponse
+				Ω(rw.Status).Should(Equal(500))
+				err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.ID).Should(Equal(errID))
+				Ω(decoded.Detail).Should(ContainSubstring(errID))
+			})
+		})
+	})
+
+	Context("with a handler returning a typed error", func() {
+		var terr error
+
+		BeforeEach(func() {
+			service = newService(nil)
+			terr = goa.NewTypedError("conflict", 409, map[string]string{"reason": "taken"}, "application/vnd.conflict+json")
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return terr
+			}
+		})
+
+		It("uses the error body", func() {
+			var decoded map[string]string
+			Ω(rw.Status).Should(Equal(409))
+			Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{"application/vnd.conflict+json"}))
+			err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(map[string]string{"reason": "taken"}))
+		})
+	})
+
+	Context("with problem details enabled", func() {
+		var gerr error
+
+		BeforeEach(func() {
+			service = newService(nil)
+			opts = []middleware.ErrorHandlerOption{middleware.WithProblemDetails()}
+			gerr = goa.NewErrorClass("code", 418)("teapot", "foobar", 42)
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return gerr
+			}
+		})
+
+		It("writes RFC 7807 responses", func() {
+			var decoded goa.ProblemDetails
+			Ω(rw.Status).Should(Equal(418))
+			Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+			err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded.Type).Should(Equal("about:blank"))
+			Ω(decoded.Title).Should(Equal(http.StatusText(418)))
+			Ω(decoded.Status).Should(Equal(418))
+			Ω(decoded.Detail).Should(Equal("teapot"))
+			Ω(decoded.Instance).Should(Equal("/foo"))
+			Ω(decoded.ID).Should(Equal(gerr.(goa.ServiceError).Token()))
+			Ω(decoded.Code).Should(Equal("code"))
+			Ω(decoded.Meta).Should(HaveKeyWithValue("foobar", BeNumerically("==", 42)))
+		})
+
+		Context("and a Go error", func() {
+			BeforeEach(func() {
+				verbose = false
+				h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					return errors.New("boom")
+				}
+			})
+
+			It("hides the error details", func() {
+				var decoded goa.ProblemDetails
+				Ω(rw.Status).Should(Equal(500))
+				Ω(rw.ParentHeader["Content-Type"]).Should(Equal([]string{goa.ProblemMediaIdentifier}))
+				err := service.Decoder.Decode(&decoded, bytes.NewBuffer(rw.Body), "application/json")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(decoded.Status).Should(Equal(500))
+				Ω(decoded.Code).Should(Equal("internal"))
+				Ω(decoded.Detail).ShouldNot(ContainSubstring("boom"))
+			})
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
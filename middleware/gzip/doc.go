@@ -1 +1,7 @@
+// Package gzip provides a middleware that compresses the responses using the gzip or deflate
+// content encoding negotiated with the request Accept-Encoding header, see Middleware.
+//
+// The brotli ("br") content encoding is intentionally not supported: the standard library does
+// not implement it and the package only depends on the standard library compressors. Requests
+// that only accept brotli are served uncompressed.
 package gzip
@@ -3,7 +3,10 @@ package gzip
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -15,7 +18,15 @@ import (
 
 // These compression constants are copied from the compress/gzip package.
 const (
-	encodingGzip = "gzip"
+	NoCompression      = gzip.NoCompression
+	BestSpeed          = gzip.BestSpeed
+	BestCompression    = gzip.BestCompression
+	DefaultCompression = gzip.DefaultCompression
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
 
 	headerAcceptEncoding  = "Accept-Encoding"
 	headerContentEncoding = "Content-Encoding"
@@ -27,11 +38,18 @@ const (
 	headerSecWebSocketKey = "Sec-WebSocket-Key"
 )
 
-// gzipResponseWriter wraps the http.ResponseWriter to provide gzip
-// capabilities.
+// compressWriter is implemented by both gzip.Writer and zlib.Writer.
+type compressWriter interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+// gzipResponseWriter wraps the http.ResponseWriter to provide gzip or
+// deflate capabilities.
 type gzipResponseWriter struct {
 	http.ResponseWriter
-	gzw            *gzip.Writer
+	gzw            compressWriter
+	encoding       string
 	buf            bytes.Buffer
 	pool           *sync.Pool
 	statusCode     int
@@ -78,10 +96,10 @@ func (grw *gzipResponseWriter) Write(b []byte) (int, error) {
 	// Retrieve gzip writer from the pool. Reset it to use the ResponseWriter.
 	// This allows us to re-use an already allocated buffer rather than
 	// allocating a new buffer for every request.
-	gz := grw.pool.Get().(*gzip.Writer)
+	gz := grw.pool.Get().(compressWriter)
 
 	// We must write header now
-	grw.Header().Set(headerContentEncoding, encodingGzip)
+	grw.Header().Set(headerContentEncoding, grw.encoding)
 	grw.Header().Set(headerVary, headerAcceptEncoding)
 	grw.Header().Del(headerContentLength)
 	grw.Header().Del(headerAcceptRanges)
@@ -114,6 +132,7 @@ type (
 		minSize      int
 		contentTypes []string
 		statusCodes  map[int]struct{}
+		encodings    []string
 	}
 )
 
@@ -233,6 +252,26 @@ func MinSize(n int) Option {
 	}
 }
 
+// OnlyEncodings allows to specify the content encodings the middleware may
+// use, the supported encodings are "gzip" and "deflate". The encoding used
+// for a given request is the one with the highest quality value in the
+// request Accept-Encoding header, ties are resolved using the order of the
+// encodings given to OnlyEncodings. The default is "gzip" then "deflate".
+// Brotli ("br") is not supported as there is no implementation in the
+// standard library, giving it to OnlyEncodings makes Middleware panic with an
+// "unsupported content encoding" error.
+func OnlyEncodings(encodings ...string) Option {
+	return func(c *options) error {
+		for _, e := range encodings {
+			if e != encodingGzip && e != encodingDeflate {
+				return fmt.Errorf("unsupported content encoding %q", e)
+			}
+		}
+		c.encodings = encodings
+		return nil
+	}
+}
+
 // IgnoreRange will set make the compressor ignore Range requests.
 // Range requests are incompatible with compressed content,
 // so if this is set to true "Range" headers will be ignored.
@@ -244,14 +283,21 @@ func IgnoreRange(b bool) Option {
 	}
 }
 
-// Middleware encodes the response using Gzip encoding and sets all the
-// appropriate headers. If the Content-Type is not set, it will be set by
-// calling http.DetectContentType on the data being written.
+// Middleware encodes the response using gzip or deflate encoding depending on
+// the request Accept-Encoding header and sets all the appropriate headers. If
+// the Content-Type is not set, it will be set by calling
+// http.DetectContentType on the data being written. The middleware may be
+// mounted on the service to compress all responses or on a controller to
+// only compress the responses of its actions:
+//
+//	service.Use(gzip.Middleware(gzip.BestSpeed))
+//	ctrl.Use(gzip.Middleware(gzip.BestCompression, gzip.MinSize(1024)))
 func Middleware(level int, o ...Option) goa.Middleware {
 	opts := options{
 		ignoreRange:  true,
 		minSize:      256,
 		contentTypes: defaultContentTypes,
+		encodings:    []string{encodingGzip, encodingDeflate},
 	}
 	opts.statusCodes = make(map[int]struct{}, len(defaultStatusCodes))
 	for _, v := range defaultStatusCodes {
@@ -272,16 +318,33 @@ func Middleware(level int, o ...Option) goa.Middleware {
 			return gz
 		},
 	}
+	// The "deflate" HTTP content coding is the zlib format (RFC 9110 section 8.4.1.2), not raw
+	// deflate data.
+	deflatePool := sync.Pool{
+		New: func() interface{} {
+			zw, err := zlib.NewWriterLevel(ioutil.Discard, level)
+			if err != nil {
+				panic(err)
+			}
+			return zw
+		},
+	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
-			// Skip compression if the client doesn't accept gzip encoding, is
-			// requesting a WebSocket or the data is already compressed.
-			if !strings.Contains(req.Header.Get(headerAcceptEncoding), encodingGzip) ||
+			// Skip compression if the client doesn't accept any supported
+			// encoding, is requesting a WebSocket or the data is already
+			// compressed.
+			encoding := opts.negotiateEncoding(req.Header.Get(headerAcceptEncoding))
+			if encoding == "" ||
 				len(req.Header.Get(headerSecWebSocketKey)) > 0 ||
-				rw.Header().Get(headerContentEncoding) == encodingGzip ||
+				rw.Header().Get(headerContentEncoding) != "" ||
 				(!opts.ignoreRange && req.Header.Get(headerRange) != "") {
 				return h(ctx, rw, req)
 			}
+			pool := &gzipPool
+			if encoding == encodingDeflate {
+				pool = &deflatePool
+			}
 
 			// Set the appropriate gzip headers.
 			resp := goa.ContextResponse(ctx)
@@ -292,7 +355,8 @@ func Middleware(level int, o ...Option) goa.Middleware {
 			// Wrap the original http.ResponseWriter with our gzipResponseWriter
 			grw := &gzipResponseWriter{
 				ResponseWriter: w,
-				pool:           &gzipPool,
+				encoding:       encoding,
+				pool:           pool,
 				statusCode:     http.StatusOK,
 				o:              opts,
 			}
@@ -323,7 +387,7 @@ func Middleware(level int, o ...Option) goa.Middleware {
 				if err = grw.gzw.Close(); err != nil {
 					return
 				}
-				gzipPool.Put(grw.gzw)
+				pool.Put(grw.gzw)
 				return
 			}
 			// No writes, set status code.
@@ -335,6 +399,46 @@ func Middleware(level int, o ...Option) goa.Middleware {
 	}
 }
 
+// negotiateEncoding returns the configured encoding with the highest quality
+// value in the given Accept-Encoding header value, the empty string if none is
+// acceptable.
+func (o options) negotiateEncoding(acceptEncoding string) string {
+	qs := make(map[string]float64)
+	for _, elem := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(elem, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				v, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					v = 0
+				}
+				q = v
+			}
+		}
+		qs[name] = q
+	}
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, e := range o.encodings {
+		q, ok := qs[e]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return best
+}
+
 // returns true if we've been configured to compress the specific content type.
 func (o options) shouldCompress(contentType string, statusCode int) bool {
 	// If contentTypes is nil we handle all content types.
@@ -3,6 +3,7 @@ package gzip_test
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"io"
 	"net/http"
@@ -489,3 +490,66 @@ var _ = Describe("NotGzip", func() {
 		Ω(buf.String()).Should(Equal("gzip me!"))
 	})
 })
+
+var _ = Describe("Deflate", func() {
+	var ctx context.Context
+	var req *http.Request
+	var rw *TestResponseWriter
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("GET", "/foo/bar", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx = goa.NewContext(nil, rw, req, nil)
+	})
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		resp := goa.ContextResponse(ctx)
+		resp.Header().Set("Content-Type", "text/plain")
+		resp.WriteHeader(http.StatusOK)
+		resp.Write([]byte("deflate me!"))
+		return nil
+	}
+
+	It("encodes response using deflate", func() {
+		req.Header.Set("Accept-Encoding", "deflate")
+		t := gzm.Middleware(gzip.BestCompression, gzm.MinSize(0))(h)
+		err := t(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		resp := goa.ContextResponse(ctx)
+		Ω(resp.Header().Get("Content-Encoding")).Should(Equal("deflate"))
+
+		var buf bytes.Buffer
+		zr, err := zlib.NewReader(bytes.NewReader(rw.Body))
+		Ω(err).ShouldNot(HaveOccurred())
+		_, err = io.Copy(&buf, zr)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("deflate me!"))
+	})
+
+	It("uses the encoding with the highest quality value", func() {
+		req.Header.Set("Accept-Encoding", "gzip;q=0.5, deflate")
+		t := gzm.Middleware(gzip.BestCompression, gzm.MinSize(0))(h)
+		err := t(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(goa.ContextResponse(ctx).Header().Get("Content-Encoding")).Should(Equal("deflate"))
+	})
+
+	It("honors encodings explicitly refused", func() {
+		req.Header.Set("Accept-Encoding", "*, gzip;q=0")
+		t := gzm.Middleware(gzip.BestCompression, gzm.MinSize(0))(h)
+		err := t(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(goa.ContextResponse(ctx).Header().Get("Content-Encoding")).Should(Equal("deflate"))
+	})
+
+	It("only uses the configured encodings", func() {
+		req.Header.Set("Accept-Encoding", "deflate")
+		t := gzm.Middleware(gzip.BestCompression, gzm.MinSize(0), gzm.OnlyEncodings("gzip"))(h)
+		err := t(ctx, rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(goa.ContextResponse(ctx).Header().Get("Content-Encoding")).Should(Equal(""))
+		Ω(string(rw.Body)).Should(Equal("deflate me!"))
+	})
+})
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// IdempotencyKeyHeader is the name of the request header containing the idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type (
+	// IdempotencyStore stores the responses to the requests that have an idempotency key so
+	// that the Idempotency middleware may replay them. Implementations must be safe for
+	// concurrent use and may share the responses between service instances, for example by
+	// storing them in Redis.
+	IdempotencyStore interface {
+		// Get returns the response stored under key, nil if there is none.
+		Get(ctx context.Context, key string) (*IdempotentResponse, error)
+		// Set stores the response under key.
+		Set(ctx context.Context, key string, resp *IdempotentResponse) error
+	}
+
+	// IdempotentResponse is a response stored by the Idempotency middleware.
+	IdempotentResponse struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
+	// memoryIdempotencyStore is the IdempotencyStore returned by NewMemoryIdempotencyStore.
+	memoryIdempotencyStore struct {
+		ttl     time.Duration
+		mu      sync.Mutex
+		entries map[string]*memoryIdempotencyEntry
+	}
+
+	// memoryIdempotencyEntry is a response stored in memory with its expiry time.
+	memoryIdempotencyEntry struct {
+		resp      *IdempotentResponse
+		expiresAt time.Time
+	}
+
+	// idempotencyRecorder records the response written by the handler.
+	idempotencyRecorder struct {
+		http.ResponseWriter
+		resp *IdempotentResponse
+	}
+)
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore that keeps the responses in memory for the
+// given duration. The store is not shared between service instances.
+func NewMemoryIdempotencyStore(ttl time.Duration) IdempotencyStore {
+	return &memoryIdempotencyStore{ttl: ttl, entries: make(map[string]*memoryIdempotencyEntry)}
+}
+
+// Idempotency returns a middleware that makes the requests that have an Idempotency-Key header
+// safe to retry. The first request with a given key is handled normally and its response is
+// stored in store unless the handler fails or the response status code is 5xx. The later
+// requests with the same key, method and path get the stored response with the
+// Idempotent-Replayed header set to "true" without calling the handler. Requests reusing the key
+// of a request being handled fail with goa.ErrConflict.
+//
+// The middleware ignores the requests whose method is safe (GET, HEAD, OPTIONS and TRACE). Use
+// the IdempotencyKey DSL to document the header and make it available to the actions:
+//
+//	ctrl := service.NewController("payment")
+//	ctrl.Use(middleware.Idempotency(middleware.NewMemoryIdempotencyStore(24 * time.Hour)))
+func Idempotency(store IdempotencyStore) goa.Middleware {
+	var (
+		mu       sync.Mutex
+		inflight = make(map[string]struct{})
+	)
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := req.Header.Get(IdempotencyKeyHeader)
+			if key == "" || safeMethod(req.Method) {
+				return h(ctx, rw, req)
+			}
+			key = req.Method + " " + req.URL.Path + " " + key
+
+			mu.Lock()
+			if _, ok := inflight[key]; ok {
+				mu.Unlock()
+				return goa.ErrConflict("a request with the same idempotency key is in progress")
+			}
+			inflight[key] = struct{}{}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(inflight, key)
+				mu.Unlock()
+			}()
+
+			stored, err := store.Get(ctx, key)
+			if err != nil {
+				return err
+			}
+			if stored != nil {
+				for k, v := range stored.Header {
+					rw.Header()[k] = v
+				}
+				rw.Header().Set("Idempotent-Replayed", "true")
+				rw.WriteHeader(stored.Status)
+				_, err := rw.Write(stored.Body)
+				return err
+			}
+
+			resp := goa.ContextResponse(ctx)
+			if resp == nil {
+				return h(ctx, rw, req)
+			}
+			rec := &idempotencyRecorder{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(rec)
+			err = h(ctx, rw, req)
+			resp.SwitchWriter(rec.ResponseWriter)
+			if err != nil || rec.resp == nil || rec.resp.Status >= 500 {
+				return err
+			}
+			if serr := store.Set(ctx, key, rec.resp); serr != nil {
+				goa.LogError(ctx, "failed to store idempotent response", "err", serr)
+			}
+			return nil
+		}
+	}
+}
+
+// WriteHeader records the status code and headers of the response.
+func (r *idempotencyRecorder) WriteHeader(status int) {
+	r.resp = &IdempotentResponse{Status: status, Header: cloneHeader(r.Header())}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response body.
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.resp == nil {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.resp.Body = append(r.resp.Body, b...)
+	return r.ResponseWriter.Write(b)
+}
+
+// Get returns the response stored under key if it has not expired.
+func (s *memoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, nil
+	}
+	return e.resp, nil
+}
+
+// Set stores the response under key and discards the expired responses.
+func (s *memoryIdempotencyStore) Set(_ context.Context, key string, resp *IdempotentResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = &memoryIdempotencyEntry{resp: resp, expiresAt: now.Add(s.ttl)}
+	return nil
+}
+
+// safeMethod returns true if the given HTTP method is safe as defined by RFC 7231.
+func safeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+	return false
+}
+
+// cloneHeader returns a copy of h.
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
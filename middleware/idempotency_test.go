@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotency", func() {
+	var (
+		service *goa.Service
+		calls   int
+		status  int
+		handler goa.Handler
+	)
+
+	BeforeEach(func() {
+		service = newService(nil)
+		calls = 0
+		status = http.StatusCreated
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			calls++
+			rw.Header().Set("Location", "/payments/1")
+			rw.WriteHeader(status)
+			rw.Write([]byte(`{"id":1}`))
+			return nil
+		}
+		handler = middleware.Idempotency(middleware.NewMemoryIdempotencyStore(time.Minute))(h)
+	})
+
+	call := func(method, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/payments", nil)
+		if key != "" {
+			req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		rw := httptest.NewRecorder()
+		ctx := newContext(service, rw, req, nil)
+		Ω(handler(ctx, goa.ContextResponse(ctx), req)).ShouldNot(HaveOccurred())
+		return rw
+	}
+
+	It("replays the response to requests with the same key", func() {
+		first := call("POST", "abc")
+		second := call("POST", "abc")
+		Ω(calls).Should(Equal(1))
+		Ω(second.Code).Should(Equal(http.StatusCreated))
+		Ω(second.Body.String()).Should(Equal(first.Body.String()))
+		Ω(second.Header().Get("Location")).Should(Equal("/payments/1"))
+		Ω(second.Header().Get("Idempotent-Replayed")).Should(Equal("true"))
+	})
+
+	It("handles requests with different keys or no key", func() {
+		call("POST", "abc")
+		call("POST", "def")
+		call("POST", "")
+		call("POST", "")
+		Ω(calls).Should(Equal(4))
+	})
+
+	It("ignores safe methods", func() {
+		call("GET", "abc")
+		call("GET", "abc")
+		Ω(calls).Should(Equal(2))
+	})
+
+	Context("with a server error", func() {
+		BeforeEach(func() {
+			status = http.StatusServiceUnavailable
+		})
+
+		It("does not store the response", func() {
+			call("POST", "abc")
+			call("POST", "abc")
+			Ω(calls).Should(Equal(2))
+		})
+	})
+})
@@ -15,32 +15,102 @@ import (
 	"context"
 )
 
+// redacted is the value logged in place of sensitive header, parameter and payload field values.
+const redacted = "[REDACTED]"
+
+type (
+	// LogRequestOption customizes the behavior of the LogRequest middleware.
+	LogRequestOption func(*logRequestOptions)
+
+	// logRequestOptions holds the LogRequest middleware settings.
+	logRequestOptions struct {
+		headers map[string]bool
+		fields  map[string]bool
+	}
+
+	// Redacter is implemented by request payloads that contain sensitive fields. The code
+	// generated by goagen implements it for the payloads whose attributes are flagged with the
+	// "log:redact" metadata.
+	Redacter interface {
+		// RedactedFields returns the names of the fields whose values must not be logged.
+		RedactedFields() []string
+	}
+)
+
+// RedactHeaders causes the LogRequest middleware to log the values of the request headers with
+// the given names as "[REDACTED]". The Authorization, Proxy-Authorization and Cookie headers are
+// always redacted.
+func RedactHeaders(names ...string) LogRequestOption {
+	return func(o *logRequestOptions) {
+		for _, n := range names {
+			o.headers[http.CanonicalHeaderKey(n)] = true
+		}
+	}
+}
+
+// RedactFields causes the LogRequest middleware to log the values of the request parameters and
+// payload fields with the given names as "[REDACTED]". Payload fields are redacted at any depth.
+// The fields of payloads that implement Redacter are also redacted.
+func RedactFields(names ...string) LogRequestOption {
+	return func(o *logRequestOptions) {
+		for _, n := range names {
+			o.fields[n] = true
+		}
+	}
+}
+
 // LogRequest creates a request logger middleware.
 // This middleware is aware of the RequestID middleware and if registered after it leverages the
 // request ID for logging.
-// If verbose is true then the middlware logs the request and response bodies.
-func LogRequest(verbose bool) goa.Middleware {
+// If verbose is true then the middlware logs the request headers, parameters and payload at the
+// debug level. The values of sensitive headers and fields are redacted, see RedactHeaders and
+// RedactFields. Completed requests are logged at the error level for server errors, at the
+// warning level for client errors and at the informational level otherwise.
+func LogRequest(verbose bool, opts ...LogRequestOption) goa.Middleware {
+	o := logRequestOptions{
+		headers: map[string]bool{"Authorization": true, "Proxy-Authorization": true, "Cookie": true},
+		fields:  make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-			reqID := ctx.Value(reqIDKey)
-			if reqID == nil {
-				reqID = shortID()
+			// The RequestID middleware already adds the request ID to the log context.
+			if ctx.Value(reqIDKey) == nil {
+				ctx = goa.WithLogContext(ctx, "req_id", shortID())
 			}
-			ctx = goa.WithLogContext(ctx, "req_id", reqID)
 			startedAt := time.Now()
 			r := goa.ContextRequest(ctx)
-			goa.LogInfo(ctx, "started", r.Method, r.URL.String(), "from", from(req),
-				"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
+			logCtx := []interface{}{r.Method, r.URL.String(), "from", from(req),
+				"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx)}
+			if r.ContentLength > 0 {
+				logCtx = append(logCtx, "bytes", r.ContentLength)
+			}
+			goa.LogInfo(ctx, "started", logCtx...)
 			if verbose {
+				fields := o.fields
+				if rd, ok := r.Payload.(Redacter); ok {
+					fields = make(map[string]bool, len(o.fields))
+					for n := range o.fields {
+						fields[n] = true
+					}
+					for _, n := range rd.RedactedFields() {
+						fields[n] = true
+					}
+				}
 				if len(r.Header) > 0 {
 					logCtx := make([]interface{}, 2*len(r.Header))
 					i := 0
 					for k, v := range r.Header {
 						logCtx[i] = k
 						logCtx[i+1] = interface{}(strings.Join(v, ", "))
+						if o.headers[http.CanonicalHeaderKey(k)] {
+							logCtx[i+1] = redacted
+						}
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "headers", logCtx...)
+					goa.LogDebug(ctx, "headers", logCtx...)
 				}
 				if len(r.Params) > 0 {
 					logCtx := make([]interface{}, 2*len(r.Params))
@@ -48,9 +118,12 @@ func LogRequest(verbose bool) goa.Middleware {
 					for k, v := range r.Params {
 						logCtx[i] = k
 						logCtx[i+1] = interface{}(strings.Join(v, ", "))
+						if fields[k] {
+							logCtx[i+1] = redacted
+						}
 						i = i + 2
 					}
-					goa.LogInfo(ctx, "params", logCtx...)
+					goa.LogDebug(ctx, "params", logCtx...)
 				}
 				if r.ContentLength > 0 {
 					if mp, ok := r.Payload.(map[string]interface{}); ok {
@@ -58,28 +131,44 @@ func LogRequest(verbose bool) goa.Middleware {
 						i := 0
 						for k, v := range mp {
 							logCtx[i] = k
-							logCtx[i+1] = interface{}(v)
+							logCtx[i+1] = redact(v, fields)
+							if fields[k] {
+								logCtx[i+1] = redacted
+							}
 							i = i + 2
 						}
-						goa.LogInfo(ctx, "payload", logCtx...)
+						goa.LogDebug(ctx, "payload", logCtx...)
 					} else {
 						// Not the most efficient but this is used for debugging
 						js, err := json.Marshal(r.Payload)
 						if err != nil {
 							js = []byte("<invalid JSON>")
+						} else if len(fields) > 0 {
+							var v interface{}
+							if err := json.Unmarshal(js, &v); err == nil {
+								js, _ = json.Marshal(redact(v, fields))
+							}
 						}
-						goa.LogInfo(ctx, "payload", "raw", string(js))
+						goa.LogDebug(ctx, "payload", "raw", string(js))
 					}
 				}
 			}
 			err := h(ctx, rw, req)
 			resp := goa.ContextResponse(ctx)
+			// Server errors are logged as errors and client errors as warnings.
+			logf := goa.LogInfo
+			switch {
+			case resp.Status >= 500:
+				logf = goa.LogError
+			case resp.Status >= 400:
+				logf = goa.LogWarn
+			}
 			if code := resp.ErrorCode; code != "" {
-				goa.LogInfo(ctx, "completed", "status", resp.Status, "error", code,
+				logf(ctx, "completed", "status", resp.Status, "error", code,
 					"bytes", resp.Length, "time", time.Since(startedAt).String(),
 					"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
 			} else {
-				goa.LogInfo(ctx, "completed", "status", resp.Status,
+				logf(ctx, "completed", "status", resp.Status,
 					"bytes", resp.Length, "time", time.Since(startedAt).String(),
 					"ctrl", goa.ContextController(ctx), "action", goa.ContextAction(ctx))
 			}
@@ -88,6 +177,33 @@ func LogRequest(verbose bool) goa.Middleware {
 	}
 }
 
+// redact returns a copy of v where the values of the object members whose names are in fields
+// are replaced with "[REDACTED]".
+func redact(v interface{}, fields map[string]bool) interface{} {
+	if len(fields) == 0 {
+		return v
+	}
+	switch actual := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(actual))
+		for k, val := range actual {
+			if fields[k] {
+				res[k] = redacted
+				continue
+			}
+			res[k] = redact(val, fields)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(actual))
+		for i, val := range actual {
+			res[i] = redact(val, fields)
+		}
+		return res
+	}
+	return v
+}
+
 // shortID produces a "unique" 6 bytes long string.
 // Do not use as a reliable way to get unique IDs, instead use for things like logging.
 func shortID() string {
@@ -48,10 +48,12 @@ var _ = Describe("LogRequest", func() {
 		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
 		Ω(logger.InfoEntries).Should(HaveLen(4))
 
-		Ω(logger.InfoEntries[0].Data).Should(HaveLen(10))
+		Ω(logger.InfoEntries[0].Data).Should(HaveLen(12))
 		Ω(logger.InfoEntries[0].Data[0]).Should(Equal("req_id"))
 		Ω(logger.InfoEntries[0].Data[2]).Should(Equal("POST"))
 		Ω(logger.InfoEntries[0].Data[3]).Should(Equal("/goo?param=value"))
+		Ω(logger.InfoEntries[0].Data[10]).Should(Equal("bytes"))
+		Ω(logger.InfoEntries[0].Data[11]).Should(Equal(int64(14)))
 
 		Ω(logger.InfoEntries[1].Data).Should(HaveLen(4))
 		Ω(logger.InfoEntries[1].Data[0]).Should(Equal("req_id"))
@@ -84,10 +86,12 @@ var _ = Describe("LogRequest", func() {
 		lg := middleware.LogRequest(false)(middleware.ErrorHandler(service, false)(h))
 		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
 		Ω(logger.InfoEntries).Should(HaveLen(2))
-		Ω(logger.InfoEntries[0].Data).Should(HaveLen(10))
+		Ω(logger.InfoEntries[0].Data).Should(HaveLen(12))
 		Ω(logger.InfoEntries[0].Data[0]).Should(Equal("req_id"))
 		Ω(logger.InfoEntries[0].Data[2]).Should(Equal("POST"))
 		Ω(logger.InfoEntries[0].Data[3]).Should(Equal("/goo?param=value"))
+		Ω(logger.InfoEntries[0].Data[10]).Should(Equal("bytes"))
+		Ω(logger.InfoEntries[0].Data[11]).Should(Equal(int64(14)))
 
 		Ω(logger.InfoEntries[1].Data).Should(HaveLen(14))
 		Ω(logger.InfoEntries[1].Data[0]).Should(Equal("req_id"))
@@ -96,11 +100,54 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[1].Data[4]).Should(Equal("error"))
 		Ω(logger.InfoEntries[1].Data[5]).Should(HaveLen(8)) // Error ID
 		Ω(logger.InfoEntries[1].Data[6]).Should(Equal("bytes"))
-		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(124))
+		Ω(logger.InfoEntries[1].Data[7]).Should(Equal(179))
 		Ω(logger.InfoEntries[1].Data[8]).Should(Equal("time"))
 		Ω(logger.InfoEntries[1].Data[10]).Should(Equal("ctrl"))
 		Ω(logger.InfoEntries[1].Data[11]).Should(Equal("test"))
 		Ω(logger.InfoEntries[1].Data[12]).Should(Equal("action"))
 		Ω(logger.InfoEntries[1].Data[13]).Should(Equal("<unknown>"))
 	})
+
+	Context("with sensitive data", func() {
+		BeforeEach(func() {
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Api-Key", "key")
+			req.Header.Set("Accept", "application/json")
+			params = url.Values{"token": []string{"secret"}}
+			ctx = goa.NewContext(service.NewController("test").Context, rw, req, params)
+			goa.ContextRequest(ctx).Payload = &redactedPayload{Name: "n", Password: "secret"}
+		})
+
+		It("redacts the sensitive headers, params and payload fields", func() {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return service.Send(ctx, 200, "ok")
+			}
+			lg := middleware.LogRequest(true, middleware.RedactHeaders("x-api-key"), middleware.RedactFields("token"))(h)
+			Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(logger.InfoEntries).Should(HaveLen(5))
+
+			headers := logger.InfoEntries[1].Data
+			Ω(logger.InfoEntries[1].Msg).Should(Equal("headers"))
+			for i := 2; i < len(headers); i += 2 {
+				switch headers[i] {
+				case "Authorization", "X-Api-Key":
+					Ω(headers[i+1]).Should(Equal("[REDACTED]"))
+				case "Accept":
+					Ω(headers[i+1]).Should(Equal("application/json"))
+				}
+			}
+			Ω(logger.InfoEntries[2].Data).Should(Equal([]interface{}{"req_id", logger.InfoEntries[2].Data[1], "token", "[REDACTED]"}))
+			Ω(logger.InfoEntries[3].Data[2]).Should(Equal("raw"))
+			Ω(logger.InfoEntries[3].Data[3]).Should(MatchJSON(`{"name":"n","password":"[REDACTED]"}`))
+		})
+	})
 })
+
+type redactedPayload struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (p *redactedPayload) RedactedFields() []string {
+	return []string{"password"}
+}
@@ -18,12 +18,12 @@ type loggingResponseWriter struct {
 
 // Write will write raw data to logger and response writer.
 func (lrw *loggingResponseWriter) Write(buf []byte) (int, error) {
-	goa.LogInfo(lrw.ctx, "response", "body", string(buf))
+	goa.LogDebug(lrw.ctx, "response", "body", string(buf))
 	return lrw.ResponseWriter.Write(buf)
 }
 
 // LogResponse creates a response logger middleware.
-// Only Logs the raw response data without accumulating any statistics.
+// Only Logs the raw response data at the debug level without accumulating any statistics.
 func LogResponse() goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
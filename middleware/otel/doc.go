@@ -0,0 +1,18 @@
+/*
+Package goaotel provides OpenTelemetry instrumentation for goa services and clients.
+
+The middleware returned by New creates a server span for each request handled by the service and
+records request count, error count and duration metrics. The span and metric attributes are
+derived from the design: the service, resource and action names and the action route. WrapDoer
+and WrapTransport instrument the goa clients and HTTP transports used to make outgoing requests
+and propagate the trace context to the remote services.
+
+The package uses the global OpenTelemetry providers and propagators by default, use the
+WithTracerProvider, WithMeterProvider and WithPropagators options to override them:
+
+	service.Use(goaotel.New("cellar"))
+	service.Use(middleware.ErrorHandler(service, true))
+
+	c := client.New(goaotel.WrapDoer(goaclient.HTTPClientDoer(http.DefaultClient)))
+*/
+package goaotel
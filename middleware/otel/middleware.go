@@ -0,0 +1,85 @@
+package goaotel
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a middleware that creates an OpenTelemetry server span for each request and
+// records the "goa.server.requests" and "goa.server.errors" counters and the
+// "goa.server.duration" histogram.
+//
+// service is the name of the service reported in the "goa.service" attribute. The spans are
+// named after the controller and action handling the request, e.g. "bottle.show", and have the
+// "goa.resource", "goa.action", "http.route", "http.request.method" and
+// "http.response.status_code" attributes. The metrics use the same attributes.
+//
+// The middleware extracts the parent span from the request headers using the configured
+// propagators and stores the span in the request context so that requests made with a client
+// wrapped with WrapDoer or WrapTransport are traced as children of the span. Mount the
+// middleware before the ErrorHandler middleware so that it records the status of error
+// responses. Requests whose response status is 5xx are counted as errors.
+func New(service string, opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	tracer := o.tracerProvider.Tracer(instrumentationName)
+	inst := newInstruments(o.meterProvider, "goa.server")
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			ctx = o.propagators.Extract(ctx, propagation.HeaderCarrier(req.Header))
+			ctrl, action := goa.ContextController(ctx), goa.ContextAction(ctx)
+			attrs := []attribute.KeyValue{
+				ServiceKey.String(service),
+				ResourceKey.String(ctrl),
+				ActionKey.String(action),
+				MethodKey.String(req.Method),
+			}
+			if route := goa.ContextRoute(ctx); route != "" {
+				attrs = append(attrs, RouteKey.String(route))
+			}
+
+			started := time.Now()
+			ctx, span := tracer.Start(ctx, ctrl+"."+action,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...))
+			defer span.End()
+
+			err := h(ctx, rw, req)
+
+			status := responseStatus(ctx, err)
+			span.SetAttributes(StatusCodeKey.Int(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+			failed := status >= http.StatusInternalServerError
+			if failed {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			inst.record(ctx, started, failed, append(attrs, StatusCodeKey.Int(status))...)
+
+			return err
+		}
+	}
+}
+
+// responseStatus returns the status of the response written by the handler or the status of
+// the response built from the error returned by the handler if the response wasn't written.
+func responseStatus(ctx context.Context, err error) int {
+	if resp := goa.ContextResponse(ctx); resp != nil && resp.Written() {
+		return resp.Status
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+	if se, ok := err.(goa.ServiceError); ok {
+		return se.ResponseStatus()
+	}
+	return http.StatusInternalServerError
+}
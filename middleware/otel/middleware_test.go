@@ -0,0 +1,148 @@
+package goaotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentID = "00f067aa0ba902b7"
+)
+
+func TestNew(t *testing.T) {
+	cases := map[string]struct {
+		Err        error
+		Status     int
+		Failed     bool
+		SpanStatus codes.Code
+	}{
+		"ok":           {nil, http.StatusOK, false, codes.Unset},
+		"client error": {goa.ErrBadRequest("invalid"), http.StatusBadRequest, false, codes.Unset},
+		"server error": {goa.ErrInternal("boom"), http.StatusInternalServerError, true, codes.Error},
+	}
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			var (
+				recorder = tracetest.NewSpanRecorder()
+				reader   = sdkmetric.NewManualReader()
+				service  = goa.New("test")
+				ctrl     = service.NewController("bottle")
+				handled  trace.SpanContext
+			)
+			ctrl.Use(New("cellar",
+				WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+				WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				WithPropagators(propagation.TraceContext{})))
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				handled = trace.SpanContextFromContext(ctx)
+				if c.Err == nil {
+					rw.WriteHeader(c.Status)
+				}
+				return c.Err
+			}
+			service.Mux.Handle("GET", "/bottles/:id", ctrl.MuxHandler("show", h, nil))
+
+			req := httptest.NewRequest("GET", "/bottles/1", nil)
+			req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+			service.Mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, expected 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != "bottle.show" {
+				t.Errorf("got span name %q, expected %q", span.Name(), "bottle.show")
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("got span kind %s, expected server", span.SpanKind())
+			}
+			if span.Parent().TraceID().String() != traceID || span.Parent().SpanID().String() != parentID {
+				t.Errorf("got parent %s/%s, expected %s/%s", span.Parent().TraceID(), span.Parent().SpanID(), traceID, parentID)
+			}
+			if handled.SpanID() != span.SpanContext().SpanID() {
+				t.Errorf("span not stored in the handler context")
+			}
+			if span.Status().Code != c.SpanStatus {
+				t.Errorf("got span status %s, expected %s", span.Status().Code, c.SpanStatus)
+			}
+			expected := []attribute.KeyValue{
+				ServiceKey.String("cellar"),
+				ResourceKey.String("bottle"),
+				ActionKey.String("show"),
+				MethodKey.String("GET"),
+				RouteKey.String("/bottles/:id"),
+				StatusCodeKey.Int(c.Status),
+			}
+			attrs := attribute.NewSet(span.Attributes()...)
+			for _, kv := range expected {
+				if v, ok := attrs.Value(kv.Key); !ok || v != kv.Value {
+					t.Errorf("got %s attribute %v, expected %v", kv.Key, v.Emit(), kv.Value.Emit())
+				}
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+			expectedErrors := int64(0)
+			if c.Failed {
+				expectedErrors = 1
+			}
+			if n := sum(rm, "goa.server.requests"); n != 1 {
+				t.Errorf("got %d requests, expected 1", n)
+			}
+			if n := sum(rm, "goa.server.errors"); n != expectedErrors {
+				t.Errorf("got %d errors, expected %d", n, expectedErrors)
+			}
+			if n := count(rm, "goa.server.duration"); n != 1 {
+				t.Errorf("got %d durations, expected 1", n)
+			}
+		})
+	}
+}
+
+// sum returns the sum of the data points of the counter with the given name.
+func sum(rm metricdata.ResourceMetrics, name string) int64 {
+	var n int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				n += dp.Value
+			}
+		}
+	}
+	return n
+}
+
+// count returns the number of values recorded by the histogram with the given name.
+func count(rm metricdata.ResourceMetrics, name string) uint64 {
+	var n uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				n += dp.Count
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,127 @@
+package goaotel
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the instrumentation library reported with the spans and
+// metrics.
+const instrumentationName = "github.com/goadesign/goa/middleware/otel"
+
+// Attribute keys set on the spans and metrics.
+const (
+	// ServiceKey is the name of the goa service as given to New.
+	ServiceKey attribute.Key = "goa.service"
+	// ResourceKey is the name of the resource (controller) handling the request.
+	ResourceKey attribute.Key = "goa.resource"
+	// ActionKey is the name of the action handling the request.
+	ActionKey attribute.Key = "goa.action"
+	// RouteKey is the path pattern of the action route, e.g. "/bottles/:id".
+	RouteKey attribute.Key = "http.route"
+	// MethodKey is the HTTP method of the request.
+	MethodKey attribute.Key = "http.request.method"
+	// StatusCodeKey is the HTTP status code of the response.
+	StatusCodeKey attribute.Key = "http.response.status_code"
+	// ServerAddressKey is the host of outgoing requests.
+	ServerAddressKey attribute.Key = "server.address"
+	// URLKey is the URL of outgoing requests.
+	URLKey attribute.Key = "url.full"
+)
+
+type (
+	// Option is the type of the functions used to configure the middleware and transports.
+	Option func(*options)
+
+	// options contains the OpenTelemetry providers used to create spans and metrics.
+	options struct {
+		tracerProvider trace.TracerProvider
+		meterProvider  metric.MeterProvider
+		propagators    propagation.TextMapPropagator
+	}
+
+	// instruments contains the metric instruments recorded for each request.
+	instruments struct {
+		requests metric.Int64Counter
+		errors   metric.Int64Counter
+		duration metric.Float64Histogram
+	}
+)
+
+// WithTracerProvider sets the provider used to create spans. The global provider is used by
+// default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the provider used to create the metric instruments. The global provider
+// is used by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to extract the trace context from incoming requests
+// and to inject it in outgoing requests. The global propagators are used by default.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(o *options) {
+		o.propagators = p
+	}
+}
+
+// newOptions returns the options resulting from applying opts to the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagators:    otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// newInstruments creates the request count, error count and duration instruments whose names
+// start with prefix. Errors are reported to the global OpenTelemetry error handler.
+func newInstruments(mp metric.MeterProvider, prefix string) *instruments {
+	meter := mp.Meter(instrumentationName)
+	requests, err := meter.Int64Counter(prefix+".requests",
+		metric.WithDescription("Number of requests."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	errors, err := meter.Int64Counter(prefix+".errors",
+		metric.WithDescription("Number of requests that failed with a server error."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	duration, err := meter.Float64Histogram(prefix+".duration",
+		metric.WithDescription("Duration of requests."),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return &instruments{requests: requests, errors: errors, duration: duration}
+}
+
+// record records a request that started at the given time.
+func (i *instruments) record(ctx context.Context, started time.Time, failed bool, attrs ...attribute.KeyValue) {
+	set := metric.WithAttributes(attrs...)
+	i.requests.Add(ctx, 1, set)
+	i.duration.Record(ctx, time.Since(started).Seconds(), set)
+	if failed {
+		i.errors.Add(ctx, 1, set)
+	}
+}
@@ -0,0 +1,115 @@
+package goaotel
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/goadesign/goa/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// wrapDoer is a client.Doer middleware that creates client spans for outgoing requests.
+	wrapDoer struct {
+		wrapped client.Doer
+		tracer  *clientTracer
+	}
+
+	// otelTransport is a http.RoundTripper middleware that creates client spans for outgoing
+	// requests.
+	otelTransport struct {
+		wrapped http.RoundTripper
+		tracer  *clientTracer
+	}
+
+	// clientTracer creates the spans and records the metrics of outgoing requests.
+	clientTracer struct {
+		tracer      trace.Tracer
+		propagators propagation.TextMapPropagator
+		inst        *instruments
+	}
+)
+
+var _ client.Doer = (*wrapDoer)(nil)
+
+// WrapDoer wraps a goa client Doer and creates an OpenTelemetry client span for each request.
+// The span is a child of the span contained in the request context if any, see New. The
+// wrapper injects the trace context in the request headers using the configured propagators and
+// records the "goa.client.requests" and "goa.client.errors" counters and the
+// "goa.client.duration" histogram with the "http.request.method", "server.address" and
+// "http.response.status_code" attributes.
+func WrapDoer(wrapped client.Doer, opts ...Option) client.Doer {
+	return &wrapDoer{wrapped: wrapped, tracer: newClientTracer(opts)}
+}
+
+// WrapTransport wraps a http RoundTripper with a RoundTripper which creates OpenTelemetry client
+// spans the same way as WrapDoer. The parent span is read from the request context.
+//
+// Example of how to wrap http.Client's transport:
+//   httpClient := &http.Client{
+//     Transport: WrapTransport(http.DefaultTransport),
+//   }
+func WrapTransport(rt http.RoundTripper, opts ...Option) http.RoundTripper {
+	return &otelTransport{wrapped: rt, tracer: newClientTracer(opts)}
+}
+
+// Do calls through to the wrapped Doer, creating a span for the request.
+func (d *wrapDoer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return d.tracer.roundTrip(ctx, req, d.wrapped.Do)
+}
+
+// RoundTrip calls through to the wrapped RoundTripper, creating a span for the request.
+func (t *otelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.tracer.roundTrip(req.Context(), req, func(_ context.Context, req *http.Request) (*http.Response, error) {
+		return t.wrapped.RoundTrip(req)
+	})
+}
+
+// newClientTracer creates a client tracer configured with the given options.
+func newClientTracer(opts []Option) *clientTracer {
+	o := newOptions(opts)
+	return &clientTracer{
+		tracer:      o.tracerProvider.Tracer(instrumentationName),
+		propagators: o.propagators,
+		inst:        newInstruments(o.meterProvider, "goa.client"),
+	}
+}
+
+// roundTrip creates the span of the request, injects the trace context in a copy of the request
+// and calls do with the copy.
+func (c *clientTracer) roundTrip(ctx context.Context, req *http.Request, do func(context.Context, *http.Request) (*http.Response, error)) (*http.Response, error) {
+	attrs := []attribute.KeyValue{
+		MethodKey.String(req.Method),
+		ServerAddressKey.String(req.URL.Host),
+	}
+
+	started := time.Now()
+	ctx, span := c.tracer.Start(ctx, req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, URLKey.String(req.URL.Redacted()))...))
+	defer span.End()
+
+	req = req.Clone(ctx)
+	c.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := do(ctx, req)
+
+	failed := err != nil
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes(StatusCodeKey.Int(resp.StatusCode))
+		attrs = append(attrs, StatusCodeKey.Int(resp.StatusCode))
+		failed = resp.StatusCode >= http.StatusInternalServerError
+	}
+	if failed {
+		span.SetStatus(codes.Error, "request failed")
+	}
+	c.inst.record(ctx, started, failed, attrs...)
+
+	return resp, err
+}
@@ -0,0 +1,110 @@
+package goaotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goadesign/goa/client"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWrap(t *testing.T) {
+	cases := map[string]struct {
+		Status int
+		Failed bool
+	}{
+		"ok":           {http.StatusOK, false},
+		"server error": {http.StatusServiceUnavailable, true},
+	}
+	wrappers := map[string]func(...Option) client.Doer{
+		"doer": func(opts ...Option) client.Doer {
+			return WrapDoer(client.HTTPClientDoer(http.DefaultClient), opts...)
+		},
+		"transport": func(opts ...Option) client.Doer {
+			return client.HTTPClientDoer(&http.Client{Transport: WrapTransport(http.DefaultTransport, opts...)})
+		},
+	}
+	for w, wrap := range wrappers {
+		for k, c := range cases {
+			t.Run(w+" "+k, func(t *testing.T) {
+				var traceparent string
+				srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					traceparent = req.Header.Get("traceparent")
+					rw.WriteHeader(c.Status)
+				}))
+				defer srv.Close()
+				var (
+					recorder = tracetest.NewSpanRecorder()
+					reader   = sdkmetric.NewManualReader()
+					doer     = wrap(
+						WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+						WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+						WithPropagators(propagation.TraceContext{}))
+				)
+				tid, _ := trace.TraceIDFromHex(traceID)
+				sid, _ := trace.SpanIDFromHex(parentID)
+				ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+					TraceID:    tid,
+					SpanID:     sid,
+					TraceFlags: trace.FlagsSampled,
+				}))
+				req, _ := http.NewRequest("GET", srv.URL+"/bottles", nil)
+
+				resp, err := doer.Do(ctx, req.WithContext(ctx))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+
+				spans := recorder.Ended()
+				if len(spans) != 1 {
+					t.Fatalf("got %d spans, expected 1", len(spans))
+				}
+				span := spans[0]
+				if span.SpanKind() != trace.SpanKindClient {
+					t.Errorf("got span kind %s, expected client", span.SpanKind())
+				}
+				if span.Parent().SpanID() != sid {
+					t.Errorf("got parent span %s, expected %s", span.Parent().SpanID(), sid)
+				}
+				expected := "00-" + traceID + "-" + span.SpanContext().SpanID().String() + "-01"
+				if traceparent != expected {
+					t.Errorf("got traceparent %q, expected %q", traceparent, expected)
+				}
+				if req.Header.Get("traceparent") != "" {
+					t.Errorf("original request modified")
+				}
+				spanStatus := codes.Unset
+				if c.Failed {
+					spanStatus = codes.Error
+				}
+				if span.Status().Code != spanStatus {
+					t.Errorf("got span status %s, expected %s", span.Status().Code, spanStatus)
+				}
+
+				var rm metricdata.ResourceMetrics
+				if err := reader.Collect(context.Background(), &rm); err != nil {
+					t.Fatal(err)
+				}
+				expectedErrors := int64(0)
+				if c.Failed {
+					expectedErrors = 1
+				}
+				if n := sum(rm, "goa.client.requests"); n != 1 {
+					t.Errorf("got %d requests, expected 1", n)
+				}
+				if n := sum(rm, "goa.client.errors"); n != expectedErrors {
+					t.Errorf("got %d errors, expected %d", n, expectedErrors)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package goaprometheus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type (
+	// Option is the type of the functions used to configure the middleware.
+	Option func(*options)
+
+	// options contains the registry and histogram buckets used by the middleware.
+	options struct {
+		registerer prometheus.Registerer
+		gatherer   prometheus.Gatherer
+		buckets    []float64
+	}
+)
+
+// labels are the names of the labels of the request metrics.
+var labels = []string{"service", "endpoint", "code"}
+
+// WithRegistry sets the registry used to register the metrics and to serve them with Mount. The
+// default Prometheus registry is used by default.
+func WithRegistry(r *prometheus.Registry) Option {
+	return func(o *options) {
+		o.registerer = r
+		o.gatherer = r
+	}
+}
+
+// WithBuckets sets the buckets of the request duration histogram. prometheus.DefBuckets is used
+// by default.
+func WithBuckets(buckets ...float64) Option {
+	return func(o *options) {
+		o.buckets = buckets
+	}
+}
+
+// New returns a middleware that records the "goa_requests_total" counter and the
+// "goa_request_duration_seconds" histogram. Both metrics are labeled with the name of the
+// service, the endpoint (the controller and action names, e.g. "bottle.show") and the response
+// status code.
+//
+// Mount the middleware before the ErrorHandler middleware so that it records the status of error
+// responses. New may be called multiple times with the same registry, for example to mount the
+// middleware on different controllers, the metrics are only registered once.
+func New(service string, opts ...Option) goa.Middleware {
+	o := newOptions(opts)
+	requests := register(o.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "goa_requests_total",
+		Help: "Number of requests by service, endpoint and status code.",
+	}, labels)).(*prometheus.CounterVec)
+	duration := register(o.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "goa_request_duration_seconds",
+		Help:    "Duration of requests by service, endpoint and status code.",
+		Buckets: o.buckets,
+	}, labels)).(*prometheus.HistogramVec)
+
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			started := time.Now()
+			err := h(ctx, rw, req)
+			vals := []string{
+				service,
+				goa.ContextController(ctx) + "." + goa.ContextAction(ctx),
+				strconv.Itoa(responseStatus(ctx, err)),
+			}
+			requests.WithLabelValues(vals...).Inc()
+			duration.WithLabelValues(vals...).Observe(time.Since(started).Seconds())
+			return err
+		}
+	}
+}
+
+// Mount registers a handler serving the metrics in the Prometheus text format on the service
+// mux under the given path, typically "/metrics". The handler is not a controller action so
+// that the service middlewares do not apply to it.
+func Mount(service *goa.Service, path string, opts ...Option) {
+	h := promhttp.HandlerFor(newOptions(opts).gatherer, promhttp.HandlerOpts{})
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		h.ServeHTTP(rw, req)
+	})
+}
+
+// newOptions returns the options resulting from applying opts to the defaults.
+func newOptions(opts []Option) *options {
+	o := &options{
+		registerer: prometheus.DefaultRegisterer,
+		gatherer:   prometheus.DefaultGatherer,
+		buckets:    prometheus.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// register registers c with r and returns it. It returns the collector that was previously
+// registered if any.
+func register(r prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := r.Register(c); err != nil {
+		if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			return are.ExistingCollector
+		}
+		panic(err)
+	}
+	return c
+}
+
+// responseStatus returns the status of the response written by the handler or the status of
+// the response built from the error returned by the handler if the response wasn't written.
+func responseStatus(ctx context.Context, err error) int {
+	if resp := goa.ContextResponse(ctx); resp != nil && resp.Written() {
+		return resp.Status
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+	if se, ok := err.(goa.ServiceError); ok {
+		return se.ResponseStatus()
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,53 @@
+package goaprometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goadesign/goa"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNew(t *testing.T) {
+	var (
+		reg     = prometheus.NewRegistry()
+		service = goa.New("test")
+		ctrl    = service.NewController("bottle")
+		actrl   = service.NewController("account")
+	)
+	ctrl.Use(New("cellar", WithRegistry(reg)))
+	actrl.Use(New("cellar", WithRegistry(reg))) // reuses the registered metrics
+	show := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		rw.WriteHeader(http.StatusOK)
+		return nil
+	}
+	fail := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return goa.ErrNotFound("not found")
+	}
+	service.Mux.Handle("GET", "/bottles/:id", ctrl.MuxHandler("show", show, nil))
+	service.Mux.Handle("DELETE", "/accounts/:id", actrl.MuxHandler("delete", fail, nil))
+	Mount(service, "/metrics", WithRegistry(reg))
+
+	service.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/bottles/1", nil))
+	service.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/accounts/1", nil))
+	rw := httptest.NewRecorder()
+	service.Mux.ServeHTTP(rw, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rw.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected 200", rw.Code)
+	}
+	body := rw.Body.String()
+	expected := []string{
+		`goa_requests_total{code="200",endpoint="bottle.show",service="cellar"} 1`,
+		`goa_requests_total{code="404",endpoint="account.delete",service="cellar"} 1`,
+		`goa_request_duration_seconds_count{code="200",endpoint="bottle.show",service="cellar"} 1`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(body, e) {
+			t.Errorf("metrics do not contain %q:\n%s", e, body)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader is the name of the W3C Trace Context header containing the trace ID,
+	// parent span ID and trace flags, see https://www.w3.org/TR/trace-context/.
+	TraceparentHeader = "traceparent"
+
+	// B3Header is the name of the B3 single header, see https://github.com/openzipkin/b3-propagation.
+	B3Header = "b3"
+
+	// B3TraceIDHeader is the name of the B3 multi-header containing the trace ID.
+	B3TraceIDHeader = "X-B3-TraceId"
+
+	// B3SpanIDHeader is the name of the B3 multi-header containing the span ID.
+	B3SpanIDHeader = "X-B3-SpanId"
+
+	// B3SampledHeader is the name of the B3 multi-header containing the sampling decision.
+	B3SampledHeader = "X-B3-Sampled"
+
+	// B3FlagsHeader is the name of the B3 multi-header containing the debug flag.
+	B3FlagsHeader = "X-B3-Flags"
+)
+
+var (
+	// DefaultPropagator propagates the trace context using the TraceIDHeader and
+	// ParentSpanIDHeader headers.
+	DefaultPropagator Propagator = defaultPropagator{}
+
+	// W3CPropagator propagates the trace context using the W3C Trace Context traceparent
+	// header. It requires trace IDs made of 32 and span IDs made of 16 hexadecimal digits,
+	// see HexTraceID and HexSpanID.
+	W3CPropagator Propagator = w3cPropagator{}
+
+	// B3Propagator propagates the trace context using the Zipkin B3 headers. It extracts the
+	// trace context from both the single and multi-header formats and injects the
+	// multi-header format. It requires hexadecimal trace and span IDs, see HexTraceID and
+	// HexSpanID.
+	B3Propagator Propagator = b3Propagator{}
+)
+
+type (
+	// Propagator extracts the trace context from the headers of incoming requests and injects
+	// it into the headers of outgoing requests.
+	Propagator interface {
+		// Extract returns the trace ID, the parent span ID and the sampling decision
+		// contained in h. traceID is empty if h does not contain a trace context.
+		Extract(h http.Header) (traceID, parentID string, sampled bool)
+		// Inject sets the headers that propagate the given trace context in h.
+		Inject(h http.Header, traceID, spanID string, sampled bool)
+	}
+
+	// defaultPropagator is the propagator that uses the goa tracing headers.
+	defaultPropagator struct{}
+
+	// w3cPropagator is the W3C Trace Context propagator.
+	w3cPropagator struct{}
+
+	// b3Propagator is the Zipkin B3 propagator.
+	b3Propagator struct{}
+)
+
+// HexTraceID returns a random trace ID made of 32 hexadecimal digits compatible with the W3C
+// Trace Context and B3 formats.
+func HexTraceID() string {
+	return randomHex(16)
+}
+
+// HexSpanID returns a random span ID made of 16 hexadecimal digits compatible with the W3C Trace
+// Context and B3 formats.
+func HexSpanID() string {
+	return randomHex(8)
+}
+
+// Extract reads the TraceIDHeader and ParentSpanIDHeader headers.
+func (defaultPropagator) Extract(h http.Header) (string, string, bool) {
+	traceID := h.Get(TraceIDHeader)
+	if traceID == "" {
+		return "", "", false
+	}
+	return traceID, h.Get(ParentSpanIDHeader), true
+}
+
+// Inject sets the TraceIDHeader and ParentSpanIDHeader headers.
+func (defaultPropagator) Inject(h http.Header, traceID, spanID string, _ bool) {
+	h.Set(TraceIDHeader, traceID)
+	h.Set(ParentSpanIDHeader, spanID)
+}
+
+// Extract reads the traceparent header.
+func (w3cPropagator) Extract(h http.Header) (string, string, bool) {
+	elems := strings.Split(strings.TrimSpace(h.Get(TraceparentHeader)), "-")
+	if len(elems) < 4 || len(elems[0]) != 2 || elems[0] == "ff" || (elems[0] == "00" && len(elems) != 4) {
+		return "", "", false
+	}
+	traceID, parentID, flags := elems[1], elems[2], elems[3]
+	if !isHexID(traceID, 32) || !isHexID(parentID, 16) || len(flags) != 2 {
+		return "", "", false
+	}
+	f, err := hex.DecodeString(flags)
+	if err != nil {
+		return "", "", false
+	}
+	return traceID, parentID, f[0]&1 == 1
+}
+
+// Inject sets the traceparent header.
+func (w3cPropagator) Inject(h http.Header, traceID, spanID string, sampled bool) {
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	h.Set(TraceparentHeader, fmt.Sprintf("00-%s-%s-%s", traceID, spanID, flags))
+}
+
+// Extract reads the b3 single header or the X-B3 multi-headers.
+func (b3Propagator) Extract(h http.Header) (string, string, bool) {
+	if single := h.Get(B3Header); single != "" {
+		elems := strings.Split(single, "-")
+		if len(elems) < 2 || !isHexID(elems[0], 16, 32) || !isHexID(elems[1], 16) {
+			return "", "", false
+		}
+		sampled := true
+		if len(elems) > 2 {
+			sampled = elems[2] == "1" || elems[2] == "d"
+		}
+		return elems[0], elems[1], sampled
+	}
+	traceID, spanID := h.Get(B3TraceIDHeader), h.Get(B3SpanIDHeader)
+	if !isHexID(traceID, 16, 32) || !isHexID(spanID, 16) {
+		return "", "", false
+	}
+	sampled := true
+	if s := h.Get(B3SampledHeader); s != "" {
+		sampled = s == "1" || s == "true"
+	}
+	if h.Get(B3FlagsHeader) == "1" {
+		sampled = true
+	}
+	return traceID, spanID, sampled
+}
+
+// Inject sets the X-B3 multi-headers.
+func (b3Propagator) Inject(h http.Header, traceID, spanID string, sampled bool) {
+	h.Set(B3TraceIDHeader, traceID)
+	h.Set(B3SpanIDHeader, spanID)
+	s := "0"
+	if sampled {
+		s = "1"
+	}
+	h.Set(B3SampledHeader, s)
+}
+
+// isHexID returns true if id is made of one of the given number of lower case hexadecimal digits
+// and is not all zeroes.
+func isHexID(id string, lengths ...int) bool {
+	valid := false
+	for _, l := range lengths {
+		if len(id) == l {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return false
+	}
+	zero := true
+	for _, c := range id {
+		switch {
+		case c == '0':
+		case c >= '1' && c <= '9', c >= 'a' && c <= 'f':
+			zero = false
+		default:
+			return false
+		}
+	}
+	return !zero
+}
+
+// randomHex returns the hexadecimal encoding of n random bytes.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestPropagatorsExtract(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	cases := map[string]struct {
+		Propagator Propagator
+		Headers    map[string]string
+		// output
+		TraceID, ParentID string
+		Sampled           bool
+	}{
+		"default":          {DefaultPropagator, map[string]string{TraceIDHeader: "trace", ParentSpanIDHeader: "parent"}, "trace", "parent", true},
+		"default-no-trace": {DefaultPropagator, nil, "", "", false},
+
+		"w3c":             {W3CPropagator, map[string]string{TraceparentHeader: "00-" + traceID + "-" + spanID + "-01"}, traceID, spanID, true},
+		"w3c-not-sampled": {W3CPropagator, map[string]string{TraceparentHeader: "00-" + traceID + "-" + spanID + "-00"}, traceID, spanID, false},
+		"w3c-future":      {W3CPropagator, map[string]string{TraceparentHeader: "01-" + traceID + "-" + spanID + "-01-extra"}, traceID, spanID, true},
+		"w3c-zero-trace":  {W3CPropagator, map[string]string{TraceparentHeader: "00-00000000000000000000000000000000-" + spanID + "-01"}, "", "", false},
+		"w3c-uppercase":   {W3CPropagator, map[string]string{TraceparentHeader: "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + spanID + "-01"}, "", "", false},
+		"w3c-invalid":     {W3CPropagator, map[string]string{TraceparentHeader: "00-" + traceID + "-01"}, "", "", false},
+
+		"b3-single":          {B3Propagator, map[string]string{B3Header: traceID + "-" + spanID + "-1"}, traceID, spanID, true},
+		"b3-single-deny":     {B3Propagator, map[string]string{B3Header: traceID + "-" + spanID + "-0"}, traceID, spanID, false},
+		"b3-single-deferred": {B3Propagator, map[string]string{B3Header: spanID + "-" + spanID}, spanID, spanID, true},
+		"b3-single-only-0":   {B3Propagator, map[string]string{B3Header: "0"}, "", "", false},
+		"b3-multi":           {B3Propagator, map[string]string{B3TraceIDHeader: traceID, B3SpanIDHeader: spanID, B3SampledHeader: "0"}, traceID, spanID, false},
+		"b3-multi-debug":     {B3Propagator, map[string]string{B3TraceIDHeader: traceID, B3SpanIDHeader: spanID, B3SampledHeader: "0", B3FlagsHeader: "1"}, traceID, spanID, true},
+	}
+
+	for k, c := range cases {
+		h := make(http.Header)
+		for n, v := range c.Headers {
+			h.Set(n, v)
+		}
+		traceID, parentID, sampled := c.Propagator.Extract(h)
+		if traceID != c.TraceID {
+			t.Errorf("%s: invalid TraceID, expected %v - got %v", k, c.TraceID, traceID)
+		}
+		if parentID != c.ParentID {
+			t.Errorf("%s: invalid ParentID, expected %v - got %v", k, c.ParentID, parentID)
+		}
+		if sampled != c.Sampled {
+			t.Errorf("%s: invalid sampling decision, expected %v - got %v", k, c.Sampled, sampled)
+		}
+	}
+}
+
+func TestPropagatorsInject(t *testing.T) {
+	traceID, spanID := HexTraceID(), HexSpanID()
+	if len(traceID) != 32 || len(spanID) != 16 {
+		t.Fatalf("invalid hex IDs %q and %q", traceID, spanID)
+	}
+	for _, p := range []Propagator{DefaultPropagator, W3CPropagator, B3Propagator} {
+		h := make(http.Header)
+		p.Inject(h, traceID, spanID, true)
+		tid, pid, sampled := p.Extract(h)
+		if tid != traceID || pid != spanID || !sampled {
+			t.Errorf("%T: round trip failed, got %q, %q, %v", p, tid, pid, sampled)
+		}
+	}
+	h := make(http.Header)
+	W3CPropagator.Inject(h, traceID, spanID, false)
+	if got, expected := h.Get(TraceparentHeader), "00-"+traceID+"-"+spanID+"-00"; got != expected {
+		t.Errorf("invalid traceparent header, expected %v - got %v", expected, got)
+	}
+}
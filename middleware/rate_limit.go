@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// maxRateLimitBuckets is the number of buckets above which the rate limiter discards the buckets
+// that are full.
+const maxRateLimitBuckets = 10000
+
+type (
+	// RateLimitKey computes the key that identifies the client of a request for rate limiting
+	// purposes. Requests with the same key share the same token bucket.
+	RateLimitKey func(*http.Request) string
+
+	// rateLimiter implements a token bucket rate limiter with one bucket per key.
+	rateLimiter struct {
+		rate    float64
+		burst   float64
+		mu      sync.Mutex
+		buckets map[string]*bucket
+	}
+
+	// bucket contains the tokens available to a client.
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+)
+
+// RateLimit returns a middleware that limits the rate of requests to rps requests per second
+// with bursts of up to burst requests using a token bucket. key computes the bucket used by each
+// request, for example RemoteIP or HeaderKey("X-API-Key"). All requests share the same bucket if
+// key is nil. Requests that exceed the limit are rejected with ErrTooManyRequests and the
+// Retry-After header indicates when the client may retry.
+//
+// Each call to RateLimit creates a new rate limiter so that the generated code for actions
+// that use the RateLimit DSL creates one limiter per action.
+func RateLimit(rps float64, burst int, key RateLimitKey) goa.Middleware {
+	l := &rateLimiter{rate: rps, burst: float64(burst), buckets: make(map[string]*bucket)}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var k string
+			if key != nil {
+				k = key(req)
+			}
+			if wait := l.take(k, time.Now()); wait > 0 {
+				rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				return goa.ErrTooManyRequests("rate limit exceeded")
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// RemoteIP is a RateLimitKey that identifies clients using the IP address of the request remote
+// address.
+func RemoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// HeaderKey returns a RateLimitKey that identifies clients using the value of the given request
+// header, typically the header containing the API key.
+func HeaderKey(name string) RateLimitKey {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// take consumes a token from the bucket with the given key. It returns 0 if a token was
+// available or the duration until the next token is available otherwise.
+func (l *rateLimiter) take(key string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateLimitBuckets {
+			l.discardFull(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// discardFull deletes the buckets that have been refilled, they are recreated on demand.
+func (l *rateLimiter) discardFull(now time.Time) {
+	for k, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, k)
+		}
+	}
+}
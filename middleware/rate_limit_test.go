@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimit", func() {
+	var (
+		key     middleware.RateLimitKey
+		handler goa.Handler
+	)
+
+	BeforeEach(func() {
+		key = nil
+	})
+
+	JustBeforeEach(func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return nil
+		}
+		handler = middleware.RateLimit(0.5, 2, key)(h)
+	})
+
+	call := func(remoteAddr string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		rw := httptest.NewRecorder()
+		return rw, handler(context.Background(), rw, req)
+	}
+
+	It("rejects the requests exceeding the burst", func() {
+		for i := 0; i < 2; i++ {
+			_, err := call("10.0.0.1:1234")
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+		rw, err := call("10.0.0.2:1234")
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusTooManyRequests))
+		Ω(rw.Header().Get("Retry-After")).Should(Equal("2"))
+	})
+
+	Context("keyed by remote IP", func() {
+		BeforeEach(func() {
+			key = middleware.RemoteIP
+		})
+
+		It("uses one bucket per client", func() {
+			for i := 0; i < 2; i++ {
+				_, err := call("10.0.0.1:1234")
+				Ω(err).ShouldNot(HaveOccurred())
+			}
+			_, err := call("10.0.0.1:4321")
+			Ω(err).Should(HaveOccurred())
+			_, err = call("10.0.0.2:1234")
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
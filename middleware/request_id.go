@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
 
 	"context"
 )
@@ -59,6 +60,9 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 				id = id[:lengthLimit]
 			}
 			ctx = context.WithValue(ctx, reqIDKey, id)
+			ctx = client.SetContextRequestID(ctx, id)
+			ctx = goa.WithLogContext(ctx, "req_id", id)
+			rw.Header().Set(requestIDHeader, id)
 
 			return h(ctx, rw, req)
 		}
@@ -66,8 +70,12 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 }
 
 // RequestID is a middleware that injects a request ID into the context of each request.
-// Retrieve it using ctx.Value(ReqIDKey). If the incoming request has a RequestIDHeader header then
-// that value is used else a random value is generated.
+// Retrieve it using ContextRequestID. If the incoming request has a RequestIDHeader header then
+// that value is used else a random value is generated. The middleware propagates the request ID:
+// it adds it to the context logger under the "req_id" key, writes it back in the response
+// RequestIDHeader header and stores it in the context so that the requests made with the goa
+// client package using the same context carry it. The ErrorHandler middleware also includes it
+// in the meta of the error responses.
 func RequestID() goa.Middleware {
 	return RequestIDWithHeader(RequestIDHeader)
 }
@@ -1,12 +1,14 @@
 package middleware_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/url"
 
 	"context"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
 	"github.com/goadesign/goa/middleware"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -27,7 +29,7 @@ var _ = Describe("RequestID", func() {
 		req, err = http.NewRequest("GET", "/goo", nil)
 		Ω(err).ShouldNot(HaveOccurred())
 		req.Header.Set(middleware.RequestIDHeader, reqID)
-		rw = new(testResponseWriter)
+		rw = newTestResponseWriter()
 		params = url.Values{"query": []string{"value"}}
 		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
 		ctx = newContext(service, rw, req, params)
@@ -83,6 +85,23 @@ var _ = Describe("RequestID", func() {
 		Ω(middleware.ContextRequestID(newCtx)).Should(Equal(string(original)))
 	})
 
+	It("propagates the request ID", func() {
+		var newCtx context.Context
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			newCtx = ctx
+			return goa.ErrBadRequest("bad")
+		}
+		logger := new(testLogger)
+		ctx = goa.WithLogger(ctx, logger)
+		rg := middleware.RequestID()(middleware.ErrorHandler(service, false)(h))
+		Ω(rg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get(middleware.RequestIDHeader)).Should(Equal(reqID))
+		Ω(client.ContextRequestID(newCtx)).Should(Equal(reqID))
+		Ω(logger.Context).Should(Equal([]interface{}{"req_id", reqID}))
+		var body map[string]interface{}
+		Ω(json.Unmarshal(rw.(*testResponseWriter).Body, &body)).ShouldNot(HaveOccurred())
+		Ω(body["meta"]).Should(HaveKeyWithValue("request_id", reqID))
+	})
 })
 
 func makeRequestID(length int) string {
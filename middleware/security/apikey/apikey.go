@@ -0,0 +1,86 @@
+package apikey
+
+import (
+	"fmt"
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type contextKey int
+
+const (
+	apiKeyKey contextKey = iota + 1
+)
+
+// ErrAPIKeyFailed means the API key was missing or invalid.
+var ErrAPIKeyFailed = goa.NewErrorClass("api_key_failed", 401)
+
+// New returns a middleware to be used with the APIKeySecurity DSL definitions of goa. The
+// middleware reads the API key from the request header, query string parameter or cookie defined
+// in the design and calls validationFunc with its value. The request is rejected if the key is
+// missing or if validationFunc returns an error. The key is stored in the request context, use
+// ContextAPIKey to retrieve it in the action handlers.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	app.UseAPIKeyMiddleware(service, apikey.New(app.NewAPIKeySecurity(), func(ctx context.Context, key string) error {
+//		if key != "secret" {
+//			return apikey.ErrAPIKeyFailed("invalid key")
+//		}
+//		return nil
+//	}))
+func New(scheme *goa.APIKeySecurity, validationFunc func(ctx context.Context, key string) error) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key, err := extractKey(req, scheme)
+			if err != nil {
+				return err
+			}
+			if validationFunc != nil {
+				if err := validationFunc(ctx, key); err != nil {
+					return err
+				}
+			}
+			return h(WithAPIKey(ctx, key), rw, req)
+		}
+	}
+}
+
+// WithAPIKey creates a child context containing the given API key.
+func WithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// ContextAPIKey retrieves the API key from a `context` that went through the API key middleware.
+func ContextAPIKey(ctx context.Context) string {
+	key, ok := ctx.Value(apiKeyKey).(string)
+	if !ok {
+		return ""
+	}
+	return key
+}
+
+// extractKey reads the API key from the request location defined by the security scheme.
+func extractKey(req *http.Request, scheme *goa.APIKeySecurity) (string, error) {
+	var key string
+	switch scheme.In {
+	case goa.LocHeader:
+		key = req.Header.Get(scheme.Name)
+	case goa.LocQuery:
+		key = req.URL.Query().Get(scheme.Name)
+	case goa.LocCookie:
+		if c, err := req.Cookie(scheme.Name); err == nil {
+			key = c.Value
+		}
+	default:
+		return "", fmt.Errorf("security scheme with location (in) %q not supported", scheme.In)
+	}
+	if key == "" {
+		return "", ErrAPIKeyFailed(fmt.Sprintf("missing API key %s %q", scheme.In, scheme.Name))
+	}
+	return key, nil
+}
@@ -0,0 +1,94 @@
+package apikey_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/apikey"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var scheme *goa.APIKeySecurity
+	var validate func(context.Context, string) error
+	var request *http.Request
+	var fetchedKey string
+	var dispatchResult error
+
+	BeforeEach(func() {
+		scheme = &goa.APIKeySecurity{In: goa.LocHeader, Name: "X-API-Key"}
+		validate = nil
+		fetchedKey = ""
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			fetchedKey = apikey.ContextAPIKey(ctx)
+			return nil
+		}
+		middleware := apikey.New(scheme, validate)
+		dispatchResult = middleware(handler)(context.Background(), httptest.NewRecorder(), request)
+	})
+
+	Context("with the key in a header", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-API-Key", "secret")
+		})
+
+		It("stores the key in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedKey).Should(Equal("secret"))
+		})
+	})
+
+	Context("with the key in a query string parameter", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: goa.LocQuery, Name: "key"}
+			request.URL.RawQuery = "key=secret"
+		})
+
+		It("stores the key in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedKey).Should(Equal("secret"))
+		})
+	})
+
+	Context("with the key in a cookie", func() {
+		BeforeEach(func() {
+			scheme = &goa.APIKeySecurity{In: goa.LocCookie, Name: "key"}
+			request.AddCookie(&http.Cookie{Name: "key", Value: "secret"})
+		})
+
+		It("stores the key in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedKey).Should(Equal("secret"))
+		})
+	})
+
+	Context("with a missing key", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		})
+	})
+
+	Context("with an invalid key", func() {
+		BeforeEach(func() {
+			request.Header.Set("X-API-Key", "secret")
+			validate = func(context.Context, string) error {
+				return errors.New("invalid")
+			}
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(fetchedKey).Should(BeEmpty())
+		})
+	})
+})
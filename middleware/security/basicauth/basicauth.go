@@ -1,6 +1,7 @@
 package basicauth
 
 import (
+	"fmt"
 	"net/http"
 
 	"context"
@@ -8,6 +9,21 @@ import (
 	"github.com/goadesign/goa"
 )
 
+type contextKey int
+
+const (
+	credentialsKey contextKey = iota + 1
+)
+
+// credentials holds the basic auth username and password stored in the request context.
+type credentials struct {
+	username, password string
+}
+
+// DefaultRealm is the realm used in the WWW-Authenticate header of responses to unauthenticated
+// requests sent by New.
+const DefaultRealm = "Restricted"
+
 // ErrBasicAuthFailed means it wasn't able to authenticate you with your login/password.
 var ErrBasicAuthFailed = goa.NewErrorClass("basic_auth_failed", 401)
 
@@ -19,14 +35,58 @@ var ErrBasicAuthFailed = goa.NewErrorClass("basic_auth_failed", 401)
 // It doesn't get simpler than that.
 //
 // If you want to handle the username and password checks dynamically,
-// copy the source of `New`, it's 8 lines and you can tweak at will.
+// use NewWithValidator.
 func New(username, password string) goa.Middleware {
-	middleware, _ := goa.NewMiddleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-		u, p, ok := r.BasicAuth()
-		if !ok || u != username || p != password {
+	return NewWithValidator(DefaultRealm, func(ctx context.Context, u, p string) error {
+		if u != username || p != password {
 			return ErrBasicAuthFailed("Authentication failed")
 		}
 		return nil
 	})
-	return middleware
+}
+
+// NewWithValidator creates a basic auth middleware that extracts the username and password from
+// the request Authorization header and calls validate with them. Requests that do not carry
+// credentials or whose credentials fail to validate are rejected with a 401 response whose
+// WWW-Authenticate header uses the given realm. The credentials are stored in the request
+// context, use ContextCredentials to retrieve them in the action handlers.
+//
+// Example:
+//
+//	app.UseBasicAuthMiddleware(service, basicauth.NewWithValidator("cellar", func(ctx context.Context, user, pass string) error {
+//		if !store.Check(user, pass) {
+//			return basicauth.ErrBasicAuthFailed("invalid credentials")
+//		}
+//		return nil
+//	}))
+func NewWithValidator(realm string, validate func(ctx context.Context, username, password string) error) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			u, p, ok := req.BasicAuth()
+			if !ok {
+				rw.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				return ErrBasicAuthFailed("missing credentials")
+			}
+			if err := validate(ctx, u, p); err != nil {
+				rw.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+				return err
+			}
+			return h(WithCredentials(ctx, u, p), rw, req)
+		}
+	}
+}
+
+// WithCredentials creates a child context containing the given basic auth credentials.
+func WithCredentials(ctx context.Context, username, password string) context.Context {
+	return context.WithValue(ctx, credentialsKey, &credentials{username, password})
+}
+
+// ContextCredentials retrieves the basic auth credentials from a `context` that went through the
+// basic auth middleware. ok is false if the context does not contain credentials.
+func ContextCredentials(ctx context.Context) (username, password string, ok bool) {
+	c, ok := ctx.Value(credentialsKey).(*credentials)
+	if !ok {
+		return "", "", false
+	}
+	return c.username, c.password, true
 }
@@ -0,0 +1,65 @@
+package basicauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/basicauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var request *http.Request
+	var rw *httptest.ResponseRecorder
+	var user string
+	var dispatchResult error
+
+	BeforeEach(func() {
+		user = ""
+		rw = httptest.NewRecorder()
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			user, _, _ = basicauth.ContextCredentials(ctx)
+			return nil
+		}
+		dispatchResult = basicauth.New("admin", "password")(handler)(context.Background(), rw, request)
+	})
+
+	Context("with valid credentials", func() {
+		BeforeEach(func() {
+			request.SetBasicAuth("admin", "password")
+		})
+
+		It("stores the credentials in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(user).Should(Equal("admin"))
+		})
+	})
+
+	Context("with invalid credentials", func() {
+		BeforeEach(func() {
+			request.SetBasicAuth("admin", "nope")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Basic realm="Restricted"`))
+		})
+	})
+
+	Context("with no credentials", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+			Ω(rw.Header().Get("WWW-Authenticate")).Should(Equal(`Basic realm="Restricted"`))
+			Ω(user).Should(BeEmpty())
+		})
+	})
+})
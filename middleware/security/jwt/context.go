@@ -25,3 +25,18 @@ func ContextJWT(ctx context.Context) *jwt.Token {
 	}
 	return token
 }
+
+// ContextClaims retrieves the claims of the JWT token from a `context` that went through our
+// security middleware. It returns nil if there is no token or if the token claims are not a
+// map.
+func ContextClaims(ctx context.Context) jwt.MapClaims {
+	token := ContextJWT(ctx)
+	if token == nil {
+		return nil
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
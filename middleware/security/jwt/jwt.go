@@ -19,8 +19,9 @@ import (
 //
 // The steps taken by the middleware are:
 //
-//     1. Validate the "Bearer" token present in the "Authorization" header against the key(s)
-//        given to New
+//     1. Validate the token against the key(s) given to New. The token is read from the
+//        location defined in the design: a "Bearer" token in a header (e.g. "Authorization"),
+//        a query string parameter or a cookie
 //     2. If scopes are defined in the design for the action, validate them
 //        against the scopes presented by the JWT in the claim "scope", or if
 //        that's not defined, "scopes".
@@ -59,29 +60,20 @@ import (
 //    jwtResolver, _ := jwt.NewSimpleResolver("secret")
 //    app.UseJWT(jwt.New(jwtResolver, validationHandler, app.NewJWTSecurity()))
 //
+// The validated token is stored in the request context, use ContextJWT or ContextClaims to
+// retrieve it in the action handlers.
 func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSecurity) goa.Middleware {
 	return func(nextHandler goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
-			// TODO: implement the QUERY string handler too
-			if scheme.In != goa.LocHeader {
-				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
-			}
-			val := req.Header.Get(scheme.Name)
-			if val == "" {
-				return ErrJWTError(fmt.Sprintf("missing header %q", scheme.Name))
-			}
-
-			if !strings.HasPrefix(strings.ToLower(val), "bearer ") {
-				return ErrJWTError(fmt.Sprintf("invalid or malformed %q header, expected 'Bearer JWT-token...'", val))
+			incomingToken, err := extractToken(req, scheme)
+			if err != nil {
+				return err
 			}
 
-			incomingToken := strings.Split(val, " ")[1]
-
 			rsaKeys, ecdsaKeys, hmacKeys := partitionKeys(resolver.SelectKeys(req))
 
 			var (
 				token     *jwt.Token
-				err       error
 				validated = false
 			)
 
@@ -112,7 +104,7 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 
 			scopesInClaim, scopesInClaimList, err := parseClaimScopes(token)
 			if err != nil {
-				goa.LogError(ctx, err.Error())
+				goa.LogWarn(ctx, err.Error())
 				return ErrJWTError(err)
 			}
 
@@ -134,6 +126,35 @@ func New(resolver KeyResolver, validationFunc goa.Middleware, scheme *goa.JWTSec
 	}
 }
 
+// extractToken reads the raw JWT from the request location defined by the security scheme.
+func extractToken(req *http.Request, scheme *goa.JWTSecurity) (string, error) {
+	switch scheme.In {
+	case goa.LocHeader:
+		val := req.Header.Get(scheme.Name)
+		if val == "" {
+			return "", ErrJWTError(fmt.Sprintf("missing header %q", scheme.Name))
+		}
+		if !strings.HasPrefix(strings.ToLower(val), "bearer ") {
+			return "", ErrJWTError(fmt.Sprintf("invalid or malformed %q header, expected 'Bearer JWT-token...'", val))
+		}
+		return strings.Split(val, " ")[1], nil
+	case goa.LocQuery:
+		val := req.URL.Query().Get(scheme.Name)
+		if val == "" {
+			return "", ErrJWTError(fmt.Sprintf("missing query string parameter %q", scheme.Name))
+		}
+		return val, nil
+	case goa.LocCookie:
+		c, err := req.Cookie(scheme.Name)
+		if err != nil || c.Value == "" {
+			return "", ErrJWTError(fmt.Sprintf("missing cookie %q", scheme.Name))
+		}
+		return c.Value, nil
+	default:
+		return "", fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+	}
+}
+
 // partitionKeys sorts keys by their type.
 func partitionKeys(keys []Key) ([]*rsa.PublicKey, []*ecdsa.PublicKey, [][]byte) {
 	var (
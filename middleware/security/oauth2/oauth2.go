@@ -0,0 +1,78 @@
+package oauth2
+
+import (
+	"net/http"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type contextKey int
+
+const (
+	tokenKey contextKey = iota + 1
+)
+
+// ErrOAuth2Failed means the OAuth2 access token was missing, invalid or did not grant the
+// required scopes.
+var ErrOAuth2Failed = goa.NewErrorClass("oauth2_failed", 401)
+
+// TokenValidator validates an OAuth2 access token and returns the scopes it grants. A typical
+// implementation introspects the token with the authorization server or looks it up in a token
+// store.
+type TokenValidator func(ctx context.Context, token string) (scopes []string, err error)
+
+// New returns a middleware to be used with the OAuth2Security DSL definitions of goa. The
+// middleware reads the access token from the "Bearer" Authorization header, validates it with
+// validator and ensures the token grants the scopes required by the action as defined in the
+// design with the Security DSL. The token is stored in the request context, use ContextToken to
+// retrieve it in the action handlers.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//	app.UseOAuth2Middleware(service, oauth2.New(validator))
+func New(validator TokenValidator) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			val := req.Header.Get("Authorization")
+			if !strings.HasPrefix(strings.ToLower(val), "bearer ") {
+				return ErrOAuth2Failed("missing or malformed Authorization header, expected 'Bearer token...'")
+			}
+			token := strings.TrimSpace(val[len("bearer "):])
+			scopes, err := validator(ctx, token)
+			if err != nil {
+				return ErrOAuth2Failed(err)
+			}
+			granted := make(map[string]bool, len(scopes))
+			for _, s := range scopes {
+				granted[s] = true
+			}
+			required := goa.ContextRequiredScopes(ctx)
+			for _, s := range required {
+				if !granted[s] {
+					return ErrOAuth2Failed("authorization failed: required scopes not granted by token",
+						"required", required, "scopes", scopes)
+				}
+			}
+			return h(WithToken(ctx, token), rw, req)
+		}
+	}
+}
+
+// WithToken creates a child context containing the given access token.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenKey, token)
+}
+
+// ContextToken retrieves the access token from a `context` that went through the OAuth2
+// middleware.
+func ContextToken(ctx context.Context) string {
+	token, ok := ctx.Value(tokenKey).(string)
+	if !ok {
+		return ""
+	}
+	return token
+}
@@ -0,0 +1,84 @@
+package oauth2_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/oauth2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var validator oauth2.TokenValidator
+	var ctx context.Context
+	var request *http.Request
+	var fetchedToken string
+	var dispatchResult error
+
+	BeforeEach(func() {
+		validator = func(_ context.Context, token string) ([]string, error) {
+			if token != "valid" {
+				return nil, errors.New("invalid token")
+			}
+			return []string{"read"}, nil
+		}
+		ctx = context.Background()
+		fetchedToken = ""
+		request, _ = http.NewRequest("GET", "http://example.com/", nil)
+	})
+
+	JustBeforeEach(func() {
+		handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			fetchedToken = oauth2.ContextToken(ctx)
+			return nil
+		}
+		middleware := oauth2.New(validator)
+		dispatchResult = middleware(handler)(ctx, httptest.NewRecorder(), request)
+	})
+
+	Context("with a valid token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer valid")
+			ctx = goa.WithRequiredScopes(ctx, []string{"read"})
+		})
+
+		It("stores the token in the context", func() {
+			Ω(dispatchResult).ShouldNot(HaveOccurred())
+			Ω(fetchedToken).Should(Equal("valid"))
+		})
+	})
+
+	Context("with a token missing required scopes", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer valid")
+			ctx = goa.WithRequiredScopes(ctx, []string{"write"})
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(dispatchResult.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		})
+	})
+
+	Context("with an invalid token", func() {
+		BeforeEach(func() {
+			request.Header.Set("Authorization", "Bearer invalid")
+		})
+
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+			Ω(fetchedToken).Should(BeEmpty())
+		})
+	})
+
+	Context("with no token", func() {
+		It("rejects the request", func() {
+			Ω(dispatchResult).Should(HaveOccurred())
+		})
+	})
+})
@@ -28,12 +28,22 @@ import (
 //	}
 //
 // Controller actions can check if a timeout is set by calling the context Deadline method.
+//
+// The middleware returns a goa.ErrGatewayTimeout error, i.e. a response with status code 504, if
+// the timeout expires before the handler writes the response. The handler is not interrupted, it
+// must return once the context is done.
 func Timeout(timeout time.Duration) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			nctx, cancel := context.WithTimeout(ctx, timeout)
 			defer cancel()
-			return h(nctx, rw, req)
+			err := h(nctx, rw, req)
+			if nctx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+				if resp := goa.ContextResponse(ctx); resp == nil || !resp.Written() {
+					return goa.ErrGatewayTimeout("request timed out", "timeout", timeout.String())
+				}
+			}
+			return err
 		}
 	}
 }
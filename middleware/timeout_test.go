@@ -7,6 +7,7 @@ import (
 
 	"context"
 
+	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/middleware"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -31,4 +32,21 @@ var _ = Describe("Timeout", func() {
 		_, ok := newCtx.Deadline()
 		Ω(ok).Should(BeTrue())
 	})
+
+	It("returns a gateway timeout error if the deadline expires", func() {
+		service := newService(nil)
+
+		req, err := http.NewRequest("GET", "/goo", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := new(testResponseWriter)
+		ctx := newContext(service, rw, req, nil)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		t := middleware.Timeout(time.Millisecond)(h)
+		err = t(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(http.StatusGatewayTimeout))
+	})
 })
@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/client"
@@ -23,6 +25,39 @@ type (
 	// tracing systems such as Zipkin or AWS X-Ray.
 	IDFunc func() string
 
+	// SpanExporter is a function called by the tracer middleware once the
+	// request handling completes with the corresponding span. Exporters send
+	// the spans to tracing backends such as Zipkin or Jaeger.
+	SpanExporter func(ctx context.Context, span *Span)
+
+	// Span describes the handling of a traced request.
+	Span struct {
+		// TraceID is the ID of the trace the span belongs to.
+		TraceID string
+		// SpanID is the ID of the span.
+		SpanID string
+		// ParentID is the ID of the parent span if any.
+		ParentID string
+		// Name is the span name built from the controller and action names.
+		Name string
+		// Controller is the name of the controller that handled the request.
+		Controller string
+		// Action is the name of the action that handled the request.
+		Action string
+		// Method is the request HTTP method.
+		Method string
+		// Path is the request path.
+		Path string
+		// Status is the response HTTP status code.
+		Status int
+		// Err is the error returned by the handler if any.
+		Err error
+		// StartedAt is the time the request handling started.
+		StartedAt time.Time
+		// Duration is the request handling duration.
+		Duration time.Duration
+	}
+
 	// TracerOption is a constructor option that makes it possible to customize
 	// the middleware.
 	TracerOption func(*tracerOptions) *tracerOptions
@@ -34,6 +69,8 @@ type (
 		samplingPercent int
 		maxSamplingRate int
 		sampleSize      int
+		propagators     []Propagator
+		exporter        SpanExporter
 	}
 
 	// tracedDoer is a goa client Doer that inserts the tracing headers for
@@ -106,6 +143,27 @@ func SampleSize(s int) TracerOption {
 	}
 }
 
+// Propagators sets the formats used to extract the trace context from the
+// incoming requests and to inject it in the requests made with TraceDoer. The
+// trace context is extracted using the first propagator that finds one and
+// injected using all the propagators. Defaults to DefaultPropagator. The trace
+// and span IDs default to HexTraceID and HexSpanID when this option is used.
+func Propagators(ps ...Propagator) TracerOption {
+	return func(o *tracerOptions) *tracerOptions {
+		o.propagators = ps
+		return o
+	}
+}
+
+// ExportSpans sets the function called with the span of each sampled request
+// once its handling completes.
+func ExportSpans(e SpanExporter) TracerOption {
+	return func(o *tracerOptions) *tracerOptions {
+		o.exporter = e
+		return o
+	}
+}
+
 // NewTracer returns a trace middleware that initializes the trace information
 // in the request context. The information can be retrieved using any of the
 // ContextXXX functions.
@@ -118,16 +176,33 @@ func SampleSize(s int) TracerOption {
 // IDs respectively. This is configurable so that the created IDs are compatible
 // with the various backend tracing systems. The xray package provides
 // implementations that produce AWS X-Ray compatible IDs.
+//
+// The trace context is propagated using the goa tracing headers by default, use
+// Propagators to use the W3C Trace Context or B3 formats instead. Use
+// ExportSpans to export the spans of the traced requests.
 func NewTracer(opts ...TracerOption) goa.Middleware {
 	o := &tracerOptions{
-		traceIDFunc:     shortID,
-		spanIDFunc:      shortID,
 		samplingPercent: 100,
 		sampleSize:      1000, // only applies if maxSamplingRate is set
 	}
 	for _, opt := range opts {
 		o = opt(o)
 	}
+	if o.traceIDFunc == nil {
+		o.traceIDFunc = shortID
+		if o.propagators != nil {
+			o.traceIDFunc = HexTraceID
+		}
+	}
+	if o.spanIDFunc == nil {
+		o.spanIDFunc = shortID
+		if o.propagators != nil {
+			o.spanIDFunc = HexSpanID
+		}
+	}
+	if len(o.propagators) == 0 {
+		o.propagators = []Propagator{DefaultPropagator}
+	}
 	var sampler Sampler
 	if o.maxSamplingRate > 0 {
 		sampler = NewAdaptiveSampler(o.maxSamplingRate, o.sampleSize)
@@ -137,11 +212,18 @@ func NewTracer(opts ...TracerOption) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			// insert a new trace ID only if not already being traced.
-			traceID := req.Header.Get(TraceIDHeader)
+			var traceID, parentID string
+			sampled := true
+			for _, p := range o.propagators {
+				if traceID, parentID, sampled = p.Extract(req.Header); traceID != "" {
+					break
+				}
+			}
 			if traceID == "" {
 				// insert tracing only within sample.
 				if sampler.Sample() {
 					traceID = o.traceIDFunc()
+					sampled = true
 				} else {
 					return h(ctx, rw, req)
 				}
@@ -149,9 +231,33 @@ func NewTracer(opts ...TracerOption) goa.Middleware {
 
 			// insert IDs into context to enable tracing.
 			spanID := o.spanIDFunc()
-			parentID := req.Header.Get(ParentSpanIDHeader)
 			ctx = WithTrace(ctx, traceID, spanID, parentID)
-			return h(ctx, rw, req)
+			ctx = context.WithValue(ctx, sampledKey, sampled)
+			ctx = context.WithValue(ctx, propagatorsKey, o.propagators)
+			if o.exporter == nil || !sampled {
+				return h(ctx, rw, req)
+			}
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+			ctrl, action := goa.ContextController(ctx), goa.ContextAction(ctx)
+			span := &Span{
+				TraceID:    traceID,
+				SpanID:     spanID,
+				ParentID:   parentID,
+				Name:       fmt.Sprintf("%s.%s", ctrl, action),
+				Controller: ctrl,
+				Action:     action,
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Err:        err,
+				StartedAt:  startedAt,
+				Duration:   time.Since(startedAt),
+			}
+			if resp := goa.ContextResponse(ctx); resp != nil {
+				span.Status = resp.Status
+			}
+			o.exporter(ctx, span)
+			return err
 		}
 	}
 }
@@ -163,6 +269,8 @@ func Tracer(sampleRate int, spanIDFunc, traceIDFunc IDFunc) goa.Middleware {
 
 // TraceDoer wraps a goa client Doer and sets the trace headers so that the
 // downstream service may properly retrieve the parent span ID and trace ID.
+// The headers are set using the propagators configured on the tracer
+// middleware that traced the request context.
 func TraceDoer(doer client.Doer) client.Doer {
 	return &tracedDoer{doer}
 }
@@ -212,8 +320,17 @@ func (d *tracedDoer) Do(ctx context.Context, req *http.Request) (*http.Response,
 		spanID  = ContextSpanID(ctx)
 	)
 	if traceID != "" {
-		req.Header.Set(TraceIDHeader, traceID)
-		req.Header.Set(ParentSpanIDHeader, spanID)
+		sampled := true
+		if s, ok := ctx.Value(sampledKey).(bool); ok {
+			sampled = s
+		}
+		propagators := []Propagator{DefaultPropagator}
+		if ps, ok := ctx.Value(propagatorsKey).([]Propagator); ok {
+			propagators = ps
+		}
+		for _, p := range propagators {
+			p.Inject(req.Header, traceID, spanID, sampled)
+		}
 	}
 
 	return d.Doer.Do(ctx, req)
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/goadesign/goa/client"
 )
 
 func TestNewTracer(t *testing.T) {
@@ -169,3 +171,53 @@ func TestTracerMiddleware(t *testing.T) {
 		}
 	}
 }
+
+func TestTracerPropagation(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	var (
+		spans []*Span
+		out   http.Header
+
+		m = NewTracer(Propagators(W3CPropagator, B3Propagator), ExportSpans(func(_ context.Context, s *Span) {
+			spans = append(spans, s)
+		}))
+		doer = TraceDoer(client.HTTPClientDoer(&http.Client{Transport: roundTripper(func(req *http.Request) (*http.Response, error) {
+			out = req.Header
+			return &http.Response{StatusCode: 200, Body: http.NoBody}, nil
+		})}))
+		h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			r, _ := http.NewRequest("GET", "http://downstream/", nil)
+			_, err := doer.Do(ctx, r)
+			return err
+		}
+	)
+	req, _ := http.NewRequest("GET", "/bottles", nil)
+	req.Header.Set(B3Header, traceID+"-"+spanID+"-1")
+
+	if err := m(h)(context.Background(), httptest.NewRecorder(), req); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.TraceID != traceID || s.ParentID != spanID || len(s.SpanID) != 16 || s.Path != "/bottles" {
+		t.Errorf("invalid span %+v", s)
+	}
+	if got, expected := out.Get(TraceparentHeader), "00-"+traceID+"-"+s.SpanID+"-01"; got != expected {
+		t.Errorf("invalid traceparent header, expected %v - got %v", expected, got)
+	}
+	if got := out.Get(B3TraceIDHeader); got != traceID {
+		t.Errorf("invalid B3 trace ID header, expected %v - got %v", traceID, got)
+	}
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -56,6 +56,7 @@ var _ = Describe("NewMiddleware", func() {
 
 		BeforeEach(func() {
 			service = goa.New("test")
+			service.Encoder.Register(goa.NewJSONEncoder, "*/*")
 			ctrl := service.NewController("foo")
 			var err error
 			req, err = http.NewRequest("GET", "/goo", nil)
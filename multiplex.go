@@ -0,0 +1,161 @@
+package goa
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dimfeld/httptreemux"
+)
+
+type (
+	// MultiplexOption configures the services multiplexed with Multiplex.
+	MultiplexOption func(*multiplexer)
+
+	// multiplexer dispatches the requests received by a shared mux to the multiplexed
+	// services.
+	multiplexer struct {
+		mux        ServeMux
+		muxes      []*prefixMux
+		prefixes   map[*Service]string
+		middleware []Middleware
+		routes     map[string]*Service
+	}
+
+	// prefixMux is the mux of a multiplexed service, it registers the service handlers on the
+	// shared mux.
+	prefixMux struct {
+		m                *multiplexer
+		service          *Service
+		prefix           string
+		notFound         MuxHandler
+		methodNotAllowed MethodNotAllowedHandler
+	}
+)
+
+// MultiplexPathPrefix prefixes the request paths of all the handlers mounted on the given service
+// with prefix, e.g. "/v1".
+func MultiplexPathPrefix(service *Service, prefix string) MultiplexOption {
+	return func(m *multiplexer) {
+		m.prefixes[service] = "/" + strings.Trim(prefix, "/")
+	}
+}
+
+// MultiplexMiddleware adds middleware shared by all the multiplexed services. The shared
+// middleware runs before the middleware of each service.
+func MultiplexMiddleware(middleware ...Middleware) MultiplexOption {
+	return func(m *multiplexer) {
+		m.middleware = append(m.middleware, middleware...)
+	}
+}
+
+// Multiplex makes the given services share the given mux so that they can be served by a single
+// HTTP server. The services handlers are registered on mux when the controllers are mounted, so
+// Multiplex must be called before mounting the controllers. Mounting a handler whose method and
+// path is already handled by another multiplexed service panics with an error describing the
+// conflict. Requests that do not match any handler are handled by the NotFound and
+// MethodNotAllowed handlers of the service with the longest matching path prefix.
+// Example:
+//
+//	mux := goa.NewMux()
+//	users, billing := goa.New("users"), goa.New("billing")
+//	goa.Multiplex(mux, []*goa.Service{users, billing},
+//		goa.MultiplexPathPrefix(billing, "/billing"),
+//		goa.MultiplexMiddleware(middleware.RequestID()),
+//	)
+//	usersapp.MountUserController(users, NewUserController(users))
+//	billingapp.MountInvoiceController(billing, NewInvoiceController(billing))
+//	http.ListenAndServe(":8080", mux)
+func Multiplex(mux ServeMux, services []*Service, opts ...MultiplexOption) {
+	m := &multiplexer{
+		mux:      mux,
+		prefixes: make(map[*Service]string),
+		routes:   make(map[string]*Service),
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	for _, s := range services {
+		prefix := m.prefixes[s]
+		if prefix == "/" {
+			prefix = ""
+		}
+		pm := &prefixMux{
+			m:                m,
+			service:          s,
+			prefix:           prefix,
+			notFound:         s.notFound,
+			methodNotAllowed: s.methodNotAllowed,
+		}
+		m.muxes = append(m.muxes, pm)
+		s.Mux = pm
+		if s.Server != nil {
+			s.Server.Handler = mux
+		}
+		if len(m.middleware) > 0 {
+			s.middleware = append(append([]Middleware{}, m.middleware...), s.middleware...)
+		}
+	}
+	sort.SliceStable(m.muxes, func(i, j int) bool { return len(m.muxes[i].prefix) > len(m.muxes[j].prefix) })
+	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		if pm := m.match(req.URL.Path); pm != nil && pm.notFound != nil {
+			pm.notFound(rw, req, params)
+			return
+		}
+		http.NotFound(rw, req)
+	})
+	mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, params url.Values, methods map[string]httptreemux.HandlerFunc) {
+		if pm := m.match(req.URL.Path); pm != nil && pm.methodNotAllowed != nil {
+			pm.methodNotAllowed(rw, req, params, methods)
+			return
+		}
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
+
+// match returns the mux of the service with the longest path prefix matching path.
+func (m *multiplexer) match(path string) *prefixMux {
+	for _, pm := range m.muxes {
+		if pm.prefix == "" || path == pm.prefix || strings.HasPrefix(path, pm.prefix+"/") {
+			return pm
+		}
+	}
+	return nil
+}
+
+// ServeHTTP dispatches the request using the shared mux.
+func (p *prefixMux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	p.m.mux.ServeHTTP(rw, req)
+}
+
+// Handle registers the handler on the shared mux under the prefixed path. It panics if another
+// multiplexed service already handles the same method and path.
+func (p *prefixMux) Handle(method, path string, handle MuxHandler) {
+	path = p.prefix + path
+	key := method + " " + path
+	if s, ok := p.m.routes[key]; ok {
+		panic(fmt.Sprintf("goa: %s of service %q conflicts with service %q", key, p.service.Name, s.Name))
+	}
+	p.m.routes[key] = p.service
+	p.m.mux.Handle(method, path, handle)
+}
+
+// HandleNotFound sets the NotFound handler for the requests whose path matches the service path
+// prefix.
+func (p *prefixMux) HandleNotFound(handle MuxHandler) {
+	p.notFound = handle
+}
+
+// HandleMethodNotAllowed sets the MethodNotAllowed handler for the requests whose path matches the
+// service path prefix.
+func (p *prefixMux) HandleMethodNotAllowed(handle MethodNotAllowedHandler) {
+	p.methodNotAllowed = handle
+}
+
+// Lookup returns the MuxHandler associated with the given method and path relative to the service
+// path prefix.
+func (p *prefixMux) Lookup(method, path string) MuxHandler {
+	return p.m.mux.Lookup(method, p.prefix+path)
+}
@@ -0,0 +1,122 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multiplex", func() {
+	var mux goa.ServeMux
+	var users, billing *goa.Service
+	var opts []goa.MultiplexOption
+
+	BeforeEach(func() {
+		mux = goa.NewMux()
+		users = goa.New("users")
+		billing = goa.New("billing")
+		opts = []goa.MultiplexOption{goa.MultiplexPathPrefix(billing, "/billing")}
+	})
+
+	JustBeforeEach(func() {
+		goa.Multiplex(mux, []*goa.Service{users, billing}, opts...)
+	})
+
+	mount := func(s *goa.Service, method, path, body string) {
+		h := s.NewController("test").MuxHandler("test", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Write([]byte(body))
+			return nil
+		}, nil)
+		s.Mux.Handle(method, path, h)
+	}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+		return rw
+	}
+
+	It("sets the services mux and server handler", func() {
+		Ω(users.Mux).ShouldNot(BeNil())
+		Ω(users.Server.Handler).Should(Equal(mux))
+		Ω(billing.Server.Handler).Should(Equal(mux))
+	})
+
+	Context("with handlers mounted on each service", func() {
+		JustBeforeEach(func() {
+			mount(users, "GET", "/users", "users")
+			mount(billing, "GET", "/invoices", "invoices")
+		})
+
+		It("routes the requests to the services", func() {
+			Ω(serve("GET", "/users").Body.String()).Should(Equal("users"))
+			Ω(serve("GET", "/billing/invoices").Body.String()).Should(Equal("invoices"))
+			Ω(serve("GET", "/invoices").Code).Should(Equal(404))
+		})
+
+		It("looks up handlers relative to the service prefix", func() {
+			Ω(billing.Mux.Lookup("GET", "/invoices")).ShouldNot(BeNil())
+			Ω(billing.Mux.Lookup("GET", "/users")).Should(BeNil())
+		})
+	})
+
+	Context("with conflicting handlers", func() {
+		BeforeEach(func() {
+			opts = nil
+		})
+
+		It("panics", func() {
+			mount(users, "GET", "/things", "users")
+			Ω(func() { mount(billing, "GET", "/things", "billing") }).Should(Panic())
+		})
+	})
+
+	Context("with shared middleware", func() {
+		var calls []string
+
+		BeforeEach(func() {
+			calls = nil
+			shared := func(h goa.Handler) goa.Handler {
+				return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					calls = append(calls, "shared")
+					return h(ctx, rw, req)
+				}
+			}
+			opts = append(opts, goa.MultiplexMiddleware(shared))
+			billing.Use(func(h goa.Handler) goa.Handler {
+				return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					calls = append(calls, "billing")
+					return h(ctx, rw, req)
+				}
+			})
+		})
+
+		JustBeforeEach(func() {
+			mount(billing, "GET", "/invoices", "invoices")
+		})
+
+		It("runs the shared middleware first", func() {
+			serve("GET", "/billing/invoices")
+			Ω(calls).Should(Equal([]string{"shared", "billing"}))
+		})
+	})
+
+	Context("with a custom not found handler", func() {
+		JustBeforeEach(func() {
+			billing.Mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+				rw.WriteHeader(418)
+			})
+		})
+
+		It("dispatches unknown paths to the service with the matching prefix", func() {
+			Ω(serve("GET", "/billing/unknown").Code).Should(Equal(418))
+			Ω(serve("GET", "/unknown").Code).Should(Equal(404))
+		})
+	})
+})
@@ -1,8 +1,12 @@
 package goa
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/dimfeld/httptreemux"
 )
@@ -37,6 +41,26 @@ type (
 		Lookup(method, path string) MuxHandler
 	}
 
+	// RoutingConfigurer is implemented by the muxes that make it possible to configure how
+	// request paths are matched against the handler paths. The default mux implements it.
+	RoutingConfigurer interface {
+		// SetTrailingSlash sets the handling of requests whose path only differs from the
+		// path of a handler by a trailing slash, one of TrailingSlashRedirect,
+		// TrailingSlashAbsorb or TrailingSlashStrict.
+		SetTrailingSlash(behavior string)
+		// SetCaseInsensitive enables or disables case insensitive matching of the static
+		// segments of the request paths.
+		SetCaseInsensitive(caseInsensitive bool)
+	}
+
+	// MethodOverrider is implemented by the muxes that can route POST requests using the method
+	// given in the MethodOverrideHeader header. The default mux implements it.
+	MethodOverrider interface {
+		// SetMethodOverride enables or disables routing POST requests that set the
+		// MethodOverrideHeader header using the method given in the header.
+		SetMethodOverride(methodOverride bool)
+	}
+
 	// Muxer implements an adapter that given a request handler can produce a mux handler.
 	Muxer interface {
 		MuxHandler(string, Handler, Unmarshaler) MuxHandler
@@ -44,11 +68,34 @@ type (
 
 	// mux is the default ServeMux implementation.
 	mux struct {
-		router  *httptreemux.TreeMux
-		handles map[string]MuxHandler
+		router         *httptreemux.TreeMux
+		handles        map[string]MuxHandler
+		methodOverride bool
+	}
+
+	// headResponseWriter discards the body written by the GET handlers serving HEAD requests.
+	headResponseWriter struct {
+		http.ResponseWriter
 	}
 )
 
+const (
+	// TrailingSlashRedirect redirects requests whose path only differs from the path of a
+	// handler by a trailing slash to the handler path. This is the default behavior.
+	TrailingSlashRedirect = "redirect"
+	// TrailingSlashAbsorb serves requests whose path only differs from the path of a handler by
+	// a trailing slash with the handler directly.
+	TrailingSlashAbsorb = "absorb"
+	// TrailingSlashStrict handles requests whose path only differs from the path of a handler by
+	// a trailing slash with the NotFound handler.
+	TrailingSlashStrict = "strict"
+)
+
+// MethodOverrideHeader is the name of the header that POST requests may set to select the method
+// used to route the request when method override is enabled, e.g. for clients behind proxies
+// that only allow GET and POST requests.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
 // NewMux returns a Mux.
 func NewMux() ServeMux {
 	r := httptreemux.New()
@@ -66,7 +113,7 @@ func (m *mux) Handle(method, path string, handle MuxHandler) {
 		for n, p := range htparams {
 			params.Set(n, p)
 		}
-		handle(rw, req, params)
+		handle(rw, req.WithContext(WithRoute(req.Context(), path)), params)
 	}
 	m.handles[method+path] = handle
 	m.router.Handle(method, path, hthandle)
@@ -95,7 +142,127 @@ func (m *mux) Lookup(method, path string) MuxHandler {
 	return m.handles[method+path]
 }
 
+// SetTrailingSlash sets the handling of requests whose path only differs from the path of a
+// handler by a trailing slash.
+func (m *mux) SetTrailingSlash(behavior string) {
+	switch behavior {
+	case TrailingSlashAbsorb:
+		m.router.RedirectTrailingSlash = true
+		m.router.RedirectBehavior = httptreemux.UseHandler
+	case TrailingSlashStrict:
+		m.router.RedirectTrailingSlash = false
+	default:
+		m.router.RedirectTrailingSlash = true
+		m.router.RedirectBehavior = httptreemux.Redirect301
+	}
+}
+
+// SetCaseInsensitive enables or disables case insensitive matching of the static segments of the
+// request paths.
+func (m *mux) SetCaseInsensitive(caseInsensitive bool) {
+	m.router.CaseInsensitive = caseInsensitive
+}
+
+// SetMethodOverride enables or disables routing POST requests that set the MethodOverrideHeader
+// header using the method given in the header. Only the PUT, PATCH and DELETE methods may be
+// selected.
+func (m *mux) SetMethodOverride(methodOverride bool) {
+	m.methodOverride = methodOverride
+}
+
 // ServeHTTP is the function called back by the underlying HTTP server to handle incoming requests.
 func (m *mux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if m.methodOverride && req.Method == "POST" {
+		switch method := strings.ToUpper(req.Header.Get(MethodOverrideHeader)); method {
+		case "PUT", "PATCH", "DELETE":
+			req = req.WithContext(req.Context())
+			req.Method = method
+		}
+	}
 	m.router.ServeHTTP(rw, req)
 }
+
+// HeadHandler returns a MuxHandler that serves HEAD requests using the given GET handler: the
+// response status and headers are the same as the GET response but the body is discarded. The
+// code generated by goagen mounts the GET action handlers on HEAD requests with HeadHandler
+// unless the design defines a HEAD route with the same path.
+func HeadHandler(get MuxHandler) MuxHandler {
+	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+		get(&headResponseWriter{ResponseWriter: rw}, req, params)
+	}
+}
+
+// Write discards the response body.
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Flush sends the buffered headers to the client if the underlying writer supports it.
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection if the underlying writer supports it.
+func (w *headResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
+
+// ConvertPath converts a request path that uses the httptreemux syntax, e.g. "/bottles/:id" or
+// "/files/*filepath", into the path pattern syntax of another router. param and wildcard return
+// the pattern of the path segment that captures the parameter with the given name and of the
+// segment that captures the rest of the path respectively. Segments escaped with a backslash are
+// unescaped. The ServeMux adapters of the mux package use ConvertPath to register the paths of the
+// generated handlers.
+func ConvertPath(path string, param, wildcard func(name string) string) string {
+	segs := strings.Split(path, "/")
+	for i, s := range segs {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segs[i] = param(s[1:])
+		case strings.HasPrefix(s, "*"):
+			segs[i] = wildcard(s[1:])
+		case strings.HasPrefix(s, `\:`), strings.HasPrefix(s, `\*`):
+			segs[i] = s[1:]
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// SplitParamValues returns the values of a parameter or header given as repeated values, as comma
+// separated values or as a mix of both. The code generated to decode array parameters and headers
+// whose elements are not strings calls SplitParamValues prior to converting the elements so that
+// "?ids=1&ids=2", "?ids=1,2" and "X-Ids: 1, 2" all produce the same array.
+func SplitParamValues(vals []string) []string {
+	var res []string
+	for _, v := range vals {
+		for _, e := range strings.Split(v, ",") {
+			res = append(res, strings.TrimSpace(e))
+		}
+	}
+	return res
+}
+
+// MapParamValues returns the values of a map parameter given using the "name[key]=value" query
+// string syntax indexed by key. Only the first value of a given key is retained. The code
+// generated to decode map parameters calls MapParamValues prior to converting the keys and values
+// so that "?filter[color]=red&filter[size]=10" produces a map with the "color" and "size" keys.
+func MapParamValues(params url.Values, name string) map[string]string {
+	var res map[string]string
+	prefix := name + "["
+	for k, vals := range params {
+		if len(vals) == 0 || !strings.HasPrefix(k, prefix) || !strings.HasSuffix(k, "]") {
+			continue
+		}
+		key := k[len(prefix) : len(k)-1]
+		if res == nil {
+			res = make(map[string]string)
+		}
+		res[key] = vals[0]
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+/*
+Package goachi contains an adapter that makes it possible to mount the goa service handlers on a
+chi router (https://github.com/go-chi/chi).
+Usage:
+
+	router := chi.NewRouter()
+	router.Use(chimiddleware.RealIP)
+	service.UseMux(goachi.New(router))
+	// ... Proceed with mounting the controllers and starting the goa service
+*/
+package goachi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/goadesign/goa"
+)
+
+// adapter is the chi goa mux adapter.
+type adapter struct {
+	router  chi.Router
+	handles map[string]goa.MuxHandler
+}
+
+// New wraps a chi router into a goa mux.
+func New(router chi.Router) goa.ServeMux {
+	return &adapter{router: router, handles: make(map[string]goa.MuxHandler)}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	var wildcard string
+	pattern := goa.ConvertPath(path,
+		func(name string) string { return "{" + name + "}" },
+		func(name string) string { wildcard = name; return "*" },
+	)
+	a.handles[method+path] = handle
+	a.router.MethodFunc(method, pattern, func(rw http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			for i, k := range rctx.URLParams.Keys {
+				if k == "*" {
+					k = wildcard
+				}
+				if k != "" {
+					params.Set(k, rctx.URLParams.Values[i])
+				}
+			}
+		}
+		handle(rw, req.WithContext(goa.WithRoute(req.Context(), path)), params)
+	})
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any handler
+// registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.router.NotFound(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil)
+	})
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match the path of a
+// handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.router.MethodNotAllowed(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil, nil)
+	})
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP dispatches the request using the chi router.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.router.ServeHTTP(rw, req)
+}
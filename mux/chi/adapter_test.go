@@ -0,0 +1,72 @@
+package goachi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/dimfeld/httptreemux"
+	"github.com/go-chi/chi/v5"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/mux/chi"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("goachi", func() {
+	var mux goa.ServeMux
+	var rw *httptest.ResponseRecorder
+	var params url.Values
+
+	BeforeEach(func() {
+		mux = goachi.New(chi.NewRouter())
+		params = nil
+		handler := func(rw http.ResponseWriter, req *http.Request, p url.Values) {
+			params = p
+			rw.Write([]byte(goa.ContextRoute(req.Context())))
+		}
+		mux.Handle("GET", "/bottles/:id", handler)
+		mux.Handle("GET", "/public/*filepath", handler)
+		mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			rw.WriteHeader(http.StatusTeapot)
+		})
+		mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, _ url.Values, methods map[string]httptreemux.HandlerFunc) {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+		})
+	})
+
+	serve := func(method, path string) {
+		req, _ := http.NewRequest(method, path, nil)
+		rw = httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+	}
+
+	It("passes the path and querystring parameters to the handler", func() {
+		serve("GET", "/bottles/42?sort=asc")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Body.String()).Should(Equal("/bottles/:id"))
+		Ω(params.Get("id")).Should(Equal("42"))
+		Ω(params.Get("sort")).Should(Equal("asc"))
+	})
+
+	It("passes the wildcard value to the handler", func() {
+		serve("GET", "/public/css/site.css")
+		Ω(rw.Code).Should(Equal(200))
+		Ω(params.Get("filepath")).Should(Equal("css/site.css"))
+	})
+
+	It("looks up the registered handlers", func() {
+		Ω(mux.Lookup("GET", "/bottles/:id")).ShouldNot(BeNil())
+		Ω(mux.Lookup("POST", "/bottles/:id")).Should(BeNil())
+	})
+
+	It("calls the not found handler", func() {
+		serve("GET", "/unknown")
+		Ω(rw.Code).Should(Equal(http.StatusTeapot))
+	})
+
+	It("calls the method not allowed handler", func() {
+		serve("POST", "/bottles/42")
+		Ω(rw.Code).Should(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,31 @@
+/*
+Package mux contains ServeMux adapters that make it possible to mount the handlers generated by
+goagen on routers other than the default goa mux. Each adapter exists in its own sub-package named
+after the corresponding router package.
+
+goa.ServeMux is the minimal interface the generated Mount functions rely on: Handle registers a
+handler for a HTTP method and a path that uses the httptreemux syntax ("/bottles/:id",
+"/files/*filepath"), the adapters convert the paths into the router syntax and pass the path
+parameters to the handlers together with the querystring values. The adapters call the
+MethodNotAllowed handler with a nil map of allowed methods.
+
+Once instantiated adapters can be used by setting the goa service mux with UseMux prior to
+mounting the controllers:
+
+	func main() {
+		// ...
+
+		// Create service
+		service := goa.New("my service")
+
+		// Setup mux adapter
+		router := chi.NewRouter()
+		service.UseMux(goachi.New(router))
+
+		// Mount controllers
+		app.MountBottleController(service, NewBottleController(service))
+
+		// ...
+	}
+*/
+package mux
@@ -0,0 +1,70 @@
+/*
+Package goagorilla contains an adapter that makes it possible to mount the goa service handlers on
+a gorilla/mux router (https://github.com/gorilla/mux).
+Usage:
+
+	router := mux.NewRouter()
+	service.UseMux(goagorilla.New(router))
+	// ... Proceed with mounting the controllers and starting the goa service
+*/
+package goagorilla
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	"github.com/gorilla/mux"
+)
+
+// adapter is the gorilla/mux goa mux adapter.
+type adapter struct {
+	router  *mux.Router
+	handles map[string]goa.MuxHandler
+}
+
+// New wraps a gorilla/mux router into a goa mux.
+func New(router *mux.Router) goa.ServeMux {
+	return &adapter{router: router, handles: make(map[string]goa.MuxHandler)}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	pattern := goa.ConvertPath(path,
+		func(name string) string { return "{" + name + "}" },
+		func(name string) string { return "{" + name + ":.*}" },
+	)
+	a.handles[method+path] = handle
+	a.router.HandleFunc(pattern, func(rw http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		for k, v := range mux.Vars(req) {
+			params.Set(k, v)
+		}
+		handle(rw, req.WithContext(goa.WithRoute(req.Context(), path)), params)
+	}).Methods(method)
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any handler
+// registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.router.NotFoundHandler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil)
+	})
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match the path of a
+// handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.router.MethodNotAllowedHandler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil, nil)
+	})
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP dispatches the request using the gorilla/mux router.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.router.ServeHTTP(rw, req)
+}
@@ -0,0 +1,83 @@
+/*
+Package goahttprouter contains an adapter that makes it possible to mount the goa service handlers
+on a httprouter router (https://github.com/julienschmidt/httprouter). httprouter uses the same path
+syntax as the default goa mux but does not allow registering a path that conflicts with the
+wildcard of another path.
+Usage:
+
+	router := httprouter.New()
+	service.UseMux(goahttprouter.New(router))
+	// ... Proceed with mounting the controllers and starting the goa service
+*/
+package goahttprouter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/julienschmidt/httprouter"
+)
+
+// adapter is the httprouter goa mux adapter.
+type adapter struct {
+	router  *httprouter.Router
+	handles map[string]goa.MuxHandler
+}
+
+// New wraps a httprouter router into a goa mux.
+func New(router *httprouter.Router) goa.ServeMux {
+	return &adapter{router: router, handles: make(map[string]goa.MuxHandler)}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	var wildcard string
+	pattern := goa.ConvertPath(path,
+		func(name string) string { return ":" + name },
+		func(name string) string {
+			wildcard = name
+			return "*" + name
+		},
+	)
+	a.handles[method+path] = handle
+	a.router.Handle(method, pattern, func(rw http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		params := req.URL.Query()
+		for _, p := range ps {
+			v := p.Value
+			if p.Key == wildcard {
+				// httprouter includes the leading slash in the catch-all value
+				v = strings.TrimPrefix(v, "/")
+			}
+			params.Set(p.Key, v)
+		}
+		handle(rw, req.WithContext(goa.WithRoute(req.Context(), path)), params)
+	})
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any handler
+// registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.router.NotFound = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil)
+	})
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match the path of a
+// handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.router.HandleMethodNotAllowed = true
+	a.router.MethodNotAllowed = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handle(rw, req, nil, nil)
+	})
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP dispatches the request using the httprouter router.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	a.router.ServeHTTP(rw, req)
+}
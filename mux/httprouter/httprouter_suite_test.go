@@ -0,0 +1,13 @@
+package goahttprouter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHttprouter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Httprouter Suite")
+}
@@ -0,0 +1,119 @@
+// +build go1.22
+
+/*
+Package goastd contains an adapter that makes it possible to mount the goa service handlers on a
+standard library http.ServeMux using the method and wildcard patterns introduced in Go 1.22.
+The patterns are disabled when GODEBUG sets httpmuxgo121=1 which is the default for modules that
+declare a go version prior to 1.22.
+Usage:
+
+	mux := http.NewServeMux()
+	service.UseMux(goastd.New(mux))
+	// ... Proceed with mounting the controllers and starting the goa service
+*/
+package goastd
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/goadesign/goa"
+)
+
+// adapter is the http.ServeMux goa mux adapter.
+type adapter struct {
+	mux              *http.ServeMux
+	handles          map[string]goa.MuxHandler
+	methods          map[string]bool
+	notFound         goa.MuxHandler
+	methodNotAllowed goa.MethodNotAllowedHandler
+}
+
+// New wraps a http.ServeMux into a goa mux.
+func New(mux *http.ServeMux) goa.ServeMux {
+	return &adapter{
+		mux:     mux,
+		handles: make(map[string]goa.MuxHandler),
+		methods: make(map[string]bool),
+	}
+}
+
+// Handle sets the handler for the given verb and path.
+func (a *adapter) Handle(method, path string, handle goa.MuxHandler) {
+	var names []string
+	pattern := goa.ConvertPath(path,
+		func(name string) string {
+			names = append(names, name)
+			return "{" + name + "}"
+		},
+		func(name string) string {
+			names = append(names, name)
+			return "{" + name + "...}"
+		},
+	)
+	if strings.HasSuffix(pattern, "/") {
+		// Only match the path itself, not the subtree.
+		pattern += "{$}"
+	}
+	a.handles[method+path] = handle
+	a.methods[method] = true
+	a.mux.HandleFunc(method+" "+pattern, func(rw http.ResponseWriter, req *http.Request) {
+		params := req.URL.Query()
+		for _, n := range names {
+			params.Set(n, req.PathValue(n))
+		}
+		handle(rw, req.WithContext(goa.WithRoute(req.Context(), path)), params)
+	})
+}
+
+// HandleNotFound sets the MuxHandler invoked for requests that don't match any handler
+// registered with Handle.
+func (a *adapter) HandleNotFound(handle goa.MuxHandler) {
+	a.notFound = handle
+}
+
+// HandleMethodNotAllowed sets the MuxHandler invoked for requests that match the path of a
+// handler but not its HTTP method.
+func (a *adapter) HandleMethodNotAllowed(handle goa.MethodNotAllowedHandler) {
+	a.methodNotAllowed = handle
+}
+
+// Lookup returns the MuxHandler associated with the given method and path.
+func (a *adapter) Lookup(method, path string) goa.MuxHandler {
+	return a.handles[method+path]
+}
+
+// ServeHTTP dispatches the request using the http.ServeMux. http.ServeMux does not make it
+// possible to customize the responses to requests that match no pattern so ServeHTTP looks up
+// the pattern first and calls the NotFound or MethodNotAllowed handler if there is none.
+func (a *adapter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if _, pattern := a.mux.Handler(req); pattern != "" {
+		a.mux.ServeHTTP(rw, req)
+		return
+	}
+	if a.methodNotAllowed != nil && a.allowsOtherMethod(req) {
+		a.methodNotAllowed(rw, req, nil, nil)
+		return
+	}
+	if a.notFound != nil {
+		a.notFound(rw, req, nil)
+		return
+	}
+	a.mux.ServeHTTP(rw, req)
+}
+
+// allowsOtherMethod returns true if a handler is registered for the request path with a method
+// other than the request method.
+func (a *adapter) allowsOtherMethod(req *http.Request) bool {
+	for m := range a.methods {
+		if m == req.Method {
+			continue
+		}
+		r := req.Clone(req.Context())
+		r.Method = m
+		if _, pattern := a.mux.Handler(r); pattern != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+// +build go1.22
+
+// The adapter relies on the Go 1.22 ServeMux patterns.
+//go:debug httpmuxgo121=0
+
+package goastd_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Std Suite")
+}
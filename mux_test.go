@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 
 	"github.com/goadesign/goa"
@@ -66,6 +67,27 @@ var _ = Describe("Mux", func() {
 		})
 	})
 
+	Context("with a route containing a path parameter", func() {
+		const route = "/foo/:id"
+
+		var readRoute, readID string
+
+		BeforeEach(func() {
+			var err error
+			req, err = http.NewRequest("GET", "/foo/42", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			mux.Handle("GET", route, func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				readRoute = goa.ContextRoute(req.Context())
+				readID = vals.Get("id")
+			})
+		})
+
+		It("stores the route in the request context", func() {
+			Ω(readRoute).Should(Equal(route))
+			Ω(readID).Should(Equal("42"))
+		})
+	})
+
 	Context("with registered handlers and wrong method", func() {
 		const handlerMeth = "POST"
 		const reqMeth = "GET"
@@ -83,4 +105,165 @@ var _ = Describe("Mux", func() {
 		})
 	})
 
+	Context("with a request path with a trailing slash", func() {
+		var handled bool
+
+		BeforeEach(func() {
+			handled = false
+			var err error
+			req, err = http.NewRequest("GET", "/foo/", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			mux.Handle("GET", "/foo", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				handled = true
+			})
+		})
+
+		It("redirects by default", func() {
+			Ω(rw.Status).Should(Equal(301))
+			Ω(handled).Should(BeFalse())
+		})
+
+		Context("absorbing trailing slashes", func() {
+			BeforeEach(func() {
+				mux.(goa.RoutingConfigurer).SetTrailingSlash(goa.TrailingSlashAbsorb)
+			})
+
+			It("serves the request with the handler", func() {
+				Ω(handled).Should(BeTrue())
+			})
+		})
+
+		Context("with strict trailing slashes", func() {
+			BeforeEach(func() {
+				mux.(goa.RoutingConfigurer).SetTrailingSlash(goa.TrailingSlashStrict)
+			})
+
+			It("returns 404", func() {
+				Ω(rw.Status).Should(Equal(404))
+				Ω(handled).Should(BeFalse())
+			})
+		})
+	})
+
+	Context("with method override", func() {
+		var readMeth string
+
+		BeforeEach(func() {
+			readMeth = ""
+			mux.(goa.MethodOverrider).SetMethodOverride(true)
+			var err error
+			req, err = http.NewRequest("POST", "/foo", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			req.Header.Set(goa.MethodOverrideHeader, "delete")
+			handler := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				readMeth = req.Method
+			}
+			mux.Handle("POST", "/foo", handler)
+			mux.Handle("DELETE", "/foo", handler)
+		})
+
+		It("routes the request using the method given in the header", func() {
+			Ω(readMeth).Should(Equal("DELETE"))
+		})
+
+		Context("with a method that cannot be selected", func() {
+			BeforeEach(func() {
+				req.Header.Set(goa.MethodOverrideHeader, "TRACE")
+			})
+
+			It("ignores the header", func() {
+				Ω(readMeth).Should(Equal("POST"))
+			})
+		})
+	})
+
+	Context("with case insensitive routing", func() {
+		var handled bool
+
+		BeforeEach(func() {
+			handled = false
+			mux.(goa.RoutingConfigurer).SetCaseInsensitive(true)
+			var err error
+			req, err = http.NewRequest("GET", "/FOO", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			mux.Handle("GET", "/foo", func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+				handled = true
+			})
+		})
+
+		It("matches the path regardless of case", func() {
+			Ω(handled).Should(BeTrue())
+		})
+	})
+
+})
+
+var _ = Describe("HeadHandler", func() {
+	It("discards the response body", func() {
+		get := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+			rw.Header().Set("Content-Type", "text/plain")
+			rw.WriteHeader(200)
+			rw.Write([]byte("body"))
+		}
+		req, _ := http.NewRequest("HEAD", "/foo", nil)
+		rw := &TestResponseWriter{ParentHeader: http.Header{}}
+		goa.HeadHandler(get)(rw, req, nil)
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/plain"))
+		Ω(rw.Body).Should(BeEmpty())
+	})
+
+	It("exposes the flusher and hijacker of the underlying writer", func() {
+		var flusher, hijacker bool
+		get := func(rw http.ResponseWriter, req *http.Request, vals url.Values) {
+			_, flusher = rw.(http.Flusher)
+			_, hijacker = rw.(http.Hijacker)
+			rw.(http.Flusher).Flush()
+		}
+		req, _ := http.NewRequest("HEAD", "/foo", nil)
+		rec := httptest.NewRecorder()
+		goa.HeadHandler(get)(rec, req, nil)
+		Ω(flusher).Should(BeTrue())
+		Ω(hijacker).Should(BeTrue())
+		Ω(rec.Flushed).Should(BeTrue())
+	})
+})
+
+var _ = Describe("ConvertPath", func() {
+	param := func(n string) string { return "{" + n + "}" }
+	wildcard := func(n string) string { return "{" + n + "...}" }
+
+	It("converts the parameters and wildcards", func() {
+		Ω(goa.ConvertPath("/accounts/:accountID/files/*filepath", param, wildcard)).Should(Equal("/accounts/{accountID}/files/{filepath...}"))
+	})
+
+	It("unescapes the escaped segments", func() {
+		Ω(goa.ConvertPath(`/public/\*star/:id`, param, wildcard)).Should(Equal("/public/*star/{id}"))
+	})
+})
+
+var _ = Describe("SplitParamValues", func() {
+	It("splits comma separated values", func() {
+		Ω(goa.SplitParamValues([]string{"1,2", " 3 , 4"})).Should(Equal([]string{"1", "2", "3", "4"}))
+	})
+
+	It("keeps repeated values", func() {
+		Ω(goa.SplitParamValues([]string{"1", "2"})).Should(Equal([]string{"1", "2"}))
+	})
+})
+
+var _ = Describe("MapParamValues", func() {
+	It("indexes the values by key", func() {
+		params := url.Values{
+			"filter[color]": {"red", "blue"},
+			"filter[size]":  {"10"},
+			"filter":        {"ignored"},
+			"other[color]":  {"green"},
+		}
+		Ω(goa.MapParamValues(params, "filter")).Should(Equal(map[string]string{"color": "red", "size": "10"}))
+	})
+
+	It("returns nil when the parameter is missing", func() {
+		Ω(goa.MapParamValues(url.Values{"sort": {"asc"}}, "filter")).Should(BeNil())
+	})
 })
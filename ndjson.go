@@ -0,0 +1,121 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// NDJSONContentType is the MIME type of newline delimited JSON documents, see
+// http://ndjson.org.
+const NDJSONContentType = "application/x-ndjson"
+
+type (
+	// NDJSONStream sends results to a client as newline delimited JSON. The stream writes each
+	// result on its own line and flushes it right away.
+	NDJSONStream struct {
+		rw      http.ResponseWriter
+		flusher http.Flusher
+		enc     *json.Encoder
+	}
+
+	// ndjsonEncoder writes the elements of slices and arrays as separate lines.
+	ndjsonEncoder struct {
+		w   io.Writer
+		enc *json.Encoder
+	}
+
+	// ndjsonDecoder reads newline delimited JSON documents.
+	ndjsonDecoder struct {
+		dec *json.Decoder
+	}
+)
+
+// NewNDJSONStream writes the response headers required to stream newline delimited JSON and
+// returns the corresponding stream. It returns an error if the response writer does not support
+// flushing.
+func NewNDJSONStream(rw http.ResponseWriter, req *http.Request) (*NDJSONStream, error) {
+	f, ok := rw.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("response writer does not support flushing, cannot stream results")
+	}
+	h := rw.Header()
+	h.Set("Content-Type", NDJSONContentType)
+	h.Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &NDJSONStream{rw: rw, flusher: f, enc: json.NewEncoder(rw)}, nil
+}
+
+// Send writes the JSON representation of v followed by a newline and flushes it.
+func (s *NDJSONStream) Send(v interface{}) error {
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// NewNDJSONEncoder returns an encoder that writes newline delimited JSON to w. Slices and arrays
+// are written one element per line, other values are written on a single line. Each line is
+// flushed right away if w implements http.Flusher so that collection results are sent to the
+// client as they are encoded.
+func NewNDJSONEncoder(w io.Writer) Encoder {
+	return &ndjsonEncoder{w: w, enc: json.NewEncoder(w)}
+}
+
+// NewNDJSONDecoder returns a decoder that reads newline delimited JSON from r. Decoding into a
+// pointer to a slice appends the values read from each line until the end of the input, decoding
+// into any other value reads a single line.
+func NewNDJSONDecoder(r io.Reader) Decoder {
+	return &ndjsonDecoder{dec: json.NewDecoder(r)}
+}
+
+// Encode writes v as newline delimited JSON.
+func (e *ndjsonEncoder) Encode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return e.encode(v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := e.encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode writes a single line and flushes it.
+func (e *ndjsonEncoder) encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// Decode reads newline delimited JSON into v.
+func (d *ndjsonDecoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return d.dec.Decode(v)
+	}
+	slice := rv.Elem()
+	for {
+		elem := reflect.New(slice.Type().Elem())
+		if err := d.dec.Decode(elem.Interface()); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+}
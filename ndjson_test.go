@@ -0,0 +1,84 @@
+package goa_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NDJSONStream", func() {
+	var rw *httptest.ResponseRecorder
+	var stream *goa.NDJSONStream
+
+	BeforeEach(func() {
+		rw = httptest.NewRecorder()
+		req, err := http.NewRequest("GET", "/results", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		stream, err = goa.NewNDJSONStream(rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("writes the stream headers", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("application/x-ndjson"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("sends one line per result", func() {
+		Ω(stream.Send(map[string]int{"count": 1})).ShouldNot(HaveOccurred())
+		Ω(stream.Send(map[string]int{"count": 2})).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("{\"count\":1}\n{\"count\":2}\n"))
+	})
+})
+
+var _ = Describe("NDJSON encoding", func() {
+	type item struct {
+		Name string `json:"name"`
+	}
+
+	It("encodes collections one element per line", func() {
+		var buf bytes.Buffer
+		err := goa.NewNDJSONEncoder(&buf).Encode([]*item{{Name: "a"}, {Name: "b"}})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"))
+	})
+
+	It("encodes other values on a single line", func() {
+		var buf bytes.Buffer
+		err := goa.NewNDJSONEncoder(&buf).Encode(&item{Name: "a"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(Equal("{\"name\":\"a\"}\n"))
+	})
+
+	It("flushes each line", func() {
+		rw := httptest.NewRecorder()
+		err := goa.NewNDJSONEncoder(rw).Encode([]int{1})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("decodes lines into slices", func() {
+		var items []*item
+		err := goa.NewNDJSONDecoder(strings.NewReader("{\"name\":\"a\"}\n{\"name\":\"b\"}\n")).Decode(&items)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(items).Should(Equal([]*item{{Name: "a"}, {Name: "b"}}))
+	})
+
+	It("decodes a single line into other values", func() {
+		var i item
+		err := goa.NewNDJSONDecoder(strings.NewReader("{\"name\":\"a\"}\n{\"name\":\"b\"}\n")).Decode(&i)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(i.Name).Should(Equal("a"))
+	})
+
+	It("reports invalid lines", func() {
+		var items []*item
+		err := goa.NewNDJSONDecoder(strings.NewReader("{\"name\":\"a\"}\n{\n")).Decode(&items)
+		Ω(err).Should(HaveOccurred())
+	})
+})
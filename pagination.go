@@ -0,0 +1,127 @@
+package goa
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page describes the links to the pages of a paginated collection relative to the current page.
+// The code generated by goagen for actions that use the Paginate DSL sets the response headers
+// from the page and returns it so that it can also be rendered in the response body.
+type Page struct {
+	// Total is the total number of items in the collection, nil if unknown.
+	Total *int `json:"total,omitempty" xml:"total,omitempty" form:"total,omitempty"`
+	// First is the URL of the first page if any.
+	First string `json:"first,omitempty" xml:"first,omitempty" form:"first,omitempty"`
+	// Prev is the URL of the previous page if any.
+	Prev string `json:"prev,omitempty" xml:"prev,omitempty" form:"prev,omitempty"`
+	// Next is the URL of the next page if any.
+	Next string `json:"next,omitempty" xml:"next,omitempty" form:"next,omitempty"`
+	// Last is the URL of the last page if any.
+	Last string `json:"last,omitempty" xml:"last,omitempty" form:"last,omitempty"`
+}
+
+// NumberedPage returns the page of the collection containing total items identified by its
+// number starting at 1 and its size. u is the URL of the current page, the other page URLs are
+// built by setting the "page" query string parameter.
+func NumberedPage(u *url.URL, page, size, total int) *Page {
+	p := &Page{Total: &total}
+	if size < 1 {
+		return p
+	}
+	last := (total + size - 1) / size
+	if last < 1 {
+		last = 1
+	}
+	link := func(n int) string { return pageURL(u, "page", strconv.Itoa(n)) }
+	p.First = link(1)
+	p.Last = link(last)
+	if page > 1 {
+		p.Prev = link(page - 1)
+		if page > last {
+			p.Prev = p.Last
+		}
+	}
+	if page < last {
+		p.Next = link(page + 1)
+	}
+	return p
+}
+
+// OffsetPage returns the page of the collection containing total items that starts at the given
+// offset and contains at most limit items. u is the URL of the current page, the other page URLs
+// are built by setting the "offset" query string parameter.
+func OffsetPage(u *url.URL, offset, limit, total int) *Page {
+	p := &Page{Total: &total}
+	if limit < 1 {
+		return p
+	}
+	link := func(o int) string { return pageURL(u, "offset", strconv.Itoa(o)) }
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+	p.First = link(0)
+	p.Last = link(last)
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		if prev > last {
+			prev = last
+		}
+		p.Prev = link(prev)
+	}
+	if offset+limit < total {
+		p.Next = link(offset + limit)
+	}
+	return p
+}
+
+// CursorPage returns the page of a collection paginated with opaque cursors. next is the cursor of
+// the next page, the empty string if the current page is the last one. u is the URL of the
+// current page, the next page URL is built by setting the "cursor" query string parameter.
+func CursorPage(u *url.URL, next string) *Page {
+	p := &Page{First: pageURL(u, "cursor", "")}
+	if next != "" {
+		p.Next = pageURL(u, "cursor", next)
+	}
+	return p
+}
+
+// SetHeaders sets the "Link" header to the page links as described in RFC 8288 and the
+// "X-Total-Count" header to the total number of items if known.
+func (p *Page) SetHeaders(h http.Header) {
+	var links []string
+	for _, l := range []struct{ rel, url string }{
+		{"first", p.First}, {"prev", p.Prev}, {"next", p.Next}, {"last", p.Last},
+	} {
+		if l.url != "" {
+			links = append(links, fmt.Sprintf("<%s>; rel=%q", l.url, l.rel))
+		}
+	}
+	if len(links) > 0 {
+		h.Set("Link", strings.Join(links, ", "))
+	}
+	if p.Total != nil {
+		h.Set("X-Total-Count", strconv.Itoa(*p.Total))
+	}
+}
+
+// pageURL returns a copy of u where the query string parameter with the given name is set to
+// value or removed if value is empty.
+func pageURL(u *url.URL, name, value string) string {
+	pu := *u
+	q := pu.Query()
+	if value == "" {
+		q.Del(name)
+	} else {
+		q.Set(name, value)
+	}
+	pu.RawQuery = q.Encode()
+	return pu.String()
+}
@@ -0,0 +1,64 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Page", func() {
+	var u *url.URL
+
+	BeforeEach(func() {
+		var err error
+		u, err = url.Parse("/bottles?sort=name&page=2&page_size=10")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Describe("NumberedPage", func() {
+		It("computes the links to the other pages", func() {
+			p := goa.NumberedPage(u, 2, 10, 35)
+			Ω(*p.Total).Should(Equal(35))
+			Ω(p.First).Should(Equal("/bottles?page=1&page_size=10&sort=name"))
+			Ω(p.Prev).Should(Equal("/bottles?page=1&page_size=10&sort=name"))
+			Ω(p.Next).Should(Equal("/bottles?page=3&page_size=10&sort=name"))
+			Ω(p.Last).Should(Equal("/bottles?page=4&page_size=10&sort=name"))
+		})
+
+		It("omits the next link on the last page", func() {
+			p := goa.NumberedPage(u, 4, 10, 35)
+			Ω(p.Next).Should(BeEmpty())
+		})
+	})
+
+	Describe("OffsetPage", func() {
+		It("computes the links to the other pages", func() {
+			p := goa.OffsetPage(u, 5, 10, 35)
+			Ω(p.First).Should(ContainSubstring("offset=0"))
+			Ω(p.Prev).Should(ContainSubstring("offset=0"))
+			Ω(p.Next).Should(ContainSubstring("offset=15"))
+			Ω(p.Last).Should(ContainSubstring("offset=30"))
+		})
+	})
+
+	Describe("CursorPage", func() {
+		It("sets the next cursor", func() {
+			p := goa.CursorPage(u, "abc")
+			Ω(p.Total).Should(BeNil())
+			Ω(p.Next).Should(ContainSubstring("cursor=abc"))
+			Ω(goa.CursorPage(u, "").Next).Should(BeEmpty())
+		})
+	})
+
+	Describe("SetHeaders", func() {
+		It("sets the Link and X-Total-Count headers", func() {
+			h := make(http.Header)
+			goa.NumberedPage(u, 1, 10, 15).SetHeaders(h)
+			Ω(h.Get("Link")).Should(Equal(`</bottles?page=1&page_size=10&sort=name>; rel="first", </bottles?page=2&page_size=10&sort=name>; rel="next", </bottles?page=2&page_size=10&sort=name>; rel="last"`))
+			Ω(h.Get("X-Total-Count")).Should(Equal("15"))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package goa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Route describes a request handler of a service, goagen generates a Routes function that lists
+// the routes of all the API actions and file servers.
+type Route struct {
+	// Method is the HTTP method of the route, e.g. "GET".
+	Method string `json:"method"`
+	// Path is the request path of the route including any wildcard, e.g. "/bottles/:id".
+	Path string `json:"path"`
+	// Handler is the name of the handler, e.g. "Bottle.show" for the show action of the Bottle
+	// resource.
+	Handler string `json:"handler"`
+	// Mounted is true if a handler is registered for the route method and path on the service
+	// mux. It is only set by MountedRoutes.
+	Mounted bool `json:"mounted"`
+}
+
+// MountedRoutes returns a copy of routes where the Mounted field of each route indicates whether
+// mux has a handler registered for the route method and path.
+func MountedRoutes(mux ServeMux, routes []Route) []Route {
+	res := make([]Route, len(routes))
+	for i, r := range routes {
+		r.Mounted = mux.Lookup(r.Method, r.Path) != nil
+		res[i] = r
+	}
+	return res
+}
+
+// MountRoutes mounts a handler under path on the service mux that renders the given routes as a
+// JSON array so that operators can verify which handlers are actually mounted. The Mounted field
+// of each route is computed when the request is received so that the response reflects the
+// controllers mounted after MountRoutes is called. The handler is not a controller action so the
+// service and controller middlewares do not apply to it, it should only be mounted on debug
+// servers or behind an access control mechanism.
+func MountRoutes(service *Service, path string, routes []Route) {
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(rw).Encode(MountedRoutes(service.Mux, routes))
+	})
+	service.LogInfo("mount", "routes", path)
+}
@@ -0,0 +1,55 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var service *goa.Service
+	var routes []goa.Route
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		routes = []goa.Route{
+			{Method: "GET", Path: "/bottles/:id", Handler: "Bottle.show"},
+			{Method: "POST", Path: "/bottles", Handler: "Bottle.create"},
+		}
+		service.Mux.Handle("GET", "/bottles/:id", func(http.ResponseWriter, *http.Request, url.Values) {})
+	})
+
+	Describe("MountedRoutes", func() {
+		It("reports whether the routes are mounted", func() {
+			res := goa.MountedRoutes(service.Mux, routes)
+			Ω(res).Should(HaveLen(2))
+			Ω(res[0].Mounted).Should(BeTrue())
+			Ω(res[1].Mounted).Should(BeFalse())
+			Ω(routes[0].Mounted).Should(BeFalse())
+		})
+	})
+
+	Describe("MountRoutes", func() {
+		It("renders the route table", func() {
+			goa.MountRoutes(service, "/debug/routes", routes)
+			service.Mux.Handle("POST", "/bottles", func(http.ResponseWriter, *http.Request, url.Values) {})
+			req, _ := http.NewRequest("GET", "/debug/routes", nil)
+			rw := httptest.NewRecorder()
+			service.Mux.ServeHTTP(rw, req)
+
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+			var res []goa.Route
+			Ω(json.Unmarshal(rw.Body.Bytes(), &res)).Should(Succeed())
+			Ω(res).Should(Equal([]goa.Route{
+				{Method: "GET", Path: "/bottles/:id", Handler: "Bottle.show", Mounted: true},
+				{Method: "POST", Path: "/bottles", Handler: "Bottle.create", Mounted: true},
+			}))
+		})
+	})
+})
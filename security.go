@@ -3,7 +3,7 @@ package goa
 import "context"
 
 // Location is the enum defining where the value of key based security schemes should be read:
-// either a HTTP request header or a URL querystring value
+// either a HTTP request header, a URL querystring value or a cookie
 type Location string
 
 // LocHeader indicates the secret value should be loaded from the request headers.
@@ -12,6 +12,9 @@ const LocHeader Location = "header"
 // LocQuery indicates the secret value should be loaded from the request URL querystring.
 const LocQuery Location = "query"
 
+// LocCookie indicates the secret value should be loaded from the request cookies.
+const LocCookie Location = "cookie"
+
 // ContextRequiredScopes extracts the security scopes from the given context.
 // This should be used in auth handlers to validate that the required scopes are present in the
 // JWT or OAuth2 token.
@@ -66,9 +69,9 @@ type APIKeySecurity struct {
 type JWTSecurity struct {
 	// Description of the security scheme
 	Description string
-	// In represents where to check for the JWT, `query` or `header`
+	// In represents where to check for the JWT, `query`, `header` or `cookie`
 	In Location
-	// Name is the name of the `header` or `query` parameter to check for data.
+	// Name is the name of the `header`, `query` parameter or `cookie` to check for data.
 	Name string
 	// TokenURL defines the URL where you'd get the JWT tokens.
 	TokenURL string
@@ -0,0 +1,216 @@
+package goa
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	// defaultReadHeaderTimeout is the default duration allowed to read request headers.
+	defaultReadHeaderTimeout = 10 * time.Second
+
+	// defaultIdleTimeout is the default duration keep-alive connections are kept idle.
+	defaultIdleTimeout = 2 * time.Minute
+)
+
+type (
+	// RunOption is the type of the functions used to configure Run.
+	RunOption func(*runOptions)
+
+	// runOptions contains the settings used by Run.
+	runOptions struct {
+		shutdownTimeout time.Duration
+		signals         []os.Signal
+		certs           []certFiles
+		clientCAFile    string
+		h2c             bool
+		readHeader      time.Duration
+		idle            time.Duration
+		hooks           []func(context.Context) error
+	}
+
+	// certFiles contains the paths to a certificate and its key.
+	certFiles struct {
+		certFile string
+		keyFile  string
+	}
+)
+
+// ShutdownTimeout sets the maximum duration of the graceful shutdown performed by Run, 30 seconds
+// by default.
+func ShutdownTimeout(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.shutdownTimeout = d
+	}
+}
+
+// ShutdownSignals sets the signals that trigger the graceful shutdown performed by Run, SIGINT and
+// SIGTERM by default.
+func ShutdownSignals(sigs ...os.Signal) RunOption {
+	return func(o *runOptions) {
+		o.signals = sigs
+	}
+}
+
+// TLSFiles makes Run serve HTTPS requests using the given certificate and key files. TLSFiles may
+// be given multiple times to serve multiple hosts, the server picks the certificate matching the
+// host requested by the client (SNI) and defaults to the first certificate.
+func TLSFiles(certFile, keyFile string) RunOption {
+	return func(o *runOptions) {
+		o.certs = append(o.certs, certFiles{certFile, keyFile})
+	}
+}
+
+// ClientCAFile makes Run require clients to present a certificate signed by one of the CAs
+// contained in the given PEM file (mutual TLS). ClientCAFile has no effect unless TLSFiles is
+// also given.
+func ClientCAFile(caFile string) RunOption {
+	return func(o *runOptions) {
+		o.clientCAFile = caFile
+	}
+}
+
+// H2C makes Run serve HTTP/2 requests over cleartext connections (h2c) in addition to HTTP/1
+// requests, typically for services deployed behind a service mesh that terminates TLS. HTTP/2 is
+// always enabled for HTTPS servers, see TLSFiles.
+func H2C() RunOption {
+	return func(o *runOptions) {
+		o.h2c = true
+	}
+}
+
+// ReadHeaderTimeout sets the amount of time the server allows to read request headers, 10 seconds
+// by default unless the service server ReadHeaderTimeout field is set.
+func ReadHeaderTimeout(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.readHeader = d
+	}
+}
+
+// IdleTimeout sets the maximum amount of time to wait for the next request on keep-alive
+// connections, 2 minutes by default unless the service server IdleTimeout field is set.
+func IdleTimeout(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.idle = d
+	}
+}
+
+// OnShutdown registers a function called by Run once the server has shut down, typically to flush
+// buffered loggers or tracers. The functions are called in the order they are registered, the
+// context they are given expires at the end of the shutdown timeout.
+func OnShutdown(hook func(context.Context) error) RunOption {
+	return func(o *runOptions) {
+		o.hooks = append(o.hooks, hook)
+	}
+}
+
+// Run starts a HTTP server listening on the given host/port and blocks until the server fails or
+// the process receives one of the shutdown signals. On shutdown Run stops accepting new
+// connections, waits for the requests being handled to complete and for the idle connections to
+// close, cancels the service context (see CancelAll) and calls the OnShutdown hooks. The whole
+// shutdown must complete within the shutdown timeout. Run returns nil if the shutdown succeeds.
+//
+// Run also sets the server read header and idle timeouts to protect the service against slow or
+// idle clients, see ReadHeaderTimeout and IdleTimeout.
+func (service *Service) Run(addr string, opts ...RunOption) error {
+	o := &runOptions{
+		shutdownTimeout: 30 * time.Second,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		readHeader:      service.Server.ReadHeaderTimeout,
+		idle:            service.Server.IdleTimeout,
+	}
+	if o.readHeader == 0 {
+		o.readHeader = defaultReadHeaderTimeout
+	}
+	if o.idle == 0 {
+		o.idle = defaultIdleTimeout
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	service.Server.ReadHeaderTimeout = o.readHeader
+	service.Server.IdleTimeout = o.idle
+	if o.h2c {
+		service.Server.Handler = h2c.NewHandler(service.Server.Handler, &http2.Server{IdleTimeout: o.idle})
+	}
+	if len(o.certs) > 0 {
+		cfg, err := o.tlsConfig(service.Server.TLSConfig)
+		if err != nil {
+			return err
+		}
+		service.Server.TLSConfig = cfg
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, o.signals...)
+	defer signal.Stop(sigc)
+
+	errc := make(chan error, 1)
+	go func() {
+		if len(o.certs) > 0 {
+			errc <- service.ListenAndServeTLS(addr, "", "")
+			return
+		}
+		errc <- service.ListenAndServe(addr)
+	}()
+
+	select {
+	case err := <-errc:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	case sig := <-sigc:
+		service.LogInfo("shutdown", "signal", sig.String(), "timeout", o.shutdownTimeout.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.shutdownTimeout)
+	defer cancel()
+	err := service.Server.Shutdown(ctx)
+	service.CancelAll()
+	for _, hook := range o.hooks {
+		if herr := hook(ctx); herr != nil && err == nil {
+			err = herr
+		}
+	}
+	return err
+}
+
+// tlsConfig returns a copy of base, if any, configured with the certificates and client CAs.
+func (o *runOptions) tlsConfig(base *tls.Config) (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	for _, c := range o.certs {
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+	if o.clientCAFile != "" {
+		b, err := ioutil.ReadFile(o.clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", o.clientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
@@ -0,0 +1,221 @@
+// +build !windows
+
+package goa_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/http2"
+)
+
+var _ = Describe("Run", func() {
+	var (
+		service *goa.Service
+		addr    string
+		started chan struct{}
+		hooked  bool
+		errc    chan error
+		opts    []goa.RunOption
+		running bool
+	)
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		service.WithLogger(nil)
+		opts = nil
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Ω(err).ShouldNot(HaveOccurred())
+		addr = l.Addr().String()
+		l.Close()
+		started = make(chan struct{})
+		hooked = false
+		service.Mux.Handle("GET", "/slow", func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			rw.WriteHeader(http.StatusOK)
+		})
+	})
+
+	JustBeforeEach(func() {
+		errc = make(chan error, 1)
+		opts = append(opts,
+			goa.ShutdownSignals(syscall.SIGUSR1),
+			goa.OnShutdown(func(context.Context) error {
+				hooked = true
+				return nil
+			}))
+		go func() {
+			errc <- service.Run(addr, opts...)
+		}()
+		running = true
+		Eventually(func() error {
+			c, err := net.Dial("tcp", addr)
+			if err == nil {
+				c.Close()
+			}
+			return err
+		}).ShouldNot(HaveOccurred())
+	})
+
+	It("drains the requests and calls the hooks on shutdown", func() {
+		respc := make(chan int, 1)
+		go func() {
+			resp, err := http.Get("http://" + addr + "/slow")
+			if err != nil {
+				respc <- 0
+				return
+			}
+			resp.Body.Close()
+			respc <- resp.StatusCode
+		}()
+		<-started
+		Ω(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).ShouldNot(HaveOccurred())
+
+		Eventually(errc).Should(Receive(BeNil()))
+		running = false
+		Ω(<-respc).Should(Equal(http.StatusOK))
+		Ω(hooked).Should(BeTrue())
+		Ω(service.Context.Err()).Should(HaveOccurred())
+	})
+
+	It("sets the server timeouts", func() {
+		Ω(service.Server.ReadHeaderTimeout).Should(Equal(10 * time.Second))
+		Ω(service.Server.IdleTimeout).Should(Equal(2 * time.Minute))
+	})
+
+	Context("with h2c", func() {
+		BeforeEach(func() {
+			opts = []goa.RunOption{goa.H2C(), goa.IdleTimeout(time.Minute)}
+		})
+
+		It("serves HTTP/2 requests over cleartext connections", func() {
+			c := &http.Client{Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					return net.Dial(network, addr)
+				},
+			}}
+			resp, err := c.Get("http://" + addr + "/slow")
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+			Ω(resp.ProtoMajor).Should(Equal(2))
+			Ω(service.Server.IdleTimeout).Should(Equal(time.Minute))
+		})
+	})
+
+	Context("with TLS", func() {
+		var (
+			dir   string
+			roots *x509.CertPool
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "goa-tls")
+			Ω(err).ShouldNot(HaveOccurred())
+			roots = x509.NewCertPool()
+			for _, host := range []string{"a.example.com", "b.example.com"} {
+				cert, key := writeCert(dir, host)
+				opts = append(opts, goa.TLSFiles(cert, key))
+				b, err := ioutil.ReadFile(cert)
+				Ω(err).ShouldNot(HaveOccurred())
+				roots.AppendCertsFromPEM(b)
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("picks the certificate matching the requested host", func() {
+			for _, host := range []string{"a.example.com", "b.example.com"} {
+				conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, ServerName: host})
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(conn.ConnectionState().PeerCertificates[0].Subject.CommonName).Should(Equal(host))
+				conn.Close()
+			}
+		})
+
+		Context("with a client CA", func() {
+			var clientCert tls.Certificate
+
+			BeforeEach(func() {
+				cert, key := writeCert(dir, "client")
+				opts = append(opts, goa.ClientCAFile(cert))
+				var err error
+				clientCert, err = tls.LoadX509KeyPair(cert, key)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("requires a client certificate", func() {
+				get := func(certs ...tls.Certificate) error {
+					c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+						RootCAs:      roots,
+						ServerName:   "a.example.com",
+						Certificates: certs,
+					}}}
+					resp, err := c.Get("https://" + addr + "/slow")
+					if err == nil {
+						resp.Body.Close()
+					}
+					return err
+				}
+				Ω(get()).Should(HaveOccurred())
+				Ω(get(clientCert)).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+
+	AfterEach(func() {
+		if running {
+			syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+			Eventually(errc).Should(Receive())
+		}
+	})
+})
+
+// writeCert writes a self-signed certificate for the given host and its key to dir and returns
+// the paths to the files.
+func writeCert(dir, host string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Ω(err).ShouldNot(HaveOccurred())
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	Ω(err).ShouldNot(HaveOccurred())
+	kder, err := x509.MarshalECPrivateKey(key)
+	Ω(err).ShouldNot(HaveOccurred())
+	certFile := filepath.Join(dir, host+".pem")
+	keyFile := filepath.Join(dir, host+"-key.pem")
+	Ω(ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)).ShouldNot(HaveOccurred())
+	Ω(ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kder}), 0600)).ShouldNot(HaveOccurred())
+	return certFile, keyFile
+}
@@ -1,7 +1,10 @@
 package goa
 
 import (
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -39,9 +42,18 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
-
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		// MaxMultipartMemory is the maximum number of bytes of a multipart request body that
+		// the generated payload decoders keep in memory, the content of the file parts that
+		// exceed it is stored in temporary files. The whole body is read before the action
+		// runs. Defaults to 32MB.
+		MaxMultipartMemory int64
+
+		middleware         []Middleware         // Middleware chain
+		endpointMiddleware []EndpointMiddleware // Endpoint middleware chain
+		cancel             context.CancelFunc   // Service context cancel signal trigger
+
+		notFound         MuxHandler              // Default NotFound handler
+		methodNotAllowed MethodNotAllowedHandler // Default MethodNotAllowed handler
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -52,8 +64,9 @@ type (
 		Service *Service
 		// Controller root context
 		Context context.Context
-		// MaxRequestBodyLength is the maximum length read from request bodies.
-		// Set to 0 to remove the limit altogether. Defaults to 1GB.
+		// MaxRequestBodyLength is the maximum length read from request bodies. The limit
+		// also applies to the decompressed content of request bodies whose Content-Encoding
+		// is gzip or deflate. Set to 0 to remove the limit altogether. Defaults to 1GB.
 		MaxRequestBodyLength int64
 		// FileSystem is used in FileHandler to open files. By default it returns
 		// http.Dir but you can override it with another one that implements http.FileSystem.
@@ -69,7 +82,8 @@ type (
 		//	}
 		FileSystem func(string) http.FileSystem
 
-		middleware []Middleware // Controller specific middleware if any
+		middleware         []Middleware         // Controller specific middleware if any
+		endpointMiddleware []EndpointMiddleware // Controller specific endpoint middleware if any
 	}
 
 	// FileServer is the interface implemented by controllers that can serve static files.
@@ -105,6 +119,8 @@ func New(name string) *Service {
 			Decoder: NewHTTPDecoder(),
 			Encoder: NewHTTPEncoder(),
 
+			MaxMultipartMemory: 32 << 20,
+
 			cancel: cancel,
 		}
 		notFoundHandler         Handler
@@ -112,7 +128,7 @@ func New(name string) *Service {
 	)
 
 	// Setup default NotFound handler
-	mux.HandleNotFound(func(rw http.ResponseWriter, req *http.Request, params url.Values) {
+	service.notFound = func(rw http.ResponseWriter, req *http.Request, params url.Values) {
 		if resp := ContextResponse(ctx); resp != nil && resp.Written() {
 			return
 		}
@@ -133,10 +149,11 @@ func New(name string) *Service {
 		if !ContextResponse(ctx).Written() {
 			service.Send(ctx, 404, err)
 		}
-	})
+	}
+	mux.HandleNotFound(service.notFound)
 
 	// Setup default MethodNotAllowed handler
-	mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, params url.Values, methods map[string]httptreemux.HandlerFunc) {
+	service.methodNotAllowed = func(rw http.ResponseWriter, req *http.Request, params url.Values, methods map[string]httptreemux.HandlerFunc) {
 		if resp := ContextResponse(ctx); resp != nil && resp.Written() {
 			return
 		}
@@ -164,7 +181,8 @@ func New(name string) *Service {
 		if !ContextResponse(ctx).Written() {
 			service.Send(ctx, 405, err)
 		}
-	})
+	}
+	mux.HandleMethodNotAllowed(service.methodNotAllowed)
 
 	return service
 }
@@ -182,16 +200,41 @@ func (service *Service) Use(m Middleware) {
 	service.middleware = append(service.middleware, m)
 }
 
+// UseMux replaces the service mux with the given mux, typically an adapter of the mux package
+// that makes it possible to use another router. UseMux registers the service NotFound and
+// MethodNotAllowed handlers with mux and sets it as the handler of the service server. It must be
+// called prior to mounting the controllers.
+func (service *Service) UseMux(mux ServeMux) {
+	mux.HandleNotFound(service.notFound)
+	mux.HandleMethodNotAllowed(service.methodNotAllowed)
+	service.Mux = mux
+	if service.Server != nil {
+		service.Server.Handler = mux
+	}
+}
+
 // WithLogger sets the logger used internally by the service and by Log.
 func (service *Service) WithLogger(logger LogAdapter) {
 	service.Context = WithLogger(service.Context, logger)
 }
 
+// LogDebug logs the debug message and values at odd indeces using the keys at even indeces of the
+// keyvals slice.
+func (service *Service) LogDebug(msg string, keyvals ...interface{}) {
+	LogDebug(service.Context, msg, keyvals...)
+}
+
 // LogInfo logs the message and values at odd indeces using the keys at even indeces of the keyvals slice.
 func (service *Service) LogInfo(msg string, keyvals ...interface{}) {
 	LogInfo(service.Context, msg, keyvals...)
 }
 
+// LogWarn logs the warning and values at odd indeces using the keys at even indeces of the keyvals
+// slice.
+func (service *Service) LogWarn(msg string, keyvals ...interface{}) {
+	LogWarn(service.Context, msg, keyvals...)
+}
+
 // LogError logs the error and values at odd indeces using the keys at even indeces of the keyvals slice.
 func (service *Service) LogError(msg string, keyvals ...interface{}) {
 	LogError(service.Context, msg, keyvals...)
@@ -231,14 +274,59 @@ func (service *Service) NewController(name string) *Controller {
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders. It uses the default service encoder if no match is found unless the service encoder
+// is strict. The encoder is negotiated prior to writing the response header so that the error
+// handler may respond with 406 Not Acceptable when Send returns an ErrNotAcceptable error. Error
+// responses (4xx and 5xx) always fall back to the default encoder, including the 406 response.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	accept := ContextRequest(ctx).Header.Get("Accept")
+	p, contentType, err := service.Encoder.resolve(accept, code >= 400)
+	if err != nil {
+		if code >= 400 {
+			// There is no default encoder for the error body, send the status alone.
+			r.WriteHeader(code)
+		}
+		return err
+	}
 	r.WriteHeader(code)
-	return service.EncodeResponse(ctx, body)
+	return service.Encoder.encodeWith(p, contentType, body, r)
+}
+
+// SetDefaultContentType sets the content type used to encode responses to requests that do not
+// specify an Accept header and to decode request bodies that do not specify a Content-Type
+// header. The code generated by goagen calls SetDefaultContentType when the design uses the
+// DefaultResponseContentType DSL.
+func (service *Service) SetDefaultContentType(contentType string) {
+	service.Encoder.DefaultContentType = contentType
+	service.Decoder.DefaultContentType = contentType
+}
+
+// SetRouting configures how the service mux matches request paths: trailingSlash is one of
+// TrailingSlashRedirect, TrailingSlashAbsorb or TrailingSlashStrict, the empty string selects the
+// default behavior. The code generated by goagen calls SetRouting when the design uses the
+// TrailingSlash or CaseInsensitiveRouting DSL. SetRouting has no effect if the service mux does
+// not implement RoutingConfigurer.
+func (service *Service) SetRouting(trailingSlash string, caseInsensitive bool) {
+	rc, ok := service.Mux.(RoutingConfigurer)
+	if !ok {
+		return
+	}
+	rc.SetTrailingSlash(trailingSlash)
+	rc.SetCaseInsensitive(caseInsensitive)
+}
+
+// SetMethodOverride enables or disables routing POST requests that set the MethodOverrideHeader
+// header using the PUT, PATCH or DELETE method given in the header. The code generated by goagen
+// calls SetMethodOverride when the design uses the MethodOverride DSL. SetMethodOverride has no
+// effect if the service mux does not implement MethodOverrider.
+func (service *Service) SetMethodOverride(enabled bool) {
+	if mo, ok := service.Mux.(MethodOverrider); ok {
+		mo.SetMethodOverride(enabled)
+	}
 }
 
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
@@ -299,6 +387,7 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	// Use closure to enable late computation of handlers to ensure all middleware has been
 	// registered.
 	var handler Handler
+	var endpointChain []EndpointMiddleware
 	var initHandler sync.Once
 
 	return func(rw http.ResponseWriter, req *http.Request, params url.Values) {
@@ -315,10 +404,17 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 			for i := range chain {
 				handler = chain[ml-i-1](handler)
 			}
+			endpointChain = append(append([]EndpointMiddleware{}, ctrl.Service.endpointMiddleware...), ctrl.endpointMiddleware...)
 		})
 
 		// Build context
 		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
+		if route := ContextRoute(req.Context()); route != "" {
+			ctx = WithRoute(ctx, route)
+		}
+		if len(endpointChain) > 0 {
+			ctx = context.WithValue(ctx, endpointMiddlewareKey, endpointChain)
+		}
 
 		// Protect against request bodies with unreasonable length
 		if ctrl.MaxRequestBodyLength > 0 {
@@ -327,11 +423,15 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 
 		// Load body if any
 		if req.ContentLength > 0 && unm != nil {
-			if err := unm(ctx, ctrl.Service, req); err != nil {
-				if err.Error() == "http: request body too large" {
+			err := decompressBody(req, ctrl.MaxRequestBodyLength)
+			if err == nil {
+				err = unm(ctx, ctrl.Service, req)
+			}
+			if err != nil {
+				if err.Error() == "http: request body too large" || err == errBodyTooLarge {
 					msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
 					err = ErrRequestBodyTooLarge(msg)
-				} else {
+				} else if !isClientError(err) {
 					err = ErrBadRequest(err)
 				}
 				ctx = WithError(ctx, err)
@@ -347,6 +447,72 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 	}
 }
 
+// errBodyTooLarge is the error returned when reading decompressed request bodies whose length
+// exceeds the controller MaxRequestBodyLength.
+var errBodyTooLarge = errors.New("decompressed request body too large")
+
+// isClientError returns true if err is a service error with a 4xx status such as the 413 errors
+// returned by the generated unmarshalers of actions that define a maximum body length or the
+// validation errors returned by the generated payload validations. These errors are left as is
+// so that the details they carry make it to the response.
+func isClientError(err error) bool {
+	se, ok := err.(ServiceError)
+	if !ok {
+		return false
+	}
+	status := se.ResponseStatus()
+	return status >= 400 && status < 500
+}
+
+// decompressBody wraps the request body with a reader that decompresses it if the request
+// Content-Encoding header is gzip or deflate. The decompressed content length is limited to max
+// bytes unless max is 0.
+func decompressBody(req *http.Request, max int64) error {
+	var (
+		body io.ReadCloser
+		err  error
+	)
+	switch strings.ToLower(req.Header.Get("Content-Encoding")) {
+	case "gzip", "x-gzip":
+		body, err = gzip.NewReader(req.Body)
+	case "deflate":
+		body, err = zlib.NewReader(req.Body)
+	default:
+		return nil
+	}
+	if err != nil {
+		return ErrInvalidEncoding(err)
+	}
+	if max > 0 {
+		body = &limitedReadCloser{ReadCloser: body, n: max}
+	}
+	req.Body = body
+	req.Header.Del("Content-Encoding")
+	return nil
+}
+
+// limitedReadCloser returns errBodyTooLarge when more than n bytes are read.
+type limitedReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+// Read reads from the underlying reader and decrements the number of bytes left.
+func (l *limitedReadCloser) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
+
 // FileHandler returns a handler that serves files under the given filename for the given route path.
 // The logic for what to do when the filename points to a file vs. a directory is the same as the
 // standard http package ServeFile function. The path may end with a wildcard that matches the rest
@@ -361,7 +527,8 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 //	c.FileHandler("/assets/*filepath", "/www/data/assets")
 //
 // returns the content of the file "/www/data/assets/x/y/z" when requests are sent to
-// "/assets/x/y/z".
+// "/assets/x/y/z". The handler sets a weak ETag computed from the file modification time and
+// size so that clients may send conditional and range requests.
 func (ctrl *Controller) FileHandler(path, filename string) Handler {
 	var wc string
 	if idx := strings.LastIndex(path, "/*"); idx > -1 && idx < len(path)-1 {
@@ -377,7 +544,7 @@ func (ctrl *Controller) FileHandler(path, filename string) Handler {
 				fname = filepath.Join(filename, m[0])
 			}
 		}
-		LogInfo(ctx, "serve file", "name", fname, "route", req.URL.Path)
+		LogDebug(ctx, "serve file", "name", fname, "route", req.URL.Path)
 		dir, name := filepath.Split(fname)
 		fs := ctrl.FileSystem(dir)
 		f, err := fs.Open(name)
@@ -409,6 +576,11 @@ func (ctrl *Controller) FileHandler(path, filename string) Handler {
 		if d.IsDir() {
 			return dirList(rw, f)
 		}
+		// http.ServeContent handles the If-None-Match and If-Range headers using the ETag
+		// header.
+		if rw.Header().Get("ETag") == "" {
+			rw.Header().Set("ETag", fmt.Sprintf(`W/"%x-%x"`, d.ModTime().UnixNano(), d.Size()))
+		}
 		http.ServeContent(rw, req, d.Name(), d.ModTime(), f)
 		return nil
 	}
@@ -2,16 +2,20 @@ package goa_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"context"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -35,6 +39,29 @@ var _ = Describe("Service", func() {
 			Ω(s.Name).Should(Equal(appName))
 			Ω(s.Mux).ShouldNot(BeNil())
 			Ω(s.Server).ShouldNot(BeNil())
+			Ω(s.MaxMultipartMemory).Should(Equal(int64(32 << 20)))
+		})
+	})
+
+	Describe("UseMux", func() {
+		var mux goa.ServeMux
+
+		BeforeEach(func() {
+			mux = goa.NewMux()
+			s.UseMux(mux)
+		})
+
+		It("sets the service mux and server handler", func() {
+			Ω(s.Mux).Should(Equal(mux))
+			Ω(s.Server.Handler).Should(Equal(mux))
+		})
+
+		It("registers the service not found handler", func() {
+			req, _ := http.NewRequest("GET", "/foo", nil)
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(404))
+			Ω(string(rw.Body)).Should(ContainSubstring(`"code":"not_found"`))
 		})
 	})
 
@@ -140,6 +167,120 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("unmarshaler returning a request too large error", func() {
+		var rw *TestResponseWriter
+
+		BeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`"body"`))
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				return goa.ErrRequestBodyTooLarge("request body length exceeds 2 bytes")
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				rw.WriteHeader(400)
+				rw.Write([]byte(goa.ContextError(ctx).Error()))
+				return nil
+			}
+			ctrl.MuxHandler("testMax", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("preserves the error", func() {
+			Ω(string(rw.Body)).Should(MatchRegexp(`^\[.*\] 413 request_too_large: request body length exceeds 2 bytes$`))
+		})
+	})
+
+	Describe("unmarshaler returning validation errors", func() {
+		var ctxErr error
+
+		BeforeEach(func() {
+			req, _ := http.NewRequest("POST", "/foo", bytes.NewBufferString(`{}`))
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl := s.NewController("test")
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				err := goa.MissingAttributeError("payload", "name")
+				return goa.MergeErrors(err, goa.InvalidRangeError("payload.count", 0, 1, true))
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				ctxErr = goa.ContextError(ctx)
+				return nil
+			}
+			ctrl.MuxHandler("testValidation", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("preserves all the violations", func() {
+			Ω(ctxErr).Should(HaveOccurred())
+			se, ok := ctxErr.(goa.ServiceError)
+			Ω(ok).Should(BeTrue())
+			Ω(se.ResponseStatus()).Should(Equal(400))
+			Ω(se.Token()).ShouldNot(BeEmpty())
+			Ω(ctxErr.Error()).Should(ContainSubstring("invalid_request"))
+			Ω(ctxErr.Error()).Should(ContainSubstring(`attribute "name"`))
+			Ω(ctxErr.Error()).Should(ContainSubstring("payload.count"))
+		})
+	})
+
+	Describe("compressed request bodies", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var ctrl *goa.Controller
+		var read string
+		content := `"` + strings.Repeat("a", 1000) + `"`
+
+		BeforeEach(func() {
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			gw.Write([]byte(content))
+			gw.Close()
+			req, _ = http.NewRequest("POST", "/foo", &buf)
+			req.Header.Set("Content-Encoding", "gzip")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl = s.NewController("test")
+			read = ""
+		})
+
+		JustBeforeEach(func() {
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				b, err := ioutil.ReadAll(req.Body)
+				read = string(b)
+				return err
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				if err := goa.ContextError(ctx); err != nil {
+					rw.WriteHeader(400)
+					rw.Write([]byte(err.Error()))
+				}
+				return nil
+			}
+			ctrl.MuxHandler("testGzip", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("decompresses the body", func() {
+			Ω(read).Should(Equal(content))
+			Ω(rw.Status).ShouldNot(Equal(400))
+		})
+
+		Context("with a decompressed length exceeding the maximum", func() {
+			BeforeEach(func() {
+				ctrl.MaxRequestBodyLength = 100
+			})
+
+			It("returns a request too large error", func() {
+				Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 100 bytes`))
+			})
+		})
+
+		Context("with an invalid compressed body", func() {
+			BeforeEach(func() {
+				req.Body = ioutil.NopCloser(bytes.NewBufferString("not gzip"))
+			})
+
+			It("returns a bad request error", func() {
+				Ω(rw.Status).Should(Equal(400))
+			})
+		})
+	})
+
 	Describe("MuxHandler", func() {
 		var handler goa.Handler
 		var unmarshaler goa.Unmarshaler
@@ -333,6 +474,45 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("Send", func() {
+		var rw *httptest.ResponseRecorder
+		var sendErr error
+
+		BeforeEach(func() {
+			s.Encoder.Register(goa.NewJSONEncoder, "application/json")
+			s.Use(middleware.ErrorHandler(s, false))
+		})
+
+		JustBeforeEach(func() {
+			req, _ := http.NewRequest("GET", "/foo", nil)
+			req.Header.Set("Accept", "text/html")
+			rw = httptest.NewRecorder()
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				sendErr = s.Send(ctx, 200, map[string]int{"a": 1})
+				return sendErr
+			}
+			s.NewController("test").MuxHandler("send", handler, nil)(rw, req, nil)
+		})
+
+		It("falls back to the default encoder", func() {
+			Ω(sendErr).ShouldNot(HaveOccurred())
+			Ω(rw.Code).Should(Equal(200))
+			Ω(rw.Body.String()).Should(Equal(`{"a":1}` + "\n"))
+		})
+
+		Context("with a strict encoder", func() {
+			BeforeEach(func() {
+				s.Encoder.Strict = true
+			})
+
+			It("responds with 406 Not Acceptable", func() {
+				Ω(sendErr).Should(HaveOccurred())
+				Ω(rw.Code).Should(Equal(406))
+				Ω(rw.Body.String()).Should(ContainSubstring(`"code":"not_acceptable"`))
+			})
+		})
+	})
+
 	Describe("FileHandler", func() {
 		const publicPath = "github.com/goadesign/goa/public"
 
@@ -390,6 +570,32 @@ var _ = Describe("Service", func() {
 				Ω(tw.Body).Should(Equal(respContent))
 			})
 		})
+
+		Context("with a conditional request", func() {
+			It("uses the ETag to respond with 304 Not Modified", func() {
+				dir, err := ioutil.TempDir("", "goa-files")
+				Ω(err).ShouldNot(HaveOccurred())
+				defer os.RemoveAll(dir)
+				err = ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("var x;"), 0644)
+				Ω(err).ShouldNot(HaveOccurred())
+				ctrl := s.NewController("test")
+				h := ctrl.MuxHandler("serve", ctrl.FileHandler("/app.js", filepath.Join(dir, "app.js")), nil)
+
+				r, err := http.NewRequest("GET", "/app.js", nil)
+				Ω(err).ShouldNot(HaveOccurred())
+				rw := httptest.NewRecorder()
+				h(rw, r, nil)
+				Ω(rw.Code).Should(Equal(200))
+				Ω(rw.Header().Get("Content-Type")).Should(ContainSubstring("javascript"))
+				etag := rw.Header().Get("ETag")
+				Ω(etag).Should(HavePrefix(`W/"`))
+
+				r.Header.Set("If-None-Match", etag)
+				rw = httptest.NewRecorder()
+				h(rw, r, nil)
+				Ω(rw.Code).Should(Equal(304))
+			})
+		})
 	})
 })
 
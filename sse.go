@@ -0,0 +1,81 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EventStream sends Server-Sent Events to a client. The stream writes each event to the
+// underlying response writer and flushes it right away. See
+// https://html.spec.whatwg.org/multipage/server-sent-events.html.
+type EventStream struct {
+	// LastEventID contains the value of the Last-Event-ID header sent by clients that
+	// reconnect after losing the connection. Handlers may use it to resume the stream from
+	// the corresponding event.
+	LastEventID string
+
+	rw      http.ResponseWriter
+	flusher http.Flusher
+}
+
+// NewEventStream writes the response headers required to stream Server-Sent Events and returns
+// the corresponding stream. It returns an error if the response writer does not support
+// flushing.
+func NewEventStream(rw http.ResponseWriter, req *http.Request) (*EventStream, error) {
+	f, ok := rw.(http.Flusher)
+	if !ok {
+		return nil, fmt.Errorf("response writer does not support flushing, cannot stream events")
+	}
+	h := rw.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &EventStream{
+		LastEventID: req.Header.Get("Last-Event-ID"),
+		rw:          rw,
+		flusher:     f,
+	}, nil
+}
+
+// Send sends an event with the given ID and type and whose data is the JSON representation of
+// v. The ID and the type are omitted from the event if empty.
+func (s *EventStream) Send(id, event string, v interface{}) error {
+	if strings.ContainsAny(id+event, "\r\n") {
+		return fmt.Errorf("invalid event ID or type, must not contain line breaks")
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	if _, err := s.rw.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Comment sends a comment line, comments are ignored by clients and can be used to keep the
+// connection alive.
+func (s *EventStream) Comment(text string) error {
+	if _, err := fmt.Fprintf(s.rw, ": %s\n\n", text); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,58 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventStream", func() {
+	var rw *httptest.ResponseRecorder
+	var req *http.Request
+	var stream *goa.EventStream
+
+	BeforeEach(func() {
+		var err error
+		rw = httptest.NewRecorder()
+		req, err = http.NewRequest("GET", "/events", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Last-Event-ID", "42")
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		stream, err = goa.NewEventStream(rw, req)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("writes the event stream headers", func() {
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/event-stream"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-cache"))
+		Ω(rw.Flushed).Should(BeTrue())
+	})
+
+	It("exposes the last event ID", func() {
+		Ω(stream.LastEventID).Should(Equal("42"))
+	})
+
+	It("sends events", func() {
+		err := stream.Send("43", "update", map[string]int{"count": 1})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("id: 43\nevent: update\ndata: {\"count\":1}\n\n"))
+	})
+
+	It("omits empty event IDs and types", func() {
+		err := stream.Send("", "", "hello")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(rw.Body.String()).Should(Equal("data: \"hello\"\n\n"))
+	})
+
+	It("rejects event IDs containing line breaks", func() {
+		err := stream.Send("1\ndata: injected", "", "hello")
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -51,6 +51,9 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
+
+	// FormatDuration defines duration values using the syntax accepted by time.ParseDuration.
+	FormatDuration = "duration"
 )
 
 var (
@@ -76,6 +79,7 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "duration": Go duration value, e.g. "1h30m"
 func ValidateFormat(f Format, val string) error {
 	var err error
 	switch f {
@@ -113,6 +117,8 @@ func ValidateFormat(f Format, val string) error {
 		_, _, err = net.ParseCIDR(val)
 	case FormatRegexp:
 		_, err = regexp.Compile(val)
+	case FormatDuration:
+		_, err = time.ParseDuration(val)
 	case FormatRFC1123:
 		_, err = time.Parse(time.RFC1123, val)
 	default:
@@ -369,4 +369,30 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("Duration", func() {
+		BeforeEach(func() {
+			f = goa.FormatDuration
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "1 hour"
+			})
+
+			It("does not validates", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "1h30m"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
 })
@@ -0,0 +1,186 @@
+package goa
+
+import (
+	"bufio"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dimfeld/httptreemux"
+)
+
+type (
+	// SelectVersionFunc returns the API version targeted by the given request, the empty string
+	// if the request does not select a version.
+	SelectVersionFunc func(*http.Request) string
+
+	// EncodeVersionFunc returns the response writer used by the handlers of the given API
+	// version, the writer encodes the version in the response.
+	EncodeVersionFunc func(rw http.ResponseWriter, version string) http.ResponseWriter
+
+	// VersionMux is a ServeMux that routes the requests that select an API version using the
+	// mux of that version. The handlers registered with Handle serve the requests that don't
+	// select a version or that no handler of the selected version matches.
+	VersionMux struct {
+		ServeMux
+		selectVersion SelectVersionFunc
+		encodeVersion EncodeVersionFunc
+		muxes         map[string]ServeMux
+	}
+
+	// versionServeMux is the mux of an API version. The handlers registered with Handle
+	// encode the version in their responses.
+	versionServeMux struct {
+		ServeMux
+		version       string
+		encodeVersion EncodeVersionFunc
+	}
+
+	// mediaTypeVersionWriter adds the version parameter to the response Content-Type header
+	// when the response header is written.
+	mediaTypeVersionWriter struct {
+		http.ResponseWriter
+		param, version string
+		wroteHeader    bool
+	}
+)
+
+// NewVersionMux returns a VersionMux that uses mux to route the requests that are not handled
+// by a specific version, sel to compute the version selected by a request and enc to encode
+// the version in the responses of the version handlers. enc may be nil.
+func NewVersionMux(mux ServeMux, sel SelectVersionFunc, enc EncodeVersionFunc) *VersionMux {
+	return &VersionMux{
+		ServeMux:      mux,
+		selectVersion: sel,
+		encodeVersion: enc,
+		muxes:         make(map[string]ServeMux),
+	}
+}
+
+// Version returns the mux used to route the requests that select the given version.
+func (m *VersionMux) Version(version string) ServeMux {
+	if mux, ok := m.muxes[version]; ok {
+		return mux
+	}
+	mux := NewMux()
+	fallback := func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		m.ServeMux.ServeHTTP(rw, req)
+	}
+	mux.HandleNotFound(fallback)
+	mux.HandleMethodNotAllowed(func(rw http.ResponseWriter, req *http.Request, v url.Values, _ map[string]httptreemux.HandlerFunc) {
+		fallback(rw, req, v)
+	})
+	var vmux ServeMux = mux
+	if m.encodeVersion != nil {
+		vmux = &versionServeMux{ServeMux: mux, version: version, encodeVersion: m.encodeVersion}
+	}
+	m.muxes[version] = vmux
+	return vmux
+}
+
+// ServeHTTP routes the request using the mux of the version it selects if any.
+func (m *VersionMux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if mux, ok := m.muxes[m.selectVersion(req)]; ok {
+		mux.ServeHTTP(rw, req)
+		return
+	}
+	m.ServeMux.ServeHTTP(rw, req)
+}
+
+// HeaderSelectVersionFunc returns a SelectVersionFunc that reads the version from the request
+// header with the given name.
+func HeaderSelectVersionFunc(header string) SelectVersionFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(header)
+	}
+}
+
+// MediaTypeSelectVersionFunc returns a SelectVersionFunc that reads the version from the media
+// type parameter with the given name. The parameter is read from the Accept header media range
+// with the highest quality value that defines it or else from the Content-Type header, e.g.
+// "application/json; version=v2".
+func MediaTypeSelectVersionFunc(param string) SelectVersionFunc {
+	key := strings.ToLower(param)
+	return func(req *http.Request) string {
+		var (
+			version string
+			bestQ   float64
+		)
+		for _, r := range parseAccept(req.Header.Get("Accept")) {
+			if v := r.params[key]; v != "" && r.q > bestQ {
+				version, bestQ = v, r.q
+			}
+		}
+		if version != "" {
+			return version
+		}
+		if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil {
+			return params[key]
+		}
+		return ""
+	}
+}
+
+// HeaderEncodeVersionFunc returns an EncodeVersionFunc that sets the response header with the
+// given name to the version.
+func HeaderEncodeVersionFunc(header string) EncodeVersionFunc {
+	return func(rw http.ResponseWriter, version string) http.ResponseWriter {
+		rw.Header().Set(header, version)
+		return rw
+	}
+}
+
+// MediaTypeEncodeVersionFunc returns an EncodeVersionFunc that adds the media type parameter with
+// the given name to the response Content-Type header, e.g. "application/json; version=v2".
+func MediaTypeEncodeVersionFunc(param string) EncodeVersionFunc {
+	return func(rw http.ResponseWriter, version string) http.ResponseWriter {
+		return &mediaTypeVersionWriter{ResponseWriter: rw, param: param, version: version}
+	}
+}
+
+// Handle registers the handler so that it encodes the version in its responses.
+func (m *versionServeMux) Handle(method, path string, handle MuxHandler) {
+	m.ServeMux.Handle(method, path, func(rw http.ResponseWriter, req *http.Request, v url.Values) {
+		handle(m.encodeVersion(rw, m.version), req, v)
+	})
+}
+
+// WriteHeader adds the version parameter to the Content-Type header and writes the header.
+func (w *mediaTypeVersionWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if mt, params, err := mime.ParseMediaType(w.Header().Get("Content-Type")); err == nil {
+			if _, ok := params[w.param]; !ok {
+				params[w.param] = w.version
+				w.Header().Set("Content-Type", mime.FormatMediaType(mt, params))
+			}
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the header if needed and the data.
+func (w *mediaTypeVersionWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the buffered data to the client if the underlying writer supports it.
+func (w *mediaTypeVersionWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection if the underlying writer supports it.
+func (w *mediaTypeVersionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
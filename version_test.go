@@ -0,0 +1,111 @@
+package goa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VersionMux", func() {
+	var mux *goa.VersionMux
+	var req *http.Request
+	var rw *httptest.ResponseRecorder
+
+	handler := func(body string) goa.MuxHandler {
+		return func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+			rw.Write([]byte(body))
+		}
+	}
+
+	BeforeEach(func() {
+		mux = goa.NewVersionMux(goa.NewMux(), goa.HeaderSelectVersionFunc("X-API-Version"), goa.HeaderEncodeVersionFunc("X-API-Version"))
+		mux.Handle("GET", "/health", handler("health"))
+		mux.Version("v1").Handle("GET", "/bottles", handler("v1"))
+		mux.Version("v2").Handle("GET", "/bottles", handler("v2"))
+		req = httptest.NewRequest("GET", "/bottles", nil)
+	})
+
+	JustBeforeEach(func() {
+		rw = httptest.NewRecorder()
+		mux.ServeHTTP(rw, req)
+	})
+
+	Context("with a request selecting a version", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "v2")
+		})
+
+		It("uses the version handler", func() {
+			Ω(rw.Body.String()).Should(Equal("v2"))
+		})
+
+		It("encodes the version in the response", func() {
+			Ω(rw.Header().Get("X-API-Version")).Should(Equal("v2"))
+		})
+
+		Context("sent to an unversioned path", func() {
+			BeforeEach(func() {
+				req = httptest.NewRequest("GET", "/health", nil)
+				req.Header.Set("X-API-Version", "v2")
+			})
+
+			It("uses the default mux", func() {
+				Ω(rw.Body.String()).Should(Equal("health"))
+				Ω(rw.Header().Get("X-API-Version")).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with a request selecting an unknown version", func() {
+		BeforeEach(func() {
+			req.Header.Set("X-API-Version", "v3")
+		})
+
+		It("responds with 404", func() {
+			Ω(rw.Code).Should(Equal(404))
+		})
+	})
+})
+
+var _ = Describe("MediaTypeSelectVersionFunc", func() {
+	sel := goa.MediaTypeSelectVersionFunc("version")
+
+	It("reads the version from the Accept header", func() {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "application/json; version=v1; q=0.5, application/xml; version=v2")
+		Ω(sel(req)).Should(Equal("v2"))
+	})
+
+	It("falls back to the Content-Type header", func() {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json; version=v1")
+		Ω(sel(req)).Should(Equal("v1"))
+	})
+})
+
+var _ = Describe("MediaTypeEncodeVersionFunc", func() {
+	enc := goa.MediaTypeEncodeVersionFunc("version")
+
+	It("adds the version parameter to the response Content-Type", func() {
+		rec := httptest.NewRecorder()
+		rw := enc(rec, "v2")
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		rw.Write([]byte("{}"))
+		Ω(rec.Header().Get("Content-Type")).Should(Equal("application/json; charset=utf-8; version=v2"))
+		Ω(rec.Body.String()).Should(Equal("{}"))
+	})
+
+	It("keeps the version set by the handler", func() {
+		rec := httptest.NewRecorder()
+		rw := enc(rec, "v2")
+		rw.Header().Set("Content-Type", "application/json; version=v1")
+		rw.WriteHeader(http.StatusNoContent)
+		Ω(rec.Header().Get("Content-Type")).Should(Equal("application/json; version=v1"))
+		Ω(rec.Code).Should(Equal(http.StatusNoContent))
+	})
+})
@@ -0,0 +1,43 @@
+package goa
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// pingCodec sends websocket ping frames.
+var pingCodec = websocket.Codec{
+	Marshal: func(interface{}) ([]byte, byte, error) {
+		return nil, websocket.PingFrame, nil
+	},
+}
+
+// KeepAlive sends a ping frame over ws every interval until the returned function is called. The
+// peer answers the pings with pong frames, the websocket package handles both transparently.
+// KeepAlive closes ws if a ping cannot be sent so that the pending reads and writes fail instead
+// of blocking on a lost connection. The streams generated for the actions that use the KeepAlive
+// DSL call KeepAlive when they are created and stop it when they are closed.
+func KeepAlive(ws *websocket.Conn, interval time.Duration) (stop func()) {
+	var (
+		done = make(chan struct{})
+		once sync.Once
+	)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := pingCodec.Send(ws, nil); err != nil {
+					ws.Close()
+					return
+				}
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
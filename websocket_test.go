@@ -0,0 +1,55 @@
+package goa_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+)
+
+var _ = Describe("KeepAlive", func() {
+	var (
+		server *httptest.Server
+		ws     *websocket.Conn
+		stop   func()
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+			stop := goa.KeepAlive(ws, 10*time.Millisecond)
+			defer stop()
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+				websocket.Message.Send(ws, msg)
+			}
+		}))
+		url := "ws" + strings.TrimPrefix(server.URL, "http")
+		var err error
+		ws, err = websocket.Dial(url, "", server.URL)
+		Ω(err).ShouldNot(HaveOccurred())
+		stop = goa.KeepAlive(ws, 10*time.Millisecond)
+	})
+
+	AfterEach(func() {
+		stop()
+		ws.Close()
+		server.Close()
+	})
+
+	It("keeps the connection usable while pinging", func() {
+		time.Sleep(50 * time.Millisecond)
+		Ω(websocket.Message.Send(ws, "hello")).ShouldNot(HaveOccurred())
+		var msg string
+		Ω(websocket.Message.Receive(ws, &msg)).ShouldNot(HaveOccurred())
+		Ω(msg).Should(Equal("hello"))
+	})
+
+	It("can be stopped more than once", func() {
+		stop()
+		Ω(stop).ShouldNot(Panic())
+	})
+})